@@ -31,6 +31,7 @@ const (
 	AssessTypeObjectTypeCompatible = "OBJECT_TYPE_COMPATIBLE"
 	AssessTypeObjectTypeCheck      = "OBJECT_TYPE_CHECK"
 	AssessTypeObjectTypeRelated    = "OBJECT_TYPE_RELATED"
+	AssessTypeObjectTypeAdvisory   = "OBJECT_TYPE_ADVISORY"
 )
 
 // Assess Name
@@ -47,6 +48,7 @@ const (
 	AssessNamePartitionTypeCompatible      = "PARTITION_TYPE_COMPATIBLE"
 	AssessNameSubPartitionTypeCompatible   = "SUBPARTITION_TYPE_COMPATIBLE"
 	AssessNameTemporaryTableTypeCompatible = "TEMPORARY_TABLE_TYPE_COMPATIBLE"
+	AssessNameTemporalTableTypeCompatible  = "TEMPORAL_TABLE_TYPE_COMPATIBLE"
 
 	AssessNamePartitionTableCountsCheck = "PARTITION_TABLE_COUNTS_CHECK"
 	AssessNameTableColumnCountsCheck    = "TABLE_COLUMN_COUNTS_CHECK"
@@ -69,4 +71,30 @@ const (
 	AssessNameSchemaMaterializedViewRelated     = "SCHEMA_MATERIALIZED_VIEW_OBJECT_RELATED"
 	AssessNameSchemaTableAvgRowLengthTopRelated = "SCHEMA_TABLE_AVG_ROW_LENGTH_TOP_RELATED"
 	AssessNameSchemaTableNumberTypeEqual0       = "SCHEMA_TABLE_NUMBER_TYPE_EQUAL0"
+	AssessNameSchemaTablePurgeJobRelated        = "SCHEMA_TABLE_PURGE_JOB_RELATED"
+
+	AssessNameSchemaLargeTableMigrationAdvisory = "SCHEMA_LARGE_TABLE_MIGRATION_ADVISORY"
+)
+
+// Assess Advisory
+const (
+	// 大表迁移策略建议阈值，表段（含分区、子分区）大小，单位 GB
+	AssessAdvisoryLargeTableSizeGB = 50
+	// 分区交换暂存建议的最小分区数
+	AssessAdvisoryPartitionExchangeMinPartitions = 2
+
+	AssessAdvisoryStrategyPartitionExchange       = "PARTITION EXCHANGE STAGING"
+	AssessAdvisoryStrategyTransportableTablespace = "TRANSPORTABLE TABLESPACE"
+	AssessAdvisoryStrategyRowIDParallel           = "ROWID PARALLEL EXTRACT"
+)
+
+// Assess Dashboard
+const (
+	// 单表风险分值达到阈值划分风险等级
+	AssessDashboardRiskHighScore   = 5
+	AssessDashboardRiskMediumScore = 3
+
+	AssessDashboardRiskHigh   = "HIGH"
+	AssessDashboardRiskMedium = "MEDIUM"
+	AssessDashboardRiskLow    = "LOW"
 )
@@ -0,0 +1,182 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"bytes"
+	"sync"
+)
+
+const (
+	// 缓冲池归还上限，超出不再复用，避免超大 batch 长期占用内存
+	bufferPoolMaxBytes = 16 << 20
+	// 字节 arena 单块默认大小
+	byteArenaChunkBytes = 64 << 10
+)
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// GetBuffer 从缓冲池获取 buffer，使用完毕需 PutBuffer 归还
+func GetBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// PutBuffer 归还 buffer
+func PutBuffer(buf *bytes.Buffer) {
+	if buf == nil || buf.Cap() > bufferPoolMaxBytes {
+		return
+	}
+	bufferPool.Put(buf)
+}
+
+// ByteArena 字节 arena，同一批次字段值拷贝至连续大块内存，替代逐值 make
+// 非并发安全，单个抽取协程内使用
+type ByteArena struct {
+	chunkBytes int
+	buf        []byte
+}
+
+func NewByteArena(chunkBytes int) *ByteArena {
+	if chunkBytes <= 0 {
+		chunkBytes = byteArenaChunkBytes
+	}
+	return &ByteArena{chunkBytes: chunkBytes}
+}
+
+// Copy 拷贝 b 至 arena 并返回拷贝，返回切片容量固定，append 不会覆盖相邻值
+func (a *ByteArena) Copy(b []byte) []byte {
+	if cap(a.buf)-len(a.buf) < len(b) {
+		size := a.chunkBytes
+		if len(b) > size {
+			size = len(b)
+		}
+		a.buf = make([]byte, 0, size)
+	}
+	start := len(a.buf)
+	a.buf = append(a.buf, b...)
+	return a.buf[start:len(a.buf):len(a.buf)]
+}
+
+// Reset 复用当前内存块，仅适用于此前拷贝值已不再引用的场景（例如逐行写入 CSV）
+func (a *ByteArena) Reset() {
+	a.buf = a.buf[:0]
+}
+
+// RowBatch 列式批次缓冲，批次内全部行的字段值共用一块连续 []interface{}
+// 按行切分返回 RowValue，避免逐行分配
+type RowBatch struct {
+	columns int
+	values  []interface{}
+	rows    []RowValue
+}
+
+func NewRowBatch(columns, batchSize int) *RowBatch {
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	return &RowBatch{
+		columns: columns,
+		values:  make([]interface{}, 0, columns*batchSize),
+		rows:    make([]RowValue, 0, batchSize),
+	}
+}
+
+// Append 追加一行，row 长度需与字段数一致
+func (b *RowBatch) Append(row []interface{}) {
+	start := len(b.values)
+	b.values = append(b.values, row...)
+	b.rows = append(b.rows, b.values[start:len(b.values):len(b.values)])
+}
+
+func (b *RowBatch) Len() int {
+	return len(b.rows)
+}
+
+// Rows 返回批次数据行，返回后批次缓冲重新分配，已返回数据行不受后续 Append 影响
+func (b *RowBatch) Rows() []RowValue {
+	rows := b.rows
+	batchSize := cap(b.rows)
+	b.values = make([]interface{}, 0, b.columns*batchSize)
+	b.rows = make([]RowValue, 0, batchSize)
+	return rows
+}
+
+// AdaptiveBatcher 按目标字节数切分 batch，抽取时累计编码后行字节数，达到目标字节数或行数上限即成批
+// 宽表批次行数自动减小避免超出 max_allowed_packet，窄表批次行数放大减少写入语句数
+type AdaptiveBatcher struct {
+	targetBytes int
+	maxRows     int
+	batchBytes  int
+	batchRows   int
+	totalBytes  int64
+	totalRows   int64
+}
+
+func NewAdaptiveBatcher(targetBytes, maxRows int) *AdaptiveBatcher {
+	return &AdaptiveBatcher{
+		targetBytes: targetBytes,
+		maxRows:     maxRows,
+	}
+}
+
+// Add 记录一行，返回当前批次是否已满
+func (b *AdaptiveBatcher) Add(row []interface{}) bool {
+	size := EncodedRowBytes(row)
+	b.batchBytes += size
+	b.batchRows++
+	b.totalBytes += int64(size)
+	b.totalRows++
+	return b.batchBytes >= b.targetBytes || (b.maxRows > 0 && b.batchRows >= b.maxRows)
+}
+
+// Reset 批次写出后重新计数
+func (b *AdaptiveBatcher) Reset() {
+	b.batchBytes = 0
+	b.batchRows = 0
+}
+
+// AvgRowBytes 已抽取行平均编码字节数
+func (b *AdaptiveBatcher) AvgRowBytes() int64 {
+	if b.totalRows == 0 {
+		return 0
+	}
+	return b.totalBytes / b.totalRows
+}
+
+// EncodedRowBytes 估算行拼接为 VALUES 后字节数，字符值按两侧引号、字段按分隔符追加
+func EncodedRowBytes(row []interface{}) int {
+	size := 2
+	for _, val := range row {
+		switch v := val.(type) {
+		case nil:
+			size += 4
+		case []byte:
+			size += len(v) + 2
+		case string:
+			size += len(v) + 2
+		default:
+			size += 20
+		}
+		size++
+	}
+	return size
+}
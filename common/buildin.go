@@ -361,4 +361,20 @@ const (
 
 	BuildInOracleTemporaryTypeSession     = "SYS$SESSION"
 	BuildInOracleTemporaryTypeTransaction = "SYS$TRANSACTION"
+
+	BuildInOracleTemporalTypeFlashbackArchive = "FLASHBACK ARCHIVE"
+	BuildInOracleTemporalTypeValidity         = "TEMPORAL VALIDITY"
+)
+
+// Oracle 内置黑名单对象，filter 通配符规则
+const (
+	BuildInOracleBlacklistRecycleBin   = `BIN\$*`
+	BuildInOracleBlacklistMViewLog     = `MLOG\$_*`
+	BuildInOracleBlacklistMViewRupd    = `RUPD\$_*`
+	BuildInOracleBlacklistIOTOverflow  = `SYS_IOT_OVER_*`
+	BuildInOracleBlacklistFBA          = `SYS_FBA_*`
+	BuildInOracleBlacklistTypeRecycle  = "RECYCLEBIN"
+	BuildInOracleBlacklistTypeMViewLog = "MVIEW LOG"
+	BuildInOracleBlacklistTypeIOT      = "IOT OVERFLOW"
+	BuildInOracleBlacklistTypeFBA      = "FLASHBACK ARCHIVE"
 )
@@ -0,0 +1,157 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"fmt"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/transform"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 字符集转换支持字符集，Oracle 字符集名与通用字符集名统一映射
+var charsetEncodingMap = map[string]encoding.Encoding{
+	"UTF8":         encoding.Nop,
+	"UTF8MB4":      encoding.Nop,
+	"AL32UTF8":     encoding.Nop,
+	"GBK":          simplifiedchinese.GBK,
+	"ZHS16GBK":     simplifiedchinese.GBK,
+	"GB18030":      simplifiedchinese.GB18030,
+	"ZHS32GB18030": simplifiedchinese.GB18030,
+	"BIG5":         traditionalchinese.Big5,
+	"ZHT16BIG5":    traditionalchinese.Big5,
+}
+
+// CharsetConverter 字符字段字符集转换，按源端字符集解码后按目标端字符集编码
+// 为 nil 时表示无需转换
+type CharsetConverter struct {
+	SourceCharset string
+	TargetCharset string
+	source        encoding.Encoding
+	target        encoding.Encoding
+}
+
+// NewCharsetConverter 源端或目标端未配置、或上下游字符集相同时返回 nil
+func NewCharsetConverter(sourceCharset, targetCharset string) (*CharsetConverter, error) {
+	if sourceCharset == "" || targetCharset == "" {
+		return nil, nil
+	}
+	source, ok := charsetEncodingMap[StringUPPER(sourceCharset)]
+	if !ok {
+		return nil, fmt.Errorf("source charset [%s] isn't support, support charset [%s]", sourceCharset, strings.Join(supportCharsets(), ","))
+	}
+	target, ok := charsetEncodingMap[StringUPPER(targetCharset)]
+	if !ok {
+		return nil, fmt.Errorf("target charset [%s] isn't support, support charset [%s]", targetCharset, strings.Join(supportCharsets(), ","))
+	}
+	if source == target {
+		return nil, nil
+	}
+	return &CharsetConverter{
+		SourceCharset: StringUPPER(sourceCharset),
+		TargetCharset: StringUPPER(targetCharset),
+		source:        source,
+		target:        target,
+	}, nil
+}
+
+// Convert 转换器非并发安全，每次转换新建
+func (c *CharsetConverter) Convert(raw []byte) ([]byte, error) {
+	if c == nil || len(raw) == 0 {
+		return raw, nil
+	}
+	val, _, err := transform.Bytes(transform.Chain(c.source.NewDecoder(), c.target.NewEncoder()), raw)
+	if err != nil {
+		return nil, fmt.Errorf("charset [%s] convert to [%s] failed: %v", c.SourceCharset, c.TargetCharset, err)
+	}
+	return val, nil
+}
+
+// IsCharsetConvertColumn 仅字符类型字段参与字符集转换，RAW/BLOB 等二进制字段原样输出
+func IsCharsetConvertColumn(databaseTypeName string) bool {
+	switch StringUPPER(databaseTypeName) {
+	case "CHAR", "NCHAR", "VARCHAR", "VARCHAR2", "NVARCHAR2", "CLOB", "NCLOB", "LONG":
+		return true
+	default:
+		return false
+	}
+}
+
+// CSVCharset 字符集对应 CSV 文件字符集
+func (c *CharsetConverter) CSVCharset() string {
+	switch c.target {
+	case simplifiedchinese.GBK:
+		return GBKCharacterSetCSV
+	case simplifiedchinese.GB18030:
+		return GB18030CharacterSetCSV
+	case encoding.Nop:
+		return UTF8CharacterSetCSV
+	default:
+		return c.TargetCharset
+	}
+}
+
+func supportCharsets() []string {
+	var charsets []string
+	for c := range charsetEncodingMap {
+		charsets = append(charsets, c)
+	}
+	sort.Strings(charsets)
+	return charsets
+}
+
+// MySQL 字符集单字符最大字节数
+var mysqlCharsetMaxBytes = map[string]int{
+	"UTF8MB4": 4,
+	"UTF8":    3,
+	"UTF8MB3": 3,
+	"GBK":     2,
+	"GB18030": 4,
+	"BIG5":    2,
+	"LATIN1":  1,
+	"ASCII":   1,
+}
+
+// MySQLCharsetMaxBytes 未知字符集按 4 字节保守估算
+func MySQLCharsetMaxBytes(charset string) int {
+	if val, ok := mysqlCharsetMaxBytes[StringUPPER(charset)]; ok {
+		return val
+	}
+	return 4
+}
+
+// OracleColumnCharLength Oracle 字符字段可容纳字符数
+// CHAR_USED = C 字符语义取 CHAR_LENGTH，DATA_LENGTH 为字符数 × 库字符集单字符最大字节数；B 字节语义取 DATA_LENGTH（单字符至少 1 字节）
+func OracleColumnCharLength(charUsed, charLength, dataLength string) (int, error) {
+	length := dataLength
+	if strings.EqualFold(charUsed, "C") {
+		length = charLength
+	}
+	val, err := strconv.Atoi(length)
+	if err != nil {
+		return 0, fmt.Errorf("oracle column char_used [%s] length [%s] string to int failed: %v", charUsed, length, err)
+	}
+	return val, nil
+}
+
+// IsMySQLVarcharOverflow VARCHAR 字符数按字符集单字符最大字节数换算超出 65535 字节上限
+func IsMySQLVarcharOverflow(charLength int, charset string) bool {
+	return charLength*MySQLCharsetMaxBytes(charset) > MySQLVarcharMaxBytes
+}
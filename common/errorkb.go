@@ -0,0 +1,81 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"regexp"
+	"strings"
+)
+
+// 常见 ORA- / MySQL 错误码对应处理建议，写入 error_log_detail 以及日志、报告
+var errorKnowledgeBase = map[string]string{
+	"ORA-00054": "资源被占用，确认源端无长事务锁表或于业务低峰重跑",
+	"ORA-00942": "表或视图不存在，确认源端对象存在且迁移用户具备 SELECT ANY DICTIONARY / SELECT 权限",
+	"ORA-01013": "查询被取消或超时，调大 logminer-query-timeout 或降低 chunk 大小",
+	"ORA-01031": "权限不足，按文档授予迁移用户所需系统权限以及字典视图访问权限",
+	"ORA-01291": "logminer 缺少日志文件，确认归档日志未被删除并保留至同步位点",
+	"ORA-01555": "快照过旧，减小 chunk 大小（[full] chunk-size / [csv] rows）或调大 UNDO_RETENTION 以及 UNDO 表空间；开启 [full] consistent-read 或快照组时 UNDO_RETENTION 需覆盖整个全量耗时",
+	"ORA-01652": "临时表空间不足，扩容 TEMP 表空间或降低并发",
+	"ORA-01795": "IN 列表超过 1000 项，降低批次大小",
+	"ORA-03113": "连接中断，检查网络以及源端数据库告警日志后重跑",
+	"ORA-04031": "共享池内存不足，调大 SHARED_POOL_SIZE 或降低并发",
+	"ORA-08181": "指定 SCN 不是有效 SCN，确认 SCN 未超出闪回查询范围",
+	"ORA-12514": "监听未识别服务名，检查 [oracle] service-name 配置",
+	"ORA-12541": "无监听，检查 [oracle] host / port 配置以及源端监听状态",
+	"ORA-30052": "AS OF SCN 超出 UNDO 保留范围，调大 UNDO_RETENTION 或缩短全量耗时",
+	"1045":      "下游认证失败，检查 [mysql] username / password 配置",
+	"1062":      "主键或唯一键冲突，开启 safe-mode（REPLACE）或清理下游重复数据后重跑",
+	"1071":      "索引长度超出限制，开启前缀索引选项或调整字段长度",
+	"1105":      "TiDB 内部错误，查看下游日志；事务过大时降低 insert-batch-size",
+	"1118":      "行长度超出限制，调整字段类型为 TEXT/BLOB 或拆分表",
+	"1146":      "下游表不存在，先执行 reverse 生成并创建表结构",
+	"1153":      "单条 SQL 超出 max_allowed_packet，调大下游 max_allowed_packet 或降低 insert-batch-size",
+	"1205":      "锁等待超时，降低 apply-threads 并发或确认下游无长事务",
+	"1213":      "死锁，降低 apply-threads 并发后重跑",
+	"1264":      "数值超出字段范围，检查字段类型映射规则（datatype rule）",
+	"1366":      "字符值非法，检查上下游字符集以及 nls-lang 配置",
+	"1406":      "字符超出字段长度，检查字符集转换后长度或调整字段长度",
+	"8004":      "TiDB 事务过大，调大 txn-total-size-limit 或降低 insert-batch-size",
+}
+
+var (
+	oraErrorRegex   = regexp.MustCompile(`ORA-\d{5}`)
+	mysqlErrorRegex = regexp.MustCompile(`(?i)error (\d{4})`)
+)
+
+// ErrorSuggestion 按错误信息中的错误码匹配处理建议，多个错误码建议以分号拼接，未命中返回空
+func ErrorSuggestion(errMsg string) string {
+	var codes []string
+	codes = append(codes, oraErrorRegex.FindAllString(errMsg, -1)...)
+	for _, m := range mysqlErrorRegex.FindAllStringSubmatch(errMsg, -1) {
+		codes = append(codes, m[1])
+	}
+
+	var (
+		seen     []string
+		suggests []string
+	)
+	for _, c := range codes {
+		if IsContainString(seen, c) {
+			continue
+		}
+		seen = append(seen, c)
+		if suggest, ok := errorKnowledgeBase[c]; ok {
+			suggests = append(suggests, StringsBuilder(c, ": ", suggest))
+		}
+	}
+	return strings.Join(suggests, "; ")
+}
@@ -15,10 +15,205 @@ limitations under the License.
 */
 package common
 
+import "time"
+
 // 数据全量/实时同步 Oracle 版本要求
 // 要求 oracle 11g 及以上
 const RequireOracleDBVersion = "11"
 
+// chunk 行数校准统计信息缺失时抽样行数
+const MigrateChunkCalibrateSampleRows = 10000
+
+// 增量行数漂移监控每表保留趋势次数
+const MigrateDriftTrendSize = 5
+
+// LOB 大字段表预编译写入每批默认行数
+const MigrateLOBBatchSize = 10
+
+// compare 语言排序校验每字段默认抽样去重值个数
+const CompareSortSampleRows = 100
+
+// full chunk 切分方式
+// ROWID 使用 DBMS_PARALLEL_EXECUTE，需 CREATE JOB 权限；PK 按单列 NUMBER 主键 NTILE 区间切分
+// PARTITION 分区表按分区/子分区切分，非分区表按 ROWID 切分
+const (
+	MigrateChunkSplitModeRowID     = "ROWID"
+	MigrateChunkSplitModePK        = "PK"
+	MigrateChunkSplitModePartition = "PARTITION"
+)
+
+// full 下游写入冲突处理方式
+// INSERT 要求下游空表；INSERT-IGNORE 跳过冲突行；REPLACE 覆盖冲突行；UPSERT-ON-DUPLICATE-KEY 冲突行按上游更新
+const (
+	MigrateApplyModeInsert       = "INSERT"
+	MigrateApplyModeInsertIgnore = "INSERT-IGNORE"
+	MigrateApplyModeReplace      = "REPLACE"
+	MigrateApplyModeUpsert       = "UPSERT-ON-DUPLICATE-KEY"
+)
+
+// full 非一致性读表级 chunk 抽取 SCN 跨度告警阈值
+const MigrateSCNDriftThreshold = 1000000
+
+// full 下游写入协议
+// TEXT 拼接 SQL 字面量写入；PREPARE 预编译语句占位符绑定参数写入，同一 chunk 相同行数 batch 复用预编译语句
+const (
+	MigrateApplyProtocolText    = "TEXT"
+	MigrateApplyProtocolPrepare = "PREPARE"
+)
+
+// MySQL 单条预编译语句占位符上限
+const MigrateMySQLMaxPlaceholders = 65535
+
+// full 下游磁盘空间预检查，OFF 不检查，WARN 空间不足告警继续，ERROR 空间不足拒绝运行
+const (
+	MigrateDiskPrecheckOff   = "OFF"
+	MigrateDiskPrecheckWarn  = "WARN"
+	MigrateDiskPrecheckError = "ERROR"
+)
+
+// 下游磁盘空间预估默认膨胀系数，数据按 utf8mb4 以及 InnoDB 页开销，索引按 B+ 树开销
+const (
+	MigrateDataExpansionFactor  = 1.5
+	MigrateIndexExpansionFactor = 1.2
+)
+
+// TiDB 无法获取 PD 副本数配置时默认副本数
+const TiDBDefaultMaxReplicas = 3
+
+// compare chunk 行校验和算法
+const (
+	CompareChecksumCRC32   = "CRC32"
+	CompareChecksumAdler32 = "ADLER32"
+)
+
+// csv 导出每表清单文件名，用于 load 模式文件导入
+const MigrateCSVManifestFile = "manifest.json"
+
+// csv 文件写入缓冲默认大小
+const MigrateCSVBufferSize = 4 << 20
+
+// csv 文件写入临时文件后缀，写入完成后原子 rename
+const MigrateCSVTempFileSuffix = ".tmp"
+
+// csv 文件 fsync 策略
+const (
+	MigrateCSVFsyncPolicyNone  = "NONE"
+	MigrateCSVFsyncPolicyClose = "CLOSE"
+	MigrateCSVFsyncPolicyFlush = "FLUSH"
+)
+
+// 目标表数据清理方式
+// 1、TRUNCATE 直接 TRUNCATE TABLE
+// 2、DELETE 分批 DELETE，适用于托管服务限制 TRUNCATE 或 TRUNCATE 影响复制场景
+// 3、AUTO 优先 TRUNCATE，权限不足时回退分批 DELETE
+const (
+	MigrateTargetCleanModeTruncate = "TRUNCATE"
+	MigrateTargetCleanModeDelete   = "DELETE"
+	MigrateTargetCleanModeAuto     = "AUTO"
+)
+
+// 目标表分批 DELETE 清理默认单批行数，控制单事务 binlog 大小
+const MigrateTargetCleanBatchSize = 1000
+
+// 分批 DELETE 进度日志输出间隔
+const MigrateDeleteProgressInterval = 30 * time.Second
+
+// csv 文件压缩格式，文件名追加对应后缀，与 TiDB Lightning 支持的压缩输入一致
+const (
+	MigrateCSVCompressGzip   = "GZIP"
+	MigrateCSVCompressZstd   = "ZSTD"
+	MigrateCSVCompressSnappy = "SNAPPY"
+)
+
+// full batch-bytes 自适应 batch 行数上限倍数，相对 insert-batch-size
+const MigrateAdaptiveBatchMaxFactor = 10
+
+// TiDB 目标表预切分 region，每 region 默认行数以及单表最大 region 数
+const (
+	MigrateTiDBPreSplitRegionRows = 1000000
+	MigrateTiDBPreSplitMaxRegions = 1000
+)
+
+// csv 方言，按目标导入工具预置引用、转义、NULL 字面量以及行尾默认值
+// 1、LIGHTNING 默认，与 TiDB Lightning 一致
+// 2、MYSQL 适配 LOAD DATA INFILE，NULL 输出 \N，反斜杠转义
+// 3、SNOWFLAKE / DUCKDB / GREENPLUM 适配 COPY，RFC 4180 按需引用，引号内双写引号转义，NULL 输出空值
+// 4、CLICKHOUSE 适配 FORMAT CSV，RFC 4180 按需引用，引号内双写引号转义，NULL 输出 \N
+const (
+	MigrateCSVDialectLightning  = "LIGHTNING"
+	MigrateCSVDialectMySQL      = "MYSQL"
+	MigrateCSVDialectSnowflake  = "SNOWFLAKE"
+	MigrateCSVDialectDuckDB     = "DUCKDB"
+	MigrateCSVDialectClickHouse = "CLICKHOUSE"
+	MigrateCSVDialectGreenplum  = "GREENPLUM"
+)
+
+// 分析型目标端默认建表选项
+// 1、ClickHouse 表引擎，ORDER BY 取源端主键字段，无主键 ORDER BY tuple()
+// 2、Greenplum 追加优化列存压缩表，DISTRIBUTED BY 取源端主键字段，无主键 DISTRIBUTED RANDOMLY
+const (
+	AnalyticClickHouseDefaultEngine      = "MergeTree"
+	AnalyticGreenplumDefaultTableOptions = "APPENDONLY=TRUE, ORIENTATION=COLUMN, COMPRESSTYPE=ZLIB, COMPRESSLEVEL=5"
+)
+
+// csv 字符串引用方式
+// 1、ALWAYS 字符串字段均加引用定界符
+// 2、MINIMAL 仅包含分隔符、定界符、换行或空字符串时加引用定界符
+// 3、NONE 不加引用定界符
+const (
+	MigrateCSVQuoteStyleAlways  = "ALWAYS"
+	MigrateCSVQuoteStyleMinimal = "MINIMAL"
+	MigrateCSVQuoteStyleNone    = "NONE"
+)
+
+// csv UTF-8 BOM 文件头
+const MigrateCSVUTF8BOM = "\xEF\xBB\xBF"
+
+// csv 对象存储输出，output-dir 以 s3:// 或 oss:// 开头时 chunk 文件直接分片上传
+const (
+	MigrateCSVStorageSchemeS3  = "s3://"
+	MigrateCSVStorageSchemeOSS = "oss://"
+)
+
+// 对象存储分片上传默认分片大小，S3 协议分片最小 5MiB、最多 10000 片
+const (
+	MigrateCSVStoragePartSize    = 16 << 20
+	MigrateCSVStorageMinPartSize = 5 << 20
+	MigrateCSVStorageMaxParts    = 10000
+)
+
+// 对象存储单次请求超时（秒）以及 5xx/429/SlowDown 等可重试错误默认重试次数
+const (
+	MigrateCSVStorageTimeout    = 300
+	MigrateCSVStorageRetryTimes = 5
+)
+
+// 物化视图日志增量消费默认间隔（秒）以及单次行数，单次行数受限 Oracle IN 列表 1000 上限
+const (
+	MigrateMVLogInterval     = 5
+	MigrateMVLogBatchSize    = 500
+	MigrateMVLogMaxBatchSize = 1000
+)
+
+// 增量捕获方式，logminer 之外的按表捕获
+const (
+	MigrateCaptureModeMVLog   = "MVLOG"
+	MigrateCaptureModeTrigger = "TRIGGER"
+)
+
+// 触发器捕获源端对象名前缀，后缀为源表 OBJECT_ID，避免超出 30 字符标识符限制
+const (
+	MigrateTriggerShadowTablePrefix = "TDB$CT_"
+	MigrateTriggerSequencePrefix    = "TDB$SQ_"
+	MigrateTriggerNamePrefix        = "TDB$TR_"
+)
+
+// ship 模式文件传输角色
+const (
+	MigrateShipRoleSender   = "SENDER"
+	MigrateShipRoleReceiver = "RECEIVER"
+)
+
 // Oracle Redo 同步操作类型
 const (
 	MigrateOperationUpdate   = "UPDATE"
@@ -36,3 +231,34 @@ const (
 // 当值 == 0 启用 filterOracleIncrRecord 大于或者等于逻辑
 // 当值 == 1 启用 filterOracleIncrRecord 大于逻辑，避免已被消费得日志一直被重复消费
 var MigrateCurrentResetFlag = 0
+
+// OGG Kafka 增量变更记录格式以及无断点时起始消费位点
+const (
+	OGGFormatJSON          = "JSON"
+	OGGFormatAvro          = "AVRO"
+	OGGStartOffsetEarliest = "EARLIEST"
+	OGGStartOffsetLatest   = "LATEST"
+)
+
+// OGG JSON 变更记录操作类型
+const (
+	OGGOpTypeInsert   = "I"
+	OGGOpTypeUpdate   = "U"
+	OGGOpTypeDelete   = "D"
+	OGGOpTypeTruncate = "T"
+)
+
+// full consistent-read 全局一致性快照组名，未归属快照组的表统一归入
+const MigrateSnapshotGroupGlobal = "GLOBAL"
+
+// 物理备库等待应用至主库 SCN 默认超时时间，单位秒
+const OracleStandbyApplyLagTimeout = 600
+
+// bench 模式未配置或配置非法（<= 0）时默认值
+const (
+	BenchChunkSize    = 100000
+	BenchReadThreads  = 32
+	BenchWriteThreads = 64
+	BenchBatchSize    = 100
+	BenchRowBytes     = 256
+)
@@ -0,0 +1,91 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TokenBucket 令牌桶限速器，rate 每秒令牌数，桶容量为 1 秒令牌数，多协程共享
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket rate <= 0 返回 nil 代表不限速
+func NewTokenBucket(rate float64) *TokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	return &TokenBucket{
+		rate:   rate,
+		tokens: rate,
+		last:   time.Now(),
+	}
+}
+
+// WaitN 获取 n 个令牌，不足时阻塞等待；n 超过桶容量时允许透支，由后续请求等待补足
+func (b *TokenBucket) WaitN(ctx context.Context, n float64) error {
+	if b == nil || n <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+	b.last = now
+	b.tokens -= n
+	var wait time.Duration
+	if b.tokens < 0 {
+		wait = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RowValueBytes 估算数据行字节数，用于带宽限速
+func RowValueBytes(row RowValue) int {
+	var size int
+	for _, val := range row {
+		switch v := val.(type) {
+		case nil:
+		case []byte:
+			size += len(v)
+		case string:
+			size += len(v)
+		default:
+			size += 8
+		}
+	}
+	return size
+}
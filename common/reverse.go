@@ -28,17 +28,63 @@ const (
 	MySQLVersionDelimiter = "-"
 	// MySQL 字符集
 	MySQLCharacterSet = "UTF8MB4"
+	// MySQL 标识符（索引名、约束名）最大长度，超出截断并追加哈希后缀
+	MySQLIdentifierMaxLength = 64
+	// MySQL VARCHAR 字段最大字节数，超出需转换为 TEXT 类
+	MySQLVarcharMaxBytes = 65535
+	// MySQL VARCHAR 超出最大字节数时转换类型
+	MySQLVarcharOverflowType = "MEDIUMTEXT"
+
+	// reverse 表数量达到阈值时按 schema 批量查询数据字典
+	ReverseDictionaryBatchThreshold = 100
+	// 数据字典缓存默认有效期，超过仅告警
+	OracleDictionaryCacheTTL = "24h"
 
 	// 允许 Oracle 表、字段 Collation
 	// 需要 oracle 12.2g 及以上
 	OracleTableColumnCollationDBVersion = "12.2"
 
+	// 允许 Oracle 表 Temporal Validity 有效期
+	// 需要 oracle 12c 及以上
+	OracleTemporalValidityDBVersion = "12"
+
+	// 允许 Oracle 基于版本的重定义（EBR）编辑视图、跨版本触发器
+	// 需要 oracle 11.2g 及以上
+	OracleEditionBasedRedefinitionDBVersion = "11.2"
+
+	// 允许 Oracle 标识列 GENERATED AS IDENTITY
+	// 需要 oracle 12c 及以上
+	OracleIdentityColumnDBVersion = "12"
+
+	// Oracle 临时表处理策略
+	// NORMAL 转换为普通表，TEMPORARY 转换为 MySQL 临时表脚本，SKIP 跳过
+	ReverseTemporaryTablePolicyNormal    = "NORMAL"
+	ReverseTemporaryTablePolicyTemporary = "TEMPORARY"
+	ReverseTemporaryTablePolicySkip      = "SKIP"
+
+	// reverse 决策类型，记录于 reverse_decision 用于多次 reverse 结果对比
+	ReverseDecisionTableName       = "TABLE_NAME"
+	ReverseDecisionTableCollation  = "TABLE_COLLATION"
+	ReverseDecisionColumnName      = "COLUMN_NAME"
+	ReverseDecisionColumnType      = "COLUMN_TYPE"
+	ReverseDecisionColumnDefault   = "COLUMN_DEFAULT"
+	ReverseDecisionColumnCollation = "COLUMN_COLLATION"
+	ReverseDecisionIdentifierName  = "IDENTIFIER_NAME"
+
+	// reverse 按对象类型拆分输出文件
+	ReverseSplitObjectSequence   = "sequence"
+	ReverseSplitObjectTable      = "table"
+	ReverseSplitObjectIndex      = "index"
+	ReverseSplitObjectConstraint = "constraint"
+	ReverseSplitObjectComment    = "comment"
+
 	// Oracle 用户、表、字段默认使用 DB 排序规则
 	OracleUserTableColumnDefaultCollation = "USING_NLS_COMP"
 
 	// CSV 字符集判断
-	UTF8CharacterSetCSV = "UTF8"
-	GBKCharacterSetCSV  = "GBK"
+	UTF8CharacterSetCSV    = "UTF8"
+	GBKCharacterSetCSV     = "GBK"
+	GB18030CharacterSetCSV = "GB18030"
 
 	// Struct JSON 格式化 -> Check 阶段
 	JSONColumns      = "COLUMN"
@@ -47,8 +93,12 @@ const (
 	JSONFKConstraint = "FK"
 	JSONCKConstraint = "CK"
 	JSONPartition    = "PARTITION"
+	JSONTrigger      = "TRIGGER"
 )
 
+// reverse 拆分文件执行顺序，序列、表结构创建后导入数据，再创建索引、约束以及注释
+var ReverseSplitObjects = []string{ReverseSplitObjectSequence, ReverseSplitObjectTable, ReverseSplitObjectIndex, ReverseSplitObjectConstraint, ReverseSplitObjectComment}
+
 /*
 O2M/T Oracle Reverse MySQL/TiDB
 */
@@ -57,6 +107,26 @@ const (
 	TiDBClusteredIndexIntOnlyValue = "INT_ONLY"
 	TiDBClusteredIndexONValue      = "ON"
 	TiDBClusteredIndexOFFValue     = "OFF"
+
+	// TiDB TTL 表属性版本 >= 6.5.0
+	TiDBTableTTLVersion = "6.5.0"
+	// TiDB 版本分隔符号，例如 5.7.25-TiDB-v6.5.0
+	TiDBVersionDelimiter = "-TiDB-v"
+)
+
+/*
+O2M MariaDB Oracle Reverse MariaDB
+*/
+const (
+	// MariaDB 版本标识，例如 10.6.12-MariaDB-log，旧版本客户端握手版本带 5.5.5- 前缀
+	MariaDBVersionDelimiter = "-MariaDB"
+	MariaDBVersionPrefix    = "5.5.5-"
+	// MariaDB 强制校验 check 约束版本 > 10.2.0
+	MariaDBCheckConsVersion = "10.2.0"
+	// MariaDB 序列版本 >= 10.3.0
+	MariaDBSequenceVersion = "10.3.0"
+	// MariaDB utf8mb4_uca1400_as_ci 排序规则版本 >= 10.10.1，低版本不存在区分重音、不区分大小写排序规则
+	MariaDBUCA1400CollationVersion = "10.10.1"
 )
 
 // alter-primary-key = fase 主键整型数据类型列表
@@ -81,6 +151,16 @@ var OracleCollationMap = map[string]string{
 	"BINARY": "utf8mb4_bin",
 }
 
+// MariaDB 不支持 MySQL 8.0 utf8mb4_0900 排序规则，按版本映射
+var MariaDBCollationMap = map[string]string{
+	"utf8mb4_0900_as_ci": "utf8mb4_uca1400_as_ci",
+}
+
+// MariaDB 低版本 utf8mb4_0900 排序规则降级，区分重音语义丢失
+var MariaDBLowVersionCollationMap = map[string]string{
+	"utf8mb4_0900_as_ci": "utf8mb4_general_ci",
+}
+
 // ORACLE 字符集映射规则
 var OracleDBCharacterSetMap = map[string]string{
 	"AL32UTF8":  "UTF8MB4",
@@ -0,0 +1,157 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// NameRewriter 表名/字段名正则改写规则，按配置顺序首个匹配规则生效，匹配忽略大小写
+type NameRewriter struct {
+	rules []nameRewriteRule
+}
+
+type nameRewriteRule struct {
+	pattern *regexp.Regexp
+	replace string
+	merge   bool
+}
+
+func NewNameRewriter() *NameRewriter {
+	return &NameRewriter{}
+}
+
+// AddRule replace 支持 $1 / ${name} 分组引用，merge 表示允许多个源端对象改写为同一目标名
+func (n *NameRewriter) AddRule(pattern, replace string, merge bool) error {
+	re, err := regexp.Compile(`(?i)` + pattern)
+	if err != nil {
+		return fmt.Errorf("rewrite rule pattern [%s] compile failed: %v", pattern, err)
+	}
+	n.rules = append(n.rules, nameRewriteRule{
+		pattern: re,
+		replace: replace,
+		merge:   merge,
+	})
+	return nil
+}
+
+// Rewrite 返回改写后名称（大写）以及命中规则是否允许合并，未命中返回原名称
+func (n *NameRewriter) Rewrite(name string) (string, bool, bool) {
+	if n == nil {
+		return name, false, false
+	}
+	for _, r := range n.rules {
+		if r.pattern.MatchString(name) {
+			return StringUPPER(r.pattern.ReplaceAllString(name, r.replace)), r.merge, true
+		}
+	}
+	return name, false, false
+}
+
+// RewriteQuotedColumns 反引号包裹字段名批量改写，去除反引号改写后重新包裹，未配置规则时原样返回
+func (n *NameRewriter) RewriteQuotedColumns(columns []string) []string {
+	if n == nil || len(n.rules) == 0 {
+		return columns
+	}
+	newColumns := make([]string, 0, len(columns))
+	for _, c := range columns {
+		newName, _, _ := n.Rewrite(strings.Trim(c, "`"))
+		newColumns = append(newColumns, StringsBuilder("`", newName, "`"))
+	}
+	return newColumns
+}
+
+// GenNameRewriteMap 源端名称 -> 目标名称映射，explicit 显式规则优先，其余名称按正则改写
+// 多个源端映射为同一目标名且并非全部来自 merge 规则时视为冲突
+func GenNameRewriteMap(explicit map[string]string, sources []string, rewriter *NameRewriter) (map[string]string, error) {
+	nameMap := make(map[string]string)
+	mergeMap := make(map[string]bool)
+	for s, t := range explicit {
+		nameMap[StringUPPER(s)] = StringUPPER(t)
+	}
+	for _, s := range sources {
+		upperS := StringUPPER(s)
+		if _, ok := nameMap[upperS]; ok {
+			continue
+		}
+		newName, merge, ok := rewriter.Rewrite(upperS)
+		if !ok {
+			continue
+		}
+		nameMap[upperS] = newName
+		mergeMap[upperS] = merge
+	}
+
+	// 冲突检测，未出现在映射中的源端名称按原名参与
+	targets := make(map[string][]string)
+	for s, t := range nameMap {
+		targets[t] = append(targets[t], s)
+	}
+	for _, s := range sources {
+		upperS := StringUPPER(s)
+		if _, ok := nameMap[upperS]; !ok {
+			targets[upperS] = append(targets[upperS], upperS)
+		}
+	}
+
+	var conflicts []string
+	for t, ss := range targets {
+		if len(ss) <= 1 {
+			continue
+		}
+		allMerge := true
+		for _, s := range ss {
+			if !mergeMap[s] {
+				allMerge = false
+				break
+			}
+		}
+		if !allMerge {
+			sort.Strings(ss)
+			conflicts = append(conflicts, fmt.Sprintf("[%s] -> [%s]", strings.Join(ss, ","), t))
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return nameMap, fmt.Errorf("name rewrite rule target conflict: %s", strings.Join(conflicts, "; "))
+	}
+	return nameMap, nil
+}
+
+// DistinctRewriteTargets 多个源端合并为同一目标时仅保留排序后首个源端，用于表结构转换等只需生成一次目标对象的场景
+func DistinctRewriteTargets(sources []string, nameMap map[string]string) []string {
+	sorted := make([]string, len(sources))
+	copy(sorted, sources)
+	sort.Strings(sorted)
+
+	seen := make(map[string]struct{})
+	var distinct []string
+	for _, s := range sorted {
+		target := StringUPPER(s)
+		if val, ok := nameMap[target]; ok {
+			target = val
+		}
+		if _, ok := seen[target]; ok {
+			continue
+		}
+		seen[target] = struct{}{}
+		distinct = append(distinct, s)
+	}
+	return distinct
+}
@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"golang.org/x/text/encoding/simplifiedchinese"
 	"golang.org/x/text/transform"
+	"hash/adler32"
+	"hash/crc32"
 	"io/ioutil"
 	"os"
 	"reflect"
@@ -34,6 +36,27 @@ import (
 	"github.com/thinkeridea/go-extend/exbytes"
 )
 
+// ParseStoreSize 解析 TiDB 存储容量字符串，如 467.6GiB，返回字节数
+func ParseStoreSize(size string) (float64, error) {
+	size = strings.TrimSpace(size)
+	units := []struct {
+		suffix string
+		bytes  float64
+	}{
+		{"PiB", 1 << 50}, {"TiB", 1 << 40}, {"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10}, {"B", 1},
+	}
+	for _, u := range units {
+		if strings.HasSuffix(size, u.suffix) {
+			val, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(size, u.suffix)), 64)
+			if err != nil {
+				return 0, fmt.Errorf("parse store size [%s] failed: %v", size, err)
+			}
+			return val * u.bytes, nil
+		}
+	}
+	return strconv.ParseFloat(size, 64)
+}
+
 // 是否空字符串
 func IsEmptyString(str string) bool {
 	return str == "null" || strings.TrimSpace(str) == ""
@@ -408,28 +431,26 @@ func Utf8ToGbk(s []byte) ([]byte, error) {
 判断是否为中文：unicode.Han(v)
 */
 func SpecialLettersUsingMySQL(bs []byte) string {
+	buf := GetBuffer()
+	defer PutBuffer(buf)
+	AppendSpecialLettersUsingMySQL(buf, bs)
+	return buf.String()
+}
 
-	var (
-		b     strings.Builder
-		chars []rune
-	)
-	for _, r := range bytes.Runes(bs) {
+// AppendSpecialLettersUsingMySQL 特殊字符转义直接追加至 buf，避免中间 rune 切片
+func AppendSpecialLettersUsingMySQL(buf *bytes.Buffer, bs []byte) {
+	for len(bs) > 0 {
+		r, size := utf8.DecodeRune(bs)
+		bs = bs[size:]
 		if unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r) {
 			// mysql/tidb % 字符, /% 代表 /%，% 代表 % ,无需转义
 			// mysql/tidb _ 字符, /_ 代表 /_，_ 代表 _ ,无需转义
-			if r == '%' || r == '_' {
-				chars = append(chars, r)
-			} else {
-				chars = append(chars, '\\', r)
+			if r != '%' && r != '_' {
+				buf.WriteByte('\\')
 			}
-		} else {
-			chars = append(chars, r)
 		}
+		buf.WriteRune(r)
 	}
-
-	b.WriteString(string(chars))
-
-	return b.String()
 }
 
 func SpecialLettersUsingOracle(bs []byte) string {
@@ -466,3 +487,78 @@ func PathExist(path string) error {
 	}
 	return err
 }
+
+// IsOracleLinguisticSort 判断 ORACLE collation/nls_sort 是否为语言排序
+// BINARY 系列排序可映射 MySQL collation，其余如 SCHINESE_PINYIN_M、GENERIC_M 按语言规则排序，MySQL 无等价 collation
+func IsOracleLinguisticSort(collation string) bool {
+	collation = strings.ToUpper(strings.TrimSpace(collation))
+	if collation == "" || collation == "USING_NLS_COMP" {
+		return false
+	}
+	if _, ok := OracleCollationMap[collation]; ok {
+		return false
+	}
+	return true
+}
+
+// OracleCollationToMySQL ORACLE collation 映射目标端 collation，MariaDB 不支持 utf8mb4_0900 排序规则按版本改写
+func OracleCollationToMySQL(targetDBType, targetDBVersion, collation string) (string, bool) {
+	val, ok := OracleCollationMap[strings.ToUpper(strings.TrimSpace(collation))]
+	if !ok || !strings.EqualFold(targetDBType, DatabaseTypeMariaDB) {
+		return val, ok
+	}
+	if VersionOrdinal(targetDBVersion) >= VersionOrdinal(MariaDBUCA1400CollationVersion) {
+		if v, exist := MariaDBCollationMap[val]; exist {
+			return v, true
+		}
+		return val, true
+	}
+	if v, exist := MariaDBLowVersionCollationMap[val]; exist {
+		return v, true
+	}
+	return val, true
+}
+
+// MariaDBVersion 截取 MariaDB 版本号，如 5.5.5-10.6.12-MariaDB-log -> 10.6.12
+func MariaDBVersion(version string) string {
+	version = strings.TrimPrefix(version, MariaDBVersionPrefix)
+	if strings.Contains(version, MySQLVersionDelimiter) {
+		return strings.Split(version, MySQLVersionDelimiter)[0]
+	}
+	return version
+}
+
+// IsMariaDBVersion 版本字符串是否为 MariaDB
+func IsMariaDBVersion(version string) bool {
+	return strings.Contains(StringUPPER(version), StringUPPER(MariaDBVersionDelimiter))
+}
+
+// RowChecksum 数据行校验和，默认 CRC32，chunk 内各行累加，与行顺序无关
+func RowChecksum(algo string, row []byte) uint32 {
+	if algo == CompareChecksumAdler32 {
+		return adler32.Checksum(row)
+	}
+	return crc32.ChecksumIEEE(row)
+}
+
+// TruncateIdentifier 标识符超出 maxLen 时保留前缀并追加源名 CRC32 后缀，同一源名生成结果固定
+func TruncateIdentifier(name string, maxLen int) string {
+	if len(name) <= maxLen {
+		return name
+	}
+	return HashSuffixIdentifier(name, name, maxLen)
+}
+
+// HashSuffixIdentifier 以 hashKey 的 CRC32 作为后缀重新生成 name，总长度不超过 maxLen
+func HashSuffixIdentifier(name, hashKey string, maxLen int) string {
+	suffix := fmt.Sprintf("_%08X", crc32.ChecksumIEEE([]byte(hashKey)))
+	prefixLen := maxLen - len(suffix)
+	if len(name) < prefixLen {
+		prefixLen = len(name)
+	}
+	// 截断位置回退至字符边界，避免截断多字节字符
+	for prefixLen > 0 && prefixLen < len(name) && !utf8.RuneStart(name[prefixLen]) {
+		prefixLen--
+	}
+	return StringsBuilder(name[:prefixLen], suffix)
+}
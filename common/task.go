@@ -25,21 +25,92 @@ const (
 	MySQLConnMaxIdleTime = 200 * time.Second
 )
 
+// MySQL 目标端托管形态，托管实例无 SUPER 权限且限制连接数
+const (
+	MySQLFlavorAuto      = "AUTO"
+	MySQLFlavorCommunity = "COMMUNITY"
+	MySQLFlavorRDS       = "RDS"
+	MySQLFlavorAurora    = "AURORA"
+	// 托管实例连接池上限占连接配额比例，预留运维以及元数据库连接
+	MySQLManagedConnRatio = 0.8
+)
+
 // 任务并发通道 Channle Size
 const ChannelBufferSize = 1024
 
 // 任务模式
 const (
-	TaskModePrepare = "PREPARE"
-	TaskModeAssess  = "ASSESS"
-	TaskModeReverse = "REVERSE"
-	TaskModeCheck   = "CHECK"
-	TaskModeCompare = "COMPARE"
-	TaskModeCSV     = "CSV"
-	TaskModeFull    = "FULL"
-	TaskModeAll     = "ALL"
+	TaskModePrepare   = "PREPARE"
+	TaskModeAssess    = "ASSESS"
+	TaskModeReverse   = "REVERSE"
+	TaskModeCheck     = "CHECK"
+	TaskModeCompare   = "COMPARE"
+	TaskModeCSV       = "CSV"
+	TaskModeFull      = "FULL"
+	TaskModeAll       = "ALL"
+	TaskModeReload    = "RELOAD"
+	TaskModeBench     = "BENCH"
+	TaskModeLoad      = "LOAD"
+	TaskModeLightning = "LIGHTNING"
+	TaskModeAnalytic  = "ANALYTIC"
+	TaskModeShip      = "SHIP"
+	TaskModeVerify    = "VERIFY"
+	TaskModeRollback  = "ROLLBACK"
+	TaskModeUninstall = "UNINSTALL"
+	TaskModeResume    = "RESUME"
+	TaskModeOGG       = "OGG"
+	TaskModeMeta      = "META"
+)
+
+// 任务编排阶段，--pipeline 按顺序执行
+// SCHEMA: reverse + check，DATA: full，VERIFY: compare
+const (
+	PipelineStageSchema = "SCHEMA"
+	PipelineStageData   = "DATA"
+	PipelineStageVerify = "VERIFY"
+)
+
+// 元数据快照导出导入
+const (
+	MetaActionExport = "EXPORT"
+	MetaActionImport = "IMPORT"
+)
+
+// 表级错误处理策略
+const (
+	TaskErrorPolicyAbort    = "ABORT"
+	TaskErrorPolicyContinue = "CONTINUE"
+)
+
+// 任务钩子范围以及执行阶段
+const (
+	TaskHookScopeTask   = "TASK"
+	TaskHookScopeTable  = "TABLE"
+	TaskHookStageBefore = "BEFORE"
+	TaskHookStageAfter  = "AFTER"
+	TaskHookTypeShell   = "SHELL"
+	TaskHookTypeSQL     = "SQL"
 )
 
+// check 修复 SQL 执行阶段，按切片顺序执行：表属性、字段、主键唯一键、索引、检查约束、外键
+const (
+	CheckFixStageTable      = "TABLE"
+	CheckFixStageColumn     = "COLUMN"
+	CheckFixStageKey        = "KEY"
+	CheckFixStageIndex      = "INDEX"
+	CheckFixStageCheck      = "CHECK"
+	CheckFixStageForeignKey = "FOREIGN"
+)
+
+var CheckFixStages = []string{
+	CheckFixStageTable,
+	CheckFixStageColumn,
+	CheckFixStageKey,
+	CheckFixStageIndex,
+	CheckFixStageCheck,
+	CheckFixStageForeignKey,
+}
+
 // 任务状态
 const (
 	TaskStatusWaiting = "WAITING"
@@ -61,4 +132,21 @@ const (
 	DatabaseTypeOracle = "ORACLE"
 	DatabaseTypeTiDB   = "TIDB"
 	DatabaseTypeMySQL  = "MYSQL"
+	DatabaseTypeDM     = "DM"
+	// MariaDB 复用 MySQL 链路，db-type-t = mariadb 时归一为 db-type-t = mysql 以及 [mysql] db-type = mariadb
+	DatabaseTypeMariaDB = "MARIADB"
+	// openGauss 以及兼容发行版 MogDB
+	DatabaseTypeOpenGauss = "OPENGAUSS"
+	// 分析型目标端，仅 analytic 模式 csv 导出 + 原生批量导入
+	DatabaseTypeClickHouse = "CLICKHOUSE"
+	DatabaseTypeGreenplum  = "GREENPLUM"
+	// DB2 LUW 源端，仅 assess/reverse 模式，目标端 MySQL/TiDB
+	DatabaseTypeDB2 = "DB2"
+)
+
+// 数据库连接隧道类型
+const (
+	TunnelTypeSSH    = "SSH"
+	TunnelTypeSOCKS5 = "SOCKS5"
+	TunnelTypeHTTP   = "HTTP"
 )
@@ -0,0 +1,131 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"strconv"
+)
+
+// RowValue 上游类型化数据行
+// 抽取时按字段扫描类型解析一次，SQL 字面量、CSV 等渲染由各写入端负责
+type RowValue []interface{}
+
+// ParseRowValue 原始字节按 go 扫描类型 ScanType() 解析
+// 注意 Oracle/Mysql NULL VS 空字符串区别，Oracle 空字符串与 NULL 归于一类，统一解析成 nil
+// 字符以及二进制返回字节拷贝，rows.Scan 复用原始字节缓冲，arena 非空则拷贝至 arena
+func ParseRowValue(raw []byte, scanType string, arena *ByteArena) (interface{}, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	switch scanType {
+	case "int64":
+		return StrconvIntBitSize(string(raw), 64)
+	case "uint64":
+		return StrconvUintBitSize(string(raw), 64)
+	case "float32":
+		return StrconvFloatBitSize(string(raw), 32)
+	case "float64":
+		return StrconvFloatBitSize(string(raw), 64)
+	case "rune":
+		return StrconvRune(string(raw))
+	case "godror.Number":
+		r, err := decimal.NewFromString(string(raw))
+		if err != nil {
+			return nil, err
+		}
+		if r.IsInteger() {
+			return StrconvIntBitSize(string(raw), 64)
+		}
+		return StrconvFloatBitSize(string(raw), 64)
+	default:
+		if arena != nil {
+			return arena.Copy(raw), nil
+		}
+		val := make([]byte, len(raw))
+		copy(val, raw)
+		return val, nil
+	}
+}
+
+// AppendMySQLValue 追加 MySQL SQL 字面量，数值类型 strconv 直接追加，避免 fmt.Sprintf
+func AppendMySQLValue(buf *bytes.Buffer, val interface{}) {
+	var scratch [32]byte
+	switch v := val.(type) {
+	case nil:
+		buf.WriteString(`NULL`)
+	case []byte:
+		// 特殊字符
+		buf.WriteByte('\'')
+		AppendSpecialLettersUsingMySQL(buf, v)
+		buf.WriteByte('\'')
+	case int64:
+		buf.Write(strconv.AppendInt(scratch[:0], v, 10))
+	case uint64:
+		buf.Write(strconv.AppendUint(scratch[:0], v, 10))
+	case int32:
+		buf.Write(strconv.AppendInt(scratch[:0], int64(v), 10))
+	case float32:
+		buf.Write(strconv.AppendFloat(scratch[:0], float64(v), 'g', -1, 32))
+	case float64:
+		buf.Write(strconv.AppendFloat(scratch[:0], v, 'g', -1, 64))
+	default:
+		buf.WriteString(fmt.Sprintf("%v", v))
+	}
+}
+
+// AppendMySQLRow 追加 MySQL VALUES 单行 (...)
+func AppendMySQLRow(buf *bytes.Buffer, row RowValue) {
+	buf.WriteByte('(')
+	for i, val := range row {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		AppendMySQLValue(buf, val)
+	}
+	buf.WriteByte(')')
+}
+
+// RenderMySQLValue 渲染 MySQL SQL 字面量
+func RenderMySQLValue(val interface{}) string {
+	buf := GetBuffer()
+	defer PutBuffer(buf)
+	AppendMySQLValue(buf, val)
+	return buf.String()
+}
+
+// RenderMySQLRow 渲染 MySQL VALUES 单行 (...)
+func RenderMySQLRow(row RowValue) string {
+	buf := GetBuffer()
+	defer PutBuffer(buf)
+	AppendMySQLRow(buf, row)
+	return buf.String()
+}
+
+// RenderMySQLRows 渲染 MySQL VALUES 多行 (...),(...)
+func RenderMySQLRows(rows []RowValue) string {
+	buf := GetBuffer()
+	defer PutBuffer(buf)
+	for i, row := range rows {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		AppendMySQLRow(buf, row)
+	}
+	return buf.String()
+}
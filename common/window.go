@@ -0,0 +1,236 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RunWindow 任务运行时间窗口，窗口外暂停 chunk 调度，进入窗口自动恢复
+// 窗口按配置时区墙上时间判定，夏令时切换由 time.Location 处理，支持跨零点窗口如 22:00-06:00
+type RunWindow struct {
+	windows  []clockRange
+	location *time.Location
+	interval time.Duration
+
+	mu          sync.RWMutex
+	paused      bool
+	pausedSince time.Time
+	nextResume  time.Time
+}
+
+type clockRange struct {
+	raw   string
+	start int
+	end   int
+}
+
+// RunWindowState 窗口暂停状态，用于状态接口输出
+type RunWindowState struct {
+	Windows     []string `json:"windows"`
+	TimeZone    string   `json:"time-zone"`
+	Paused      bool     `json:"paused"`
+	PausedSince string   `json:"paused-since,omitempty"`
+	NextResume  string   `json:"next-resume,omitempty"`
+}
+
+var (
+	runWindowMu     sync.RWMutex
+	globalRunWindow *RunWindow
+)
+
+// NewRunWindow windows 格式 HH:MM-HH:MM，timeZone 为 IANA 时区名，空代表本地时区；windows 为空返回 nil 代表不限制
+func NewRunWindow(windows []string, timeZone string, interval time.Duration) (*RunWindow, error) {
+	if len(windows) == 0 {
+		return nil, nil
+	}
+	loc := time.Local
+	if timeZone != "" {
+		l, err := time.LoadLocation(timeZone)
+		if err != nil {
+			return nil, fmt.Errorf("run window time zone [%s] load failed: %v", timeZone, err)
+		}
+		loc = l
+	}
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	w := &RunWindow{location: loc, interval: interval}
+	for _, s := range windows {
+		parts := strings.Split(strings.TrimSpace(s), "-")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("run window [%s] format error, example: 22:00-06:00", s)
+		}
+		start, err := parseClockMinute(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("run window [%s] start parse failed: %v", s, err)
+		}
+		end, err := parseClockMinute(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("run window [%s] end parse failed: %v", s, err)
+		}
+		if start == end {
+			return nil, fmt.Errorf("run window [%s] start can't be equal end", s)
+		}
+		w.windows = append(w.windows, clockRange{raw: strings.TrimSpace(s), start: start, end: end})
+	}
+	return w, nil
+}
+
+func parseClockMinute(s string) (int, error) {
+	hm := strings.Split(strings.TrimSpace(s), ":")
+	if len(hm) != 2 {
+		return 0, fmt.Errorf("clock [%s] isn't HH:MM", s)
+	}
+	h, err := strconv.Atoi(hm[0])
+	if err != nil || h < 0 || h > 24 {
+		return 0, fmt.Errorf("clock [%s] hour invalid", s)
+	}
+	m, err := strconv.Atoi(hm[1])
+	if err != nil || m < 0 || m > 59 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("clock [%s] minute invalid", s)
+	}
+	return h*60 + m, nil
+}
+
+// InWindow 判断时间点是否位于任一窗口
+func (w *RunWindow) InWindow(t time.Time) bool {
+	if w == nil {
+		return true
+	}
+	local := t.In(w.location)
+	minute := local.Hour()*60 + local.Minute()
+	for _, r := range w.windows {
+		if r.start < r.end {
+			if minute >= r.start && minute < r.end {
+				return true
+			}
+			continue
+		}
+		// 跨零点
+		if minute >= r.start || minute < r.end {
+			return true
+		}
+	}
+	return false
+}
+
+// nextStart 下一个窗口开始时间，按墙上时间构造，夏令时跳过的时刻由 time.Date 归一化
+func (w *RunWindow) nextStart(t time.Time) time.Time {
+	local := t.In(w.location)
+	var next time.Time
+	for day := 0; day <= 1; day++ {
+		for _, r := range w.windows {
+			s := time.Date(local.Year(), local.Month(), local.Day()+day, r.start/60, r.start%60, 0, 0, w.location)
+			if s.After(t) && (next.IsZero() || s.Before(next)) {
+				next = s
+			}
+		}
+	}
+	return next
+}
+
+// Wait 窗口外阻塞直至进入窗口，期间记录暂停状态
+func (w *RunWindow) Wait(ctx context.Context) error {
+	if w == nil {
+		return nil
+	}
+	for {
+		now := time.Now()
+		if w.InWindow(now) {
+			w.mu.Lock()
+			if w.paused {
+				pausedSince := w.pausedSince
+				w.paused = false
+				w.mu.Unlock()
+				zap.L().Info("run window resume chunk scheduling",
+					zap.String("cost", time.Since(pausedSince).String()))
+			} else {
+				w.mu.Unlock()
+			}
+			return nil
+		}
+
+		w.mu.Lock()
+		if !w.paused {
+			w.paused = true
+			w.pausedSince = now
+			w.nextResume = w.nextStart(now)
+			w.mu.Unlock()
+			zap.L().Warn("run window pause chunk scheduling",
+				zap.String("time zone", w.location.String()),
+				zap.String("next resume", w.nextResume.Format(time.RFC3339)))
+		} else {
+			w.mu.Unlock()
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(w.interval):
+		}
+	}
+}
+
+// State 当前窗口暂停状态
+func (w *RunWindow) State() RunWindowState {
+	if w == nil {
+		return RunWindowState{}
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	state := RunWindowState{
+		TimeZone: w.location.String(),
+		Paused:   w.paused,
+	}
+	for _, r := range w.windows {
+		state.Windows = append(state.Windows, r.raw)
+	}
+	if w.paused {
+		state.PausedSince = w.pausedSince.Format(time.RFC3339)
+		state.NextResume = w.nextResume.Format(time.RFC3339)
+	}
+	return state
+}
+
+// SetRunWindow 设置进程级运行窗口，由任务启动时初始化
+func SetRunWindow(w *RunWindow) {
+	runWindowMu.Lock()
+	defer runWindowMu.Unlock()
+	globalRunWindow = w
+}
+
+// WaitRunWindow chunk 调度前调用，未配置窗口直接返回
+func WaitRunWindow(ctx context.Context) error {
+	runWindowMu.RLock()
+	w := globalRunWindow
+	runWindowMu.RUnlock()
+	return w.Wait(ctx)
+}
+
+// CurrentRunWindowState 进程级运行窗口状态
+func CurrentRunWindowState() RunWindowState {
+	runWindowMu.RLock()
+	w := globalRunWindow
+	runWindowMu.RUnlock()
+	return w.State()
+}
@@ -27,28 +27,60 @@ import (
 
 // 程序配置文件
 type Config struct {
-	*flag.FlagSet `json:"-"`
-	AppConfig     AppConfig     `toml:"app" json:"app"`
-	ReverseConfig ReverseConfig `toml:"reverse" json:"reverse"`
-	CheckConfig   CheckConfig   `toml:"check" json:"check"`
-	FullConfig    FullConfig    `toml:"full" json:"full"`
-	CSVConfig     CSVConfig     `toml:"csv" json:"csv"`
-	AllConfig     AllConfig     `toml:"all" json:"all"`
-	OracleConfig  OracleConfig  `toml:"oracle" json:"oracle"`
-	MySQLConfig   MySQLConfig   `toml:"mysql" json:"mysql"`
-	LogConfig     LogConfig     `toml:"log" json:"log"`
-	DiffConfig    DiffConfig    `toml:"compare" json:"compare"`
-	ConfigFile    string        `json:"config-file"`
-	PrintVersion  bool
-	TaskMode      string `json:"task-mode"`
-	DBTypeS       string `json:"db-type-s"`
-	DBTypeT       string `json:"db-type-t"`
+	*flag.FlagSet   `json:"-"`
+	AppConfig       AppConfig       `toml:"app" json:"app"`
+	ReverseConfig   ReverseConfig   `toml:"reverse" json:"reverse"`
+	CheckConfig     CheckConfig     `toml:"check" json:"check"`
+	FullConfig      FullConfig      `toml:"full" json:"full"`
+	CSVConfig       CSVConfig       `toml:"csv" json:"csv"`
+	AllConfig       AllConfig       `toml:"all" json:"all"`
+	OracleConfig    OracleConfig    `toml:"oracle" json:"oracle"`
+	MySQLConfig     MySQLConfig     `toml:"mysql" json:"mysql"`
+	LogConfig       LogConfig       `toml:"log" json:"log"`
+	DiffConfig      DiffConfig      `toml:"compare" json:"compare"`
+	ReloadConfig    ReloadConfig    `toml:"reload" json:"reload"`
+	BenchConfig     BenchConfig     `toml:"bench" json:"bench"`
+	LoadConfig      LoadConfig      `toml:"load" json:"load"`
+	LightningConfig LightningConfig `toml:"lightning" json:"lightning"`
+	AnalyticConfig  AnalyticConfig  `toml:"analytic" json:"analytic"`
+	ShipConfig      ShipConfig      `toml:"ship" json:"ship"`
+	HookConfig      HookConfig      `toml:"hook" json:"hook"`
+	SnapshotConfig  SnapshotConfig  `toml:"snapshot" json:"snapshot"`
+	SampleConfig    SampleConfig    `toml:"sample" json:"sample"`
+	VerifyConfig    VerifyConfig    `toml:"verify" json:"verify"`
+	RollbackConfig  RollbackConfig  `toml:"rollback" json:"rollback"`
+	OGGConfig       OGGConfig       `toml:"ogg" json:"ogg"`
+	RewriteConfig   RewriteConfig   `toml:"rewrite" json:"rewrite"`
+	RuleConfig      RuleConfig      `toml:"rule" json:"rule"`
+	DMConfig        DMConfig        `toml:"dm" json:"dm"`
+	OpenGaussConfig OpenGaussConfig `toml:"opengauss" json:"opengauss"`
+	DB2Config       DB2Config       `toml:"db2" json:"db2"`
+	ConfigFile      string          `json:"config-file"`
+	PrintVersion    bool
+	TaskMode        string `json:"task-mode"`
+	DBTypeS         string `json:"db-type-s"`
+	DBTypeT         string `json:"db-type-t"`
+	StartSCN        uint64 `json:"start-scn"`
+	Fix             bool   `json:"fix"`
+	ResumeMode      string `json:"resume-mode"`
+	MetaAction      string `json:"meta-action"`
+	MetaTask        string `json:"meta-task"`
+	MetaFile        string `json:"meta-file"`
+	Pipeline        string `json:"pipeline"`
 }
 
 type AppConfig struct {
-	InsertBatchSize  int    `toml:"insert-batch-size" json:"insert-batch-size"`
-	SlowlogThreshold int    `toml:"slowlog-threshold" json:"slowlog-threshold"`
-	PprofPort        string `toml:"pprof-port" json:"pprof-port"`
+	InsertBatchSize    int    `toml:"insert-batch-size" json:"insert-batch-size"`
+	SlowlogThreshold   int    `toml:"slowlog-threshold" json:"slowlog-threshold"`
+	SlowlogDiagnostics bool   `toml:"slowlog-diagnostics" json:"slowlog-diagnostics"`
+	PprofPort          string `toml:"pprof-port" json:"pprof-port"`
+	HTTPAddr           string `toml:"http-addr" json:"http-addr"`
+	// chunk 调度运行窗口，HH:MM-HH:MM，窗口外暂停调度
+	RunWindows             []string `toml:"run-windows" json:"run-windows"`
+	RunWindowTimeZone      string   `toml:"run-window-time-zone" json:"run-window-time-zone"`
+	RunWindowCheckInterval int      `toml:"run-window-check-interval" json:"run-window-check-interval"`
+	// 表级错误处理策略，abort 任一表错误中断任务，continue 记录错误后继续其他表
+	ErrorPolicy string `toml:"error-policy" json:"error-policy"`
 }
 
 type DiffConfig struct {
@@ -58,25 +90,52 @@ type DiffConfig struct {
 	EnableCheckpoint  bool          `toml:"enable-checkpoint" json:"enable-checkpoint"`
 	IgnoreStructCheck bool          `toml:"ignore-struct-check" json:"ignore-struct-check"`
 	FixSqlDir         string        `toml:"fix-sql-dir" json:"fix-sql-dir"`
+	SortOrderCheck    bool          `toml:"sort-order-check" json:"sort-order-check"`
+	SortNLSSort       string        `toml:"sort-nls-sort" json:"sort-nls-sort"`
+	SortSampleRows    int           `toml:"sort-sample-rows" json:"sort-sample-rows"`
+	ChecksumAlgo      string        `toml:"checksum-algo" json:"checksum-algo"`
+	EnableFix         bool          `toml:"enable-fix" json:"enable-fix"`
 	TableConfig       []TableConfig `toml:"table-config" json:"table-config"`
 }
 
 type ReverseConfig struct {
-	ReverseThreads   int    `toml:"reverse-threads" json:"reverse-threads"`
-	DirectWrite      bool   `toml:"direct-write" json:"direct-write"`
-	DDLReverseDir    string `toml:"ddl-reverse-dir" json:"ddl-reverse-dir"`
-	DDLCompatibleDir string `toml:"ddl-compatible-dir" json:"ddl-compatible-dir"`
+	ReverseThreads       int                `toml:"reverse-threads" json:"reverse-threads"`
+	DirectWrite          bool               `toml:"direct-write" json:"direct-write"`
+	DDLReverseDir        string             `toml:"ddl-reverse-dir" json:"ddl-reverse-dir"`
+	DDLCompatibleDir     string             `toml:"ddl-compatible-dir" json:"ddl-compatible-dir"`
+	SplitByObject        bool               `toml:"split-by-object" json:"split-by-object"`
+	SplitPerTable        bool               `toml:"split-per-table" json:"split-per-table"`
+	TemporaryTablePolicy string             `toml:"temporary-table-policy" json:"temporary-table-policy"`
+	SchedulerJob         bool               `toml:"scheduler-job" json:"scheduler-job"`
+	IndexDowngrade       bool               `toml:"index-downgrade" json:"index-downgrade"`
+	TTLConfig            []ReverseTTLConfig `toml:"ttl-config" json:"ttl-config"`
+}
+
+type ReverseTTLConfig struct {
+	SourceTable string `toml:"source-table" json:"source-table"`
+	TTLColumn   string `toml:"ttl-column" json:"ttl-column"`
+	TTLInterval string `toml:"ttl-interval" json:"ttl-interval"`
 }
 
 type CheckConfig struct {
-	CheckThreads int    `toml:"check-threads" json:"check-threads"`
-	CheckSQLDir  string `toml:"check-sql-dir" json:"check-sql-dir"`
+	CheckThreads      int    `toml:"check-threads" json:"check-threads"`
+	CheckSQLDir       string `toml:"check-sql-dir" json:"check-sql-dir"`
+	ApplicationImpact bool   `toml:"application-impact" json:"application-impact"`
+	IgnorePartition   bool   `toml:"ignore-partition" json:"ignore-partition"`
+	IgnoreComment     bool   `toml:"ignore-comment" json:"ignore-comment"`
+	CheckTrigger      bool   `toml:"check-trigger" json:"check-trigger"`
+	EnableFix         bool   `toml:"enable-fix" json:"enable-fix"`
 }
 
 type TableConfig struct {
 	SourceTable string `toml:"source-table" json:"source-table"`
 	IndexFields string `toml:"index-fields" json:"index-fields"`
 	Range       string `toml:"range" json:"range"`
+	// 行标识表达式，上下游一一对应，用于联合键、函数索引等表的行级匹配
+	IdentityKeyS []string `toml:"identity-key-s" json:"identity-key-s"`
+	IdentityKeyT []string `toml:"identity-key-t" json:"identity-key-t"`
+	// 对比忽略字段，用于下游触发器维护等合理存在差异的字段
+	IgnoreColumns []string `toml:"ignore-columns" json:"ignore-columns"`
 }
 
 type CSVConfig struct {
@@ -87,28 +146,287 @@ type CSVConfig struct {
 	EscapeBackslash  bool   `toml:"escape-backslash" json:"escape-backslash"`
 	Charset          string `toml:"charset" json:"charset"`
 	Rows             int    `toml:"rows" json:"rows"`
+	ChunkBytes       int    `toml:"chunk-bytes" json:"chunk-bytes"`
 	OutputDir        string `toml:"output-dir" json:"output-dir"`
 	TaskThreads      int    `toml:"task-threads" json:"task-threads"`
 	TableThreads     int    `toml:"table-threads" json:"table-threads"`
 	SQLThreads       int    `toml:"sql-threads" json:"sql-threads"`
 	EnableCheckpoint bool   `toml:"enable-checkpoint" json:"enable-checkpoint"`
+	BufferSize       int    `toml:"buffer-size" json:"buffer-size"`
+	DirectIO         bool   `toml:"direct-io" json:"direct-io"`
+	FsyncPolicy      string `toml:"fsync-policy" json:"fsync-policy"`
+	Compress         string `toml:"compress" json:"compress"`
+	Dialect          string `toml:"dialect" json:"dialect"`
+	QuoteStyle       string `toml:"quote-style" json:"quote-style"`
+	EscapeChar       string `toml:"escape-char" json:"escape-char"`
+	NullValue        string `toml:"null-value" json:"null-value"`
+	BOM              bool   `toml:"bom" json:"bom"`
+	StorageEndpoint  string `toml:"storage-endpoint" json:"storage-endpoint"`
+	StorageRegion    string `toml:"storage-region" json:"storage-region"`
+	StorageAccessKey string `toml:"storage-access-key" json:"storage-access-key"`
+	StorageSecretKey string `toml:"storage-secret-key" json:"storage-secret-key"`
+	StoragePathStyle bool   `toml:"storage-path-style" json:"storage-path-style"`
+	StoragePartSize  int    `toml:"storage-part-size" json:"storage-part-size"`
+	// STS 临时凭证 session token
+	StorageSessionToken string `toml:"storage-session-token" json:"storage-session-token"`
+	// 单次请求超时（秒）以及可重试错误重试次数
+	StorageTimeout    int `toml:"storage-timeout" json:"storage-timeout"`
+	StorageRetryTimes int `toml:"storage-retry-times" json:"storage-retry-times"`
 }
 
 type FullConfig struct {
-	ChunkSize        int  `toml:"chunk-size" json:"chunk-size"`
-	TaskThreads      int  `toml:"task-threads" json:"task-threads"`
-	TableThreads     int  `toml:"table-threads" json:"table-threads"`
-	SQLThreads       int  `toml:"sql-threads" json:"sql-threads"`
-	ApplyThreads     int  `toml:"apply-threads" json:"apply-threads"`
-	EnableCheckpoint bool `toml:"enable-checkpoint" json:"enable-checkpoint"`
+	ChunkSize            int                    `toml:"chunk-size" json:"chunk-size"`
+	TaskThreads          int                    `toml:"task-threads" json:"task-threads"`
+	TableThreads         int                    `toml:"table-threads" json:"table-threads"`
+	SQLThreads           int                    `toml:"sql-threads" json:"sql-threads"`
+	ApplyThreads         int                    `toml:"apply-threads" json:"apply-threads"`
+	EnableCheckpoint     bool                   `toml:"enable-checkpoint" json:"enable-checkpoint"`
+	VerifyChunkPercent   int                    `toml:"verify-chunk-percent" json:"verify-chunk-percent"`
+	VerifySampleRows     int                    `toml:"verify-sample-rows" json:"verify-sample-rows"`
+	ChunkBytes           int                    `toml:"chunk-bytes" json:"chunk-bytes"`
+	ApplyBisect          bool                   `toml:"apply-bisect" json:"apply-bisect"`
+	ChunkCheckpoint      bool                   `toml:"chunk-checkpoint" json:"chunk-checkpoint"`
+	LOBThreshold         int                    `toml:"lob-threshold" json:"lob-threshold"`
+	LOBBatchSize         int                    `toml:"lob-batch-size" json:"lob-batch-size"`
+	ChunkSplitMode       string                 `toml:"chunk-split-mode" json:"chunk-split-mode"`
+	ApplyMode            string                 `toml:"apply-mode" json:"apply-mode"`
+	ApplyProtocol        string                 `toml:"apply-protocol" json:"apply-protocol"`
+	DiskPrecheck         string                 `toml:"disk-precheck" json:"disk-precheck"`
+	DataExpansionFactor  float64                `toml:"data-expansion-factor" json:"data-expansion-factor"`
+	IndexExpansionFactor float64                `toml:"index-expansion-factor" json:"index-expansion-factor"`
+	DiskAvailableGB      float64                `toml:"disk-available-gb" json:"disk-available-gb"`
+	MaxStatementBytes    int                    `toml:"max-statement-bytes" json:"max-statement-bytes"`
+	BatchBytes           int                    `toml:"batch-bytes" json:"batch-bytes"`
+	QPSLimit             int                    `toml:"qps-limit" json:"qps-limit"`
+	BandwidthLimit       string                 `toml:"bandwidth-limit" json:"bandwidth-limit"`
+	TableLimit           []FullTableLimitConfig `toml:"table-limit" json:"table-limit"`
+	ConsistentRead       bool                   `toml:"consistent-read" json:"consistent-read"`
+	SCNDriftThreshold    uint64                 `toml:"scn-drift-threshold" json:"scn-drift-threshold"`
+	TiDBPreSplit         bool                   `toml:"tidb-pre-split" json:"tidb-pre-split"`
+	PreSplitRegionRows   int                    `toml:"pre-split-region-rows" json:"pre-split-region-rows"`
+	PriorityBySize       bool                   `toml:"priority-by-size" json:"priority-by-size"`
+	TablePriority        []FullTablePriority    `toml:"table-priority" json:"table-priority"`
+}
+
+// FullTablePriority 表级调度优先级，数值越大越先同步
+type FullTablePriority struct {
+	SourceTable string `toml:"source-table" json:"source-table"`
+	Priority    int    `toml:"priority" json:"priority"`
+}
+
+// FullTableLimitConfig 表级抽取限速，与全局限速同时生效
+type FullTableLimitConfig struct {
+	SourceTable    string `toml:"source-table" json:"source-table"`
+	QPSLimit       int    `toml:"qps-limit" json:"qps-limit"`
+	BandwidthLimit string `toml:"bandwidth-limit" json:"bandwidth-limit"`
+}
+
+type ReloadConfig struct {
+	DeleteBatchSize int                 `toml:"delete-batch-size" json:"delete-batch-size"`
+	TableConfig     []ReloadTableConfig `toml:"table-config" json:"table-config"`
+}
+
+type ReloadTableConfig struct {
+	SourceTable string `toml:"source-table" json:"source-table"`
+	SourceWhere string `toml:"source-where" json:"source-where"`
+	TargetWhere string `toml:"target-where" json:"target-where"`
+}
+
+type LoadConfig struct {
+	InputDir         string `toml:"input-dir" json:"input-dir"`
+	TableThreads     int    `toml:"table-threads" json:"table-threads"`
+	FileThreads      int    `toml:"file-threads" json:"file-threads"`
+	Replace          bool   `toml:"replace" json:"replace"`
+	EnableCheckpoint bool   `toml:"enable-checkpoint" json:"enable-checkpoint"`
+}
+
+type LightningConfig struct {
+	BinaryPath       string `toml:"binary-path" json:"binary-path"`
+	PDAddr           string `toml:"pd-addr" json:"pd-addr"`
+	StatusPort       int    `toml:"status-port" json:"status-port"`
+	SortedKVDir      string `toml:"sorted-kv-dir" json:"sorted-kv-dir"`
+	LogFile          string `toml:"log-file" json:"log-file"`
+	SkipExport       bool   `toml:"skip-export" json:"skip-export"`
+	EnableCheckpoint bool   `toml:"enable-checkpoint" json:"enable-checkpoint"`
+}
+
+// AnalyticConfig 分析型目标端 ClickHouse/Greenplum，csv 导出后生成建表语句并调用原生批量导入工具
+type AnalyticConfig struct {
+	BinaryPath    string `toml:"binary-path" json:"binary-path"`
+	Host          string `toml:"host" json:"host"`
+	Port          int    `toml:"port" json:"port"`
+	Username      string `toml:"username" json:"username"`
+	Password      string `toml:"password" json:"password"`
+	DBName        string `toml:"db-name" json:"db-name"`
+	SchemaName    string `toml:"schema-name" json:"schema-name"`
+	TableThreads  int    `toml:"table-threads" json:"table-threads"`
+	Engine        string `toml:"engine" json:"engine"`
+	TableOptions  string `toml:"table-options" json:"table-options"`
+	LocalHostname string `toml:"local-hostname" json:"local-hostname"`
+	SkipExport    bool   `toml:"skip-export" json:"skip-export"`
+	SkipDDL       bool   `toml:"skip-ddl" json:"skip-ddl"`
+}
+
+type HookConfig struct {
+	HookRules []HookRule `toml:"rule" json:"rule"`
+}
+
+type HookRule struct {
+	Scope        string `toml:"scope" json:"scope"`
+	Stage        string `toml:"stage" json:"stage"`
+	SourceTable  string `toml:"source-table" json:"source-table"`
+	Command      string `toml:"command" json:"command"`
+	SQLFile      string `toml:"sql-file" json:"sql-file"`
+	Timeout      int    `toml:"timeout" json:"timeout"`
+	AbortOnError bool   `toml:"abort-on-error" json:"abort-on-error"`
+}
+
+type RollbackConfig struct {
+	EnableSnapshot bool   `toml:"enable-snapshot" json:"enable-snapshot"`
+	OutputDir      string `toml:"output-dir" json:"output-dir"`
+	KeepSnapshots  int    `toml:"keep-snapshots" json:"keep-snapshots"`
+	SnapshotID     string `toml:"snapshot-id" json:"snapshot-id"`
+}
+
+type VerifyConfig struct {
+	Threads        int              `toml:"threads" json:"threads"`
+	SampleRows     int              `toml:"sample-rows" json:"sample-rows"`
+	VerifyRelation []VerifyRelation `toml:"relation" json:"relation"`
+}
+
+type VerifyRelation struct {
+	ChildTable    string `toml:"child-table" json:"child-table"`
+	ChildColumns  string `toml:"child-columns" json:"child-columns"`
+	ParentTable   string `toml:"parent-table" json:"parent-table"`
+	ParentColumns string `toml:"parent-columns" json:"parent-columns"`
+}
+
+type SnapshotConfig struct {
+	GroupByFK      bool            `toml:"group-by-fk" json:"group-by-fk"`
+	SnapshotGroups []SnapshotGroup `toml:"group" json:"group"`
+}
+
+// SampleConfig 开发环境抽样导出，作用于 full / csv 模式
+type SampleConfig struct {
+	Enable        bool                `toml:"enable" json:"enable"`
+	SamplePercent float64             `toml:"sample-percent" json:"sample-percent"`
+	FKConsistent  bool                `toml:"fk-consistent" json:"fk-consistent"`
+	TableConfig   []SampleTableConfig `toml:"table-config" json:"table-config"`
+}
+
+// SampleTableConfig 表级抽样配置，top-n 优先于 sample-percent
+type SampleTableConfig struct {
+	SourceTable   string  `toml:"source-table" json:"source-table"`
+	SamplePercent float64 `toml:"sample-percent" json:"sample-percent"`
+	TopN          int     `toml:"top-n" json:"top-n"`
+}
+
+type SnapshotGroup struct {
+	Name   string   `toml:"name" json:"name"`
+	Tables []string `toml:"tables" json:"tables"`
+}
+
+type ShipConfig struct {
+	Role        string `toml:"role" json:"role"`
+	ListenAddr  string `toml:"listen-addr" json:"listen-addr"`
+	RemoteAddr  string `toml:"remote-addr" json:"remote-addr"`
+	FileThreads int    `toml:"file-threads" json:"file-threads"`
+	CAPath      string `toml:"ca-path" json:"ca-path"`
+	CertPath    string `toml:"cert-path" json:"cert-path"`
+	KeyPath     string `toml:"key-path" json:"key-path"`
+	AutoLoad    bool   `toml:"auto-load" json:"auto-load"`
+}
+
+// OGGConfig OGG Kafka Handler 变更记录消费
+type OGGConfig struct {
+	Brokers     []string `toml:"brokers" json:"brokers"`
+	Topics      []string `toml:"topics" json:"topics"`
+	Format      string   `toml:"format" json:"format"`
+	StartOffset string   `toml:"start-offset" json:"start-offset"`
+	BatchSize   int      `toml:"batch-size" json:"batch-size"`
+}
+
+// RewriteConfig 表名、字段名正则改写规则，显式 table_name_rule 优先
+type RewriteConfig struct {
+	TableRules  []RewriteRuleConfig `toml:"table-rule" json:"table-rule"`
+	ColumnRules []RewriteRuleConfig `toml:"column-rule" json:"column-rule"`
+}
+
+type RewriteRuleConfig struct {
+	Pattern string `toml:"pattern" json:"pattern"`
+	Replace string `toml:"replace" json:"replace"`
+	Merge   bool   `toml:"merge" json:"merge"`
+}
+
+// RuleConfig 自定义字段类型映射规则，任务启动时写入元数据库 schema_datatype_rule / table_datatype_rule / column_datatype_rule
+type RuleConfig struct {
+	DatatypeRules []DatatypeRuleConfig `toml:"datatype" json:"datatype"`
+}
+
+// DatatypeRuleConfig 规则级别按配置项区分：配置 column-name 为字段级别，仅配置 table-name 为表级别，否则为 schema 级别
+// db-type-s / db-type-t 未配置则取任务 db-type-s / db-type-t
+type DatatypeRuleConfig struct {
+	DBTypeS     string `toml:"db-type-s" json:"db-type-s"`
+	DBTypeT     string `toml:"db-type-t" json:"db-type-t"`
+	SchemaName  string `toml:"schema-name" json:"schema-name"`
+	TableName   string `toml:"table-name" json:"table-name"`
+	ColumnName  string `toml:"column-name" json:"column-name"`
+	ColumnTypeS string `toml:"column-type-s" json:"column-type-s"`
+	ColumnTypeT string `toml:"column-type-t" json:"column-type-t"`
+}
+
+// TableRewriter 表名改写规则
+func (c RewriteConfig) TableRewriter() (*common.NameRewriter, error) {
+	return genNameRewriter(c.TableRules)
+}
+
+// ColumnRewriter 字段名改写规则，字段不支持合并
+func (c RewriteConfig) ColumnRewriter() (*common.NameRewriter, error) {
+	return genNameRewriter(c.ColumnRules)
+}
+
+func genNameRewriter(rules []RewriteRuleConfig) (*common.NameRewriter, error) {
+	rewriter := common.NewNameRewriter()
+	for _, r := range rules {
+		if err := rewriter.AddRule(r.Pattern, r.Replace, r.Merge); err != nil {
+			return nil, err
+		}
+	}
+	return rewriter, nil
+}
+
+type BenchConfig struct {
+	SourceTable  string `toml:"source-table" json:"source-table"`
+	ChunkSize    int    `toml:"chunk-size" json:"chunk-size"`
+	ReadThreads  int    `toml:"read-threads" json:"read-threads"`
+	TargetTable  string `toml:"target-table" json:"target-table"`
+	WriteRows    int    `toml:"write-rows" json:"write-rows"`
+	WriteThreads int    `toml:"write-threads" json:"write-threads"`
+	BatchSize    int    `toml:"batch-size" json:"batch-size"`
+	RowBytes     int    `toml:"row-bytes" json:"row-bytes"`
 }
 
 type AllConfig struct {
-	LogminerQueryTimeout int `toml:"logminer-query-timeout" json:"logminer-query-timeout"`
-	FilterThreads        int `toml:"filter-threads" json:"filter-threads"`
-	ApplyThreads         int `toml:"apply-threads" json:"apply-threads"`
-	WorkerQueue          int `toml:"worker-queue" json:"worker-queue"`
-	WorkerThreads        int `toml:"worker-threads" json:"worker-threads"`
+	LogminerQueryTimeout int      `toml:"logminer-query-timeout" json:"logminer-query-timeout"`
+	FilterThreads        int      `toml:"filter-threads" json:"filter-threads"`
+	ApplyThreads         int      `toml:"apply-threads" json:"apply-threads"`
+	WorkerQueue          int      `toml:"worker-queue" json:"worker-queue"`
+	WorkerThreads        int      `toml:"worker-threads" json:"worker-threads"`
+	SpaceCheckInterval   int      `toml:"space-check-interval" json:"space-check-interval"`
+	SpaceWarnThreshold   int      `toml:"space-warn-threshold" json:"space-warn-threshold"`
+	SpacePauseThreshold  int      `toml:"space-pause-threshold" json:"space-pause-threshold"`
+	DriftCheckInterval   int      `toml:"drift-check-interval" json:"drift-check-interval"`
+	DriftSampleTables    int      `toml:"drift-sample-tables" json:"drift-sample-tables"`
+	DriftThreshold       float64  `toml:"drift-threshold" json:"drift-threshold"`
+	MVLogTables          []string `toml:"mvlog-tables" json:"mvlog-tables"`
+	MVLogInterval        int      `toml:"mvlog-interval" json:"mvlog-interval"`
+	MVLogBatchSize       int      `toml:"mvlog-batch-size" json:"mvlog-batch-size"`
+	MVLogPurge           bool     `toml:"mvlog-purge" json:"mvlog-purge"`
+	TriggerTables        []string `toml:"trigger-tables" json:"trigger-tables"`
+	StartSCN             uint64   `toml:"start-scn" json:"start-scn"`
+	DDLReplicate         bool     `toml:"ddl-replicate" json:"ddl-replicate"`
+	DDLSkip              []string `toml:"ddl-skip" json:"ddl-skip"`
+	GTIDCheckpoint       bool     `toml:"gtid-checkpoint" json:"gtid-checkpoint"`
 }
 
 type OracleConfig struct {
@@ -125,6 +443,40 @@ type OracleConfig struct {
 	SchemaName    string   `toml:"schema-name" json:"schema-name"`
 	IncludeTable  []string `toml:"include-table" json:"include-table"`
 	ExcludeTable  []string `toml:"exclude-table" json:"exclude-table"`
+	// 多地址 host:port 列表（RAC SCAN/VIP），配置后忽略 host/port
+	Addrs       []string `toml:"addrs" json:"addrs"`
+	LoadBalance bool     `toml:"load-balance" json:"load-balance"`
+	// 会话时区，如 +08:00，为空不设置
+	SessionTimeZone string `toml:"session-time-zone" json:"session-time-zone"`
+	// 源端连接 SSH 隧道/代理
+	Tunnel TunnelConfig `toml:"tunnel" json:"tunnel"`
+	// 数据字典缓存文件，reverse/check/assess 优先读取，为空不开启
+	DictionaryCache    string `toml:"dictionary-cache" json:"dictionary-cache"`
+	DictionaryCacheTTL string `toml:"dictionary-cache-ttl" json:"dictionary-cache-ttl"`
+	// 物理备库连接，配置后 full/csv 数据抽取走备库，SCN 以及元数据查询仍走主库
+	Standby OracleStandbyConfig `toml:"standby-conn" json:"standby-conn"`
+	// 会话被 kill（ORA-00028/ORA-03113）时重建会话重试 chunk 次数，0 代表不重试，间隔单位秒
+	SessionKillRetryTimes    int `toml:"session-kill-retry-times" json:"session-kill-retry-times"`
+	SessionKillRetryInterval int `toml:"session-kill-retry-interval" json:"session-kill-retry-interval"`
+	// 抽取端字符字段字符集转换，源端字符集解码后按目标端字符集编码，任一为空不转换
+	SourceCharset string `toml:"source-charset" json:"source-charset"`
+	TargetCharset string `toml:"target-charset" json:"target-charset"`
+	// 云数据库（Autonomous Database）wallet 连接，配置后按 tnsnames.ora 服务别名 TCPS 连接，忽略 host/port/service-name
+	WalletZip string `toml:"wallet-zip" json:"wallet-zip"`
+	WalletDir string `toml:"wallet-dir" json:"wallet-dir"`
+	TNSAlias  string `toml:"tns-alias" json:"tns-alias"`
+}
+
+// OracleStandbyConfig 物理备库（Active Data Guard）连接，用户名、密码、服务名为空沿用主库配置
+type OracleStandbyConfig struct {
+	Host        string   `toml:"host" json:"host"`
+	Port        int      `toml:"port" json:"port"`
+	Addrs       []string `toml:"addrs" json:"addrs"`
+	ServiceName string   `toml:"service-name" json:"service-name"`
+	Username    string   `toml:"username" json:"username"`
+	Password    string   `toml:"password" json:"password"`
+	// 等待备库应用至主库 SCN 的超时时间，单位秒
+	ApplyLagTimeout int `toml:"apply-lag-timeout" json:"apply-lag-timeout"`
 }
 
 type MySQLConfig struct {
@@ -134,12 +486,80 @@ type MySQLConfig struct {
 	Host          string `toml:"host" json:"host"`
 	Port          int    `toml:"port" json:"port"`
 	ConnectParams string `toml:"connect-params" json:"connect-params"`
-	MetaSchema    string `toml:"meta-schema" json:"meta-schema"`
+	// 下游 DDL 执行用户（schema owner），为空则与数据写入用户相同
+	DDLUsername string `toml:"ddl-username" json:"ddl-username"`
+	DDLPassword string `toml:"ddl-password" json:"ddl-password"`
+	MetaSchema  string `toml:"meta-schema" json:"meta-schema"`
+	SchemaName  string `toml:"schema-name" json:"schema-name"`
+	TableOption string `toml:"table-option" json:"table-option"`
+	Overwrite   bool   `toml:"overwrite" json:"overwrite"`
+	// 下游 ProxySQL/HAProxy 主从切换重连重放
+	FailoverRetryTimes    int `toml:"failover-retry-times" json:"failover-retry-times"`
+	FailoverRetryInterval int `toml:"failover-retry-interval" json:"failover-retry-interval"`
+	// 多地址 host:port 列表，配置后忽略 host/port，建连时按顺序故障切换
+	Addrs []string `toml:"addrs" json:"addrs"`
+	// 会话时区，如 +08:00，为空不设置
+	SessionTimeZone string `toml:"session-time-zone" json:"session-time-zone"`
+	// 目标表数据清理方式 truncate / delete / auto
+	TargetCleanMode      string `toml:"target-clean-mode" json:"target-clean-mode"`
+	TargetCleanBatchSize int    `toml:"target-clean-batch-size" json:"target-clean-batch-size"`
+	// 数据写入会话 sql_log_bin = 0，不写 binlog，仅适用于无下游复制的临时目标库
+	DisableBinlog bool `toml:"disable-binlog" json:"disable-binlog"`
+	// 目标端形态 auto / community / rds / aurora，auto 建连时自动识别，托管实例自动适配会话设置、清理方式以及连接池
+	Flavor string `toml:"flavor" json:"flavor"`
+	// 目标端连接 SSH 隧道/代理，元数据库连接同样生效
+	Tunnel TunnelConfig `toml:"tunnel" json:"tunnel"`
+}
+
+// DMConfig 达梦目标端连接，db-type-t = dm 时生效，元数据库仍使用 [mysql] 配置
+type DMConfig struct {
+	Username      string `toml:"username" json:"username"`
+	Password      string `toml:"password" json:"password"`
+	Host          string `toml:"host" json:"host"`
+	Port          int    `toml:"port" json:"port"`
 	SchemaName    string `toml:"schema-name" json:"schema-name"`
-	TableOption   string `toml:"table-option" json:"table-option"`
+	ConnectParams string `toml:"connect-params" json:"connect-params"`
+	Overwrite     bool   `toml:"overwrite" json:"overwrite"`
+}
+
+// OpenGaussConfig openGauss/MogDB 目标端连接，db-type-t = opengauss 时生效，元数据库仍使用 [mysql] 配置
+type OpenGaussConfig struct {
+	Username      string `toml:"username" json:"username"`
+	Password      string `toml:"password" json:"password"`
+	Host          string `toml:"host" json:"host"`
+	Port          int    `toml:"port" json:"port"`
+	DBName        string `toml:"db-name" json:"db-name"`
+	SchemaName    string `toml:"schema-name" json:"schema-name"`
+	ConnectParams string `toml:"connect-params" json:"connect-params"`
 	Overwrite     bool   `toml:"overwrite" json:"overwrite"`
 }
 
+// DB2Config DB2 LUW 源端连接，db-type-s = db2 时生效，需 -tags db2 编译（依赖 IBM clidriver）
+type DB2Config struct {
+	Username      string   `toml:"username" json:"username"`
+	Password      string   `toml:"password" json:"password"`
+	Host          string   `toml:"host" json:"host"`
+	Port          int      `toml:"port" json:"port"`
+	DBName        string   `toml:"db-name" json:"db-name"`
+	SchemaName    string   `toml:"schema-name" json:"schema-name"`
+	ConnectParams string   `toml:"connect-params" json:"connect-params"`
+	IncludeTable  []string `toml:"include-table" json:"include-table"`
+	ExcludeTable  []string `toml:"exclude-table" json:"exclude-table"`
+}
+
+// TunnelConfig 数据库连接隧道，type 为空代表直连
+type TunnelConfig struct {
+	// ssh / socks5 / http
+	Type       string `toml:"type" json:"type"`
+	Addr       string `toml:"addr" json:"addr"`
+	User       string `toml:"user" json:"user"`
+	Password   string `toml:"password" json:"password"`
+	KeyFile    string `toml:"key-file" json:"key-file"`
+	KnownHosts string `toml:"known-hosts" json:"known-hosts"`
+	// 未配置 known-hosts 时显式跳过 ssh 主机公钥校验
+	InsecureSkipHostKey bool `toml:"insecure-skip-host-key" json:"insecure-skip-host-key"`
+}
+
 type LogConfig struct {
 	LogLevel   string `toml:"log-level" json:"log-level"`
 	LogFile    string `toml:"log-file" json:"log-file"`
@@ -159,9 +579,16 @@ func NewConfig() *Config {
 	}
 	fs.BoolVar(&cfg.PrintVersion, "V", false, "print version information and exit")
 	fs.StringVar(&cfg.ConfigFile, "config", "./config.toml", "path to the configuration file")
-	fs.StringVar(&cfg.TaskMode, "mode", "", "specify the program running mode: [prepare assess reverse full csv all check compare]")
+	fs.StringVar(&cfg.TaskMode, "mode", "", "specify the program running mode: [prepare assess reverse full csv all check compare reload bench load lightning analytic ship verify rollback uninstall resume ogg meta]")
 	fs.StringVar(&cfg.DBTypeS, "source", "oracle", "specify the source db type")
 	fs.StringVar(&cfg.DBTypeT, "target", "mysql", "specify the target db type")
+	fs.Uint64Var(&cfg.StartSCN, "start-scn", 0, "specify the logminer increment sync start scn, override meta table [incr_sync_meta] scn, only for mode all")
+	fs.BoolVar(&cfg.Fix, "fix", false, "specify the compare mismatch chunk repair sql or check fixed sql directly apply to target db, only for mode compare and check")
+	fs.StringVar(&cfg.ResumeMode, "resume-mode", "full", "specify the task mode [full csv] which failed chunks requeue and rerun, only for mode resume")
+	fs.StringVar(&cfg.MetaAction, "meta-action", "export", "specify the meta snapshot action [export import], only for mode meta")
+	fs.StringVar(&cfg.MetaTask, "task", "full", "specify the task mode [full csv all compare ...] which meta snapshot export or import, only for mode meta")
+	fs.StringVar(&cfg.MetaFile, "file", "./task.json.gz", "specify the meta snapshot gzip json file, only for mode meta")
+	fs.StringVar(&cfg.Pipeline, "pipeline", "", "specify the task stages [schema data verify] run in sequence, separated by comma, override flag mode")
 	return cfg
 }
 
@@ -188,6 +615,16 @@ func (c *Config) Parse(args []string) error {
 		return fmt.Errorf("no config file")
 	}
 
+	// 命令行 --start-scn 优先于配置文件 [all] start-scn
+	if c.StartSCN > 0 {
+		c.AllConfig.StartSCN = c.StartSCN
+	}
+	// 命令行 --fix 优先于配置文件 [compare] / [check] enable-fix
+	if c.Fix {
+		c.DiffConfig.EnableFix = true
+		c.CheckConfig.EnableFix = true
+	}
+
 	c.AdjustConfig()
 
 	return nil
@@ -204,9 +641,142 @@ func (c *Config) configFromFile(file string) error {
 func (c *Config) AdjustConfig() {
 	c.DBTypeS = common.StringUPPER(c.DBTypeS)
 	c.DBTypeT = common.StringUPPER(c.DBTypeT)
+	// MariaDB 目标端复用 MySQL 链路以及内置规则，方言差异按 [mysql] db-type 区分
+	if c.DBTypeT == common.DatabaseTypeMariaDB {
+		c.DBTypeT = common.DatabaseTypeMySQL
+		c.MySQLConfig.DBType = common.DatabaseTypeMariaDB
+	}
 	c.TaskMode = common.StringUPPER(c.TaskMode)
+	c.ResumeMode = common.StringUPPER(c.ResumeMode)
+	c.MetaAction = common.StringUPPER(c.MetaAction)
+	c.MetaTask = common.StringUPPER(c.MetaTask)
+	c.Pipeline = common.StringUPPER(c.Pipeline)
 	c.OracleConfig.SchemaName = common.StringUPPER(c.OracleConfig.SchemaName)
 	c.MySQLConfig.SchemaName = common.StringUPPER(c.MySQLConfig.SchemaName)
+	c.AnalyticConfig.SchemaName = common.StringUPPER(c.AnalyticConfig.SchemaName)
+	c.DB2Config.SchemaName = common.StringUPPER(c.DB2Config.SchemaName)
+	c.ReverseConfig.TemporaryTablePolicy = common.StringUPPER(c.ReverseConfig.TemporaryTablePolicy)
+	c.ShipConfig.Role = common.StringUPPER(c.ShipConfig.Role)
+	c.CSVConfig.FsyncPolicy = common.StringUPPER(c.CSVConfig.FsyncPolicy)
+	c.MySQLConfig.TargetCleanMode = common.StringUPPER(c.MySQLConfig.TargetCleanMode)
+	c.MySQLConfig.Flavor = common.StringUPPER(c.MySQLConfig.Flavor)
+	c.DiffConfig.ChecksumAlgo = common.StringUPPER(c.DiffConfig.ChecksumAlgo)
+	c.FullConfig.ChunkSplitMode = common.StringUPPER(c.FullConfig.ChunkSplitMode)
+	c.FullConfig.ApplyMode = common.StringUPPER(c.FullConfig.ApplyMode)
+	c.FullConfig.ApplyProtocol = common.StringUPPER(c.FullConfig.ApplyProtocol)
+	c.AppConfig.ErrorPolicy = common.StringUPPER(c.AppConfig.ErrorPolicy)
+	c.FullConfig.DiskPrecheck = common.StringUPPER(c.FullConfig.DiskPrecheck)
+	c.OGGConfig.Format = common.StringUPPER(c.OGGConfig.Format)
+	c.OGGConfig.StartOffset = common.StringUPPER(c.OGGConfig.StartOffset)
+	for i := range c.SnapshotConfig.SnapshotGroups {
+		c.SnapshotConfig.SnapshotGroups[i].Name = common.StringUPPER(c.SnapshotConfig.SnapshotGroups[i].Name)
+		for j := range c.SnapshotConfig.SnapshotGroups[i].Tables {
+			c.SnapshotConfig.SnapshotGroups[i].Tables[j] = common.StringUPPER(c.SnapshotConfig.SnapshotGroups[i].Tables[j])
+		}
+	}
+	for i := range c.AllConfig.MVLogTables {
+		c.AllConfig.MVLogTables[i] = common.StringUPPER(c.AllConfig.MVLogTables[i])
+	}
+	for i := range c.AllConfig.TriggerTables {
+		c.AllConfig.TriggerTables[i] = common.StringUPPER(c.AllConfig.TriggerTables[i])
+	}
+	for i := range c.HookConfig.HookRules {
+		c.HookConfig.HookRules[i].Scope = common.StringUPPER(c.HookConfig.HookRules[i].Scope)
+		c.HookConfig.HookRules[i].Stage = common.StringUPPER(c.HookConfig.HookRules[i].Stage)
+		c.HookConfig.HookRules[i].SourceTable = common.StringUPPER(c.HookConfig.HookRules[i].SourceTable)
+	}
+	for i := range c.RuleConfig.DatatypeRules {
+		r := &c.RuleConfig.DatatypeRules[i]
+		r.DBTypeS = common.StringUPPER(r.DBTypeS)
+		r.DBTypeT = common.StringUPPER(r.DBTypeT)
+		if r.DBTypeS == "" {
+			r.DBTypeS = c.DBTypeS
+		}
+		if r.DBTypeT == "" {
+			r.DBTypeT = c.DBTypeT
+		}
+		r.SchemaName = common.StringUPPER(r.SchemaName)
+		r.TableName = common.StringUPPER(r.TableName)
+		r.ColumnName = common.StringUPPER(r.ColumnName)
+	}
+	if c.CSVConfig.FsyncPolicy == "" {
+		c.CSVConfig.FsyncPolicy = common.MigrateCSVFsyncPolicyNone
+	}
+	if c.CSVConfig.StorageTimeout <= 0 {
+		c.CSVConfig.StorageTimeout = common.MigrateCSVStorageTimeout
+	}
+	if c.CSVConfig.StorageRetryTimes < 0 {
+		c.CSVConfig.StorageRetryTimes = 0
+	}
+	if c.MySQLConfig.TargetCleanMode == "" {
+		c.MySQLConfig.TargetCleanMode = common.MigrateTargetCleanModeTruncate
+	}
+	if c.MySQLConfig.Flavor == "" {
+		c.MySQLConfig.Flavor = common.MySQLFlavorAuto
+	}
+	if c.OracleConfig.DictionaryCache != "" && c.OracleConfig.DictionaryCacheTTL == "" {
+		c.OracleConfig.DictionaryCacheTTL = common.OracleDictionaryCacheTTL
+	}
+	if c.OracleConfig.Standby.ApplyLagTimeout <= 0 {
+		c.OracleConfig.Standby.ApplyLagTimeout = common.OracleStandbyApplyLagTimeout
+	}
+	if c.FullConfig.ChunkSplitMode == "" {
+		c.FullConfig.ChunkSplitMode = common.MigrateChunkSplitModeRowID
+	}
+	if c.FullConfig.ApplyMode == "" {
+		c.FullConfig.ApplyMode = common.MigrateApplyModeReplace
+	}
+	if c.FullConfig.ApplyProtocol == "" {
+		c.FullConfig.ApplyProtocol = common.MigrateApplyProtocolText
+	}
+	if c.AppConfig.ErrorPolicy == "" {
+		c.AppConfig.ErrorPolicy = common.TaskErrorPolicyAbort
+	}
+	if c.FullConfig.DiskPrecheck == "" {
+		c.FullConfig.DiskPrecheck = common.MigrateDiskPrecheckOff
+	}
+	if c.FullConfig.DataExpansionFactor <= 0 {
+		c.FullConfig.DataExpansionFactor = common.MigrateDataExpansionFactor
+	}
+	if c.FullConfig.IndexExpansionFactor <= 0 {
+		c.FullConfig.IndexExpansionFactor = common.MigrateIndexExpansionFactor
+	}
+	if c.OGGConfig.Format == "" {
+		c.OGGConfig.Format = common.OGGFormatJSON
+	}
+	if c.OGGConfig.StartOffset == "" {
+		c.OGGConfig.StartOffset = common.OGGStartOffsetEarliest
+	}
+	if c.OGGConfig.BatchSize <= 0 {
+		c.OGGConfig.BatchSize = c.AppConfig.InsertBatchSize
+	}
+	if c.DiffConfig.ChecksumAlgo == "" {
+		c.DiffConfig.ChecksumAlgo = common.CompareChecksumCRC32
+	}
+	if c.BenchConfig.ChunkSize <= 0 {
+		c.BenchConfig.ChunkSize = common.BenchChunkSize
+	}
+	if c.BenchConfig.ReadThreads <= 0 {
+		c.BenchConfig.ReadThreads = common.BenchReadThreads
+	}
+	if c.BenchConfig.WriteThreads <= 0 {
+		c.BenchConfig.WriteThreads = common.BenchWriteThreads
+	}
+	if c.BenchConfig.BatchSize <= 0 {
+		c.BenchConfig.BatchSize = c.AppConfig.InsertBatchSize
+	}
+	if c.BenchConfig.BatchSize <= 0 {
+		c.BenchConfig.BatchSize = common.BenchBatchSize
+	}
+	if c.BenchConfig.RowBytes <= 0 {
+		c.BenchConfig.RowBytes = common.BenchRowBytes
+	}
+	if c.AnalyticConfig.TableThreads <= 0 {
+		c.AnalyticConfig.TableThreads = 1
+	}
+	if c.ReverseConfig.TemporaryTablePolicy == "" {
+		c.ReverseConfig.TemporaryTablePolicy = common.ReverseTemporaryTablePolicyNormal
+	}
 }
 
 func (c *Config) String() string {
@@ -0,0 +1,179 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package db2
+
+import (
+	"fmt"
+)
+
+// GetDB2SchemaObjectTypeCounts schema 对象类型统计
+// SYSCAT.TABLES TYPE：T 表、V 视图、S 物化查询表（MQT）、A 别名、N 昵称、G 全局临时表
+func (d *DB2) GetDB2SchemaObjectTypeCounts(schemaName string) ([]map[string]string, error) {
+	_, res, err := Query(d.Ctx, d.DB2DB, fmt.Sprintf(`SELECT OBJECT_TYPE, COUNT(1) AS COUNTS FROM (
+ SELECT CASE TYPE WHEN 'T' THEN 'TABLE'
+   WHEN 'V' THEN 'VIEW'
+   WHEN 'S' THEN 'MATERIALIZED QUERY TABLE'
+   WHEN 'A' THEN 'ALIAS'
+   WHEN 'N' THEN 'NICKNAME'
+   WHEN 'G' THEN 'GLOBAL TEMPORARY TABLE'
+   ELSE 'OTHER TABLE TYPE ' || TYPE END AS OBJECT_TYPE
+ FROM SYSCAT.TABLES WHERE TABSCHEMA = '%[1]s'
+ UNION ALL
+ SELECT 'SEQUENCE' AS OBJECT_TYPE FROM SYSCAT.SEQUENCES WHERE SEQSCHEMA = '%[1]s' AND SEQTYPE = 'S'
+ UNION ALL
+ SELECT CASE ROUTINETYPE WHEN 'P' THEN 'PROCEDURE' WHEN 'F' THEN 'FUNCTION' ELSE 'METHOD' END AS OBJECT_TYPE
+ FROM SYSCAT.ROUTINES WHERE ROUTINESCHEMA = '%[1]s' AND ORIGIN NOT IN ('S')
+ UNION ALL
+ SELECT 'TRIGGER' AS OBJECT_TYPE FROM SYSCAT.TRIGGERS WHERE TRIGSCHEMA = '%[1]s'
+ UNION ALL
+ SELECT 'MODULE' AS OBJECT_TYPE FROM SYSCAT.MODULES WHERE MODULESCHEMA = '%[1]s'
+) GROUP BY OBJECT_TYPE
+ ORDER BY OBJECT_TYPE`, schemaName))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetDB2SchemaColumnTypeCounts 普通表字段类型分布，按类型、长度、精度以及代码页聚合用于类型映射评估
+func (d *DB2) GetDB2SchemaColumnTypeCounts(schemaName string) ([]map[string]string, error) {
+	_, res, err := Query(d.Ctx, d.DB2DB, fmt.Sprintf(`SELECT TRIM(C.TYPESCHEMA) AS TYPE_SCHEMA,
+		TRIM(C.TYPENAME) AS DATA_TYPE,
+		C.LENGTH AS DATA_LENGTH,
+		C.SCALE AS DATA_SCALE,
+		C.CODEPAGE,
+		COUNT(1) AS COUNTS
+ FROM SYSCAT.COLUMNS C, SYSCAT.TABLES T
+ WHERE C.TABSCHEMA = T.TABSCHEMA
+   AND C.TABNAME = T.TABNAME
+   AND T.TYPE = 'T'
+   AND C.TABSCHEMA = '%s'
+ GROUP BY C.TYPESCHEMA, C.TYPENAME, C.LENGTH, C.SCALE, C.CODEPAGE
+ ORDER BY C.TYPENAME, C.LENGTH, C.SCALE`, schemaName))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetDB2SchemaIdentityColumn 标识列，GENERATED = A 为 GENERATED ALWAYS，迁移需显式写入值
+func (d *DB2) GetDB2SchemaIdentityColumn(schemaName string) ([]map[string]string, error) {
+	_, res, err := Query(d.Ctx, d.DB2DB, fmt.Sprintf(`SELECT TRIM(C.TABNAME) AS TABLE_NAME,
+		TRIM(C.COLNAME) AS COLUMN_NAME,
+		C.GENERATED,
+		I.START,
+		I.INCREMENT,
+		I.CYCLE,
+		I.NEXTCACHEFIRSTVALUE
+ FROM SYSCAT.COLUMNS C, SYSCAT.COLIDENTATTRIBUTES I
+ WHERE C.TABSCHEMA = I.TABSCHEMA
+   AND C.TABNAME = I.TABNAME
+   AND C.COLNAME = I.COLNAME
+   AND C.IDENTITY = 'Y'
+   AND C.TABSCHEMA = '%s'
+ ORDER BY C.TABNAME`, schemaName))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetDB2SchemaGeneratedColumn 表达式生成列
+func (d *DB2) GetDB2SchemaGeneratedColumn(schemaName string) ([]map[string]string, error) {
+	_, res, err := Query(d.Ctx, d.DB2DB, fmt.Sprintf(`SELECT TRIM(TABNAME) AS TABLE_NAME,
+		TRIM(COLNAME) AS COLUMN_NAME,
+		TEXT AS GENERATION_EXPRESSION
+ FROM SYSCAT.COLUMNS
+ WHERE TABSCHEMA = '%s'
+   AND GENERATED = 'A'
+   AND IDENTITY = 'N'
+   AND ROWCHANGETIMESTAMP = 'N'
+ ORDER BY TABNAME, COLNO`, schemaName))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetDB2SchemaTablespace schema 普通表所属表空间统计
+func (d *DB2) GetDB2SchemaTablespace(schemaName string) ([]map[string]string, error) {
+	_, res, err := Query(d.Ctx, d.DB2DB, fmt.Sprintf(`SELECT TRIM(T.TBSPACE) AS TABLESPACE_NAME,
+		S.TBSPACETYPE AS TABLESPACE_TYPE,
+		S.DATATYPE,
+		S.PAGESIZE,
+		COUNT(1) AS TABLE_COUNTS
+ FROM SYSCAT.TABLES T, SYSCAT.TABLESPACES S
+ WHERE T.TBSPACE = S.TBSPACE
+   AND T.TYPE = 'T'
+   AND T.TABSCHEMA = '%s'
+ GROUP BY T.TBSPACE, S.TBSPACETYPE, S.DATATYPE, S.PAGESIZE
+ ORDER BY T.TBSPACE`, schemaName))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetDB2SchemaPartitionTable 数据分区表以及分区数
+func (d *DB2) GetDB2SchemaPartitionTable(schemaName string) ([]map[string]string, error) {
+	_, res, err := Query(d.Ctx, d.DB2DB, fmt.Sprintf(`SELECT TRIM(TABNAME) AS TABLE_NAME,
+		COUNT(1) AS PARTITION_COUNTS
+ FROM SYSCAT.DATAPARTITIONS
+ WHERE TABSCHEMA = '%s'
+ GROUP BY TABNAME
+ HAVING COUNT(1) > 1
+ ORDER BY TABNAME`, schemaName))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetDB2SchemaTableWithoutPK 无主键普通表
+func (d *DB2) GetDB2SchemaTableWithoutPK(schemaName string) ([]string, error) {
+	_, res, err := Query(d.Ctx, d.DB2DB, fmt.Sprintf(`SELECT TRIM(T.TABNAME) AS TABLE_NAME
+ FROM SYSCAT.TABLES T
+ WHERE T.TABSCHEMA = '%s'
+   AND T.TYPE = 'T'
+   AND NOT EXISTS (
+     SELECT 1 FROM SYSCAT.TABCONST C
+     WHERE C.TABSCHEMA = T.TABSCHEMA
+       AND C.TABNAME = T.TABNAME
+       AND C.TYPE = 'P')
+ ORDER BY T.TABNAME`, schemaName))
+	if err != nil {
+		return nil, err
+	}
+	var tables []string
+	for _, r := range res {
+		tables = append(tables, r["TABLE_NAME"])
+	}
+	return tables, nil
+}
+
+// GetDB2SchemaIndexTypeCounts 索引类型统计，REG 常规索引、CLUS 聚簇索引、DIM/BLOK 多维聚簇索引、XPTH/XVIL/XVIP XML 索引
+func (d *DB2) GetDB2SchemaIndexTypeCounts(schemaName string) ([]map[string]string, error) {
+	_, res, err := Query(d.Ctx, d.DB2DB, fmt.Sprintf(`SELECT TRIM(INDEXTYPE) AS INDEX_TYPE,
+		COUNT(1) AS COUNTS
+ FROM SYSCAT.INDEXES
+ WHERE TABSCHEMA = '%s'
+ GROUP BY INDEXTYPE
+ ORDER BY INDEXTYPE`, schemaName))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
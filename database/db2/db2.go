@@ -0,0 +1,142 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package db2
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"strings"
+)
+
+// go_ibm_db 驱动依赖 IBM clidriver 以及 cgo，仅 -tags db2 编译时注册
+const db2DriverName = "go_ibm_db"
+
+type DB2 struct {
+	Ctx   context.Context
+	DB2DB *sql.DB
+}
+
+func NewDB2DBEngine(ctx context.Context, db2Cfg config.DB2Config) (*DB2, error) {
+	if !common.IsContainString(sql.Drivers(), db2DriverName) {
+		return nil, fmt.Errorf("db2 driver [%s] isn't registered, please rebuild transferdb with [-tags db2] and IBM clidriver installed", db2DriverName)
+	}
+
+	dsn := fmt.Sprintf("HOSTNAME=%s;PORT=%d;DATABASE=%s;UID=%s;PWD=%s;PROTOCOL=TCPIP",
+		db2Cfg.Host, db2Cfg.Port, db2Cfg.DBName, db2Cfg.Username, db2Cfg.Password)
+	if db2Cfg.SchemaName != "" {
+		dsn = common.StringsBuilder(dsn, ";CurrentSchema=", common.StringUPPER(db2Cfg.SchemaName))
+	}
+	if db2Cfg.ConnectParams != "" {
+		dsn = common.StringsBuilder(dsn, ";", strings.TrimPrefix(db2Cfg.ConnectParams, ";"))
+	}
+
+	db2DB, err := sql.Open(db2DriverName, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error on open db2 database connection [%v] user [%v]: %v", db2Cfg.DBName, db2Cfg.Username, err)
+	}
+
+	db2DB.SetMaxIdleConns(common.MySQLMaxIdleConn)
+	db2DB.SetMaxOpenConns(common.MySQLMaxConn)
+	db2DB.SetConnMaxLifetime(common.MySQLConnMaxLifeTime)
+	db2DB.SetConnMaxIdleTime(common.MySQLConnMaxIdleTime)
+
+	if err = db2DB.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("error on ping db2 database connection [%v] user [%v]: %v", db2Cfg.DBName, db2Cfg.Username, err)
+	}
+
+	return &DB2{
+		Ctx:   ctx,
+		DB2DB: db2DB,
+	}, nil
+}
+
+// GetDB2DBVersion 实例版本，形如 DB2 v11.5.8.0
+func (d *DB2) GetDB2DBVersion() (string, error) {
+	_, res, err := Query(d.Ctx, d.DB2DB, `SELECT SERVICE_LEVEL FROM SYSIBMADM.ENV_INST_INFO`)
+	if err != nil {
+		return "", err
+	}
+	if len(res) == 0 {
+		return "", fmt.Errorf("db2 db version query result is empty")
+	}
+	return strings.TrimSpace(res[0]["SERVICE_LEVEL"]), nil
+}
+
+// GetDB2Schemas 排除系统 schema
+func (d *DB2) GetDB2Schemas() ([]string, error) {
+	_, res, err := Query(d.Ctx, d.DB2DB, `SELECT TRIM(SCHEMANAME) AS SCHEMANAME
+ FROM SYSCAT.SCHEMATA
+ WHERE SCHEMANAME NOT LIKE 'SYS%'
+   AND SCHEMANAME NOT IN ('NULLID','SQLJ')
+ ORDER BY SCHEMANAME`)
+	if err != nil {
+		return nil, err
+	}
+	var schemas []string
+	for _, r := range res {
+		schemas = append(schemas, r["SCHEMANAME"])
+	}
+	return schemas, nil
+}
+
+func Query(ctx context.Context, db *sql.DB, querySQL string) ([]string, []map[string]string, error) {
+	var (
+		cols []string
+		res  []map[string]string
+	)
+	rows, err := db.QueryContext(ctx, querySQL)
+	if err != nil {
+		return cols, res, fmt.Errorf("general sql [%v] query failed: [%v]", querySQL, err.Error())
+	}
+	defer rows.Close()
+
+	cols, err = rows.Columns()
+	if err != nil {
+		return cols, res, fmt.Errorf("general sql [%v] query rows.Columns failed: [%v]", querySQL, err.Error())
+	}
+
+	values := make([]sql.RawBytes, len(cols))
+	scans := make([]interface{}, len(cols))
+	for i := range values {
+		scans[i] = &values[i]
+	}
+
+	for rows.Next() {
+		err = rows.Scan(scans...)
+		if err != nil {
+			return cols, res, fmt.Errorf("general sql [%v] query rows.Scan failed: [%v]", querySQL, err.Error())
+		}
+
+		row := make(map[string]string)
+		for k, v := range values {
+			// 与 Oracle/MySQL 保持一致，NULL 值统一以 NULLABLE 表示
+			if v == nil {
+				row[strings.ToUpper(cols[k])] = "NULLABLE"
+			} else {
+				row[strings.ToUpper(cols[k])] = string(v)
+			}
+		}
+		res = append(res, row)
+	}
+
+	if err = rows.Err(); err != nil {
+		return cols, res, fmt.Errorf("general sql [%v] query rows.Next failed: [%v]", querySQL, err.Error())
+	}
+	return cols, res, nil
+}
@@ -0,0 +1,22 @@
+//go:build db2
+
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package db2
+
+// DB2 驱动依赖 IBM clidriver（IBM_DB_HOME、LD_LIBRARY_PATH）以及 cgo，默认编译不引入
+// 编译：go get github.com/ibmdb/go_ibm_db && go build -tags db2
+import _ "github.com/ibmdb/go_ibm_db"
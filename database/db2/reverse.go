@@ -0,0 +1,196 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package db2
+
+import (
+	"fmt"
+)
+
+// GetDB2SchemaTable schema 下普通表，不含视图、物化查询表（MQT）、别名以及全局临时表
+func (d *DB2) GetDB2SchemaTable(schemaName string) ([]string, error) {
+	_, res, err := Query(d.Ctx, d.DB2DB, fmt.Sprintf(`SELECT TRIM(TABNAME) AS TABLE_NAME
+ FROM SYSCAT.TABLES
+ WHERE TABSCHEMA = '%s'
+   AND TYPE = 'T'
+ ORDER BY TABNAME`, schemaName))
+	if err != nil {
+		return nil, err
+	}
+	var tables []string
+	for _, r := range res {
+		tables = append(tables, r["TABLE_NAME"])
+	}
+	return tables, nil
+}
+
+// GetDB2SchemaTableColumn 字段定义
+// CODEPAGE = 0 为 FOR BIT DATA 二进制字符类型，TIMESTAMP 精度记录于 SCALE，GRAPHIC 类型 LENGTH 为双字节字符数
+// GENERATED = A/D 且 IDENTITY = Y 为标识列，GENERATED = A 且 ROWCHANGETIMESTAMP = Y 为行更新时间戳列，其余 GENERATED = A 为表达式生成列
+func (d *DB2) GetDB2SchemaTableColumn(schemaName, tableName string) ([]map[string]string, error) {
+	_, res, err := Query(d.Ctx, d.DB2DB, fmt.Sprintf(`SELECT TRIM(COLNAME) AS COLUMN_NAME,
+		TRIM(TYPESCHEMA) AS TYPE_SCHEMA,
+		TRIM(TYPENAME) AS DATA_TYPE,
+		LENGTH AS DATA_LENGTH,
+		SCALE AS DATA_SCALE,
+		CODEPAGE,
+		NULLS AS NULLABLE,
+		DEFAULT AS DATA_DEFAULT,
+		REMARKS AS COMMENTS,
+		IDENTITY,
+		GENERATED,
+		ROWCHANGETIMESTAMP,
+		TEXT AS GENERATION_EXPRESSION
+ FROM SYSCAT.COLUMNS
+ WHERE TABSCHEMA = '%s'
+   AND TABNAME = '%s'
+ ORDER BY COLNO`, schemaName, tableName))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetDB2SchemaTableIdentityColumn 标识列属性，NEXTCACHEFIRSTVALUE 为下一个待分配值
+func (d *DB2) GetDB2SchemaTableIdentityColumn(schemaName, tableName string) ([]map[string]string, error) {
+	_, res, err := Query(d.Ctx, d.DB2DB, fmt.Sprintf(`SELECT TRIM(COLNAME) AS COLUMN_NAME,
+		START,
+		INCREMENT,
+		NEXTCACHEFIRSTVALUE
+ FROM SYSCAT.COLIDENTATTRIBUTES
+ WHERE TABSCHEMA = '%s'
+   AND TABNAME = '%s'`, schemaName, tableName))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetDB2SchemaTableKey 主键（TYPE = P）、唯一约束（TYPE = U）
+func (d *DB2) GetDB2SchemaTableKey(schemaName, tableName string) ([]map[string]string, error) {
+	_, res, err := Query(d.Ctx, d.DB2DB, fmt.Sprintf(`SELECT TRIM(C.CONSTNAME) AS CONSTRAINT_NAME,
+		C.TYPE AS CONSTRAINT_TYPE,
+		LISTAGG(TRIM(K.COLNAME), ',') WITHIN GROUP (ORDER BY K.COLSEQ) AS COLUMN_LIST
+ FROM SYSCAT.TABCONST C
+ JOIN SYSCAT.KEYCOLUSE K
+   ON C.TABSCHEMA = K.TABSCHEMA
+  AND C.TABNAME = K.TABNAME
+  AND C.CONSTNAME = K.CONSTNAME
+ WHERE C.TABSCHEMA = '%s'
+   AND C.TABNAME = '%s'
+   AND C.TYPE IN ('P','U')
+ GROUP BY C.CONSTNAME, C.TYPE
+ ORDER BY C.TYPE, C.CONSTNAME`, schemaName, tableName))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetDB2SchemaTableForeignKey 外键，DELETERULE/UPDATERULE：A NO ACTION、C CASCADE、N SET NULL、R RESTRICT
+func (d *DB2) GetDB2SchemaTableForeignKey(schemaName, tableName string) ([]map[string]string, error) {
+	_, res, err := Query(d.Ctx, d.DB2DB, fmt.Sprintf(`SELECT TRIM(R.CONSTNAME) AS CONSTRAINT_NAME,
+		TRIM(R.REFTABSCHEMA) AS R_OWNER,
+		TRIM(R.REFTABNAME) AS RTABLE_NAME,
+		R.DELETERULE AS DELETE_RULE,
+		R.UPDATERULE AS UPDATE_RULE,
+		(SELECT LISTAGG(TRIM(K.COLNAME), ',') WITHIN GROUP (ORDER BY K.COLSEQ)
+		   FROM SYSCAT.KEYCOLUSE K
+		  WHERE K.TABSCHEMA = R.TABSCHEMA AND K.TABNAME = R.TABNAME AND K.CONSTNAME = R.CONSTNAME) AS COLUMN_LIST,
+		(SELECT LISTAGG(TRIM(K.COLNAME), ',') WITHIN GROUP (ORDER BY K.COLSEQ)
+		   FROM SYSCAT.KEYCOLUSE K
+		  WHERE K.TABSCHEMA = R.REFTABSCHEMA AND K.TABNAME = R.REFTABNAME AND K.CONSTNAME = R.REFKEYNAME) AS RCOLUMN_LIST
+ FROM SYSCAT.REFERENCES R
+ WHERE R.TABSCHEMA = '%s'
+   AND R.TABNAME = '%s'
+ ORDER BY R.CONSTNAME`, schemaName, tableName))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetDB2SchemaTableCheckKey 检查约束，不含函数依赖约束（TYPE = F）
+func (d *DB2) GetDB2SchemaTableCheckKey(schemaName, tableName string) ([]map[string]string, error) {
+	_, res, err := Query(d.Ctx, d.DB2DB, fmt.Sprintf(`SELECT TRIM(CONSTNAME) AS CONSTRAINT_NAME,
+		TEXT AS SEARCH_CONDITION
+ FROM SYSCAT.CHECKS
+ WHERE TABSCHEMA = '%s'
+   AND TABNAME = '%s'
+   AND TYPE = 'C'
+ ORDER BY CONSTNAME`, schemaName, tableName))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetDB2SchemaTableIndex 索引，排除主键、唯一约束依赖的系统索引（SYSTEM_REQUIRED > 0）
+// UNIQUERULE：D 普通、U 唯一；COLNAMES 形如 +COL1-COL2，+ 升序 - 降序
+func (d *DB2) GetDB2SchemaTableIndex(schemaName, tableName string) ([]map[string]string, error) {
+	_, res, err := Query(d.Ctx, d.DB2DB, fmt.Sprintf(`SELECT TRIM(INDNAME) AS INDEX_NAME,
+		UNIQUERULE,
+		TRIM(INDEXTYPE) AS INDEX_TYPE,
+		COLNAMES
+ FROM SYSCAT.INDEXES
+ WHERE TABSCHEMA = '%s'
+   AND TABNAME = '%s'
+   AND UNIQUERULE <> 'P'
+   AND SYSTEM_REQUIRED = 0
+ ORDER BY INDNAME`, schemaName, tableName))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetDB2SchemaTableDetail 表注释、数据/索引/大字段表空间、压缩以及分区方式
+func (d *DB2) GetDB2SchemaTableDetail(schemaName, tableName string) (map[string]string, error) {
+	_, res, err := Query(d.Ctx, d.DB2DB, fmt.Sprintf(`SELECT REMARKS AS COMMENTS,
+		TRIM(TBSPACE) AS TBSPACE,
+		TRIM(INDEX_TBSPACE) AS INDEX_TBSPACE,
+		TRIM(LONG_TBSPACE) AS LONG_TBSPACE,
+		COMPRESSION,
+		PARTITION_MODE
+ FROM SYSCAT.TABLES
+ WHERE TABSCHEMA = '%s'
+   AND TABNAME = '%s'`, schemaName, tableName))
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("db2 table [%s.%s] isn't exist", schemaName, tableName)
+	}
+	return res[0], nil
+}
+
+// GetDB2SchemaTableDataPartition 表范围分区，非分区表返回空
+// DB2 分区边界默认 STARTING / ENDING 双闭区间，与 MySQL VALUES LESS THAN 语义不同
+func (d *DB2) GetDB2SchemaTableDataPartition(schemaName, tableName string) ([]map[string]string, error) {
+	_, res, err := Query(d.Ctx, d.DB2DB, fmt.Sprintf(`SELECT TRIM(P.DATAPARTITIONNAME) AS PARTITION_NAME,
+		P.LOWINCLUSIVE,
+		P.LOWVALUE,
+		P.HIGHINCLUSIVE,
+		P.HIGHVALUE
+ FROM SYSCAT.DATAPARTITIONS P
+ WHERE P.TABSCHEMA = '%[1]s'
+   AND P.TABNAME = '%[2]s'
+   AND EXISTS (SELECT 1 FROM SYSCAT.DATAPARTITIONEXPRESSION E WHERE E.TABSCHEMA = '%[1]s' AND E.TABNAME = '%[2]s')
+ ORDER BY P.SEQNO`, schemaName, tableName))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
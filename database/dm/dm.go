@@ -0,0 +1,183 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package dm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	_ "gitee.com/chunanyong/dm"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"net/url"
+	"strings"
+)
+
+type DM struct {
+	Ctx  context.Context
+	DMDB *sql.DB
+}
+
+func NewDMDBEngine(ctx context.Context, dmCfg config.DMConfig) (*DM, error) {
+	// dm://user:password@host:port?schema=xxx，密码可能包含特殊字符需转义
+	dsn := fmt.Sprintf("dm://%s:%s@%s:%d?schema=%s",
+		dmCfg.Username, url.PathEscape(dmCfg.Password), dmCfg.Host, dmCfg.Port, common.StringUPPER(dmCfg.SchemaName))
+	if dmCfg.ConnectParams != "" {
+		dsn = common.StringsBuilder(dsn, "&", dmCfg.ConnectParams)
+	}
+
+	dmDB, err := sql.Open("dm", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error on open dm database connection [%v] user [%v]: %v", dmCfg.SchemaName, dmCfg.Username, err)
+	}
+
+	dmDB.SetMaxIdleConns(common.MySQLMaxIdleConn)
+	dmDB.SetMaxOpenConns(common.MySQLMaxConn)
+	dmDB.SetConnMaxLifetime(common.MySQLConnMaxLifeTime)
+	dmDB.SetConnMaxIdleTime(common.MySQLConnMaxIdleTime)
+
+	if err = dmDB.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("error on ping dm database connection [%v] user [%v]: %v", dmCfg.SchemaName, dmCfg.Username, err)
+	}
+
+	return &DM{
+		Ctx:  ctx,
+		DMDB: dmDB,
+	}, nil
+}
+
+func (d *DM) GetDMDBVersion() (string, error) {
+	_, res, err := Query(d.Ctx, d.DMDB, `SELECT BANNER FROM V$VERSION WHERE ROWNUM = 1`)
+	if err != nil {
+		return "", err
+	}
+	if len(res) == 0 {
+		return "", fmt.Errorf("dm db version query result is empty")
+	}
+	return res[0]["BANNER"], nil
+}
+
+func (d *DM) IsExistDMSchema(schemaName string) (bool, error) {
+	// 达梦模式对象记录于 SYSOBJECTS，TYPE$ = 'SCH'
+	_, res, err := Query(d.Ctx, d.DMDB, fmt.Sprintf(`SELECT COUNT(1) AS COUNT FROM SYSOBJECTS WHERE TYPE$ = 'SCH' AND NAME = '%s'`, common.StringUPPER(schemaName)))
+	if err != nil {
+		return false, err
+	}
+	if len(res) == 0 || res[0]["COUNT"] == "0" {
+		return false, nil
+	}
+	return true, nil
+}
+
+func (d *DM) IsExistDMTable(schemaName, tableName string) (bool, error) {
+	_, res, err := Query(d.Ctx, d.DMDB, fmt.Sprintf(`SELECT COUNT(1) AS COUNT FROM DBA_TABLES WHERE OWNER = '%s' AND TABLE_NAME = '%s'`,
+		common.StringUPPER(schemaName), tableName))
+	if err != nil {
+		return false, err
+	}
+	if len(res) == 0 || res[0]["COUNT"] == "0" {
+		return false, nil
+	}
+	return true, nil
+}
+
+func (d *DM) WriteDMDDL(sql string) error {
+	_, err := d.DMDB.ExecContext(d.Ctx, sql)
+	if err != nil {
+		return fmt.Errorf("target dm schema ddl sql [%v] exec failed: %v", sql, err)
+	}
+	return nil
+}
+
+func (d *DM) TruncateDMTable(schemaName, tableName string) error {
+	truncateSQL := common.StringsBuilder(`TRUNCATE TABLE "`, common.StringUPPER(schemaName), `"."`, tableName, `"`)
+	_, err := d.DMDB.ExecContext(d.Ctx, truncateSQL)
+	if err != nil {
+		return fmt.Errorf("truncate dm table sql [%v] failed: %v", truncateSQL, err)
+	}
+	return nil
+}
+
+// WriteDMTable 预编译语句逐行绑定写入，单批次单事务提交
+func (d *DM) WriteDMTable(insertSQL string, rows [][]interface{}) error {
+	txn, err := d.DMDB.BeginTx(d.Ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("dm sql [%v] begin txn failed: %v", insertSQL, err)
+	}
+	stmt, err := txn.PrepareContext(d.Ctx, insertSQL)
+	if err != nil {
+		_ = txn.Rollback()
+		return fmt.Errorf("dm sql [%v] prepare failed: %v", insertSQL, err)
+	}
+	defer stmt.Close()
+
+	for _, args := range rows {
+		if _, err = stmt.ExecContext(d.Ctx, args...); err != nil {
+			_ = txn.Rollback()
+			return fmt.Errorf("dm sql [%v] bind write failed: %v", insertSQL, err)
+		}
+	}
+	if err = txn.Commit(); err != nil {
+		return fmt.Errorf("dm sql [%v] commit failed: %v", insertSQL, err)
+	}
+	return nil
+}
+
+func Query(ctx context.Context, db *sql.DB, querySQL string) ([]string, []map[string]string, error) {
+	var (
+		cols []string
+		res  []map[string]string
+	)
+	rows, err := db.QueryContext(ctx, querySQL)
+	if err != nil {
+		return cols, res, fmt.Errorf("general sql [%v] query failed: [%v]", querySQL, err.Error())
+	}
+	defer rows.Close()
+
+	cols, err = rows.Columns()
+	if err != nil {
+		return cols, res, fmt.Errorf("general sql [%v] query rows.Columns failed: [%v]", querySQL, err.Error())
+	}
+
+	values := make([]sql.RawBytes, len(cols))
+	scans := make([]interface{}, len(cols))
+	for i := range values {
+		scans[i] = &values[i]
+	}
+
+	for rows.Next() {
+		err = rows.Scan(scans...)
+		if err != nil {
+			return cols, res, fmt.Errorf("general sql [%v] query rows.Scan failed: [%v]", querySQL, err.Error())
+		}
+
+		row := make(map[string]string)
+		for k, v := range values {
+			// 与 Oracle/MySQL 保持一致，NULL 值统一以 NULLABLE 表示
+			if v == nil {
+				row[strings.ToUpper(cols[k])] = "NULLABLE"
+			} else {
+				row[strings.ToUpper(cols[k])] = string(v)
+			}
+		}
+		res = append(res, row)
+	}
+
+	if err = rows.Err(); err != nil {
+		return cols, res, fmt.Errorf("general sql [%v] query rows.Next failed: [%v]", querySQL, err.Error())
+	}
+	return cols, res, nil
+}
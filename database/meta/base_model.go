@@ -16,6 +16,8 @@ limitations under the License.
 package meta
 
 import (
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/metrics"
 	"gorm.io/gorm"
 	"time"
 )
@@ -49,3 +51,12 @@ func getCurrentTime() time.Time {
 		time.Now().Nanosecond(),
 		time.Local)
 }
+
+// observeChunkStatus chunk 终态更新计入 prometheus 指标，错误数由 CreateErrorLog 单独统计
+func observeChunkStatus(taskMode, schemaName, tableName string, updates map[string]interface{}) {
+	status, ok := updates["TaskStatus"].(string)
+	if !ok || (status != common.TaskStatusSuccess && status != common.TaskStatusFailed) {
+		return
+	}
+	metrics.ChunkCounter.WithLabelValues(common.StringUPPER(taskMode), schemaName, tableName, status).Inc()
+}
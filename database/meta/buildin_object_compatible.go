@@ -604,5 +604,21 @@ func (rw *BuildinObjectCompatible) InitO2MBuildinObjectCompatible(ctx context.Co
 		IsConvertible: common.AssessNoConvertible,
 	})
 
+	// oracle temporal type，转换为普通表，历史数据以及有效期语义不迁移
+	buildinObjComps = append(buildinObjComps, &BuildinObjectCompatible{
+		DBTypeS:       common.DatabaseTypeOracle,
+		DBTypeT:       common.DatabaseTypeMySQL,
+		ObjectNameS:   common.BuildInOracleTemporalTypeFlashbackArchive,
+		IsCompatible:  common.AssessNoCompatible,
+		IsConvertible: common.AssessYesConvertible,
+	})
+	buildinObjComps = append(buildinObjComps, &BuildinObjectCompatible{
+		DBTypeS:       common.DatabaseTypeOracle,
+		DBTypeT:       common.DatabaseTypeMySQL,
+		ObjectNameS:   common.BuildInOracleTemporalTypeValidity,
+		IsCompatible:  common.AssessNoCompatible,
+		IsConvertible: common.AssessYesConvertible,
+	})
+
 	return rw.DB(ctx).Clauses(clause.Insert{Modifier: "IGNORE"}).CreateInBatches(buildinObjComps, 20).Error
 }
@@ -0,0 +1,127 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package meta
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/filter"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 内置表黑名单规则，用户可自行新增记录扩展
+type BuildinTableBlacklist struct {
+	ID           uint   `gorm:"primary_key;autoIncrement;comment:'自增编号'" json:"id"`
+	DBTypeS      string `gorm:"type:varchar(15);index:idx_dbtype_s_pattern,unique;comment:'源数据库类型'" json:"db_type_s"`
+	TablePattern string `gorm:"type:varchar(300);not null;index:idx_dbtype_s_pattern,unique;comment:'源端表名通配符规则'" json:"table_pattern"`
+	ObjectType   string `gorm:"type:varchar(30);comment:'对象类型'" json:"object_type"`
+	*BaseModel
+}
+
+func NewBuildinTableBlacklistModel(m *Meta) *BuildinTableBlacklist {
+	return &BuildinTableBlacklist{BaseModel: &BaseModel{
+		Meta: m,
+	}}
+}
+
+func (rw *BuildinTableBlacklist) ParseSchemaTable() (string, error) {
+	stmt := &gorm.Statement{DB: rw.GormDB}
+	err := stmt.Parse(rw)
+	if err != nil {
+		return "", fmt.Errorf("parse struct [BuildinTableBlacklist] get table_name failed: %v", err)
+	}
+	return stmt.Schema.Table, nil
+}
+
+func (rw *BuildinTableBlacklist) DetailBuildinTableBlacklist(ctx context.Context, detailS *BuildinTableBlacklist) ([]BuildinTableBlacklist, error) {
+	var blacklists []BuildinTableBlacklist
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return blacklists, err
+	}
+	if err = rw.DB(ctx).Where("UPPER(db_type_s) = ?",
+		common.StringUPPER(detailS.DBTypeS)).Find(&blacklists).Error; err != nil {
+		return blacklists, fmt.Errorf("detail table [%s] record failed: %v", table, err)
+	}
+	return blacklists, nil
+}
+
+// FilterBuildinTableBlacklist 返回过滤黑名单之后的表以及命中黑名单的表
+func (rw *BuildinTableBlacklist) FilterBuildinTableBlacklist(ctx context.Context, dbTypeS string, tables []string) ([]string, []string, error) {
+	blacklists, err := rw.DetailBuildinTableBlacklist(ctx, &BuildinTableBlacklist{DBTypeS: dbTypeS})
+	if err != nil {
+		return tables, []string{}, err
+	}
+	if len(blacklists) == 0 {
+		return tables, []string{}, nil
+	}
+
+	var patterns []string
+	for _, b := range blacklists {
+		patterns = append(patterns, b.TablePattern)
+	}
+	f, err := filter.Parse(patterns)
+	if err != nil {
+		return tables, []string{}, fmt.Errorf("parse table [buildin_table_blacklist] pattern failed: %v", err)
+	}
+
+	var (
+		normalTables    []string
+		blacklistTables []string
+	)
+	for _, t := range tables {
+		if f.MatchTable(t) {
+			blacklistTables = append(blacklistTables, t)
+		} else {
+			normalTables = append(normalTables, t)
+		}
+	}
+	return normalTables, blacklistTables, nil
+}
+
+func (rw *BuildinTableBlacklist) InitO2MBuildinTableBlacklist(ctx context.Context) error {
+	var blacklists []*BuildinTableBlacklist
+
+	blacklists = append(blacklists, &BuildinTableBlacklist{
+		DBTypeS:      common.DatabaseTypeOracle,
+		TablePattern: common.BuildInOracleBlacklistRecycleBin,
+		ObjectType:   common.BuildInOracleBlacklistTypeRecycle,
+	})
+	blacklists = append(blacklists, &BuildinTableBlacklist{
+		DBTypeS:      common.DatabaseTypeOracle,
+		TablePattern: common.BuildInOracleBlacklistMViewLog,
+		ObjectType:   common.BuildInOracleBlacklistTypeMViewLog,
+	})
+	blacklists = append(blacklists, &BuildinTableBlacklist{
+		DBTypeS:      common.DatabaseTypeOracle,
+		TablePattern: common.BuildInOracleBlacklistMViewRupd,
+		ObjectType:   common.BuildInOracleBlacklistTypeMViewLog,
+	})
+	blacklists = append(blacklists, &BuildinTableBlacklist{
+		DBTypeS:      common.DatabaseTypeOracle,
+		TablePattern: common.BuildInOracleBlacklistIOTOverflow,
+		ObjectType:   common.BuildInOracleBlacklistTypeIOT,
+	})
+	blacklists = append(blacklists, &BuildinTableBlacklist{
+		DBTypeS:      common.DatabaseTypeOracle,
+		TablePattern: common.BuildInOracleBlacklistFBA,
+		ObjectType:   common.BuildInOracleBlacklistTypeFBA,
+	})
+
+	return rw.DB(ctx).Clauses(clause.Insert{Modifier: "IGNORE"}).CreateInBatches(blacklists, 20).Error
+}
@@ -0,0 +1,108 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package meta
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// check 修复 SQL 按依赖顺序执行记录，失败后重跑跳过已成功语句
+type CheckFixApply struct {
+	ID          uint   `gorm:"primary_key;autoIncrement;comment:'自增编号'" json:"id"`
+	DBTypeS     string `gorm:"type:varchar(15);index:idx_dbtype_st_fix,unique;comment:'源数据库类型'" json:"db_type_s"`
+	DBTypeT     string `gorm:"type:varchar(15);index:idx_dbtype_st_fix,unique;comment:'目标数据库类型'" json:"db_type_t"`
+	SchemaNameS string `gorm:"type:varchar(100);not null;index:idx_dbtype_st_fix,unique;comment:'源端库 schema'" json:"schema_name_s"`
+	SQLDigest   string `gorm:"type:varchar(32);not null;index:idx_dbtype_st_fix,unique;comment:'修复 SQL 摘要'" json:"sql_digest"`
+	SchemaNameT string `gorm:"type:varchar(100);not null;comment:'目标端 schema'" json:"schema_name_t"`
+	FixStage    string `gorm:"type:varchar(15);not null;comment:'修复阶段'" json:"fix_stage"`
+	FixOrder    int    `gorm:"not null;comment:'执行顺序'" json:"fix_order"`
+	SQLDetail   string `gorm:"type:text;comment:'修复 SQL'" json:"sql_detail"`
+	ApplyStatus string `gorm:"type:varchar(15);not null;comment:'执行状态'" json:"apply_status"`
+	ErrorDetail string `gorm:"type:text;comment:'错误详情'" json:"error_detail"`
+	*BaseModel
+}
+
+func NewCheckFixApplyModel(m *Meta) *CheckFixApply {
+	return &CheckFixApply{BaseModel: &BaseModel{
+		Meta: m,
+	}}
+}
+
+func (rw *CheckFixApply) ParseSchemaTable() (string, error) {
+	stmt := &gorm.Statement{DB: rw.GormDB}
+	err := stmt.Parse(rw)
+	if err != nil {
+		return "", fmt.Errorf("parse struct [CheckFixApply] get table_name failed: %v", err)
+	}
+	return stmt.Schema.Table, nil
+}
+
+// BatchCreateCheckFixApply 已登记的修复 SQL 保留原执行状态
+func (rw *CheckFixApply) BatchCreateCheckFixApply(ctx context.Context, createS []CheckFixApply) error {
+	if len(createS) == 0 {
+		return nil
+	}
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	if err = rw.DB(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "db_type_s"},
+			{Name: "db_type_t"},
+			{Name: "schema_name_s"},
+			{Name: "sql_digest"},
+		},
+		DoNothing: true,
+	}).CreateInBatches(createS, 50).Error; err != nil {
+		return fmt.Errorf("batch create table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
+
+// DetailCheckFixApply 按执行顺序返回
+func (rw *CheckFixApply) DetailCheckFixApply(ctx context.Context, detailS *CheckFixApply) ([]CheckFixApply, error) {
+	var fixes []CheckFixApply
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return fixes, err
+	}
+	if err = rw.DB(ctx).Where(detailS).Order("fix_order ASC, id ASC").Find(&fixes).Error; err != nil {
+		return fixes, fmt.Errorf("detail table [%s] record failed: %v", table, err)
+	}
+	return fixes, nil
+}
+
+func (rw *CheckFixApply) UpdateCheckFixApply(ctx context.Context, updateS *CheckFixApply, updates map[string]interface{}) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	if err = rw.DB(ctx).Model(&CheckFixApply{}).
+		Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND sql_digest = ?",
+			common.StringUPPER(updateS.DBTypeS),
+			common.StringUPPER(updateS.DBTypeT),
+			common.StringUPPER(updateS.SchemaNameS),
+			updateS.SQLDigest).
+		Updates(updates).Error; err != nil {
+		return fmt.Errorf("update table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package meta
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+)
+
+// 数据校验不一致 chunk 明细，每次校验不一致 chunk 记录一行，修复 SQL 见 fix-sql-dir 对应文件
+type CompareSyncMeta struct {
+	ID             uint   `gorm:"primary_key;autoIncrement;comment:'自增编号'" json:"id"`
+	DBTypeS        string `gorm:"type:varchar(15);index:idx_dbtype_st_map,unique;comment:'源数据库类型'" json:"db_type_s"`
+	DBTypeT        string `gorm:"type:varchar(15);index:idx_dbtype_st_map,unique;comment:'目标数据库类型'" json:"db_type_t"`
+	SchemaNameS    string `gorm:"type:varchar(100);not null;index:idx_dbtype_st_map,unique;comment:'源端 schema'" json:"schema_name_s"`
+	TableNameS     string `gorm:"type:varchar(100);not null;index:idx_dbtype_st_map,unique;comment:'源端表名'" json:"table_name_s"`
+	SchemaNameT    string `gorm:"type:varchar(100);not null;comment:'目标 schema'" json:"schema_name_t"`
+	TableNameT     string `gorm:"type:varchar(100);not null;comment:'目标表名'" json:"table_name_t"`
+	WhereRange     string `gorm:"type:varchar(300);not null;index:idx_dbtype_st_map,unique;comment:'chunk 范围'" json:"where_range"`
+	ChecksumAlgo   string `gorm:"type:varchar(15);comment:'校验和算法'" json:"checksum_algo"`
+	ChecksumS      uint32 `gorm:"comment:'源端 chunk 校验和'" json:"checksum_s"`
+	ChecksumT      uint32 `gorm:"comment:'目标端 chunk 校验和'" json:"checksum_t"`
+	RowsS          int64  `gorm:"comment:'源端 chunk 行数'" json:"rows_s"`
+	RowsT          int64  `gorm:"comment:'目标端 chunk 行数'" json:"rows_t"`
+	SourceMoreRows int64  `gorm:"comment:'源端多出行数（目标端需 INSERT）'" json:"source_more_rows"`
+	TargetMoreRows int64  `gorm:"comment:'目标端多出行数（目标端需 DELETE）'" json:"target_more_rows"`
+	FixFile        string `gorm:"type:varchar(300);comment:'修复 SQL 文件'" json:"fix_file"`
+	TaskMode       string `gorm:"type:varchar(30);not null;index:idx_dbtype_st_map,unique;comment:'任务模式'" json:"task_mode"`
+	*BaseModel
+}
+
+func NewCompareSyncMetaModel(m *Meta) *CompareSyncMeta {
+	return &CompareSyncMeta{BaseModel: &BaseModel{
+		Meta: m}}
+}
+
+func (rw *CompareSyncMeta) ParseSchemaTable() (string, error) {
+	stmt := &gorm.Statement{DB: rw.GormDB}
+	err := stmt.Parse(rw)
+	if err != nil {
+		return "", fmt.Errorf("parse struct [CompareSyncMeta] get table_name failed: %v", err)
+	}
+	return stmt.Schema.Table, nil
+}
+
+// SaveCompareSyncMeta 同一 chunk 重复校验覆盖上次结果
+func (rw *CompareSyncMeta) SaveCompareSyncMeta(ctx context.Context, createS *CompareSyncMeta) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	if err = rw.DB(ctx).Where(&CompareSyncMeta{
+		DBTypeS:     createS.DBTypeS,
+		DBTypeT:     createS.DBTypeT,
+		SchemaNameS: createS.SchemaNameS,
+		TableNameS:  createS.TableNameS,
+		WhereRange:  createS.WhereRange,
+		TaskMode:    createS.TaskMode,
+	}).Delete(&CompareSyncMeta{}).Error; err != nil {
+		return fmt.Errorf("delete table [%s] record failed: %v", table, err)
+	}
+	if err = rw.DB(ctx).Create(createS).Error; err != nil {
+		return fmt.Errorf("create table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
+
+// DeleteCompareSyncMeta 表重新校验前清理历史不一致记录
+func (rw *CompareSyncMeta) DeleteCompareSyncMeta(ctx context.Context, deleteS *CompareSyncMeta) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	if err = rw.DB(ctx).Where(deleteS).Delete(&CompareSyncMeta{}).Error; err != nil {
+		return fmt.Errorf("delete table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
+
+func (rw *CompareSyncMeta) DetailCompareSyncMeta(ctx context.Context, detailS *CompareSyncMeta) ([]CompareSyncMeta, error) {
+	var dsMetas []CompareSyncMeta
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return dsMetas, err
+	}
+	if err = rw.DB(ctx).Where(detailS).Find(&dsMetas).Error; err != nil {
+		return dsMetas, fmt.Errorf("detail table [%s] record failed: %v", table, err)
+	}
+	return dsMetas, nil
+}
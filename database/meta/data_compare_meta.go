@@ -34,6 +34,8 @@ type DataCompareMeta struct {
 	TableNameT    string `gorm:"not null;comment:'目标端表名'" json:"table_name_t"`
 	ColumnDetailT string `gorm:"type:text;comment:'目标端查询字段信息'" json:"column_detail_t"`
 	WhereColumn   string `gorm:"comment:'查询类型字段列'" json:"where_column"`
+	IdentityS     string `gorm:"type:text;comment:'源端行标识表达式'" json:"identity_s"`
+	IdentityT     string `gorm:"type:text;comment:'目标端行标识表达式'" json:"identity_t"`
 	WhereRange    string `gorm:"not null;index:idx_dbtype_st_obj,unique;comment:'查询 where 条件'" json:"where_range"`
 	TaskMode      string `gorm:"not null;index:idx_dbtype_st_obj,unique;comment:'任务模式'" json:"task_mode"`
 	TaskStatus    string `gorm:"not null;comment:'数据对比状态,only waiting,success,failed'" json:"task_status"`
@@ -122,6 +124,7 @@ func (rw *DataCompareMeta) UpdateDataCompareMeta(ctx context.Context, deleteS *D
 		Updates(updates).Error; err != nil {
 		return fmt.Errorf("update table [%s] record failed: %v", table, err)
 	}
+	observeChunkStatus(deleteS.TaskMode, deleteS.SchemaNameS, deleteS.TableNameS, updates)
 	return nil
 }
 
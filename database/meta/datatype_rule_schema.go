@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"github.com/wentaojin/transferdb/common"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // 自定义库转换规则 - schema 级别
@@ -63,3 +64,24 @@ func (rw *SchemaDatatypeRule) DetailSchemaRule(ctx context.Context, detailS *Sch
 	}
 	return schemaRuleMap, nil
 }
+
+// UpsertSchemaRule 配置文件规则写入，规则已存在则更新目标字段类型
+func (rw *SchemaDatatypeRule) UpsertSchemaRule(ctx context.Context, upsertS *SchemaDatatypeRule) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	err = rw.DB(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "db_type_s"},
+			{Name: "db_type_t"},
+			{Name: "schema_name_s"},
+			{Name: "column_type_s"},
+		},
+		DoUpdates: clause.AssignmentColumns([]string{"column_type_t", "updated_at"}),
+	}).Create(upsertS).Error
+	if err != nil {
+		return fmt.Errorf("upsert table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
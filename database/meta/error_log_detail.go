@@ -19,6 +19,8 @@ import (
 	"context"
 	"fmt"
 	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/metrics"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
@@ -34,6 +36,7 @@ type ErrorLogDetail struct {
 	TaskStatus  string `gorm:"not null;comment:'任务状态'" json:"task_status"`
 	InfoDetail  string `gorm:"not null;comment:'信息详情'" json:"info_detail"`
 	ErrorDetail string `gorm:"not null;comment:'错误详情'" json:"error_detail"`
+	Suggestion  string `gorm:"type:text;comment:'处理建议'" json:"suggestion"`
 	*BaseModel
 }
 
@@ -54,14 +57,27 @@ func (rw *ErrorLogDetail) ParseSchemaTable() (string, error) {
 	return stmt.Schema.Table, nil
 }
 
+// CreateErrorLog 写入错误记录，按错误码自动补充处理建议
 func (rw *ErrorLogDetail) CreateErrorLog(ctx context.Context, createS *ErrorLogDetail) error {
 	table, err := rw.ParseSchemaTable()
 	if err != nil {
 		return err
 	}
+	if createS.Suggestion == "" {
+		createS.Suggestion = common.ErrorSuggestion(createS.ErrorDetail)
+	}
+	if createS.Suggestion != "" {
+		zap.L().Warn("error log detail suggestion",
+			zap.String("schema", createS.SchemaNameS),
+			zap.String("table", createS.TableNameS),
+			zap.String("task mode", createS.TaskMode),
+			zap.String("error", createS.ErrorDetail),
+			zap.String("suggest", createS.Suggestion))
+	}
 	if err = rw.DB(ctx).Create(createS).Error; err != nil {
 		return fmt.Errorf("create table [%s] record failed: %v", table, err)
 	}
+	metrics.ErrorCounter.WithLabelValues(common.StringUPPER(createS.TaskMode), createS.SchemaNameS, createS.TableNameS).Inc()
 	return nil
 }
 
@@ -99,3 +115,23 @@ func (rw *ErrorLogDetail) CountsErrorLogBySchema(ctx context.Context, detailS *E
 	}
 	return totals, nil
 }
+
+// DistinctErrorSuggestion 汇总任务错误处理建议，用于任务结束报告
+func (rw *ErrorLogDetail) DistinctErrorSuggestion(ctx context.Context, detailS *ErrorLogDetail) ([]string, error) {
+	var suggestions []string
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return suggestions, err
+	}
+	if err = rw.DB(ctx).Model(&ErrorLogDetail{}).
+		Where(`db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND task_mode = ? AND suggestion <> ''`,
+			common.StringUPPER(detailS.DBTypeS),
+			common.StringUPPER(detailS.DBTypeT),
+			common.StringUPPER(detailS.SchemaNameS),
+			detailS.TaskMode).
+		Distinct().
+		Pluck("suggestion", &suggestions).Error; err != nil {
+		return suggestions, fmt.Errorf("distinct table [%s] column [suggestion] failed: %v", table, err)
+	}
+	return suggestions, nil
+}
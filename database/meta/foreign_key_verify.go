@@ -0,0 +1,80 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package meta
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"gorm.io/gorm"
+)
+
+// 下游引用完整性校验结果
+type ForeignKeyVerify struct {
+	ID             uint   `gorm:"primary_key;autoIncrement;comment:'自增编号'" json:"id"`
+	DBTypeS        string `gorm:"type:varchar(15);index:idx_dbtype_schema;comment:'源数据库类型'" json:"db_type_s"`
+	DBTypeT        string `gorm:"type:varchar(15);index:idx_dbtype_schema;comment:'目标数据库类型'" json:"db_type_t"`
+	SchemaNameT    string `gorm:"type:varchar(64);not null;index:idx_dbtype_schema;comment:'目标端 schema'" json:"schema_name_t"`
+	TableNameT     string `gorm:"type:varchar(64);not null;comment:'目标端子表名'" json:"table_name_t"`
+	ColumnListT    string `gorm:"type:varchar(1000);comment:'目标端子表关联字段'" json:"column_list_t"`
+	RTableNameT    string `gorm:"type:varchar(64);not null;comment:'目标端父表名'" json:"rtable_name_t"`
+	RColumnListT   string `gorm:"type:varchar(1000);comment:'目标端父表被引用字段'" json:"rcolumn_list_t"`
+	RelationSource string `gorm:"type:varchar(15);comment:'关系来源 DEFINED/CONFIG'" json:"relation_source"`
+	OrphanRows     int64  `gorm:"comment:'孤儿数据行数'" json:"orphan_rows"`
+	SampleKeys     string `gorm:"type:text;comment:'孤儿数据样例键值'" json:"sample_keys"`
+	TaskStatus     string `gorm:"not null;comment:'校验状态'" json:"task_status"`
+	ErrorDetail    string `gorm:"type:text;comment:'错误详情'" json:"error_detail"`
+	*BaseModel
+}
+
+func NewForeignKeyVerifyModel(m *Meta) *ForeignKeyVerify {
+	return &ForeignKeyVerify{BaseModel: &BaseModel{
+		Meta: m}}
+}
+
+func (rw *ForeignKeyVerify) ParseSchemaTable() (string, error) {
+	stmt := &gorm.Statement{DB: rw.GormDB}
+	err := stmt.Parse(rw)
+	if err != nil {
+		return "", fmt.Errorf("parse struct [ForeignKeyVerify] get table_name failed: %v", err)
+	}
+	return stmt.Schema.Table, nil
+}
+
+func (rw *ForeignKeyVerify) CreateForeignKeyVerify(ctx context.Context, createS *ForeignKeyVerify) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	if err = rw.DB(ctx).Create(createS).Error; err != nil {
+		return fmt.Errorf("create table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
+
+func (rw *ForeignKeyVerify) DeleteForeignKeyVerify(ctx context.Context, deleteS *ForeignKeyVerify) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	if err = rw.DB(ctx).Where("db_type_s = ? AND db_type_t = ? AND schema_name_t = ?",
+		common.StringUPPER(deleteS.DBTypeS),
+		common.StringUPPER(deleteS.DBTypeT),
+		common.StringUPPER(deleteS.SchemaNameT)).Delete(&ForeignKeyVerify{}).Error; err != nil {
+		return fmt.Errorf("delete table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package meta
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+)
+
+// 任务钩子执行记录
+type HookHistory struct {
+	ID          uint   `gorm:"primary_key;autoIncrement;comment:'自增编号'" json:"id"`
+	TaskMode    string `gorm:"type:varchar(15);not null;index:idx_mode_schema_table;comment:'任务模式'" json:"task_mode"`
+	SchemaNameS string `gorm:"type:varchar(64);index:idx_mode_schema_table;comment:'源端 schema'" json:"schema_name_s"`
+	TableNameS  string `gorm:"type:varchar(64);index:idx_mode_schema_table;comment:'源端表名，任务级别钩子为空'" json:"table_name_s"`
+	HookScope   string `gorm:"type:varchar(15);not null;comment:'钩子范围'" json:"hook_scope"`
+	HookStage   string `gorm:"type:varchar(15);not null;comment:'钩子执行阶段'" json:"hook_stage"`
+	HookType    string `gorm:"type:varchar(15);not null;comment:'钩子类型'" json:"hook_type"`
+	HookDetail  string `gorm:"type:text;comment:'钩子命令或者 SQL 脚本'" json:"hook_detail"`
+	TaskStatus  string `gorm:"not null;comment:'执行状态'" json:"task_status"`
+	Output      string `gorm:"type:text;comment:'执行输出'" json:"output"`
+	Cost        string `gorm:"comment:'执行耗时'" json:"cost"`
+	ErrorDetail string `gorm:"type:text;comment:'错误详情'" json:"error_detail"`
+	*BaseModel
+}
+
+func NewHookHistoryModel(m *Meta) *HookHistory {
+	return &HookHistory{BaseModel: &BaseModel{
+		Meta: m}}
+}
+
+func (rw *HookHistory) ParseSchemaTable() (string, error) {
+	stmt := &gorm.Statement{DB: rw.GormDB}
+	err := stmt.Parse(rw)
+	if err != nil {
+		return "", fmt.Errorf("parse struct [HookHistory] get table_name failed: %v", err)
+	}
+	return stmt.Schema.Table, nil
+}
+
+func (rw *HookHistory) CreateHookHistory(ctx context.Context, createS *HookHistory) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	if err = rw.DB(ctx).Create(createS).Error; err != nil {
+		return fmt.Errorf("create table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
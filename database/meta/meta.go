@@ -20,6 +20,7 @@ import (
 	"database/sql"
 	"fmt"
 	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/tunnel"
 	"github.com/wentaojin/transferdb/logger"
 	"go.uber.org/zap"
 	"gorm.io/driver/mysql"
@@ -32,13 +33,33 @@ type Meta struct {
 }
 
 func NewMetaDBEngine(ctx context.Context, mysqlCfg config.MySQLConfig, slowThreshold int) (*Meta, error) {
-	// 创建元数据库
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/?charset=utf8mb4&parseTime=True&loc=Local",
-		mysqlCfg.Username, mysqlCfg.Password, mysqlCfg.Host, mysqlCfg.Port)
+	endpoints, err := tunnel.ForwardAddrs(mysqlCfg.Tunnel, mysqlCfg.Host, mysqlCfg.Port, mysqlCfg.Addrs)
+	if err != nil {
+		return &Meta{}, err
+	}
 
-	mysqlDB, err := sql.Open("mysql", dsn)
+	// 创建元数据库，多地址依次建连取首个可用地址
+	var (
+		addr    string
+		mysqlDB *sql.DB
+	)
+	for _, addr = range endpoints {
+		dsn := fmt.Sprintf("%s:%s@tcp(%s)/?charset=utf8mb4&parseTime=True&loc=Local",
+			mysqlCfg.Username, mysqlCfg.Password, addr)
+		mysqlDB, err = sql.Open("mysql", dsn)
+		if err != nil {
+			return &Meta{}, fmt.Errorf("error on open general database connection [%v]: %v", mysqlCfg.MetaSchema, err)
+		}
+		if err = mysqlDB.Ping(); err == nil {
+			break
+		}
+		mysqlDB.Close()
+		zap.L().Warn("meta database addr connect failed, try next addr",
+			zap.String("addr", addr),
+			zap.Error(err))
+	}
 	if err != nil {
-		return &Meta{}, fmt.Errorf("error on open general database connection [%v]: %v", mysqlCfg.MetaSchema, err)
+		return &Meta{}, fmt.Errorf("error on connect meta database, all addrs %v unavailable: %v", endpoints, err)
 	}
 
 	createSchema := fmt.Sprintf(`CREATE DATABASE IF NOT EXISTS %s`, mysqlCfg.MetaSchema)
@@ -58,8 +79,8 @@ func NewMetaDBEngine(ctx context.Context, mysqlCfg config.MySQLConfig, slowThres
 
 	// 初始化 MetaDB
 	// 初始化 gorm 日志记录器
-	dsn = fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		mysqlCfg.Username, mysqlCfg.Password, mysqlCfg.Host, mysqlCfg.Port, mysqlCfg.MetaSchema)
+	dsn := fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		mysqlCfg.Username, mysqlCfg.Password, addr, mysqlCfg.MetaSchema)
 	l := logger.NewGormLogger(zap.L(), slowThreshold)
 	l.SetAsDefault()
 	gormDB, err := gorm.Open(mysql.New(mysql.Config{
@@ -108,15 +129,29 @@ func (m *Meta) MigrateTables() (err error) {
 		new(TableDatatypeRule),
 		new(SchemaDatatypeRule),
 		new(DataCompareMeta),
+		new(CompareSyncMeta),
 		new(WaitSyncMeta),
 		new(FullSyncMeta),
 		new(IncrSyncMeta),
+		new(MVLogSyncMeta),
+		new(LoadSyncMeta),
+		new(ShipSyncMeta),
+		new(OGGOffsetMeta),
+		new(HookHistory),
+		new(MetaSlowQuery),
+		new(ForeignKeyVerify),
+		new(RollbackSnapshot),
 		new(ErrorLogDetail),
 		new(BuildinGlobalDefaultval),
 		new(BuildinColumnDefaultval),
 		new(BuildinObjectCompatible),
 		new(BuildinDatatypeRule),
+		new(BuildinTableBlacklist),
 		new(TableNameRule),
+		new(IdentifierNameRule),
+		new(ReverseDecision),
+		new(CheckFixApply),
+		new(IncrSyncGTID),
 	)
 }
 
@@ -141,6 +176,10 @@ func (m *Meta) InitDefaultValue(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	err = NewBuildinTableBlacklistModel(m).InitO2MBuildinTableBlacklist(ctx)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -0,0 +1,236 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package meta
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 元数据库慢查询诊断记录
+type MetaSlowQuery struct {
+	ID              uint   `gorm:"primary_key;autoIncrement;comment:'自增编号'" json:"id"`
+	MetaTable       string `gorm:"type:varchar(64);index:idx_meta_table;comment:'元数据表名'" json:"meta_table"`
+	SQLText         string `gorm:"type:text;comment:'慢查询 SQL，包含绑定变量值'" json:"sql_text"`
+	AffectRows      int64  `gorm:"comment:'影响或者返回行数'" json:"affect_rows"`
+	ElapsedMs       int64  `gorm:"comment:'执行耗时，单位毫秒'" json:"elapsed_ms"`
+	FilterColumns   string `gorm:"type:varchar(1000);comment:'等值过滤字段'" json:"filter_columns"`
+	IndexSuggestion string `gorm:"type:text;comment:'索引建议'" json:"index_suggestion"`
+	*BaseModel
+}
+
+func NewMetaSlowQueryModel(m *Meta) *MetaSlowQuery {
+	return &MetaSlowQuery{BaseModel: &BaseModel{
+		Meta: m}}
+}
+
+func (rw *MetaSlowQuery) ParseSchemaTable() (string, error) {
+	stmt := &gorm.Statement{DB: rw.GormDB}
+	err := stmt.Parse(rw)
+	if err != nil {
+		return "", fmt.Errorf("parse struct [MetaSlowQuery] get table_name failed: %v", err)
+	}
+	return stmt.Schema.Table, nil
+}
+
+var (
+	slowQueryTableRegexp  = regexp.MustCompile("(?i)(?:FROM|UPDATE|INTO)\\s+`?(\\w+)`?")
+	slowQueryFilterRegexp = regexp.MustCompile("(?i)(?:`?\\w+`?\\.)?`?(\\w+)`?\\s*(?:=|IN\\s*\\()")
+	slowQueryWhereRegexp  = regexp.MustCompile("(?is)\\sWHERE\\s(.*?)(?:\\sORDER\\s+BY\\s|\\sGROUP\\s+BY\\s|\\sLIMIT\\s|$)")
+)
+
+// slowQueryDiagnostics 慢查询诊断，异步写入诊断表，诊断自身查询不记录日志，避免递归
+type slowQueryDiagnostics struct {
+	db         *gorm.DB
+	table      string
+	indexes    map[string][][]string
+	suggestion map[string]struct{}
+	mu         sync.Mutex
+}
+
+// StartSlowQueryDiagnostics 开启元数据库慢查询诊断
+// 超过 slowlog-threshold 的元数据库查询连同绑定变量值记录至 [meta_slow_query]，并按等值过滤字段与现有索引比对给出索引建议
+func (m *Meta) StartSlowQueryDiagnostics(ctx context.Context) error {
+	table, err := NewMetaSlowQueryModel(m).ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	d := &slowQueryDiagnostics{
+		db:         m.GormDB.Session(&gorm.Session{Logger: gormlogger.Discard}),
+		table:      table,
+		indexes:    make(map[string][][]string),
+		suggestion: make(map[string]struct{}),
+	}
+
+	queryCh := make(chan MetaSlowQuery, 1024)
+	logger.SetSlowQueryRecorder(func(sql string, rows int64, elapsed time.Duration) {
+		// 诊断队列满则丢弃，不阻塞业务查询
+		select {
+		case queryCh <- MetaSlowQuery{SQLText: sql, AffectRows: rows, ElapsedMs: elapsed.Milliseconds()}:
+		default:
+		}
+	})
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				logger.SetSlowQueryRecorder(nil)
+				return
+			case q := <-queryCh:
+				if err := d.diagnose(ctx, q); err != nil {
+					zap.L().Warn("meta slow query diagnose failed", zap.String("sql", q.SQLText), zap.Error(err))
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (d *slowQueryDiagnostics) diagnose(ctx context.Context, q MetaSlowQuery) error {
+	tableMatch := slowQueryTableRegexp.FindStringSubmatch(q.SQLText)
+	if len(tableMatch) < 2 {
+		return nil
+	}
+	q.MetaTable = strings.ToLower(tableMatch[1])
+	if q.MetaTable == d.table {
+		return nil
+	}
+
+	filterCols := parseSlowQueryFilterColumns(q.SQLText)
+	q.FilterColumns = strings.Join(filterCols, ",")
+
+	if len(filterCols) > 0 {
+		indexes, err := d.tableIndexes(ctx, q.MetaTable)
+		if err != nil {
+			return err
+		}
+		q.IndexSuggestion = suggestMetaIndex(q.MetaTable, filterCols, indexes)
+		if q.IndexSuggestion != "" {
+			d.mu.Lock()
+			if _, ok := d.suggestion[q.IndexSuggestion]; !ok {
+				d.suggestion[q.IndexSuggestion] = struct{}{}
+				zap.L().Warn("meta slow query index suggestion",
+					zap.String("table", q.MetaTable),
+					zap.String("filter columns", q.FilterColumns),
+					zap.String("suggestion", q.IndexSuggestion))
+			}
+			d.mu.Unlock()
+		}
+	}
+
+	if err := d.db.WithContext(ctx).Create(&q).Error; err != nil {
+		return fmt.Errorf("create table [%s] record failed: %v", d.table, err)
+	}
+	return nil
+}
+
+// tableIndexes 获取元数据表索引字段，按表缓存
+func (d *slowQueryDiagnostics) tableIndexes(ctx context.Context, tableName string) ([][]string, error) {
+	d.mu.Lock()
+	indexes, ok := d.indexes[tableName]
+	d.mu.Unlock()
+	if ok {
+		return indexes, nil
+	}
+
+	var stats []struct {
+		IndexName  string
+		ColumnName string
+	}
+	if err := d.db.WithContext(ctx).Raw(`SELECT INDEX_NAME AS index_name, COLUMN_NAME AS column_name
+FROM information_schema.STATISTICS
+WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
+ORDER BY INDEX_NAME, SEQ_IN_INDEX`, tableName).Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("get meta table [%s] indexes failed: %v", tableName, err)
+	}
+
+	idxMap := make(map[string]int)
+	for _, s := range stats {
+		i, ok := idxMap[s.IndexName]
+		if !ok {
+			i = len(indexes)
+			idxMap[s.IndexName] = i
+			indexes = append(indexes, []string{})
+		}
+		indexes[i] = append(indexes[i], strings.ToLower(s.ColumnName))
+	}
+
+	d.mu.Lock()
+	d.indexes[tableName] = indexes
+	d.mu.Unlock()
+	return indexes, nil
+}
+
+// parseSlowQueryFilterColumns 解析 WHERE 条件等值以及 IN 过滤字段
+func parseSlowQueryFilterColumns(sql string) []string {
+	whereMatch := slowQueryWhereRegexp.FindStringSubmatch(sql)
+	if len(whereMatch) < 2 {
+		return nil
+	}
+	var cols []string
+	for _, m := range slowQueryFilterRegexp.FindAllStringSubmatch(whereMatch[1], -1) {
+		col := strings.ToLower(m[1])
+		if !common.IsContainString(cols, col) {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
+
+// suggestMetaIndex 现有索引最长前缀可覆盖的等值过滤字段少于全部过滤字段时给出建议
+// 建议索引以最长前缀匹配索引字段打头，其余过滤字段追加
+func suggestMetaIndex(tableName string, filterCols []string, indexes [][]string) string {
+	var bestPrefix []string
+	for _, idx := range indexes {
+		var prefix []string
+		for _, col := range idx {
+			if !common.IsContainString(filterCols, col) {
+				break
+			}
+			prefix = append(prefix, col)
+		}
+		if len(prefix) > len(bestPrefix) {
+			bestPrefix = prefix
+		}
+	}
+	if len(bestPrefix) >= len(filterCols) {
+		return ""
+	}
+
+	suggestCols := append([]string{}, bestPrefix...)
+	for _, col := range filterCols {
+		if !common.IsContainString(suggestCols, col) {
+			suggestCols = append(suggestCols, col)
+		}
+	}
+	// 索引名长度上限 64
+	idxName := common.StringsBuilder("idx_suggest_", strings.Join(suggestCols, "_"))
+	if len(idxName) > 64 {
+		idxName = idxName[:64]
+	}
+	return fmt.Sprintf("ALTER TABLE `%s` ADD INDEX `%s` (`%s`)",
+		tableName, idxName, strings.Join(suggestCols, "`,`"))
+}
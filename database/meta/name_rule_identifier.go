@@ -0,0 +1,88 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package meta
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"gorm.io/gorm"
+)
+
+// 上下游索引、约束名字映射，reverse 阶段截断或冲突重命名时记录
+type IdentifierNameRule struct {
+	ID          uint   `gorm:"primary_key;autoIncrement;comment:'自增编号'" json:"id"`
+	DBTypeS     string `gorm:"type:varchar(15);index:idx_dbtype_st_ident,unique;comment:'源数据库类型'" json:"db_type_s"`
+	DBTypeT     string `gorm:"type:varchar(15);index:idx_dbtype_st_ident,unique;comment:'目标数据库类型'" json:"db_type_t"`
+	SchemaNameS string `gorm:"type:varchar(100);not null;index:idx_dbtype_st_ident,unique;comment:'源端库 schema'" json:"schema_name_s"`
+	TableNameS  string `gorm:"type:varchar(100);not null;index:idx_dbtype_st_ident,unique;comment:'源端表名'" json:"table_name_s"`
+	ObjectType  string `gorm:"type:varchar(30);not null;index:idx_dbtype_st_ident,unique;comment:'对象类型'" json:"object_type"`
+	ObjectNameS string `gorm:"type:varchar(300);not null;index:idx_dbtype_st_ident,unique;comment:'源端对象名'" json:"object_name_s"`
+	SchemaNameT string `gorm:"type:varchar(100);not null;comment:'目标库 schema'" json:"schema_name_t"`
+	TableNameT  string `gorm:"type:varchar(100);not null;comment:'目标表名'" json:"table_name_t"`
+	ObjectNameT string `gorm:"type:varchar(100);not null;comment:'目标对象名'" json:"object_name_t"`
+	*BaseModel
+}
+
+func NewIdentifierNameRuleModel(m *Meta) *IdentifierNameRule {
+	return &IdentifierNameRule{BaseModel: &BaseModel{
+		Meta: m,
+	}}
+}
+
+func (rw *IdentifierNameRule) ParseSchemaTable() (string, error) {
+	stmt := &gorm.Statement{DB: rw.GormDB}
+	err := stmt.Parse(rw)
+	if err != nil {
+		return "", fmt.Errorf("parse struct [IdentifierNameRule] get table_name failed: %v", err)
+	}
+	return stmt.Schema.Table, nil
+}
+
+// ReplaceIdentifierNameRule 清理 schema 历史映射后批量写入本次 reverse 生成结果
+func (rw *IdentifierNameRule) ReplaceIdentifierNameRule(ctx context.Context, deleteS *IdentifierNameRule, createS []IdentifierNameRule) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	return rw.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err = tx.Where("UPPER(db_type_s) = ? AND UPPER(db_type_t) = ? AND UPPER(schema_name_s) = ?",
+			common.StringUPPER(deleteS.DBTypeS),
+			common.StringUPPER(deleteS.DBTypeT),
+			common.StringUPPER(deleteS.SchemaNameS)).Delete(&IdentifierNameRule{}).Error; err != nil {
+			return fmt.Errorf("delete table [%s] record failed: %v", table, err)
+		}
+		if len(createS) == 0 {
+			return nil
+		}
+		if err = tx.CreateInBatches(createS, 20).Error; err != nil {
+			return fmt.Errorf("batch create table [%s] record failed: %v", table, err)
+		}
+		return nil
+	})
+}
+
+func (rw *IdentifierNameRule) DetailIdentifierNameRule(ctx context.Context, detailS *IdentifierNameRule) ([]IdentifierNameRule, error) {
+	var identRules []IdentifierNameRule
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return identRules, err
+	}
+	if err = rw.DB(ctx).Where(detailS).Find(&identRules).Error; err != nil {
+		return identRules, fmt.Errorf("detail table [%s] record failed: %v", table, err)
+	}
+	return identRules, nil
+}
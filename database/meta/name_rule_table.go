@@ -66,3 +66,20 @@ func (rw *TableNameRule) DetailTableNameRule(ctx context.Context, detailS *Table
 	}
 	return tableRuleMap, nil
 }
+
+// DetailTableNameRuleMap 源端表名 -> 目标表名，显式规则优先，sourceTables 其余表按正则改写规则映射并做目标表名冲突检测
+func (rw *TableNameRule) DetailTableNameRuleMap(ctx context.Context, detailS *TableNameRule, sourceTables []string, rewriter *common.NameRewriter) (map[string]string, error) {
+	tableNameRules, err := rw.DetailTableNameRule(ctx, detailS)
+	if err != nil {
+		return nil, err
+	}
+	customTableNameRule := make(map[string]string)
+	for _, tr := range tableNameRules {
+		customTableNameRule[common.StringUPPER(tr.TableNameS)] = common.StringUPPER(tr.TableNameT)
+	}
+	tableNameRuleMap, err := common.GenNameRewriteMap(customTableNameRule, sourceTables, rewriter)
+	if err != nil {
+		return nil, fmt.Errorf("schema [%s] table name rule failed: %v", detailS.SchemaNameS, err)
+	}
+	return tableNameRuleMap, nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package meta
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"gorm.io/gorm"
+)
+
+// 上一次 reverse 的类型映射、重命名、排序规则等决策，重跑时对比输出差异
+type ReverseDecision struct {
+	ID            uint   `gorm:"primary_key;autoIncrement;comment:'自增编号'" json:"id"`
+	DBTypeS       string `gorm:"type:varchar(15);index:idx_dbtype_st_decision,unique;comment:'源数据库类型'" json:"db_type_s"`
+	DBTypeT       string `gorm:"type:varchar(15);index:idx_dbtype_st_decision,unique;comment:'目标数据库类型'" json:"db_type_t"`
+	SchemaNameS   string `gorm:"type:varchar(100);not null;index:idx_dbtype_st_decision,unique;comment:'源端库 schema'" json:"schema_name_s"`
+	TableNameS    string `gorm:"type:varchar(100);not null;index:idx_dbtype_st_decision,unique;comment:'源端表名'" json:"table_name_s"`
+	DecisionType  string `gorm:"type:varchar(30);not null;index:idx_dbtype_st_decision,unique;comment:'决策类型'" json:"decision_type"`
+	ObjectNameS   string `gorm:"type:varchar(300);not null;index:idx_dbtype_st_decision,unique;comment:'源端对象名'" json:"object_name_s"`
+	DecisionValue string `gorm:"type:text;comment:'决策结果'" json:"decision_value"`
+	*BaseModel
+}
+
+func NewReverseDecisionModel(m *Meta) *ReverseDecision {
+	return &ReverseDecision{BaseModel: &BaseModel{
+		Meta: m,
+	}}
+}
+
+func (rw *ReverseDecision) ParseSchemaTable() (string, error) {
+	stmt := &gorm.Statement{DB: rw.GormDB}
+	err := stmt.Parse(rw)
+	if err != nil {
+		return "", fmt.Errorf("parse struct [ReverseDecision] get table_name failed: %v", err)
+	}
+	return stmt.Schema.Table, nil
+}
+
+// ReplaceReverseDecision 清理 schema 上一次决策后批量写入本次 reverse 决策
+func (rw *ReverseDecision) ReplaceReverseDecision(ctx context.Context, deleteS *ReverseDecision, createS []ReverseDecision) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	return rw.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err = tx.Where("UPPER(db_type_s) = ? AND UPPER(db_type_t) = ? AND UPPER(schema_name_s) = ?",
+			common.StringUPPER(deleteS.DBTypeS),
+			common.StringUPPER(deleteS.DBTypeT),
+			common.StringUPPER(deleteS.SchemaNameS)).Delete(&ReverseDecision{}).Error; err != nil {
+			return fmt.Errorf("delete table [%s] record failed: %v", table, err)
+		}
+		if len(createS) == 0 {
+			return nil
+		}
+		if err = tx.CreateInBatches(createS, 50).Error; err != nil {
+			return fmt.Errorf("batch create table [%s] record failed: %v", table, err)
+		}
+		return nil
+	})
+}
+
+func (rw *ReverseDecision) DetailReverseDecision(ctx context.Context, detailS *ReverseDecision) ([]ReverseDecision, error) {
+	var decisions []ReverseDecision
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return decisions, err
+	}
+	if err = rw.DB(ctx).Where(detailS).Find(&decisions).Error; err != nil {
+		return decisions, fmt.Errorf("detail table [%s] record failed: %v", table, err)
+	}
+	return decisions, nil
+}
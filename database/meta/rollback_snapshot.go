@@ -0,0 +1,127 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package meta
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"gorm.io/gorm"
+)
+
+// 下游切换回退快照
+type RollbackSnapshot struct {
+	ID            uint   `gorm:"primary_key;autoIncrement;comment:'自增编号'" json:"id"`
+	DBTypeT       string `gorm:"type:varchar(15);index:idx_dbtype_schema_snapshot;comment:'目标数据库类型'" json:"db_type_t"`
+	SchemaNameT   string `gorm:"type:varchar(64);not null;index:idx_dbtype_schema_snapshot;comment:'目标端 schema'" json:"schema_name_t"`
+	SnapshotID    string `gorm:"type:varchar(30);not null;index:idx_dbtype_schema_snapshot;comment:'快照编号'" json:"snapshot_id"`
+	TableNameT    string `gorm:"type:varchar(64);not null;comment:'目标端表名'" json:"table_name_t"`
+	TaskMode      string `gorm:"type:varchar(15);not null;comment:'生成快照任务模式'" json:"task_mode"`
+	FileName      string `gorm:"type:varchar(1000);comment:'快照文件'" json:"file_name"`
+	ColumnDetailT string `gorm:"type:text;comment:'快照字段（排除生成列）'" json:"column_detail_t"`
+	TableRows     int64  `gorm:"comment:'快照行数'" json:"table_rows"`
+	TaskStatus    string `gorm:"not null;comment:'快照状态'" json:"task_status"`
+	RestoreStatus string `gorm:"type:varchar(15);comment:'回退状态'" json:"restore_status"`
+	ErrorDetail   string `gorm:"type:text;comment:'错误详情'" json:"error_detail"`
+	*BaseModel
+}
+
+func NewRollbackSnapshotModel(m *Meta) *RollbackSnapshot {
+	return &RollbackSnapshot{BaseModel: &BaseModel{
+		Meta: m}}
+}
+
+func (rw *RollbackSnapshot) ParseSchemaTable() (string, error) {
+	stmt := &gorm.Statement{DB: rw.GormDB}
+	err := stmt.Parse(rw)
+	if err != nil {
+		return "", fmt.Errorf("parse struct [RollbackSnapshot] get table_name failed: %v", err)
+	}
+	return stmt.Schema.Table, nil
+}
+
+func (rw *RollbackSnapshot) CreateRollbackSnapshot(ctx context.Context, createS *RollbackSnapshot) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	if err = rw.DB(ctx).Create(createS).Error; err != nil {
+		return fmt.Errorf("create table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
+
+func (rw *RollbackSnapshot) DetailRollbackSnapshot(ctx context.Context, detailS *RollbackSnapshot) ([]RollbackSnapshot, error) {
+	var dsMetas []RollbackSnapshot
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return dsMetas, err
+	}
+	if err = rw.DB(ctx).Where(detailS).Find(&dsMetas).Error; err != nil {
+		return dsMetas, fmt.Errorf("detail table [%s] record failed: %v", table, err)
+	}
+	return dsMetas, nil
+}
+
+// DistinctRollbackSnapshotID 快照编号按时间倒序
+func (rw *RollbackSnapshot) DistinctRollbackSnapshotID(ctx context.Context, detailS *RollbackSnapshot) ([]string, error) {
+	var snapshotIDs []string
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return snapshotIDs, err
+	}
+	if err = rw.DB(ctx).Model(&RollbackSnapshot{}).
+		Where("db_type_t = ? AND schema_name_t = ?",
+			common.StringUPPER(detailS.DBTypeT),
+			common.StringUPPER(detailS.SchemaNameT)).
+		Distinct().
+		Order("snapshot_id DESC").
+		Pluck("snapshot_id", &snapshotIDs).Error; err != nil {
+		return snapshotIDs, fmt.Errorf("distinct table [%s] column [snapshot_id] failed: %v", table, err)
+	}
+	return snapshotIDs, nil
+}
+
+func (rw *RollbackSnapshot) UpdateRollbackSnapshot(ctx context.Context, updateS *RollbackSnapshot, updates map[string]interface{}) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	if err = rw.DB(ctx).Model(&RollbackSnapshot{}).
+		Where("db_type_t = ? AND schema_name_t = ? AND snapshot_id = ? AND table_name_t = ?",
+			common.StringUPPER(updateS.DBTypeT),
+			common.StringUPPER(updateS.SchemaNameT),
+			updateS.SnapshotID,
+			common.StringUPPER(updateS.TableNameT)).
+		Updates(updates).Error; err != nil {
+		return fmt.Errorf("update table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
+
+func (rw *RollbackSnapshot) DeleteRollbackSnapshot(ctx context.Context, deleteS *RollbackSnapshot) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	if err = rw.DB(ctx).Where("db_type_t = ? AND schema_name_t = ? AND snapshot_id = ?",
+		common.StringUPPER(deleteS.DBTypeT),
+		common.StringUPPER(deleteS.SchemaNameT),
+		deleteS.SnapshotID).Delete(&RollbackSnapshot{}).Error; err != nil {
+		return fmt.Errorf("delete table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
@@ -22,26 +22,37 @@ import (
 	"github.com/wentaojin/transferdb/common"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"time"
 )
 
 // 全量同步元数据表
 type FullSyncMeta struct {
-	ID            uint   `gorm:"primary_key;autoIncrement;comment:'自增编号'" json:"id"`
-	DBTypeS       string `gorm:"type:varchar(15);index:idx_dbtype_st_map,unique;index:idx_schema_mode;comment:'源数据库类型'" json:"db_type_s"`
-	DBTypeT       string `gorm:"type:varchar(15);index:idx_dbtype_st_map,unique;index:idx_schema_mode;comment:'目标数据库类型'" json:"db_type_t"`
-	SchemaNameS   string `gorm:"type:varchar(15);not null;index:idx_dbtype_st_map,unique;index:idx_schema_mode;comment:'源端 schema'" json:"schema_name_s"`
-	TableNameS    string `gorm:"type:varchar(30);not null;index:idx_dbtype_st_map,unique;comment:'源端表名'" json:"table_name_s"`
-	SchemaNameT   string `gorm:"type:varchar(15);not null;comment:'目标端 schema'" json:"schema_name_t"`
-	TableNameT    string `gorm:"type:varchar(30);not null;comment:'目标端表名'" json:"table_name_t"`
-	GlobalScnS    uint64 `gorm:"comment:'源端全局 SCN'" json:"global_scn_s"`
-	ColumnDetailS string `gorm:"type:text;comment:'源端查询字段信息'" json:"column_detail_s"`
-	ChunkDetailS  string `gorm:"type:varchar(300);not null;index:idx_dbtype_st_map,unique;comment:'表 chunk 切分信息'" json:"chunk_detail_s"`
-	TaskMode      string `gorm:"not null;index:idx_dbtype_st_map,unique;index:idx_schema_mode;comment:'任务模式'" json:"task_mode"`
-	TaskStatus    string `gorm:"not null;comment:'任务 chunk 状态'" json:"task_status"`
-	CSVFile       string `gorm:"type:varchar(300);comment:'csv 文件名'" json:"csv_file"`
-	IsPartition   string `gorm:"comment:'是否是分区表'" json:"is_partition"` // 同步转换统一转换成非分区表，此处只做标志
-	InfoDetail    string `gorm:"not null;comment:'信息详情'" json:"info_detail"`
-	ErrorDetail   string `gorm:"not null;comment:'错误详情'" json:"error_detail"`
+	ID              uint   `gorm:"primary_key;autoIncrement;comment:'自增编号'" json:"id"`
+	DBTypeS         string `gorm:"type:varchar(15);index:idx_dbtype_st_map,unique;index:idx_schema_mode;comment:'源数据库类型'" json:"db_type_s"`
+	DBTypeT         string `gorm:"type:varchar(15);index:idx_dbtype_st_map,unique;index:idx_schema_mode;comment:'目标数据库类型'" json:"db_type_t"`
+	SchemaNameS     string `gorm:"type:varchar(15);not null;index:idx_dbtype_st_map,unique;index:idx_schema_mode;comment:'源端 schema'" json:"schema_name_s"`
+	TableNameS      string `gorm:"type:varchar(30);not null;index:idx_dbtype_st_map,unique;comment:'源端表名'" json:"table_name_s"`
+	SchemaNameT     string `gorm:"type:varchar(15);not null;comment:'目标端 schema'" json:"schema_name_t"`
+	TableNameT      string `gorm:"type:varchar(30);not null;comment:'目标端表名'" json:"table_name_t"`
+	GlobalScnS      uint64 `gorm:"comment:'源端全局 SCN'" json:"global_scn_s"`
+	ColumnDetailS   string `gorm:"type:text;comment:'源端查询字段信息'" json:"column_detail_s"`
+	ChunkDetailS    string `gorm:"type:varchar(300);not null;index:idx_dbtype_st_map,unique;comment:'表 chunk 切分信息'" json:"chunk_detail_s"`
+	ChunkPartitionS string `gorm:"type:varchar(300);not null;default:'';index:idx_dbtype_st_map,unique;comment:'表 chunk 分区扩展子句'" json:"chunk_partition_s"`
+	TaskMode        string `gorm:"not null;index:idx_dbtype_st_map,unique;index:idx_schema_mode;comment:'任务模式'" json:"task_mode"`
+	TaskStatus      string `gorm:"not null;comment:'任务 chunk 状态'" json:"task_status"`
+	CSVFile         string `gorm:"type:varchar(300);comment:'csv 文件名'" json:"csv_file"`
+	RowOffset       int64  `gorm:"default:0;comment:'chunk 内已写入行数'" json:"row_offset"`
+	SnapshotGroup   string `gorm:"type:varchar(64);comment:'一致性快照组，非空则基于 global_scn_s 闪回查询'" json:"snapshot_group"`
+	// chunk 内断点续读固定 SCN，row_offset 仅在同一 SCN 下行集合与 ROWID 顺序一致时有效
+	CheckpointScnS uint64 `gorm:"default:0;comment:'chunk 断点续读 SCN'" json:"checkpoint_scn_s"`
+	// chunk 实际抽取 SCN 以及时间，用于非一致性读表级 SCN 漂移检测
+	ExtractStartScnS uint64     `gorm:"default:0;comment:'chunk 抽取开始 SCN'" json:"extract_start_scn_s"`
+	ExtractEndScnS   uint64     `gorm:"default:0;comment:'chunk 抽取结束 SCN'" json:"extract_end_scn_s"`
+	ExtractStartTime *time.Time `gorm:"comment:'chunk 抽取开始时间'" json:"extract_start_time"`
+	ExtractEndTime   *time.Time `gorm:"comment:'chunk 抽取结束时间'" json:"extract_end_time"`
+	IsPartition      string     `gorm:"comment:'是否是分区表'" json:"is_partition"` // 同步转换统一转换成非分区表，此处只做标志
+	InfoDetail       string     `gorm:"not null;comment:'信息详情'" json:"info_detail"`
+	ErrorDetail      string     `gorm:"not null;comment:'错误详情'" json:"error_detail"`
 	*BaseModel
 }
 
@@ -94,13 +105,14 @@ func (rw *FullSyncMeta) DeleteFullSyncMetaBySchemaTableRowid(ctx context.Context
 	if err != nil {
 		return err
 	}
-	err = rw.DB(ctx).Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND table_name_s = ? AND task_mode = ?  AND UPPER(chunk_detail_s) = ?",
+	err = rw.DB(ctx).Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND table_name_s = ? AND task_mode = ?  AND UPPER(chunk_detail_s) = ? AND chunk_partition_s = ?",
 		common.StringUPPER(deleteS.DBTypeS),
 		common.StringUPPER(deleteS.DBTypeT),
 		common.StringUPPER(deleteS.SchemaNameS),
 		common.StringUPPER(deleteS.TableNameS),
 		deleteS.TaskMode,
-		common.StringUPPER(deleteS.ChunkDetailS)).Delete(&FullSyncMeta{}).Error
+		common.StringUPPER(deleteS.ChunkDetailS),
+		deleteS.ChunkPartitionS).Delete(&FullSyncMeta{}).Error
 	if err != nil {
 		return fmt.Errorf("delete table [%s] reocrd failed: %v", table, err)
 	}
@@ -110,6 +122,23 @@ func (rw *FullSyncMeta) DeleteFullSyncMetaBySchemaTableRowid(ctx context.Context
 	return nil
 }
 
+func (rw *FullSyncMeta) DeleteFullSyncMetaBySchemaTable(ctx context.Context, deleteS *FullSyncMeta) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	err = rw.DB(ctx).Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND table_name_s = ? AND task_mode = ?",
+		common.StringUPPER(deleteS.DBTypeS),
+		common.StringUPPER(deleteS.DBTypeT),
+		common.StringUPPER(deleteS.SchemaNameS),
+		common.StringUPPER(deleteS.TableNameS),
+		deleteS.TaskMode).Delete(&FullSyncMeta{}).Error
+	if err != nil {
+		return fmt.Errorf("delete table [%s] reocrd failed: %v", table, err)
+	}
+	return nil
+}
+
 func (rw *FullSyncMeta) BatchCreateFullSyncMeta(ctx context.Context, createS []FullSyncMeta, batchSize int) error {
 	table, err := rw.ParseSchemaTable()
 	if err != nil {
@@ -147,16 +176,70 @@ func (rw *FullSyncMeta) UpdateFullSyncMeta(ctx context.Context, deleteS *FullSyn
 		return err
 	}
 	if err = rw.DB(ctx).Model(FullSyncMeta{}).
-		Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND table_name_s = ? AND task_mode = ? AND chunk_detail_s = ?",
+		Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND table_name_s = ? AND task_mode = ? AND chunk_detail_s = ? AND chunk_partition_s = ?",
 			common.StringUPPER(deleteS.DBTypeS),
 			common.StringUPPER(deleteS.DBTypeT),
 			common.StringUPPER(deleteS.SchemaNameS),
 			common.StringUPPER(deleteS.TableNameS),
 			common.StringUPPER(deleteS.TaskMode),
-			deleteS.ChunkDetailS).
+			deleteS.ChunkDetailS,
+			deleteS.ChunkPartitionS).
 		Updates(updates).Error; err != nil {
 		return fmt.Errorf("update table [%s] record failed: %v", table, err)
 	}
+	observeChunkStatus(deleteS.TaskMode, deleteS.SchemaNameS, deleteS.TableNameS, updates)
+	return nil
+}
+
+// FullSyncMetaStatusCounts 表 chunk 状态统计
+type FullSyncMetaStatusCounts struct {
+	TableNameS string    `json:"table_name_s"`
+	TaskStatus string    `json:"task_status"`
+	Counts     int64     `json:"counts"`
+	StartAt    time.Time `json:"start_at"`
+	LastAt     time.Time `json:"last_at"`
+}
+
+// CountsFullSyncMetaGroupByTableStatus 按表以及 chunk 状态分组统计，用于任务进度展示
+func (rw *FullSyncMeta) CountsFullSyncMetaGroupByTableStatus(ctx context.Context, detailS *FullSyncMeta) ([]FullSyncMetaStatusCounts, error) {
+	var counts []FullSyncMetaStatusCounts
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return counts, err
+	}
+	if err = rw.DB(ctx).Model(&FullSyncMeta{}).
+		Select("table_name_s, task_status, COUNT(1) AS counts, MIN(created_at) AS start_at, MAX(updated_at) AS last_at").
+		Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND task_mode = ?",
+			common.StringUPPER(detailS.DBTypeS),
+			common.StringUPPER(detailS.DBTypeT),
+			common.StringUPPER(detailS.SchemaNameS),
+			common.StringUPPER(detailS.TaskMode)).
+		Group("table_name_s, task_status").
+		Scan(&counts).Error; err != nil {
+		return counts, fmt.Errorf("counts table [%s] group by task_status failed: %v", table, err)
+	}
+	return counts, nil
+}
+
+// UpdateFullSyncMetaRowOffset 更新 chunk 内已写入行数，仅向前推进
+func (rw *FullSyncMeta) UpdateFullSyncMetaRowOffset(ctx context.Context, updateS *FullSyncMeta, rowOffset int64) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	if err = rw.DB(ctx).Model(FullSyncMeta{}).
+		Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND table_name_s = ? AND task_mode = ? AND chunk_detail_s = ? AND chunk_partition_s = ? AND row_offset < ?",
+			common.StringUPPER(updateS.DBTypeS),
+			common.StringUPPER(updateS.DBTypeT),
+			common.StringUPPER(updateS.SchemaNameS),
+			common.StringUPPER(updateS.TableNameS),
+			common.StringUPPER(updateS.TaskMode),
+			updateS.ChunkDetailS,
+			updateS.ChunkPartitionS,
+			rowOffset).
+		Update("row_offset", rowOffset).Error; err != nil {
+		return fmt.Errorf("update table [%s] row_offset record failed: %v", table, err)
+	}
 	return nil
 }
 
@@ -199,6 +282,35 @@ func (rw *FullSyncMeta) CountsFullSyncMetaByTaskTable(ctx context.Context, dataE
 	return countsErr, nil
 }
 
+// FullSyncMetaExtractRange 表 chunk 实际抽取 SCN 以及时间范围
+type FullSyncMetaExtractRange struct {
+	MinScnS   uint64     `json:"min_scn_s"`
+	MaxScnS   uint64     `json:"max_scn_s"`
+	StartTime *time.Time `json:"start_time"`
+	EndTime   *time.Time `json:"end_time"`
+}
+
+// DetailFullSyncMetaExtractRange 统计表已记录抽取 SCN 的 chunk 最小开始 SCN、最大结束 SCN 以及时间范围
+func (rw *FullSyncMeta) DetailFullSyncMetaExtractRange(ctx context.Context, detailS *FullSyncMeta) (FullSyncMetaExtractRange, error) {
+	var extractRange FullSyncMetaExtractRange
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return extractRange, err
+	}
+	if err = rw.DB(ctx).Model(&FullSyncMeta{}).
+		Select("MIN(extract_start_scn_s) AS min_scn_s, MAX(extract_end_scn_s) AS max_scn_s, MIN(extract_start_time) AS start_time, MAX(extract_end_time) AS end_time").
+		Where(`db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND table_name_s = ? AND task_mode = ? AND extract_start_scn_s > 0`,
+			common.StringUPPER(detailS.DBTypeS),
+			common.StringUPPER(detailS.DBTypeT),
+			common.StringUPPER(detailS.SchemaNameS),
+			common.StringUPPER(detailS.TableNameS),
+			common.StringUPPER(detailS.TaskMode)).
+		Scan(&extractRange).Error; err != nil {
+		return extractRange, fmt.Errorf("get table [%s] extract scn range failed: %v", table, err)
+	}
+	return extractRange, nil
+}
+
 func (rw *FullSyncMeta) String() string {
 	jsonStr, _ := json.Marshal(rw)
 	return string(jsonStr)
@@ -0,0 +1,80 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package meta
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"gorm.io/gorm"
+)
+
+// 增量应用一致性位点映射表，记录源端已应用 SCN 与下游 binlog GTID/位点对应关系，用于下游从库切换
+type IncrSyncGTID struct {
+	ID          uint   `gorm:"primary_key;autoIncrement;comment:'自增编号'" json:"id"`
+	DBTypeS     string `gorm:"type:varchar(15);index:idx_dbtype_scn;comment:'源数据库类型'" json:"db_type_s"`
+	DBTypeT     string `gorm:"type:varchar(15);index:idx_dbtype_scn;comment:'目标数据库类型'" json:"db_type_t"`
+	SchemaNameS string `gorm:"type:varchar(30);not null;index:idx_dbtype_scn;comment:'源端 schema'" json:"schema_name_s"`
+	GlobalScnS  uint64 `gorm:"index:idx_dbtype_scn;comment:'源端已应用一致性 SCN，小于等于该 SCN 变更均已应用'" json:"global_scn_s"`
+	LogFileS    string `gorm:"type:varchar(300);comment:'源端 logminer 日志文件'" json:"log_file_s"`
+	SchemaNameT string `gorm:"type:varchar(30);not null;comment:'目标 schema'" json:"schema_name_t"`
+	BinlogFileT string `gorm:"type:varchar(300);comment:'目标端 binlog 文件'" json:"binlog_file_t"`
+	BinlogPosT  uint64 `gorm:"comment:'目标端 binlog 位点'" json:"binlog_pos_t"`
+	GTIDSetT    string `gorm:"type:longtext;comment:'目标端 gtid_executed'" json:"gtid_set_t"`
+	*BaseModel
+}
+
+func NewIncrSyncGTIDModel(m *Meta) *IncrSyncGTID {
+	return &IncrSyncGTID{BaseModel: &BaseModel{
+		Meta: m}}
+}
+
+func (rw *IncrSyncGTID) ParseSchemaTable() (string, error) {
+	stmt := &gorm.Statement{DB: rw.GormDB}
+	err := stmt.Parse(rw)
+	if err != nil {
+		return "", fmt.Errorf("parse struct [IncrSyncGTID] get table_name failed: %v", err)
+	}
+	return stmt.Schema.Table, nil
+}
+
+func (rw *IncrSyncGTID) CreateIncrSyncGTID(ctx context.Context, createS *IncrSyncGTID) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	if err = rw.DB(ctx).Create(createS).Error; err != nil {
+		return fmt.Errorf("create table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
+
+// GetIncrSyncGTIDBySCN 获取小于等于指定 SCN 的最近一致性位点，用于下游从库按已知 SCN 切换
+func (rw *IncrSyncGTID) GetIncrSyncGTIDBySCN(ctx context.Context, detailS *IncrSyncGTID) (IncrSyncGTID, error) {
+	var gtidMeta IncrSyncGTID
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return gtidMeta, err
+	}
+	if err = rw.DB(ctx).Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND global_scn_s <= ?",
+		common.StringUPPER(detailS.DBTypeS),
+		common.StringUPPER(detailS.DBTypeT),
+		common.StringUPPER(detailS.SchemaNameS),
+		detailS.GlobalScnS).Order("global_scn_s DESC").Limit(1).Find(&gtidMeta).Error; err != nil {
+		return gtidMeta, fmt.Errorf("get table [%s] record by scn failed: %v", table, err)
+	}
+	return gtidMeta, nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package meta
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 文件导入元数据表，按文件断点续传
+type LoadSyncMeta struct {
+	ID           uint   `gorm:"primary_key;autoIncrement;comment:'自增编号'" json:"id"`
+	DBTypeT      string `gorm:"type:varchar(15);index:idx_dbtype_t_file,unique;comment:'目标数据库类型'" json:"db_type_t"`
+	SchemaNameT  string `gorm:"type:varchar(64);not null;index:idx_dbtype_t_file,unique;comment:'目标端 schema'" json:"schema_name_t"`
+	TableNameT   string `gorm:"type:varchar(64);not null;index:idx_dbtype_t_file,unique;comment:'目标端表名'" json:"table_name_t"`
+	FileName     string `gorm:"type:varchar(300);not null;index:idx_dbtype_t_file,unique;comment:'导入文件名'" json:"file_name"`
+	FileSize     int64  `gorm:"comment:'导入文件字节数'" json:"file_size"`
+	TaskStatus   string `gorm:"not null;comment:'文件导入状态'" json:"task_status"`
+	RowsAffected int64  `gorm:"comment:'导入影响行数'" json:"rows_affected"`
+	ErrorDetail  string `gorm:"type:text;comment:'错误详情'" json:"error_detail"`
+	*BaseModel
+}
+
+func NewLoadSyncMetaModel(m *Meta) *LoadSyncMeta {
+	return &LoadSyncMeta{BaseModel: &BaseModel{
+		Meta: m}}
+}
+
+func (rw *LoadSyncMeta) ParseSchemaTable() (string, error) {
+	stmt := &gorm.Statement{DB: rw.GormDB}
+	err := stmt.Parse(rw)
+	if err != nil {
+		return "", fmt.Errorf("parse struct [LoadSyncMeta] get table_name failed: %v", err)
+	}
+	return stmt.Schema.Table, nil
+}
+
+// BatchCreateLoadSyncMeta 已存在文件记录忽略，保留断点状态
+func (rw *LoadSyncMeta) BatchCreateLoadSyncMeta(ctx context.Context, createS []LoadSyncMeta, batchSize int) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	if err = rw.DB(ctx).Clauses(clause.Insert{Modifier: "IGNORE"}).CreateInBatches(createS, batchSize).Error; err != nil {
+		return fmt.Errorf("batch create table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
+
+func (rw *LoadSyncMeta) DetailLoadSyncMeta(ctx context.Context, detailS *LoadSyncMeta) ([]LoadSyncMeta, error) {
+	var dsMetas []LoadSyncMeta
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return dsMetas, err
+	}
+	if err = rw.DB(ctx).Where(detailS).Find(&dsMetas).Error; err != nil {
+		return dsMetas, fmt.Errorf("detail table [%s] record failed: %v", table, err)
+	}
+	return dsMetas, nil
+}
+
+func (rw *LoadSyncMeta) UpdateLoadSyncMeta(ctx context.Context, detailS *LoadSyncMeta, updates map[string]interface{}) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	err = rw.DB(ctx).Model(&LoadSyncMeta{}).
+		Where("db_type_t = ? AND schema_name_t = ? AND table_name_t = ? AND file_name = ?",
+			common.StringUPPER(detailS.DBTypeT),
+			common.StringUPPER(detailS.SchemaNameT),
+			common.StringUPPER(detailS.TableNameT),
+			detailS.FileName).
+		Updates(updates).Error
+	if err != nil {
+		return fmt.Errorf("update table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
+
+func (rw *LoadSyncMeta) DeleteLoadSyncMeta(ctx context.Context, deleteS *LoadSyncMeta) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	err = rw.DB(ctx).Where("db_type_t = ? AND schema_name_t = ? AND table_name_t = ?",
+		common.StringUPPER(deleteS.DBTypeT),
+		common.StringUPPER(deleteS.SchemaNameT),
+		common.StringUPPER(deleteS.TableNameT)).Delete(&LoadSyncMeta{}).Error
+	if err != nil {
+		return fmt.Errorf("delete table [%s] reocrd failed: %v", table, err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package meta
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"gorm.io/gorm"
+)
+
+// 物化视图日志 / 触发器影子表增量同步元数据表
+type MVLogSyncMeta struct {
+	ID          uint   `gorm:"primary_key;autoIncrement;comment:'自增编号'" json:"id"`
+	DBTypeS     string `gorm:"type:varchar(15);index:idx_dbtype_st_map,unique;comment:'源数据库类型'" json:"db_type_s"`
+	DBTypeT     string `gorm:"type:varchar(15);index:idx_dbtype_st_map,unique;comment:'目标数据库类型'" json:"db_type_t"`
+	SchemaNameS string `gorm:"type:varchar(30);not null;index:idx_dbtype_st_map,unique;comment:'源端 schema'" json:"schema_name_s"`
+	TableNameS  string `gorm:"type:varchar(30);not null;index:idx_dbtype_st_map,unique;comment:'源端表名'" json:"table_name_s"`
+	SchemaNameT string `gorm:"type:varchar(30);not null;comment:'目标 schema'" json:"schema_name_t"`
+	TableNameT  string `gorm:"type:varchar(30);not null;comment:'目标表名'" json:"table_name_t"`
+	CaptureMode string `gorm:"type:varchar(15);comment:'捕获方式 MVLOG/TRIGGER'" json:"capture_mode"`
+	LogTableS   string `gorm:"type:varchar(128);not null;comment:'源端物化视图日志表或触发器影子表'" json:"log_table_s"`
+	PrimaryKeyS string `gorm:"type:varchar(1000);not null;comment:'源端主键字段，逗号分隔'" json:"primary_key_s"`
+	ReuseLogS   bool   `gorm:"comment:'是否复用已存在物化视图日志'" json:"reuse_log_s"`
+	SequenceS   uint64 `gorm:"comment:'已消费 SEQUENCE$$ 位点'" json:"sequence_s"`
+	ApplyRows   int64  `gorm:"comment:'累计应用主键数'" json:"apply_rows"`
+	*BaseModel
+}
+
+func NewMVLogSyncMetaModel(m *Meta) *MVLogSyncMeta {
+	return &MVLogSyncMeta{BaseModel: &BaseModel{
+		Meta: m}}
+}
+
+func (rw *MVLogSyncMeta) ParseSchemaTable() (string, error) {
+	stmt := &gorm.Statement{DB: rw.GormDB}
+	err := stmt.Parse(rw)
+	if err != nil {
+		return "", fmt.Errorf("parse struct [MVLogSyncMeta] get table_name failed: %v", err)
+	}
+	return stmt.Schema.Table, nil
+}
+
+func (rw *MVLogSyncMeta) CreateMVLogSyncMeta(ctx context.Context, createS *MVLogSyncMeta) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	if err = rw.DB(ctx).Create(createS).Error; err != nil {
+		return fmt.Errorf("create table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
+
+func (rw *MVLogSyncMeta) CountsMVLogSyncMetaBySchemaTable(ctx context.Context, detailS *MVLogSyncMeta) (int64, error) {
+	var count int64
+
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return count, err
+	}
+
+	if err = rw.DB(ctx).Model(&MVLogSyncMeta{}).
+		Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ? and table_name_s = ?",
+			common.StringUPPER(detailS.DBTypeS),
+			common.StringUPPER(detailS.DBTypeT),
+			common.StringUPPER(detailS.SchemaNameS),
+			common.StringUPPER(detailS.TableNameS),
+		).
+		Count(&count).Error; err != nil {
+		return count, fmt.Errorf("query table [%s] counts by column [schema and table] failed: %v", table, err)
+	}
+	return count, nil
+}
+
+func (rw *MVLogSyncMeta) DetailMVLogSyncMetaBySchema(ctx context.Context, detailS *MVLogSyncMeta) ([]MVLogSyncMeta, error) {
+	var mvlogMetas []MVLogSyncMeta
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return mvlogMetas, err
+	}
+	if err = rw.DB(ctx).Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ?",
+		common.StringUPPER(detailS.DBTypeS),
+		common.StringUPPER(detailS.DBTypeT),
+		common.StringUPPER(detailS.SchemaNameS)).Find(&mvlogMetas).Error; err != nil {
+		return mvlogMetas, fmt.Errorf("detail table [%s] record by schema failed: %v", table, err)
+	}
+	return mvlogMetas, nil
+}
+
+// UpdateMVLogSyncMetaSequence 推进已消费位点并累计应用主键数
+func (rw *MVLogSyncMeta) UpdateMVLogSyncMetaSequence(ctx context.Context, updateS *MVLogSyncMeta, sequence uint64, applyRows int64) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	if err = rw.DB(ctx).Model(&MVLogSyncMeta{}).
+		Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND table_name_s = ?",
+			common.StringUPPER(updateS.DBTypeS),
+			common.StringUPPER(updateS.DBTypeT),
+			common.StringUPPER(updateS.SchemaNameS),
+			common.StringUPPER(updateS.TableNameS)).
+		Updates(map[string]interface{}{
+			"sequence_s": sequence,
+			"apply_rows": gorm.Expr("apply_rows + ?", applyRows),
+		}).Error; err != nil {
+		return fmt.Errorf("update table [%s] column [sequence_s] failed: %v", table, err)
+	}
+	return nil
+}
+
+func (rw *MVLogSyncMeta) DeleteMVLogSyncMeta(ctx context.Context, deleteS *MVLogSyncMeta) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	if err = rw.DB(ctx).Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND table_name_s = ?",
+		common.StringUPPER(deleteS.DBTypeS),
+		common.StringUPPER(deleteS.DBTypeT),
+		common.StringUPPER(deleteS.SchemaNameS),
+		common.StringUPPER(deleteS.TableNameS)).Delete(&MVLogSyncMeta{}).Error; err != nil {
+		return fmt.Errorf("delete table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package meta
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OGG Kafka 消费位点元数据表，按 topic 分区记录下一条待消费 offset
+type OGGOffsetMeta struct {
+	ID          uint   `gorm:"primary_key;autoIncrement;comment:'自增编号'" json:"id"`
+	DBTypeS     string `gorm:"type:varchar(15);index:idx_dbtype_topic_partition,unique;comment:'源数据库类型'" json:"db_type_s"`
+	DBTypeT     string `gorm:"type:varchar(15);index:idx_dbtype_topic_partition,unique;comment:'目标数据库类型'" json:"db_type_t"`
+	SchemaNameS string `gorm:"type:varchar(64);not null;index:idx_dbtype_topic_partition,unique;comment:'源端 schema'" json:"schema_name_s"`
+	Topic       string `gorm:"type:varchar(249);not null;index:idx_dbtype_topic_partition,unique;comment:'kafka topic'" json:"topic"`
+	PartitionID int    `gorm:"not null;index:idx_dbtype_topic_partition,unique;comment:'kafka 分区'" json:"partition_id"`
+	Offset      int64  `gorm:"not null;comment:'下一条待消费 offset'" json:"offset"`
+	*BaseModel
+}
+
+func NewOGGOffsetMetaModel(m *Meta) *OGGOffsetMeta {
+	return &OGGOffsetMeta{BaseModel: &BaseModel{
+		Meta: m}}
+}
+
+func (rw *OGGOffsetMeta) ParseSchemaTable() (string, error) {
+	stmt := &gorm.Statement{DB: rw.GormDB}
+	err := stmt.Parse(rw)
+	if err != nil {
+		return "", fmt.Errorf("parse struct [OGGOffsetMeta] get table_name failed: %v", err)
+	}
+	return stmt.Schema.Table, nil
+}
+
+func (rw *OGGOffsetMeta) DetailOGGOffsetMeta(ctx context.Context, detailS *OGGOffsetMeta) ([]OGGOffsetMeta, error) {
+	var dsMetas []OGGOffsetMeta
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return dsMetas, err
+	}
+	if err = rw.DB(ctx).Where(detailS).Find(&dsMetas).Error; err != nil {
+		return dsMetas, fmt.Errorf("detail table [%s] record failed: %v", table, err)
+	}
+	return dsMetas, nil
+}
+
+// UpsertOGGOffsetMeta 分区位点不存在则写入，存在则更新 offset
+func (rw *OGGOffsetMeta) UpsertOGGOffsetMeta(ctx context.Context, upsertS *OGGOffsetMeta) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	err = rw.DB(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "db_type_s"},
+			{Name: "db_type_t"},
+			{Name: "schema_name_s"},
+			{Name: "topic"},
+			{Name: "partition_id"},
+		},
+		DoUpdates: clause.AssignmentColumns([]string{"offset", "updated_at"}),
+	}).Create(upsertS).Error
+	if err != nil {
+		return fmt.Errorf("upsert table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
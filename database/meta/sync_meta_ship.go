@@ -0,0 +1,106 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package meta
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 文件传输元数据表，按文件断点续传
+type ShipSyncMeta struct {
+	ID          uint   `gorm:"primary_key;autoIncrement;comment:'自增编号'" json:"id"`
+	SchemaNameS string `gorm:"type:varchar(64);not null;index:idx_schema_table_file,unique;comment:'源端 schema'" json:"schema_name_s"`
+	TableNameS  string `gorm:"type:varchar(64);not null;index:idx_schema_table_file,unique;comment:'源端表名'" json:"table_name_s"`
+	FileName    string `gorm:"type:varchar(300);not null;index:idx_schema_table_file,unique;comment:'传输文件名'" json:"file_name"`
+	FileSize    int64  `gorm:"comment:'传输文件字节数'" json:"file_size"`
+	Checksum    string `gorm:"type:varchar(64);comment:'文件 sha256'" json:"checksum"`
+	TaskStatus  string `gorm:"not null;comment:'文件传输状态'" json:"task_status"`
+	ErrorDetail string `gorm:"type:text;comment:'错误详情'" json:"error_detail"`
+	*BaseModel
+}
+
+func NewShipSyncMetaModel(m *Meta) *ShipSyncMeta {
+	return &ShipSyncMeta{BaseModel: &BaseModel{
+		Meta: m}}
+}
+
+func (rw *ShipSyncMeta) ParseSchemaTable() (string, error) {
+	stmt := &gorm.Statement{DB: rw.GormDB}
+	err := stmt.Parse(rw)
+	if err != nil {
+		return "", fmt.Errorf("parse struct [ShipSyncMeta] get table_name failed: %v", err)
+	}
+	return stmt.Schema.Table, nil
+}
+
+// BatchCreateShipSyncMeta 已存在文件记录保留断点状态，文件大小或 sha256 变化（重新导出）则重置为 WAITING 重新传输
+// ON DUPLICATE KEY UPDATE 按书写顺序赋值，状态判断须先于 file_size/checksum 更新
+func (rw *ShipSyncMeta) BatchCreateShipSyncMeta(ctx context.Context, createS []ShipSyncMeta, batchSize int) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	changed := "file_size <> VALUES(file_size) OR checksum IS NULL OR checksum <> VALUES(checksum)"
+	if err = rw.DB(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "schema_name_s"},
+			{Name: "table_name_s"},
+			{Name: "file_name"},
+		},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "task_status"}, Value: gorm.Expr(common.StringsBuilder("IF(", changed, ", VALUES(task_status), task_status)"))},
+			{Column: clause.Column{Name: "error_detail"}, Value: gorm.Expr(common.StringsBuilder("IF(", changed, ", '', error_detail)"))},
+			{Column: clause.Column{Name: "file_size"}, Value: gorm.Expr("VALUES(file_size)")},
+			{Column: clause.Column{Name: "checksum"}, Value: gorm.Expr("VALUES(checksum)")},
+		},
+	}).CreateInBatches(createS, batchSize).Error; err != nil {
+		return fmt.Errorf("batch create table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
+
+func (rw *ShipSyncMeta) DetailShipSyncMeta(ctx context.Context, detailS *ShipSyncMeta) ([]ShipSyncMeta, error) {
+	var dsMetas []ShipSyncMeta
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return dsMetas, err
+	}
+	if err = rw.DB(ctx).Where(detailS).Find(&dsMetas).Error; err != nil {
+		return dsMetas, fmt.Errorf("detail table [%s] record failed: %v", table, err)
+	}
+	return dsMetas, nil
+}
+
+func (rw *ShipSyncMeta) UpdateShipSyncMeta(ctx context.Context, detailS *ShipSyncMeta, updates map[string]interface{}) error {
+	table, err := rw.ParseSchemaTable()
+	if err != nil {
+		return err
+	}
+	err = rw.DB(ctx).Model(&ShipSyncMeta{}).
+		Where("schema_name_s = ? AND table_name_s = ? AND file_name = ?",
+			common.StringUPPER(detailS.SchemaNameS),
+			common.StringUPPER(detailS.TableNameS),
+			detailS.FileName).
+		Updates(updates).Error
+	if err != nil {
+		return fmt.Errorf("update table [%s] record failed: %v", table, err)
+	}
+	return nil
+}
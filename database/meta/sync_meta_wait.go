@@ -36,6 +36,11 @@ type WaitSyncMeta struct {
 	ChunkSuccessNums int64  `gorm:"comment:'全量任务 full_sync_meta 执行成功 chunk 数'" json:"chunk_success_nums"`
 	ChunkFailedNums  int64  `gorm:"comment:'全量任务 full_sync_meta 执行失败 chunk 数'" json:"chunk_failed_nums"`
 	IsPartition      string `gorm:"comment:'是否是分区表'" json:"is_partition"` // 同步转换统一转换成非分区表，此处只做标志
+	AvgRowBytes      int64  `gorm:"comment:'全量任务 chunk 校准平均行字节数'" json:"avg_row_bytes"`
+	ChunkRows        int64  `gorm:"comment:'全量任务 chunk 校准每 chunk 行数'" json:"chunk_rows"`
+	ExtractMinScnS   uint64 `gorm:"default:0;comment:'全量任务 chunk 实际抽取最小 SCN'" json:"extract_min_scn_s"`
+	ExtractMaxScnS   uint64 `gorm:"default:0;comment:'全量任务 chunk 实际抽取最大 SCN'" json:"extract_max_scn_s"`
+	Priority         int    `gorm:"default:0;comment:'全量任务表调度优先级，越大越先同步'" json:"priority"`
 	*BaseModel
 }
 
@@ -213,6 +213,8 @@ func (rw *Transaction) BatchCreateFullSyncMetaAndUpdateWaitSyncMeta(ctx context.
 			"ChunkSuccessNums": waitSyncMeta.ChunkSuccessNums,
 			"ChunkFailedNums":  waitSyncMeta.ChunkFailedNums,
 			"IsPartition":      waitSyncMeta.IsPartition,
+			"AvgRowBytes":      waitSyncMeta.AvgRowBytes,
+			"ChunkRows":        waitSyncMeta.ChunkRows,
 		}).Error
 	if err != nil {
 		return fmt.Errorf("update table [wait_sync_meta] reocrd by transaction failed: %v", err)
@@ -329,3 +331,92 @@ func (rw *Transaction) UpdateIncrSyncMetaSCNByArchivedLog(ctx context.Context,
 	}
 	return nil
 }
+
+// RequeueFailedFullSyncMetaAndWaitSyncMeta 失败 chunk 重新入队，full_sync_meta FAILED 重置 WAITING 并清理错误详情，wait_sync_meta FAILED 重置 RUNNING 以断点续传方式重跑
+func (rw *Transaction) RequeueFailedFullSyncMetaAndWaitSyncMeta(ctx context.Context, requeueS *WaitSyncMeta) ([]string, error) {
+	var tables []string
+	txn := rw.DB(ctx).Begin()
+	if err := txn.Model(&WaitSyncMeta{}).
+		Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND task_mode = ? AND task_status = ?",
+			common.StringUPPER(requeueS.DBTypeS),
+			common.StringUPPER(requeueS.DBTypeT),
+			common.StringUPPER(requeueS.SchemaNameS),
+			requeueS.TaskMode,
+			common.TaskStatusFailed).
+		Pluck("table_name_s", &tables).Error; err != nil {
+		txn.Rollback()
+		return tables, fmt.Errorf("query table [wait_sync_meta] failed record failed: %v", err)
+	}
+	if len(tables) == 0 {
+		txn.Rollback()
+		return tables, nil
+	}
+	if err := txn.Model(&FullSyncMeta{}).
+		Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND task_mode = ? AND task_status = ? AND table_name_s IN (?)",
+			common.StringUPPER(requeueS.DBTypeS),
+			common.StringUPPER(requeueS.DBTypeT),
+			common.StringUPPER(requeueS.SchemaNameS),
+			requeueS.TaskMode,
+			common.TaskStatusFailed,
+			tables).
+		Updates(map[string]interface{}{
+			"TaskStatus":  common.TaskStatusWaiting,
+			"InfoDetail":  "",
+			"ErrorDetail": "",
+		}).Error; err != nil {
+		txn.Rollback()
+		return tables, fmt.Errorf("update table [full_sync_meta] failed record by transaction failed: %v", err)
+	}
+	if err := txn.Model(&WaitSyncMeta{}).
+		Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND task_mode = ? AND task_status = ? AND table_name_s IN (?)",
+			common.StringUPPER(requeueS.DBTypeS),
+			common.StringUPPER(requeueS.DBTypeT),
+			common.StringUPPER(requeueS.SchemaNameS),
+			requeueS.TaskMode,
+			common.TaskStatusFailed,
+			tables).
+		Updates(map[string]interface{}{
+			"TaskStatus":      common.TaskStatusRunning,
+			"ChunkFailedNums": 0,
+		}).Error; err != nil {
+		txn.Rollback()
+		return tables, fmt.Errorf("update table [wait_sync_meta] failed record by transaction failed: %v", err)
+	}
+	txn.Commit()
+	return tables, nil
+}
+
+// ImportMetaSnapshot 导入元数据快照，先清理同任务已有记录，保留快照原始创建/更新时间
+func (rw *Transaction) ImportMetaSnapshot(ctx context.Context, deleteS *WaitSyncMeta, waitMetas []WaitSyncMeta, fullMetas []FullSyncMeta, errLogs []ErrorLogDetail, batchSize int) error {
+	txn := rw.DB(ctx).Session(&gorm.Session{SkipHooks: true}).Begin()
+	for _, model := range []interface{}{&WaitSyncMeta{}, &FullSyncMeta{}, &ErrorLogDetail{}} {
+		if err := txn.Where("db_type_s = ? AND db_type_t = ? AND schema_name_s = ? AND task_mode = ?",
+			common.StringUPPER(deleteS.DBTypeS),
+			common.StringUPPER(deleteS.DBTypeT),
+			common.StringUPPER(deleteS.SchemaNameS),
+			deleteS.TaskMode).Delete(model).Error; err != nil {
+			txn.Rollback()
+			return fmt.Errorf("delete meta snapshot record by transaction failed: %v", err)
+		}
+	}
+	if len(waitMetas) > 0 {
+		if err := txn.CreateInBatches(waitMetas, batchSize).Error; err != nil {
+			txn.Rollback()
+			return fmt.Errorf("create table [wait_sync_meta] record by transaction failed: %v", err)
+		}
+	}
+	if len(fullMetas) > 0 {
+		if err := txn.CreateInBatches(fullMetas, batchSize).Error; err != nil {
+			txn.Rollback()
+			return fmt.Errorf("create table [full_sync_meta] record by transaction failed: %v", err)
+		}
+	}
+	if len(errLogs) > 0 {
+		if err := txn.CreateInBatches(errLogs, batchSize).Error; err != nil {
+			txn.Rollback()
+			return fmt.Errorf("create table [error_log_detail] record by transaction failed: %v", err)
+		}
+	}
+	txn.Commit()
+	return nil
+}
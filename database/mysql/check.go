@@ -73,6 +73,53 @@ func (m *MySQL) GetMySQLTableColumn(schemaName, tableName string) ([]map[string]
 	return res, nil
 }
 
+// GetMySQLTableGeneratedColumn 获取表生成列以及表达式，EXTRA 为 VIRTUAL GENERATED / STORED GENERATED（MariaDB PERSISTENT GENERATED）
+// 先按 EXTRA 过滤，存在生成列才查询 GENERATION_EXPRESSION，兼容无该字段的 MySQL 5.6
+func (m *MySQL) GetMySQLTableGeneratedColumn(schemaName, tableName string) ([]map[string]string, error) {
+	_, res, err := Query(m.Ctx, m.MySQLDB, fmt.Sprintf(`SELECT COLUMN_NAME
+ FROM information_schema.COLUMNS
+ WHERE UPPER(TABLE_SCHEMA) = UPPER('%s')
+   AND UPPER(TABLE_NAME) = UPPER('%s')
+   AND EXTRA LIKE '%%GENERATED%%'`, schemaName, tableName))
+	if err != nil {
+		return res, err
+	}
+	if len(res) == 0 {
+		return res, nil
+	}
+
+	_, res, err = Query(m.Ctx, m.MySQLDB, fmt.Sprintf(`SELECT COLUMN_NAME,
+		UPPER(EXTRA) EXTRA,
+		IFNULL(GENERATION_EXPRESSION,'') GENERATION_EXPRESSION
+ FROM information_schema.COLUMNS
+ WHERE UPPER(TABLE_SCHEMA) = UPPER('%s')
+   AND UPPER(TABLE_NAME) = UPPER('%s')
+   AND EXTRA LIKE '%%GENERATED%%'
+ ORDER BY ORDINAL_POSITION`, schemaName, tableName))
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// GetMySQLTableAutoIncrement 获取表自增列以及当前 AUTO_INCREMENT 值
+// MySQL 8.0 information_schema.TABLES 统计信息存在缓存（information_schema_stats_expiry），值可能滞后
+func (m *MySQL) GetMySQLTableAutoIncrement(schemaName, tableName string) ([]map[string]string, error) {
+	_, res, err := Query(m.Ctx, m.MySQLDB, fmt.Sprintf(`SELECT C.COLUMN_NAME,
+		IFNULL(T.AUTO_INCREMENT,1) AUTO_INCREMENT
+ FROM information_schema.COLUMNS C
+ JOIN information_schema.TABLES T
+   ON C.TABLE_SCHEMA = T.TABLE_SCHEMA
+  AND C.TABLE_NAME = T.TABLE_NAME
+ WHERE UPPER(C.TABLE_SCHEMA) = UPPER('%s')
+   AND UPPER(C.TABLE_NAME) = UPPER('%s')
+   AND UPPER(C.EXTRA) LIKE '%%AUTO_INCREMENT%%'`, schemaName, tableName))
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
 func (m *MySQL) GetMySQLTableColumnComment(schemaName, tableName string) ([]map[string]string, error) {
 	var (
 		res []map[string]string
@@ -151,6 +198,35 @@ func (m *MySQL) GetMySQLPartitionTableINFO(schemaName, tableName string) ([]map[
 	return res, nil
 }
 
+func (m *MySQL) GetMySQLTablePartitionName(schemaName, tableName string) ([]string, error) {
+	_, res, err := Query(m.Ctx, m.MySQLDB, fmt.Sprintf(`SELECT DISTINCT PARTITION_NAME
+FROM INFORMATION_SCHEMA.PARTITIONS
+WHERE UPPER(TABLE_SCHEMA) = UPPER('%s')
+  AND UPPER(TABLE_NAME) = UPPER('%s')
+  AND PARTITION_NAME IS NOT NULL`, schemaName, tableName))
+	if err != nil {
+		return nil, err
+	}
+	var parts []string
+	for _, r := range res {
+		parts = append(parts, r["PARTITION_NAME"])
+	}
+	return parts, nil
+}
+
+func (m *MySQL) GetMySQLTableTrigger(schemaName, tableName string) ([]map[string]string, error) {
+	_, res, err := Query(m.Ctx, m.MySQLDB, fmt.Sprintf(`SELECT TRIGGER_NAME,
+       ACTION_TIMING AS TRIGGER_TYPE,
+       EVENT_MANIPULATION AS TRIGGERING_EVENT
+FROM INFORMATION_SCHEMA.TRIGGERS
+WHERE UPPER(EVENT_OBJECT_SCHEMA) = UPPER('%s')
+  AND UPPER(EVENT_OBJECT_TABLE) = UPPER('%s')`, schemaName, tableName))
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
 func (m *MySQL) GetMySQLTable(schemaName string) ([]string, error) {
 	_, res, err := Query(m.Ctx, m.MySQLDB, fmt.Sprintf(`SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES where UPPER(TABLE_SCHEMA) = '%s'`, strings.ToUpper(schemaName)))
 	if err != nil {
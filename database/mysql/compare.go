@@ -22,7 +22,6 @@ import (
 	"github.com/scylladb/go-set/strset"
 	"github.com/thinkeridea/go-extend/exstrings"
 	"github.com/wentaojin/transferdb/common"
-	"hash/crc32"
 	"strconv"
 	"strings"
 	"sync/atomic"
@@ -57,7 +56,9 @@ func (m *MySQL) GetMySQLTableActualRows(mysqlQuery string) (int64, error) {
 	return rowsCount, nil
 }
 
-func (m *MySQL) GetMySQLDataRowStrings(querySQL string) ([]string, *strset.Set, uint32, error) {
+// GetMySQLDataRowStrings 查询 chunk 数据行，返回字段、行字符串集合以及按 checksumAlgo 累加的行校验和
+// identityCols 大于 0 时查询前 identityCols 列为行标识，不参与行字符串以及校验和，按行字符串返回行标识值
+func (m *MySQL) GetMySQLDataRowStrings(querySQL, checksumAlgo string, identityCols int) ([]string, *strset.Set, map[string][]string, uint32, error) {
 	var (
 		cols     []string
 		rowsTMP  []string
@@ -68,10 +69,11 @@ func (m *MySQL) GetMySQLDataRowStrings(querySQL string) ([]string, *strset.Set,
 	var crc32Value uint32 = 0
 
 	stringSet := set.NewStringSet()
+	identities := make(map[string][]string)
 
 	rows, err = m.MySQLDB.Query(querySQL)
 	if err != nil {
-		return cols, stringSet, crc32Value, fmt.Errorf("general sql [%v] query failed: [%v]", querySQL, err.Error())
+		return cols, stringSet, identities, crc32Value, fmt.Errorf("general sql [%v] query failed: [%v]", querySQL, err.Error())
 	}
 
 	defer rows.Close()
@@ -79,14 +81,14 @@ func (m *MySQL) GetMySQLDataRowStrings(querySQL string) ([]string, *strset.Set,
 	//不确定字段通用查询，自动获取字段名称
 	cols, err = rows.Columns()
 	if err != nil {
-		return cols, stringSet, crc32Value, fmt.Errorf("general sql [%v] query rows.Columns failed: [%v]", querySQL, err.Error())
+		return cols, stringSet, identities, crc32Value, fmt.Errorf("general sql [%v] query rows.Columns failed: [%v]", querySQL, err.Error())
 	}
 
 	// 用于判断字段值是数字还是字符
 	var columnTypes []string
 	colTypes, err := rows.ColumnTypes()
 	if err != nil {
-		return cols, stringSet, crc32Value, err
+		return cols, stringSet, identities, crc32Value, err
 	}
 
 	for _, ct := range colTypes {
@@ -97,7 +99,11 @@ func (m *MySQL) GetMySQLDataRowStrings(querySQL string) ([]string, *strset.Set,
 	//不确定字段通用查询，自动获取字段名称
 	cols, err = rows.Columns()
 	if err != nil {
-		return cols, stringSet, crc32Value, fmt.Errorf("general sql [%v] query rows.Columns failed: [%v]", querySQL, err.Error())
+		return cols, stringSet, identities, crc32Value, fmt.Errorf("general sql [%v] query rows.Columns failed: [%v]", querySQL, err.Error())
+	}
+
+	if identityCols > len(cols) {
+		return cols, stringSet, identities, crc32Value, fmt.Errorf("general sql [%v] identity columns [%d] over query columns [%d]", querySQL, identityCols, len(cols))
 	}
 
 	rawResult := make([][]byte, len(cols))
@@ -109,7 +115,7 @@ func (m *MySQL) GetMySQLDataRowStrings(querySQL string) ([]string, *strset.Set,
 	for rows.Next() {
 		err = rows.Scan(scans...)
 		if err != nil {
-			return cols, stringSet, crc32Value, fmt.Errorf("general sql [%v] query rows.Scan failed: [%v]", querySQL, err.Error())
+			return cols, stringSet, identities, crc32Value, fmt.Errorf("general sql [%v] query rows.Scan failed: [%v]", querySQL, err.Error())
 		}
 
 		for i, raw := range rawResult {
@@ -123,67 +129,67 @@ func (m *MySQL) GetMySQLDataRowStrings(querySQL string) ([]string, *strset.Set,
 				case "int8":
 					r, err := common.StrconvIntBitSize(string(raw), 8)
 					if err != nil {
-						return cols, stringSet, crc32Value, err
+						return cols, stringSet, identities, crc32Value, err
 					}
 					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
 				case "int16":
 					r, err := common.StrconvIntBitSize(string(raw), 16)
 					if err != nil {
-						return cols, stringSet, crc32Value, err
+						return cols, stringSet, identities, crc32Value, err
 					}
 					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
 				case "int32", "sql.NullInt32":
 					r, err := common.StrconvIntBitSize(string(raw), 32)
 					if err != nil {
-						return cols, stringSet, crc32Value, err
+						return cols, stringSet, identities, crc32Value, err
 					}
 					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
 				case "int64", "sql.NullInt64":
 					r, err := common.StrconvIntBitSize(string(raw), 64)
 					if err != nil {
-						return cols, stringSet, crc32Value, err
+						return cols, stringSet, identities, crc32Value, err
 					}
 					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
 				case "uint8":
 					r, err := common.StrconvUintBitSize(string(raw), 8)
 					if err != nil {
-						return cols, stringSet, crc32Value, err
+						return cols, stringSet, identities, crc32Value, err
 					}
 					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
 				case "uint16":
 					r, err := common.StrconvUintBitSize(string(raw), 16)
 					if err != nil {
-						return cols, stringSet, crc32Value, err
+						return cols, stringSet, identities, crc32Value, err
 					}
 					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
 				case "uint32":
 					r, err := common.StrconvUintBitSize(string(raw), 32)
 					if err != nil {
-						return cols, stringSet, crc32Value, err
+						return cols, stringSet, identities, crc32Value, err
 					}
 					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
 				case "uint64":
 					r, err := common.StrconvUintBitSize(string(raw), 64)
 					if err != nil {
-						return cols, stringSet, crc32Value, err
+						return cols, stringSet, identities, crc32Value, err
 					}
 					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
 				case "float32":
 					r, err := common.StrconvFloatBitSize(string(raw), 32)
 					if err != nil {
-						return cols, stringSet, crc32Value, err
+						return cols, stringSet, identities, crc32Value, err
 					}
 					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
 				case "float64", "sql.NullFloat64":
 					r, err := common.StrconvFloatBitSize(string(raw), 64)
 					if err != nil {
-						return cols, stringSet, crc32Value, err
+						return cols, stringSet, identities, crc32Value, err
 					}
 					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
 				case "rune":
 					r, err := common.StrconvRune(string(raw))
 					if err != nil {
-						return cols, stringSet, crc32Value, err
+						return cols, stringSet, identities, crc32Value, err
 					}
 					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
 				default:
@@ -193,19 +199,68 @@ func (m *MySQL) GetMySQLDataRowStrings(querySQL string) ([]string, *strset.Set,
 			}
 		}
 
-		rowS := exstrings.Join(rowsTMP, ",")
+		rowS := exstrings.Join(rowsTMP[identityCols:], ",")
 
-		// 计算 CRC32
-		crc32SUM = atomic.AddUint32(&crc32Value, crc32.ChecksumIEEE([]byte(rowS)))
+		// 计算行校验和
+		crc32SUM = atomic.AddUint32(&crc32Value, common.RowChecksum(checksumAlgo, []byte(rowS)))
 		stringSet.Add(rowS)
+		if identityCols > 0 {
+			identities[rowS] = append([]string{}, rowsTMP[:identityCols]...)
+		}
 
 		// 数组清空
 		rowsTMP = rowsTMP[0:0]
 	}
 
 	if err = rows.Err(); err != nil {
-		return cols, stringSet, crc32Value, fmt.Errorf("general sql [%v] query rows.Next failed: [%v]", querySQL, err.Error())
+		return cols, stringSet, identities, crc32Value, fmt.Errorf("general sql [%v] query rows.Next failed: [%v]", querySQL, err.Error())
+	}
+
+	return cols[identityCols:], stringSet, identities, crc32SUM, err
+}
+
+// GetMySQLTableColumnSortValue 按下游字段 collation 对指定值排序输出，用于与上游语言排序结果比对
+func (m *MySQL) GetMySQLTableColumnSortValue(schemaName, tableName, columnName string, values []string) ([]string, error) {
+	var (
+		inValues   []string
+		sortValues []string
+	)
+	if len(values) == 0 {
+		return sortValues, nil
+	}
+	for _, v := range values {
+		v = strings.ReplaceAll(v, `\`, `\\`)
+		inValues = append(inValues, common.StringsBuilder("'", strings.ReplaceAll(v, "'", "''"), "'"))
+	}
+	querySQL := fmt.Sprintf("SELECT DISTINCT `%s` AS V FROM `%s`.`%s` WHERE `%s` IN (%s) ORDER BY `%s`",
+		columnName, schemaName, tableName, columnName, strings.Join(inValues, ","), columnName)
+
+	_, res, err := Query(m.Ctx, m.MySQLDB, querySQL)
+	if err != nil {
+		return sortValues, err
+	}
+	for _, r := range res {
+		sortValues = append(sortValues, r["V"])
 	}
+	return sortValues, nil
+}
 
-	return cols, stringSet, crc32SUM, err
+// ApplyMySQLFixSQL 单事务执行 chunk 行级修复 SQL，失败整体回滚
+func (m *MySQL) ApplyMySQLFixSQL(fixSQL []string) error {
+	txn, err := m.MySQLDB.BeginTx(m.Ctx, nil)
+	if err != nil {
+		return fmt.Errorf("fix sql begin transaction failed: %v", err)
+	}
+	for _, s := range fixSQL {
+		if _, err = txn.ExecContext(m.Ctx, s); err != nil {
+			if errR := txn.Rollback(); errR != nil {
+				return fmt.Errorf("fix sql [%v] exec failed: %v, rollback failed: %v", s, err, errR)
+			}
+			return fmt.Errorf("fix sql [%v] exec failed: %v", s, err)
+		}
+	}
+	if err = txn.Commit(); err != nil {
+		return fmt.Errorf("fix sql commit transaction failed: %v", err)
+	}
+	return nil
 }
@@ -0,0 +1,207 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+)
+
+// mysqlFlavorAdapt 目标端形态识别以及适配结果
+type mysqlFlavorAdapt struct {
+	Flavor        string
+	DisableBinlog bool
+	CleanMode     string
+	MaxOpenConns  int
+	MaxIdleConns  int
+}
+
+// adaptMySQLFlavor 建连前识别目标端形态（社区版/RDS/Aurora），托管实例无 SUPER 权限、限制连接数配额，据此适配：
+// 1、disable-binlog 需 SUPER/SYSTEM_VARIABLES_ADMIN 权限，无权限告警并忽略，数据写入会话照常写 binlog
+// 2、target-clean-mode = truncate 调整为 auto，TRUNCATE 权限受限时回退分批 DELETE
+// 3、连接池上限不超过 max_user_connections/max_connections 配额的 80%
+func adaptMySQLFlavor(mysqlCfg config.MySQLConfig, addr string) (mysqlFlavorAdapt, error) {
+	adapt := mysqlFlavorAdapt{
+		Flavor:        mysqlCfg.Flavor,
+		DisableBinlog: mysqlCfg.DisableBinlog,
+		CleanMode:     common.StringUPPER(mysqlCfg.TargetCleanMode),
+		MaxOpenConns:  common.MySQLMaxConn,
+		MaxIdleConns:  common.MySQLMaxIdleConn,
+	}
+	// TiDB 不存在托管 MySQL 限制
+	if strings.EqualFold(mysqlCfg.DBType, common.DatabaseTypeTiDB) {
+		adapt.Flavor = common.MySQLFlavorCommunity
+		return adapt, nil
+	}
+
+	switch adapt.Flavor {
+	case "", common.MySQLFlavorAuto, common.MySQLFlavorCommunity, common.MySQLFlavorRDS, common.MySQLFlavorAurora:
+	default:
+		return adapt, fmt.Errorf("mysql config flavor [%s] isn't support, only support auto/community/rds/aurora", mysqlCfg.Flavor)
+	}
+
+	// 探测连接与数据连接 DSN 参数一致（tls 等），探测失败不阻断建连，按社区版处理
+	db, err := sql.Open("mysql", genMySQLDSN(mysqlCfg, addr, mysqlCfg.Username, mysqlCfg.Password, ""))
+	if err != nil {
+		return fallbackMySQLFlavor(adapt, addr, err), nil
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	if adapt.Flavor == "" || adapt.Flavor == common.MySQLFlavorAuto {
+		adapt.Flavor, err = detectMySQLFlavor(db)
+		if err != nil {
+			return fallbackMySQLFlavor(adapt, addr, err), nil
+		}
+	}
+
+	quota, err := getMySQLConnQuota(db)
+	if err != nil {
+		zap.L().Warn("mysql connection quota probe failed, keep default connection pool",
+			zap.String("addr", addr),
+			zap.Error(err))
+		quota = 0
+	}
+	if adapt.Flavor == common.MySQLFlavorCommunity {
+		if quota > 0 && quota < adapt.MaxOpenConns {
+			adapt.MaxOpenConns = quota
+			adapt.MaxIdleConns = quota
+		}
+		return adapt, nil
+	}
+
+	// 托管实例
+	if adapt.DisableBinlog {
+		super, err := hasMySQLSuperPrivilege(db)
+		if err != nil {
+			zap.L().Warn("mysql managed instance user privilege probe failed",
+				zap.String("flavor", adapt.Flavor),
+				zap.Error(err))
+		}
+		if !super {
+			zap.L().Warn("mysql managed instance user hasn't SUPER/SYSTEM_VARIABLES_ADMIN privilege, disable-binlog isn't support and ignore, data still write binlog",
+				zap.String("flavor", adapt.Flavor),
+				zap.String("user", mysqlCfg.Username))
+			adapt.DisableBinlog = false
+		}
+	}
+	if adapt.CleanMode == common.MigrateTargetCleanModeTruncate {
+		zap.L().Warn("mysql managed instance target-clean-mode truncate adjust to auto, fallback delete by batch when truncate privilege denied",
+			zap.String("flavor", adapt.Flavor))
+		adapt.CleanMode = common.MigrateTargetCleanModeAuto
+	}
+	if quota > 0 {
+		maxConns := int(float64(quota) * common.MySQLManagedConnRatio)
+		if maxConns < 1 {
+			maxConns = 1
+		}
+		if maxConns < adapt.MaxOpenConns {
+			adapt.MaxOpenConns = maxConns
+		}
+		if adapt.MaxIdleConns > adapt.MaxOpenConns {
+			adapt.MaxIdleConns = adapt.MaxOpenConns
+		}
+	}
+	zap.L().Info("mysql managed instance adapt",
+		zap.String("flavor", adapt.Flavor),
+		zap.Int("connection quota", quota),
+		zap.Int("max open conns", adapt.MaxOpenConns),
+		zap.Bool("disable binlog", adapt.DisableBinlog),
+		zap.String("clean mode", adapt.CleanMode))
+	return adapt, nil
+}
+
+// fallbackMySQLFlavor 形态探测失败告警，未显式配置 flavor 时按社区版处理，连接池保持默认
+func fallbackMySQLFlavor(adapt mysqlFlavorAdapt, addr string, err error) mysqlFlavorAdapt {
+	if adapt.Flavor == "" || adapt.Flavor == common.MySQLFlavorAuto {
+		adapt.Flavor = common.MySQLFlavorCommunity
+	}
+	zap.L().Warn("mysql flavor probe failed, skip managed instance adapt",
+		zap.String("addr", addr),
+		zap.String("flavor", adapt.Flavor),
+		zap.Error(err))
+	return adapt
+}
+
+// detectMySQLFlavor Aurora 存在 aurora_version 变量，RDS MySQL 安装目录为 /rdsdbbin/
+func detectMySQLFlavor(db *sql.DB) (string, error) {
+	var name, value string
+	err := db.QueryRow(`SHOW VARIABLES LIKE 'aurora_version'`).Scan(&name, &value)
+	switch {
+	case err == nil && value != "":
+		return common.MySQLFlavorAurora, nil
+	case err != nil && err != sql.ErrNoRows:
+		return "", fmt.Errorf("error on detect mysql flavor aurora_version: %v", err)
+	}
+
+	err = db.QueryRow(`SHOW VARIABLES LIKE 'basedir'`).Scan(&name, &value)
+	if err != nil && err != sql.ErrNoRows {
+		return "", fmt.Errorf("error on detect mysql flavor basedir: %v", err)
+	}
+	if strings.Contains(strings.ToLower(value), "rdsdbbin") {
+		return common.MySQLFlavorRDS, nil
+	}
+	return common.MySQLFlavorCommunity, nil
+}
+
+// hasMySQLSuperPrivilege 当前用户是否具备 SUPER 或 SYSTEM_VARIABLES_ADMIN（MySQL 8.0 动态权限）
+func hasMySQLSuperPrivilege(db *sql.DB) (bool, error) {
+	rows, err := db.Query(`SHOW GRANTS`)
+	if err != nil {
+		return false, fmt.Errorf("error on show mysql current user grants: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var grant string
+		if err = rows.Scan(&grant); err != nil {
+			return false, fmt.Errorf("error on scan mysql current user grants: %v", err)
+		}
+		grant = common.StringUPPER(grant)
+		if !strings.Contains(grant, " ON *.* ") {
+			continue
+		}
+		if strings.Contains(grant, "ALL PRIVILEGES") || strings.Contains(grant, "SUPER") || strings.Contains(grant, "SYSTEM_VARIABLES_ADMIN") {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// getMySQLConnQuota 当前用户可用连接配额，max_user_connections 未限制时取 max_connections
+func getMySQLConnQuota(db *sql.DB) (int, error) {
+	var maxConns, maxUserConns string
+	if err := db.QueryRow(`SELECT @@GLOBAL.MAX_CONNECTIONS, @@SESSION.MAX_USER_CONNECTIONS`).Scan(&maxConns, &maxUserConns); err != nil {
+		return 0, fmt.Errorf("error on query mysql max connections: %v", err)
+	}
+	userQuota, err := strconv.Atoi(maxUserConns)
+	if err != nil {
+		return 0, fmt.Errorf("error on parse mysql max_user_connections [%s]: %v", maxUserConns, err)
+	}
+	if userQuota > 0 {
+		return userQuota, nil
+	}
+	quota, err := strconv.Atoi(maxConns)
+	if err != nil {
+		return 0, fmt.Errorf("error on parse mysql max_connections [%s]: %v", maxConns, err)
+	}
+	return quota, nil
+}
@@ -16,13 +16,36 @@ limitations under the License.
 package mysql
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	gomysql "github.com/go-sql-driver/mysql"
+	"github.com/wentaojin/transferdb/common"
 	"go.uber.org/zap"
+	"io"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// TruncateMySQLTable 按 target-clean-mode 清理目标表数据
 func (m *MySQL) TruncateMySQLTable(targetSchema string, targetTable string) error {
-	_, err := m.MySQLDB.ExecContext(m.Ctx, fmt.Sprintf("TRUNCATE TABLE %s.%s", targetSchema, targetTable))
+	if m.CleanMode == common.MigrateTargetCleanModeDelete {
+		_, err := m.DeleteMySQLTableByBatch(targetSchema, targetTable, "1 = 1", m.CleanBatchSize)
+		return err
+	}
+	_, err := m.DDLDB.ExecContext(m.Ctx, fmt.Sprintf("TRUNCATE TABLE %s.%s", targetSchema, targetTable))
 	if err != nil {
+		if m.CleanMode == common.MigrateTargetCleanModeAuto && IsMySQLPrivilegeError(err) {
+			zap.L().Warn("truncate table privilege denied, fallback delete by batch",
+				zap.String("schema", targetSchema),
+				zap.String("table", targetTable),
+				zap.Int("batch size", m.CleanBatchSize),
+				zap.Error(err))
+			_, err = m.DeleteMySQLTableByBatch(targetSchema, targetTable, "1 = 1", m.CleanBatchSize)
+			return err
+		}
 		return fmt.Errorf("truncate mysql schema [%v] table [%v] reocrd failed: %v", targetSchema, targetTable, err.Error())
 	}
 	zap.L().Info("truncate table",
@@ -32,6 +55,107 @@ func (m *MySQL) TruncateMySQLTable(targetSchema string, targetTable string) erro
 	return nil
 }
 
+// DeleteMySQLTableByBatch 按条件分批删除表数据，避免大事务
+func (m *MySQL) DeleteMySQLTableByBatch(targetSchema, targetTable, whereS string, batchSize int) (int64, error) {
+	var totalRows int64
+	deleteSQL := fmt.Sprintf("DELETE FROM `%s`.`%s` WHERE %s LIMIT %d", targetSchema, targetTable, whereS, batchSize)
+	startTime := time.Now()
+	lastLogTime := startTime
+	for {
+		res, err := m.MySQLDB.ExecContext(m.Ctx, deleteSQL)
+		if err != nil {
+			return totalRows, fmt.Errorf("delete mysql schema [%v] table [%v] sql [%v] reocrd failed: %v", targetSchema, targetTable, deleteSQL, err)
+		}
+		affectRows, err := res.RowsAffected()
+		if err != nil {
+			return totalRows, fmt.Errorf("delete mysql schema [%v] table [%v] get rows affected failed: %v", targetSchema, targetTable, err)
+		}
+		totalRows = totalRows + affectRows
+		if affectRows < int64(batchSize) {
+			break
+		}
+		// 大表分批删除耗时较长，定期输出进度
+		if time.Since(lastLogTime) >= common.MigrateDeleteProgressInterval {
+			lastLogTime = time.Now()
+			zap.L().Info("delete table by batch progress",
+				zap.String("schema", targetSchema),
+				zap.String("table", targetTable),
+				zap.String("where", whereS),
+				zap.Int64("deleted rows", totalRows),
+				zap.String("cost", time.Since(startTime).String()))
+		}
+	}
+	zap.L().Info("delete table by batch",
+		zap.String("schema", targetSchema),
+		zap.String("table", targetTable),
+		zap.String("where", whereS),
+		zap.Int64("rows", totalRows),
+		zap.String("status", "success"))
+	return totalRows, nil
+}
+
+// GetTiDBStoreAvailableBytes TiKV 存储节点可用空间汇总以及 PD 副本数
+func (m *MySQL) GetTiDBStoreAvailableBytes() (float64, int, error) {
+	_, res, err := Query(m.Ctx, m.MySQLDB, `SELECT AVAILABLE FROM INFORMATION_SCHEMA.TIKV_STORE_STATUS WHERE STORE_STATE_NAME = 'Up' AND LABEL NOT LIKE '%tiflash%'`)
+	if err != nil {
+		return 0, 0, err
+	}
+	var availableBytes float64
+	for _, r := range res {
+		bytes, err := common.ParseStoreSize(r["AVAILABLE"])
+		if err != nil {
+			return 0, 0, err
+		}
+		availableBytes += bytes
+	}
+
+	replicas := common.TiDBDefaultMaxReplicas
+	_, cfgRes, err := Query(m.Ctx, m.MySQLDB, `SHOW CONFIG WHERE type = 'pd' AND name = 'replication.max-replicas'`)
+	if err != nil {
+		zap.L().Warn("get tidb pd max replicas failed, using default replicas",
+			zap.Int("replicas", replicas),
+			zap.Error(err))
+		return availableBytes, replicas, nil
+	}
+	if len(cfgRes) > 0 {
+		if val, errA := strconv.Atoi(cfgRes[0]["Value"]); errA == nil && val > 0 {
+			replicas = val
+		}
+	}
+	return availableBytes, replicas, nil
+}
+
+// GetTiDBTablePKType 表主键类型 CLUSTERED / NONCLUSTERED
+func (m *MySQL) GetTiDBTablePKType(targetSchema, targetTable string) (string, error) {
+	_, res, err := Query(m.Ctx, m.MySQLDB, fmt.Sprintf(`SELECT TIDB_PK_TYPE FROM INFORMATION_SCHEMA.TABLES WHERE UPPER(TABLE_SCHEMA) = '%s' AND UPPER(TABLE_NAME) = '%s'`,
+		common.StringUPPER(targetSchema), common.StringUPPER(targetTable)))
+	if err != nil {
+		return "", err
+	}
+	if len(res) == 0 {
+		return "", fmt.Errorf("tidb schema [%s] table [%s] isn't exist", targetSchema, targetTable)
+	}
+	return common.StringUPPER(res[0]["TIDB_PK_TYPE"]), nil
+}
+
+// SplitTiDBTableRegion 按区间预切分表数据 region，indexName 非空切分索引 region，返回切分 region 数
+func (m *MySQL) SplitTiDBTableRegion(targetSchema, targetTable, indexName, lower, upper string, regions int) (string, error) {
+	var splitSQL string
+	if indexName == "" {
+		splitSQL = fmt.Sprintf("SPLIT TABLE `%s`.`%s` BETWEEN (%s) AND (%s) REGIONS %d", targetSchema, targetTable, lower, upper, regions)
+	} else {
+		splitSQL = fmt.Sprintf("SPLIT TABLE `%s`.`%s` INDEX `%s` BETWEEN (%s) AND (%s) REGIONS %d", targetSchema, targetTable, indexName, lower, upper, regions)
+	}
+	_, res, err := Query(m.Ctx, m.MySQLDB, splitSQL)
+	if err != nil {
+		return "", fmt.Errorf("tidb split sql [%s] failed: %v", splitSQL, err)
+	}
+	if len(res) == 0 {
+		return "0", nil
+	}
+	return res[0]["TOTAL_SPLIT_REGION"], nil
+}
+
 func (m *MySQL) WriteMySQLTable(sql string) error {
 	_, err := m.MySQLDB.ExecContext(m.Ctx, sql)
 	if err != nil {
@@ -39,3 +163,297 @@ func (m *MySQL) WriteMySQLTable(sql string) error {
 	}
 	return nil
 }
+
+// WriteMySQLDDL 表结构变更使用 DDL 用户连接执行
+func (m *MySQL) WriteMySQLDDL(sql string) error {
+	_, err := m.DDLDB.ExecContext(m.Ctx, sql)
+	if err != nil {
+		return fmt.Errorf("target schema ddl sql [%v] exec failed: %v", sql, err)
+	}
+	return nil
+}
+
+// LoadMySQLTableByLocalFile LOAD DATA LOCAL INFILE 导入本地文件，返回影响行数
+func (m *MySQL) LoadMySQLTableByLocalFile(fileName, loadSQL string) (int64, error) {
+	gomysql.RegisterLocalFile(fileName)
+	defer gomysql.DeregisterLocalFile(fileName)
+
+	res, err := m.MySQLDB.ExecContext(m.Ctx, loadSQL)
+	if err != nil {
+		return 0, fmt.Errorf("load file [%v] sql [%v] failed: %v", fileName, loadSQL, err)
+	}
+	affectRows, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("load file [%v] get rows affected failed: %v", fileName, err)
+	}
+	return affectRows, nil
+}
+
+// LoadMySQLTableByReader LOAD DATA LOCAL INFILE 'Reader::name' 流式导入，用于压缩文件边解压边导入
+func (m *MySQL) LoadMySQLTableByReader(name string, reader io.Reader, loadSQL string) (int64, error) {
+	gomysql.RegisterReaderHandler(name, func() io.Reader { return reader })
+	defer gomysql.DeregisterReaderHandler(name)
+
+	res, err := m.MySQLDB.ExecContext(m.Ctx, loadSQL)
+	if err != nil {
+		return 0, fmt.Errorf("load reader [%v] sql [%v] failed: %v", name, loadSQL, err)
+	}
+	affectRows, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("load reader [%v] get rows affected failed: %v", name, err)
+	}
+	return affectRows, nil
+}
+
+// WriteMySQLTableWithFailover 用于下游 ProxySQL/HAProxy 等代理场景
+// 写入过程中遇到主从切换类错误，等待重连后重放当前 batch，要求写入语句幂等（REPLACE/INSERT IGNORE/ON DUPLICATE KEY UPDATE）
+// 2013 等连接丢失时 batch 可能已提交，普通 INSERT 重放主键冲突，调用方需拒绝 INSERT 与重试组合
+func (m *MySQL) WriteMySQLTableWithFailover(sql string, retryTimes int, retryInterval time.Duration) error {
+	var err error
+	for i := 0; i <= retryTimes; i++ {
+		if _, err = m.MySQLDB.ExecContext(m.Ctx, sql); err == nil {
+			return nil
+		}
+		if !IsMySQLFailoverError(err) {
+			return fmt.Errorf("source schema table sql [%v] write failed: %v", sql, err)
+		}
+		zap.L().Warn("target db failover detected, reconnect and replay batch",
+			zap.Int("retry", i+1),
+			zap.Int("retry times", retryTimes),
+			zap.Error(err))
+
+		select {
+		case <-m.Ctx.Done():
+			return m.Ctx.Err()
+		case <-time.After(retryInterval):
+		}
+		// 连接池剔除失效连接，重新建立连接
+		if errPing := m.MySQLDB.PingContext(m.Ctx); errPing != nil {
+			zap.L().Warn("target db ping failed, continue retry", zap.Error(errPing))
+		}
+	}
+	return fmt.Errorf("source schema table sql [%v] write failed after [%d] failover retry: %v", sql, retryTimes, err)
+}
+
+// WriteMySQLTableArgsWithFailover 预编译语句绑定参数写入，LOB 等大字段值不拼接 SQL 字面量
+func (m *MySQL) WriteMySQLTableArgsWithFailover(prepareSQL string, args []interface{}, retryTimes int, retryInterval time.Duration) error {
+	var err error
+	for i := 0; i <= retryTimes; i++ {
+		if _, err = m.MySQLDB.ExecContext(m.Ctx, prepareSQL, args...); err == nil {
+			return nil
+		}
+		if !IsMySQLFailoverError(err) {
+			return fmt.Errorf("source schema table prepare sql [%v] write failed: %v", prepareSQL, err)
+		}
+		zap.L().Warn("target db failover detected, reconnect and replay prepare batch",
+			zap.Int("retry", i+1),
+			zap.Int("retry times", retryTimes),
+			zap.Error(err))
+
+		select {
+		case <-m.Ctx.Done():
+			return m.Ctx.Err()
+		case <-time.After(retryInterval):
+		}
+		if errPing := m.MySQLDB.PingContext(m.Ctx); errPing != nil {
+			zap.L().Warn("target db ping failed, continue retry", zap.Error(errPing))
+		}
+	}
+	return fmt.Errorf("source schema table prepare sql [%v] write failed after [%d] failover retry: %v", prepareSQL, retryTimes, err)
+}
+
+// PrepareMySQLTableStmt 服务端预编译写入语句，连接失效时 database/sql 在新连接上自动重新预编译
+func (m *MySQL) PrepareMySQLTableStmt(prepareSQL string) (*sql.Stmt, error) {
+	stmt, err := m.MySQLDB.PrepareContext(m.Ctx, prepareSQL)
+	if err != nil {
+		return nil, fmt.Errorf("target schema table prepare sql [%v] failed: %v", prepareSQL, err)
+	}
+	return stmt, nil
+}
+
+// WriteMySQLTableStmtWithFailover 复用预编译语句绑定参数写入，仅传输参数不重复解析 SQL
+func (m *MySQL) WriteMySQLTableStmtWithFailover(stmt *sql.Stmt, prepareSQL string, args []interface{}, retryTimes int, retryInterval time.Duration) error {
+	var err error
+	for i := 0; i <= retryTimes; i++ {
+		if _, err = stmt.ExecContext(m.Ctx, args...); err == nil {
+			return nil
+		}
+		if !IsMySQLFailoverError(err) {
+			return fmt.Errorf("source schema table prepare stmt [%v] write failed: %v", prepareSQL, err)
+		}
+		zap.L().Warn("target db failover detected, reconnect and replay prepare stmt batch",
+			zap.Int("retry", i+1),
+			zap.Int("retry times", retryTimes),
+			zap.Error(err))
+
+		select {
+		case <-m.Ctx.Done():
+			return m.Ctx.Err()
+		case <-time.After(retryInterval):
+		}
+		if errPing := m.MySQLDB.PingContext(m.Ctx); errPing != nil {
+			zap.L().Warn("target db ping failed, continue retry", zap.Error(errPing))
+		}
+	}
+	return fmt.Errorf("source schema table prepare stmt [%v] write failed after [%d] failover retry: %v", prepareSQL, retryTimes, err)
+}
+
+// IsMySQLPrivilegeError 权限不足类错误
+// 1044/1045: 库访问拒绝
+// 1142: 表操作权限不足
+// 1227: 缺少特定权限（托管服务限制）
+func IsMySQLPrivilegeError(err error) bool {
+	var mysqlErr *gomysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case 1044, 1045, 1142, 1227:
+			return true
+		}
+	}
+	return false
+}
+
+// IsMySQLFailoverError 判断是否下游主从切换导致错误
+// 1290: --read-only 只读
+// 1836: read-only mode
+// 1305: SAVEPOINT 丢失
+// 2006/2013: 连接丢失
+func IsMySQLFailoverError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, gomysql.ErrInvalidConn) {
+		return true
+	}
+	var mysqlErr *gomysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case 1290, 1836, 1305, 2006, 2013:
+			return true
+		}
+	}
+	return false
+}
+
+// GetMySQLTableColumnType 获取表字段以及数据类型（排除生成列）
+func (m *MySQL) GetMySQLTableColumnType(schemaName, tableName string) ([]map[string]string, error) {
+	_, res, err := Query(m.Ctx, m.MySQLDB, fmt.Sprintf(`SELECT
+	COLUMN_NAME,
+	DATA_TYPE
+FROM
+	INFORMATION_SCHEMA.COLUMNS
+WHERE
+	TABLE_SCHEMA = '%s'
+	AND TABLE_NAME = '%s'
+	AND EXTRA NOT LIKE '%%GENERATED%%'
+ORDER BY
+	ORDINAL_POSITION`, schemaName, tableName))
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// GetMySQLTableIntegerPKColumn 获取单列整型主键字段，非单列整型主键返回空
+func (m *MySQL) GetMySQLTableIntegerPKColumn(schemaName, tableName string) (string, error) {
+	_, res, err := Query(m.Ctx, m.MySQLDB, fmt.Sprintf(`SELECT
+	k.COLUMN_NAME,
+	c.DATA_TYPE
+FROM
+	INFORMATION_SCHEMA.KEY_COLUMN_USAGE k
+	JOIN INFORMATION_SCHEMA.COLUMNS c ON k.TABLE_SCHEMA = c.TABLE_SCHEMA AND k.TABLE_NAME = c.TABLE_NAME AND k.COLUMN_NAME = c.COLUMN_NAME
+WHERE
+	k.TABLE_SCHEMA = '%s'
+	AND k.TABLE_NAME = '%s'
+	AND k.CONSTRAINT_NAME = 'PRIMARY'`, schemaName, tableName))
+	if err != nil {
+		return "", err
+	}
+	if len(res) != 1 {
+		return "", nil
+	}
+	switch common.StringUPPER(res[0]["DATA_TYPE"]) {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT":
+		return res[0]["COLUMN_NAME"], nil
+	default:
+		return "", nil
+	}
+}
+
+// GetMySQLTableRowsAndPKRange 获取表统计行数以及主键最小最大值
+func (m *MySQL) GetMySQLTableRowsAndPKRange(schemaName, tableName, pkColumn string) (int64, string, string, error) {
+	_, res, err := Query(m.Ctx, m.MySQLDB, fmt.Sprintf(`SELECT IFNULL(TABLE_ROWS,0) AS TABLE_ROWS FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = '%s' AND TABLE_NAME = '%s'`, schemaName, tableName))
+	if err != nil {
+		return 0, "", "", err
+	}
+	if len(res) == 0 {
+		return 0, "", "", fmt.Errorf("mysql schema [%s] table [%s] isn't exist", schemaName, tableName)
+	}
+	tableRows, err := strconv.ParseInt(res[0]["TABLE_ROWS"], 10, 64)
+	if err != nil {
+		return 0, "", "", fmt.Errorf("mysql schema [%s] table [%s] rows [%s] parse failed: %v", schemaName, tableName, res[0]["TABLE_ROWS"], err)
+	}
+
+	_, res, err = Query(m.Ctx, m.MySQLDB, fmt.Sprintf("SELECT MIN(`%s`) AS MIN_VALUE, MAX(`%s`) AS MAX_VALUE FROM `%s`.`%s`", pkColumn, pkColumn, schemaName, tableName))
+	if err != nil {
+		return 0, "", "", err
+	}
+	// 空表
+	if strings.EqualFold(res[0]["MIN_VALUE"], "NULLABLE") {
+		return tableRows, "", "", nil
+	}
+	return tableRows, res[0]["MIN_VALUE"], res[0]["MAX_VALUE"], nil
+}
+
+// QueryMySQLTableRowsByBatch 按批次读取原始字节数据，NULL 值为 nil
+func (m *MySQL) QueryMySQLTableRowsByBatch(querySQL string, batchSize int, fn func(rows [][][]byte) error) error {
+	rows, err := m.MySQLDB.QueryContext(m.Ctx, querySQL)
+	if err != nil {
+		return fmt.Errorf("mysql sql [%v] query failed: %v", querySQL, err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("mysql sql [%v] query rows.Columns failed: %v", querySQL, err)
+	}
+
+	rawValues := make([]sql.RawBytes, len(cols))
+	scans := make([]interface{}, len(cols))
+	for i := range rawValues {
+		scans[i] = &rawValues[i]
+	}
+
+	var batchRows [][][]byte
+	for rows.Next() {
+		if err = rows.Scan(scans...); err != nil {
+			return fmt.Errorf("mysql sql [%v] query rows.Scan failed: %v", querySQL, err)
+		}
+		// RawBytes 下一次 Scan 会被覆盖，需拷贝
+		row := make([][]byte, len(cols))
+		for i, v := range rawValues {
+			if v == nil {
+				row[i] = nil
+			} else {
+				row[i] = append([]byte{}, v...)
+			}
+		}
+		batchRows = append(batchRows, row)
+
+		if len(batchRows) == batchSize {
+			if err = fn(batchRows); err != nil {
+				return err
+			}
+			batchRows = make([][][]byte, 0, batchSize)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("mysql sql [%v] query rows.Next failed: %v", querySQL, err)
+	}
+	if len(batchRows) > 0 {
+		if err = fn(batchRows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
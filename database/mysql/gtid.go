@@ -0,0 +1,52 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package mysql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GetMySQLBinlogPoint 获取下游当前 binlog 文件、位点以及 gtid_executed
+// 未开启 binlog 时 binlog 文件为空，未开启 GTID 时 gtid_executed 为空
+func (m *MySQL) GetMySQLBinlogPoint() (string, uint64, string, error) {
+	var (
+		binlogFile string
+		binlogPos  uint64
+		gtidSet    string
+	)
+	// MySQL 8.2 起 SHOW MASTER STATUS 更名为 SHOW BINARY LOG STATUS
+	_, res, err := Query(m.Ctx, m.MySQLDB, `SHOW MASTER STATUS`)
+	if err != nil {
+		_, res, err = Query(m.Ctx, m.MySQLDB, `SHOW BINARY LOG STATUS`)
+		if err != nil {
+			return binlogFile, binlogPos, gtidSet, fmt.Errorf("error on show mysql master status: %v", err)
+		}
+	}
+	if len(res) > 0 {
+		binlogFile = res[0]["File"]
+		if res[0]["Position"] != "" {
+			binlogPos, err = strconv.ParseUint(res[0]["Position"], 10, 64)
+			if err != nil {
+				return binlogFile, binlogPos, gtidSet, fmt.Errorf("error on parse mysql binlog position [%s]: %v", res[0]["Position"], err)
+			}
+		}
+		// 多 server_uuid 时 gtid 集合带换行
+		gtidSet = strings.ReplaceAll(res[0]["Executed_Gtid_Set"], "\n", "")
+	}
+	return binlogFile, binlogPos, gtidSet, nil
+}
@@ -21,35 +21,137 @@ import (
 	"fmt"
 	"github.com/wentaojin/transferdb/common"
 	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/tunnel"
+	"go.uber.org/zap"
+	"net/url"
+	"strings"
 )
 
 type MySQL struct {
 	Ctx     context.Context
 	MySQLDB *sql.DB
+	// DDLDB 表结构变更连接，未配置 ddl-username 时与 MySQLDB 相同
+	DDLDB *sql.DB
+	// 目标表数据清理方式以及分批 DELETE 单批行数
+	CleanMode      string
+	CleanBatchSize int
+	// 目标端形态 COMMUNITY / RDS / AURORA
+	Flavor string
 }
 
 func NewMySQLDBEngine(ctx context.Context, mysqlCfg config.MySQLConfig) (*MySQL, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?%s",
-		mysqlCfg.Username, mysqlCfg.Password, mysqlCfg.Host, mysqlCfg.Port, mysqlCfg.SchemaName, mysqlCfg.ConnectParams)
+	addr, err := FailoverMySQLAddr(mysqlCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	adapt, err := adaptMySQLFlavor(mysqlCfg, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	mysqlDB, err := openMySQLDB(mysqlCfg, addr, mysqlCfg.Username, mysqlCfg.Password, adapt)
+	if err != nil {
+		return nil, err
+	}
+
+	ddlDB := mysqlDB
+	if adapt.DisableBinlog || (mysqlCfg.DDLUsername != "" && mysqlCfg.DDLUsername != mysqlCfg.Username) {
+		ddlUser, ddlPassword := mysqlCfg.Username, mysqlCfg.Password
+		if mysqlCfg.DDLUsername != "" {
+			ddlUser, ddlPassword = mysqlCfg.DDLUsername, mysqlCfg.DDLPassword
+		}
+		ddlAdapt := adapt
+		ddlAdapt.DisableBinlog = false
+		ddlDB, err = openMySQLDB(mysqlCfg, addr, ddlUser, ddlPassword, ddlAdapt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cleanBatchSize := mysqlCfg.TargetCleanBatchSize
+	if cleanBatchSize <= 0 {
+		cleanBatchSize = common.MigrateTargetCleanBatchSize
+	}
+
+	return &MySQL{
+		Ctx:            ctx,
+		MySQLDB:        mysqlDB,
+		DDLDB:          ddlDB,
+		CleanMode:      adapt.CleanMode,
+		CleanBatchSize: cleanBatchSize,
+		Flavor:         adapt.Flavor,
+	}, nil
+}
+
+// FailoverMySQLAddr 按 addrs 顺序探测建连，返回首个可用 host:port，实现建连时故障切换（隧道场景为本地转发地址）
+func FailoverMySQLAddr(mysqlCfg config.MySQLConfig) (string, error) {
+	endpoints, err := tunnel.ForwardAddrs(mysqlCfg.Tunnel, mysqlCfg.Host, mysqlCfg.Port, mysqlCfg.Addrs)
+	if err != nil {
+		return "", err
+	}
+	if len(endpoints) == 1 {
+		return endpoints[0], nil
+	}
+	for _, addr := range endpoints {
+		db, err := sql.Open("mysql", fmt.Sprintf("%s:%s@tcp(%s)/", mysqlCfg.Username, mysqlCfg.Password, addr))
+		if err != nil {
+			return "", fmt.Errorf("error on open mysql addr [%s] connection: %v", addr, err)
+		}
+		err = db.Ping()
+		db.Close()
+		if err == nil {
+			return addr, nil
+		}
+		zap.L().Warn("mysql addr connect failed, try next addr",
+			zap.String("addr", addr),
+			zap.Error(err))
+	}
+	return "", fmt.Errorf("error on connect mysql, all addrs %v unavailable", endpoints)
+}
+
+// genMySQLDSN 按配置拼接 DSN，携带 connect-params 以及会话时区
+func genMySQLDSN(mysqlCfg config.MySQLConfig, addr, username, password, schemaName string) string {
+	dsn := fmt.Sprintf("%s:%s@tcp(%s)/%s?%s",
+		username, password, addr, schemaName, mysqlCfg.ConnectParams)
+	// 会话时区固定，驱动建连时执行 SET time_zone
+	if mysqlCfg.SessionTimeZone != "" {
+		dsn = common.StringsBuilder(dsn, "&time_zone=", url.QueryEscape(common.StringsBuilder("'", mysqlCfg.SessionTimeZone, "'")))
+	}
+	return dsn
+}
+
+func openMySQLDB(mysqlCfg config.MySQLConfig, addr, username, password string, adapt mysqlFlavorAdapt) (*sql.DB, error) {
+	dsn := genMySQLDSN(mysqlCfg, addr, username, password, mysqlCfg.SchemaName)
+	// 驱动建连时执行 SET sql_log_bin = 0，连接池新建会话同样生效
+	if adapt.DisableBinlog {
+		dsn = common.StringsBuilder(dsn, "&sql_log_bin=0")
+	}
 
 	mysqlDB, err := sql.Open("mysql", dsn)
 	if err != nil {
-		return nil, fmt.Errorf("error on open mysql database connection [%v]: %v", mysqlCfg.SchemaName, err)
+		return nil, fmt.Errorf("error on open mysql database connection [%v] user [%v]: %v", mysqlCfg.SchemaName, username, err)
 	}
 
-	mysqlDB.SetMaxIdleConns(common.MySQLMaxIdleConn)
-	mysqlDB.SetMaxOpenConns(common.MySQLMaxConn)
+	// 连接池上限按目标端连接配额适配
+	mysqlDB.SetMaxIdleConns(adapt.MaxIdleConns)
+	mysqlDB.SetMaxOpenConns(adapt.MaxOpenConns)
 	mysqlDB.SetConnMaxLifetime(common.MySQLConnMaxLifeTime)
 	mysqlDB.SetConnMaxIdleTime(common.MySQLConnMaxIdleTime)
 
 	if err = mysqlDB.Ping(); err != nil {
-		return nil, fmt.Errorf("error on ping mysql database connection [%v]: %v", mysqlCfg.SchemaName, err)
+		return nil, fmt.Errorf("error on ping mysql database connection [%v] user [%v]: %v", mysqlCfg.SchemaName, username, err)
 	}
-
-	return &MySQL{
-		Ctx:     ctx,
-		MySQLDB: mysqlDB,
-	}, nil
+	if mysqlCfg.SessionTimeZone != "" {
+		var timeZone string
+		if err = mysqlDB.QueryRow(`SELECT @@SESSION.TIME_ZONE`).Scan(&timeZone); err != nil {
+			return nil, fmt.Errorf("error on query mysql session time zone user [%v]: %v", username, err)
+		}
+		if !strings.EqualFold(timeZone, mysqlCfg.SessionTimeZone) {
+			return nil, fmt.Errorf("mysql session time zone [%s] isn't equal config session-time-zone [%s]", timeZone, mysqlCfg.SessionTimeZone)
+		}
+	}
+	return mysqlDB, nil
 }
 
 func Query(ctx context.Context, db *sql.DB, querySQL string) ([]string, []map[string]string, error) {
@@ -74,9 +74,9 @@ func (m *MySQL) RenameMySQLTableName(schemaName string, tableName string) error
 		zap.String("schema", schemaName),
 		zap.String("table", tableName),
 		zap.String("sql", fmt.Sprintf("%v", querySQL)))
-	_, _, err := Query(m.Ctx, m.MySQLDB, querySQL)
+	_, err := m.DDLDB.ExecContext(m.Ctx, querySQL)
 	if err != nil {
-		return err
+		return fmt.Errorf("rename table sql [%v] exec failed: %v", querySQL, err)
 	}
 	return nil
 }
@@ -321,7 +321,8 @@ GROUP BY INDEX_NAME,UNIQUENESS,INDEX_TYPE,COLUMN_EXPRESSION`, schemaName, tableN
 		} else {
 			mysqlDBVersion = mysqlVersion
 		}
-		if common.VersionOrdinal(mysqlDBVersion) >= common.VersionOrdinal(common.MySQLExpressionIndexVersion) {
+		// MariaDB 不支持表达式索引，STATISTICS 不存在 EXPRESSION 字段
+		if !common.IsMariaDBVersion(mysqlVersion) && common.VersionOrdinal(mysqlDBVersion) >= common.VersionOrdinal(common.MySQLExpressionIndexVersion) {
 			query = fmt.Sprintf(`SELECT 
 		INDEX_NAME,
 		INDEX_TYPE,
@@ -388,7 +389,8 @@ GROUP BY INDEX_NAME,UNIQUENESS,INDEX_TYPE,COLUMN_EXPRESSION`, schemaName, tableN
 		} else {
 			mysqlDBVersion = mysqlVersion
 		}
-		if common.VersionOrdinal(mysqlDBVersion) >= common.VersionOrdinal(common.MySQLExpressionIndexVersion) {
+		// MariaDB 不支持表达式索引，STATISTICS 不存在 EXPRESSION 字段
+		if !common.IsMariaDBVersion(mysqlVersion) && common.VersionOrdinal(mysqlDBVersion) >= common.VersionOrdinal(common.MySQLExpressionIndexVersion) {
 			query = fmt.Sprintf(`SELECT 
 		INDEX_NAME,
 		INDEX_TYPE,
@@ -0,0 +1,95 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package mysql
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+)
+
+// ExportMySQLTableToFile 下游表数据导出 LOAD DATA 默认格式文件，用于切换回退快照
+// 字段 \t 分隔、行 \n 结尾、NULL 以 \N 表示、特殊字符反斜杠转义
+func (m *MySQL) ExportMySQLTableToFile(schemaName, tableName, columnDetail, fileName string) (int64, error) {
+	var rowCounts int64
+	rows, err := m.MySQLDB.QueryContext(m.Ctx, fmt.Sprintf("SELECT %s FROM `%s`.`%s`", columnDetail, schemaName, tableName))
+	if err != nil {
+		return rowCounts, fmt.Errorf("export mysql schema [%s] table [%s] query failed: %v", schemaName, tableName, err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return rowCounts, err
+	}
+	rawResult := make([][]byte, len(cols))
+	dest := make([]interface{}, len(cols))
+	for i := range rawResult {
+		dest[i] = &rawResult[i]
+	}
+
+	file, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return rowCounts, err
+	}
+	defer file.Close()
+	writer := bufio.NewWriterSize(file, 4<<20)
+
+	for rows.Next() {
+		if err = rows.Scan(dest...); err != nil {
+			return rowCounts, err
+		}
+		for i, raw := range rawResult {
+			if i > 0 {
+				writer.WriteByte('\t')
+			}
+			if raw == nil {
+				writer.WriteString(`\N`)
+				continue
+			}
+			for _, c := range raw {
+				switch c {
+				case '\\':
+					writer.WriteString(`\\`)
+				case '\t':
+					writer.WriteString(`\t`)
+				case '\n':
+					writer.WriteString(`\n`)
+				case '\r':
+					writer.WriteString(`\r`)
+				case 0:
+					writer.WriteString(`\0`)
+				default:
+					writer.WriteByte(c)
+				}
+			}
+		}
+		if err = writer.WriteByte('\n'); err != nil {
+			return rowCounts, fmt.Errorf("export mysql schema [%s] table [%s] write file [%s] failed: %v", schemaName, tableName, fileName, err)
+		}
+		rowCounts++
+	}
+	if err = rows.Err(); err != nil {
+		return rowCounts, err
+	}
+	if err = writer.Flush(); err != nil {
+		return rowCounts, fmt.Errorf("export mysql schema [%s] table [%s] flush file [%s] failed: %v", schemaName, tableName, fileName, err)
+	}
+	if err = file.Sync(); err != nil {
+		return rowCounts, err
+	}
+	return rowCounts, nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package mysql
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"strconv"
+	"strings"
+)
+
+// GetMySQLTableOrphanRows 子表关联字段均非空而父表不存在对应记录的孤儿数据行数以及样例键值
+func (m *MySQL) GetMySQLTableOrphanRows(schemaName, tableName string, columns []string, rtableName string, rcolumns []string, sampleRows int) (int64, []string, error) {
+	var (
+		joins      []string
+		notNulls   []string
+		childCols  []string
+		sampleKeys []string
+	)
+	for i, col := range columns {
+		joins = append(joins, fmt.Sprintf("c.`%s` = p.`%s`", col, rcolumns[i]))
+		notNulls = append(notNulls, fmt.Sprintf("c.`%s` IS NOT NULL", col))
+		childCols = append(childCols, fmt.Sprintf("c.`%s`", col))
+	}
+	fromSQL := fmt.Sprintf("FROM `%s`.`%s` c LEFT JOIN `%s`.`%s` p ON %s WHERE %s AND p.`%s` IS NULL",
+		schemaName, tableName, schemaName, rtableName,
+		strings.Join(joins, " AND "), strings.Join(notNulls, " AND "), rcolumns[0])
+
+	_, res, err := Query(m.Ctx, m.MySQLDB, common.StringsBuilder("SELECT COUNT(1) AS ORPHAN_ROWS ", fromSQL))
+	if err != nil {
+		return 0, sampleKeys, err
+	}
+	orphanRows, err := strconv.ParseInt(res[0]["ORPHAN_ROWS"], 10, 64)
+	if err != nil {
+		return 0, sampleKeys, fmt.Errorf("get mysql table [%s.%s] orphan rows [%s] parse failed: %v", schemaName, tableName, res[0]["ORPHAN_ROWS"], err)
+	}
+	if orphanRows == 0 || sampleRows <= 0 {
+		return orphanRows, sampleKeys, nil
+	}
+
+	cols, res, err := Query(m.Ctx, m.MySQLDB, common.StringsBuilder("SELECT DISTINCT ", strings.Join(childCols, ","), " ", fromSQL, " LIMIT ", strconv.Itoa(sampleRows)))
+	if err != nil {
+		return orphanRows, sampleKeys, err
+	}
+	for _, r := range res {
+		var keys []string
+		for _, c := range cols {
+			keys = append(keys, common.StringsBuilder(c, "=", r[c]))
+		}
+		sampleKeys = append(sampleKeys, common.StringsBuilder("(", strings.Join(keys, ","), ")"))
+	}
+	return orphanRows, sampleKeys, nil
+}
@@ -0,0 +1,167 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package opengauss
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	pq "gitee.com/opengauss/openGauss-connector-go-pq"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"strings"
+)
+
+type OpenGauss struct {
+	Ctx  context.Context
+	OGDB *sql.DB
+}
+
+func NewOpenGaussDBEngine(ctx context.Context, ogCfg config.OpenGaussConfig) (*OpenGauss, error) {
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
+		ogCfg.Host, ogCfg.Port, ogCfg.Username, ogCfg.Password, ogCfg.DBName)
+	if ogCfg.ConnectParams != "" {
+		dsn = common.StringsBuilder(dsn, " ", ogCfg.ConnectParams)
+	}
+
+	ogDB, err := sql.Open("opengauss", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error on open opengauss database connection [%v] user [%v]: %v", ogCfg.DBName, ogCfg.Username, err)
+	}
+
+	ogDB.SetMaxIdleConns(common.MySQLMaxIdleConn)
+	ogDB.SetMaxOpenConns(common.MySQLMaxConn)
+	ogDB.SetConnMaxLifetime(common.MySQLConnMaxLifeTime)
+	ogDB.SetConnMaxIdleTime(common.MySQLConnMaxIdleTime)
+
+	if err = ogDB.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("error on ping opengauss database connection [%v] user [%v]: %v", ogCfg.DBName, ogCfg.Username, err)
+	}
+
+	return &OpenGauss{
+		Ctx:  ctx,
+		OGDB: ogDB,
+	}, nil
+}
+
+func (g *OpenGauss) IsExistOpenGaussSchema(schemaName string) (bool, error) {
+	_, res, err := Query(g.Ctx, g.OGDB, fmt.Sprintf(`SELECT COUNT(1) AS COUNT FROM PG_NAMESPACE WHERE NSPNAME = '%s'`, schemaName))
+	if err != nil {
+		return false, err
+	}
+	if len(res) == 0 || res[0]["COUNT"] == "0" {
+		return false, nil
+	}
+	return true, nil
+}
+
+func (g *OpenGauss) WriteOpenGaussDDL(sql string) error {
+	_, err := g.OGDB.ExecContext(g.Ctx, sql)
+	if err != nil {
+		return fmt.Errorf("target opengauss schema ddl sql [%v] exec failed: %v", sql, err)
+	}
+	return nil
+}
+
+func (g *OpenGauss) TruncateOpenGaussTable(schemaName, tableName string) error {
+	truncateSQL := common.StringsBuilder(`TRUNCATE TABLE "`, schemaName, `"."`, tableName, `"`)
+	_, err := g.OGDB.ExecContext(g.Ctx, truncateSQL)
+	if err != nil {
+		return fmt.Errorf("truncate opengauss table sql [%v] failed: %v", truncateSQL, err)
+	}
+	return nil
+}
+
+// CopyOpenGaussTable COPY FROM STDIN 协议批量写入，单批次单事务提交
+func (g *OpenGauss) CopyOpenGaussTable(schemaName, tableName string, columns []string, rows [][]interface{}) error {
+	copySQL := pq.CopyInSchema(schemaName, tableName, columns...)
+	txn, err := g.OGDB.BeginTx(g.Ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("opengauss copy [%v] begin txn failed: %v", copySQL, err)
+	}
+	stmt, err := txn.PrepareContext(g.Ctx, copySQL)
+	if err != nil {
+		_ = txn.Rollback()
+		return fmt.Errorf("opengauss copy [%v] prepare failed: %v", copySQL, err)
+	}
+	for _, args := range rows {
+		if _, err = stmt.ExecContext(g.Ctx, args...); err != nil {
+			_ = stmt.Close()
+			_ = txn.Rollback()
+			return fmt.Errorf("opengauss copy [%v] row failed: %v", copySQL, err)
+		}
+	}
+	// 无参 Exec 刷新 COPY 缓冲
+	if _, err = stmt.ExecContext(g.Ctx); err != nil {
+		_ = stmt.Close()
+		_ = txn.Rollback()
+		return fmt.Errorf("opengauss copy [%v] flush failed: %v", copySQL, err)
+	}
+	if err = stmt.Close(); err != nil {
+		_ = txn.Rollback()
+		return fmt.Errorf("opengauss copy [%v] close failed: %v", copySQL, err)
+	}
+	if err = txn.Commit(); err != nil {
+		return fmt.Errorf("opengauss copy [%v] commit failed: %v", copySQL, err)
+	}
+	return nil
+}
+
+func Query(ctx context.Context, db *sql.DB, querySQL string) ([]string, []map[string]string, error) {
+	var (
+		cols []string
+		res  []map[string]string
+	)
+	rows, err := db.QueryContext(ctx, querySQL)
+	if err != nil {
+		return cols, res, fmt.Errorf("general sql [%v] query failed: [%v]", querySQL, err.Error())
+	}
+	defer rows.Close()
+
+	cols, err = rows.Columns()
+	if err != nil {
+		return cols, res, fmt.Errorf("general sql [%v] query rows.Columns failed: [%v]", querySQL, err.Error())
+	}
+
+	values := make([]sql.RawBytes, len(cols))
+	scans := make([]interface{}, len(cols))
+	for i := range values {
+		scans[i] = &values[i]
+	}
+
+	for rows.Next() {
+		err = rows.Scan(scans...)
+		if err != nil {
+			return cols, res, fmt.Errorf("general sql [%v] query rows.Scan failed: [%v]", querySQL, err.Error())
+		}
+
+		row := make(map[string]string)
+		for k, v := range values {
+			// 与 Oracle/MySQL 保持一致，NULL 值统一以 NULLABLE 表示，字段名统一大写
+			if v == nil {
+				row[strings.ToUpper(cols[k])] = "NULLABLE"
+			} else {
+				row[strings.ToUpper(cols[k])] = string(v)
+			}
+		}
+		res = append(res, row)
+	}
+
+	if err = rows.Err(); err != nil {
+		return cols, res, fmt.Errorf("general sql [%v] query rows.Next failed: [%v]", querySQL, err.Error())
+	}
+	return cols, res, nil
+}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/wentaojin/transferdb/common"
+	"strconv"
 )
 
 func (o *Oracle) GetOracleRedoLogSCN(scn string) (uint64, error) {
@@ -64,6 +65,24 @@ func (o *Oracle) GetOracleArchivedLogSCN(scn string) (uint64, error) {
 	return globalSCN, nil
 }
 
+// IsOracleLogCoverSCN 判断 SCN 是否被现存归档日志或在线重做日志覆盖
+func (o *Oracle) IsOracleLogCoverSCN(scn string) (bool, error) {
+	_, res, err := Query(o.Ctx, o.OracleDB, common.StringsBuilder(`SELECT COUNT(1) AS COUNTS
+  FROM (SELECT FIRST_CHANGE#, NEXT_CHANGE#
+          FROM v$ARCHIVED_LOG
+         WHERE STATUS = 'A'
+           AND DELETED = 'NO'
+        UNION ALL
+        SELECT FIRST_CHANGE#, NEXT_CHANGE#
+          FROM v$LOG)
+ WHERE FIRST_CHANGE# <= `, scn, `
+   AND NEXT_CHANGE# > `, scn))
+	if err != nil {
+		return false, err
+	}
+	return res[0]["COUNTS"] != "0", nil
+}
+
 func (o *Oracle) GetOracleRedoLogFile(scn string) ([]map[string]string, error) {
 	_, res, err := Query(o.Ctx, o.OracleDB, common.StringsBuilder(`SELECT 
        --l.GROUP# GROUP_NUMBER,
@@ -182,3 +201,51 @@ END;`))
 	}
 	return nil
 }
+
+// 快速恢复区空间使用率（扣除可回收空间）
+func (o *Oracle) GetOracleRecoveryAreaUsage() (float64, error) {
+	_, res, err := Query(o.Ctx, o.OracleDB, `SELECT NVL(SUM(PERCENT_SPACE_USED - PERCENT_SPACE_RECLAIMABLE),0) AS USED_PERCENT FROM v$recovery_area_usage`)
+	if err != nil {
+		return 0, err
+	}
+	if len(res) == 0 {
+		return 0, nil
+	}
+	usedPercent, err := strconv.ParseFloat(res[0]["USED_PERCENT"], 64)
+	if err != nil {
+		return 0, fmt.Errorf("get oracle recovery area usage [%s] strconv.ParseFloat failed: %v", res[0]["USED_PERCENT"], err)
+	}
+	return usedPercent, nil
+}
+
+// ASM 磁盘组空间使用率
+func (o *Oracle) GetOracleASMDiskgroupUsage() ([]map[string]string, error) {
+	_, res, err := Query(o.Ctx, o.OracleDB, `SELECT NAME,
+       TOTAL_MB,
+       FREE_MB,
+       ROUND((TOTAL_MB - FREE_MB) / DECODE(TOTAL_MB, 0, 1, TOTAL_MB) * 100, 2) AS USED_PERCENT
+  FROM v$asm_diskgroup`)
+	if err != nil {
+		return []map[string]string{}, err
+	}
+	return res, nil
+}
+
+// 最近一小时归档日志生成量，单位 MB
+func (o *Oracle) GetOracleArchivedLogGenerateRate() (float64, error) {
+	_, res, err := Query(o.Ctx, o.OracleDB, `SELECT NVL(ROUND(SUM(BLOCKS * BLOCK_SIZE) / 1024 / 1024, 2),0) AS LOG_MB
+  FROM v$archived_log
+ WHERE STANDBY_DEST = 'NO'
+   AND FIRST_TIME >= SYSDATE - 1 / 24`)
+	if err != nil {
+		return 0, err
+	}
+	if len(res) == 0 {
+		return 0, nil
+	}
+	logMB, err := strconv.ParseFloat(res[0]["LOG_MB"], 64)
+	if err != nil {
+		return 0, fmt.Errorf("get oracle archived log generate rate [%s] strconv.ParseFloat failed: %v", res[0]["LOG_MB"], err)
+	}
+	return logMB, nil
+}
@@ -17,11 +17,12 @@ package oracle
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
 func (o *Oracle) GetOracleDBName() (string, string, string, error) {
-	_, res, err := Query(o.Ctx, o.OracleDB, `SELECT name dbname,platform_id platform_id,platform_name platform_name FROM v$database`)
+	_, res, err := o.queryDictionary(`SELECT name dbname,platform_id platform_id,platform_name platform_name FROM v$database`)
 	if err != nil {
 		return "", "", "", err
 	}
@@ -29,7 +30,7 @@ func (o *Oracle) GetOracleDBName() (string, string, string, error) {
 }
 
 func (o *Oracle) GetOracleGlobalName() (string, error) {
-	_, res, err := Query(o.Ctx, o.OracleDB, `SELECT global_name global_name FROM global_name`)
+	_, res, err := o.queryDictionary(`SELECT global_name global_name FROM global_name`)
 	if err != nil {
 		return "", err
 	}
@@ -43,25 +44,25 @@ func (o *Oracle) GetOracleParameters() (string, string, string, string, error) {
 		CLusterDatabaseInstance string
 		characterSet            string
 	)
-	_, res, err := Query(o.Ctx, o.OracleDB, `SELECT VALUE FROM v$parameter WHERE	NAME = 'db_block_size'`)
+	_, res, err := o.queryDictionary(`SELECT VALUE FROM v$parameter WHERE	NAME = 'db_block_size'`)
 	if err != nil {
 		return dbBlockSize, clusterDatabase, CLusterDatabaseInstance, characterSet, err
 	}
 	dbBlockSize = res[0]["VALUE"]
 
-	_, res, err = Query(o.Ctx, o.OracleDB, `SELECT VALUE FROM v$parameter WHERE	NAME = 'cluster_database'`)
+	_, res, err = o.queryDictionary(`SELECT VALUE FROM v$parameter WHERE	NAME = 'cluster_database'`)
 	if err != nil {
 		return dbBlockSize, clusterDatabase, CLusterDatabaseInstance, characterSet, err
 	}
 	clusterDatabase = res[0]["VALUE"]
 
-	_, res, err = Query(o.Ctx, o.OracleDB, `SELECT VALUE FROM v$parameter WHERE	NAME = 'cluster_database_instances'`)
+	_, res, err = o.queryDictionary(`SELECT VALUE FROM v$parameter WHERE	NAME = 'cluster_database_instances'`)
 	if err != nil {
 		return dbBlockSize, clusterDatabase, CLusterDatabaseInstance, characterSet, err
 	}
 	CLusterDatabaseInstance = res[0]["VALUE"]
 
-	_, res, err = Query(o.Ctx, o.OracleDB, `select VALUE from nls_database_parameters WHERE PARAMETER='NLS_CHARACTERSET'`)
+	_, res, err = o.queryDictionary(`select VALUE from nls_database_parameters WHERE PARAMETER='NLS_CHARACTERSET'`)
 	if err != nil {
 		return dbBlockSize, clusterDatabase, CLusterDatabaseInstance, characterSet, err
 	}
@@ -71,7 +72,7 @@ func (o *Oracle) GetOracleParameters() (string, string, string, string, error) {
 }
 
 func (o *Oracle) GetOracleInstance() ([]map[string]string, error) {
-	_, res, err := Query(o.Ctx, o.OracleDB, `SELECT
+	_, res, err := o.queryDictionary(`SELECT
 	host_name,
 	instance_name,
 	instance_number,
@@ -85,7 +86,7 @@ FROM
 }
 
 func (o *Oracle) GetOracleDataTotal() (string, error) {
-	_, res, err := Query(o.Ctx, o.OracleDB, `SELECT
+	_, res, err := o.queryDictionary(`SELECT
     (a.bytes - f.bytes)/1024/1024/1024 GB
 FROM
     ( select sum(bytes) bytes
@@ -101,7 +102,7 @@ FROM
 }
 
 func (o *Oracle) GetOracleNumCPU() (string, error) {
-	_, res, err := Query(o.Ctx, o.OracleDB, `SELECT VALUE FROM GV$OSSTAT where stat_name='NUM_CPUS'`)
+	_, res, err := o.queryDictionary(`SELECT VALUE FROM GV$OSSTAT where stat_name='NUM_CPUS'`)
 	if err != nil {
 		return "", err
 	}
@@ -109,7 +110,7 @@ func (o *Oracle) GetOracleNumCPU() (string, error) {
 }
 
 func (o *Oracle) GetOracleMemoryGB() (string, error) {
-	_, res, err := Query(o.Ctx, o.OracleDB, `select value/1024/1024/1024 mem_gb from v$osstat where stat_name='PHYSICAL_MEMORY_BYTES'`)
+	_, res, err := o.queryDictionary(`select value/1024/1024/1024 mem_gb from v$osstat where stat_name='PHYSICAL_MEMORY_BYTES'`)
 	if err != nil {
 		return "", err
 	}
@@ -117,7 +118,7 @@ func (o *Oracle) GetOracleMemoryGB() (string, error) {
 }
 
 func (o *Oracle) GetOracleMaxActiveSessionCount() ([]map[string]string, error) {
-	_, res, err := Query(o.Ctx, o.OracleDB, `select rownum,a.* from (
+	_, res, err := o.queryDictionary(`select rownum,a.* from (
 select /*+ parallel 8 */
 dbid, instance_number, sample_id, sample_time, count(*) session_count
   from dba_hist_active_sess_history t
@@ -137,14 +138,14 @@ func (o *Oracle) GetOracleSchemaOverview(schemaName []string) ([]map[string]stri
 
 	userSQL = fmt.Sprintf(`select username from dba_users where username IN (%s)`, strings.Join(schemaName, ","))
 
-	_, res, err := Query(o.Ctx, o.OracleDB, userSQL)
+	_, res, err := o.queryDictionary(userSQL)
 	if err != nil {
 		return vals, err
 	}
 
 	for _, val := range res {
 		owner := fmt.Sprintf("'%s'", strings.ToUpper(val["USERNAME"]))
-		_, tableRes, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`SELECT ROUND(NVL(SUM( bytes )/ 1024 / 1024 / 1024,0),2) GB 
+		_, tableRes, err := o.queryDictionary(fmt.Sprintf(`SELECT ROUND(NVL(SUM( bytes )/ 1024 / 1024 / 1024,0),2) GB 
 FROM
 	dba_segments 
 WHERE
@@ -154,7 +155,7 @@ WHERE
 			return vals, err
 		}
 
-		_, indexRes, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`SELECT ROUND(NVL(SUM( bytes )/ 1024 / 1024 / 1024,0),2) GB
+		_, indexRes, err := o.queryDictionary(fmt.Sprintf(`SELECT ROUND(NVL(SUM( bytes )/ 1024 / 1024 / 1024,0),2) GB
 FROM
 	dba_indexes i,
 	dba_segments s 
@@ -167,7 +168,7 @@ WHERE
 			return vals, err
 		}
 
-		_, lobTable, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`SELECT ROUND(NVL(SUM( bytes )/ 1024 / 1024 / 1024,0) ,2) GB 
+		_, lobTable, err := o.queryDictionary(fmt.Sprintf(`SELECT ROUND(NVL(SUM( bytes )/ 1024 / 1024 / 1024,0) ,2) GB 
 FROM
 	dba_lobs l,
 	dba_segments s 
@@ -179,7 +180,7 @@ WHERE
 		if err != nil {
 			return vals, err
 		}
-		_, lobIndex, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`SELECT ROUND(NVL(SUM( bytes )/ 1024 / 1024 / 1024,0),2) GB
+		_, lobIndex, err := o.queryDictionary(fmt.Sprintf(`SELECT ROUND(NVL(SUM( bytes )/ 1024 / 1024 / 1024,0),2) GB
 FROM
 	dba_lobs l,
 	dba_segments s 
@@ -193,7 +194,7 @@ WHERE
 			return vals, err
 		}
 
-		_, tableRows, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`select NVL(SUM(num_rows),0) NUM_ROWS from dba_tables where OWNER IN (%s)`, owner))
+		_, tableRows, err := o.queryDictionary(fmt.Sprintf(`select NVL(SUM(num_rows),0) NUM_ROWS from dba_tables where OWNER IN (%s)`, owner))
 		if err != nil {
 			return vals, err
 		}
@@ -231,13 +232,13 @@ func (o *Oracle) GetOracleSchemaTableRowsTOP(schemaName []string) ([]map[string]
 
 	userSQL = fmt.Sprintf(`select username from dba_users where username IN (%s)`, strings.Join(schemaName, ","))
 
-	_, res, err := Query(o.Ctx, o.OracleDB, userSQL)
+	_, res, err := o.queryDictionary(userSQL)
 	if err != nil {
 		return vals, err
 	}
 	for _, val := range res {
 		owner := fmt.Sprintf("'%s'", strings.ToUpper(val["USERNAME"]))
-		_, tableRes, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`SELECT *
+		_, tableRes, err := o.queryDictionary(fmt.Sprintf(`SELECT *
 FROM
 (
 SELECT
@@ -276,7 +277,7 @@ ORDER BY GB DESC
 func (o *Oracle) GetOracleSchemaCodeObject(schemaName []string) ([]map[string]string, error) {
 	querySQL := fmt.Sprintf(`SELECT OWNER,NAME,TYPE,MAX(LINE) LINES from DBA_SOURCE where OWNER IN (%s) GROUP BY OWNER,NAME,TYPE ORDER BY LINES DESC`, strings.Join(schemaName, ","))
 
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -294,7 +295,7 @@ FROM
 WHERE
 	OWNER IN (%s)`, strings.Join(schemaName, ","))
 
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -304,7 +305,7 @@ WHERE
 func (o *Oracle) GetOracleSchemaTableAvgRowLengthTOP(schemaName []string) ([]map[string]string, error) {
 	querySQL := fmt.Sprintf(`SELECT ROWNUM,A.* FROM (select OWNER,TABLE_NAME,AVG_ROW_LEN FROM DBA_TABLES WHERE OWNER IN (%s) order by AVG_ROW_LEN desc nulls last)  A WHERE ROWNUM <=10`, strings.Join(schemaName, ","))
 
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -313,7 +314,7 @@ func (o *Oracle) GetOracleSchemaTableAvgRowLengthTOP(schemaName []string) ([]map
 
 func (o *Oracle) GetOracleSchemaSynonymObject(schemaName []string) ([]map[string]string, error) {
 	querySQL := fmt.Sprintf(`SELECT OWNER,SYNONYM_NAME,TABLE_OWNER,TABLE_NAME FROM dba_synonyms WHERE TABLE_OWNER IN (%s)`, strings.Join(schemaName, ","))
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -322,7 +323,7 @@ func (o *Oracle) GetOracleSchemaSynonymObject(schemaName []string) ([]map[string
 
 func (o *Oracle) GetOracleSchemaMaterializedViewObject(schemaName []string) ([]map[string]string, error) {
 	querySQL := fmt.Sprintf(`SELECT OWNER,MVIEW_NAME,REWRITE_CAPABILITY,REFRESH_MODE,REFRESH_METHOD,FAST_REFRESHABLE FROM DBA_MVIEWS WHERE OWNER IN (%s)`, strings.Join(schemaName, ","))
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -332,7 +333,7 @@ func (o *Oracle) GetOracleSchemaMaterializedViewObject(schemaName []string) ([]m
 func (o *Oracle) GetOracleSchemaPartitionTableCountsOver1024(schemaName []string) ([]map[string]string, error) {
 	querySQL := fmt.Sprintf(`select OWNER,TABLE_NAME,PARTITION_COUNT from DBA_PART_TABLES where OWNER IN (%s) and PARTITION_COUNT>1024`, strings.Join(schemaName, ","))
 
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -342,7 +343,7 @@ func (o *Oracle) GetOracleSchemaPartitionTableCountsOver1024(schemaName []string
 func (o *Oracle) GetOracleSchemaTableRowLengthOver6M(schemaName []string) ([]map[string]string, error) {
 	querySQL := fmt.Sprintf(`select OWNER,TABLE_NAME,ROUND(AVG_ROW_LEN/1024/1024,2) AS AVG_ROW_LEN FROM DBA_TABLES WHERE OWNER IN (%s) and ROUND(AVG_ROW_LEN/1024/1024,2) >= 6`, strings.Join(schemaName, ","))
 
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -353,7 +354,7 @@ func (o *Oracle) GetOracleSchemaTableIndexLengthOver3072(schemaName []string) ([
 	querySQL := fmt.Sprintf(`select INDEX_OWNER,INDEX_NAME,TABLE_NAME,sum(COLUMN_LENGTH)*4 LENGTH_OVER
   from dba_ind_columns where TABLE_OWNER IN (%s) group by INDEX_OWNER,INDEX_NAME,TABLE_NAME HAVING sum(COLUMN_LENGTH)*4 > 3072`, strings.Join(schemaName, ","))
 
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -363,7 +364,7 @@ func (o *Oracle) GetOracleSchemaTableIndexLengthOver3072(schemaName []string) ([
 func (o *Oracle) GetOracleSchemaTableColumnCountsOver512(schemaName []string) ([]map[string]string, error) {
 	querySQL := fmt.Sprintf(`SELECT OWNER,TABLE_NAME,COUNT(COLUMN_NAME) COUNT_OVER FROM DBA_TAB_COLUMNS  WHERE  OWNER IN (%s) GROUP BY  OWNER,TABLE_NAME HAVING COUNT(COLUMN_NAME) > 512 ORDER BY OWNER,TABLE_NAME`, strings.Join(schemaName, ","))
 
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -373,7 +374,7 @@ func (o *Oracle) GetOracleSchemaTableColumnCountsOver512(schemaName []string) ([
 func (o *Oracle) GetOracleSchemaTableIndexCountsOver64(schemaName []string) ([]map[string]string, error) {
 	querySQL := fmt.Sprintf(`select TABLE_OWNER,TABLE_NAME,COUNT(INDEX_NAME) COUNT_OVER FROM dba_ind_columns WHERE TABLE_OWNER IN (%s) GROUP BY TABLE_OWNER,TABLE_NAME HAVING COUNT(INDEX_NAME) >64 ORDER BY TABLE_OWNER,TABLE_NAME`, strings.Join(schemaName, ","))
 
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -382,17 +383,124 @@ func (o *Oracle) GetOracleSchemaTableIndexCountsOver64(schemaName []string) ([]m
 
 func (o *Oracle) GetOracleSchemaTableNumberTypeEqual0(schemaName []string) ([]map[string]string, error) {
 	querySQL := fmt.Sprintf(`select OWNER,TABLE_NAME,COLUMN_NAME,NVL(DATA_PRECISION,0) DATA_PRECISION,NVL(DATA_SCALE,0) DATA_SCALE from dba_tab_columns where DATA_PRECISION is null and OWNER IN (%s) and DATA_TYPE='NUMBER' and DATA_PRECISION is null ORDER BY OWNER,COLUMN_NAME`, strings.Join(schemaName, ","))
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
 	return res, nil
 }
 
+func (o *Oracle) GetOracleSchemaTablePurgeJob(schemaName []string) ([]map[string]string, error) {
+	// 基于日期清理数据的 job 启发式识别
+	querySQL := fmt.Sprintf(`SELECT OWNER,JOB_NAME,JOB_ACTION,REPEAT_INTERVAL FROM DBA_SCHEDULER_JOBS
+WHERE OWNER IN (%s)
+  AND UPPER(JOB_ACTION) LIKE '%%DELETE%%'
+  AND (UPPER(JOB_ACTION) LIKE '%%SYSDATE%%' OR UPPER(JOB_ACTION) LIKE '%%SYSTIMESTAMP%%')
+UNION ALL
+SELECT SCHEMA_USER AS OWNER,TO_CHAR(JOB) AS JOB_NAME,WHAT AS JOB_ACTION,INTERVAL AS REPEAT_INTERVAL FROM DBA_JOBS
+WHERE SCHEMA_USER IN (%s)
+  AND UPPER(WHAT) LIKE '%%DELETE%%'
+  AND (UPPER(WHAT) LIKE '%%SYSDATE%%' OR UPPER(WHAT) LIKE '%%SYSTIMESTAMP%%')`, strings.Join(schemaName, ","), strings.Join(schemaName, ","))
+	_, res, err := o.queryDictionary(querySQL)
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (o *Oracle) GetOracleSchemaLargeTable(schemaName []string, sizeGB int) ([]map[string]string, error) {
+	querySQL := fmt.Sprintf(`SELECT T.OWNER,T.TABLE_NAME,T.PARTITIONED,NVL(T.NUM_ROWS,0) NUM_ROWS,S.GB
+FROM DBA_TABLES T,
+(
+SELECT OWNER,SEGMENT_NAME,ROUND(NVL(SUM(BYTES)/1024/1024/1024,0),2) GB
+	FROM DBA_SEGMENTS
+	WHERE OWNER IN (%s)
+	AND SEGMENT_TYPE IN ('TABLE','TABLE PARTITION','TABLE SUBPARTITION')
+	GROUP BY OWNER,SEGMENT_NAME
+) S
+WHERE T.OWNER = S.OWNER
+  AND T.TABLE_NAME = S.SEGMENT_NAME
+  AND T.TEMPORARY = 'N'
+  AND S.GB >= %d
+ORDER BY S.GB DESC`, strings.Join(schemaName, ","), sizeGB)
+	_, res, err := o.queryDictionary(querySQL)
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (o *Oracle) GetOracleTablePartitionAdvisory(schemaName, tableName string) ([]map[string]string, error) {
+	// 分区交换需逐分区 EXCHANGE 至同构暂存表，非分区索引（全局索引）在交换后失效需 UPDATE GLOBAL INDEXES
+	querySQL := fmt.Sprintf(`SELECT P.PARTITIONING_TYPE,
+	P.SUBPARTITIONING_TYPE,
+	P.PARTITION_COUNT,
+	(SELECT COUNT(1) FROM DBA_INDEXES I WHERE I.TABLE_OWNER = P.OWNER AND I.TABLE_NAME = P.TABLE_NAME AND I.PARTITIONED = 'NO') GLOBAL_INDEX_COUNTS
+FROM DBA_PART_TABLES P
+WHERE P.OWNER = '%s'
+  AND P.TABLE_NAME = '%s'`, schemaName, tableName)
+	_, res, err := o.queryDictionary(querySQL)
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (o *Oracle) GetOracleTableTablespaceAdvisory(schemaName, tableName string) ([]map[string]string, error) {
+	// 表、索引、LOB 段所在表空间以及表空间内不属于该表的段数，FOREIGN_SEGMENTS 为 0 表示表空间专属该表
+	querySQL := fmt.Sprintf(`WITH OBJS AS (
+SELECT OWNER,TABLE_NAME SEGMENT_NAME FROM DBA_TABLES WHERE OWNER = '%[1]s' AND TABLE_NAME = '%[2]s'
+UNION ALL
+SELECT OWNER,INDEX_NAME SEGMENT_NAME FROM DBA_INDEXES WHERE TABLE_OWNER = '%[1]s' AND TABLE_NAME = '%[2]s'
+UNION ALL
+SELECT OWNER,SEGMENT_NAME FROM DBA_LOBS WHERE OWNER = '%[1]s' AND TABLE_NAME = '%[2]s'
+UNION ALL
+SELECT OWNER,INDEX_NAME SEGMENT_NAME FROM DBA_LOBS WHERE OWNER = '%[1]s' AND TABLE_NAME = '%[2]s'
+),
+TS AS (
+SELECT DISTINCT S.TABLESPACE_NAME FROM DBA_SEGMENTS S,OBJS O WHERE S.OWNER = O.OWNER AND S.SEGMENT_NAME = O.SEGMENT_NAME
+)
+SELECT TS.TABLESPACE_NAME,
+	T.CONTENTS,
+	T.STATUS,
+	(SELECT COUNT(1) FROM DBA_SEGMENTS S WHERE S.TABLESPACE_NAME = TS.TABLESPACE_NAME AND (S.OWNER,S.SEGMENT_NAME) NOT IN (SELECT OWNER,SEGMENT_NAME FROM OBJS)) FOREIGN_SEGMENTS
+FROM TS,DBA_TABLESPACES T
+WHERE TS.TABLESPACE_NAME = T.TABLESPACE_NAME
+ORDER BY TS.TABLESPACE_NAME`, schemaName, tableName)
+	_, res, err := o.queryDictionary(querySQL)
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (o *Oracle) GetOracleTableExternalReferenceCounts(schemaName, tableName string) (int, error) {
+	// 跨表外键（引用或被引用）会导致传输表空间集合非自包含
+	querySQL := fmt.Sprintf(`SELECT COUNT(1) COUNTS
+FROM DBA_CONSTRAINTS C,DBA_CONSTRAINTS R
+WHERE C.CONSTRAINT_TYPE = 'R'
+  AND C.R_OWNER = R.OWNER
+  AND C.R_CONSTRAINT_NAME = R.CONSTRAINT_NAME
+  AND ((C.OWNER = '%[1]s' AND C.TABLE_NAME = '%[2]s') OR (R.OWNER = '%[1]s' AND R.TABLE_NAME = '%[2]s'))
+  AND NOT (C.OWNER = R.OWNER AND C.TABLE_NAME = R.TABLE_NAME)`, schemaName, tableName)
+	_, res, err := o.queryDictionary(querySQL)
+	if err != nil {
+		return 0, err
+	}
+	if len(res) == 0 {
+		return 0, nil
+	}
+	counts, err := strconv.Atoi(res[0]["COUNTS"])
+	if err != nil {
+		return 0, fmt.Errorf("get oracle table [%s.%s] external reference counts [%s] strconv.Atoi failed: %v", schemaName, tableName, res[0]["COUNTS"], err)
+	}
+	return counts, nil
+}
+
 func (o *Oracle) GetOracleUsernameLengthOver64(schemaName []string) ([]map[string]string, error) {
 
 	querySQL := fmt.Sprintf(`select USERNAME,ACCOUNT_STATUS,CREATED,length(USERNAME) LENGTH_OVER from dba_users where username IN (%s) AND length(USERNAME) > 64`, strings.Join(schemaName, ","))
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -402,7 +510,7 @@ func (o *Oracle) GetOracleUsernameLengthOver64(schemaName []string) ([]map[strin
 func (o *Oracle) GetOracleSchemaTableNameLengthOver64(schemaName []string) ([]map[string]string, error) {
 	querySQL := fmt.Sprintf(`SELECT OWNER,TABLE_NAME,length(TABLE_NAME) LENGTH_OVER FROM DBA_TABLES WHERE OWNER IN (%s) AND length(TABLE_NAME) > 64 ORDER BY OWNER,TABLE_NAME`, strings.Join(schemaName, ","))
 
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -412,7 +520,7 @@ func (o *Oracle) GetOracleSchemaTableNameLengthOver64(schemaName []string) ([]ma
 func (o *Oracle) GetOracleSchemaTableColumnNameLengthOver64(schemaName []string) ([]map[string]string, error) {
 	querySQL := fmt.Sprintf(`SELECT OWNER,TABLE_NAME,COLUMN_NAME,length(COLUMN_NAME) LENGTH_OVER FROM DBA_TAB_COLUMNS WHERE OWNER IN (%s) AND length(COLUMN_NAME) >64 ORDER BY OWNER,TABLE_NAME,COLUMN_NAME`, strings.Join(schemaName, ","))
 
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -423,7 +531,7 @@ func (o *Oracle) GetOracleSchemaTableIndexNameLengthOver64(schemaName []string)
 	querySQL := fmt.Sprintf(`SELECT INDEX_OWNER,TABLE_NAME,INDEX_NAME,LENGTH(COLUMN_NAME) LENGTH_OVER
  FROM DBA_IND_COLUMNS WHERE TABLE_OWNER IN (%s) AND LENGTH(COLUMN_NAME) > 64 ORDER BY INDEX_OWNER,TABLE_NAME,INDEX_NAME`, strings.Join(schemaName, ","))
 
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -433,7 +541,7 @@ func (o *Oracle) GetOracleSchemaTableIndexNameLengthOver64(schemaName []string)
 func (o *Oracle) GetOracleSchemaTableViewNameLengthOver64(schemaName []string) ([]map[string]string, error) {
 	querySQL := fmt.Sprintf(`SELECT OWNER,VIEW_NAME,READ_ONLY,LENGTH(VIEW_NAME) LENGTH_OVER FROM DBA_VIEWS WHERE OWNER IN (%s) AND LENGTH(VIEW_NAME) > 64 ORDER BY OWNER,VIEW_NAME`, strings.Join(schemaName, ","))
 
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -443,7 +551,7 @@ func (o *Oracle) GetOracleSchemaTableViewNameLengthOver64(schemaName []string) (
 func (o *Oracle) GetOracleSchemaTableSequenceNameLengthOver64(schemaName []string) ([]map[string]string, error) {
 	querySQL := fmt.Sprintf(`SELECT SEQUENCE_OWNER,SEQUENCE_NAME,ORDER_FLAG,LENGTH(SEQUENCE_NAME) LENGTH_OVER FROM DBA_SEQUENCES WHERE SEQUENCE_OWNER IN (%s) AND LENGTH(SEQUENCE_NAME) > 64 ORDER BY SEQUENCE_OWNER,SEQUENCE_NAME,ORDER_FLAG`, strings.Join(schemaName, ","))
 
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -485,7 +593,7 @@ select owner,iot_name,iot_type from DBA_TABLES WHERE owner in (%s)) t
 ON f.owner = t.owner AND f.table_name = t.iot_name) temp
 GROUP BY temp.SCHEMA_NAME,temp.TABLE_TYPE`, strings.Join(schemaName, ","), strings.Join(schemaName, ","))
 
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -510,7 +618,7 @@ func (o *Oracle) GetOracleSchemaColumnDataDefaultCounts(schemaName []string) ([]
 		DATA_DEFAULT VARCHAR2 ( 4000 )
 	) xs
 	GROUP BY xs.OWNER,xs.DATA_DEFAULT`, strings.Join(schemaName, ","))
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -520,7 +628,7 @@ func (o *Oracle) GetOracleSchemaColumnDataDefaultCounts(schemaName []string) ([]
 func (o *Oracle) GetOracleSchemaViewTypeCounts(schemaName []string) ([]map[string]string, error) {
 	// Type of the view if the view is a typed view -> view_type
 	querySQL := fmt.Sprintf(`SELECT OWNER,NVL(VIEW_TYPE,'VIEW') VIEW_TYPE,VIEW_TYPE_OWNER,COUNT(1) AS COUNTS FROM DBA_VIEWS WHERE OWNER IN (%s) GROUP BY OWNER,VIEW_TYPE,VIEW_TYPE_OWNER`, strings.Join(schemaName, ","))
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -530,7 +638,7 @@ func (o *Oracle) GetOracleSchemaViewTypeCounts(schemaName []string) ([]map[strin
 func (o *Oracle) GetOracleSchemaObjectTypeCounts(schemaName []string) ([]map[string]string, error) {
 	querySQL := fmt.Sprintf(`SELECT OWNER,OBJECT_TYPE,COUNT(1) COUNTS FROM DBA_OBJECTS WHERE OWNER IN (%s) AND OBJECT_TYPE NOT IN ('TABLE','TABLE PARTITION','TABLE SUBPARTITION','INDEX','VIEW') GROUP BY OWNER,OBJECT_TYPE`, strings.Join(schemaName, ","))
 
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -550,7 +658,7 @@ WHERE
 AND SUBPARTITIONING_TYPE = 'NONE'
 GROUP BY OWNER,PARTITIONING_TYPE`, strings.Join(schemaName, ","))
 
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -575,7 +683,7 @@ AND SUBPARTITIONING_TYPE <> 'NONE'
 )
 GROUP BY OWNER,SUBPARTITIONING_TYPE`, strings.Join(schemaName, ","))
 
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -585,7 +693,24 @@ GROUP BY OWNER,SUBPARTITIONING_TYPE`, strings.Join(schemaName, ","))
 func (o *Oracle) GetOracleSchemaTemporaryTableTypeCounts(schemaName []string) ([]map[string]string, error) {
 	querySQL := fmt.Sprintf(`select OWNER,DURATION AS TEMP_TYPE,COUNT(*) COUNT FROM DBA_TABLES WHERE OWNER IN (%s) AND TEMPORARY='Y' AND DURATION IS NOT NULL GROUP BY OWNER,DURATION`, strings.Join(schemaName, ","))
 
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (o *Oracle) GetOracleSchemaTemporalTableTypeCounts(schemaName []string, temporalValidity bool) ([]map[string]string, error) {
+	querySQL := fmt.Sprintf(`SELECT OWNER_NAME AS OWNER,'FLASHBACK ARCHIVE' AS TEMPORAL_TYPE,COUNT(*) COUNTS FROM DBA_FLASHBACK_ARCHIVE_TABLES WHERE OWNER_NAME IN (%s) GROUP BY OWNER_NAME`, strings.Join(schemaName, ","))
+
+	// Temporal Validity 有效期定义 oracle 12c 及以上
+	if temporalValidity {
+		querySQL += fmt.Sprintf(`
+UNION ALL
+SELECT O.OWNER,'TEMPORAL VALIDITY' AS TEMPORAL_TYPE,COUNT(DISTINCT O.OBJECT_NAME) COUNTS FROM SYS.SYS_FBA_PERIOD P,DBA_OBJECTS O WHERE P.OBJ# = O.OBJECT_ID AND O.OBJECT_TYPE = 'TABLE' AND O.OWNER IN (%s) GROUP BY O.OWNER`, strings.Join(schemaName, ","))
+	}
+
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -594,7 +719,7 @@ func (o *Oracle) GetOracleSchemaTemporaryTableTypeCounts(schemaName []string) ([
 
 func (o *Oracle) GetOracleSchemaConstraintTypeCounts(schemaName []string) ([]map[string]string, error) {
 	querySQL := fmt.Sprintf(`select owner,CONSTRAINT_TYPE,count(*) COUNT from dba_constraints where OWNER IN (%s) group by owner,CONSTRAINT_TYPE ORDER BY COUNT DESC`, strings.Join(schemaName, ","))
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -604,7 +729,7 @@ func (o *Oracle) GetOracleSchemaConstraintTypeCounts(schemaName []string) ([]map
 func (o *Oracle) GetOracleSchemaIndexTypeCounts(schemaName []string) ([]map[string]string, error) {
 	querySQL := fmt.Sprintf(`select owner,INDEX_TYPE,count(*) COUNT from dba_indexes where OWNER IN (%s) group by owner,INDEX_TYPE ORDER BY COUNT DESC`, strings.Join(schemaName, ","))
 
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -621,7 +746,7 @@ func (o *Oracle) GetOracleSchemaColumnTypeCounts(schemaName []string) ([]map[str
 	ELSE
 	 MAX(DATA_LENGTH)
 	END AS MAX_DATA_LENGTH from dba_tab_columns where OWNER IN (%s)  group by OWNER,DATA_TYPE ORDER BY COUNT DESC`, strings.Join(schemaName, ","))
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -23,7 +23,7 @@ import (
 
 func (o *Oracle) GetOracleDBCharacterNLSSortCollation() (string, error) {
 	querySQL := fmt.Sprintf(`SELECT VALUE from NLS_DATABASE_PARAMETERS WHERE PARAMETER = 'NLS_SORT'`)
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return "", err
 	}
@@ -32,7 +32,7 @@ func (o *Oracle) GetOracleDBCharacterNLSSortCollation() (string, error) {
 
 func (o *Oracle) GetOracleDBCharacterNLSCompCollation() (string, error) {
 	querySQL := fmt.Sprintf(`SELECT VALUE from NLS_DATABASE_PARAMETERS WHERE PARAMETER = 'NLS_COMP'`)
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return "", err
 	}
@@ -41,7 +41,7 @@ func (o *Oracle) GetOracleDBCharacterNLSCompCollation() (string, error) {
 
 func (o *Oracle) GetOracleDBVersion() (string, error) {
 	querySQL := fmt.Sprintf(`select VALUE from NLS_DATABASE_PARAMETERS WHERE PARAMETER='NLS_RDBMS_VERSION'`)
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res[0]["VALUE"], err
 	}
@@ -50,7 +50,7 @@ func (o *Oracle) GetOracleDBVersion() (string, error) {
 
 func (o *Oracle) GetOracleDBCharacterSet() (string, error) {
 	querySQL := fmt.Sprintf(`select userenv('language') AS LANG from dual`)
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res[0]["LANG"], err
 	}
@@ -60,7 +60,7 @@ func (o *Oracle) GetOracleDBCharacterSet() (string, error) {
 func (o *Oracle) GetOracleSchemaCollation(schemaName string) (string, error) {
 	querySQL := fmt.Sprintf(`SELECT DECODE(DEFAULT_COLLATION,
 'USING_NLS_COMP',(SELECT VALUE from NLS_DATABASE_PARAMETERS WHERE PARAMETER = 'NLS_COMP'),DEFAULT_COLLATION) DEFAULT_COLLATION FROM DBA_USERS WHERE USERNAME = '%s'`, strings.ToUpper(schemaName))
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return "", err
 	}
@@ -72,7 +72,7 @@ func (o *Oracle) GetOracleSchemaTableCollation(schemaName, schemaCollation strin
 
 	tablesMap := make(map[string]string)
 
-	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`SELECT TABLE_NAME,DEFAULT_COLLATION FROM DBA_TABLES WHERE UPPER(owner) = UPPER('%s') AND (IOT_TYPE IS NUll OR IOT_TYPE='IOT')`, schemaName))
+	_, res, err := o.queryDictionary(fmt.Sprintf(`SELECT TABLE_NAME,DEFAULT_COLLATION FROM DBA_TABLES WHERE UPPER(owner) = UPPER('%s') AND (IOT_TYPE IS NUll OR IOT_TYPE='IOT')`, schemaName))
 	if err != nil {
 		return tablesMap, err
 	}
@@ -90,7 +90,7 @@ func (o *Oracle) GetOracleSchemaTableCollation(schemaName, schemaCollation strin
 func (o *Oracle) GetOracleSchemaTableType(schemaName string) (map[string]string, error) {
 	tableMap := make(map[string]string)
 
-	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`SELECT 
+	_, res, err := o.queryDictionary(fmt.Sprintf(`SELECT 
 f.TABLE_NAME,
 	(
 	CASE WHEN f.CLUSTER_NAME IS NOT NULL THEN 'CLUSTERED' ELSE
@@ -133,7 +133,7 @@ ON f.owner = t.owner AND f.table_name = t.iot_name`, strings.ToUpper(schemaName)
 }
 
 func (o *Oracle) IsOraclePartitionTable(schemaName, tableName string) (bool, error) {
-	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`select count(1) AS COUNT
+	_, res, err := o.queryDictionary(fmt.Sprintf(`select count(1) AS COUNT
   from dba_tables
  where partitioned = 'YES'
    and upper(owner) = upper('%s')
@@ -147,6 +147,36 @@ func (o *Oracle) IsOraclePartitionTable(schemaName, tableName string) (bool, err
 	return true, nil
 }
 
+func (o *Oracle) GetOracleTablePartitionName(schemaName, tableName string) ([]string, error) {
+	_, res, err := o.queryDictionary(fmt.Sprintf(`SELECT PARTITION_NAME
+  FROM DBA_TAB_PARTITIONS
+ WHERE UPPER(TABLE_OWNER) = UPPER('%s')
+   AND UPPER(TABLE_NAME) = UPPER('%s')
+ ORDER BY PARTITION_POSITION`, schemaName, tableName))
+	if err != nil {
+		return nil, err
+	}
+	var parts []string
+	for _, r := range res {
+		parts = append(parts, r["PARTITION_NAME"])
+	}
+	return parts, nil
+}
+
+func (o *Oracle) GetOracleTableTrigger(schemaName, tableName string) ([]map[string]string, error) {
+	_, res, err := o.queryDictionary(fmt.Sprintf(`SELECT TRIGGER_NAME,
+       TRIGGER_TYPE,
+       TRIGGERING_EVENT
+  FROM DBA_TRIGGERS
+ WHERE BASE_OBJECT_TYPE = 'TABLE'
+   AND UPPER(TABLE_OWNER) = UPPER('%s')
+   AND UPPER(TABLE_NAME) = UPPER('%s')`, schemaName, tableName))
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
 func (o *Oracle) GetOraclePartitionTableINFO(schemaName, tableName string) ([]map[string]string, error) {
 	querySQL := fmt.Sprintf(`SELECT L.PARTITIONING_TYPE,
        L.SUBPARTITIONING_TYPE,
@@ -172,7 +202,7 @@ WHERE L.OWNER = skc.OWNER
 GROUP BY  L.PARTITIONING_TYPE,
        L.SUBPARTITIONING_TYPE,
        L.PARTITION_EXPRESS`, schemaName, tableName)
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
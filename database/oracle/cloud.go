@@ -0,0 +1,199 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package oracle
+
+import (
+	"archive/zip"
+	"fmt"
+	"github.com/wentaojin/transferdb/config"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// tnsnames.ora 服务别名，如 mydb_high = (description= ...)
+	oracleTNSAliasRegex = regexp.MustCompile(`(?m)^\s*([A-Za-z0-9_.\-]+)\s*=`)
+	// sqlnet.ora wallet 目录，ADB 下载 wallet 默认 DIRECTORY="?/network/admin"
+	oracleWalletDirRegex = regexp.MustCompile(`(?i)DIRECTORY\s*=\s*"[^"]*"`)
+)
+
+// IsOracleWalletConfig 是否配置云数据库 wallet 连接
+func IsOracleWalletConfig(oraCfg config.OracleConfig) bool {
+	return oraCfg.WalletZip != "" || oraCfg.WalletDir != ""
+}
+
+// prepareOracleWallet 解压 wallet zip 并校验服务别名，返回 wallet 目录（TNS_ADMIN）
+// 1、wallet-zip 非空时解压至 wallet-dir，wallet-dir 为空解压至 zip 同名目录
+// 2、sqlnet.ora wallet 目录改写为实际解压目录
+// 3、服务别名必须存在于 tnsnames.ora 且为 TCPS 协议，ADB 强制 TLS 连接
+func prepareOracleWallet(oraCfg config.OracleConfig) (string, error) {
+	walletDir := oraCfg.WalletDir
+	if oraCfg.WalletZip != "" {
+		if walletDir == "" {
+			walletDir = strings.TrimSuffix(oraCfg.WalletZip, filepath.Ext(oraCfg.WalletZip))
+		}
+		if err := unzipOracleWallet(oraCfg.WalletZip, walletDir); err != nil {
+			return "", err
+		}
+	}
+	walletDir, err := filepath.Abs(walletDir)
+	if err != nil {
+		return "", fmt.Errorf("oracle wallet dir [%s] get abs path failed: %v", walletDir, err)
+	}
+
+	if err = rewriteOracleWalletSQLNet(walletDir); err != nil {
+		return "", err
+	}
+
+	aliases, err := getOracleWalletTNSNames(walletDir)
+	if err != nil {
+		return "", err
+	}
+	var aliasNames []string
+	for a := range aliases {
+		aliasNames = append(aliasNames, a)
+	}
+	sort.Strings(aliasNames)
+
+	if oraCfg.TNSAlias == "" {
+		return "", fmt.Errorf("oracle wallet config [tns-alias] can't be null, tnsnames.ora service alias %v", aliasNames)
+	}
+	descriptor, ok := aliases[strings.ToLower(oraCfg.TNSAlias)]
+	if !ok {
+		return "", fmt.Errorf("oracle wallet tns-alias [%s] isn't exist in tnsnames.ora, service alias %v", oraCfg.TNSAlias, aliasNames)
+	}
+	if !strings.Contains(strings.ToUpper(strings.Join(strings.Fields(descriptor), "")), "PROTOCOL=TCPS") {
+		return "", fmt.Errorf("oracle wallet tns-alias [%s] protocol isn't tcps, cloud database require tls connection", oraCfg.TNSAlias)
+	}
+
+	zap.L().Info("oracle wallet prepared",
+		zap.String("wallet dir", walletDir),
+		zap.String("tns alias", oraCfg.TNSAlias))
+	return walletDir, nil
+}
+
+func unzipOracleWallet(zipFile, walletDir string) error {
+	r, err := zip.OpenReader(zipFile)
+	if err != nil {
+		return fmt.Errorf("oracle wallet zip [%s] open failed: %v", zipFile, err)
+	}
+	defer r.Close()
+
+	if err = os.MkdirAll(walletDir, 0700); err != nil {
+		return fmt.Errorf("oracle wallet dir [%s] create failed: %v", walletDir, err)
+	}
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		// wallet 文件平铺于同一目录，忽略 zip 内路径，避免路径穿越
+		target := filepath.Join(walletDir, filepath.Base(f.Name))
+		if err = unzipOracleWalletFile(f, target); err != nil {
+			return fmt.Errorf("oracle wallet zip [%s] file [%s] unzip failed: %v", zipFile, f.Name, err)
+		}
+	}
+	return nil
+}
+
+func unzipOracleWalletFile(f *zip.File, target string) error {
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+func rewriteOracleWalletSQLNet(walletDir string) error {
+	sqlnetFile := filepath.Join(walletDir, "sqlnet.ora")
+	content, err := os.ReadFile(sqlnetFile)
+	if err != nil {
+		return fmt.Errorf("oracle wallet sqlnet.ora [%s] read failed: %v", sqlnetFile, err)
+	}
+	newContent := oracleWalletDirRegex.ReplaceAllString(string(content), fmt.Sprintf(`DIRECTORY="%s"`, walletDir))
+	if newContent == string(content) {
+		return nil
+	}
+	if err = os.WriteFile(sqlnetFile, []byte(newContent), 0600); err != nil {
+		return fmt.Errorf("oracle wallet sqlnet.ora [%s] rewrite failed: %v", sqlnetFile, err)
+	}
+	return nil
+}
+
+// getOracleWalletTNSNames tnsnames.ora 服务别名（小写）-> connect descriptor
+func getOracleWalletTNSNames(walletDir string) (map[string]string, error) {
+	tnsFile := filepath.Join(walletDir, "tnsnames.ora")
+	content, err := os.ReadFile(tnsFile)
+	if err != nil {
+		return nil, fmt.Errorf("oracle wallet tnsnames.ora [%s] read failed: %v", tnsFile, err)
+	}
+	text := string(content)
+	aliases := make(map[string]string)
+
+	// 仅括号外的 name = 为服务别名，排除多行 descriptor 内参数
+	var locs [][]int
+	for _, loc := range oracleTNSAliasRegex.FindAllStringSubmatchIndex(text, -1) {
+		if strings.Count(text[:loc[0]], "(") == strings.Count(text[:loc[0]], ")") {
+			locs = append(locs, loc)
+		}
+	}
+	for i, loc := range locs {
+		end := len(text)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		aliases[strings.ToLower(text[loc[2]:loc[3]])] = strings.TrimSpace(text[loc[1]:end])
+	}
+	return aliases, nil
+}
+
+// GetOracleCloudService 云数据库服务类型（OLTP/DWCS/JDCS 等），非 Autonomous Database 或低版本不支持该参数返回空
+func (o *Oracle) GetOracleCloudService() string {
+	_, res, err := Query(o.Ctx, o.OracleDB, `SELECT NVL(SYS_CONTEXT('USERENV','CLOUD_SERVICE'),'NONE') CLOUD_SERVICE FROM DUAL`)
+	if err != nil || len(res) == 0 || strings.EqualFold(res[0]["CLOUD_SERVICE"], "NONE") {
+		return ""
+	}
+	return strings.ToUpper(res[0]["CLOUD_SERVICE"])
+}
+
+// IsOracleParallelExecuteAvailable DBMS_PARALLEL_EXECUTE ROWID 切分需 package 执行权限以及 CREATE JOB 权限，部分云数据库服务等级受限
+func (o *Oracle) IsOracleParallelExecuteAvailable() (bool, error) {
+	_, res, err := Query(o.Ctx, o.OracleDB, `SELECT
+	(SELECT COUNT(1) FROM ALL_OBJECTS WHERE OBJECT_NAME = 'DBMS_PARALLEL_EXECUTE' AND OBJECT_TYPE = 'PACKAGE') PACKAGE_COUNTS,
+	(SELECT COUNT(1) FROM SESSION_PRIVS WHERE PRIVILEGE IN ('CREATE JOB', 'CREATE ANY JOB')) PRIV_COUNTS
+FROM DUAL`)
+	if err != nil {
+		return false, err
+	}
+	if len(res) == 0 {
+		return false, nil
+	}
+	return res[0]["PACKAGE_COUNTS"] != "0" && res[0]["PRIV_COUNTS"] != "0", nil
+}
@@ -24,7 +24,6 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/thinkeridea/go-extend/exstrings"
 	"github.com/wentaojin/transferdb/common"
-	"hash/crc32"
 	"strconv"
 	"strings"
 	"sync/atomic"
@@ -143,7 +142,9 @@ func (o *Oracle) GetOracleTableActualRows(oraQuery string) (int64, error) {
 	return rowsCount, nil
 }
 
-func (o *Oracle) GetOracleDataRowStrings(querySQL string) ([]string, *strset.Set, uint32, error) {
+// GetOracleDataRowStrings 查询 chunk 数据行，返回字段、行字符串集合以及按 checksumAlgo 累加的行校验和
+// identityCols 大于 0 时查询前 identityCols 列为行标识，不参与行字符串以及校验和，按行字符串返回行标识值
+func (o *Oracle) GetOracleDataRowStrings(querySQL, checksumAlgo string, identityCols int) ([]string, *strset.Set, map[string][]string, uint32, error) {
 	var (
 		cols     []string
 		rowsTMP  []string
@@ -155,10 +156,11 @@ func (o *Oracle) GetOracleDataRowStrings(querySQL string) ([]string, *strset.Set
 	var crc32Value uint32 = 0
 
 	stringSet := set.NewStringSet()
+	identities := make(map[string][]string)
 
 	rows, err = o.OracleDB.Query(querySQL)
 	if err != nil {
-		return cols, stringSet, crc32Value, fmt.Errorf("general sql [%v] query failed: [%v]", querySQL, err.Error())
+		return cols, stringSet, identities, crc32Value, fmt.Errorf("general sql [%v] query failed: [%v]", querySQL, err.Error())
 	}
 
 	defer rows.Close()
@@ -167,7 +169,7 @@ func (o *Oracle) GetOracleDataRowStrings(querySQL string) ([]string, *strset.Set
 	var columnTypes []string
 	colTypes, err := rows.ColumnTypes()
 	if err != nil {
-		return cols, stringSet, crc32Value, err
+		return cols, stringSet, identities, crc32Value, err
 	}
 
 	for _, ct := range colTypes {
@@ -178,7 +180,11 @@ func (o *Oracle) GetOracleDataRowStrings(querySQL string) ([]string, *strset.Set
 	//不确定字段通用查询，自动获取字段名称
 	cols, err = rows.Columns()
 	if err != nil {
-		return cols, stringSet, crc32Value, fmt.Errorf("general sql [%v] query rows.Columns failed: [%v]", querySQL, err.Error())
+		return cols, stringSet, identities, crc32Value, fmt.Errorf("general sql [%v] query rows.Columns failed: [%v]", querySQL, err.Error())
+	}
+
+	if identityCols > len(cols) {
+		return cols, stringSet, identities, crc32Value, fmt.Errorf("general sql [%v] identity columns [%d] over query columns [%d]", querySQL, identityCols, len(cols))
 	}
 
 	rawResult := make([][]byte, len(cols))
@@ -190,7 +196,7 @@ func (o *Oracle) GetOracleDataRowStrings(querySQL string) ([]string, *strset.Set
 	for rows.Next() {
 		err = rows.Scan(scans...)
 		if err != nil {
-			return cols, stringSet, crc32Value, fmt.Errorf("general sql [%v] query rows.Scan failed: [%v]", querySQL, err.Error())
+			return cols, stringSet, identities, crc32Value, fmt.Errorf("general sql [%v] query rows.Scan failed: [%v]", querySQL, err.Error())
 		}
 
 		for i, raw := range rawResult {
@@ -204,48 +210,48 @@ func (o *Oracle) GetOracleDataRowStrings(querySQL string) ([]string, *strset.Set
 				case "int64":
 					r, err := common.StrconvIntBitSize(string(raw), 64)
 					if err != nil {
-						return cols, stringSet, crc32Value, err
+						return cols, stringSet, identities, crc32Value, err
 					}
 					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
 				case "uint64":
 					r, err := common.StrconvUintBitSize(string(raw), 64)
 					if err != nil {
-						return cols, stringSet, crc32Value, err
+						return cols, stringSet, identities, crc32Value, err
 					}
 					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
 				case "float32":
 					r, err := common.StrconvFloatBitSize(string(raw), 32)
 					if err != nil {
-						return cols, stringSet, crc32Value, err
+						return cols, stringSet, identities, crc32Value, err
 					}
 					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
 				case "float64":
 					r, err := common.StrconvFloatBitSize(string(raw), 64)
 					if err != nil {
-						return cols, stringSet, crc32Value, err
+						return cols, stringSet, identities, crc32Value, err
 					}
 					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
 				case "rune":
 					r, err := common.StrconvRune(string(raw))
 					if err != nil {
-						return cols, stringSet, crc32Value, err
+						return cols, stringSet, identities, crc32Value, err
 					}
 					rowsTMP = append(rowsTMP, fmt.Sprintf("%v", r))
 				case "godror.Number":
 					r, err := decimal.NewFromString(string(raw))
 					if err != nil {
-						return cols, stringSet, crc32Value, err
+						return cols, stringSet, identities, crc32Value, err
 					}
 					if r.IsInteger() {
 						si, err := common.StrconvIntBitSize(string(raw), 64)
 						if err != nil {
-							return cols, stringSet, crc32Value, err
+							return cols, stringSet, identities, crc32Value, err
 						}
 						rowsTMP = append(rowsTMP, fmt.Sprintf("%v", si))
 					} else {
 						rf, err := common.StrconvFloatBitSize(string(raw), 64)
 						if err != nil {
-							return cols, stringSet, crc32Value, err
+							return cols, stringSet, identities, crc32Value, err
 						}
 						rowsTMP = append(rowsTMP, fmt.Sprintf("%v", rf))
 					}
@@ -256,19 +262,40 @@ func (o *Oracle) GetOracleDataRowStrings(querySQL string) ([]string, *strset.Set
 			}
 		}
 
-		rowS := exstrings.Join(rowsTMP, ",")
+		rowS := exstrings.Join(rowsTMP[identityCols:], ",")
 
-		// 计算 CRC32
-		crc32SUM = atomic.AddUint32(&crc32Value, crc32.ChecksumIEEE([]byte(rowS)))
+		// 计算行校验和
+		crc32SUM = atomic.AddUint32(&crc32Value, common.RowChecksum(checksumAlgo, []byte(rowS)))
 		stringSet.Add(rowS)
+		if identityCols > 0 {
+			identities[rowS] = append([]string{}, rowsTMP[:identityCols]...)
+		}
 
 		// 数组清空
 		rowsTMP = rowsTMP[0:0]
 	}
 
 	if err = rows.Err(); err != nil {
-		return cols, stringSet, crc32Value, fmt.Errorf("general sql [%v] query rows.Next failed: [%v]", querySQL, err.Error())
+		return cols, stringSet, identities, crc32Value, fmt.Errorf("general sql [%v] query rows.Next failed: [%v]", querySQL, err.Error())
 	}
 
-	return cols, stringSet, crc32SUM, err
+	return cols[identityCols:], stringSet, identities, crc32SUM, err
+}
+
+// GetOracleTableColumnSortSample 抽样字段非空去重值，并按指定语言排序 NLSSORT 输出
+func (o *Oracle) GetOracleTableColumnSortSample(schemaName, tableName, columnName, nlsSort string, sampleRows int) ([]string, error) {
+	var values []string
+	querySQL := fmt.Sprintf(`SELECT V FROM (
+	SELECT V FROM (SELECT DISTINCT "%s" AS V FROM "%s"."%s" WHERE "%s" IS NOT NULL) WHERE ROWNUM <= %d
+) ORDER BY NLSSORT(V, 'NLS_SORT = %s'), V`,
+		columnName, strings.ToUpper(schemaName), strings.ToUpper(tableName), columnName, sampleRows, strings.ToUpper(nlsSort))
+
+	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	if err != nil {
+		return values, err
+	}
+	for _, r := range res {
+		values = append(values, r["V"])
+	}
+	return values, nil
 }
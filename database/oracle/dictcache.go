@@ -0,0 +1,151 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package oracle
+
+import (
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// 数据字典查询结果本地缓存，按查询 SQL 文本索引
+type dictCache struct {
+	mu    sync.RWMutex
+	file  string
+	dirty bool
+	data  dictCacheData
+}
+
+type dictCacheData struct {
+	CreatedAt time.Time                 `json:"created_at"`
+	Entries   map[string]dictCacheEntry `json:"entries"`
+}
+
+type dictCacheEntry struct {
+	Columns []string            `json:"columns"`
+	Rows    []map[string]string `json:"rows"`
+}
+
+// EnableDictionaryCache 开启数据字典缓存，file 为空不开启，缓存文件存在则加载，超过 ttl 仅告警仍继续使用
+// 仅用于 reverse/check/assess 等结构类任务，数据同步类任务不应读取缓存
+func (o *Oracle) EnableDictionaryCache(file, cacheTTL string) error {
+	if file == "" {
+		return nil
+	}
+	var ttl time.Duration
+	if cacheTTL != "" {
+		d, err := time.ParseDuration(cacheTTL)
+		if err != nil {
+			return fmt.Errorf("parse oracle dictionary cache ttl [%s] failed: %v", cacheTTL, err)
+		}
+		ttl = d
+	}
+	c := &dictCache{
+		file: file,
+		data: dictCacheData{
+			CreatedAt: time.Now(),
+			Entries:   make(map[string]dictCacheEntry),
+		},
+	}
+	content, err := os.ReadFile(file)
+	switch {
+	case os.IsNotExist(err):
+		zap.L().Info("oracle dictionary cache file isn't exist, query from database and dump",
+			zap.String("file", file))
+	case err != nil:
+		return fmt.Errorf("read oracle dictionary cache file [%s] failed: %v", file, err)
+	default:
+		if err = json.Unmarshal(content, &c.data); err != nil {
+			return fmt.Errorf("parse oracle dictionary cache file [%s] failed: %v", file, err)
+		}
+		if c.data.Entries == nil {
+			c.data.Entries = make(map[string]dictCacheEntry)
+		}
+		age := time.Since(c.data.CreatedAt)
+		if ttl > 0 && age > ttl {
+			zap.L().Warn("oracle dictionary cache is stale, please remove the file to refresh if the source schema changed",
+				zap.String("file", file),
+				zap.Time("created at", c.data.CreatedAt),
+				zap.String("age", age.String()),
+				zap.String("ttl", ttl.String()))
+		} else {
+			zap.L().Info("oracle dictionary cache loaded",
+				zap.String("file", file),
+				zap.Time("created at", c.data.CreatedAt),
+				zap.Int("entries", len(c.data.Entries)))
+		}
+	}
+	o.dictCache = c
+	return nil
+}
+
+// FlushDictionaryCache 缓存有新增查询结果时写回文件
+func (o *Oracle) FlushDictionaryCache() error {
+	c := o.dictCache
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+	content, err := json.Marshal(c.data)
+	if err != nil {
+		return fmt.Errorf("marshal oracle dictionary cache failed: %v", err)
+	}
+	if err = os.MkdirAll(filepath.Dir(c.file), 0755); err != nil {
+		return fmt.Errorf("create oracle dictionary cache dir failed: %v", err)
+	}
+	// 先写临时文件再重命名，避免中断留下不完整缓存
+	tmpFile := c.file + ".tmp"
+	if err = os.WriteFile(tmpFile, content, 0644); err != nil {
+		return fmt.Errorf("write oracle dictionary cache file [%s] failed: %v", tmpFile, err)
+	}
+	if err = os.Rename(tmpFile, c.file); err != nil {
+		return fmt.Errorf("rename oracle dictionary cache file [%s] failed: %v", c.file, err)
+	}
+	c.dirty = false
+	return nil
+}
+
+// queryDictionary 数据字典查询，开启缓存时优先读取缓存，未命中查询数据库后写入缓存
+func (o *Oracle) queryDictionary(querySQL string) ([]string, []map[string]string, error) {
+	c := o.dictCache
+	if c == nil {
+		return Query(o.Ctx, o.OracleDB, querySQL)
+	}
+	c.mu.RLock()
+	entry, ok := c.data.Entries[querySQL]
+	c.mu.RUnlock()
+	if ok {
+		return entry.Columns, entry.Rows, nil
+	}
+
+	cols, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	if err != nil {
+		return cols, res, err
+	}
+	c.mu.Lock()
+	c.data.Entries[querySQL] = dictCacheEntry{Columns: cols, Rows: res}
+	c.dirty = true
+	c.mu.Unlock()
+	return cols, res, nil
+}
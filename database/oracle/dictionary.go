@@ -0,0 +1,325 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package oracle
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+/*
+	Schema 级数据字典批量查询，reverse 表数量较多时替代逐表查询，结果按 TABLE_NAME 分组
+*/
+
+// GroupByTableName 按 TABLE_NAME 分组
+func GroupByTableName(res []map[string]string) map[string][]map[string]string {
+	groups := make(map[string][]map[string]string)
+	for _, r := range res {
+		groups[r["TABLE_NAME"]] = append(groups[r["TABLE_NAME"]], r)
+	}
+	return groups
+}
+
+func (o *Oracle) GetOracleSchemaPrimaryKey(schemaName string) ([]map[string]string, error) {
+	querySQL := fmt.Sprintf(`select au.table_name,cu.constraint_name,
+       LISTAGG(cu.column_name, ',') WITHIN GROUP(ORDER BY cu.POSITION) AS COLUMN_LIST
+  from dba_cons_columns cu, dba_constraints au
+ where cu.constraint_name = au.constraint_name
+   and au.constraint_type = 'P'
+   and au.STATUS = 'ENABLED'
+   and cu.owner = au.owner
+   and cu.table_name = au.table_name
+   and upper(cu.owner) = upper('%s')
+ group by au.table_name,cu.constraint_name`, strings.ToUpper(schemaName))
+	_, res, err := o.queryDictionary(querySQL)
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (o *Oracle) GetOracleSchemaUniqueKey(schemaName string) ([]map[string]string, error) {
+	querySQL := fmt.Sprintf(`select au.table_name,cu.constraint_name,au.index_name,
+       LISTAGG(cu.column_name, ',') WITHIN GROUP(ORDER BY cu.POSITION) AS column_list
+  from dba_cons_columns cu, dba_constraints au
+ where cu.constraint_name = au.constraint_name
+   and cu.owner = au.owner
+   and cu.table_name = au.table_name
+   and au.constraint_type = 'U'
+   and au.STATUS = 'ENABLED'
+   and upper(cu.owner) = upper('%s')
+ group by au.table_name,cu.constraint_name,au.index_name`, strings.ToUpper(schemaName))
+	_, res, err := o.queryDictionary(querySQL)
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (o *Oracle) GetOracleSchemaForeignKey(schemaName string) ([]map[string]string, error) {
+	querySQL := fmt.Sprintf(`with temp1 as
+ (select
+         t1.OWNER,
+         t1.TABLE_NAME,
+         t1.r_owner,
+         t1.constraint_name,
+         t1.r_constraint_name,
+         t1.DELETE_RULE,
+         LISTAGG(a1.column_name, ',') WITHIN GROUP(ORDER BY a1.POSITION) AS COLUMN_LIST
+    from dba_constraints t1, dba_cons_columns a1
+   where t1.constraint_name = a1.constraint_name
+     AND t1.owner = a1.owner
+     AND upper(t1.owner) = upper('%s')
+     AND t1.STATUS = 'ENABLED'
+     AND t1.Constraint_Type = 'R'
+   group by t1.OWNER, t1.TABLE_NAME, t1.r_owner, t1.constraint_name, t1.r_constraint_name,t1.DELETE_RULE),
+temp2 as
+ (select t1.owner,
+         t1.TABLE_NAME,
+         t1.constraint_name,
+         LISTAGG(a1.column_name, ',') WITHIN GROUP(ORDER BY a1.POSITION) AS COLUMN_LIST
+    from dba_constraints t1, dba_cons_columns a1
+   where t1.constraint_name = a1.constraint_name
+     AND upper(t1.owner) = upper('%s')
+     AND t1.STATUS = 'ENABLED'
+     AND t1.Constraint_Type = 'P'
+   group by t1.owner,t1.TABLE_NAME, t1.r_owner, t1.constraint_name)
+select x.TABLE_NAME,
+       x.constraint_name,
+       x.COLUMN_LIST,
+       x.r_owner,
+       y.TABLE_NAME as RTABLE_NAME,
+       y.COLUMN_LIST as RCOLUMN_LIST,
+       x.DELETE_RULE
+  from temp1 x, temp2 y
+ where x.r_owner = y.owner
+   and x.r_constraint_name = y.constraint_name`,
+		strings.ToUpper(schemaName),
+		strings.ToUpper(schemaName))
+	_, res, err := o.queryDictionary(querySQL)
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (o *Oracle) GetOracleSchemaCheckKey(schemaName string) ([]map[string]string, error) {
+	querySQL := fmt.Sprintf(`select au.table_name,cu.constraint_name,SEARCH_CONDITION
+          from dba_cons_columns cu, dba_constraints au
+         where cu.owner=au.owner
+           and cu.table_name=au.table_name
+           and cu.constraint_name = au.constraint_name
+           and au.constraint_type = 'C'
+           and au.STATUS = 'ENABLED'
+           and upper(au.owner) = upper('%s')`, strings.ToUpper(schemaName))
+	_, res, err := o.queryDictionary(querySQL)
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// GetOracleSchemaIndex 唯一索引 uniqueness = UNIQUE，普通索引 uniqueness = NONUNIQUE，排除约束索引
+func (o *Oracle) GetOracleSchemaIndex(schemaName string, uniqueness string) ([]map[string]string, error) {
+	// 唯一索引仅排除主键、唯一约束索引，普通索引排除全部约束索引
+	consFilter := ""
+	if strings.EqualFold(uniqueness, "UNIQUE") {
+		consFilter = "AND C.CONSTRAINT_TYPE IN ('P','U')"
+	}
+	querySQL := fmt.Sprintf(`SELECT
+	temp.TABLE_NAME,
+	temp.UNIQUENESS,
+	temp.INDEX_NAME,
+	temp.INDEX_TYPE,
+	temp.ITYP_OWNER,
+	temp.ITYP_NAME,
+	temp.PARAMETERS,
+	LISTAGG ( temp.COLUMN_NAME, ',' ) WITHIN GROUP ( ORDER BY temp.COLUMN_POSITION ) AS COLUMN_LIST 
+FROM
+	(
+SELECT
+	I.TABLE_OWNER,
+	I.TABLE_NAME,
+	I.UNIQUENESS,
+	I.INDEX_NAME,
+	I.INDEX_TYPE,
+	NVL(I.ITYP_OWNER,'') ITYP_OWNER,
+	NVL(I.ITYP_NAME,'') ITYP_NAME,
+	NVL(I.PARAMETERS,'') PARAMETERS,
+	NVL(E.COLUMN_EXPRESSION, T.COLUMN_NAME) COLUMN_NAME,
+	T.COLUMN_POSITION
+FROM
+	DBA_INDEXES I,
+	DBA_IND_COLUMNS T,
+	(SELECT
+		xs.INDEX_OWNER,
+		xs.INDEX_NAME,
+		xs.COLUMN_POSITION,
+		xs.COLUMN_EXPRESSION
+	FROM
+		XMLTABLE (
+			'/ROWSET/ROW' PASSING ( SELECT DBMS_XMLGEN.GETXMLTYPE ( 
+				q'[SELECT
+	S.INDEX_OWNER,
+	S.INDEX_NAME,
+	S.COLUMN_EXPRESSION,
+	S.COLUMN_POSITION
+FROM
+	DBA_IND_EXPRESSIONS S
+WHERE
+	S.TABLE_OWNER = '%s']' ) FROM DUAL ) COLUMNS 
+	INDEX_OWNER VARCHAR2 ( 30 ) PATH 'INDEX_OWNER',
+	INDEX_NAME VARCHAR2 ( 30 ) PATH 'INDEX_NAME',
+	COLUMN_POSITION VARCHAR2 ( 30 ) PATH 'COLUMN_POSITION',
+	COLUMN_EXPRESSION VARCHAR2 ( 4000 ) 
+	) xs) E
+WHERE
+	I.INDEX_NAME = T.INDEX_NAME 
+	AND I.TABLE_OWNER = T.TABLE_OWNER 
+	AND I.TABLE_NAME = T.TABLE_NAME 
+	AND T.INDEX_OWNER = E.INDEX_OWNER (+)
+	AND T.INDEX_NAME = E.INDEX_NAME (+)
+	AND TO_CHAR(T.COLUMN_POSITION) = E.COLUMN_POSITION (+)
+	AND I.UNIQUENESS = '%s'
+	AND I.TABLE_OWNER = '%s' 
+	AND NOT EXISTS (
+	SELECT
+		1 
+	FROM
+		DBA_CONSTRAINTS C 
+	WHERE
+		I.INDEX_NAME = C.INDEX_NAME 
+		AND I.TABLE_OWNER = C.OWNER 
+		AND I.TABLE_NAME = C.TABLE_NAME 
+		%s
+	)) temp
+		GROUP BY
+		temp.TABLE_OWNER,
+		temp.TABLE_NAME,
+		temp.UNIQUENESS,
+		temp.INDEX_NAME,
+		temp.INDEX_TYPE,
+		temp.ITYP_OWNER,
+		temp.ITYP_NAME,
+		temp.PARAMETERS`,
+		strings.ToUpper(schemaName),
+		strings.ToUpper(uniqueness),
+		strings.ToUpper(schemaName),
+		consFilter)
+	_, res, err := o.queryDictionary(querySQL)
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (o *Oracle) GetOracleSchemaComment(schemaName string) ([]map[string]string, error) {
+	querySQL := fmt.Sprintf(`select table_name,table_type,comments 
+from dba_tab_comments 
+where 
+table_type = 'TABLE'
+and upper(owner)=upper('%s')`, strings.ToUpper(schemaName))
+	_, res, err := o.queryDictionary(querySQL)
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// GetOracleSchemaColumn 字段信息，检查约束 IS NOT NULL 同逐表查询一样折算为 NULLABLE = N
+func (o *Oracle) GetOracleSchemaColumn(schemaName string, oraCollation bool) ([]map[string]string, error) {
+	collationCol := ""
+	if oraCollation {
+		collationCol = "DECODE(t.COLLATION,'USING_NLS_COMP',(SELECT VALUE from NLS_DATABASE_PARAMETERS WHERE PARAMETER = 'NLS_COMP'),t.COLLATION) COLLATION,"
+	}
+	querySQL := fmt.Sprintf(`select t.TABLE_NAME,
+	    t.COLUMN_NAME,
+	    t.DATA_TYPE,
+		 t.CHAR_LENGTH,
+		 NVL(t.CHAR_USED,DECODE(NVL(t.CHAR_LENGTH,0),0,'UNKNOWN',(SELECT DECODE(VALUE,'CHAR','C','B') from NLS_DATABASE_PARAMETERS WHERE PARAMETER = 'NLS_LENGTH_SEMANTICS'))) CHAR_USED,
+	    NVL(t.DATA_LENGTH,0) AS DATA_LENGTH,
+	    DECODE(NVL(TO_CHAR(t.DATA_PRECISION),'*'),'*','38',TO_CHAR(t.DATA_PRECISION)) AS DATA_PRECISION,
+	    DECODE(NVL(TO_CHAR(t.DATA_SCALE),'*'),'*','127',TO_CHAR(t.DATA_SCALE)) AS DATA_SCALE,
+		t.NULLABLE,
+	    t.DATA_DEFAULT,
+		%s
+	    c.COMMENTS
+	from dba_tab_columns t, dba_col_comments c
+	where t.table_name = c.table_name
+	and t.column_name = c.column_name
+	and t.owner = c.owner
+	and upper(t.owner) = upper('%s')
+	order by t.TABLE_NAME,t.COLUMN_ID`, collationCol, strings.ToUpper(schemaName))
+
+	_, queryRes, err := o.queryDictionary(querySQL)
+	if err != nil {
+		return queryRes, err
+	}
+
+	_, condRes, err := o.queryDictionary(fmt.Sprintf(`SELECT
+				col.TABLE_NAME,
+				col.COLUMN_NAME,
+				cons.SEARCH_CONDITION
+				FROM
+				DBA_CONS_COLUMNS col,
+				DBA_CONSTRAINTS cons
+				WHERE
+				col.OWNER = cons.OWNER
+				AND col.TABLE_NAME = cons.TABLE_NAME
+				AND col.CONSTRAINT_NAME = cons.CONSTRAINT_NAME
+				AND cons.CONSTRAINT_TYPE = 'C'
+				AND upper(col.OWNER) = '%s'`, strings.ToUpper(schemaName)))
+	if err != nil {
+		return queryRes, err
+	}
+
+	rep, err := regexp.Compile(`(^.*)(?i:IS NOT NULL)`)
+	if err != nil {
+		return queryRes, fmt.Errorf("check notnull constraint regexp complile failed: %v", err)
+	}
+	notNullCols := make(map[string]struct{})
+	for _, c := range condRes {
+		if rep.MatchString(c["SEARCH_CONDITION"]) {
+			notNullCols[c["TABLE_NAME"]+"."+c["COLUMN_NAME"]] = struct{}{}
+		}
+	}
+	for _, r := range queryRes {
+		if r["NULLABLE"] == "Y" {
+			if _, ok := notNullCols[r["TABLE_NAME"]+"."+r["COLUMN_NAME"]]; ok {
+				r["NULLABLE"] = "N"
+			}
+		}
+	}
+	return queryRes, nil
+}
+
+func (o *Oracle) GetOracleSchemaColumnComment(schemaName string) ([]map[string]string, error) {
+	querySQL := fmt.Sprintf(`select t.TABLE_NAME,
+	    t.COLUMN_NAME,
+	    c.COMMENTS
+	from dba_tab_columns t, dba_col_comments c
+	where t.table_name = c.table_name
+	and t.column_name = c.column_name
+	and t.owner = c.owner
+	and upper(t.owner) = upper('%s')
+	order by t.TABLE_NAME,t.COLUMN_ID`, strings.ToUpper(schemaName))
+	_, queryRes, err := o.queryDictionary(querySQL)
+	if err != nil {
+		return queryRes, err
+	}
+	return queryRes, nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package oracle
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+)
+
+// editionSupported 判断数据库是否支持 EBR，11.2 以下不存在 DBA_EDITIONING_VIEWS 等字典
+func (o *Oracle) editionSupported() (bool, error) {
+	version, err := o.GetOracleDBVersion()
+	if err != nil {
+		return false, err
+	}
+	return common.VersionOrdinal(version) >= common.VersionOrdinal(common.OracleEditionBasedRedefinitionDBVersion), nil
+}
+
+// GetOracleSchemaEditioningView 获取编辑视图以及对应基表，返回 VIEW_NAME -> TABLE_NAME
+func (o *Oracle) GetOracleSchemaEditioningView(schemaName string) (map[string]string, error) {
+	views := make(map[string]string)
+
+	supported, err := o.editionSupported()
+	if err != nil {
+		return views, err
+	}
+	if !supported {
+		return views, nil
+	}
+
+	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`select view_name AS VIEW_NAME,
+       table_name AS TABLE_NAME
+  from dba_editioning_views
+ where upper(owner) = upper('%s')`, schemaName))
+	if err != nil {
+		return views, err
+	}
+
+	for _, r := range res {
+		views[r["VIEW_NAME"]] = r["TABLE_NAME"]
+	}
+	return views, nil
+}
+
+// GetOracleSchemaCrosseditionTrigger 获取跨版本触发器，CROSSEDITION 取值 FORWARD / REVERSE
+func (o *Oracle) GetOracleSchemaCrosseditionTrigger(schemaName string) ([]map[string]string, error) {
+	supported, err := o.editionSupported()
+	if err != nil {
+		return nil, err
+	}
+	if !supported {
+		return nil, nil
+	}
+
+	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`select trigger_name AS TRIGGER_NAME,
+       table_name AS TABLE_NAME,
+       crossedition AS CROSSEDITION
+  from dba_triggers
+ where crossedition <> 'NO'
+   and upper(owner) = upper('%s')`, schemaName))
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// GetOracleSchemaEditionedObject 获取非当前版本可见的编辑对象（视图、同义词、存储过程等），以 OBJECT_TYPE 分组
+func (o *Oracle) GetOracleSchemaEditionedObject(schemaName string) (map[string][]string, error) {
+	objects := make(map[string][]string)
+
+	supported, err := o.editionSupported()
+	if err != nil {
+		return objects, err
+	}
+	if !supported {
+		return objects, nil
+	}
+
+	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`select object_type AS OBJECT_TYPE,
+       object_name AS OBJECT_NAME,
+       edition_name AS EDITION_NAME
+  from dba_objects_ae
+ where edition_name IS NOT NULL
+   and edition_name <> sys_context('USERENV', 'CURRENT_EDITION_NAME')
+   and upper(owner) = upper('%s')`, schemaName))
+	if err != nil {
+		return objects, err
+	}
+
+	for _, r := range res {
+		objects[r["OBJECT_TYPE"]] = append(objects[r["OBJECT_TYPE"]], common.StringsBuilder(r["OBJECT_NAME"], "@", r["EDITION_NAME"]))
+	}
+	return objects, nil
+}
+
+// FilterOracleEditioningViewBaseTable 将命中过滤规则的编辑视图解析为对应基表
+func (o *Oracle) FilterOracleEditioningViewBaseTable(schemaName string, match func(string) bool) ([]string, error) {
+	views, err := o.GetOracleSchemaEditioningView(schemaName)
+	if err != nil {
+		return nil, err
+	}
+
+	var baseTables []string
+	for view, table := range views {
+		if match(view) && !common.IsContainString(baseTables, table) {
+			baseTables = append(baseTables, table)
+		}
+	}
+	return baseTables, nil
+}
@@ -17,10 +17,11 @@ package oracle
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
-	"github.com/shopspring/decimal"
-	"github.com/thinkeridea/go-extend/exstrings"
 	"github.com/wentaojin/transferdb/common"
+	"strconv"
+	"strings"
 )
 
 func (o *Oracle) GetOracleCurrentSnapshotSCN() (uint64, error) {
@@ -87,6 +88,99 @@ func (o *Oracle) GetOracleTableChunksByRowID(taskName string) ([]map[string]stri
 	return res, nil
 }
 
+// GetOracleTableNumberPKColumn 单列 NUMBER 主键字段名，不存在返回空
+func (o *Oracle) GetOracleTableNumberPKColumn(schemaName, tableName string) (string, error) {
+	querySQL := fmt.Sprintf(`SELECT MAX(cu.COLUMN_NAME) COLUMN_NAME, COUNT(1) COLUMN_COUNTS, MAX(tc.DATA_TYPE) DATA_TYPE
+  FROM dba_cons_columns cu, dba_constraints au, dba_tab_columns tc
+ WHERE cu.constraint_name = au.constraint_name
+   AND cu.owner = au.owner
+   AND cu.table_name = au.table_name
+   AND tc.owner = cu.owner
+   AND tc.table_name = cu.table_name
+   AND tc.column_name = cu.column_name
+   AND au.constraint_type = 'P'
+   AND au.STATUS = 'ENABLED'
+   AND au.owner = '%s'
+   AND au.table_name = '%s'`, schemaName, tableName)
+	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	if err != nil {
+		return "", err
+	}
+	if len(res) == 0 || res[0]["COLUMN_COUNTS"] != "1" || !strings.EqualFold(res[0]["DATA_TYPE"], "NUMBER") {
+		return "", nil
+	}
+	return res[0]["COLUMN_NAME"], nil
+}
+
+// GetOracleSchemaTableSegmentBytes 表数据（含 LOB）以及索引段大小，按表汇总
+func (o *Oracle) GetOracleSchemaTableSegmentBytes(schemaName string) ([]map[string]string, error) {
+	querySQL := fmt.Sprintf(`SELECT s.SEGMENT_NAME TABLE_NAME, 'TABLE' SEGMENT_CATEGORY, SUM(s.BYTES) BYTES
+  FROM DBA_SEGMENTS s
+ WHERE s.OWNER = '%[1]s'
+   AND s.SEGMENT_TYPE IN ('TABLE', 'TABLE PARTITION', 'TABLE SUBPARTITION')
+ GROUP BY s.SEGMENT_NAME
+UNION ALL
+SELECT l.TABLE_NAME, 'TABLE' SEGMENT_CATEGORY, SUM(s.BYTES) BYTES
+  FROM DBA_LOBS l, DBA_SEGMENTS s
+ WHERE s.OWNER = l.OWNER
+   AND s.SEGMENT_NAME = l.SEGMENT_NAME
+   AND l.OWNER = '%[1]s'
+ GROUP BY l.TABLE_NAME
+UNION ALL
+SELECT i.TABLE_NAME, 'INDEX' SEGMENT_CATEGORY, SUM(s.BYTES) BYTES
+  FROM DBA_INDEXES i, DBA_SEGMENTS s
+ WHERE s.OWNER = i.OWNER
+   AND s.SEGMENT_NAME = i.INDEX_NAME
+   AND i.TABLE_OWNER = '%[1]s'
+   AND i.INDEX_TYPE <> 'LOB'
+   AND s.SEGMENT_TYPE IN ('INDEX', 'INDEX PARTITION', 'INDEX SUBPARTITION')
+ GROUP BY i.TABLE_NAME`, schemaName)
+	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// GetOracleTablePartitionClause 表分区扩展子句，存在子分区的分区按子分区展开
+func (o *Oracle) GetOracleTablePartitionClause(schemaName, tableName string) ([]string, error) {
+	querySQL := fmt.Sprintf(`SELECT 'PARTITION ("' || PARTITION_NAME || '")' PARTITION_CLAUSE, PARTITION_POSITION, 0 SUBPARTITION_POSITION
+  FROM DBA_TAB_PARTITIONS
+ WHERE TABLE_OWNER = '%[1]s'
+   AND TABLE_NAME = '%[2]s'
+   AND SUBPARTITION_COUNT = 0
+UNION ALL
+SELECT 'SUBPARTITION ("' || s.SUBPARTITION_NAME || '")' PARTITION_CLAUSE, p.PARTITION_POSITION, s.SUBPARTITION_POSITION
+  FROM DBA_TAB_SUBPARTITIONS s, DBA_TAB_PARTITIONS p
+ WHERE s.TABLE_OWNER = p.TABLE_OWNER
+   AND s.TABLE_NAME = p.TABLE_NAME
+   AND s.PARTITION_NAME = p.PARTITION_NAME
+   AND s.TABLE_OWNER = '%[1]s'
+   AND s.TABLE_NAME = '%[2]s'
+ ORDER BY 2, 3`, schemaName, tableName)
+	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	if err != nil {
+		return nil, err
+	}
+	var partitions []string
+	for _, r := range res {
+		partitions = append(partitions, r["PARTITION_CLAUSE"])
+	}
+	return partitions, nil
+}
+
+// GetOracleTableChunksByNTILE 按 NUMBER 主键 NTILE 均分 chunkNums 个区间，无需 DBMS_PARALLEL_EXECUTE 权限
+func (o *Oracle) GetOracleTableChunksByNTILE(tableFrom, columnName string, chunkNums int) ([]map[string]string, error) {
+	querySQL := common.StringsBuilder(`SELECT '`, columnName, ` BETWEEN ' || MIN(`, columnName, `) || ' AND ' || MAX(`, columnName, `) CMD
+  FROM (SELECT `, columnName, `, NTILE(`, strconv.Itoa(chunkNums), `) OVER (ORDER BY `, columnName, `) NT FROM `, tableFrom, `)
+ GROUP BY NT ORDER BY NT`)
+	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
 func (o *Oracle) CloseOracleChunkTask(taskName string) error {
 	ctx, _ := context.WithCancel(context.Background())
 
@@ -102,150 +196,308 @@ END;`)
 	return nil
 }
 
-// 获取表字段名以及行数据 -> 用于 FULL/ALL
-func (o *Oracle) GetOracleTableRowsData(querySQL string, insertBatchSize int) ([]string, []string, error) {
+// 获取表字段名以及类型化行数据 -> 用于 ALL 物化视图日志回查等小结果集
+func (o *Oracle) GetOracleTableRowsData(querySQL string, insertBatchSize int) ([]string, [][]common.RowValue, error) {
 	var (
-		err          error
-		rowsResult   []string
-		rowsTMP      []string
-		batchResults []string
 		cols         []string
+		batchResults [][]common.RowValue
 	)
-	rows, err := o.OracleDB.QueryContext(o.Ctx, querySQL)
+	err := o.streamOracleTableRowsData(o.Ctx, o.OracleDB, querySQL, insertBatchSize, nil, func(columns []string, rows []common.RowValue) error {
+		cols = columns
+		batchResults = append(batchResults, rows)
+		return nil
+	})
+	if err != nil {
+		return cols, batchResults, err
+	}
+	return cols, batchResults, nil
+}
+
+// StreamOracleTableRowsData 游标逐行读取，每满 batch 回调一次 -> 用于 FULL 流式抽取
+// 回调返回后批次不再被复用，字符值所在 arena 随批次重新分配，内存占用只与在途批次数相关
+// 配置物理备库时走备库抽取
+func (o *Oracle) StreamOracleTableRowsData(ctx context.Context, querySQL string, insertBatchSize int, fn func(columns []string, rows []common.RowValue) error) error {
+	return o.streamOracleTableRowsData(ctx, o.ExtractDB(), querySQL, insertBatchSize, nil, fn)
+}
+
+// StreamOracleTableRowsDataByBytes 同 StreamOracleTableRowsData，batch 按 batcher 目标字节数切分
+func (o *Oracle) StreamOracleTableRowsDataByBytes(ctx context.Context, querySQL string, insertBatchSize int, batcher *common.AdaptiveBatcher, fn func(columns []string, rows []common.RowValue) error) error {
+	return o.streamOracleTableRowsData(ctx, o.ExtractDB(), querySQL, insertBatchSize, batcher, fn)
+}
+
+func (o *Oracle) streamOracleTableRowsData(ctx context.Context, db *sql.DB, querySQL string, insertBatchSize int, batcher *common.AdaptiveBatcher, fn func(columns []string, rows []common.RowValue) error) error {
+	rows, err := db.QueryContext(ctx, querySQL)
 	if err != nil {
-		return []string{}, batchResults, err
+		return err
 	}
 	defer rows.Close()
 
 	tmpCols, err := rows.Columns()
 	if err != nil {
-		return cols, batchResults, err
+		return err
 	}
 
 	// 字段名关键字反引号处理
+	var cols []string
 	for _, col := range tmpCols {
 		cols = append(cols, common.StringsBuilder("`", col, "`"))
 	}
 
 	// 用于判断字段值是数字还是字符
-	var (
-		columnTypes   []string
-		databaseTypes []string
-	)
+	var columnTypes []string
 	colTypes, err := rows.ColumnTypes()
 	if err != nil {
-		return cols, batchResults, err
+		return err
 	}
 
-	for _, ct := range colTypes {
+	// 字符字段字符集转换
+	convertColumns := make([]bool, len(colTypes))
+	for i, ct := range colTypes {
 		// 数据库字段类型 DatabaseTypeName() 映射 go 类型 ScanType()
 		columnTypes = append(columnTypes, ct.ScanType().String())
-		databaseTypes = append(databaseTypes, ct.DatabaseTypeName())
+		convertColumns[i] = o.charsetConverter != nil && common.IsCharsetConvertColumn(ct.DatabaseTypeName())
 	}
 
 	// 数据 Scan
+	// scan 目标、行暂存跨批次复用，字符值拷贝至 arena，同批次行共用连续内存
 	columns := len(cols)
 	rawResult := make([][]byte, columns)
 	dest := make([]interface{}, columns)
 	for i := range rawResult {
 		dest[i] = &rawResult[i]
 	}
+	rowValue := make([]interface{}, columns)
+	arena := common.NewByteArena(0)
+	rowBatch := common.NewRowBatch(columns, insertBatchSize)
 
 	// 表行数读取
 	for rows.Next() {
 		err = rows.Scan(dest...)
 		if err != nil {
-			return cols, batchResults, err
+			return err
 		}
 
 		for i, raw := range rawResult {
-			// 注意 Oracle/Mysql NULL VS 空字符串区别
-			// Oracle 空字符串与 NULL 归于一类，统一 NULL 处理 （is null 可以查询 NULL 以及空字符串值，空字符串查询无法查询到空字符串值）
-			// Mysql 空字符串与 NULL 非一类，NULL 是 NULL，空字符串是空字符串（is null 只查询 NULL 值，空字符串查询只查询到空字符串值）
-			// 按照 Oracle 特性来，转换同步统一转换成 NULL 即可，但需要注意业务逻辑中空字符串得写入，需要变更
-			// Oracle/Mysql 对于 'NULL' 统一字符 NULL 处理，查询出来转成 NULL,所以需要判断处理
-			if raw == nil {
-				rowsResult = append(rowsResult, fmt.Sprintf("%v", `NULL`))
-			} else if string(raw) == "" {
-				rowsResult = append(rowsResult, fmt.Sprintf("%v", `NULL`))
-			} else {
-				switch columnTypes[i] {
-				case "int64":
-					r, err := common.StrconvIntBitSize(string(raw), 64)
-					if err != nil {
-						return cols, batchResults, err
-					}
-					rowsResult = append(rowsResult, fmt.Sprintf("%v", r))
-				case "uint64":
-					r, err := common.StrconvUintBitSize(string(raw), 64)
-					if err != nil {
-						return cols, batchResults, err
-					}
-					rowsResult = append(rowsResult, fmt.Sprintf("%v", r))
-				case "float32":
-					r, err := common.StrconvFloatBitSize(string(raw), 32)
-					if err != nil {
-						return cols, batchResults, err
-					}
-					rowsResult = append(rowsResult, fmt.Sprintf("%v", r))
-				case "float64":
-					r, err := common.StrconvFloatBitSize(string(raw), 64)
-					if err != nil {
-						return cols, batchResults, err
-					}
-					rowsResult = append(rowsResult, fmt.Sprintf("%v", r))
-				case "rune":
-					r, err := common.StrconvRune(string(raw))
-					if err != nil {
-						return cols, batchResults, err
-					}
-					rowsResult = append(rowsResult, fmt.Sprintf("%v", r))
-				case "godror.Number":
-					r, err := decimal.NewFromString(string(raw))
-					if err != nil {
-						return cols, rowsResult, err
-					}
-					if r.IsInteger() {
-						si, err := common.StrconvIntBitSize(string(raw), 64)
-						if err != nil {
-							return cols, rowsResult, err
-						}
-						rowsResult = append(rowsResult, fmt.Sprintf("%v", si))
-					} else {
-						rf, err := common.StrconvFloatBitSize(string(raw), 64)
-						if err != nil {
-							return cols, rowsResult, err
-						}
-						rowsResult = append(rowsResult, fmt.Sprintf("%v", rf))
-					}
-				default:
-					// 特殊字符
-					rowsResult = append(rowsResult, fmt.Sprintf("'%v'", common.SpecialLettersUsingMySQL(raw)))
+			if convertColumns[i] {
+				if raw, err = o.charsetConverter.Convert(raw); err != nil {
+					return err
 				}
 			}
+			rowValue[i], err = common.ParseRowValue(raw, columnTypes[i], arena)
+			if err != nil {
+				return err
+			}
 		}
-
-		rowsTMP = append(rowsTMP, common.StringsBuilder("(", exstrings.Join(rowsResult, ","), ")"))
-
-		// 数组清空
-		rowsResult = rowsResult[0:0]
+		rowBatch.Append(rowValue)
 
 		// batch 批次
-		if len(rowsTMP) == insertBatchSize {
-			batchResults = append(batchResults, exstrings.Join(rowsTMP, ","))
-			// 数组清空
-			rowsTMP = rowsTMP[0:0]
+		batchFull := rowBatch.Len() == insertBatchSize
+		if batcher != nil {
+			batchFull = batcher.Add(rowValue)
+		}
+		if batchFull {
+			if err = fn(cols, rowBatch.Rows()); err != nil {
+				return err
+			}
+			arena = common.NewByteArena(0)
+			if batcher != nil {
+				batcher.Reset()
+			}
 		}
 	}
 
 	if err = rows.Err(); err != nil {
-		return cols, batchResults, err
+		return err
 	}
 
 	// 非 batch 批次
-	if len(rowsTMP) > 0 {
-		batchResults = append(batchResults, exstrings.Join(rowsTMP, ","))
+	if rowBatch.Len() > 0 {
+		if err = fn(cols, rowBatch.Rows()); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	return cols, batchResults, nil
+// 获取表 chunk 抽样数据 -> 用于 FULL 抽样校验
+func (o *Oracle) GetOracleTableChunkSampleRows(tableFrom, columnDetail, chunkDetail string, sampleRows int) ([]string, []map[string]string, error) {
+	querySQL := common.StringsBuilder(`SELECT `, columnDetail, ` FROM `, tableFrom, ` WHERE `, chunkDetail, ` AND ROWNUM <= `, strconv.Itoa(sampleRows))
+	cols, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	if err != nil {
+		return cols, res, err
+	}
+	return cols, res, nil
+}
+
+// GetOracleTableChunkReadBytes 读取数据只统计行数以及字节数 -> 用于 BENCH
+func (o *Oracle) GetOracleTableChunkReadBytes(querySQL string) (int64, int64, error) {
+	var (
+		rowCounts int64
+		byteSize  int64
+	)
+	rows, err := o.OracleDB.QueryContext(o.Ctx, querySQL)
+	if err != nil {
+		return rowCounts, byteSize, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return rowCounts, byteSize, err
+	}
+
+	rawResult := make([]sql.RawBytes, len(cols))
+	dest := make([]interface{}, len(cols))
+	for i := range rawResult {
+		dest[i] = &rawResult[i]
+	}
+
+	for rows.Next() {
+		if err = rows.Scan(dest...); err != nil {
+			return rowCounts, byteSize, err
+		}
+		for _, raw := range rawResult {
+			byteSize += int64(len(raw))
+		}
+		rowCounts++
+	}
+	if err = rows.Err(); err != nil {
+		return rowCounts, byteSize, err
+	}
+	return rowCounts, byteSize, nil
+}
+
+// GetOracleTableAvgRowBytes 获取表平均行字节数 -> 用于 chunk 行数校准
+// 优先统计信息 AVG_ROW_LEN，统计信息缺失则抽样读取计算
+func (o *Oracle) GetOracleTableAvgRowBytes(schemaName, tableName string, sampleRows int) (int, error) {
+	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`select NVL(AVG_ROW_LEN,0) AS AVG_ROW_LEN
+  from dba_tables
+ where upper(OWNER) = upper('%s')
+   and upper(table_name) = upper('%s')`, schemaName, tableName))
+	if err != nil {
+		return 0, err
+	}
+	if len(res) != 1 {
+		return 0, fmt.Errorf("get oracle schema table [%s.%s] avg row len by statistics falied, results: [%v]", schemaName, tableName, res)
+	}
+	avgRowLen, err := strconv.Atoi(res[0]["AVG_ROW_LEN"])
+	if err != nil {
+		return 0, fmt.Errorf("get oracle schema table [%s.%s] avg row len [%s] strconv.Atoi falied: %v", schemaName, tableName, res[0]["AVG_ROW_LEN"], err)
+	}
+	if avgRowLen > 0 {
+		return avgRowLen, nil
+	}
+
+	rowCounts, byteSize, err := o.GetOracleTableChunkReadBytes(common.StringsBuilder(`SELECT * FROM `, schemaName, `.`, tableName, ` SAMPLE BLOCK (1) WHERE ROWNUM <= `, strconv.Itoa(sampleRows)))
+	if err != nil {
+		return 0, err
+	}
+	// 小表块抽样可能无数据，直接读取前 N 行
+	if rowCounts == 0 {
+		rowCounts, byteSize, err = o.GetOracleTableChunkReadBytes(common.StringsBuilder(`SELECT * FROM `, schemaName, `.`, tableName, ` WHERE ROWNUM <= `, strconv.Itoa(sampleRows)))
+		if err != nil {
+			return 0, err
+		}
+	}
+	if rowCounts == 0 {
+		return 0, nil
+	}
+	return int(byteSize / rowCounts), nil
+}
+
+// GetOracleTableLOBMaxBytes 抽样获取表 LOB 字段最大长度，不存在 LOB 字段返回 0
+// CLOB/NCLOB 按字符数，BLOB 按字节数
+func (o *Oracle) GetOracleTableLOBMaxBytes(schemaName, tableName string, sampleRows int) (int, error) {
+	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`select COLUMN_NAME
+  from dba_tab_columns
+ where upper(OWNER) = upper('%s')
+   and upper(table_name) = upper('%s')
+   and DATA_TYPE IN ('CLOB', 'NCLOB', 'BLOB')`, schemaName, tableName))
+	if err != nil {
+		return 0, err
+	}
+	if len(res) == 0 {
+		return 0, nil
+	}
+
+	var lobCols []string
+	for _, r := range res {
+		lobCols = append(lobCols, common.StringsBuilder(`NVL(DBMS_LOB.GETLENGTH("`, r["COLUMN_NAME"], `"),0)`))
+	}
+	_, res, err = Query(o.Ctx, o.OracleDB, common.StringsBuilder(`SELECT NVL(MAX(GREATEST(`, strings.Join(append(lobCols, "0"), ","), `)),0) AS LOB_BYTES FROM `,
+		schemaName, `.`, tableName, ` WHERE ROWNUM <= `, strconv.Itoa(sampleRows)))
+	if err != nil {
+		return 0, err
+	}
+	lobBytes, err := strconv.Atoi(res[0]["LOB_BYTES"])
+	if err != nil {
+		return 0, fmt.Errorf("get oracle schema table [%s.%s] lob bytes [%s] strconv.Atoi falied: %v", schemaName, tableName, res[0]["LOB_BYTES"], err)
+	}
+	return lobBytes, nil
+}
+
+// 获取 schema 内外键关联表对 -> 用于一致性快照组划分
+func (o *Oracle) GetOracleSchemaForeignKeyTablePairs(schemaName string) ([]map[string]string, error) {
+	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`SELECT DISTINCT
+       C.TABLE_NAME,
+       R.TABLE_NAME AS RTABLE_NAME
+  FROM DBA_CONSTRAINTS C, DBA_CONSTRAINTS R
+ WHERE C.R_OWNER = R.OWNER
+   AND C.R_CONSTRAINT_NAME = R.CONSTRAINT_NAME
+   AND C.CONSTRAINT_TYPE = 'R'
+   AND C.STATUS = 'ENABLED'
+   AND C.OWNER = '%s'
+   AND R.OWNER = '%s'`, common.StringUPPER(schemaName), common.StringUPPER(schemaName)))
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// TruncateOracleTable 清理目标表数据
+func (o *Oracle) TruncateOracleTable(schemaName, tableName string) error {
+	_, err := o.OracleDB.ExecContext(o.Ctx, fmt.Sprintf(`TRUNCATE TABLE %s.%s`, schemaName, tableName))
+	if err != nil {
+		return fmt.Errorf("truncate oracle schema [%v] table [%v] reocrd failed: %v", schemaName, tableName, err)
+	}
+	return nil
+}
+
+// DeleteOracleTableByWhere 按条件删除表数据，用于 chunk 断点重跑前清理部分写入数据
+func (o *Oracle) DeleteOracleTableByWhere(schemaName, tableName, whereS string) (int64, error) {
+	deleteSQL := fmt.Sprintf(`DELETE FROM %s.%s WHERE %s`, schemaName, tableName, whereS)
+	res, err := o.OracleDB.ExecContext(o.Ctx, deleteSQL)
+	if err != nil {
+		return 0, fmt.Errorf("oracle sql [%v] delete failed: %v", deleteSQL, err)
+	}
+	affectRows, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("oracle sql [%v] get rows affected failed: %v", deleteSQL, err)
+	}
+	return affectRows, nil
+}
+
+// WriteOracleTableByArrayBind 数组绑定批量写入，args 每个元素为对应字段的数据切片
+func (o *Oracle) WriteOracleTableByArrayBind(insertSQL string, args []interface{}) error {
+	_, err := o.OracleDB.ExecContext(o.Ctx, insertSQL, args...)
+	if err != nil {
+		return fmt.Errorf("oracle sql [%v] array bind write failed: %v", insertSQL, err)
+	}
+	return nil
+}
+
+// GetOracleTableColumnMinMax 数值字段最小、最大值，分别查询以利用索引 MIN/MAX 扫描
+func (o *Oracle) GetOracleTableColumnMinMax(schemaName, tableName, columnName string) (string, string, error) {
+	querySQL := fmt.Sprintf(`SELECT
+	TO_CHAR((SELECT FLOOR(MIN("%[3]s")) FROM "%[1]s"."%[2]s")) MIN_VALUE,
+	TO_CHAR((SELECT CEIL(MAX("%[3]s")) FROM "%[1]s"."%[2]s")) MAX_VALUE
+FROM DUAL`, schemaName, tableName, columnName)
+	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	if err != nil {
+		return "", "", err
+	}
+	if len(res) == 0 {
+		return "", "", nil
+	}
+	return res[0]["MIN_VALUE"], res[0]["MAX_VALUE"], nil
 }
@@ -0,0 +1,83 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package oracle
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"strings"
+)
+
+// GetOracleTableMViewLog 获取表物化视图日志表名，不存在返回空，需 WITH PRIMARY KEY, SEQUENCE
+func (o *Oracle) GetOracleTableMViewLog(schemaName, tableName string) (string, error) {
+	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`select log_table AS LOG_TABLE,
+       primary_key AS PRIMARY_KEY,
+       sequence AS SEQUENCE
+  from dba_mview_logs
+ where upper(log_owner) = upper('%s')
+   and upper(master) = upper('%s')`, schemaName, tableName))
+	if err != nil {
+		return "", err
+	}
+	if len(res) == 0 {
+		return "", nil
+	}
+	if res[0]["PRIMARY_KEY"] != "YES" || res[0]["SEQUENCE"] != "YES" {
+		return "", fmt.Errorf("oracle schema [%s] table [%s] materialized view log [%s] exist, but isn't created with primary key and sequence", schemaName, tableName, res[0]["LOG_TABLE"])
+	}
+	return res[0]["LOG_TABLE"], nil
+}
+
+func (o *Oracle) CreateOracleTableMViewLog(schemaName, tableName string) error {
+	createSQL := common.StringsBuilder(`CREATE MATERIALIZED VIEW LOG ON `, schemaName, `.`, tableName, ` WITH PRIMARY KEY, SEQUENCE EXCLUDING NEW VALUES`)
+	if _, err := o.OracleDB.ExecContext(o.Ctx, createSQL); err != nil {
+		return fmt.Errorf("oracle sql [%v] create materialized view log failed: %v", createSQL, err)
+	}
+	return nil
+}
+
+// GetOracleMViewLogRecord 按 SEQUENCE$$ 顺序获取物化视图日志记录
+// purge 模式读取全部剩余记录（已消费记录会被删除），否则读取位点之后、安全位点之内记录
+// SEQUENCE$$ 于 DML 时分配，长事务提交晚于后续事务，位点直接推进至可见最大值会跳过其记录
+// 安全位点取提交早于当前最早活跃事务开始 SCN 的最大 SEQUENCE$$，活跃事务分配的 SEQUENCE$$ 均大于该值；块级 ORA_ROWSCN 不小于实际提交 SCN，判断偏保守
+func (o *Oracle) GetOracleMViewLogRecord(schemaName, logTable string, pkColumns []string, sequence uint64, purge bool, limit int) ([]map[string]string, error) {
+	var whereS string
+	if !purge {
+		logTableFrom := common.StringsBuilder(schemaName, `."`, logTable, `"`)
+		whereS = fmt.Sprintf(` WHERE SEQUENCE$$ > %d AND SEQUENCE$$ <= (SELECT NVL(MAX(SEQUENCE$$), %d) FROM %s WHERE SEQUENCE$$ > %d AND ORA_ROWSCN < (SELECT NVL(MIN(START_SCN), (SELECT CURRENT_SCN FROM V$DATABASE)) FROM GV$TRANSACTION))`,
+			sequence, sequence, logTableFrom, sequence)
+	}
+	querySQL := common.StringsBuilder(`SELECT * FROM (SELECT ROWIDTOCHAR(ROWID) AS LOG_ROWID,`, strings.Join(pkColumns, ","),
+		`,DMLTYPE$$ AS DMLTYPE,SEQUENCE$$ AS SEQUENCE FROM `, schemaName, `."`, logTable, `"`, whereS,
+		` ORDER BY SEQUENCE$$) WHERE ROWNUM <= `, fmt.Sprintf("%d", limit))
+	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// PurgeOracleMViewLogRecord 按 ROWID 删除已消费记录，避免误删消费期间提交的较小 SEQUENCE$$ 记录
+func (o *Oracle) PurgeOracleMViewLogRecord(schemaName, logTable string, rowIDs []string) error {
+	if len(rowIDs) == 0 {
+		return nil
+	}
+	deleteSQL := common.StringsBuilder(`DELETE FROM `, schemaName, `."`, logTable, `" WHERE ROWID IN ('`, strings.Join(rowIDs, "','"), `')`)
+	if _, err := o.OracleDB.ExecContext(o.Ctx, deleteSQL); err != nil {
+		return fmt.Errorf("oracle schema [%s] materialized view log [%s] purge failed: %v", schemaName, logTable, err)
+	}
+	return nil
+}
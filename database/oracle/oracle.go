@@ -23,15 +23,28 @@ import (
 	"github.com/godror/godror/dsn"
 	"github.com/wentaojin/transferdb/common"
 	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/tunnel"
+	"go.uber.org/zap"
+	"net"
 	"os"
 	"runtime"
-	"strconv"
 	"strings"
+	"time"
 )
 
 type Oracle struct {
 	Ctx      context.Context
 	OracleDB *sql.DB
+	// 物理备库连接，仅用于 full/csv 数据抽取，未配置为 nil
+	StandbyDB           *sql.DB
+	standbyApplyTimeout time.Duration
+	// 会话被 kill 重试次数以及间隔
+	sessionKillRetryTimes    int
+	sessionKillRetryInterval time.Duration
+	// 抽取字符字段字符集转换，未配置为 nil
+	charsetConverter *common.CharsetConverter
+	// 数据字典缓存，EnableDictionaryCache 开启
+	dictCache *dictCache
 }
 
 // 创建 oracle 数据库引擎
@@ -41,19 +54,67 @@ func NewOracleDBEngine(ctx context.Context, oraCfg config.OracleConfig) (*Oracle
 	// https://godror.github.io/godror/doc/connection.html
 	// You can specify connection timeout seconds with "?connect_timeout=15" - Ping uses this timeout, NOT the Deadline in Context!
 	// For more connection options, see [Godor Connection Handling](https://godror.github.io/godror/doc/connection.html).
+	converter, err := common.NewCharsetConverter(oraCfg.SourceCharset, oraCfg.TargetCharset)
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := openOracleDB(ctx, oraCfg)
+	if err != nil {
+		return nil, err
+	}
+	o := &Oracle{
+		Ctx:                      ctx,
+		OracleDB:                 sqlDB,
+		sessionKillRetryTimes:    oraCfg.SessionKillRetryTimes,
+		sessionKillRetryInterval: time.Duration(oraCfg.SessionKillRetryInterval) * time.Second,
+		charsetConverter:         converter,
+	}
+
+	if oraCfg.Standby.Host != "" || len(oraCfg.Standby.Addrs) > 0 {
+		o.StandbyDB, err = openOracleDB(ctx, genOracleStandbyConfig(oraCfg))
+		if err != nil {
+			return nil, fmt.Errorf("error on open oracle standby database connection: %v", err)
+		}
+		if err = verifyOracleStandby(ctx, o.StandbyDB); err != nil {
+			return nil, err
+		}
+		o.standbyApplyTimeout = time.Duration(oraCfg.Standby.ApplyLagTimeout) * time.Second
+	}
+	return o, nil
+}
+
+// openOracleDB 按连接配置建立连接池
+func openOracleDB(ctx context.Context, oraCfg config.OracleConfig) (*sql.DB, error) {
 	var (
 		connString string
 		oraDSN     dsn.ConnectionParams
+		endpoints  []string
+		walletDir  string
 		err        error
 	)
 
+	// 云数据库 wallet 连接按 tnsnames.ora 服务别名建连，不经隧道以及多地址
+	if IsOracleWalletConfig(oraCfg) {
+		walletDir, err = prepareOracleWallet(oraCfg)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = []string{oraCfg.TNSAlias}
+	} else {
+		// 隧道场景改为连接本地转发端口
+		endpoints, err = tunnel.ForwardAddrs(oraCfg.Tunnel, oraCfg.Host, oraCfg.Port, oraCfg.Addrs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	switch {
 	// CDB 架构，程序用户 c## 开头
 	case strings.EqualFold(oraCfg.OraArch, "CDB") && !strings.EqualFold(oraCfg.SchemaName, oraCfg.Username) &&
 		strings.HasPrefix(strings.ToUpper(oraCfg.Username), "C##"):
 		// 启用异构池 heterogeneousPool 即程序连接用户与访问 oracle schema 用户名不一致
 		connString = fmt.Sprintf("oracle://@%s/%s?connectionClass=POOL_CONNECTION_CLASS&heterogeneousPool=1&%s",
-			common.StringsBuilder(oraCfg.Host, ":", strconv.Itoa(oraCfg.Port)),
+			endpoints[0],
 			oraCfg.ServiceName, oraCfg.ConnectParams)
 		oraDSN, err = godror.ParseDSN(connString)
 		if err != nil {
@@ -68,7 +129,7 @@ func NewOracleDBEngine(ctx context.Context, oraCfg config.OracleConfig) (*Oracle
 
 	default:
 		connString = fmt.Sprintf("oracle://@%s/%s?connectionClass=POOL_CONNECTION_CLASS&heterogeneousPool=1&%s",
-			common.StringsBuilder(oraCfg.Host, ":", strconv.Itoa(oraCfg.Port)),
+			endpoints[0],
 			oraCfg.ServiceName, oraCfg.ConnectParams)
 		oraDSN, err = godror.ParseDSN(connString)
 		if err != nil {
@@ -79,6 +140,28 @@ func NewOracleDBEngine(ctx context.Context, oraCfg config.OracleConfig) (*Oracle
 		oraDSN.OnInitStmts = oraCfg.SessionParams
 	}
 
+	// 会话时区固定，每个新建会话执行
+	if oraCfg.SessionTimeZone != "" {
+		oraDSN.OnInitStmts = append(oraDSN.OnInitStmts, fmt.Sprintf("ALTER SESSION SET TIME_ZONE = '%s'", oraCfg.SessionTimeZone))
+	}
+
+	// wallet 服务别名连接，TNS_ADMIN 指向 wallet 目录读取 tnsnames.ora/sqlnet.ora 以及 TLS 证书
+	if walletDir != "" {
+		oraDSN.ConnectString = oraCfg.TNSAlias
+		oraDSN.ConfigDir = walletDir
+		if err = os.Setenv("TNS_ADMIN", walletDir); err != nil {
+			return nil, fmt.Errorf("set TNS_ADMIN env failed: %v", err)
+		}
+	}
+
+	// 多地址 connect descriptor，建连失败依次切换，load-balance 开启时会话随机分布于各地址（RAC 节点间分摊抽取会话）
+	if len(endpoints) > 1 {
+		oraDSN.ConnectString, err = genOracleConnectDescriptor(endpoints, oraCfg.ServiceName, oraCfg.LoadBalance)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// libDir won't have any effect on Linux for linking reasons to do with Oracle's libnnz library that are proving to be intractable.
 	// You must set LD_LIBRARY_PATH or run ldconfig before your process starts.
 	// This is documented in various places for other drivers that use ODPI-C. The parameter works on macOS and Windows.
@@ -108,10 +191,124 @@ func NewOracleDBEngine(ctx context.Context, oraCfg config.OracleConfig) (*Oracle
 	if err != nil {
 		return nil, fmt.Errorf("error on ping oracle database connection:%v", err)
 	}
-	return &Oracle{
-		Ctx:      ctx,
-		OracleDB: sqlDB,
-	}, nil
+
+	if oraCfg.SessionTimeZone != "" {
+		if err = verifyOracleSessionTimeZone(ctx, sqlDB, oraCfg.SessionTimeZone); err != nil {
+			return nil, err
+		}
+	}
+	return sqlDB, nil
+}
+
+// genOracleStandbyConfig 备库连接配置，未配置项沿用主库
+func genOracleStandbyConfig(oraCfg config.OracleConfig) config.OracleConfig {
+	standbyCfg := oraCfg
+	standbyCfg.Host, standbyCfg.Port, standbyCfg.Addrs = oraCfg.Standby.Host, oraCfg.Standby.Port, oraCfg.Standby.Addrs
+	standbyCfg.WalletZip, standbyCfg.WalletDir, standbyCfg.TNSAlias = "", "", ""
+	if oraCfg.Standby.ServiceName != "" {
+		standbyCfg.ServiceName = oraCfg.Standby.ServiceName
+	}
+	if oraCfg.Standby.Username != "" {
+		standbyCfg.Username, standbyCfg.Password = oraCfg.Standby.Username, oraCfg.Standby.Password
+	}
+	return standbyCfg
+}
+
+// verifyOracleStandby 仅支持 READ ONLY [WITH APPLY] 打开的物理备库，MOUNTED 状态无法查询表数据
+func verifyOracleStandby(ctx context.Context, db *sql.DB) error {
+	_, res, err := Query(ctx, db, `SELECT DATABASE_ROLE, OPEN_MODE FROM V$DATABASE`)
+	if err != nil {
+		return err
+	}
+	if len(res) == 0 {
+		return fmt.Errorf("oracle standby database v$database return null rows")
+	}
+	role, openMode := strings.ToUpper(res[0]["DATABASE_ROLE"]), strings.ToUpper(res[0]["OPEN_MODE"])
+	if role != "PHYSICAL STANDBY" {
+		return fmt.Errorf("oracle standby-conn database role [%s] isn't physical standby", role)
+	}
+	if !strings.HasPrefix(openMode, "READ ONLY") {
+		return fmt.Errorf("oracle standby database open mode [%s] can't query table data, please open read only (active data guard)", openMode)
+	}
+	return nil
+}
+
+// CharsetConverter 抽取字符字段字符集转换，未配置返回 nil
+func (o *Oracle) CharsetConverter() *common.CharsetConverter {
+	return o.charsetConverter
+}
+
+// ExtractDB 数据抽取连接，配置备库时走备库
+func (o *Oracle) ExtractDB() *sql.DB {
+	if o.StandbyDB != nil {
+		return o.StandbyDB
+	}
+	return o.OracleDB
+}
+
+// WaitStandbyApplySCN 等待备库应用至主库 SCN，保证基于主库 SCN 的抽取在备库可见，未配置备库直接返回
+func (o *Oracle) WaitStandbyApplySCN(scn uint64) error {
+	if o.StandbyDB == nil {
+		return nil
+	}
+	startTime := time.Now()
+	for {
+		_, res, err := Query(o.Ctx, o.StandbyDB, `SELECT CURRENT_SCN FROM V$DATABASE`)
+		if err != nil {
+			return err
+		}
+		standbySCN, err := common.StrconvUintBitSize(res[0]["CURRENT_SCN"], 64)
+		if err != nil {
+			return fmt.Errorf("get oracle standby current scn %s utils.StrconvUintBitSize failed: %v", res[0]["CURRENT_SCN"], err)
+		}
+		if standbySCN >= scn {
+			zap.L().Info("oracle standby apply scn caught up",
+				zap.Uint64("primary scn", scn),
+				zap.Uint64("standby scn", standbySCN),
+				zap.String("cost", time.Since(startTime).String()))
+			return nil
+		}
+		if time.Since(startTime) > o.standbyApplyTimeout {
+			return fmt.Errorf("oracle standby current scn [%d] lag primary scn [%d] over apply-lag-timeout [%v]", standbySCN, scn, o.standbyApplyTimeout)
+		}
+		select {
+		case <-o.Ctx.Done():
+			return o.Ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// verifyOracleSessionTimeZone 校验会话时区与配置一致，不一致 DATE/TIMESTAMP 数据会出现整小时偏移
+func verifyOracleSessionTimeZone(ctx context.Context, db *sql.DB, timeZone string) error {
+	_, res, err := Query(ctx, db, `SELECT SESSIONTIMEZONE AS TZ FROM DUAL`)
+	if err != nil {
+		return err
+	}
+	if len(res) == 0 || !strings.EqualFold(strings.TrimSpace(res[0]["TZ"]), strings.TrimSpace(timeZone)) {
+		return fmt.Errorf("oracle session time zone [%v] isn't equal config session-time-zone [%s]", res, timeZone)
+	}
+	return nil
+}
+
+// genOracleConnectDescriptor 多地址 ADDRESS_LIST 连接描述符
+func genOracleConnectDescriptor(endpoints []string, serviceName string, loadBalance bool) (string, error) {
+	var b strings.Builder
+	b.WriteString("(DESCRIPTION=(ADDRESS_LIST=(FAILOVER=on)")
+	if loadBalance {
+		b.WriteString("(LOAD_BALANCE=on)")
+	} else {
+		b.WriteString("(LOAD_BALANCE=off)")
+	}
+	for _, e := range endpoints {
+		host, port, err := net.SplitHostPort(e)
+		if err != nil {
+			return "", fmt.Errorf("oracle addr [%s] parse failed: %v", e, err)
+		}
+		b.WriteString(fmt.Sprintf("(ADDRESS=(PROTOCOL=TCP)(HOST=%s)(PORT=%s))", host, port))
+	}
+	b.WriteString(fmt.Sprintf(")(CONNECT_DATA=(SERVICE_NAME=%s)))", serviceName))
+	return b.String(), nil
 }
 
 func Query(ctx context.Context, db *sql.DB, querySQL string) ([]string, []map[string]string, error) {
@@ -22,7 +22,7 @@ import (
 )
 
 func (o *Oracle) GetOracleSchemaPartitionTable(schemaName string) ([]string, error) {
-	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`SELECT table_name AS TABLE_NAME
+	_, res, err := o.queryDictionary(fmt.Sprintf(`SELECT table_name AS TABLE_NAME
 	FROM DBA_TABLES
  WHERE partitioned = 'YES'
    AND UPPER(owner) = UPPER('%s')`, schemaName))
@@ -38,7 +38,7 @@ func (o *Oracle) GetOracleSchemaPartitionTable(schemaName string) ([]string, err
 }
 
 func (o *Oracle) GetOracleSchemaTemporaryTable(schemaName string) ([]string, error) {
-	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`select table_name AS TABLE_NAME
+	_, res, err := o.queryDictionary(fmt.Sprintf(`select table_name AS TABLE_NAME
   from dba_tables
  where TEMPORARY = 'Y'
    and upper(owner) = upper('%s')`, schemaName))
@@ -53,9 +53,42 @@ func (o *Oracle) GetOracleSchemaTemporaryTable(schemaName string) ([]string, err
 	return tables, nil
 }
 
+func (o *Oracle) GetOracleSchemaFlashbackArchiveTable(schemaName string) ([]string, error) {
+	_, res, err := o.queryDictionary(fmt.Sprintf(`select table_name AS TABLE_NAME
+  from dba_flashback_archive_tables
+ where upper(owner_name) = upper('%s')`, schemaName))
+	if err != nil {
+		return []string{}, err
+	}
+
+	var tables []string
+	for _, r := range res {
+		tables = append(tables, r["TABLE_NAME"])
+	}
+	return tables, nil
+}
+
+func (o *Oracle) GetOracleSchemaTemporalValidityTable(schemaName string) ([]string, error) {
+	// 过滤 Temporal Validity 有效期表，oracle 12c 及以上
+	_, res, err := o.queryDictionary(fmt.Sprintf(`select distinct o.object_name AS TABLE_NAME
+  from sys.sys_fba_period p, dba_objects o
+ where p.obj# = o.object_id
+   and o.object_type = 'TABLE'
+   and upper(o.owner) = upper('%s')`, schemaName))
+	if err != nil {
+		return []string{}, err
+	}
+
+	var tables []string
+	for _, r := range res {
+		tables = append(tables, r["TABLE_NAME"])
+	}
+	return tables, nil
+}
+
 func (o *Oracle) GetOracleSchemaClusteredTable(schemaName string) ([]string, error) {
 	// 过滤蔟表
-	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`select table_name AS TABLE_NAME
+	_, res, err := o.queryDictionary(fmt.Sprintf(`select table_name AS TABLE_NAME
   from dba_tables
  where CLUSTER_NAME IS NOT NULL
    and upper(owner) = upper('%s')`, schemaName))
@@ -72,7 +105,7 @@ func (o *Oracle) GetOracleSchemaClusteredTable(schemaName string) ([]string, err
 
 func (o *Oracle) GetOracleSchemaMaterializedView(schemaName string) ([]string, error) {
 	// 过滤物化视图
-	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`SELECT OWNER,MVIEW_NAME FROM DBA_MVIEWS WHERE UPPER(OWNER) = UPPER('%s')`, schemaName))
+	_, res, err := o.queryDictionary(fmt.Sprintf(`SELECT OWNER,MVIEW_NAME FROM DBA_MVIEWS WHERE UPPER(OWNER) = UPPER('%s')`, schemaName))
 	if err != nil {
 		return []string{}, err
 	}
@@ -210,7 +243,7 @@ func (o *Oracle) GetOracleSchemaTablePrimaryKey(schemaName string, tableName str
  group by cu.constraint_name`,
 		strings.ToUpper(tableName),
 		strings.ToUpper(schemaName))
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -231,7 +264,7 @@ func (o *Oracle) GetOracleSchemaTableUniqueKey(schemaName string, tableName stri
  group by cu.constraint_name,au.index_name`,
 		strings.ToUpper(tableName),
 		strings.ToUpper(schemaName))
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -277,7 +310,7 @@ select x.constraint_name,
 		strings.ToUpper(tableName),
 		strings.ToUpper(schemaName),
 		strings.ToUpper(schemaName))
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -297,7 +330,7 @@ func (o *Oracle) GetOracleSchemaTableCheckKey(schemaName string, tableName strin
 		strings.ToUpper(tableName),
 		strings.ToUpper(schemaName),
 	)
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -387,7 +420,7 @@ AND xs.COLUMN_POSITION = T.COLUMN_POSITION)) COLUMN_NAME,
 		strings.ToUpper(tableName),
 		strings.ToUpper(schemaName),
 		strings.ToUpper(tableName))
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -484,7 +517,7 @@ WHERE
 		strings.ToUpper(tableName),
 		strings.ToUpper(schemaName),
 		strings.ToUpper(tableName))
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return res, err
 	}
@@ -502,7 +535,7 @@ where
 table_type = 'TABLE'
 and upper(owner)=upper('%s')
 and upper(table_name)=upper('%s')`, strings.ToUpper(schemaName), strings.ToUpper(tableName))
-	_, res, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, res, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return comments, err
 	}
@@ -532,11 +565,12 @@ func (o *Oracle) GetOracleSchemaTableColumn(schemaName string, tableName string,
 			- number -> number(38,127)
 			- number(x,y) -> number(x,y)
 	*/
+	// 字符字段 CHAR_USED 为空时按库级 NLS_LENGTH_SEMANTICS 判定长度语义（C 字符 / B 字节）
 	if oraCollation {
 		querySQL = fmt.Sprintf(`select t.COLUMN_NAME,
 	    t.DATA_TYPE,
 		 t.CHAR_LENGTH,
-		 NVL(t.CHAR_USED,'UNKNOWN') CHAR_USED,
+		 NVL(t.CHAR_USED,DECODE(NVL(t.CHAR_LENGTH,0),0,'UNKNOWN',(SELECT DECODE(VALUE,'CHAR','C','B') from NLS_DATABASE_PARAMETERS WHERE PARAMETER = 'NLS_LENGTH_SEMANTICS'))) CHAR_USED,
 	    NVL(t.DATA_LENGTH,0) AS DATA_LENGTH,
 	    DECODE(NVL(TO_CHAR(t.DATA_PRECISION),'*'),'*','38',TO_CHAR(t.DATA_PRECISION)) AS DATA_PRECISION,
 	    DECODE(NVL(TO_CHAR(t.DATA_SCALE),'*'),'*','127',TO_CHAR(t.DATA_SCALE)) AS DATA_SCALE,
@@ -557,7 +591,7 @@ func (o *Oracle) GetOracleSchemaTableColumn(schemaName string, tableName string,
 		querySQL = fmt.Sprintf(`select t.COLUMN_NAME,
 	    t.DATA_TYPE,
 		 t.CHAR_LENGTH,
-		 NVL(t.CHAR_USED,'UNKNOWN') CHAR_USED,
+		 NVL(t.CHAR_USED,DECODE(NVL(t.CHAR_LENGTH,0),0,'UNKNOWN',(SELECT DECODE(VALUE,'CHAR','C','B') from NLS_DATABASE_PARAMETERS WHERE PARAMETER = 'NLS_LENGTH_SEMANTICS'))) CHAR_USED,
 	    NVL(t.DATA_LENGTH,0) AS DATA_LENGTH,
 	    DECODE(NVL(TO_CHAR(t.DATA_PRECISION),'*'),'*','38',TO_CHAR(t.DATA_PRECISION)) AS DATA_PRECISION,
 	    DECODE(NVL(TO_CHAR(t.DATA_SCALE),'*'),'*','127',TO_CHAR(t.DATA_SCALE)) AS DATA_SCALE,
@@ -575,7 +609,7 @@ func (o *Oracle) GetOracleSchemaTableColumn(schemaName string, tableName string,
 			strings.ToUpper(tableName))
 	}
 
-	_, queryRes, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, queryRes, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return queryRes, err
 	}
@@ -585,7 +619,7 @@ func (o *Oracle) GetOracleSchemaTableColumn(schemaName string, tableName string,
 
 	// check constraints notnull
 	// search_condition long datatype
-	_, condRes, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`SELECT
+	_, condRes, err := o.queryDictionary(fmt.Sprintf(`SELECT
 				col.COLUMN_NAME,
 				cons.SEARCH_CONDITION
 				FROM
@@ -638,7 +672,7 @@ func (o *Oracle) GetOracleSchemaTableColumnComment(schemaName string, tableName
 		strings.ToUpper(schemaName),
 		strings.ToUpper(tableName))
 
-	_, queryRes, err := Query(o.Ctx, o.OracleDB, querySQL)
+	_, queryRes, err := o.queryDictionary(querySQL)
 	if err != nil {
 		return queryRes, err
 	}
@@ -649,7 +683,7 @@ func (o *Oracle) GetOracleSchemaTableColumnComment(schemaName string, tableName
 }
 
 func (o *Oracle) GetOracleExtendedMode() (bool, error) {
-	_, res, err := Query(o.Ctx, o.OracleDB, `SELECT VALUE FROM V$PARAMETER WHERE UPPER(NAME) = UPPER('MAX_STRING_SIZE')`)
+	_, res, err := o.queryDictionary(`SELECT VALUE FROM V$PARAMETER WHERE UPPER(NAME) = UPPER('MAX_STRING_SIZE')`)
 	if err != nil {
 		return false, err
 	}
@@ -659,6 +693,42 @@ func (o *Oracle) GetOracleExtendedMode() (bool, error) {
 	return false, nil
 }
 
+// GetOracleSchemaSequence 获取 schema 序列定义，LAST_NUMBER 为序列下一可分配值
+func (o *Oracle) GetOracleSchemaSequence(schemaName string) ([]map[string]string, error) {
+	_, res, err := o.queryDictionary(fmt.Sprintf(`SELECT SEQUENCE_NAME,
+	TO_CHAR(MIN_VALUE) AS MIN_VALUE,
+	TO_CHAR(MAX_VALUE) AS MAX_VALUE,
+	TO_CHAR(INCREMENT_BY) AS INCREMENT_BY,
+	CYCLE_FLAG,
+	TO_CHAR(CACHE_SIZE) AS CACHE_SIZE,
+	TO_CHAR(LAST_NUMBER) AS LAST_NUMBER
+FROM DBA_SEQUENCES
+WHERE SEQUENCE_OWNER = '%s'
+ORDER BY SEQUENCE_NAME`, strings.ToUpper(schemaName)))
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// GetOracleSchemaTableIdentityColumn 获取表标识列以及关联序列，需 oracle 12c 及以上
+func (o *Oracle) GetOracleSchemaTableIdentityColumn(schemaName string, tableName string) ([]map[string]string, error) {
+	_, res, err := o.queryDictionary(fmt.Sprintf(`SELECT I.COLUMN_NAME,
+	I.GENERATION_TYPE,
+	I.SEQUENCE_NAME,
+	TO_CHAR(S.INCREMENT_BY) AS INCREMENT_BY,
+	TO_CHAR(S.LAST_NUMBER) AS LAST_NUMBER
+FROM DBA_TAB_IDENTITY_COLS I, DBA_SEQUENCES S
+WHERE I.OWNER = S.SEQUENCE_OWNER
+	AND I.SEQUENCE_NAME = S.SEQUENCE_NAME
+	AND I.OWNER = '%s'
+	AND I.TABLE_NAME = '%s'`, strings.ToUpper(schemaName), strings.ToUpper(tableName)))
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
 func (o *Oracle) WriteOracleTable(sql string) error {
 	_, err := o.OracleDB.ExecContext(o.Ctx, sql)
 	if err != nil {
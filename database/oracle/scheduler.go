@@ -0,0 +1,65 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package oracle
+
+import (
+	"fmt"
+)
+
+// GetOracleSchemaSchedulerJob 获取 DBMS_SCHEDULER 作业，调度以及执行内容
+// 作业引用 program/schedule 对象时 JOB_ACTION/REPEAT_INTERVAL 取对应对象定义
+func (o *Oracle) GetOracleSchemaSchedulerJob(schemaName string) ([]map[string]string, error) {
+	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`select j.job_name AS JOB_NAME,
+       NVL(j.job_type, NVL(p.program_type, 'UNKNOWN')) AS JOB_TYPE,
+       NVL(j.job_action, NVL(p.program_action, '')) AS JOB_ACTION,
+       NVL(j.repeat_interval, NVL(s.repeat_interval, '')) AS REPEAT_INTERVAL,
+       NVL(j.schedule_type, 'UNKNOWN') AS SCHEDULE_TYPE,
+       NVL(j.enabled, 'FALSE') AS ENABLED,
+       NVL(j.state, 'UNKNOWN') AS STATE,
+       NVL(j.comments, '') AS COMMENTS
+  from dba_scheduler_jobs j
+  left join dba_scheduler_programs p
+    on j.program_owner = p.owner
+   and j.program_name = p.program_name
+  left join dba_scheduler_schedules s
+    on j.schedule_owner = s.owner
+   and j.schedule_name = s.schedule_name
+ where upper(j.owner) = upper('%s')
+ order by j.job_name`, schemaName))
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// GetOracleSchemaLegacyJob 获取 DBMS_JOB 作业，INTERVAL 为日期表达式
+func (o *Oracle) GetOracleSchemaLegacyJob(schemaName string) ([]map[string]string, error) {
+	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`select to_char(job) AS JOB_NAME,
+       'PLSQL_BLOCK' AS JOB_TYPE,
+       what AS JOB_ACTION,
+       NVL(interval, '') AS REPEAT_INTERVAL,
+       'PLSQL' AS SCHEDULE_TYPE,
+       DECODE(broken, 'Y', 'FALSE', 'TRUE') AS ENABLED,
+       DECODE(broken, 'Y', 'BROKEN', 'SCHEDULED') AS STATE,
+       '' AS COMMENTS
+  from dba_jobs
+ where upper(schema_user) = upper('%s')
+ order by job`, schemaName))
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
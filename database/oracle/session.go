@@ -0,0 +1,68 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package oracle
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+// IsOracleSessionKilled 会话被 DBA kill（ORA-00028）或服务端连接断开（ORA-03113）
+func IsOracleSessionKilled(err error) bool {
+	if err == nil {
+		return false
+	}
+	return strings.Contains(err.Error(), "ORA-00028") || strings.Contains(err.Error(), "ORA-03113")
+}
+
+// ReacquireOracleSession 重新建立会话，失效连接由连接池丢弃，Ping 确认可新建会话
+func (o *Oracle) ReacquireOracleSession() error {
+	if err := o.OracleDB.PingContext(o.Ctx); err != nil {
+		return fmt.Errorf("oracle reacquire session failed: %v", err)
+	}
+	if o.StandbyDB != nil {
+		if err := o.StandbyDB.PingContext(o.Ctx); err != nil {
+			return fmt.Errorf("oracle standby reacquire session failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// RetryOnSessionKilled 会话被 kill 时重建会话并重试，其他错误以及重试次数耗尽直接返回
+// fn 参数为重试序号，首次执行为 0
+func (o *Oracle) RetryOnSessionKilled(fn func(attempt int) error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn(attempt)
+		if err == nil || !IsOracleSessionKilled(err) || attempt >= o.sessionKillRetryTimes {
+			return err
+		}
+		zap.L().Warn("oracle session killed, reacquire session and retry",
+			zap.Int("attempt", attempt+1),
+			zap.Int("retry times", o.sessionKillRetryTimes),
+			zap.Error(err))
+
+		select {
+		case <-o.Ctx.Done():
+			return o.Ctx.Err()
+		case <-time.After(o.sessionKillRetryInterval):
+		}
+		if errR := o.ReacquireOracleSession(); errR != nil {
+			return fmt.Errorf("%v, %v", err, errR)
+		}
+	}
+}
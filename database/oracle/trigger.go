@@ -0,0 +1,134 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package oracle
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"strings"
+)
+
+func (o *Oracle) GetOracleTableObjectID(schemaName, tableName string) (string, error) {
+	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`select object_id AS OBJECT_ID
+  from dba_objects
+ where object_type = 'TABLE'
+   and upper(owner) = upper('%s')
+   and upper(object_name) = upper('%s')`, schemaName, tableName))
+	if err != nil {
+		return "", err
+	}
+	if len(res) == 0 {
+		return "", fmt.Errorf("oracle schema [%s] table [%s] isn't exist", schemaName, tableName)
+	}
+	return res[0]["OBJECT_ID"], nil
+}
+
+func (o *Oracle) isExistOracleObject(schemaName, objectType, objectName string) (bool, error) {
+	_, res, err := Query(o.Ctx, o.OracleDB, fmt.Sprintf(`select count(1) AS COUNTS
+  from dba_objects
+ where object_type = '%s'
+   and upper(owner) = upper('%s')
+   and object_name = '%s'`, objectType, schemaName, objectName))
+	if err != nil {
+		return false, err
+	}
+	return res[0]["COUNTS"] != "0", nil
+}
+
+// CreateOracleTableChangeCapture 创建触发器捕获对象，影子表字段与物化视图日志保持一致（主键 + DMLTYPE$$ + SEQUENCE$$），复用同一消费流程
+// 已存在对象跳过创建，触发器 CREATE OR REPLACE
+func (o *Oracle) CreateOracleTableChangeCapture(schemaName, tableName, objectID string, pkColumns []string) (string, error) {
+	shadowTable := common.StringsBuilder(common.MigrateTriggerShadowTablePrefix, objectID)
+	sequenceName := common.StringsBuilder(common.MigrateTriggerSequencePrefix, objectID)
+	triggerName := common.StringsBuilder(common.MigrateTriggerNamePrefix, objectID)
+
+	var createSQLs []string
+	isExist, err := o.isExistOracleObject(schemaName, "SEQUENCE", sequenceName)
+	if err != nil {
+		return shadowTable, err
+	}
+	if !isExist {
+		createSQLs = append(createSQLs, common.StringsBuilder(`CREATE SEQUENCE `, schemaName, `.`, sequenceName, ` CACHE 1000`))
+	}
+	isExist, err = o.isExistOracleObject(schemaName, "TABLE", shadowTable)
+	if err != nil {
+		return shadowTable, err
+	}
+	if !isExist {
+		// 主键字段类型与源表一致
+		createSQLs = append(createSQLs,
+			common.StringsBuilder(`CREATE TABLE `, schemaName, `.`, shadowTable, ` AS SELECT `, strings.Join(pkColumns, ","), ` FROM `, schemaName, `.`, tableName, ` WHERE 1 = 0`),
+			common.StringsBuilder(`ALTER TABLE `, schemaName, `.`, shadowTable, ` ADD (DMLTYPE$$ CHAR(1), SEQUENCE$$ NUMBER, CHANGE_TIME$$ DATE DEFAULT SYSDATE)`))
+	}
+
+	var newCols, oldCols []string
+	for _, c := range pkColumns {
+		newCols = append(newCols, common.StringsBuilder(`:NEW.`, c))
+		oldCols = append(oldCols, common.StringsBuilder(`:OLD.`, c))
+	}
+	insertPrefix := common.StringsBuilder(`INSERT INTO `, schemaName, `.`, shadowTable, ` (`, strings.Join(pkColumns, ","), `,DMLTYPE$$,SEQUENCE$$) VALUES (`)
+	// UPDATE 同时记录新旧主键，主键变更时旧主键按删除处理
+	createSQLs = append(createSQLs, common.StringsBuilder(`CREATE OR REPLACE TRIGGER `, schemaName, `.`, triggerName, `
+AFTER INSERT OR UPDATE OR DELETE ON `, schemaName, `.`, tableName, `
+FOR EACH ROW
+BEGIN
+  IF INSERTING THEN
+    `, insertPrefix, strings.Join(newCols, ","), `,'I',`, schemaName, `.`, sequenceName, `.NEXTVAL);
+  ELSIF UPDATING THEN
+    `, insertPrefix, strings.Join(oldCols, ","), `,'U',`, schemaName, `.`, sequenceName, `.NEXTVAL);
+    `, insertPrefix, strings.Join(newCols, ","), `,'U',`, schemaName, `.`, sequenceName, `.NEXTVAL);
+  ELSE
+    `, insertPrefix, strings.Join(oldCols, ","), `,'D',`, schemaName, `.`, sequenceName, `.NEXTVAL);
+  END IF;
+END;`))
+
+	for _, createSQL := range createSQLs {
+		if _, err = o.OracleDB.ExecContext(o.Ctx, createSQL); err != nil {
+			return shadowTable, fmt.Errorf("oracle schema [%s] table [%s] sql [%v] create change capture failed: %v", schemaName, tableName, createSQL, err)
+		}
+	}
+	return shadowTable, nil
+}
+
+// DropOracleTableChangeCapture 按影子表名删除触发器、影子表以及序列，先删触发器避免业务 DML 写入失败
+func (o *Oracle) DropOracleTableChangeCapture(schemaName, shadowTable string) error {
+	objectID := strings.TrimPrefix(shadowTable, common.MigrateTriggerShadowTablePrefix)
+	objects := []struct {
+		objectType string
+		objectName string
+	}{
+		{"TRIGGER", common.StringsBuilder(common.MigrateTriggerNamePrefix, objectID)},
+		{"TABLE", shadowTable},
+		{"SEQUENCE", common.StringsBuilder(common.MigrateTriggerSequencePrefix, objectID)},
+	}
+	for _, obj := range objects {
+		isExist, err := o.isExistOracleObject(schemaName, obj.objectType, obj.objectName)
+		if err != nil {
+			return err
+		}
+		if !isExist {
+			continue
+		}
+		dropSQL := common.StringsBuilder(`DROP `, obj.objectType, ` `, schemaName, `.`, obj.objectName)
+		if obj.objectType == "TABLE" {
+			dropSQL = common.StringsBuilder(dropSQL, ` PURGE`)
+		}
+		if _, err = o.OracleDB.ExecContext(o.Ctx, dropSQL); err != nil {
+			return fmt.Errorf("oracle sql [%v] drop change capture object failed: %v", dropSQL, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package tunnel
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"golang.org/x/net/proxy"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	forwardsMu sync.Mutex
+	// 同一隧道与目标地址复用本地转发端口，engine 多次创建不重复监听
+	forwards = make(map[string]string)
+)
+
+// Forward 本地随机端口监听并经隧道转发至 host:port，返回替换后的连接地址，未配置隧道原样返回
+// 转发随进程存活，多日任务 ssh 连接断开时下次建连自动重连
+func Forward(tunnelCfg config.TunnelConfig, host string, port int) (string, int, error) {
+	if tunnelCfg.Type == "" {
+		return host, port, nil
+	}
+	target := net.JoinHostPort(host, strconv.Itoa(port))
+	key := common.StringsBuilder(common.StringUPPER(tunnelCfg.Type), "/", tunnelCfg.Addr, "/", tunnelCfg.User, "/", target)
+
+	forwardsMu.Lock()
+	defer forwardsMu.Unlock()
+	if localAddr, ok := forwards[key]; ok {
+		return splitHostPort(localAddr)
+	}
+
+	d, err := newDialer(tunnelCfg)
+	if err != nil {
+		return host, port, err
+	}
+	// 预先建连校验隧道可达
+	conn, err := d.Dial("tcp", target)
+	if err != nil {
+		return host, port, fmt.Errorf("tunnel [%s] dial target [%s] failed: %v", tunnelCfg.Addr, target, err)
+	}
+	conn.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return host, port, fmt.Errorf("tunnel local listen failed: %v", err)
+	}
+	go serve(ln, d, target)
+
+	localAddr := ln.Addr().String()
+	forwards[key] = localAddr
+	zap.L().Info("database connection tunnel forward",
+		zap.String("tunnel type", tunnelCfg.Type),
+		zap.String("tunnel addr", tunnelCfg.Addr),
+		zap.String("target", target),
+		zap.String("local", localAddr))
+	return splitHostPort(localAddr)
+}
+
+// ForwardAddrs 汇总连接地址，配置 addrs 时以 addrs 为准否则取 host/port，隧道场景逐个替换为本地转发地址
+// 返回 host:port 列表，IPv6 地址带方括号
+func ForwardAddrs(tunnelCfg config.TunnelConfig, host string, port int, addrs []string) ([]string, error) {
+	if len(addrs) == 0 {
+		addrs = []string{net.JoinHostPort(host, strconv.Itoa(port))}
+	}
+	var endpoints []string
+	for _, addr := range addrs {
+		h, p, err := splitHostPort(addr)
+		if err != nil {
+			return nil, fmt.Errorf("database addr [%s] parse failed: %v", addr, err)
+		}
+		h, p, err = Forward(tunnelCfg, h, p)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, net.JoinHostPort(h, strconv.Itoa(p)))
+	}
+	return endpoints, nil
+}
+
+func splitHostPort(addr string) (string, int, error) {
+	host, portS, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0, err
+	}
+	port, err := strconv.Atoi(portS)
+	if err != nil {
+		return "", 0, err
+	}
+	return host, port, nil
+}
+
+func serve(ln net.Listener, d proxy.Dialer, target string) {
+	for {
+		local, err := ln.Accept()
+		if err != nil {
+			zap.L().Error("tunnel local accept failed", zap.String("target", target), zap.Error(err))
+			return
+		}
+		go func() {
+			remote, err := d.Dial("tcp", target)
+			if err != nil {
+				zap.L().Warn("tunnel dial target failed", zap.String("target", target), zap.Error(err))
+				local.Close()
+				return
+			}
+			pipe(local, remote)
+		}()
+	}
+}
+
+// pipe 双向拷贝，任一方向结束即关闭两端
+func pipe(local, remote net.Conn) {
+	var once sync.Once
+	closeBoth := func() {
+		local.Close()
+		remote.Close()
+	}
+	go func() {
+		_, _ = io.Copy(remote, local)
+		once.Do(closeBoth)
+	}()
+	_, _ = io.Copy(local, remote)
+	once.Do(closeBoth)
+}
+
+func newDialer(tunnelCfg config.TunnelConfig) (proxy.Dialer, error) {
+	switch common.StringUPPER(tunnelCfg.Type) {
+	case common.TunnelTypeSSH:
+		sshCfg, err := newSSHClientConfig(tunnelCfg)
+		if err != nil {
+			return nil, err
+		}
+		return &sshDialer{addr: tunnelCfg.Addr, cfg: sshCfg}, nil
+	case common.TunnelTypeSOCKS5:
+		var auth *proxy.Auth
+		if tunnelCfg.User != "" {
+			auth = &proxy.Auth{User: tunnelCfg.User, Password: tunnelCfg.Password}
+		}
+		return proxy.SOCKS5("tcp", tunnelCfg.Addr, auth, proxy.Direct)
+	case common.TunnelTypeHTTP:
+		return &httpDialer{addr: tunnelCfg.Addr, user: tunnelCfg.User, password: tunnelCfg.Password}, nil
+	default:
+		return nil, fmt.Errorf("tunnel type [%s] isn't support, only support ssh/socks5/http", tunnelCfg.Type)
+	}
+}
+
+func newSSHClientConfig(tunnelCfg config.TunnelConfig) (*ssh.ClientConfig, error) {
+	var auths []ssh.AuthMethod
+	if tunnelCfg.KeyFile != "" {
+		key, err := os.ReadFile(tunnelCfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read ssh key file [%s] failed: %v", tunnelCfg.KeyFile, err)
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("parse ssh key file [%s] failed: %v", tunnelCfg.KeyFile, err)
+		}
+		auths = append(auths, ssh.PublicKeys(signer))
+	}
+	if tunnelCfg.Password != "" {
+		auths = append(auths, ssh.Password(tunnelCfg.Password))
+	}
+
+	var hostKeyCallback ssh.HostKeyCallback
+	switch {
+	case tunnelCfg.KnownHosts != "":
+		callback, err := knownhosts.New(tunnelCfg.KnownHosts)
+		if err != nil {
+			return nil, fmt.Errorf("read ssh known hosts [%s] failed: %v", tunnelCfg.KnownHosts, err)
+		}
+		hostKeyCallback = callback
+	case tunnelCfg.InsecureSkipHostKey:
+		zap.L().Warn("ssh tunnel skip host key verification, connection is vulnerable to man-in-the-middle attack",
+			zap.String("addr", tunnelCfg.Addr),
+			zap.String("user", tunnelCfg.User))
+		hostKeyCallback = ssh.InsecureIgnoreHostKey()
+	default:
+		return nil, fmt.Errorf("ssh tunnel [%s] known-hosts is empty, please config known-hosts or set insecure-skip-host-key = true explicitly", tunnelCfg.Addr)
+	}
+	return &ssh.ClientConfig{
+		User:            tunnelCfg.User,
+		Auth:            auths,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         30 * time.Second,
+	}, nil
+}
+
+// sshDialer 复用单条 ssh 连接多路转发，连接失效时重连一次
+type sshDialer struct {
+	addr   string
+	cfg    *ssh.ClientConfig
+	mu     sync.Mutex
+	client *ssh.Client
+}
+
+func (s *sshDialer) Dial(network, addr string) (net.Conn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client != nil {
+		conn, err := s.client.Dial(network, addr)
+		if err == nil {
+			return conn, nil
+		}
+		zap.L().Warn("ssh tunnel connection broken, reconnect",
+			zap.String("ssh addr", s.addr),
+			zap.Error(err))
+		s.client.Close()
+		s.client = nil
+	}
+	client, err := ssh.Dial("tcp", s.addr, s.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("ssh dial [%s] failed: %v", s.addr, err)
+	}
+	s.client = client
+	return s.client.Dial(network, addr)
+}
+
+// httpDialer HTTP CONNECT 代理
+type httpDialer struct {
+	addr     string
+	user     string
+	password string
+}
+
+func (h *httpDialer) Dial(network, addr string) (net.Conn, error) {
+	conn, err := net.DialTimeout(network, h.addr, 30*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("http proxy dial [%s] failed: %v", h.addr, err)
+	}
+	req := common.StringsBuilder("CONNECT ", addr, " HTTP/1.1\r\nHost: ", addr, "\r\n")
+	if h.user != "" {
+		req = common.StringsBuilder(req, "Proxy-Authorization: Basic ",
+			base64.StdEncoding.EncodeToString([]byte(h.user+":"+h.password)), "\r\n")
+	}
+	if _, err = conn.Write([]byte(common.StringsBuilder(req, "\r\n"))); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("http proxy [%s] connect [%s] failed: %v", h.addr, addr, err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("http proxy [%s] connect [%s] read response failed: %v", h.addr, addr, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("http proxy [%s] connect [%s] failed: %s", h.addr, addr, resp.Status)
+	}
+	// 数据库服务端先发送握手包，可能已读入 bufio 缓冲
+	return &bufferedConn{Conn: conn, r: br}, nil
+}
+
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (b *bufferedConn) Read(p []byte) (int, error) {
+	return b.r.Read(p)
+}
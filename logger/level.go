@@ -0,0 +1,146 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LogModuleGlobal 全局日志级别，模块未单独设置时生效
+const LogModuleGlobal = "global"
+
+// logModulePaths 模块按调用方源码路径识别
+var logModulePaths = map[string]string{
+	"migrate": "/module/migrate/",
+	"oracle":  "/database/oracle/",
+	"mysql":   "/database/mysql/",
+	"meta":    "/database/meta/",
+}
+
+var (
+	globalLevel  = zap.NewAtomicLevel()
+	moduleMu     sync.RWMutex
+	moduleLevels = make(map[string]zapcore.Level)
+)
+
+// SetLogLevel 运行时调整日志级别，module 为 global 调整全局级别，level 为空时模块恢复跟随全局级别
+func SetLogLevel(module, level string) error {
+	module = strings.ToLower(module)
+	if module == "" || module == LogModuleGlobal {
+		lvl, err := parseLevel(level)
+		if err != nil {
+			return err
+		}
+		globalLevel.SetLevel(lvl)
+		return nil
+	}
+	if _, ok := logModulePaths[module]; !ok {
+		return fmt.Errorf("log module [%s] isn't support, only support [global migrate oracle mysql meta]", module)
+	}
+
+	moduleMu.Lock()
+	defer moduleMu.Unlock()
+	if level == "" {
+		delete(moduleLevels, module)
+		return nil
+	}
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	moduleLevels[module] = lvl
+	return nil
+}
+
+// LogLevels 当前全局以及模块日志级别
+func LogLevels() map[string]string {
+	levels := map[string]string{LogModuleGlobal: globalLevel.Level().String()}
+	moduleMu.RLock()
+	defer moduleMu.RUnlock()
+	for m, l := range moduleLevels {
+		levels[m] = l.String()
+	}
+	return levels
+}
+
+func parseLevel(level string) (zapcore.Level, error) {
+	var lvl zapcore.Level
+	if err := lvl.UnmarshalText([]byte(strings.ToLower(level))); err != nil {
+		return lvl, fmt.Errorf("log level [%s] isn't support: %v", level, err)
+	}
+	return lvl, nil
+}
+
+// minLevel 全局以及各模块最低日志级别，用于 Check 预过滤
+func minLevel() zapcore.Level {
+	lvl := globalLevel.Level()
+	moduleMu.RLock()
+	defer moduleMu.RUnlock()
+	for _, l := range moduleLevels {
+		if l < lvl {
+			lvl = l
+		}
+	}
+	return lvl
+}
+
+// callerLevel 按调用方源码路径匹配模块日志级别
+func callerLevel(file string) zapcore.Level {
+	moduleMu.RLock()
+	defer moduleMu.RUnlock()
+	for m, l := range moduleLevels {
+		if strings.Contains(file, logModulePaths[m]) {
+			return l
+		}
+	}
+	return globalLevel.Level()
+}
+
+// moduleLevelCore 按模块日志级别过滤，Check 阶段调用方未知，按最低级别放行，Write 阶段按调用方模块过滤
+type moduleLevelCore struct {
+	zapcore.Core
+}
+
+func newModuleLevelCore(core zapcore.Core) zapcore.Core {
+	return &moduleLevelCore{Core: core}
+}
+
+func (c *moduleLevelCore) Enabled(lvl zapcore.Level) bool {
+	return lvl >= minLevel()
+}
+
+func (c *moduleLevelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &moduleLevelCore{Core: c.Core.With(fields)}
+}
+
+func (c *moduleLevelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *moduleLevelCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if ent.Level < callerLevel(ent.Caller.File) {
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
@@ -21,6 +21,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
@@ -36,6 +37,16 @@ type Logger struct {
 	IgnoreRecordNotFoundError bool
 }
 
+// SlowQueryRecorder 慢查询回调，用于元数据库慢查询诊断记录，回调需非阻塞
+type SlowQueryRecorder func(sql string, rows int64, elapsed time.Duration)
+
+var slowQueryRecorder atomic.Value
+
+// SetSlowQueryRecorder 设置全局慢查询回调，对全部元数据库连接生效
+func SetSlowQueryRecorder(r SlowQueryRecorder) {
+	slowQueryRecorder.Store(r)
+}
+
 func NewGormLogger(zapLogger *zap.Logger, slowQueryThreshold int) Logger {
 	return Logger{
 		ZapLogger:                 zapLogger,
@@ -93,6 +104,9 @@ func (l Logger) Trace(ctx context.Context, begin time.Time, fc func() (string, i
 	case l.SlowThreshold != 0 && elapsed > l.SlowThreshold && l.LogLevel >= gormlogger.Warn:
 		sql, rows := fc()
 		l.logger().Warn("gorm slow-query", zap.String("sql", sql), zap.Int64("rows", rows), zap.Duration("elapsed", elapsed))
+		if r, ok := slowQueryRecorder.Load().(SlowQueryRecorder); ok && r != nil {
+			r(sql, rows, elapsed)
+		}
 	case l.LogLevel >= gormlogger.Info:
 		sql, rows := fc()
 		l.logger().Debug("gorm slow-query", zap.String("sql", sql), zap.Int64("rows", rows), zap.Duration("elapsed", elapsed))
@@ -33,10 +33,11 @@ const (
 func NewZapLogger(cfg *config.Config) {
 	Encoder := GetEncoder()
 	WriteSyncer := GetWriteSyncer(cfg)
-	LevelEnabler := GetLevelEnabler(cfg.LogConfig.LogLevel)
+	globalLevel.SetLevel(GetLevelEnabler(cfg.LogConfig.LogLevel))
 	// ConsoleEncoder := GetConsoleEncoder()
+	// 日志级别由 moduleLevelCore 过滤，支持运行时按模块调整
 	newCore := zapcore.NewTee(
-		zapcore.NewCore(Encoder, WriteSyncer, LevelEnabler), // 写入文件
+		newModuleLevelCore(zapcore.NewCore(Encoder, WriteSyncer, zapcore.DebugLevel)), // 写入文件
 		//zapcore.NewCore(ConsoleEncoder, zapcore.Lock(os.Stdout), zapcore.DebugLevel), // 写入控制台
 	)
 	logger := zap.New(newCore, zap.AddCaller())
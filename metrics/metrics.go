@@ -0,0 +1,88 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+var (
+	// ChunkCounter chunk 处理数，status 取值 SUCCESS / FAILED
+	ChunkCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "transferdb",
+			Name:      "chunk_processed_total",
+			Help:      "Counter of processed chunks by task mode, table and status.",
+		}, []string{"task_mode", "schema", "table", "status"})
+
+	// RowsCounter 下游写入行数
+	RowsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "transferdb",
+			Name:      "rows_applied_total",
+			Help:      "Counter of rows applied to target by task mode and table.",
+		}, []string{"task_mode", "schema", "table"})
+
+	// ApplyHistogram 下游单 batch 写入耗时
+	ApplyHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "transferdb",
+			Name:      "apply_duration_seconds",
+			Help:      "Bucketed histogram of target batch apply duration.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 20),
+		}, []string{"task_mode", "table"})
+
+	// ExtractHistogram 上游单 batch 抽取耗时
+	ExtractHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "transferdb",
+			Name:      "extract_duration_seconds",
+			Help:      "Bucketed histogram of source batch extract duration.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 20),
+		}, []string{"task_mode", "table"})
+
+	// ErrorCounter 错误数，来源 error_log_detail 记录
+	ErrorCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "transferdb",
+			Name:      "error_total",
+			Help:      "Counter of task errors by task mode and table.",
+		}, []string{"task_mode", "schema", "table"})
+
+	// LogminerLagGauge 增量同步 logminer 延迟，当前 redo 最大 SCN 与表已同步最小 SCN 差值
+	LogminerLagGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "transferdb",
+			Name:      "logminer_lag_scn",
+			Help:      "Gap between oracle current redo max scn and minimum synced table scn.",
+		}, []string{"schema"})
+)
+
+func init() {
+	prometheus.MustRegister(ChunkCounter)
+	prometheus.MustRegister(RowsCounter)
+	prometheus.MustRegister(ApplyHistogram)
+	prometheus.MustRegister(ExtractHistogram)
+	prometheus.MustRegister(ErrorCounter)
+	prometheus.MustRegister(LogminerLagGauge)
+}
+
+// Handler prometheus 指标采集接口
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,275 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package db2m
+
+import (
+	"context"
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/db2"
+	"github.com/wentaojin/transferdb/module/reverse/db2m"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 需人工改写的对象类型，MySQL 无对应对象或语法差异较大
+var manualObjectTypes = map[string]string{
+	"MATERIALIZED QUERY TABLE": "mysql isn't support, rewrite as table and scheduled refresh",
+	"ALIAS":                    "mysql isn't support, rewrite as view",
+	"NICKNAME":                 "federated object, mysql isn't support",
+	"GLOBAL TEMPORARY TABLE":   "rewrite as mysql temporary table in session",
+	"SEQUENCE":                 "mysql isn't support, rewrite as auto_increment column",
+	"PROCEDURE":                "sql pl, manual rewrite",
+	"FUNCTION":                 "sql pl, manual rewrite",
+	"METHOD":                   "structured type method, mysql isn't support",
+	"TRIGGER":                  "sql pl, manual rewrite",
+	"MODULE":                   "mysql isn't support",
+	"VIEW":                     "syntax compatible check, manual rewrite",
+}
+
+type Assess struct {
+	ctx context.Context
+	cfg *config.Config
+	db2 *db2.DB2
+}
+
+func NewAssess(ctx context.Context, cfg *config.Config) (*Assess, error) {
+	if cfg.DB2Config.SchemaName == "" {
+		return nil, fmt.Errorf("db2 config [schema-name] can not be null")
+	}
+	db2DB, err := db2.NewDB2DBEngine(ctx, cfg.DB2Config)
+	if err != nil {
+		return nil, err
+	}
+	return &Assess{
+		ctx: ctx,
+		cfg: cfg,
+		db2: db2DB,
+	}, nil
+}
+
+// Assess 输出 markdown 评估报告：对象统计、字段类型映射、标识列、表达式生成列、索引类型、表空间、分区表以及无主键表
+func (a *Assess) Assess() error {
+	startTime := time.Now()
+	schemaName := a.cfg.DB2Config.SchemaName
+	zap.L().Info("assess db2 migrate mysql start",
+		zap.String("db2Schema", schemaName),
+		zap.String("mysqlSchema", a.cfg.MySQLConfig.SchemaName))
+
+	allSchemas, err := a.db2.GetDB2Schemas()
+	if err != nil {
+		return err
+	}
+	if !common.IsContainString(allSchemas, schemaName) {
+		return fmt.Errorf("db2 schema [%v] not exist", schemaName)
+	}
+
+	dbVersion, err := a.db2.GetDB2DBVersion()
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# DB2 schema %s migrate %s assessment\n\n", schemaName, a.cfg.DBTypeT))
+	sb.WriteString(fmt.Sprintf("- db2 version: %s\n", dbVersion))
+	sb.WriteString(fmt.Sprintf("- assess time: %s\n\n", startTime.Format("2006-01-02 15:04:05")))
+
+	sections := []struct {
+		title string
+		gen   func() (string, error)
+	}{
+		{"Object Overview", a.assessObjectType},
+		{"Column Datatype Mapping", a.assessColumnType},
+		{"Identity Column", a.assessIdentityColumn},
+		{"Generated Column", a.assessGeneratedColumn},
+		{"Index Type", a.assessIndexType},
+		{"Tablespace", a.assessTablespace},
+		{"Partition Table", a.assessPartitionTable},
+		{"Table Without Primary Key", a.assessTableWithoutPK},
+	}
+	for _, s := range sections {
+		content, err := s.gen()
+		if err != nil {
+			return err
+		}
+		sb.WriteString(fmt.Sprintf("## %s\n\n%s\n\n", s.title, content))
+	}
+
+	pwdDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	fileName := filepath.Join(pwdDir, fmt.Sprintf("report_db2_%s.md", schemaName))
+	if err = os.WriteFile(fileName, []byte(sb.String()), 0666); err != nil {
+		return err
+	}
+
+	zap.L().Info("assess db2 migrate mysql finished",
+		zap.String("cost", time.Now().Sub(startTime).String()),
+		zap.String("output", fileName))
+	return nil
+}
+
+func (a *Assess) assessObjectType() (string, error) {
+	res, err := a.db2.GetDB2SchemaObjectTypeCounts(a.cfg.DB2Config.SchemaName)
+	if err != nil {
+		return "", err
+	}
+	t := newMarkdownTable(table.Row{"OBJECT TYPE", "COUNTS", "SUGGEST"})
+	for _, r := range res {
+		suggest, ok := manualObjectTypes[r["OBJECT_TYPE"]]
+		if !ok {
+			suggest = "auto reverse"
+		}
+		t.AppendRow(table.Row{r["OBJECT_TYPE"], r["COUNTS"], suggest})
+	}
+	return t.RenderMarkdown(), nil
+}
+
+// assessColumnType 字段类型按 reverse 映射规则评估，映射失败即不兼容
+func (a *Assess) assessColumnType() (string, error) {
+	res, err := a.db2.GetDB2SchemaColumnTypeCounts(a.cfg.DB2Config.SchemaName)
+	if err != nil {
+		return "", err
+	}
+	t := newMarkdownTable(table.Row{"DB2 DATATYPE", "LENGTH", "SCALE", "FOR BIT DATA", "COUNTS", "MYSQL DATATYPE", "COMPATIBLE"})
+	for _, r := range res {
+		bitData := "N"
+		if r["CODEPAGE"] == "0" {
+			bitData = "Y"
+		}
+		targetType, err := db2m.DB2TableColumnMapMySQLRule(r["DATA_TYPE"], r)
+		compatible := "Y"
+		if err != nil {
+			targetType = err.Error()
+			compatible = "N"
+		}
+		dataType := r["DATA_TYPE"]
+		if r["TYPE_SCHEMA"] != "SYSIBM" {
+			dataType = fmt.Sprintf("%s.%s", r["TYPE_SCHEMA"], r["DATA_TYPE"])
+		}
+		t.AppendRow(table.Row{dataType, r["DATA_LENGTH"], r["DATA_SCALE"], bitData, r["COUNTS"], targetType, compatible})
+	}
+	return t.RenderMarkdown(), nil
+}
+
+func (a *Assess) assessIdentityColumn() (string, error) {
+	res, err := a.db2.GetDB2SchemaIdentityColumn(a.cfg.DB2Config.SchemaName)
+	if err != nil {
+		return "", err
+	}
+	if len(res) == 0 {
+		return "None", nil
+	}
+	t := newMarkdownTable(table.Row{"TABLE", "COLUMN", "GENERATED", "START", "INCREMENT", "CYCLE", "NEXT VALUE", "SUGGEST"})
+	for _, r := range res {
+		var suggest []string
+		suggest = append(suggest, "auto_increment")
+		if strings.TrimSpace(r["INCREMENT"]) != "1" {
+			suggest = append(suggest, "increment isn't 1, config auto_increment_increment")
+		}
+		if r["CYCLE"] == "Y" {
+			suggest = append(suggest, "cycle isn't support")
+		}
+		t.AppendRow(table.Row{r["TABLE_NAME"], r["COLUMN_NAME"], r["GENERATED"], r["START"], r["INCREMENT"], r["CYCLE"], r["NEXTCACHEFIRSTVALUE"], strings.Join(suggest, ", ")})
+	}
+	return t.RenderMarkdown(), nil
+}
+
+func (a *Assess) assessGeneratedColumn() (string, error) {
+	res, err := a.db2.GetDB2SchemaGeneratedColumn(a.cfg.DB2Config.SchemaName)
+	if err != nil {
+		return "", err
+	}
+	if len(res) == 0 {
+		return "None", nil
+	}
+	t := newMarkdownTable(table.Row{"TABLE", "COLUMN", "EXPRESSION"})
+	for _, r := range res {
+		t.AppendRow(table.Row{r["TABLE_NAME"], r["COLUMN_NAME"], strings.TrimSpace(r["GENERATION_EXPRESSION"])})
+	}
+	return t.RenderMarkdown(), nil
+}
+
+func (a *Assess) assessIndexType() (string, error) {
+	res, err := a.db2.GetDB2SchemaIndexTypeCounts(a.cfg.DB2Config.SchemaName)
+	if err != nil {
+		return "", err
+	}
+	t := newMarkdownTable(table.Row{"INDEX TYPE", "COUNTS", "COMPATIBLE"})
+	for _, r := range res {
+		compatible := "N"
+		if r["INDEX_TYPE"] == "REG" || r["INDEX_TYPE"] == "CLUS" {
+			compatible = "Y"
+		}
+		t.AppendRow(table.Row{r["INDEX_TYPE"], r["COUNTS"], compatible})
+	}
+	return t.RenderMarkdown(), nil
+}
+
+// assessTablespace MySQL/TiDB 无表空间概念，仅供容量规划参考
+func (a *Assess) assessTablespace() (string, error) {
+	res, err := a.db2.GetDB2SchemaTablespace(a.cfg.DB2Config.SchemaName)
+	if err != nil {
+		return "", err
+	}
+	t := newMarkdownTable(table.Row{"TABLESPACE", "TYPE", "DATATYPE", "PAGESIZE", "TABLE COUNTS"})
+	for _, r := range res {
+		t.AppendRow(table.Row{r["TABLESPACE_NAME"], r["TABLESPACE_TYPE"], r["DATATYPE"], r["PAGESIZE"], r["TABLE_COUNTS"]})
+	}
+	return t.RenderMarkdown(), nil
+}
+
+func (a *Assess) assessPartitionTable() (string, error) {
+	res, err := a.db2.GetDB2SchemaPartitionTable(a.cfg.DB2Config.SchemaName)
+	if err != nil {
+		return "", err
+	}
+	if len(res) == 0 {
+		return "None", nil
+	}
+	t := newMarkdownTable(table.Row{"TABLE", "PARTITION COUNTS", "SUGGEST"})
+	for _, r := range res {
+		t.AppendRow(table.Row{r["TABLE_NAME"], r["PARTITION_COUNTS"], "reverse as normal table, manual add range partition"})
+	}
+	return t.RenderMarkdown(), nil
+}
+
+func (a *Assess) assessTableWithoutPK() (string, error) {
+	tables, err := a.db2.GetDB2SchemaTableWithoutPK(a.cfg.DB2Config.SchemaName)
+	if err != nil {
+		return "", err
+	}
+	if len(tables) == 0 {
+		return "None", nil
+	}
+	t := newMarkdownTable(table.Row{"TABLE"})
+	for _, tbl := range tables {
+		t.AppendRow(table.Row{tbl})
+	}
+	return t.RenderMarkdown(), nil
+}
+
+func newMarkdownTable(header table.Row) table.Writer {
+	t := table.NewWriter()
+	t.AppendHeader(header)
+	return t
+}
@@ -41,6 +41,9 @@ func NewAssess(ctx context.Context, cfg *config.Config) (*Assess, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err = oracleDB.EnableDictionaryCache(cfg.OracleConfig.DictionaryCache, cfg.OracleConfig.DictionaryCacheTTL); err != nil {
+		return nil, err
+	}
 	metaDB, err := meta.NewMetaDBEngine(ctx, cfg.MySQLConfig, cfg.AppConfig.SlowlogThreshold)
 	if err != nil {
 		return nil, err
@@ -55,6 +58,11 @@ func NewAssess(ctx context.Context, cfg *config.Config) (*Assess, error) {
 
 func (r *Assess) Assess() error {
 	startTime := time.Now()
+	defer func() {
+		if err := r.oracle.FlushDictionaryCache(); err != nil {
+			zap.L().Warn("flush oracle dictionary cache failed", zap.Error(err))
+		}
+	}()
 	zap.L().Info("assess oracle migrate myoracle cost start",
 		zap.String("oracleSchema", r.cfg.OracleConfig.SchemaName),
 		zap.String("myoracleSchema", r.cfg.MySQLConfig.SchemaName))
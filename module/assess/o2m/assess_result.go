@@ -20,6 +20,8 @@ import (
 	"github.com/wentaojin/transferdb/common"
 	"github.com/wentaojin/transferdb/database/meta"
 	"github.com/wentaojin/transferdb/database/oracle"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -727,6 +729,76 @@ func AssessOracleSchemaTemporaryTableCompatible(schemaName []string, oracle *ora
 	}, nil
 }
 
+func AssessOracleSchemaTemporalTableCompatible(schemaName []string, oracle *oracle.Oracle, objAssessCompsMap map[string]meta.BuildinObjectCompatible) ([]SchemaTemporalTableTypeCompatibles, ReportSummary, error) {
+	oracleDBVersion, err := oracle.GetOracleDBVersion()
+	if err != nil {
+		return nil, ReportSummary{}, err
+	}
+	temporalValidity := false
+	if common.VersionOrdinal(oracleDBVersion) >= common.VersionOrdinal(common.OracleTemporalValidityDBVersion) {
+		temporalValidity = true
+	}
+
+	temporalInfo, err := oracle.GetOracleSchemaTemporalTableTypeCounts(schemaName, temporalValidity)
+	if err != nil {
+		return nil, ReportSummary{}, err
+	}
+
+	if len(temporalInfo) == 0 {
+		return nil, ReportSummary{}, nil
+	}
+
+	var listData []SchemaTemporalTableTypeCompatibles
+	assessComp := 0
+	assessInComp := 0
+	assessConvert := 0
+	assessInConvert := 0
+
+	for _, ow := range temporalInfo {
+		if val, ok := objAssessCompsMap[common.StringUPPER(ow["TEMPORAL_TYPE"])]; ok {
+			listData = append(listData, SchemaTemporalTableTypeCompatibles{
+				Schema:            ow["OWNER"],
+				TemporalTableType: ow["TEMPORAL_TYPE"],
+				ObjectCounts:      ow["COUNTS"],
+				IsCompatible:      val.IsCompatible,
+				IsConvertible:     val.IsConvertible,
+			})
+			if strings.EqualFold(val.IsCompatible, common.AssessYesCompatible) {
+				assessComp += 1
+			}
+			if strings.EqualFold(val.IsCompatible, common.AssessNoCompatible) {
+				assessInComp += 1
+			}
+			if strings.EqualFold(val.IsConvertible, common.AssessYesConvertible) {
+				assessConvert += 1
+			}
+			if strings.EqualFold(val.IsConvertible, common.AssessNoConvertible) {
+				assessInConvert += 1
+			}
+		} else {
+			listData = append(listData, SchemaTemporalTableTypeCompatibles{
+				Schema:            ow["OWNER"],
+				TemporalTableType: ow["TEMPORAL_TYPE"],
+				ObjectCounts:      ow["COUNTS"],
+				IsCompatible:      common.AssessNoCompatible,
+				IsConvertible:     common.AssessYesConvertible,
+			})
+			assessInComp += 1
+			assessConvert += 1
+		}
+	}
+
+	return listData, ReportSummary{
+		AssessType:    common.AssessTypeObjectTypeCompatible,
+		AssessName:    common.AssessNameTemporalTableTypeCompatible,
+		AssessTotal:   len(listData),
+		Compatible:    assessComp,
+		Incompatible:  assessInComp,
+		Convertible:   assessConvert,
+		InConvertible: assessInConvert,
+	}, nil
+}
+
 /*
 Oracle Database Check
 */
@@ -1039,10 +1111,11 @@ func AssessOracleIndexNameLengthCheck(schemaName []string, oracle *oracle.Oracle
 	assessInConvert := 0
 	for _, ow := range synonymInfo {
 		listData = append(listData, SchemaTableIndexNameLengthCheck{
-			Schema:    ow["INDEX_OWNER"],
-			TableName: ow["TABLE_NAME"],
-			IndexName: ow["INDEX_NAME"],
-			Length:    ow["LENGTH_OVER"],
+			Schema:     ow["INDEX_OWNER"],
+			TableName:  ow["TABLE_NAME"],
+			IndexName:  ow["INDEX_NAME"],
+			Length:     ow["LENGTH_OVER"],
+			TargetName: common.TruncateIdentifier(common.StringUPPER(ow["INDEX_NAME"]), common.MySQLIdentifierMaxLength),
 		})
 	}
 
@@ -1435,3 +1508,167 @@ func AssessOracleSchemaTableNumberTypeEqual0(schemaName []string, oracle *oracle
 		InConvertible: assessInConvert,
 	}, nil
 }
+
+func AssessOracleSchemaTablePurgeJob(schemaName []string, oracle *oracle.Oracle) ([]SchemaTablePurgeJob, ReportSummary, error) {
+	jobInfo, err := oracle.GetOracleSchemaTablePurgeJob(schemaName)
+	if err != nil {
+		return nil, ReportSummary{}, err
+	}
+
+	if len(jobInfo) == 0 {
+		return nil, ReportSummary{}, nil
+	}
+
+	// DELETE [FROM] [schema.]table WHERE column < SYSDATE - N
+	tableRegex := regexp.MustCompile(`(?i)DELETE\s+(?:FROM\s+)?(?:"?\w+"?\.)?"?(\w+)"?`)
+	columnRegex := regexp.MustCompile(`(?i)"?(\w+)"?\s*(?:<|<=)\s*(?:TRUNC\s*\(\s*)?(?:SYSDATE|SYSTIMESTAMP)`)
+
+	var listData []SchemaTablePurgeJob
+	for _, ow := range jobInfo {
+		var tableName, columnName string
+		if m := tableRegex.FindStringSubmatch(ow["JOB_ACTION"]); len(m) > 1 {
+			tableName = common.StringUPPER(m[1])
+		}
+		if m := columnRegex.FindStringSubmatch(ow["JOB_ACTION"]); len(m) > 1 {
+			columnName = common.StringUPPER(m[1])
+		}
+		listData = append(listData, SchemaTablePurgeJob{
+			Schema:         ow["OWNER"],
+			JobName:        ow["JOB_NAME"],
+			TableName:      tableName,
+			ColumnName:     columnName,
+			RepeatInterval: ow["REPEAT_INTERVAL"],
+		})
+	}
+
+	return listData, ReportSummary{
+		AssessType:    common.AssessTypeObjectTypeRelated,
+		AssessName:    common.AssessNameSchemaTablePurgeJobRelated,
+		AssessTotal:   len(listData),
+		Compatible:    0,
+		Incompatible:  0,
+		Convertible:   0,
+		InConvertible: 0,
+	}, nil
+}
+
+/*
+Oracle Database Advisory
+*/
+func AssessOracleSchemaLargeTableMigrationAdvisory(schemaName []string, oracle *oracle.Oracle) ([]SchemaLargeTableMigrationAdvisory, ReportSummary, error) {
+	tableInfo, err := oracle.GetOracleSchemaLargeTable(schemaName, common.AssessAdvisoryLargeTableSizeGB)
+	if err != nil {
+		return nil, ReportSummary{}, err
+	}
+
+	if len(tableInfo) == 0 {
+		return nil, ReportSummary{}, nil
+	}
+
+	var listData []SchemaLargeTableMigrationAdvisory
+	for _, ow := range tableInfo {
+		advisory := SchemaLargeTableMigrationAdvisory{
+			Schema:      ow["OWNER"],
+			TableName:   ow["TABLE_NAME"],
+			TableSize:   ow["GB"],
+			TableRows:   ow["NUM_ROWS"],
+			Partitioned: ow["PARTITIONED"],
+		}
+
+		partitionCounts := 0
+		if strings.EqualFold(ow["PARTITIONED"], "YES") {
+			partInfo, err := oracle.GetOracleTablePartitionAdvisory(ow["OWNER"], ow["TABLE_NAME"])
+			if err != nil {
+				return nil, ReportSummary{}, err
+			}
+			if len(partInfo) > 0 {
+				advisory.PartitioningType = partInfo[0]["PARTITIONING_TYPE"]
+				if !strings.EqualFold(partInfo[0]["SUBPARTITIONING_TYPE"], "NONE") {
+					advisory.PartitioningType = fmt.Sprintf("%s-%s", partInfo[0]["PARTITIONING_TYPE"], partInfo[0]["SUBPARTITIONING_TYPE"])
+				}
+				advisory.PartitionCounts = partInfo[0]["PARTITION_COUNT"]
+				advisory.GlobalIndexCounts = partInfo[0]["GLOBAL_INDEX_COUNTS"]
+				partitionCounts, err = strconv.Atoi(partInfo[0]["PARTITION_COUNT"])
+				if err != nil {
+					return nil, ReportSummary{}, fmt.Errorf("oracle table [%s.%s] partition counts [%s] strconv.Atoi failed: %v", ow["OWNER"], ow["TABLE_NAME"], partInfo[0]["PARTITION_COUNT"], err)
+				}
+			}
+		}
+
+		tsInfo, err := oracle.GetOracleTableTablespaceAdvisory(ow["OWNER"], ow["TABLE_NAME"])
+		if err != nil {
+			return nil, ReportSummary{}, err
+		}
+		refCounts, err := oracle.GetOracleTableExternalReferenceCounts(ow["OWNER"], ow["TABLE_NAME"])
+		if err != nil {
+			return nil, ReportSummary{}, err
+		}
+
+		// 传输表空间自包含：表、索引、LOB 所在表空间均为专属的用户永久表空间，且不存在跨表外键
+		var (
+			tablespaces []string
+			reasons     []string
+		)
+		for _, ts := range tsInfo {
+			tablespaces = append(tablespaces, ts["TABLESPACE_NAME"])
+			switch {
+			case common.IsContainString([]string{"SYSTEM", "SYSAUX"}, common.StringUPPER(ts["TABLESPACE_NAME"])):
+				reasons = append(reasons, fmt.Sprintf("tablespace [%s] is system tablespace", ts["TABLESPACE_NAME"]))
+			case !strings.EqualFold(ts["CONTENTS"], "PERMANENT"):
+				reasons = append(reasons, fmt.Sprintf("tablespace [%s] contents is [%s]", ts["TABLESPACE_NAME"], ts["CONTENTS"]))
+			case !strings.EqualFold(ts["FOREIGN_SEGMENTS"], "0"):
+				reasons = append(reasons, fmt.Sprintf("tablespace [%s] contains [%s] segments of other objects", ts["TABLESPACE_NAME"], ts["FOREIGN_SEGMENTS"]))
+			}
+		}
+		if len(tsInfo) == 0 {
+			reasons = append(reasons, "table segments not found")
+		}
+		if refCounts > 0 {
+			reasons = append(reasons, fmt.Sprintf("table has [%d] foreign keys referencing or referenced by other tables", refCounts))
+		}
+		advisory.Tablespaces = strings.Join(tablespaces, ",")
+		if len(reasons) == 0 {
+			advisory.SelfContained = "YES"
+		} else {
+			advisory.SelfContained = "NO"
+		}
+
+		fullName := fmt.Sprintf("%s.%s", ow["OWNER"], ow["TABLE_NAME"])
+		stageName := fmt.Sprintf("%s.%s_STG", ow["OWNER"], ow["TABLE_NAME"])
+		switch {
+		case partitionCounts >= common.AssessAdvisoryPartitionExchangeMinPartitions:
+			advisory.Strategy = common.AssessAdvisoryStrategyPartitionExchange
+			updateIndex := ""
+			if !strings.EqualFold(advisory.GlobalIndexCounts, "0") {
+				updateIndex = " UPDATE GLOBAL INDEXES"
+			}
+			advisory.Plan = []string{
+				fmt.Sprintf("CREATE TABLE %s FOR EXCHANGE WITH TABLE %s (oracle 12.2 and above, else CREATE TABLE AS SELECT WHERE 1 = 0 with the same local indexes)", stageName, fullName),
+				fmt.Sprintf("for each of the %d partitions in a quiesced window: ALTER TABLE %s EXCHANGE PARTITION <partition> WITH TABLE %s INCLUDING INDEXES WITHOUT VALIDATION%s", partitionCounts, fullName, stageName, updateIndex),
+				fmt.Sprintf("extract %s by rowid chunks in parallel without contention on the online table, then exchange the partition back", stageName),
+			}
+		case len(reasons) == 0:
+			advisory.Strategy = common.AssessAdvisoryStrategyTransportableTablespace
+			advisory.Plan = []string{
+				fmt.Sprintf("EXEC DBMS_TTS.TRANSPORT_SET_CHECK('%s', TRUE) and confirm TRANSPORT_SET_VIOLATIONS is empty", advisory.Tablespaces),
+				fmt.Sprintf("ALTER TABLESPACE <tablespace> READ ONLY for tablespaces [%s], expdp TRANSPORT_TABLESPACES=%s, copy the datafiles to the intermediate oracle", advisory.Tablespaces, advisory.Tablespaces),
+				fmt.Sprintf("impdp TRANSPORT_DATAFILES into the intermediate oracle, set the source tablespace back to READ WRITE, then extract %s from the intermediate oracle", fullName),
+			}
+		default:
+			advisory.Strategy = common.AssessAdvisoryStrategyRowIDParallel
+			advisory.Plan = append([]string{"no alternative strategy applicable, extract by rowid chunks in parallel"}, reasons...)
+		}
+
+		listData = append(listData, advisory)
+	}
+
+	return listData, ReportSummary{
+		AssessType:    common.AssessTypeObjectTypeAdvisory,
+		AssessName:    common.AssessNameSchemaLargeTableMigrationAdvisory,
+		AssessTotal:   len(listData),
+		Compatible:    0,
+		Incompatible:  0,
+		Convertible:   0,
+		InConvertible: 0,
+	}, nil
+}
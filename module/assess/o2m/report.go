@@ -34,6 +34,8 @@ type Report struct {
 	*ReportCompatible
 	*ReportCheck
 	*ReportRelated
+	*ReportAdvisory
+	*ReportDashboard
 }
 
 func GetAssessDatabaseReport(ctx context.Context, metaDB *meta.Meta, oracle *oracle.Oracle, schemaName []string, reportName, reportUser, dbTypeS, dbTypeT string) (*Report, error) {
@@ -83,7 +85,17 @@ func GetAssessDatabaseReport(ctx context.Context, metaDB *meta.Meta, oracle *ora
 	convertibleS += relatedS.Convertible
 	inconvertibleS += relatedS.InConvertible
 
-	return &Report{
+	dbAdvisory, advisoryS, err := GetAssessDatabaseAdvisoryResult(schemaName, oracle)
+	if err != nil {
+		return nil, err
+	}
+	assessTotal += advisoryS.AssessTotal
+	compatibleS += advisoryS.Compatible
+	incompatibleS += advisoryS.Incompatible
+	convertibleS += advisoryS.Convertible
+	inconvertibleS += advisoryS.InConvertible
+
+	report := &Report{
 		ReportOverview: dbOverview,
 		ReportSummary: &ReportSummary{
 			AssessTotal:   assessTotal,
@@ -95,7 +107,10 @@ func GetAssessDatabaseReport(ctx context.Context, metaDB *meta.Meta, oracle *ora
 		ReportCompatible: dbCompatibles,
 		ReportCheck:      dbChecks,
 		ReportRelated:    dbRelated,
-	}, nil
+		ReportAdvisory:   dbAdvisory,
+	}
+	report.ReportDashboard = GenAssessDatabaseDashboard(report)
+	return report, nil
 }
 
 func GenNewHTMLReport(report *Report, file *os.File) error {
@@ -120,6 +135,10 @@ func GenNewHTMLReport(report *Report, file *os.File) error {
 		return fmt.Errorf("template FS Execute [report_summary] template HTML failed: %v", err)
 	}
 
+	if err = tf.ExecuteTemplate(file, "report_dashboard", report.ReportDashboard); err != nil {
+		return fmt.Errorf("template FS Execute [report_dashboard] template HTML failed: %v", err)
+	}
+
 	if err = tf.ExecuteTemplate(file, "report_detail", nil); err != nil {
 		return fmt.Errorf("template FS Execute [report_detail] template HTML failed: %v", err)
 	}
@@ -136,6 +155,10 @@ func GenNewHTMLReport(report *Report, file *os.File) error {
 		return fmt.Errorf("template FS Execute [report_related] template HTML failed: %v", err)
 	}
 
+	if err = tf.ExecuteTemplate(file, "report_advisory", report.ReportAdvisory); err != nil {
+		return fmt.Errorf("template FS Execute [report_advisory] template HTML failed: %v", err)
+	}
+
 	if err = tf.ExecuteTemplate(file, "report_footer", nil); err != nil {
 		return fmt.Errorf("template FS Execute [report_footer] template HTML failed: %v", err)
 	}
@@ -0,0 +1,68 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"encoding/json"
+	"github.com/wentaojin/transferdb/database/oracle"
+)
+
+type ReportAdvisory struct {
+	ListSchemaLargeTableMigrationAdvisory []SchemaLargeTableMigrationAdvisory `json:"list_schema_large_table_migration_advisory"`
+}
+
+func (ra *ReportAdvisory) String() string {
+	jsonStr, _ := json.Marshal(ra)
+	return string(jsonStr)
+}
+
+// SchemaLargeTableMigrationAdvisory 大表迁移策略建议，仅输出建议方案，不执行
+type SchemaLargeTableMigrationAdvisory struct {
+	Schema            string   `json:"schema"`
+	TableName         string   `json:"table_name"`
+	TableSize         string   `json:"table_size"`
+	TableRows         string   `json:"table_rows"`
+	Partitioned       string   `json:"partitioned"`
+	PartitioningType  string   `json:"partitioning_type"`
+	PartitionCounts   string   `json:"partition_counts"`
+	GlobalIndexCounts string   `json:"global_index_counts"`
+	Tablespaces       string   `json:"tablespaces"`
+	SelfContained     string   `json:"self_contained"`
+	Strategy          string   `json:"strategy"`
+	Plan              []string `json:"plan"`
+}
+
+func (ro *SchemaLargeTableMigrationAdvisory) String() string {
+	jsonStr, _ := json.Marshal(ro)
+	return string(jsonStr)
+}
+
+func GetAssessDatabaseAdvisoryResult(schemaName []string, oracle *oracle.Oracle) (*ReportAdvisory, *ReportSummary, error) {
+	ListSchemaLargeTableMigrationAdvisory, advisorySummary, err := AssessOracleSchemaLargeTableMigrationAdvisory(schemaName, oracle)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &ReportAdvisory{
+		ListSchemaLargeTableMigrationAdvisory: ListSchemaLargeTableMigrationAdvisory,
+	}, &ReportSummary{
+		AssessTotal:   advisorySummary.AssessTotal,
+		Compatible:    advisorySummary.Compatible,
+		Incompatible:  advisorySummary.Incompatible,
+		Convertible:   advisorySummary.Convertible,
+		InConvertible: advisorySummary.InConvertible,
+	}, nil
+}
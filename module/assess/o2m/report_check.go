@@ -131,10 +131,11 @@ func (ro *SchemaTableColumnNameLengthCheck) String() string {
 }
 
 type SchemaTableIndexNameLengthCheck struct {
-	Schema    string `json:"schema"`
-	TableName string `json:"table_name"`
-	IndexName string `json:"index_name"`
-	Length    string `json:"length"`
+	Schema     string `json:"schema"`
+	TableName  string `json:"table_name"`
+	IndexName  string `json:"index_name"`
+	Length     string `json:"length"`
+	TargetName string `json:"target_name"` // reverse 截断后目标端索引名
 }
 
 func (ro *SchemaTableIndexNameLengthCheck) String() string {
@@ -34,6 +34,7 @@ type ReportCompatible struct {
 	ListSchemaPartitionTypeCompatibles      []SchemaPartitionTypeCompatibles      `json:"list_schema_partition_type_compatibles"`
 	ListSchemaSubPartitionTypeCompatibles   []SchemaSubPartitionTypeCompatibles   `json:"list_schema_sub_partition_type_compatibles"`
 	ListSchemaTemporaryTableTypeCompatibles []SchemaTemporaryTableTypeCompatibles `json:"list_schema_temporary_table_type_compatibles"`
+	ListSchemaTemporalTableTypeCompatibles  []SchemaTemporalTableTypeCompatibles  `json:"list_schema_temporal_table_type_compatibles"`
 }
 
 func (sc *ReportCompatible) String() string {
@@ -175,6 +176,19 @@ func (sc *SchemaTemporaryTableTypeCompatibles) String() string {
 	return string(jsonStr)
 }
 
+type SchemaTemporalTableTypeCompatibles struct {
+	Schema            string `json:"schema"`
+	TemporalTableType string `json:"temporal_table_type"`
+	ObjectCounts      string `json:"object_counts"`
+	IsCompatible      string `json:"is_compatible"`
+	IsConvertible     string `json:"is_convertible"`
+}
+
+func (sc *SchemaTemporalTableTypeCompatibles) String() string {
+	jsonStr, _ := json.Marshal(sc)
+	return string(jsonStr)
+}
+
 /*
 Oracle Database Compatible
 */
@@ -190,6 +204,7 @@ func GetAssessDatabaseCompatibleResult(ctx context.Context, metaDB *meta.Meta, o
 		ListSchemaPartitionTypeCompatibles      []SchemaPartitionTypeCompatibles
 		ListSchemaSubPartitionTypeCompatibles   []SchemaSubPartitionTypeCompatibles
 		ListSchemaTemporaryTableTypeCompatibles []SchemaTemporaryTableTypeCompatibles
+		ListSchemaTemporalTableTypeCompatibles  []SchemaTemporalTableTypeCompatibles
 	)
 
 	// 获取自定义兼容性内容
@@ -338,6 +353,16 @@ func GetAssessDatabaseCompatibleResult(ctx context.Context, metaDB *meta.Meta, o
 	convertibleS += tempSummary.Convertible
 	inconvertibleS += tempSummary.InConvertible
 
+	ListSchemaTemporalTableTypeCompatibles, temporalSummary, err := AssessOracleSchemaTemporalTableCompatible(schemaName, oracle, objAssessCompsMap)
+	if err != nil {
+		return nil, nil, err
+	}
+	assessTotal += temporalSummary.AssessTotal
+	compatibleS += temporalSummary.Compatible
+	incompatibleS += temporalSummary.Incompatible
+	convertibleS += temporalSummary.Convertible
+	inconvertibleS += temporalSummary.InConvertible
+
 	return &ReportCompatible{
 			ListSchemaTableTypeCompatibles:          ListSchemaTableTypeCompatibles,
 			ListSchemaColumnTypeCompatibles:         ListSchemaColumnTypeCompatibles,
@@ -349,6 +374,7 @@ func GetAssessDatabaseCompatibleResult(ctx context.Context, metaDB *meta.Meta, o
 			ListSchemaPartitionTypeCompatibles:      ListSchemaPartitionTypeCompatibles,
 			ListSchemaSubPartitionTypeCompatibles:   ListSchemaSubPartitionTypeCompatibles,
 			ListSchemaTemporaryTableTypeCompatibles: ListSchemaTemporaryTableTypeCompatibles,
+			ListSchemaTemporalTableTypeCompatibles:  ListSchemaTemporalTableTypeCompatibles,
 		}, &ReportSummary{
 			AssessTotal:   assessTotal,
 			Compatible:    compatibleS,
@@ -0,0 +1,316 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ReportDashboard 报告首页图表汇总，基于各评估项结果生成，不额外查询数据字典
+type ReportDashboard struct {
+	SummaryChart        []DashboardBar       `json:"summary_chart"`
+	ObjectCountsChart   []DashboardBar       `json:"object_counts_chart"`
+	SchemaSizeChart     []DashboardBar       `json:"schema_size_chart"`
+	RiskLevelChart      []DashboardBar       `json:"risk_level_chart"`
+	ListIncompatibles   []DashboardIncompat  `json:"list_incompatibles"`
+	ListSchemaTableRisk []DashboardTableRisk `json:"list_schema_table_risk"`
+	EstimateTotalSize   string               `json:"estimate_total_size"`
+}
+
+func (rd *ReportDashboard) String() string {
+	jsonStr, _ := json.Marshal(rd)
+	return string(jsonStr)
+}
+
+// DashboardBar 条形图单项，Percent 为相对最大值百分比
+type DashboardBar struct {
+	Label   string `json:"label"`
+	Value   string `json:"value"`
+	Percent int    `json:"percent"`
+	Color   string `json:"color"`
+}
+
+type DashboardIncompat struct {
+	Category      string `json:"category"`
+	Schema        string `json:"schema"`
+	ObjectType    string `json:"object_type"`
+	ObjectCounts  string `json:"object_counts"`
+	IsConvertible string `json:"is_convertible"`
+}
+
+type DashboardTableRisk struct {
+	Schema    string   `json:"schema"`
+	TableName string   `json:"table_name"`
+	RiskScore int      `json:"risk_score"`
+	RiskLevel string   `json:"risk_level"`
+	Reasons   []string `json:"reasons"`
+}
+
+func (ro *DashboardTableRisk) String() string {
+	jsonStr, _ := json.Marshal(ro)
+	return string(jsonStr)
+}
+
+type dashboardItem struct {
+	label string
+	value float64
+	color string
+}
+
+// genDashboardBars 以最大值为 100% 计算条形宽度
+func genDashboardBars(items []dashboardItem, precision int) []DashboardBar {
+	var maxVal float64
+	for _, it := range items {
+		if it.value > maxVal {
+			maxVal = it.value
+		}
+	}
+	var bars []DashboardBar
+	for _, it := range items {
+		percent := 0
+		if maxVal > 0 {
+			percent = int(it.value / maxVal * 100)
+		}
+		color := it.color
+		if color == "" {
+			color = "#0066cc"
+		}
+		bars = append(bars, DashboardBar{
+			Label:   it.label,
+			Value:   strconv.FormatFloat(it.value, 'f', precision, 64),
+			Percent: percent,
+			Color:   color,
+		})
+	}
+	return bars
+}
+
+func parseDashboardFloat(s string) float64 {
+	val, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0
+	}
+	return val
+}
+
+// GenAssessDatabaseDashboard 汇总对象数、不兼容类型、预估大小以及单表风险
+func GenAssessDatabaseDashboard(report *Report) *ReportDashboard {
+	dashboard := &ReportDashboard{}
+
+	if report.ReportSummary != nil {
+		dashboard.SummaryChart = genDashboardBars([]dashboardItem{
+			{label: "COMPATIBLES", value: float64(report.Compatible), color: "#009900"},
+			{label: "InCOMPATIBLES", value: float64(report.Incompatible), color: "#ff0000"},
+			{label: "CONVERTIBLES", value: float64(report.Convertible), color: "#0066cc"},
+			{label: "InCONVERTIBLES", value: float64(report.InConvertible), color: "#990000"},
+		}, 0)
+	}
+
+	// 对象数统计
+	objectCounts := make(map[string]float64)
+	if report.ReportCompatible != nil {
+		for _, c := range report.ListSchemaTableTypeCompatibles {
+			objectCounts["TABLE"] += parseDashboardFloat(c.ObjectCounts)
+			if c.IsCompatible == common.AssessNoCompatible {
+				dashboard.ListIncompatibles = append(dashboard.ListIncompatibles, DashboardIncompat{
+					Category: "TABLE TYPE", Schema: c.Schema, ObjectType: c.TableType, ObjectCounts: c.ObjectCounts, IsConvertible: c.IsConvertible})
+			}
+		}
+		for _, c := range report.ListSchemaColumnTypeCompatibles {
+			if c.IsEquivalent == common.AssessNoEquivalent {
+				dashboard.ListIncompatibles = append(dashboard.ListIncompatibles, DashboardIncompat{
+					Category: "COLUMN TYPE", Schema: c.Schema, ObjectType: common.StringsBuilder(c.ColumnType, " -> ", c.ColumnTypeMap), ObjectCounts: c.ObjectCounts, IsConvertible: common.AssessYesConvertible})
+			}
+		}
+		for _, c := range report.ListSchemaConstraintTypeCompatibles {
+			objectCounts["CONSTRAINT"] += parseDashboardFloat(c.ObjectCounts)
+			if c.IsCompatible == common.AssessNoCompatible {
+				dashboard.ListIncompatibles = append(dashboard.ListIncompatibles, DashboardIncompat{
+					Category: "CONSTRAINT TYPE", Schema: c.Schema, ObjectType: c.ConstraintType, ObjectCounts: c.ObjectCounts, IsConvertible: c.IsConvertible})
+			}
+		}
+		for _, c := range report.ListSchemaIndexTypeCompatibles {
+			objectCounts["INDEX"] += parseDashboardFloat(c.ObjectCounts)
+			if c.IsCompatible == common.AssessNoCompatible {
+				dashboard.ListIncompatibles = append(dashboard.ListIncompatibles, DashboardIncompat{
+					Category: "INDEX TYPE", Schema: c.Schema, ObjectType: c.IndexType, ObjectCounts: c.ObjectCounts, IsConvertible: c.IsConvertible})
+			}
+		}
+		for _, c := range report.ListSchemaDefaultValueCompatibles {
+			if c.IsCompatible == common.AssessNoCompatible {
+				dashboard.ListIncompatibles = append(dashboard.ListIncompatibles, DashboardIncompat{
+					Category: "DEFAULT VALUE", Schema: c.Schema, ObjectType: c.ColumnDefaultValue, ObjectCounts: c.ObjectCounts, IsConvertible: c.IsConvertible})
+			}
+		}
+		for _, c := range report.ListSchemaViewTypeCompatibles {
+			objectCounts["VIEW"] += parseDashboardFloat(c.ObjectCounts)
+			if c.IsCompatible == common.AssessNoCompatible {
+				dashboard.ListIncompatibles = append(dashboard.ListIncompatibles, DashboardIncompat{
+					Category: "VIEW TYPE", Schema: c.Schema, ObjectType: c.ViewType, ObjectCounts: c.ObjectCounts, IsConvertible: c.IsConvertible})
+			}
+		}
+		for _, c := range report.ListSchemaObjectTypeCompatibles {
+			objectCounts[c.ObjectType] += parseDashboardFloat(c.ObjectCounts)
+			if c.IsCompatible == common.AssessNoCompatible {
+				dashboard.ListIncompatibles = append(dashboard.ListIncompatibles, DashboardIncompat{
+					Category: "OBJECT TYPE", Schema: c.Schema, ObjectType: c.ObjectType, ObjectCounts: c.ObjectCounts, IsConvertible: c.IsConvertible})
+			}
+		}
+		for _, c := range report.ListSchemaPartitionTypeCompatibles {
+			if c.IsCompatible == common.AssessNoCompatible {
+				dashboard.ListIncompatibles = append(dashboard.ListIncompatibles, DashboardIncompat{
+					Category: "PARTITION TYPE", Schema: c.Schema, ObjectType: c.PartitionType, ObjectCounts: c.ObjectCounts, IsConvertible: c.IsConvertible})
+			}
+		}
+		for _, c := range report.ListSchemaSubPartitionTypeCompatibles {
+			if c.IsCompatible == common.AssessNoCompatible {
+				dashboard.ListIncompatibles = append(dashboard.ListIncompatibles, DashboardIncompat{
+					Category: "SUBPARTITION TYPE", Schema: c.Schema, ObjectType: c.SubPartitionType, ObjectCounts: c.ObjectCounts, IsConvertible: c.IsConvertible})
+			}
+		}
+		for _, c := range report.ListSchemaTemporaryTableTypeCompatibles {
+			if c.IsCompatible == common.AssessNoCompatible {
+				dashboard.ListIncompatibles = append(dashboard.ListIncompatibles, DashboardIncompat{
+					Category: "TEMPORARY TABLE", Schema: c.Schema, ObjectType: c.TemporaryTableType, ObjectCounts: c.ObjectCounts, IsConvertible: c.IsConvertible})
+			}
+		}
+		for _, c := range report.ListSchemaTemporalTableTypeCompatibles {
+			if c.IsCompatible == common.AssessNoCompatible {
+				dashboard.ListIncompatibles = append(dashboard.ListIncompatibles, DashboardIncompat{
+					Category: "TEMPORAL TABLE", Schema: c.Schema, ObjectType: c.TemporalTableType, ObjectCounts: c.ObjectCounts, IsConvertible: c.IsConvertible})
+			}
+		}
+	}
+	var objectTypes []string
+	for k := range objectCounts {
+		objectTypes = append(objectTypes, k)
+	}
+	sort.Strings(objectTypes)
+	var objectItems []dashboardItem
+	for _, k := range objectTypes {
+		objectItems = append(objectItems, dashboardItem{label: k, value: objectCounts[k]})
+	}
+	dashboard.ObjectCountsChart = genDashboardBars(objectItems, 0)
+
+	// 预估迁移大小，表、索引、LOB 段合计，单位 GB
+	if report.ReportRelated != nil {
+		var (
+			sizeItems []dashboardItem
+			totalSize float64
+		)
+		for _, s := range report.ListSchemaTableSizeData {
+			size := parseDashboardFloat(s.TableSize) + parseDashboardFloat(s.IndexSize) +
+				parseDashboardFloat(s.LobTableSize) + parseDashboardFloat(s.LobIndexSize)
+			totalSize += size
+			sizeItems = append(sizeItems, dashboardItem{label: s.Schema, value: size})
+		}
+		dashboard.SchemaSizeChart = genDashboardBars(sizeItems, 2)
+		dashboard.EstimateTotalSize = strconv.FormatFloat(totalSize, 'f', 2, 64)
+	}
+
+	dashboard.ListSchemaTableRisk = genDashboardTableRisk(report)
+
+	riskLevels := make(map[string]float64)
+	for _, r := range dashboard.ListSchemaTableRisk {
+		riskLevels[r.RiskLevel]++
+	}
+	dashboard.RiskLevelChart = genDashboardBars([]dashboardItem{
+		{label: common.AssessDashboardRiskHigh, value: riskLevels[common.AssessDashboardRiskHigh], color: "#ff0000"},
+		{label: common.AssessDashboardRiskMedium, value: riskLevels[common.AssessDashboardRiskMedium], color: "#ff9900"},
+		{label: common.AssessDashboardRiskLow, value: riskLevels[common.AssessDashboardRiskLow], color: "#009900"},
+	}, 0)
+
+	return dashboard
+}
+
+// genDashboardTableRisk 按检查项、相关项、建议项命中情况累计单表风险分值
+func genDashboardTableRisk(report *Report) []DashboardTableRisk {
+	risks := make(map[string]*DashboardTableRisk)
+	hit := func(schema, table string, score int, reason string) {
+		key := common.StringsBuilder(schema, ".", table)
+		if _, ok := risks[key]; !ok {
+			risks[key] = &DashboardTableRisk{Schema: schema, TableName: table}
+		}
+		risks[key].RiskScore += score
+		risks[key].Reasons = append(risks[key].Reasons, reason)
+	}
+
+	if report.ReportCheck != nil {
+		for _, c := range report.ListSchemaPartitionTableCountsCheck {
+			hit(c.Schema, c.TableName, 3, fmt.Sprintf("partition counts %s over 1024", c.PartitionCounts))
+		}
+		for _, c := range report.ListSchemaTableRowLengthCheck {
+			hit(c.Schema, c.TableName, 3, fmt.Sprintf("avg row length %s over 6MB", c.AvgRowLength))
+		}
+		for _, c := range report.ListSchemaTableIndexRowLengthCheck {
+			hit(c.Schema, c.TableName, 2, fmt.Sprintf("index [%s] length %s over 3072", c.IndexName, c.ColumnLength))
+		}
+		for _, c := range report.ListSchemaTableColumnCountsCheck {
+			hit(c.Schema, c.TableName, 3, fmt.Sprintf("column counts %s over 512", c.ColumnCounts))
+		}
+		for _, c := range report.ListSchemaIndexCountsCheck {
+			hit(c.Schema, c.TableName, 1, fmt.Sprintf("index counts %s over 64", c.IndexCounts))
+		}
+		for _, c := range report.ListSchemaTableNameLengthCheck {
+			hit(c.Schema, c.TableName, 1, fmt.Sprintf("table name length %s over 64", c.Length))
+		}
+		for _, c := range report.ListSchemaTableColumnNameLengthCheck {
+			hit(c.Schema, c.TableName, 1, fmt.Sprintf("column [%s] name length %s over 64", c.ColumnName, c.Length))
+		}
+		for _, c := range report.ListSchemaTableIndexNameLengthCheck {
+			hit(c.Schema, c.TableName, 1, fmt.Sprintf("index [%s] name length %s over 64", c.IndexName, c.Length))
+		}
+	}
+	if report.ReportRelated != nil {
+		for _, c := range report.ListSchemaTableNumberTypeEqual0 {
+			hit(c.Schema, c.TableName, 1, fmt.Sprintf("column [%s] number precision/scale unspecified", c.ColumnName))
+		}
+		for _, c := range report.ListSchemaTablePurgeJob {
+			hit(c.Schema, c.TableName, 1, fmt.Sprintf("purge job [%s]", c.JobName))
+		}
+	}
+	if report.ReportAdvisory != nil {
+		for _, c := range report.ListSchemaLargeTableMigrationAdvisory {
+			hit(c.Schema, c.TableName, 2, fmt.Sprintf("large table %s GB, strategy [%s]", c.TableSize, c.Strategy))
+		}
+	}
+
+	var tableRisks []DashboardTableRisk
+	for _, r := range risks {
+		switch {
+		case r.RiskScore >= common.AssessDashboardRiskHighScore:
+			r.RiskLevel = common.AssessDashboardRiskHigh
+		case r.RiskScore >= common.AssessDashboardRiskMediumScore:
+			r.RiskLevel = common.AssessDashboardRiskMedium
+		default:
+			r.RiskLevel = common.AssessDashboardRiskLow
+		}
+		tableRisks = append(tableRisks, *r)
+	}
+	sort.Slice(tableRisks, func(i, j int) bool {
+		if tableRisks[i].RiskScore != tableRisks[j].RiskScore {
+			return tableRisks[i].RiskScore > tableRisks[j].RiskScore
+		}
+		return common.StringsBuilder(tableRisks[i].Schema, ".", tableRisks[i].TableName) <
+			common.StringsBuilder(tableRisks[j].Schema, ".", tableRisks[j].TableName)
+	})
+	return tableRisks
+}
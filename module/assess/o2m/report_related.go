@@ -29,6 +29,7 @@ type ReportRelated struct {
 	ListSchemaMaterializedViewObject []SchemaMaterializedViewObject `json:"list_schema_materialized_view_object"`
 	ListSchemaTableAvgRowLengthTOP   []SchemaTableAvgRowLengthTOP   `json:"list_schema_table_avg_row_length_top"`
 	ListSchemaTableNumberTypeEqual0  []SchemaTableNumberTypeEqual0  `json:"list_schema_table_number_type_equal_0"`
+	ListSchemaTablePurgeJob          []SchemaTablePurgeJob          `json:"list_schema_table_purge_job"`
 }
 
 func (rr *ReportRelated) String() string {
@@ -138,6 +139,19 @@ func (ro *SchemaTableNumberTypeEqual0) String() string {
 	return string(jsonStr)
 }
 
+type SchemaTablePurgeJob struct {
+	Schema         string `json:"schema"`
+	JobName        string `json:"job_name"`
+	TableName      string `json:"table_name"`
+	ColumnName     string `json:"column_name"`
+	RepeatInterval string `json:"repeat_interval"`
+}
+
+func (ro *SchemaTablePurgeJob) String() string {
+	jsonStr, _ := json.Marshal(ro)
+	return string(jsonStr)
+}
+
 /*
 Oracle Database Related
 */
@@ -151,6 +165,7 @@ func GetAssessDatabaseRelatedResult(schemaName []string, oracle *oracle.Oracle)
 		ListSchemaMaterializedViewObject []SchemaMaterializedViewObject
 		ListSchemaTableAvgRowLengthTOP   []SchemaTableAvgRowLengthTOP
 		ListSchemaTableNumberTypeEqual0  []SchemaTableNumberTypeEqual0
+		ListSchemaTablePurgeJob          []SchemaTablePurgeJob
 	)
 
 	assessTotal := 0
@@ -239,6 +254,16 @@ func GetAssessDatabaseRelatedResult(schemaName []string, oracle *oracle.Oracle)
 	convertibleS += equalSummary.Convertible
 	inconvertibleS += equalSummary.InConvertible
 
+	ListSchemaTablePurgeJob, purgeSummary, err := AssessOracleSchemaTablePurgeJob(schemaName, oracle)
+	if err != nil {
+		return nil, nil, err
+	}
+	assessTotal += purgeSummary.AssessTotal
+	compatibleS += purgeSummary.Compatible
+	incompatibleS += purgeSummary.Incompatible
+	convertibleS += purgeSummary.Convertible
+	inconvertibleS += purgeSummary.InConvertible
+
 	return &ReportRelated{
 			ListSchemaActiveSession:          ListSchemaActiveSession,
 			ListSchemaTableSizeData:          ListSchemaTableSizeData,
@@ -248,6 +273,7 @@ func GetAssessDatabaseRelatedResult(schemaName []string, oracle *oracle.Oracle)
 			ListSchemaMaterializedViewObject: ListSchemaMaterializedViewObject,
 			ListSchemaTableAvgRowLengthTOP:   ListSchemaTableAvgRowLengthTOP,
 			ListSchemaTableNumberTypeEqual0:  ListSchemaTableNumberTypeEqual0,
+			ListSchemaTablePurgeJob:          ListSchemaTablePurgeJob,
 		}, &ReportSummary{
 			AssessTotal:   assessTotal,
 			Compatible:    compatibleS,
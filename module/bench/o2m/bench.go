@@ -0,0 +1,206 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/mysql"
+	"github.com/wentaojin/transferdb/database/oracle"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+type Bench struct {
+	ctx    context.Context
+	cfg    *config.Config
+	oracle *oracle.Oracle
+	mysql  *mysql.MySQL
+}
+
+func NewBench(ctx context.Context, cfg *config.Config) (*Bench, error) {
+	oracleDB, err := oracle.NewOracleDBEngine(ctx, cfg.OracleConfig)
+	if err != nil {
+		return nil, err
+	}
+	mysqlDB, err := mysql.NewMySQLDBEngine(ctx, cfg.MySQLConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &Bench{
+		ctx:    ctx,
+		cfg:    cfg,
+		oracle: oracleDB,
+		mysql:  mysqlDB,
+	}, nil
+}
+
+func (r *Bench) Bench() error {
+	startTime := time.Now()
+	zap.L().Info("bench oracle and mysql start",
+		zap.String("oracleSchema", r.cfg.OracleConfig.SchemaName),
+		zap.String("mysqlSchema", r.cfg.MySQLConfig.SchemaName))
+
+	if r.cfg.BenchConfig.SourceTable != "" {
+		if err := r.benchSourceRead(); err != nil {
+			return err
+		}
+	}
+	if r.cfg.BenchConfig.TargetTable != "" && r.cfg.BenchConfig.WriteRows > 0 {
+		if err := r.benchTargetWrite(); err != nil {
+			return err
+		}
+	}
+
+	zap.L().Info("bench oracle and mysql finished",
+		zap.String("oracleSchema", r.cfg.OracleConfig.SchemaName),
+		zap.String("mysqlSchema", r.cfg.MySQLConfig.SchemaName),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
+
+// benchSourceRead 上游按 ROWID 切分并发全表读取
+func (r *Bench) benchSourceRead() error {
+	schemaName := common.StringUPPER(r.cfg.OracleConfig.SchemaName)
+	tableName := common.StringUPPER(r.cfg.BenchConfig.SourceTable)
+	taskName := common.StringsBuilder("BENCH_", tableName)
+
+	if err := r.oracle.StartOracleChunkCreateTask(taskName); err != nil {
+		return err
+	}
+	defer func() {
+		if err := r.oracle.CloseOracleChunkTask(taskName); err != nil {
+			zap.L().Warn("bench source close chunk task failed", zap.String("task", taskName), zap.Error(err))
+		}
+	}()
+	if err := r.oracle.StartOracleCreateChunkByRowID(taskName, schemaName, tableName, strconv.Itoa(r.cfg.BenchConfig.ChunkSize)); err != nil {
+		return err
+	}
+	chunks, err := r.oracle.GetOracleTableChunksByRowID(taskName)
+	if err != nil {
+		return err
+	}
+	if len(chunks) == 0 {
+		chunks = append(chunks, map[string]string{"CMD": "1 = 1"})
+	}
+
+	var totalRows, totalBytes int64
+	startTime := time.Now()
+
+	g := &errgroup.Group{}
+	g.SetLimit(r.cfg.BenchConfig.ReadThreads)
+	for _, chunk := range chunks {
+		querySQL := common.StringsBuilder(`SELECT * FROM `, schemaName, `.`, tableName, ` WHERE `, chunk["CMD"])
+		g.Go(func() error {
+			rows, bytes, err := r.oracle.GetOracleTableChunkReadBytes(querySQL)
+			if err != nil {
+				return fmt.Errorf("bench source read sql [%s] failed: %v", querySQL, err)
+			}
+			atomic.AddInt64(&totalRows, rows)
+			atomic.AddInt64(&totalBytes, bytes)
+			return nil
+		})
+	}
+	if err = g.Wait(); err != nil {
+		return err
+	}
+
+	cost := time.Now().Sub(startTime)
+	zap.L().Info("bench source read result",
+		zap.String("schema", schemaName),
+		zap.String("table", tableName),
+		zap.Int("chunks", len(chunks)),
+		zap.Int("read threads", r.cfg.BenchConfig.ReadThreads),
+		zap.Int64("rows", totalRows),
+		zap.Int64("bytes", totalBytes),
+		zap.String("MB/s", benchRate(float64(totalBytes)/1024/1024, cost)),
+		zap.String("rows/s", benchRate(float64(totalRows), cost)),
+		zap.String("cost", cost.String()))
+	return nil
+}
+
+// benchTargetWrite 下游合成数据批量写入临时测试表
+func (r *Bench) benchTargetWrite() error {
+	schemaName := common.StringUPPER(r.cfg.MySQLConfig.SchemaName)
+	tableName := common.StringUPPER(r.cfg.BenchConfig.TargetTable)
+	rowBytes := r.cfg.BenchConfig.RowBytes
+	batchSize := r.cfg.BenchConfig.BatchSize
+
+	if err := r.mysql.WriteMySQLDDL(fmt.Sprintf("CREATE TABLE %s.%s (ID BIGINT NOT NULL PRIMARY KEY, PAYLOAD VARCHAR(%d))", schemaName, tableName, rowBytes)); err != nil {
+		return err
+	}
+	defer func() {
+		if err := r.mysql.WriteMySQLDDL(fmt.Sprintf("DROP TABLE IF EXISTS %s.%s", schemaName, tableName)); err != nil {
+			zap.L().Warn("bench target drop table failed", zap.String("table", tableName), zap.Error(err))
+		}
+	}()
+
+	payload := strings.Repeat("x", rowBytes)
+	var totalRows, totalBytes int64
+	startTime := time.Now()
+
+	g := &errgroup.Group{}
+	g.SetLimit(r.cfg.BenchConfig.WriteThreads)
+	for begin := 0; begin < r.cfg.BenchConfig.WriteRows; begin += batchSize {
+		end := begin + batchSize
+		if end > r.cfg.BenchConfig.WriteRows {
+			end = r.cfg.BenchConfig.WriteRows
+		}
+		b, e := begin, end
+		g.Go(func() error {
+			var values []string
+			for id := b; id < e; id++ {
+				values = append(values, common.StringsBuilder("(", strconv.Itoa(id), ",'", payload, "')"))
+			}
+			insertSQL := common.StringsBuilder("INSERT INTO ", schemaName, ".", tableName, " (ID,PAYLOAD) VALUES ", strings.Join(values, ","))
+			if err := r.mysql.WriteMySQLTable(insertSQL); err != nil {
+				return err
+			}
+			atomic.AddInt64(&totalRows, int64(e-b))
+			atomic.AddInt64(&totalBytes, int64(len(insertSQL)))
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	cost := time.Now().Sub(startTime)
+	zap.L().Info("bench target write result",
+		zap.String("schema", schemaName),
+		zap.String("table", tableName),
+		zap.Int("write threads", r.cfg.BenchConfig.WriteThreads),
+		zap.Int("batch size", batchSize),
+		zap.Int64("rows", totalRows),
+		zap.Int64("bytes", totalBytes),
+		zap.String("MB/s", benchRate(float64(totalBytes)/1024/1024, cost)),
+		zap.String("rows/s", benchRate(float64(totalRows), cost)),
+		zap.String("cost", cost.String()))
+	return nil
+}
+
+func benchRate(value float64, cost time.Duration) string {
+	if cost.Seconds() == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(value/cost.Seconds(), 'f', 2, 64)
+}
@@ -48,6 +48,9 @@ func NewCheck(ctx context.Context, cfg *config.Config) (*Check, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err = oracleDB.EnableDictionaryCache(cfg.OracleConfig.DictionaryCache, cfg.OracleConfig.DictionaryCacheTTL); err != nil {
+		return nil, err
+	}
 	mysqlDB, err := mysql.NewMySQLDBEngine(ctx, cfg.MySQLConfig)
 	if err != nil {
 		return nil, err
@@ -63,11 +66,16 @@ func NewCheck(ctx context.Context, cfg *config.Config) (*Check, error) {
 
 func (r *Check) Check() error {
 	startTime := time.Now()
+	defer func() {
+		if err := r.oracle.FlushDictionaryCache(); err != nil {
+			zap.L().Warn("flush oracle dictionary cache failed", zap.Error(err))
+		}
+	}()
 	zap.L().Info("check oracle and mysql table start",
 		zap.String("oracleSchema", r.cfg.OracleConfig.SchemaName),
 		zap.String("mysqlSchema", r.cfg.MySQLConfig.SchemaName))
 
-	tablesByCfg, err := filterCFGTable(r.cfg, r.oracle)
+	tablesByCfg, err := filterCFGTable(r.ctx, r.cfg, r.oracle, r.metaDB)
 	if err != nil {
 		return err
 	}
@@ -240,26 +248,27 @@ func (r *Check) Check() error {
 	}
 
 	// 获取表名自定义规则
-	tableNameRules, err := meta.NewTableNameRuleModel(r.metaDB).DetailTableNameRule(r.ctx, &meta.TableNameRule{
+	tableRewriter, err := r.cfg.RewriteConfig.TableRewriter()
+	if err != nil {
+		return err
+	}
+	sourceTables, err := r.oracle.GetOracleSchemaTable(common.StringUPPER(r.cfg.OracleConfig.SchemaName))
+	if err != nil {
+		return err
+	}
+	tableNameRuleMap, err := meta.NewTableNameRuleModel(r.metaDB).DetailTableNameRuleMap(r.ctx, &meta.TableNameRule{
 		DBTypeS:     r.cfg.DBTypeS,
 		DBTypeT:     r.cfg.DBTypeT,
 		SchemaNameS: r.cfg.OracleConfig.SchemaName,
 		SchemaNameT: r.cfg.MySQLConfig.SchemaName,
-	})
+	}, sourceTables, tableRewriter)
 	if err != nil {
 		return err
 	}
-	tableNameRuleMap := make(map[string]string)
-
-	if len(tableNameRules) > 0 {
-		for _, tr := range tableNameRules {
-			tableNameRuleMap[common.StringUPPER(tr.TableNameS)] = common.StringUPPER(tr.TableNameT)
-		}
-	}
 
 	// 任务检查表
 	tasks := GenCheckTaskTable(r.cfg.OracleConfig.SchemaName, r.cfg.MySQLConfig.SchemaName, oracleDBCharacterSet,
-		nlsSort, nlsComp, oracleTableCollation, oracleSchemaCollation, oracleDBCollation,
+		nlsSort, nlsComp, oracleTableCollation, oracleSchemaCollation, oracleDBCollation, r.cfg.CheckConfig.CheckTrigger,
 		r.cfg.MySQLConfig.DBType, r.oracle, r.mysql, tableNameRuleMap, waitSyncMetas)
 
 	err = common.PathExist(r.cfg.CheckConfig.CheckSQLDir)
@@ -303,7 +312,7 @@ func (r *Check) Check() error {
 				return err
 			}
 			err = NewChecker(r.ctx, oracleTableInfo, mysqlTableInfo,
-				r.cfg.DBTypeS, r.cfg.DBTypeT, mysqlDBVersion, r.cfg.MySQLConfig.DBType, r.metaDB).Writer(f)
+				r.cfg.DBTypeS, r.cfg.DBTypeT, mysqlDBVersion, r.cfg.MySQLConfig.DBType, r.cfg.CheckConfig, r.metaDB).Writer(f)
 			if err != nil {
 				// skip error and continue
 				errMeta := meta.NewCommonModel(r.metaDB).CreateErrorDetailAndUpdateWaitSyncMetaTaskStatus(r.ctx, &meta.ErrorLogDetail{
@@ -354,6 +363,13 @@ func (r *Check) Check() error {
 		return err
 	}
 
+	// 修复 SQL 按依赖顺序执行
+	if r.cfg.CheckConfig.EnableFix {
+		if err = r.applyFix(checkFile); err != nil {
+			return err
+		}
+	}
+
 	// 任务详情
 	succTotals, err := meta.NewWaitSyncMetaModel(r.metaDB).DetailWaitSyncMeta(r.ctx, &meta.WaitSyncMeta{
 		DBTypeS:     r.cfg.DBTypeS,
@@ -384,11 +400,21 @@ func (r *Check) Check() error {
 			zap.Int("table failed", 0),
 			zap.String("cost", time.Now().Sub(startTime).String()))
 	} else {
+		suggestions, err := meta.NewErrorLogDetailModel(r.metaDB).DistinctErrorSuggestion(r.ctx, &meta.ErrorLogDetail{
+			DBTypeS:     r.cfg.DBTypeS,
+			DBTypeT:     r.cfg.DBTypeT,
+			SchemaNameS: common.StringUPPER(r.cfg.OracleConfig.SchemaName),
+			TaskMode:    r.cfg.TaskMode,
+		})
+		if err != nil {
+			return err
+		}
 		zap.L().Warn("check table oracle to mysql finished",
 			zap.Int("table totals", len(waitSyncMetas)),
 			zap.Int("table success", len(succTotals)),
 			zap.Int("check failed", len(failedTotals)),
 			zap.String("failed tips", "failed detail, please see table [error_log_detail]"),
+			zap.Strings("suggest", suggestions),
 			zap.String("cost", time.Now().Sub(startTime).String()))
 	}
 
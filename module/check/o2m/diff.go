@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
 	"github.com/wentaojin/transferdb/database/meta"
 	"github.com/wentaojin/transferdb/module/check"
 	"go.uber.org/zap"
@@ -37,10 +38,14 @@ type Diff struct {
 	MySQLTableINFO  *Table     `json:"mysql_table_info"`
 	MySQLDBVersion  string     `json:"mysqldb_version"`
 	MySQLDBType     string     `json:"mysqldb_type"`
+	AppImpact       bool       `json:"app_impact"`
+	IgnorePartition bool       `json:"ignore_partition"`
+	IgnoreComment   bool       `json:"ignore_comment"`
+	CheckTrigger    bool       `json:"check_trigger"`
 	MetaDB          *meta.Meta `json:"-"`
 }
 
-func NewChecker(ctx context.Context, oracleTableInfo, mysqlTableInfo *Table, dbTypeS, dbTypeT, mysqlDBVersion, targetDBType string, metaDB *meta.Meta) *Diff {
+func NewChecker(ctx context.Context, oracleTableInfo, mysqlTableInfo *Table, dbTypeS, dbTypeT, mysqlDBVersion, targetDBType string, checkCfg config.CheckConfig, metaDB *meta.Meta) *Diff {
 	return &Diff{
 		Ctx:             ctx,
 		DBTypeS:         dbTypeS,
@@ -49,6 +54,10 @@ func NewChecker(ctx context.Context, oracleTableInfo, mysqlTableInfo *Table, dbT
 		MySQLTableINFO:  mysqlTableInfo,
 		MySQLDBVersion:  mysqlDBVersion,
 		MySQLDBType:     targetDBType,
+		AppImpact:       checkCfg.ApplicationImpact,
+		IgnorePartition: checkCfg.IgnorePartition,
+		IgnoreComment:   checkCfg.IgnoreComment,
+		CheckTrigger:    checkCfg.CheckTrigger,
 		MetaDB:          metaDB,
 	}
 }
@@ -57,7 +66,8 @@ func NewChecker(ctx context.Context, oracleTableInfo, mysqlTableInfo *Table, dbT
 // 以上游 oracle 表结构信息为基准，对比下游 MySQL 表结构
 // 1、若上游存在，下游不存在，则输出记录，若上游不存在，下游存在，则默认不输出
 // 2、忽略上下游不同索引名、约束名对比，只对比下游是否存在同等约束下同等字段是否存在
-// 3、分区只对比分区类型、分区键、分区表达式等，不对比具体每个分区下的情况
+// 3、分区对比分区类型、分区键、分区表达式以及分区名称，不对比具体每个分区的边界值
+// 4、触发器只对比下游是否存在同名触发器，不对比触发器定义
 
 func (c *Diff) CheckPartitionTableType() string {
 	// 表类型检查 - only 分区表
@@ -397,7 +407,7 @@ func (c *Diff) CheckForeignKey() (string, error) {
 			for _, fk := range addDiffFK {
 				value, ok := fk.(ConstraintForeign)
 				if ok {
-					builder.WriteString(fmt.Sprintf("ALTER TABLE %s.%s ADD FOREIGN KEY(%s) REFERENCES %s.%s(%s）ON DELETE %s;\n", c.MySQLTableINFO.SchemaName, c.MySQLTableINFO.TableName, value.ColumnName, c.MySQLTableINFO.SchemaName, value.ReferencedTableName, value.ReferencedColumnName, value.DeleteRule))
+					builder.WriteString(fmt.Sprintf("ALTER TABLE %s.%s ADD FOREIGN KEY(%s) REFERENCES %s.%s(%s) ON DELETE %s;\n", c.MySQLTableINFO.SchemaName, c.MySQLTableINFO.TableName, value.ColumnName, c.MySQLTableINFO.SchemaName, value.ReferencedTableName, value.ReferencedColumnName, value.DeleteRule))
 					continue
 				}
 				return builder.String(), fmt.Errorf("oracle table [%s] constraint foreign key [%v] assert ConstraintForeign failed, type: [%v]", c.OracleTableINFO.TableName, fk, reflect.TypeOf(fk))
@@ -417,12 +427,17 @@ func (c *Diff) CheckCheckKey() (string, error) {
 	// TiDB 版本排除外键以及检查约束检查
 	if !isTiDB {
 		var dbVersion string
-		if strings.Contains(c.MySQLDBVersion, common.MySQLVersionDelimiter) {
+		checkConsVersion := common.MySQLCheckConsVersion
+		switch {
+		case common.IsMariaDBVersion(c.MySQLDBVersion):
+			dbVersion = common.MariaDBVersion(c.MySQLDBVersion)
+			checkConsVersion = common.MariaDBCheckConsVersion
+		case strings.Contains(c.MySQLDBVersion, common.MySQLVersionDelimiter):
 			dbVersion = strings.Split(c.MySQLDBVersion, common.MySQLVersionDelimiter)[0]
-		} else {
+		default:
 			dbVersion = c.MySQLDBVersion
 		}
-		if common.VersionOrdinal(dbVersion) > common.VersionOrdinal(common.MySQLCheckConsVersion) {
+		if common.VersionOrdinal(dbVersion) > common.VersionOrdinal(checkConsVersion) {
 			zap.L().Info("check table",
 				zap.String("table ck constraint check", fmt.Sprintf("%s.%s", c.OracleTableINFO.SchemaName, c.OracleTableINFO.TableName)),
 				zap.String("oracle struct", c.OracleTableINFO.String(common.JSONCKConstraint)),
@@ -603,6 +618,70 @@ func (c *Diff) CheckPartitionTable() (string, error) {
 	return builder.String(), nil
 }
 
+func (c *Diff) CheckPartitionName() string {
+	// 分区名称检查，上游存在下游不存在
+	var builder strings.Builder
+	if !c.MySQLTableINFO.IsPartition || !c.OracleTableINFO.IsPartition {
+		return builder.String()
+	}
+	zap.L().Info("check table",
+		zap.String("table partition name check", fmt.Sprintf("%s.%s", c.OracleTableINFO.SchemaName, c.OracleTableINFO.TableName)))
+
+	var tableRows []table.Row
+	for _, part := range c.OracleTableINFO.PartitionNames {
+		if !common.IsContainString(c.MySQLTableINFO.PartitionNames, part) {
+			tableRows = append(tableRows, table.Row{c.OracleTableINFO.TableName, "PARTITION", part, "NOT EXIST", "Manual Add Partition"})
+		}
+	}
+	if len(tableRows) > 0 {
+		builder.WriteString("/*\n")
+		builder.WriteString(" oracle table partition name isn't exist in mysql\n")
+
+		t := table.NewWriter()
+		t.SetStyle(table.StyleLight)
+		t.AppendHeader(table.Row{"TABLE", "PARTITION", "ORACLE", "MYSQL", "SUGGEST"})
+		t.AppendRows(tableRows)
+		builder.WriteString(fmt.Sprintf("%v\n", t.Render()))
+		builder.WriteString("*/\n")
+	}
+	return builder.String()
+}
+
+func (c *Diff) CheckTableTrigger() string {
+	// 触发器检查，只对比同名触发器是否存在，Oracle 触发器无法自动转换，需手工改写
+	zap.L().Info("check table",
+		zap.String("table trigger check", fmt.Sprintf("%s.%s", c.OracleTableINFO.SchemaName, c.OracleTableINFO.TableName)),
+		zap.String("oracle struct", c.OracleTableINFO.String(common.JSONTrigger)),
+		zap.String("mysql struct", c.MySQLTableINFO.String(common.JSONTrigger)))
+
+	var (
+		builder       strings.Builder
+		mysqlTriggers []string
+		tableRows     []table.Row
+	)
+	for _, tr := range c.MySQLTableINFO.Triggers {
+		mysqlTriggers = append(mysqlTriggers, tr.TriggerName)
+	}
+	for _, tr := range c.OracleTableINFO.Triggers {
+		if !common.IsContainString(mysqlTriggers, tr.TriggerName) {
+			tableRows = append(tableRows, table.Row{c.OracleTableINFO.TableName, tr.TriggerName,
+				fmt.Sprintf("%s %s", tr.TriggerType, tr.TriggeringEvent), "NOT EXIST", "Manual Create Trigger"})
+		}
+	}
+	if len(tableRows) > 0 {
+		builder.WriteString("/*\n")
+		builder.WriteString(" oracle table trigger isn't exist in mysql\n")
+
+		t := table.NewWriter()
+		t.SetStyle(table.StyleLight)
+		t.AppendHeader(table.Row{"TABLE", "TRIGGER", "ORACLE", "MYSQL", "SUGGEST"})
+		t.AppendRows(tableRows)
+		builder.WriteString(fmt.Sprintf("%v\n", t.Render()))
+		builder.WriteString("*/\n")
+	}
+	return builder.String()
+}
+
 func (c *Diff) CheckColumn() (string, error) {
 	// 表字段检查
 	// 注释格式化
@@ -618,6 +697,10 @@ func (c *Diff) CheckColumn() (string, error) {
 	for oracleColName, oracleColInfo := range c.OracleTableINFO.Columns {
 		mysqlColInfo, ok := c.MySQLTableINFO.Columns[oracleColName]
 		if ok {
+			// 忽略注释检查，以下游注释为准，修复 SQL 保留下游注释
+			if c.IgnoreComment {
+				oracleColInfo.Comment = mysqlColInfo.Comment
+			}
 			diffColumnMsg, tableRows, err := OracleTableColumnMapRuleCheck(
 				common.StringUPPER(c.OracleTableINFO.SchemaName),
 				common.StringUPPER(c.MySQLTableINFO.SchemaName),
@@ -670,11 +753,15 @@ func (c *Diff) Writer(f *check.File) error {
 
 	var builder strings.Builder
 
-	if !strings.EqualFold(c.CheckPartitionTableType(), "") {
-		builder.WriteString(c.CheckPartitionTableType())
+	if !c.IgnorePartition {
+		if partitionType := c.CheckPartitionTableType(); !strings.EqualFold(partitionType, "") {
+			builder.WriteString(partitionType)
+		}
 	}
-	if !strings.EqualFold(c.CheckTableComment(), "") {
-		builder.WriteString(c.CheckTableComment())
+	if !c.IgnoreComment {
+		if comment := c.CheckTableComment(); !strings.EqualFold(comment, "") {
+			builder.WriteString(comment)
+		}
 	}
 	if !strings.EqualFold(c.CheckTableCharacterSetAndCollation(), "") {
 		builder.WriteString(c.CheckTableCharacterSetAndCollation())
@@ -716,12 +803,17 @@ func (c *Diff) Writer(f *check.File) error {
 		builder.WriteString(index)
 	}
 
-	partitionTable, err := c.CheckPartitionTable()
-	if err != nil {
-		return err
-	}
-	if !strings.EqualFold(partitionTable, "") {
-		builder.WriteString(partitionTable)
+	if !c.IgnorePartition {
+		partitionTable, err := c.CheckPartitionTable()
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(partitionTable, "") {
+			builder.WriteString(partitionTable)
+		}
+		if partitionName := c.CheckPartitionName(); !strings.EqualFold(partitionName, "") {
+			builder.WriteString(partitionName)
+		}
 	}
 
 	column, err := c.CheckColumn()
@@ -731,6 +823,19 @@ func (c *Diff) Writer(f *check.File) error {
 	if !strings.EqualFold(column, "") {
 		builder.WriteString(column)
 	}
+
+	if c.CheckTrigger {
+		if trigger := c.CheckTableTrigger(); !strings.EqualFold(trigger, "") {
+			builder.WriteString(trigger)
+		}
+	}
+
+	// 应用语义差异
+	if c.AppImpact {
+		if impact := c.CheckApplicationImpact(); !strings.EqualFold(impact, "") {
+			builder.WriteString(impact)
+		}
+	}
 	// diff 记录不为空
 	if builder.String() != "" {
 		if _, err := f.CWriteFile(builder.String()); err != nil {
@@ -0,0 +1,189 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"bufio"
+	"crypto/md5"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	"go.uber.org/zap"
+	"os"
+	"strings"
+	"time"
+)
+
+// ParseCheckFixSQL 解析 check 文件中可执行的修复 SQL，忽略注释块以及人工处理提示
+func ParseCheckFixSQL(checkFile string) ([]string, error) {
+	file, err := os.Open(checkFile)
+	if err != nil {
+		return nil, fmt.Errorf("open check file [%s] failed: %v", checkFile, err)
+	}
+	defer file.Close()
+
+	var (
+		stmts   []string
+		builder strings.Builder
+		inBlock bool
+	)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if inBlock {
+			if strings.HasSuffix(line, "*/") {
+				inBlock = false
+			}
+			continue
+		}
+		// 分区提示行未换行，注释块起始可能位于行尾
+		if strings.HasSuffix(line, "/*") {
+			inBlock = true
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "--") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if builder.Len() > 0 {
+			builder.WriteString(" ")
+		}
+		builder.WriteString(line)
+		if strings.HasSuffix(line, ";") {
+			stmts = append(stmts, builder.String())
+			builder.Reset()
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan check file [%s] failed: %v", checkFile, err)
+	}
+	return stmts, nil
+}
+
+// GenCheckFixStage 修复 SQL 所属执行阶段，MySQL 不支持的语句返回空
+func GenCheckFixStage(stmt string) string {
+	upper := common.StringUPPER(stmt)
+	switch {
+	case strings.HasPrefix(upper, "CREATE BITMAP INDEX") || strings.Contains(upper, " INDEXTYPE IS "):
+		return ""
+	case strings.HasPrefix(upper, "CREATE UNIQUE INDEX") || strings.HasPrefix(upper, "CREATE INDEX"):
+		return common.CheckFixStageIndex
+	case !strings.HasPrefix(upper, "ALTER TABLE"):
+		return ""
+	case strings.Contains(upper, " ADD FOREIGN KEY"):
+		return common.CheckFixStageForeignKey
+	case strings.Contains(upper, " ADD CONSTRAINT ") && strings.Contains(upper, " CHECK("):
+		return common.CheckFixStageCheck
+	case strings.Contains(upper, " ADD PRIMARY KEY") || strings.Contains(upper, " ADD UNIQUE"):
+		return common.CheckFixStageKey
+	case strings.Contains(upper, " ADD COLUMN ") || strings.Contains(upper, " MODIFY ") || strings.Contains(upper, " DROP COLUMN "):
+		return common.CheckFixStageColumn
+	case strings.Contains(upper, " COMMENT ") || strings.Contains(upper, " CHARACTER SET "):
+		return common.CheckFixStageTable
+	default:
+		return ""
+	}
+}
+
+// applyFix 登记 check 文件修复 SQL 并按阶段顺序执行，阶段内存在失败语句则不再执行后续阶段
+func (r *Check) applyFix(checkFile string) error {
+	startTime := time.Now()
+	stmts, err := ParseCheckFixSQL(checkFile)
+	if err != nil {
+		return err
+	}
+
+	stageOrder := make(map[string]int)
+	for i, stage := range common.CheckFixStages {
+		stageOrder[stage] = i
+	}
+
+	var fixes []meta.CheckFixApply
+	for _, stmt := range stmts {
+		stage := GenCheckFixStage(stmt)
+		if stage == "" {
+			zap.L().Warn("check fixed sql isn't support apply, please manual deal",
+				zap.String("schema", r.cfg.OracleConfig.SchemaName),
+				zap.String("sql", stmt))
+			continue
+		}
+		fixes = append(fixes, meta.CheckFixApply{
+			DBTypeS:     r.cfg.DBTypeS,
+			DBTypeT:     r.cfg.DBTypeT,
+			SchemaNameS: common.StringUPPER(r.cfg.OracleConfig.SchemaName),
+			SQLDigest:   fmt.Sprintf("%x", md5.Sum([]byte(stmt))),
+			SchemaNameT: common.StringUPPER(r.cfg.MySQLConfig.SchemaName),
+			FixStage:    stage,
+			FixOrder:    stageOrder[stage],
+			SQLDetail:   stmt,
+			ApplyStatus: common.TaskStatusWaiting,
+		})
+	}
+	// 重跑时已登记语句保持原状态，仅执行未成功语句
+	if err = meta.NewCheckFixApplyModel(r.metaDB).BatchCreateCheckFixApply(r.ctx, fixes); err != nil {
+		return err
+	}
+	applies, err := meta.NewCheckFixApplyModel(r.metaDB).DetailCheckFixApply(r.ctx, &meta.CheckFixApply{
+		DBTypeS:     r.cfg.DBTypeS,
+		DBTypeT:     r.cfg.DBTypeT,
+		SchemaNameS: common.StringUPPER(r.cfg.OracleConfig.SchemaName),
+	})
+	if err != nil {
+		return err
+	}
+
+	successTotals := 0
+	for _, stage := range common.CheckFixStages {
+		stageFailed := 0
+		for _, apply := range applies {
+			if apply.FixStage != stage || apply.ApplyStatus == common.TaskStatusSuccess {
+				continue
+			}
+			applyStatus, errDetail := common.TaskStatusSuccess, ""
+			if err = r.mysql.WriteMySQLTable(apply.SQLDetail); err != nil {
+				applyStatus, errDetail = common.TaskStatusFailed, err.Error()
+				stageFailed++
+				zap.L().Error("check fixed sql apply failed",
+					zap.String("schema", apply.SchemaNameT),
+					zap.String("stage", stage),
+					zap.String("sql", apply.SQLDetail),
+					zap.Error(err))
+			} else {
+				successTotals++
+				zap.L().Info("check fixed sql apply success",
+					zap.String("schema", apply.SchemaNameT),
+					zap.String("stage", stage),
+					zap.String("sql", apply.SQLDetail))
+			}
+			if err = meta.NewCheckFixApplyModel(r.metaDB).UpdateCheckFixApply(r.ctx, &apply, map[string]interface{}{
+				"ApplyStatus": applyStatus,
+				"ErrorDetail": errDetail,
+			}); err != nil {
+				return err
+			}
+		}
+		if stageFailed > 0 {
+			return fmt.Errorf("check schema [%s] fixed sql stage [%s] apply failed [%d], later stages skipped, please see table [check_fix_apply] and deal, then rerunning", r.cfg.OracleConfig.SchemaName, stage, stageFailed)
+		}
+	}
+
+	zap.L().Info("check fixed sql apply finished",
+		zap.String("schema", r.cfg.OracleConfig.SchemaName),
+		zap.Int("sql totals", len(applies)),
+		zap.Int("apply success", successTotals),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
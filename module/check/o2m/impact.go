@@ -0,0 +1,151 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/wentaojin/transferdb/common"
+	"go.uber.org/zap"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// NLSSORT 函数索引表达式，例如 NLSSORT("NAME",'NLS_SORT = SCHINESE_PINYIN_M')
+var nlsSortRegexp = regexp.MustCompile(`NLS_SORT\s*=\s*'*([A-Z0-9_]+)`)
+
+// CheckApplicationImpact 应用语义差异检查
+// 表结构一致情况下，仍可能影响应用行为的语义差异，只输出提示，不生成修复 SQL
+// 1、CHAR 尾部空格填充
+// 2、索引 NULL 排序以及唯一索引 NULL 判定
+// 3、大小写不敏感 collation 等值比较
+// 4、语言排序 collation 以及 NLSSORT 函数索引排序
+func (c *Diff) CheckApplicationImpact() string {
+	zap.L().Info("check table",
+		zap.String("table application impact check", fmt.Sprintf("%s.%s", c.OracleTableINFO.SchemaName, c.OracleTableINFO.TableName)))
+
+	var (
+		tableRowArray []table.Row
+		builder       strings.Builder
+	)
+	tableName := fmt.Sprintf("%s.%s", c.OracleTableINFO.SchemaName, c.OracleTableINFO.TableName)
+
+	// 字段按名称排序，保证输出稳定
+	var columnNames []string
+	for colName := range c.OracleTableINFO.Columns {
+		columnNames = append(columnNames, colName)
+	}
+	sort.Strings(columnNames)
+
+	for _, colName := range columnNames {
+		oracleColInfo := c.OracleTableINFO.Columns[colName]
+		mysqlColInfo, ok := c.MySQLTableINFO.Columns[colName]
+		if !ok {
+			continue
+		}
+
+		// CHAR 填充语义
+		// oracle CHAR 存储以及读取保留尾部空格，mysql CHAR 读取去除尾部空格
+		if strings.EqualFold(oracleColInfo.DataType, "CHAR") || strings.EqualFold(oracleColInfo.DataType, "NCHAR") {
+			if strings.EqualFold(mysqlColInfo.DataType, "CHAR") {
+				tableRowArray = append(tableRowArray, table.Row{tableName, colName, "CHAR PADDING",
+					fmt.Sprintf("%s(%s) blank-padded", oracleColInfo.DataType, oracleColInfo.CharLength),
+					fmt.Sprintf("%s trailing spaces removed", mysqlColInfo.DataType),
+					"LENGTH()/concat results and fetched values differ"})
+			}
+		}
+
+		// VARCHAR 尾部空格比较语义
+		// oracle VARCHAR2 非填充比较 'a' != 'a '，mysql PAD SPACE collation 'a' = 'a '
+		if strings.EqualFold(oracleColInfo.DataType, "VARCHAR2") || strings.EqualFold(oracleColInfo.DataType, "NVARCHAR2") {
+			if mysqlColInfo.Collation != "" && !strings.Contains(mysqlColInfo.Collation, "0900") {
+				tableRowArray = append(tableRowArray, table.Row{tableName, colName, "TRAILING SPACE EQUALITY",
+					fmt.Sprintf("%s nonpadded comparison", oracleColInfo.DataType),
+					fmt.Sprintf("%s pad space comparison", mysqlColInfo.Collation),
+					"'a' = 'a ' is true, unique key may conflict"})
+			}
+		}
+
+		// 大小写不敏感 collation
+		if mysqlColInfo.Collation != "" && strings.HasSuffix(mysqlColInfo.Collation, "_CI") && !strings.HasSuffix(oracleColInfo.Collation, "_CI") {
+			tableRowArray = append(tableRowArray, table.Row{tableName, colName, "CASE INSENSITIVE EQUALITY",
+				fmt.Sprintf("%s case sensitive", oracleColInfo.Collation),
+				fmt.Sprintf("%s case insensitive", mysqlColInfo.Collation),
+				"'A' = 'a' is true, where/join/unique key semantics differ"})
+		}
+
+		// 语言排序 collation
+		// oracle 字段 collation 为 SCHINESE_PINYIN_M 等语言排序，mysql collation 按编码权重排序，order by 结果不一致
+		if common.IsOracleLinguisticSort(oracleColInfo.Collation) {
+			tableRowArray = append(tableRowArray, table.Row{tableName, colName, "LINGUISTIC SORT",
+				fmt.Sprintf("%s linguistic sort", oracleColInfo.Collation),
+				fmt.Sprintf("%s collation sort", mysqlColInfo.Collation),
+				"order by/range scan result order differ, please compare with [sort-order-check]"})
+		}
+	}
+
+	// 索引 NULL 语义以及 NLSSORT 函数索引
+	for _, idx := range c.OracleTableINFO.Indexes {
+		// NLSSORT 函数索引用于语言排序 order by 以及 NLS_COMP=LINGUISTIC 等值查询，mysql 无对应函数索引
+		if strings.Contains(idx.IndexColumn, "NLSSORT(") {
+			nlsSort := "NLS_SORT"
+			if matches := nlsSortRegexp.FindStringSubmatch(idx.IndexColumn); len(matches) == 2 {
+				nlsSort = matches[1]
+			}
+			tableRowArray = append(tableRowArray, table.Row{tableName, idx.IndexName, "LINGUISTIC SORT",
+				fmt.Sprintf("NLSSORT %s function-based index", nlsSort), "not support",
+				fmt.Sprintf("order by index expression [%s] result order differ and index unused", idx.IndexColumn)})
+		}
+
+		var nullableCols []string
+		idxCols := strings.Split(idx.IndexColumn, ",")
+		for _, col := range idxCols {
+			if colInfo, ok := c.OracleTableINFO.Columns[strings.TrimSpace(col)]; ok && strings.EqualFold(colInfo.NULLABLE, "NULL") {
+				nullableCols = append(nullableCols, strings.TrimSpace(col))
+			}
+		}
+		if len(nullableCols) == 0 {
+			continue
+		}
+
+		// oracle 升序 NULL 最大排最后，mysql 升序 NULL 最小排最前
+		tableRowArray = append(tableRowArray, table.Row{tableName, idx.IndexName, "NULL SORT ORDER",
+			"ASC NULLS LAST", "ASC NULLS FIRST",
+			fmt.Sprintf("order by nullable columns [%s] result order differ", strings.Join(nullableCols, ","))})
+
+		// oracle 复合唯一索引部分字段为 NULL 仍校验唯一，mysql 任一字段为 NULL 不校验唯一
+		if strings.EqualFold(idx.Uniqueness, "UNIQUE") && len(idxCols) > 1 {
+			tableRowArray = append(tableRowArray, table.Row{tableName, idx.IndexName, "UNIQUE NULL",
+				"partial null key unique", "any null key not unique",
+				fmt.Sprintf("duplicate rows allowed when nullable columns [%s] is null", strings.Join(nullableCols, ","))})
+		}
+	}
+
+	if len(tableRowArray) != 0 {
+		textTable := table.NewWriter()
+		textTable.SetStyle(table.StyleLight)
+		textTable.AppendHeader(table.Row{"Table", "Object", "Rule", "ORACLE", "MySQL", "Application Impact"})
+		textTable.AppendRows(tableRowArray)
+
+		builder.WriteString("/*\n")
+		builder.WriteString(fmt.Sprintf(" oracle table semantics maybe affect application, please confirm with development\n"))
+		builder.WriteString(fmt.Sprintf("%s\n", textTable.Render()))
+		builder.WriteString("*/\n")
+	}
+
+	return builder.String()
+}
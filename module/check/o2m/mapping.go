@@ -39,7 +39,7 @@ func GenOracleTableColumnMeta(ctx context.Context, metaDB *meta.Meta, dbTypeS, d
 		columnMeta string
 	)
 
-	if strings.EqualFold(columnINFO.NULLABLE, "Y") {
+	if strings.EqualFold(columnINFO.NULLABLE, "Y") || strings.EqualFold(columnINFO.NULLABLE, "NULL") {
 		nullable = "NULL"
 	} else {
 		nullable = "NOT NULL"
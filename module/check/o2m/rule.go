@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/wentaojin/transferdb/common"
+	"go.uber.org/zap"
 	"strconv"
 	"strings"
 )
@@ -79,6 +80,13 @@ func OracleTableColumnMapRuleCheck(
 	} else {
 		oracleColumnCharUsed = "unknown"
 	}
+	// 字符语义 DATA_LENGTH 为字符数 × 库字符集单字符最大字节数，按 CHAR_LENGTH 字符数与 MySQL 字符长度对比
+	if oracleColumnCharUsed == "char" {
+		oracleDataLength, err = common.OracleColumnCharLength(oracleColInfo.CharUsed, oracleColInfo.CharLength, oracleColInfo.DataLength)
+		if err != nil {
+			return "", nil, fmt.Errorf("oracle schema [%s] table [%s] column [%s] char length failed: %v", sourceSchema, tableName, columnName, err)
+		}
+	}
 
 	// GBK 处理，统一 UTF8MB4 处理
 	var (
@@ -104,6 +112,28 @@ func OracleTableColumnMapRuleCheck(
 	oracleColMeta := genColumnNullCommentDefaultMeta(oracleColInfo.NULLABLE, oracleColumnComment, oracleColInfo.OracleOriginDataDefault)
 	mysqlColMeta := genColumnNullCommentDefaultMeta(mysqlColInfo.NULLABLE, mysqlColInfo.Comment, mysqlColInfo.MySQLOriginDataDefault)
 
+	// 下游字符长度小于上游可容纳字符数，数据写入存在截断风险
+	if oracleColumnCharUsed != "unknown" && common.IsContainString([]string{"CHAR", "VARCHAR", "NCHAR", "NVARCHAR", "NCHAR VARYING"}, mysqlDataType) && mysqlDataLength < oracleDataLength {
+		zap.L().Warn("mysql column char length less than oracle column, data may be truncated",
+			zap.String("schema", targetSchema),
+			zap.String("table", tableName),
+			zap.String("column", columnName),
+			zap.String("oracle column", fmt.Sprintf("%s(%d %s)", oracleDataType, oracleDataLength, oracleColumnCharUsed)),
+			zap.String("mysql column", fmt.Sprintf("%s(%d)", mysqlDataType, mysqlDataLength)))
+	}
+
+	// 上游变长字符字段超出 MySQL VARCHAR 最大字节数，reverse 转换为 MEDIUMTEXT
+	switch oracleDataType {
+	case common.BuildInOracleDatatypeVarchar2, common.BuildInOracleDatatypeVarchar:
+		if mysqlDataType == common.MySQLVarcharOverflowType && common.IsMySQLVarcharOverflow(oracleDataLength, common.MySQLCharacterSet) && oracleDiffColMeta == mysqlDiffColMeta {
+			return "", nil, nil
+		}
+	case common.BuildInOracleDatatypeNvarchar2, common.BuildInOracleDatatypeNcharVarying:
+		if mysqlDataType == common.MySQLVarcharOverflowType && common.IsMySQLVarcharOverflow(oracleDataLength, "UTF8") && oracleDiffColMeta == mysqlDiffColMeta {
+			return "", nil, nil
+		}
+	}
+
 	// 字段类型判断
 	// CHARACTER SET %s COLLATE %s (Only 作用于字符类型)
 	switch common.StringUPPER(oracleDataType) {
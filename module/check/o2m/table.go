@@ -37,6 +37,8 @@ type Table struct {
 	CheckConstraints   []ConstraintCheck
 	IsPartition        bool
 	Partitions         []Partition // 获取分区键、分区类型以及子分区键、子分区类型
+	PartitionNames     []string
+	Triggers           []Trigger
 }
 
 type Column struct {
@@ -99,6 +101,12 @@ type Partition struct {
 	SubPartitionType string
 }
 
+type Trigger struct {
+	TriggerName     string
+	TriggerType     string
+	TriggeringEvent string
+}
+
 func (t *Table) String(jsonType string) string {
 	var jsonStr []byte
 	switch jsonType {
@@ -114,6 +122,8 @@ func (t *Table) String(jsonType string) string {
 		jsonStr, _ = json.Marshal(t.Indexes)
 	case common.JSONPartition:
 		jsonStr, _ = json.Marshal(t.Partitions)
+	case common.JSONTrigger:
+		jsonStr, _ = json.Marshal(t.Triggers)
 	}
 	return string(jsonStr)
 }
@@ -179,6 +189,13 @@ func NewOracleTableINFO(schemaName, tableName string, oracle *oracle.Oracle, sou
 				SubPartitionType: strings.ToUpper(part["SUBPARTITIONING_TYPE"]),
 			})
 		}
+		partNames, err := oracle.GetOracleTablePartitionName(schemaName, tableName)
+		if err != nil {
+			return oraTable, err
+		}
+		for _, p := range partNames {
+			oraTable.PartitionNames = append(oraTable.PartitionNames, strings.ToUpper(p))
+		}
 	}
 	oraTable.TableCharacterSet = strings.ToUpper(OracleCharacterSet)
 	oraTable.TableCollation = tableCollation
@@ -446,6 +463,13 @@ func NewMySQLTableINFO(schemaName, tableName, targetDBType string, mysql *mysql.
 				SubPartitionType: strings.ToUpper(part["SUBPARTITIONING_TYPE"]),
 			})
 		}
+		partNames, err := mysql.GetMySQLTablePartitionName(schemaName, tableName)
+		if err != nil {
+			return mysqlTable, version, err
+		}
+		for _, p := range partNames {
+			mysqlTable.PartitionNames = append(mysqlTable.PartitionNames, strings.ToUpper(p))
+		}
 	}
 
 	mysqlTable.TableCharacterSet = strings.ToUpper(characterSet)
@@ -601,3 +625,35 @@ func getMySQLTableConstraint(schemaName, tableName, version string, mysql *mysql
 	}
 	return puConstraints, fkConstraints, ckConstraints, nil
 }
+
+func GetOracleTableTrigger(schemaName, tableName string, oracle *oracle.Oracle) ([]Trigger, error) {
+	triggerInfo, err := oracle.GetOracleTableTrigger(schemaName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	var triggers []Trigger
+	for _, tr := range triggerInfo {
+		triggers = append(triggers, Trigger{
+			TriggerName:     strings.ToUpper(tr["TRIGGER_NAME"]),
+			TriggerType:     strings.ToUpper(tr["TRIGGER_TYPE"]),
+			TriggeringEvent: strings.ToUpper(tr["TRIGGERING_EVENT"]),
+		})
+	}
+	return triggers, nil
+}
+
+func getMySQLTableTrigger(schemaName, tableName string, mysql *mysql.MySQL) ([]Trigger, error) {
+	triggerInfo, err := mysql.GetMySQLTableTrigger(schemaName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	var triggers []Trigger
+	for _, tr := range triggerInfo {
+		triggers = append(triggers, Trigger{
+			TriggerName:     strings.ToUpper(tr["TRIGGER_NAME"]),
+			TriggerType:     strings.ToUpper(tr["TRIGGER_TYPE"]),
+			TriggeringEvent: strings.ToUpper(tr["TRIGGERING_EVENT"]),
+		})
+	}
+	return triggers, nil
+}
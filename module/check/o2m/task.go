@@ -35,6 +35,7 @@ type Task struct {
 	SourceDBCollation     bool   `json:"source_db_collation"`
 	SourceTableCollation  string `json:"source_table_collation"`
 	SourceSchemaCollation string `json:"source_schema_collation"`
+	CheckTrigger          bool   `json:"check_trigger"`
 
 	Oracle *oracle.Oracle `json:"-"`
 	MySQL  *mysql.MySQL   `json:"-"`
@@ -42,7 +43,7 @@ type Task struct {
 
 func GenCheckTaskTable(sourceSchemaName, targetSchemaName, sourceDBCharacterSet, nlsSort, nlsComp string,
 	sourceTableCollation map[string]string, sourceSchemaCollation string,
-	sourceDBCollation bool, targetDBType string, oracle *oracle.Oracle, mysql *mysql.MySQL, tableNameRule map[string]string, waitSyncMetas []meta.WaitSyncMeta) []*Task {
+	sourceDBCollation, checkTrigger bool, targetDBType string, oracle *oracle.Oracle, mysql *mysql.MySQL, tableNameRule map[string]string, waitSyncMetas []meta.WaitSyncMeta) []*Task {
 	var tasks []*Task
 	for _, t := range waitSyncMetas {
 		// 库名、表名规则
@@ -64,6 +65,7 @@ func GenCheckTaskTable(sourceSchemaName, targetSchemaName, sourceDBCharacterSet,
 			SourceTableCollation:  sourceTableCollation[t.TableNameS],
 			SourceSchemaCollation: sourceSchemaCollation,
 			TargetDBType:          targetDBType,
+			CheckTrigger:          checkTrigger,
 			Oracle:                oracle,
 			MySQL:                 mysql,
 		})
@@ -76,6 +78,13 @@ func (t *Task) GenOracleTable() (*Table, error) {
 	if err != nil {
 		return info, err
 	}
+	// 触发器按需获取
+	if t.CheckTrigger {
+		info.Triggers, err = GetOracleTableTrigger(t.SourceSchemaName, t.SourceTableName, t.Oracle)
+		if err != nil {
+			return info, err
+		}
+	}
 	return info, nil
 }
 
@@ -84,6 +93,12 @@ func (t *Task) GenMySQLTable() (*Table, string, error) {
 	if err != nil {
 		return info, version, err
 	}
+	if t.CheckTrigger {
+		info.Triggers, err = getMySQLTableTrigger(t.TargetSchemaName, t.TargetTableName, t.MySQL)
+		if err != nil {
+			return info, version, err
+		}
+	}
 	return info, version, nil
 }
 
@@ -41,6 +41,8 @@ type Chunk struct {
 	SourceColumnInfo string          `json:"source_column_info"`
 	TargetColumnInfo string          `json:"target_column_info"`
 	WhereColumn      string          `json:"where_column"`
+	IdentityS        string          `json:"identity_s"`
+	IdentityT        string          `json:"identity_t"`
 	WhereRange       string          `json:"where_range"` // chunk split need
 	Cfg              *config.Config  `json:"-"`
 	Oracle           *oracle.Oracle  `json:"-"`
@@ -50,7 +52,7 @@ type Chunk struct {
 
 func NewChunk(ctx context.Context, cfg *config.Config, oracle *oracle.Oracle, mysql *mysql.MySQL, metaDB *meta.Meta,
 	chunkID int, sourceGlobalSCN uint64, sourceTable, targetTable string, isPartition string, sourceColumnInfo, targetColumnInfo string,
-	whereColumn string) *Chunk {
+	whereColumn, identityS, identityT string) *Chunk {
 	return &Chunk{
 		Ctx:              ctx,
 		ChunkID:          chunkID,
@@ -61,6 +63,8 @@ func NewChunk(ctx context.Context, cfg *config.Config, oracle *oracle.Oracle, my
 		SourceColumnInfo: sourceColumnInfo,
 		TargetColumnInfo: targetColumnInfo,
 		WhereColumn:      whereColumn,
+		IdentityS:        identityS,
+		IdentityT:        identityT,
 		Oracle:           oracle,
 		MySQL:            mysql,
 		MetaDB:           metaDB,
@@ -128,6 +132,8 @@ func (c *Chunk) Split() error {
 			TableNameT:    common.StringUPPER(c.TargetTable),
 			ColumnDetailT: c.TargetColumnInfo,
 			WhereColumn:   c.WhereColumn,
+			IdentityS:     c.IdentityS,
+			IdentityT:     c.IdentityT,
 			WhereRange:    c.WhereRange,
 			TaskMode:      c.Cfg.TaskMode,
 			TaskStatus:    common.TaskStatusWaiting,
@@ -173,6 +179,8 @@ func (c *Chunk) Split() error {
 			TableNameT:    common.StringUPPER(c.TargetTable),
 			ColumnDetailT: c.TargetColumnInfo,
 			WhereColumn:   c.WhereColumn,
+			IdentityS:     c.IdentityS,
+			IdentityT:     c.IdentityT,
 			WhereRange:    c.WhereRange,
 			TaskMode:      c.Cfg.TaskMode,
 			TaskStatus:    common.TaskStatusWaiting,
@@ -200,8 +208,14 @@ func (c *Chunk) Split() error {
 	if err != nil {
 		return err
 	}
-	// 统计信息数据行数 0，直接全表扫
-	if tableRowsByStatistics == 0 {
+	// forth
+	// indexField > 程序已过滤筛选的字段 DB Filter integer column
+	if !strings.EqualFold(customColumn, "") {
+		c.WhereColumn = customColumn
+	}
+
+	// 统计信息数据行数 0 或者无可用切分字段（仅存在行标识），直接全表扫
+	if tableRowsByStatistics == 0 || strings.EqualFold(c.WhereColumn, "") {
 		zap.L().Warn("get oracle table rows",
 			zap.String("schema", common.StringUPPER(c.Cfg.OracleConfig.SchemaName)),
 			zap.String("table", c.SourceTable),
@@ -219,6 +233,8 @@ func (c *Chunk) Split() error {
 			TableNameT:    common.StringUPPER(c.TargetTable),
 			ColumnDetailT: c.TargetColumnInfo,
 			WhereColumn:   c.WhereColumn,
+			IdentityS:     c.IdentityS,
+			IdentityT:     c.IdentityT,
 			WhereRange:    c.WhereRange,
 			TaskMode:      c.Cfg.TaskMode,
 			TaskStatus:    common.TaskStatusWaiting,
@@ -246,12 +262,6 @@ func (c *Chunk) Split() error {
 		zap.String("table", c.SourceTable),
 		zap.Int("rows", tableRowsByStatistics))
 
-	// forth
-	// indexField > 程序已过滤筛选的字段 DB Filter integer column
-	if !strings.EqualFold(customColumn, "") {
-		c.WhereColumn = customColumn
-	}
-
 	taskName := common.StringsBuilder(common.StringUPPER(c.Cfg.OracleConfig.SchemaName), `_`, c.SourceTable, `_`, `TASK`, strconv.Itoa(c.ChunkID))
 
 	if err = c.Oracle.StartOracleChunkCreateTask(taskName); err != nil {
@@ -288,6 +298,8 @@ func (c *Chunk) Split() error {
 			TableNameT:    common.StringUPPER(c.TargetTable),
 			ColumnDetailT: c.TargetColumnInfo,
 			WhereColumn:   c.WhereColumn,
+			IdentityS:     c.IdentityS,
+			IdentityT:     c.IdentityT,
 			WhereRange:    c.WhereRange,
 			TaskMode:      c.Cfg.TaskMode,
 			TaskStatus:    common.TaskStatusWaiting,
@@ -323,6 +335,8 @@ func (c *Chunk) Split() error {
 			ColumnDetailT: c.TargetColumnInfo,
 			WhereRange:    r["CMD"],
 			WhereColumn:   c.WhereColumn,
+			IdentityS:     c.IdentityS,
+			IdentityT:     c.IdentityT,
 			IsPartition:   c.IsPartition,
 			TaskMode:      c.Cfg.TaskMode,
 			TaskStatus:    common.TaskStatusWaiting})
@@ -349,6 +363,8 @@ func (c *Chunk) Split() error {
 			ColumnDetailT: c.TargetColumnInfo,
 			WhereRange:    common.StringsBuilder(c.WhereColumn, " < ", r["START_ID"]),
 			WhereColumn:   c.WhereColumn,
+			IdentityS:     c.IdentityS,
+			IdentityT:     c.IdentityT,
 			IsPartition:   c.IsPartition,
 			TaskMode:      c.Cfg.TaskMode,
 			TaskStatus:    common.TaskStatusWaiting})
@@ -363,6 +379,8 @@ func (c *Chunk) Split() error {
 			ColumnDetailT: c.TargetColumnInfo,
 			WhereRange:    common.StringsBuilder(c.WhereColumn, " > ", res[0]["END_ID"]),
 			WhereColumn:   c.WhereColumn,
+			IdentityS:     c.IdentityS,
+			IdentityT:     c.IdentityT,
 			IsPartition:   c.IsPartition,
 			TaskMode:      c.Cfg.TaskMode,
 			TaskStatus:    common.TaskStatusWaiting})
@@ -17,6 +17,7 @@ package o2m
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/wentaojin/transferdb/common"
 	"github.com/wentaojin/transferdb/config"
@@ -77,7 +78,7 @@ func (r *O2M) NewCompare() error {
 	}
 
 	// 获取配置文件待同步表列表
-	exporters, err := filterCFGTable(r.cfg, r.oracle)
+	exporters, err := filterCFGTable(r.ctx, r.cfg, r.oracle, r.metaDB)
 	if err != nil {
 		return err
 	}
@@ -359,22 +360,23 @@ func (r *O2M) NewCompare() error {
 
 	// compare 任务列表
 	// 获取表名自定义规则
-	tableNameRules, err := meta.NewTableNameRuleModel(r.metaDB).DetailTableNameRule(r.ctx, &meta.TableNameRule{
+	tableRewriter, err := r.cfg.RewriteConfig.TableRewriter()
+	if err != nil {
+		return err
+	}
+	sourceTables, err := r.oracle.GetOracleSchemaTable(common.StringUPPER(r.cfg.OracleConfig.SchemaName))
+	if err != nil {
+		return err
+	}
+	tableNameRuleMap, err := meta.NewTableNameRuleModel(r.metaDB).DetailTableNameRuleMap(r.ctx, &meta.TableNameRule{
 		DBTypeS:     r.cfg.DBTypeS,
 		DBTypeT:     r.cfg.DBTypeT,
 		SchemaNameS: r.cfg.OracleConfig.SchemaName,
 		SchemaNameT: r.cfg.MySQLConfig.SchemaName,
-	})
+	}, sourceTables, tableRewriter)
 	if err != nil {
 		return err
 	}
-	tableNameRuleMap := make(map[string]string)
-
-	if len(tableNameRules) > 0 {
-		for _, tr := range tableNameRules {
-			tableNameRuleMap[common.StringUPPER(tr.TableNameS)] = common.StringUPPER(tr.TableNameT)
-		}
-	}
 
 	partTableTasks := NewPartCompareTableTask(r.ctx, r.cfg, partSyncTables, r.mysql, r.oracle, tableNameRuleMap)
 	waitTableTasks := NewWaitCompareTableTask(r.ctx, r.cfg, waitSyncTables, oracleCollation, r.mysql, r.oracle, tableNameRuleMap)
@@ -416,6 +418,13 @@ func (r *O2M) NewCompare() error {
 		}
 	}
 
+	if r.cfg.DiffConfig.SortOrderCheck {
+		err = r.compareSortOrder(f, exporters, oracleCollation, tableNameRuleMap)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = f.Close()
 	if err != nil {
 		return err
@@ -497,7 +506,7 @@ func (r *O2M) comparePartTableTasks(f *compare.File, partTableTasks []*Task) err
 		g1.SetLimit(r.cfg.DiffConfig.DiffThreads)
 
 		for _, compareMeta := range compareMetas {
-			newReport := NewReport(compareMeta, r.mysql, r.oracle, r.cfg.DiffConfig.OnlyCheckRows)
+			newReport := NewReport(compareMeta, r.mysql, r.oracle, r.cfg.DiffConfig.OnlyCheckRows, r.cfg.DiffConfig.ChecksumAlgo)
 			g1.Go(func() error {
 				// 数据对比报告
 				report, err := IReport(newReport)
@@ -529,6 +538,41 @@ func (r *O2M) comparePartTableTasks(f *compare.File, partTableTasks []*Task) err
 					if _, err := f.CWriteString(report); err != nil {
 						errMsg = fmt.Errorf("fix sql file write failed: %v", err.Error())
 					}
+					if newReport.Mismatch != nil {
+						newReport.Mismatch.FixFile = f.CFile.Name()
+						if err = meta.NewCompareSyncMetaModel(r.metaDB).SaveCompareSyncMeta(r.ctx, newReport.Mismatch); err != nil {
+							return err
+						}
+					}
+
+					// 行级修复 SQL 直接应用目标端，应用后重新校验 chunk，校验值一致才视为修复成功
+					if r.cfg.DiffConfig.EnableFix && len(newReport.FixSQL) > 0 {
+						var fixed bool
+						if err = r.mysql.ApplyMySQLFixSQL(newReport.FixSQL); err != nil {
+							errMsg = fmt.Errorf("fix sql apply failed: %v", err)
+						} else if fixed, err = newReport.RecheckCRC32(); err != nil {
+							errMsg = fmt.Errorf("fix sql applied, chunk recheck failed: %v", err)
+						} else if !fixed {
+							errMsg = fmt.Errorf("fix sql applied, but schema table data chunk still isn't euqal")
+						} else {
+							zap.L().Warn("compare chunk repaired",
+								zap.String("schema", newReport.DataCompareMeta.SchemaNameT),
+								zap.String("table", newReport.DataCompareMeta.TableNameT),
+								zap.String("range", newReport.DataCompareMeta.WhereRange),
+								zap.Int("fix sql counts", len(newReport.FixSQL)))
+							return meta.NewDataCompareMetaModel(r.metaDB).UpdateDataCompareMeta(r.ctx, &meta.DataCompareMeta{
+								DBTypeS:     newReport.DataCompareMeta.DBTypeS,
+								DBTypeT:     newReport.DataCompareMeta.DBTypeT,
+								SchemaNameS: newReport.DataCompareMeta.SchemaNameS,
+								TableNameS:  newReport.DataCompareMeta.TableNameS,
+								TaskMode:    newReport.DataCompareMeta.TaskMode,
+								WhereRange:  newReport.DataCompareMeta.WhereRange,
+							}, map[string]interface{}{
+								"TaskStatus": common.TaskStatusSuccess,
+								"InfoDetail": fmt.Sprintf("chunk repaired by fix sql, see file [%s]", f.CFile.Name()),
+							})
+						}
+					}
 					// error skip, continue
 					if err = meta.NewDataCompareMetaModel(r.metaDB).UpdateDataCompareMeta(r.ctx, &meta.DataCompareMeta{
 						DBTypeS:     newReport.DataCompareMeta.DBTypeS,
@@ -650,17 +694,39 @@ func (r *O2M) compareWaitTableTasks(f *compare.File, waitTableTasks []*Task) err
 		if err != nil {
 			return err
 		}
-		whereColumn, err := task.FilterDBWhereColumn()
+		identityS, identityT, err := task.FilterDBIdentityColumn()
 		if err != nil {
 			return err
 		}
+		whereColumn, err := task.FilterDBWhereColumn()
+		if err != nil {
+			// 存在行标识（联合键、代理键表达式）的表无 NUMBER 切分字段时整表对比
+			if len(identityS) == 0 {
+				return err
+			}
+			zap.L().Warn("compare table number where column isn't exist, compare the whole table by row identity",
+				zap.String("schema", r.cfg.OracleConfig.SchemaName),
+				zap.String("table", task.sourceTableName),
+				zap.Strings("identity", identityS),
+				zap.String("reason", err.Error()))
+			whereColumn = ""
+		}
 		isPartition, err := task.IsPartitionTable()
 		if err != nil {
 			return err
 		}
+		var identityJSONS, identityJSONT []byte
+		if len(identityS) > 0 {
+			if identityJSONS, err = json.Marshal(identityS); err != nil {
+				return err
+			}
+			if identityJSONT, err = json.Marshal(identityT); err != nil {
+				return err
+			}
+		}
 		chunks = append(chunks, NewChunk(r.ctx, r.cfg, r.oracle, r.mysql, r.metaDB,
 			cid, globalSCN, task.sourceTableName, task.targetTableName, isPartition, sourceColumnInfo, targetColumnInfo,
-			whereColumn))
+			whereColumn, string(identityJSONS), string(identityJSONT)))
 	}
 
 	// chunk split
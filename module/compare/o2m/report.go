@@ -31,10 +31,11 @@ import (
 )
 
 type DBSummary struct {
-	Columns   []string
-	StringSet *strset.Set
-	Crc32Val  uint32
-	Rows      int64
+	Columns    []string
+	StringSet  *strset.Set
+	Identities map[string][]string
+	Crc32Val   uint32
+	Rows       int64
 }
 
 type Report struct {
@@ -42,31 +43,84 @@ type Report struct {
 	Mysql           *mysql.MySQL         `json:"-"`
 	Oracle          *oracle.Oracle       `json:"-"`
 	OnlyCheckRows   bool                 `json:"only_check_rows"`
+	ChecksumAlgo    string               `json:"checksum_algo"`
+	// 数据不一致时记录 chunk 明细，写入 compare_sync_meta
+	Mismatch *meta.CompareSyncMeta `json:"-"`
+	// 行级修复 SQL，enable-fix 时直接应用到目标端
+	FixSQL []string `json:"-"`
+	// 行标识表达式，存在时按行标识定位目标端数据行
+	IdentityS []string `json:"-"`
+	IdentityT []string `json:"-"`
 }
 
-func NewReport(dataCompareMeta meta.DataCompareMeta, mysql *mysql.MySQL, oracle *oracle.Oracle, onlyCheckRows bool) *Report {
+func NewReport(dataCompareMeta meta.DataCompareMeta, mysql *mysql.MySQL, oracle *oracle.Oracle, onlyCheckRows bool, checksumAlgo string) *Report {
 	return &Report{
 		DataCompareMeta: dataCompareMeta,
 		Mysql:           mysql,
 		Oracle:          oracle,
 		OnlyCheckRows:   onlyCheckRows,
+		ChecksumAlgo:    checksumAlgo,
 	}
 }
 
+func (r *Report) newMismatch() *meta.CompareSyncMeta {
+	return &meta.CompareSyncMeta{
+		DBTypeS:      r.DataCompareMeta.DBTypeS,
+		DBTypeT:      r.DataCompareMeta.DBTypeT,
+		SchemaNameS:  r.DataCompareMeta.SchemaNameS,
+		TableNameS:   r.DataCompareMeta.TableNameS,
+		SchemaNameT:  r.DataCompareMeta.SchemaNameT,
+		TableNameT:   r.DataCompareMeta.TableNameT,
+		WhereRange:   r.DataCompareMeta.WhereRange,
+		ChecksumAlgo: r.ChecksumAlgo,
+		TaskMode:     r.DataCompareMeta.TaskMode,
+	}
+}
+
+// parseIdentity 解析 chunk 行标识表达式
+func (r *Report) parseIdentity() error {
+	if r.DataCompareMeta.IdentityS == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(r.DataCompareMeta.IdentityS), &r.IdentityS); err != nil {
+		return fmt.Errorf("oracle table [%s.%s] identity [%s] unmarshal failed: %v", r.DataCompareMeta.SchemaNameS, r.DataCompareMeta.TableNameS, r.DataCompareMeta.IdentityS, err)
+	}
+	if err := json.Unmarshal([]byte(r.DataCompareMeta.IdentityT), &r.IdentityT); err != nil {
+		return fmt.Errorf("mysql table [%s.%s] identity [%s] unmarshal failed: %v", r.DataCompareMeta.SchemaNameT, r.DataCompareMeta.TableNameT, r.DataCompareMeta.IdentityT, err)
+	}
+	if len(r.IdentityS) != len(r.IdentityT) {
+		return fmt.Errorf("oracle table [%s.%s] identity counts [%d] isn't equal mysql identity counts [%d]", r.DataCompareMeta.SchemaNameS, r.DataCompareMeta.TableNameS, len(r.IdentityS), len(r.IdentityT))
+	}
+	return nil
+}
+
 func (r *Report) GenDBQuery() (oracleQuery string, mysqlQuery string) {
+	// 行标识字段前置查询，仅用于数据行定位
+	columnDetailS, columnDetailT := r.DataCompareMeta.ColumnDetailS, r.DataCompareMeta.ColumnDetailT
+	if !r.OnlyCheckRows && len(r.IdentityS) > 0 {
+		var identityS, identityT []string
+		for i := range r.IdentityS {
+			alias := fmt.Sprintf("TRANSFERDB_IDENTITY_%d", i)
+			identityS = append(identityS, common.StringsBuilder(r.IdentityS[i], " AS ", alias))
+			identityT = append(identityT, common.StringsBuilder(r.IdentityT[i], " AS ", alias))
+		}
+		columnDetailS = common.StringsBuilder(strings.Join(identityS, ","), ",", columnDetailS)
+		columnDetailT = common.StringsBuilder(strings.Join(identityT, ","), ",", columnDetailT)
+	}
+
 	if r.DataCompareMeta.WhereColumn == "" {
 		oracleQuery = common.StringsBuilder(
-			"SELECT ", r.DataCompareMeta.ColumnDetailS, " FROM ", r.DataCompareMeta.SchemaNameS, ".", r.DataCompareMeta.TableNameS, " WHERE ", r.DataCompareMeta.WhereRange)
+			"SELECT ", columnDetailS, " FROM ", r.DataCompareMeta.SchemaNameS, ".", r.DataCompareMeta.TableNameS, " WHERE ", r.DataCompareMeta.WhereRange)
 
 		mysqlQuery = common.StringsBuilder(
-			"SELECT ", r.DataCompareMeta.ColumnDetailT, " FROM ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameT, " WHERE ", r.DataCompareMeta.WhereRange)
+			"SELECT ", columnDetailT, " FROM ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameT, " WHERE ", r.DataCompareMeta.WhereRange)
 	} else {
 		oracleQuery = common.StringsBuilder(
-			"SELECT ", r.DataCompareMeta.ColumnDetailS, " FROM ", r.DataCompareMeta.SchemaNameS, ".", r.DataCompareMeta.TableNameS, " WHERE ", r.DataCompareMeta.WhereRange,
+			"SELECT ", columnDetailS, " FROM ", r.DataCompareMeta.SchemaNameS, ".", r.DataCompareMeta.TableNameS, " WHERE ", r.DataCompareMeta.WhereRange,
 			" ORDER BY ", r.DataCompareMeta.WhereColumn, " DESC")
 
 		mysqlQuery = common.StringsBuilder(
-			"SELECT ", r.DataCompareMeta.ColumnDetailT, " FROM ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameT, " WHERE ", r.DataCompareMeta.WhereRange, " ORDER BY ", r.DataCompareMeta.WhereColumn, " DESC")
+			"SELECT ", columnDetailT, " FROM ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameT, " WHERE ", r.DataCompareMeta.WhereRange, " ORDER BY ", r.DataCompareMeta.WhereColumn, " DESC")
 	}
 	return
 }
@@ -145,6 +199,10 @@ func (r *Report) ReportCheckRows() (string, error) {
 		zap.String("oracle sql", oracleQuery),
 		zap.String("mysql sql", mysqlQuery))
 
+	r.Mismatch = r.newMismatch()
+	r.Mismatch.RowsS = oracleRows
+	r.Mismatch.RowsT = mysqlRows
+
 	sw := table.NewWriter()
 	sw.SetStyle(table.StyleLight)
 	sw.AppendHeader(table.Row{"SOURCE TABLE", "SOURCE SQL", "SOURCE COUNTS", "TARGET TABLE", "TARGET SQL", "TARGET TABLE COUNTS", "RANGE"})
@@ -171,30 +229,35 @@ func (r *Report) ReportCheckCRC32() (string, error) {
 	oraChan := make(chan DBSummary, 1)
 	mysqlChan := make(chan DBSummary, 1)
 
+	if err := r.parseIdentity(); err != nil {
+		return "", err
+	}
 	oracleQuery, mysqlQuery := r.GenDBQuery()
 
 	errORA.Go(func() error {
-		oraColumns, oraStringSet, oraCrc32Val, err := r.Oracle.GetOracleDataRowStrings(oracleQuery)
+		oraColumns, oraStringSet, oraIdentities, oraCrc32Val, err := r.Oracle.GetOracleDataRowStrings(oracleQuery, r.ChecksumAlgo, len(r.IdentityS))
 		if err != nil {
 			return fmt.Errorf("get oracle data row strings failed: %v", err)
 		}
 		oraChan <- DBSummary{
-			Columns:   oraColumns,
-			StringSet: oraStringSet,
-			Crc32Val:  oraCrc32Val,
+			Columns:    oraColumns,
+			StringSet:  oraStringSet,
+			Identities: oraIdentities,
+			Crc32Val:   oraCrc32Val,
 		}
 		return nil
 	})
 
 	errMySQL.Go(func() error {
-		mysqlColumns, mysqlStringSet, mysqlCrc32Val, err := r.Mysql.GetMySQLDataRowStrings(mysqlQuery)
+		mysqlColumns, mysqlStringSet, mysqlIdentities, mysqlCrc32Val, err := r.Mysql.GetMySQLDataRowStrings(mysqlQuery, r.ChecksumAlgo, len(r.IdentityT))
 		if err != nil {
 			return fmt.Errorf("get mysql data row strings failed: %v", err)
 		}
 		mysqlChan <- DBSummary{
-			Columns:   mysqlColumns,
-			StringSet: mysqlStringSet,
-			Crc32Val:  mysqlCrc32Val,
+			Columns:    mysqlColumns,
+			StringSet:  mysqlStringSet,
+			Identities: mysqlIdentities,
+			Crc32Val:   mysqlCrc32Val,
 		}
 		return nil
 	})
@@ -242,67 +305,153 @@ func (r *Report) ReportCheckCRC32() (string, error) {
 
 	// 判断下游数据是否多
 	targetMore := strset.Difference(mysqlReport.StringSet, oraReport.StringSet).List()
+	sourceMore := strset.Difference(oraReport.StringSet, mysqlReport.StringSet).List()
+
+	r.Mismatch = r.newMismatch()
+	r.Mismatch.ChecksumS = oraReport.Crc32Val
+	r.Mismatch.ChecksumT = mysqlReport.Crc32Val
+	r.Mismatch.RowsS = int64(oraReport.StringSet.Size())
+	r.Mismatch.RowsT = int64(mysqlReport.StringSet.Size())
+	r.Mismatch.SourceMoreRows = int64(len(sourceMore))
+	r.Mismatch.TargetMoreRows = int64(len(targetMore))
 	if len(targetMore) > 0 {
 		fixSQL.WriteString("/*\n")
 		fixSQL.WriteString(fmt.Sprintf(" mysql table [%s.%s] chunk [%s] data rows are more \n", r.DataCompareMeta.SchemaNameT, r.DataCompareMeta.TableNameT, r.DataCompareMeta.WhereRange))
 
 		sw := table.NewWriter()
 		sw.SetStyle(table.StyleLight)
-		sw.AppendHeader(table.Row{"DATABASE", "DATA COUNTS SQL", "CRC32"})
+		sw.AppendHeader(table.Row{"DATABASE", "DATA COUNTS SQL", r.ChecksumAlgo})
 		sw.AppendRows([]table.Row{
 			{"ORACLE",
 				common.StringsBuilder("SELECT COUNT(1)", " FROM ", r.DataCompareMeta.SchemaNameS, ".", r.DataCompareMeta.TableNameS, " WHERE ", r.DataCompareMeta.WhereRange),
 				oraReport.Crc32Val},
 			{"MySQL", common.StringsBuilder(
-				"SELECT COUNT(1)", " FROM ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameS, " WHERE ", r.DataCompareMeta.WhereRange),
+				"SELECT COUNT(1)", " FROM ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameT, " WHERE ", r.DataCompareMeta.WhereRange),
 				mysqlReport.Crc32Val},
 		})
 		fixSQL.WriteString(fmt.Sprintf("%v\n", sw.Render()))
 		fixSQL.WriteString("*/\n")
-		deletePrefix := common.StringsBuilder("DELETE FROM ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameS, " WHERE ")
+		deletePrefix := common.StringsBuilder("DELETE FROM ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameT, " WHERE ")
+		// 存在行标识按行标识删除，同一行标识上下游数据不一致时先删除再由 REPLACE 补齐
+		if len(r.IdentityT) > 0 {
+			deleteSQLs, err := r.genIdentityDeleteSQL(deletePrefix, targetMore, mysqlReport.Identities)
+			if err != nil {
+				return "", err
+			}
+			for _, deleteSQL := range deleteSQLs {
+				r.FixSQL = append(r.FixSQL, deleteSQL)
+				fixSQL.WriteString(fmt.Sprintf("%v;\n", deleteSQL))
+			}
+			targetMore = nil
+		}
 		for _, t := range targetMore {
 			var whereCond []string
 
 			// 计算字段列个数
 			colValues := strings.Split(t, ",")
 			if len(mysqlReport.Columns) != len(colValues) {
-				return "", fmt.Errorf("mysql schema [%s] table [%s] column counts [%d] isn't match values counts [%d]", r.DataCompareMeta.SchemaNameT, r.DataCompareMeta.TableNameS, len(mysqlReport.Columns), len(colValues))
+				return "", fmt.Errorf("mysql schema [%s] table [%s] column counts [%d] isn't match values counts [%d]", r.DataCompareMeta.SchemaNameT, r.DataCompareMeta.TableNameT, len(mysqlReport.Columns), len(colValues))
 			}
 			for i := 0; i < len(mysqlReport.Columns); i++ {
-				whereCond = append(whereCond, common.StringsBuilder(mysqlReport.Columns[i], "=", colValues[i]))
+				// NULL 值无法等值匹配
+				if colValues[i] == "NULL" {
+					whereCond = append(whereCond, common.StringsBuilder(mysqlReport.Columns[i], " IS NULL"))
+				} else {
+					whereCond = append(whereCond, common.StringsBuilder(mysqlReport.Columns[i], " = ", colValues[i]))
+				}
 			}
 
-			fixSQL.WriteString(fmt.Sprintf("%v;\n", common.StringsBuilder(deletePrefix, exstrings.Join(whereCond, " AND "))))
+			// 无行标识时全字段匹配可能命中多行重复数据，每次仅删除一行，修复结果以重新校验为准
+			deleteSQL := common.StringsBuilder(deletePrefix, exstrings.Join(whereCond, " AND "), " LIMIT 1")
+			r.FixSQL = append(r.FixSQL, deleteSQL)
+			fixSQL.WriteString(fmt.Sprintf("%v;\n", deleteSQL))
 		}
 	}
 
 	// 判断上游数据是否多
-	sourceMore := strset.Difference(oraReport.StringSet, mysqlReport.StringSet).List()
 	if len(sourceMore) > 0 {
 		fixSQL.WriteString("/*\n")
-		fixSQL.WriteString(fmt.Sprintf(" mysql table [%s.%s] chunk [%s] data rows are less \n", r.DataCompareMeta.SchemaNameT, r.DataCompareMeta.TableNameS, r.DataCompareMeta.WhereRange))
+		fixSQL.WriteString(fmt.Sprintf(" mysql table [%s.%s] chunk [%s] data rows are less \n", r.DataCompareMeta.SchemaNameT, r.DataCompareMeta.TableNameT, r.DataCompareMeta.WhereRange))
 
 		sw := table.NewWriter()
 		sw.SetStyle(table.StyleLight)
-		sw.AppendHeader(table.Row{"DATABASE", "DATA COUNTS SQL", "CRC32"})
+		sw.AppendHeader(table.Row{"DATABASE", "DATA COUNTS SQL", r.ChecksumAlgo})
 		sw.AppendRows([]table.Row{
 			{"ORACLE",
 				common.StringsBuilder("SELECT COUNT(1)", " FROM ", r.DataCompareMeta.SchemaNameS, ".", r.DataCompareMeta.TableNameS, " WHERE ", r.DataCompareMeta.WhereRange),
 				oraReport.Crc32Val},
 			{"MySQL", common.StringsBuilder(
-				"SELECT COUNT(1)", " FROM ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameS, " WHERE ", r.DataCompareMeta.WhereRange),
+				"SELECT COUNT(1)", " FROM ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameT, " WHERE ", r.DataCompareMeta.WhereRange),
 				mysqlReport.Crc32Val},
 		})
 		fixSQL.WriteString(fmt.Sprintf("%v\n", sw.Render()))
 		fixSQL.WriteString("*/\n")
-		insertPrefix := common.StringsBuilder("INSERT INTO ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameS, " (", strings.Join(oraReport.Columns, ","), ") VALUES (")
+		// REPLACE 覆盖目标端主键/唯一键相同但数据不一致的行
+		replacePrefix := common.StringsBuilder("REPLACE INTO ", r.DataCompareMeta.SchemaNameT, ".", r.DataCompareMeta.TableNameT, " (", strings.Join(oraReport.Columns, ","), ") VALUES (")
 		for _, s := range sourceMore {
-			fixSQL.WriteString(fmt.Sprintf("%v;\n", common.StringsBuilder(insertPrefix, s, ")")))
+			replaceSQL := common.StringsBuilder(replacePrefix, s, ")")
+			r.FixSQL = append(r.FixSQL, replaceSQL)
+			fixSQL.WriteString(fmt.Sprintf("%v;\n", replaceSQL))
 		}
 	}
 	return fixSQL.String(), nil
 }
 
+// RecheckCRC32 修复 SQL 应用后重新计算 chunk 校验值，上下游一致才视为修复成功
+func (r *Report) RecheckCRC32() (bool, error) {
+	oracleQuery, mysqlQuery := r.GenDBQuery()
+
+	var oraCrc32Val, mysqlCrc32Val uint32
+	g := &errgroup.Group{}
+	g.Go(func() error {
+		_, _, _, crc32Val, err := r.Oracle.GetOracleDataRowStrings(oracleQuery, r.ChecksumAlgo, len(r.IdentityS))
+		if err != nil {
+			return fmt.Errorf("recheck oracle data row strings failed: %v", err)
+		}
+		oraCrc32Val = crc32Val
+		return nil
+	})
+	g.Go(func() error {
+		_, _, _, crc32Val, err := r.Mysql.GetMySQLDataRowStrings(mysqlQuery, r.ChecksumAlgo, len(r.IdentityT))
+		if err != nil {
+			return fmt.Errorf("recheck mysql data row strings failed: %v", err)
+		}
+		mysqlCrc32Val = crc32Val
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return false, err
+	}
+	return oraCrc32Val == mysqlCrc32Val, nil
+}
+
+func (r *Report) genIdentityDeleteSQL(deletePrefix string, targetMore []string, identities map[string][]string) ([]string, error) {
+	var deleteSQLs []string
+	seen := make(map[string]struct{})
+	for _, t := range targetMore {
+		values, ok := identities[t]
+		if !ok || len(values) != len(r.IdentityT) {
+			return deleteSQLs, fmt.Errorf("mysql schema [%s] table [%s] row [%s] identity isn't found", r.DataCompareMeta.SchemaNameT, r.DataCompareMeta.TableNameT, t)
+		}
+		var whereCond []string
+		for i, v := range values {
+			// NULL 值无法等值匹配
+			if v == "NULL" {
+				whereCond = append(whereCond, common.StringsBuilder(r.IdentityT[i], " IS NULL"))
+			} else {
+				whereCond = append(whereCond, common.StringsBuilder(r.IdentityT[i], " = ", v))
+			}
+		}
+		deleteSQL := common.StringsBuilder(deletePrefix, exstrings.Join(whereCond, " AND "))
+		if _, ok := seen[deleteSQL]; ok {
+			continue
+		}
+		seen[deleteSQL] = struct{}{}
+		deleteSQLs = append(deleteSQLs, deleteSQL)
+	}
+	return deleteSQLs, nil
+}
+
 func (r *Report) Report() (string, error) {
 	if r.OnlyCheckRows {
 		return r.ReportCheckRows()
@@ -0,0 +1,128 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/module/compare"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+// compareSortOrder 语言排序等价校验
+// 抽样字符字段去重值，对比 oracle NLSSORT 排序与 mysql collation 排序结果，不一致输出至 compare 文件注释，不生成修复 SQL
+func (r *O2M) compareSortOrder(f *compare.File, exporters []string, oracleCollation bool, tableNameRuleMap map[string]string) error {
+	startTime := time.Now()
+
+	sampleRows := r.cfg.DiffConfig.SortSampleRows
+	if sampleRows <= 0 {
+		sampleRows = common.CompareSortSampleRows
+	}
+	sessionSort := common.StringUPPER(r.cfg.DiffConfig.SortNLSSort)
+
+	var tableRowArray []table.Row
+	for _, tableName := range exporters {
+		targetTable := tableName
+		if val, ok := tableNameRuleMap[common.StringUPPER(tableName)]; ok {
+			targetTable = val
+		}
+
+		columns, err := r.oracle.GetOracleSchemaTableColumn(r.cfg.OracleConfig.SchemaName, tableName, oracleCollation)
+		if err != nil {
+			return err
+		}
+		for _, col := range columns {
+			if !common.IsContainString([]string{"CHAR", "NCHAR", "VARCHAR2", "NVARCHAR2"}, common.StringUPPER(col["DATA_TYPE"])) {
+				continue
+			}
+			// 字段级语言排序优先，否则使用应用会话 NLS_SORT
+			nlsSort := sessionSort
+			if common.IsOracleLinguisticSort(col["COLLATION"]) {
+				nlsSort = common.StringUPPER(col["COLLATION"])
+			}
+			if !common.IsOracleLinguisticSort(nlsSort) {
+				continue
+			}
+
+			oraValues, err := r.oracle.GetOracleTableColumnSortSample(r.cfg.OracleConfig.SchemaName, tableName, col["COLUMN_NAME"], nlsSort, sampleRows)
+			if err != nil {
+				return err
+			}
+			mysqlValues, err := r.mysql.GetMySQLTableColumnSortValue(r.cfg.MySQLConfig.SchemaName, targetTable, col["COLUMN_NAME"], oraValues)
+			if err != nil {
+				return err
+			}
+
+			if pos, ok := diffSortOrder(oraValues, mysqlValues); !ok {
+				var oraVal, mysqlVal string
+				if pos < len(oraValues) {
+					oraVal = oraValues[pos]
+				}
+				if pos < len(mysqlValues) {
+					mysqlVal = mysqlValues[pos]
+				}
+				tableRowArray = append(tableRowArray, table.Row{
+					fmt.Sprintf("%s.%s", r.cfg.OracleConfig.SchemaName, tableName), col["COLUMN_NAME"], nlsSort,
+					len(oraValues), len(mysqlValues), pos + 1, oraVal, mysqlVal})
+				zap.L().Warn("compare table column sort order differ",
+					zap.String("schema", r.cfg.OracleConfig.SchemaName),
+					zap.String("table", tableName),
+					zap.String("column", col["COLUMN_NAME"]),
+					zap.String("nls sort", nlsSort),
+					zap.Int("first differ position", pos+1))
+			}
+		}
+	}
+
+	if len(tableRowArray) != 0 {
+		textTable := table.NewWriter()
+		textTable.SetStyle(table.StyleLight)
+		textTable.AppendHeader(table.Row{"Table", "Column", "NLS Sort", "ORACLE Samples", "MySQL Samples", "Position", "ORACLE Value", "MySQL Value"})
+		textTable.AppendRows(tableRowArray)
+
+		var builder strings.Builder
+		builder.WriteString("/*\n")
+		builder.WriteString(" oracle linguistic sort order isn't equal mysql collation sort order, order by result maybe differ\n")
+		builder.WriteString(fmt.Sprintf("%s\n", textTable.Render()))
+		builder.WriteString("*/\n")
+		if _, err := f.CWriteString(builder.String()); err != nil {
+			return err
+		}
+	}
+
+	zap.L().Info("compare table column sort order finished",
+		zap.String("schema", r.cfg.OracleConfig.SchemaName),
+		zap.Int("table totals", len(exporters)),
+		zap.Int("column differ", len(tableRowArray)),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
+
+// diffSortOrder 返回首个排序不一致位置，mysql collation 合并大小写等值时行数亦不一致
+func diffSortOrder(oraValues, mysqlValues []string) (int, bool) {
+	for i := range oraValues {
+		if i >= len(mysqlValues) || oraValues[i] != mysqlValues[i] {
+			return i, false
+		}
+	}
+	if len(mysqlValues) != len(oraValues) {
+		return len(oraValues), false
+	}
+	return 0, true
+}
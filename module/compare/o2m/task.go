@@ -130,61 +130,163 @@ func PreTableStructCheck(ctx context.Context, cfg *config.Config, metaDB *meta.M
 // 字段查询以 ORACLE 字段为主
 // Date/Timestamp 字段类型格式化
 // Interval Year/Day 数据字符 TO_CHAR 格式化
+// 表级 ignore-columns 配置字段不参与查询对比
 func (t *Task) AdjustDBSelectColumn() (sourceColumnInfo string, targetColumnInfo string, err error) {
 	var (
 		sourceColumnInfos, targetColumnInfos []string
+		ignoreColumns                        []string
 	)
 	columnInfo, err := t.oracle.GetOracleSchemaTableColumn(t.cfg.OracleConfig.SchemaName, t.sourceTableName, t.oracleCollation)
 	if err != nil {
 		return sourceColumnInfo, targetColumnInfo, err
 	}
 
+	for _, tableCfg := range t.cfg.DiffConfig.TableConfig {
+		if strings.EqualFold(t.sourceTableName, tableCfg.SourceTable) {
+			for _, c := range tableCfg.IgnoreColumns {
+				ignoreColumns = append(ignoreColumns, common.StringUPPER(c))
+			}
+		}
+	}
+
 	for _, colsInfo := range columnInfo {
 		colName := colsInfo["COLUMN_NAME"]
-		switch strings.ToUpper(colsInfo["DATA_TYPE"]) {
-		// 数字
-		case "NUMBER":
-			sourceColumnInfos = append(sourceColumnInfos, common.StringsBuilder("DECODE(SUBSTR(", colName, ",1,1),'.','0' || ", colName, ",", colName, ") AS ", colName))
-			targetColumnInfos = append(targetColumnInfos, common.StringsBuilder("CAST(0 + CAST(", colName, " AS CHAR) AS CHAR) AS ", colName))
-		case "DECIMAL", "DEC", "DOUBLE PRECISION", "FLOAT", "INTEGER", "INT", "REAL", "NUMERIC", "BINARY_FLOAT", "BINARY_DOUBLE", "SMALLINT":
-			sourceColumnInfos = append(sourceColumnInfos, common.StringsBuilder("DECODE(SUBSTR(", colName, ",1,1),'.','0' || ", colName, ",", colName, ") AS ", colName))
-			targetColumnInfos = append(targetColumnInfos, common.StringsBuilder("CAST(0 + CAST(", colName, " AS CHAR) AS CHAR) AS ", colName))
-		// 字符
-		case "BFILE", "CHARACTER", "LONG", "NCHAR VARYING", "ROWID", "UROWID", "VARCHAR", "CHAR", "NCHAR", "NVARCHAR2", "NCLOB", "CLOB":
-			sourceColumnInfos = append(sourceColumnInfos, common.StringsBuilder("NVL(", colName, ",'') AS ", colName))
-			targetColumnInfos = append(targetColumnInfos, common.StringsBuilder("IFNULL(", colName, ",'') AS ", colName))
-		case "XMLTYPE":
-			sourceColumnInfos = append(sourceColumnInfos, common.StringsBuilder("NVL(XMLSERIALIZE(CONTENT ", colName, " AS CLOB),'') AS ", colName))
-			targetColumnInfos = append(targetColumnInfos, common.StringsBuilder("IFNULL(", colName, ",'') AS ", colName))
-		// 二进制
-		case "BLOB", "LONG RAW", "RAW":
+		if common.IsContainString(ignoreColumns, common.StringUPPER(colName)) {
+			continue
+		}
+		sourceExpr, targetExpr := adjustDBColumnExpr(colName, colsInfo["DATA_TYPE"])
+		if sourceExpr == colName {
 			sourceColumnInfos = append(sourceColumnInfos, colName)
+		} else {
+			sourceColumnInfos = append(sourceColumnInfos, common.StringsBuilder(sourceExpr, " AS ", colName))
+		}
+		if targetExpr == colName {
 			targetColumnInfos = append(targetColumnInfos, colName)
-		// 时间
-		case "DATE":
-			sourceColumnInfos = append(sourceColumnInfos, common.StringsBuilder("TO_CHAR(", colName, ",'yyyy-MM-dd HH24:mi:ss') AS ", colName))
-			targetColumnInfos = append(targetColumnInfos, common.StringsBuilder("DATE_FORMAT(", colName, ",'%Y-%m-%d %H:%i:%s') AS ", colName))
-		// 默认其他类型
-		default:
-			if strings.Contains(colsInfo["DATA_TYPE"], "INTERVAL") {
-				sourceColumnInfos = append(sourceColumnInfos, common.StringsBuilder("TO_CHAR(", colName, ") AS ", colName))
-				targetColumnInfos = append(targetColumnInfos, colName)
-			} else if strings.Contains(colsInfo["DATA_TYPE"], "TIMESTAMP") {
-				sourceColumnInfos = append(sourceColumnInfos, common.StringsBuilder("TO_CHAR(", colName, ",'yyyy-MM-dd HH24:mi:ss') AS ", colName))
-				targetColumnInfos = append(targetColumnInfos, common.StringsBuilder("FROM_UNIXTIME(UNIX_TIMESTAMP(", colName, "),'%Y-%m-%d %H:%i:%s') AS ", colName))
-			} else {
-				sourceColumnInfos = append(sourceColumnInfos, colName)
-				targetColumnInfos = append(targetColumnInfos, colName)
-			}
+		} else {
+			targetColumnInfos = append(targetColumnInfos, common.StringsBuilder(targetExpr, " AS ", colName))
 		}
 	}
 
+	if len(sourceColumnInfos) == 0 {
+		return sourceColumnInfo, targetColumnInfo, fmt.Errorf("oracle schema [%s] table [%s] all columns are ignored by config ignore-columns [%v]",
+			t.cfg.OracleConfig.SchemaName, t.sourceTableName, ignoreColumns)
+	}
+	if len(ignoreColumns) > 0 {
+		zap.L().Info("compare table ignore columns",
+			zap.String("schema", t.cfg.OracleConfig.SchemaName),
+			zap.String("table", t.sourceTableName),
+			zap.Strings("ignore columns", ignoreColumns))
+	}
+
 	sourceColumnInfo = strings.Join(sourceColumnInfos, ",")
 	targetColumnInfo = strings.Join(targetColumnInfos, ",")
 
 	return sourceColumnInfo, targetColumnInfo, nil
 }
 
+// adjustDBColumnExpr 上下游字段对比格式化表达式
+func adjustDBColumnExpr(colName, dataType string) (string, string) {
+	switch strings.ToUpper(dataType) {
+	// 数字
+	case "NUMBER":
+		return common.StringsBuilder("DECODE(SUBSTR(", colName, ",1,1),'.','0' || ", colName, ",", colName, ")"),
+			common.StringsBuilder("CAST(0 + CAST(", colName, " AS CHAR) AS CHAR)")
+	case "DECIMAL", "DEC", "DOUBLE PRECISION", "FLOAT", "INTEGER", "INT", "REAL", "NUMERIC", "BINARY_FLOAT", "BINARY_DOUBLE", "SMALLINT":
+		return common.StringsBuilder("DECODE(SUBSTR(", colName, ",1,1),'.','0' || ", colName, ",", colName, ")"),
+			common.StringsBuilder("CAST(0 + CAST(", colName, " AS CHAR) AS CHAR)")
+	// 字符
+	case "BFILE", "CHARACTER", "LONG", "NCHAR VARYING", "ROWID", "UROWID", "VARCHAR", "CHAR", "NCHAR", "NVARCHAR2", "NCLOB", "CLOB":
+		return common.StringsBuilder("NVL(", colName, ",'')"), common.StringsBuilder("IFNULL(", colName, ",'')")
+	case "XMLTYPE":
+		return common.StringsBuilder("NVL(XMLSERIALIZE(CONTENT ", colName, " AS CLOB),'')"), common.StringsBuilder("IFNULL(", colName, ",'')")
+	// 二进制
+	case "BLOB", "LONG RAW", "RAW":
+		return colName, colName
+	// 时间
+	case "DATE":
+		return common.StringsBuilder("TO_CHAR(", colName, ",'yyyy-MM-dd HH24:mi:ss')"), common.StringsBuilder("DATE_FORMAT(", colName, ",'%Y-%m-%d %H:%i:%s')")
+	// 默认其他类型
+	default:
+		if strings.Contains(dataType, "INTERVAL") {
+			return common.StringsBuilder("TO_CHAR(", colName, ")"), colName
+		} else if strings.Contains(dataType, "TIMESTAMP") {
+			return common.StringsBuilder("TO_CHAR(", colName, ",'yyyy-MM-dd HH24:mi:ss')"), common.StringsBuilder("FROM_UNIXTIME(UNIX_TIMESTAMP(", colName, "),'%Y-%m-%d %H:%i:%s')")
+		}
+		return colName, colName
+	}
+}
+
+// FilterDBIdentityColumn 行标识表达式，用于行级匹配以及修复 SQL 定位数据行
+// 优先级：配置文件 identity-key > PK > UK > 唯一索引，支持联合键，均不存在返回空
+func (t *Task) FilterDBIdentityColumn() ([]string, []string, error) {
+	var identityS, identityT []string
+	for _, tableCfg := range t.cfg.DiffConfig.TableConfig {
+		if strings.EqualFold(t.sourceTableName, tableCfg.SourceTable) && len(tableCfg.IdentityKeyS) > 0 {
+			if len(tableCfg.IdentityKeyS) != len(tableCfg.IdentityKeyT) {
+				return identityS, identityT, fmt.Errorf("oracle schema [%s] table [%s] config identity-key-s counts [%d] isn't equal identity-key-t counts [%d]",
+					t.cfg.OracleConfig.SchemaName, t.sourceTableName, len(tableCfg.IdentityKeyS), len(tableCfg.IdentityKeyT))
+			}
+			return tableCfg.IdentityKeyS, tableCfg.IdentityKeyT, nil
+		}
+	}
+
+	columnInfo, err := t.oracle.GetOracleSchemaTableColumn(t.cfg.OracleConfig.SchemaName, t.sourceTableName, t.oracleCollation)
+	if err != nil {
+		return identityS, identityT, err
+	}
+	columnType := make(map[string]string)
+	for _, colsInfo := range columnInfo {
+		columnType[strings.ToUpper(colsInfo["COLUMN_NAME"])] = colsInfo["DATA_TYPE"]
+	}
+
+	var keys []string
+	pkInfo, err := t.oracle.GetOracleSchemaTablePrimaryKey(t.cfg.OracleConfig.SchemaName, t.sourceTableName)
+	if err != nil {
+		return identityS, identityT, err
+	}
+	for _, pk := range pkInfo {
+		keys = append(keys, strings.ToUpper(pk["COLUMN_LIST"]))
+	}
+	ukInfo, err := t.oracle.GetOracleSchemaTableUniqueKey(t.cfg.OracleConfig.SchemaName, t.sourceTableName)
+	if err != nil {
+		return identityS, identityT, err
+	}
+	for _, uk := range ukInfo {
+		keys = append(keys, strings.ToUpper(uk["COLUMN_LIST"]))
+	}
+	indexInfo, err := t.oracle.GetOracleSchemaTableUniqueIndex(t.cfg.OracleConfig.SchemaName, t.sourceTableName)
+	if err != nil {
+		return identityS, identityT, err
+	}
+	for _, idx := range indexInfo {
+		// 函数索引列为系统虚拟列，需配置 identity-key 代理键表达式
+		if strings.EqualFold(idx["INDEX_TYPE"], "NORMAL") {
+			keys = append(keys, strings.ToUpper(idx["COLUMN_LIST"]))
+		}
+	}
+
+	for _, key := range keys {
+		var (
+			exprS, exprT []string
+			isValid      = true
+		)
+		for _, col := range strings.Split(key, ",") {
+			dataType, ok := columnType[col]
+			if !ok {
+				isValid = false
+				break
+			}
+			colExprS, colExprT := adjustDBColumnExpr(col, dataType)
+			exprS = append(exprS, colExprS)
+			exprT = append(exprT, colExprT)
+		}
+		if isValid {
+			return exprS, exprT, nil
+		}
+	}
+	return identityS, identityT, nil
+}
+
 // 筛选 NUMBER 字段以及判断表是否存在主键/唯一键/唯一索引
 // 第一优先级配置文件指定字段【忽略是否存在索引】
 // 第二优先级任意取某个主键/唯一索引 NUMBER 字段
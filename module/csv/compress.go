@@ -0,0 +1,78 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package csv
+
+import (
+	"compress/gzip"
+	"fmt"
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/wentaojin/transferdb/common"
+	"io"
+)
+
+// CompressFileExt csv 文件扩展名，压缩时追加压缩格式后缀
+func CompressFileExt(compress string) string {
+	switch common.StringUPPER(compress) {
+	case common.MigrateCSVCompressGzip:
+		return ".csv.gz"
+	case common.MigrateCSVCompressZstd:
+		return ".csv.zst"
+	case common.MigrateCSVCompressSnappy:
+		return ".csv.snappy"
+	default:
+		return ".csv"
+	}
+}
+
+// NewCompressWriter 包装文件写入器，未配置压缩原样写入，Close 只关闭压缩流不关闭底层文件
+func NewCompressWriter(compress string, w io.Writer) (io.WriteCloser, error) {
+	switch common.StringUPPER(compress) {
+	case "":
+		return nopWriteCloser{w}, nil
+	case common.MigrateCSVCompressGzip:
+		return gzip.NewWriter(w), nil
+	case common.MigrateCSVCompressZstd:
+		return zstd.NewWriter(w)
+	case common.MigrateCSVCompressSnappy:
+		// snappy framing 格式，与 Lightning 读取方式一致
+		return snappy.NewBufferedWriter(w), nil
+	default:
+		return nil, fmt.Errorf("csv compress [%s] isn't support, only support gzip/zstd/snappy", compress)
+	}
+}
+
+// NewDecompressReader 按压缩格式解压读取
+func NewDecompressReader(compress string, r io.Reader) (io.Reader, error) {
+	switch common.StringUPPER(compress) {
+	case "":
+		return r, nil
+	case common.MigrateCSVCompressGzip:
+		return gzip.NewReader(r)
+	case common.MigrateCSVCompressZstd:
+		return zstd.NewReader(r)
+	case common.MigrateCSVCompressSnappy:
+		return snappy.NewReader(r), nil
+	default:
+		return nil, fmt.Errorf("csv compress [%s] isn't support, only support gzip/zstd/snappy", compress)
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
@@ -0,0 +1,157 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package csv
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Manifest csv 导出表清单，记录目标表、字段顺序、csv 格式以及数据文件，用于 load 模式异地导入
+type Manifest struct {
+	SchemaNameS     string         `json:"schema_name_s"`
+	TableNameS      string         `json:"table_name_s"`
+	SchemaNameT     string         `json:"schema_name_t"`
+	TableNameT      string         `json:"table_name_t"`
+	Columns         []string       `json:"columns"`
+	Header          bool           `json:"header"`
+	Separator       string         `json:"separator"`
+	Terminator      string         `json:"terminator"`
+	Delimiter       string         `json:"delimiter"`
+	EscapeBackslash bool           `json:"escape_backslash"`
+	Charset         string         `json:"charset"`
+	Compress        string         `json:"compress"`
+	Dialect         string         `json:"dialect"`
+	QuoteStyle      string         `json:"quote_style"`
+	EscapeChar      string         `json:"escape_char"`
+	NullValue       string         `json:"null_value"`
+	BOM             bool           `json:"bom"`
+	Files           []ManifestFile `json:"files"`
+}
+
+type ManifestFile struct {
+	FileName string `json:"file_name"`
+	FileSize int64  `json:"file_size"`
+}
+
+// WriteManifest 扫描表导出目录 csv 文件生成清单，文件名仅记录相对目录名，便于拷贝至异地
+func (m *Manifest) WriteManifest(tableDir string) error {
+	csvFiles, err := filepath.Glob(filepath.Join(tableDir, common.StringsBuilder("*", CompressFileExt(m.Compress))))
+	if err != nil {
+		return fmt.Errorf("glob csv dir [%s] files failed: %v", tableDir, err)
+	}
+	// 按 chunk 序号排序，schema.table.N.csv[.gz|.zst|.snappy]
+	sort.Slice(csvFiles, func(i, j int) bool {
+		return manifestFileSeq(csvFiles[i]) < manifestFileSeq(csvFiles[j])
+	})
+
+	m.Files = nil
+	for _, f := range csvFiles {
+		fi, err := os.Stat(f)
+		if err != nil {
+			return fmt.Errorf("stat csv file [%s] failed: %v", f, err)
+		}
+		m.Files = append(m.Files, ManifestFile{
+			FileName: filepath.Base(f),
+			FileSize: fi.Size(),
+		})
+	}
+
+	jsonBytes, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json marshal table [%s.%s] manifest failed: %v", m.SchemaNameS, m.TableNameS, err)
+	}
+	if err = os.WriteFile(filepath.Join(tableDir, common.MigrateCSVManifestFile), jsonBytes, 0666); err != nil {
+		return fmt.Errorf("write table [%s.%s] manifest failed: %v", m.SchemaNameS, m.TableNameS, err)
+	}
+	return nil
+}
+
+// WriteObjectManifest 对象存储表导出目录生成清单并上传
+func (m *Manifest) WriteObjectManifest(storage *ObjectStorage, tableDir string) error {
+	objects, err := storage.ListObjects(common.StringsBuilder(tableDir, "/"))
+	if err != nil {
+		return err
+	}
+	ext := CompressFileExt(m.Compress)
+	var csvFiles []ObjectInfo
+	for _, o := range objects {
+		if strings.HasSuffix(o.Key, ext) {
+			csvFiles = append(csvFiles, o)
+		}
+	}
+	sort.Slice(csvFiles, func(i, j int) bool {
+		return manifestFileSeq(csvFiles[i].Key) < manifestFileSeq(csvFiles[j].Key)
+	})
+
+	m.Files = nil
+	for _, f := range csvFiles {
+		m.Files = append(m.Files, ManifestFile{
+			FileName: path.Base(f.Key),
+			FileSize: f.Size,
+		})
+	}
+
+	jsonBytes, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json marshal table [%s.%s] manifest failed: %v", m.SchemaNameS, m.TableNameS, err)
+	}
+	if err = storage.PutObject(JoinPath(tableDir, common.MigrateCSVManifestFile), jsonBytes); err != nil {
+		return fmt.Errorf("write table [%s.%s] manifest failed: %v", m.SchemaNameS, m.TableNameS, err)
+	}
+	return nil
+}
+
+// NullLiteral csv NULL 字面量，未记录方言的历史清单固定为 NULL
+func (m *Manifest) NullLiteral() string {
+	if m.Dialect == "" {
+		return "NULL"
+	}
+	return m.NullValue
+}
+
+// ReadManifest 读取表导出目录清单
+func ReadManifest(tableDir string) (*Manifest, error) {
+	jsonBytes, err := os.ReadFile(filepath.Join(tableDir, common.MigrateCSVManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("read dir [%s] manifest failed: %v", tableDir, err)
+	}
+	m := &Manifest{}
+	if err = json.Unmarshal(jsonBytes, m); err != nil {
+		return nil, fmt.Errorf("json unmarshal dir [%s] manifest failed: %v", tableDir, err)
+	}
+	return m, nil
+}
+
+func manifestFileSeq(fileName string) int {
+	baseName := filepath.Base(fileName)
+	if idx := strings.LastIndex(baseName, ".csv"); idx >= 0 {
+		baseName = baseName[:idx]
+	}
+	items := strings.Split(baseName, ".")
+	seq, err := strconv.Atoi(items[len(items)-1])
+	if err != nil {
+		return -1
+	}
+	return seq
+}
@@ -17,15 +17,17 @@ package o2m
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"github.com/wentaojin/transferdb/common"
 	"github.com/wentaojin/transferdb/config"
 	"github.com/wentaojin/transferdb/database/meta"
 	"github.com/wentaojin/transferdb/database/mysql"
 	"github.com/wentaojin/transferdb/database/oracle"
+	"github.com/wentaojin/transferdb/module/csv"
+	"github.com/wentaojin/transferdb/module/migrate"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
+	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -38,6 +40,8 @@ type O2M struct {
 	oracle *oracle.Oracle
 	mysql  *mysql.MySQL
 	metaDB *meta.Meta
+	// output-dir 为对象存储时 chunk 文件直接上传
+	storage *csv.ObjectStorage
 }
 
 func NewCSVer(ctx context.Context, cfg *config.Config) (*O2M, error) {
@@ -53,12 +57,20 @@ func NewCSVer(ctx context.Context, cfg *config.Config) (*O2M, error) {
 	if err != nil {
 		return nil, err
 	}
+	var storage *csv.ObjectStorage
+	if csv.IsObjectStorage(cfg.CSVConfig.OutputDir) {
+		storage, err = csv.NewObjectStorage(ctx, cfg.CSVConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return &O2M{
-		ctx:    ctx,
-		cfg:    cfg,
-		oracle: oracleDB,
-		mysql:  mysqlDB,
-		metaDB: metaDB,
+		ctx:     ctx,
+		cfg:     cfg,
+		oracle:  oracleDB,
+		mysql:   mysqlDB,
+		metaDB:  metaDB,
+		storage: storage,
 	}, nil
 }
 
@@ -82,7 +94,7 @@ func (r *O2M) CSV() error {
 	}
 
 	// 获取配置文件待同步表列表
-	exporters, err := filterCFGTable(r.cfg, r.oracle)
+	exporters, err := filterCFGTable(r.ctx, r.cfg, r.oracle, r.metaDB)
 	if err != nil {
 		return err
 	}
@@ -129,7 +141,7 @@ func (r *O2M) CSV() error {
 		TaskStatus:  common.TaskStatusFailed,
 	})
 	if errTotals > 0 || err != nil {
-		return fmt.Errorf(`csv schema [%s] mode [%s] table task failed: %v, meta table [wait_sync_meta] exist failed error, please firstly check log and deal, secondly clear or update meta table [wait_sync_meta] column [task_status] table status WAITING (Need UPPER), thirdly clear meta table [full_sync_meta] error table record, finally rerunning`, strings.ToUpper(r.cfg.OracleConfig.SchemaName), r.cfg.TaskMode, err)
+		return fmt.Errorf(`csv schema [%s] mode [%s] table task failed: %v, meta table [wait_sync_meta] exist failed error, please firstly check log and deal, secondly clear or update meta table [wait_sync_meta] column [task_status] table status WAITING (Need UPPER), thirdly clear meta table [full_sync_meta] error table record, finally rerunning, or directly rerunning [--mode resume --resume-mode %s] requeue failed chunks`, strings.ToUpper(r.cfg.OracleConfig.SchemaName), r.cfg.TaskMode, err, strings.ToLower(r.cfg.TaskMode))
 	}
 
 	// 判断并记录待同步表列表
@@ -314,22 +326,23 @@ func (r *O2M) CSV() error {
 	}
 	if len(waitSyncTables) > 0 {
 		// 获取表名自定义规则
-		tableNameRules, err := meta.NewTableNameRuleModel(r.metaDB).DetailTableNameRule(r.ctx, &meta.TableNameRule{
+		tableRewriter, err := r.cfg.RewriteConfig.TableRewriter()
+		if err != nil {
+			return err
+		}
+		sourceTables, err := r.oracle.GetOracleSchemaTable(common.StringUPPER(r.cfg.OracleConfig.SchemaName))
+		if err != nil {
+			return err
+		}
+		tableNameRuleMap, err := meta.NewTableNameRuleModel(r.metaDB).DetailTableNameRuleMap(r.ctx, &meta.TableNameRule{
 			DBTypeS:     r.cfg.DBTypeS,
 			DBTypeT:     r.cfg.DBTypeT,
 			SchemaNameS: r.cfg.OracleConfig.SchemaName,
 			SchemaNameT: r.cfg.MySQLConfig.SchemaName,
-		})
+		}, sourceTables, tableRewriter)
 		if err != nil {
 			return err
 		}
-		tableNameRuleMap := make(map[string]string)
-
-		if len(tableNameRules) > 0 {
-			for _, tr := range tableNameRules {
-				tableNameRuleMap[common.StringUPPER(tr.TableNameS)] = common.StringUPPER(tr.TableNameT)
-			}
-		}
 		err = r.csvWaitSyncTable(waitSyncTables, tableNameRuleMap, oracleCollation)
 		if err != nil {
 			return err
@@ -400,6 +413,11 @@ func (r *O2M) csvPartSyncTable(csvPartTables []string) error {
 				return err
 			}
 
+			// 断点恢复，校验已完成 chunk 文件，不完整 chunk 重置为待写入
+			if err = r.recoverTableChunkFiles(t); err != nil {
+				return err
+			}
+
 			fullMetas, err := meta.NewFullSyncMetaModel(r.metaDB).DetailFullSyncMeta(r.ctx, &meta.FullSyncMeta{
 				DBTypeS:     r.cfg.DBTypeS,
 				DBTypeT:     r.cfg.DBTypeT,
@@ -417,71 +435,39 @@ func (r *O2M) csvPartSyncTable(csvPartTables []string) error {
 
 			for _, fullSyncMeta := range fullMetas {
 				m := fullSyncMeta
+				// 运行窗口外暂停派发 chunk
+				if err := common.WaitRunWindow(r.ctx); err != nil {
+					return err
+				}
 				g1.Go(func() error {
 					querySQL := common.StringsBuilder(
-						`SELECT `, m.ColumnDetailS, ` FROM `, m.SchemaNameS, `.`, m.TableNameS, ` WHERE `, m.ChunkDetailS)
-
-					// 抽取 Oracle 数据
-					var (
-						columnFields []string
-						rowsResult   *sql.Rows
-						err          error
-					)
-					rowsResult, err = r.oracle.OracleDB.QueryContext(r.ctx, querySQL)
-					if err != nil {
-						// record error, skip error
-						if errf := meta.NewFullSyncMetaModel(r.metaDB).UpdateFullSyncMeta(r.ctx, &meta.FullSyncMeta{
-							DBTypeS:      m.DBTypeS,
-							DBTypeT:      m.DBTypeT,
-							SchemaNameS:  m.SchemaNameS,
-							TableNameS:   m.TableNameS,
-							TaskMode:     m.TaskMode,
-							ChunkDetailS: m.ChunkDetailS,
-						}, map[string]interface{}{
-							"TaskStatus":  common.TaskStatusFailed,
-							"InfoDetail":  m.String(),
-							"ErrorDetail": err.Error(),
-						}); errf != nil {
-							return fmt.Errorf("get oracle schema table [%v] record by rowid sql falied: %v", m.String(), errf)
-						}
+						`SELECT `, m.ColumnDetailS, ` FROM `, migrate.GenSnapshotTableFrom(m.SchemaNameS, m.TableNameS, m.ChunkPartitionS, m.SnapshotGroup, m.GlobalScnS), ` WHERE `, m.ChunkDetailS)
 
-						return nil
-					}
-
-					columnFields, err = rowsResult.Columns()
-					if err != nil {
-						// record error, skip error
-						if errf := meta.NewFullSyncMetaModel(r.metaDB).UpdateFullSyncMeta(r.ctx, &meta.FullSyncMeta{
-							DBTypeS:      m.DBTypeS,
-							DBTypeT:      m.DBTypeT,
-							SchemaNameS:  m.SchemaNameS,
-							TableNameS:   m.TableNameS,
-							TaskMode:     m.TaskMode,
-							ChunkDetailS: m.ChunkDetailS,
-						}, map[string]interface{}{
-							"TaskStatus":  common.TaskStatusFailed,
-							"InfoDetail":  m.String(),
-							"ErrorDetail": err.Error(),
-						}); errf != nil {
-							return fmt.Errorf("get oracle schema table [%v] rows.Columns failed: %v", m.String(), errf)
+					// 抽取 Oracle 数据输出文件，会话被 kill 时重建会话重试 chunk，文件截断重写
+					errW := r.oracle.RetryOnSessionKilled(func(attempt int) error {
+						rowsResult, err := r.oracle.ExtractDB().QueryContext(r.ctx, querySQL)
+						if err != nil {
+							return fmt.Errorf("get oracle schema table [%v] record by rowid sql falied: %v", m.String(), err)
 						}
-
-						return nil
-					}
-
-					// 数据输出
-					errW := NewWriter(m.SchemaNameS,
-						m.TableNameS,
-						oracleDBCharacterSet, querySQL, m.CSVFile, columnFields,
-						r.cfg.CSVConfig, rowsResult).WriteFile()
+						columnFields, err := rowsResult.Columns()
+						if err != nil {
+							rowsResult.Close()
+							return fmt.Errorf("get oracle schema table [%v] rows.Columns failed: %v", m.String(), err)
+						}
+						return NewWriter(m.SchemaNameS,
+							m.TableNameS,
+							oracleDBCharacterSet, querySQL, m.CSVFile, columnFields,
+							r.cfg.CSVConfig, rowsResult, r.oracle.CharsetConverter(), r.storage).WriteFile()
+					})
 					if errW != nil {
 						if errf := meta.NewFullSyncMetaModel(r.metaDB).UpdateFullSyncMeta(r.ctx, &meta.FullSyncMeta{
-							DBTypeS:      m.DBTypeS,
-							DBTypeT:      m.DBTypeT,
-							SchemaNameS:  m.SchemaNameS,
-							TableNameS:   m.TableNameS,
-							TaskMode:     m.TaskMode,
-							ChunkDetailS: m.ChunkDetailS,
+							DBTypeS:         m.DBTypeS,
+							DBTypeT:         m.DBTypeT,
+							SchemaNameS:     m.SchemaNameS,
+							TableNameS:      m.TableNameS,
+							TaskMode:        m.TaskMode,
+							ChunkDetailS:    m.ChunkDetailS,
+							ChunkPartitionS: m.ChunkPartitionS,
 						}, map[string]interface{}{
 							"TaskStatus":  common.TaskStatusFailed,
 							"InfoDetail":  m.String(),
@@ -491,12 +477,13 @@ func (r *O2M) csvPartSyncTable(csvPartTables []string) error {
 						}
 					} else {
 						if errf := meta.NewFullSyncMetaModel(r.metaDB).UpdateFullSyncMeta(r.ctx, &meta.FullSyncMeta{
-							DBTypeS:      m.DBTypeS,
-							DBTypeT:      m.DBTypeT,
-							SchemaNameS:  m.SchemaNameS,
-							TableNameS:   m.TableNameS,
-							TaskMode:     m.TaskMode,
-							ChunkDetailS: m.ChunkDetailS,
+							DBTypeS:         m.DBTypeS,
+							DBTypeT:         m.DBTypeT,
+							SchemaNameS:     m.SchemaNameS,
+							TableNameS:      m.TableNameS,
+							TaskMode:        m.TaskMode,
+							ChunkDetailS:    m.ChunkDetailS,
+							ChunkPartitionS: m.ChunkPartitionS,
 						}, map[string]interface{}{
 							"TaskStatus": common.TaskStatusSuccess,
 						}); errf != nil {
@@ -547,6 +534,11 @@ func (r *O2M) csvPartSyncTable(csvPartTables []string) error {
 				if err != nil {
 					return err
 				}
+				if len(fullMetas) > 0 {
+					if err = r.writeTableManifest(fullMetas[0], oracleDBCharacterSet); err != nil {
+						return err
+					}
+				}
 				zap.L().Info("csv single table oracle to mysql finished",
 					zap.String("schema", r.cfg.OracleConfig.SchemaName),
 					zap.String("table", common.StringUPPER(t)),
@@ -608,11 +600,31 @@ func (r *O2M) initWaitSyncTableRowID(csvWaitTables []string, tableNameRule map[s
 	if err != nil {
 		return err
 	}
+	if err = r.oracle.WaitStandbyApplySCN(globalSCN); err != nil {
+		return err
+	}
+	// 一致性快照组，同组表统一 SCN
+	snapshotGroups, err := migrate.GenSnapshotGroupSCN(r.cfg, r.oracle, csvWaitTables)
+	if err != nil {
+		return err
+	}
 	partitionTables, err := r.oracle.GetOracleSchemaPartitionTable(r.cfg.OracleConfig.SchemaName)
 	if err != nil {
 		return err
 	}
 
+	sampler := migrate.NewSampler(r.cfg.SampleConfig, r.oracle, r.cfg.OracleConfig.SchemaName)
+
+	parallelExecute, err := r.oracle.IsOracleParallelExecuteAvailable()
+	if err != nil {
+		return err
+	}
+	if !parallelExecute {
+		zap.L().Warn("oracle dbms_parallel_execute isn't available, table export single chunk",
+			zap.String("schema", r.cfg.OracleConfig.SchemaName),
+			zap.String("cloud service", r.oracle.GetOracleCloudService()))
+	}
+
 	g := &errgroup.Group{}
 	g.SetLimit(r.cfg.CSVConfig.TaskThreads)
 
@@ -621,6 +633,11 @@ func (r *O2M) initWaitSyncTableRowID(csvWaitTables []string, tableNameRule map[s
 		workerID := idx
 		g.Go(func() error {
 			startTime := time.Now()
+			tableSCN := globalSCN
+			snapshotGroup, isSnapshot := snapshotGroups[common.StringUPPER(t)]
+			if isSnapshot {
+				tableSCN = snapshotGroup.SCN
+			}
 
 			// 库名、表名规则
 			var targetTableName string
@@ -630,6 +647,12 @@ func (r *O2M) initWaitSyncTableRowID(csvWaitTables []string, tableNameRule map[s
 				targetTableName = common.StringUPPER(t)
 			}
 
+			// 开发环境抽样导出
+			sampleWhere, err := sampler.SampleWhere(t)
+			if err != nil {
+				return err
+			}
+
 			if r.cfg.CSVConfig.OutputDir == "" {
 				return fmt.Errorf("csv config paramter output-dir can't be null, please configure")
 			}
@@ -668,23 +691,24 @@ func (r *O2M) initWaitSyncTableRowID(csvWaitTables []string, tableNameRule map[s
 					TableNameS:    common.StringUPPER(t),
 					SchemaNameT:   common.StringUPPER(r.cfg.MySQLConfig.SchemaName),
 					TableNameT:    common.StringUPPER(targetTableName),
-					GlobalScnS:    globalSCN,
+					GlobalScnS:    tableSCN,
+					SnapshotGroup: snapshotGroup.Group,
 					ColumnDetailS: sourceColumnInfo,
-					ChunkDetailS:  "1 = 1",
+					ChunkDetailS:  migrate.GenSampleChunkWhere("1 = 1", sampleWhere),
 					TaskMode:      r.cfg.TaskMode,
 					TaskStatus:    common.TaskStatusWaiting,
 					IsPartition:   isPartition,
-					CSVFile: filepath.Join(r.cfg.CSVConfig.OutputDir,
+					CSVFile: csv.JoinPath(r.cfg.CSVConfig.OutputDir,
 						common.StringUPPER(r.cfg.OracleConfig.SchemaName), common.StringUPPER(t),
 						common.StringsBuilder(common.StringUPPER(r.cfg.MySQLConfig.SchemaName),
-							`.`, common.StringUPPER(targetTableName), `.0.csv`)),
+							`.`, common.StringUPPER(targetTableName), `.0`, csv.CompressFileExt(r.cfg.CSVConfig.Compress))),
 				}, &meta.WaitSyncMeta{
 					DBTypeS:          r.cfg.DBTypeS,
 					DBTypeT:          r.cfg.DBTypeT,
 					SchemaNameS:      common.StringUPPER(r.cfg.OracleConfig.SchemaName),
 					TableNameS:       common.StringUPPER(t),
 					TaskMode:         r.cfg.TaskMode,
-					GlobalScnS:       globalSCN,
+					GlobalScnS:       tableSCN,
 					ChunkTotalNums:   1,
 					ChunkSuccessNums: 0,
 					ChunkFailedNums:  0,
@@ -701,19 +725,28 @@ func (r *O2M) initWaitSyncTableRowID(csvWaitTables []string, tableNameRule map[s
 				zap.String("table", common.StringUPPER(t)),
 				zap.Int("rows", tableRowsByStatistics))
 
-			taskName := common.StringsBuilder(common.StringUPPER(r.cfg.OracleConfig.SchemaName), `_`, common.StringUPPER(t), `_`, `TASK`, strconv.Itoa(workerID))
-
-			if err = r.oracle.StartOracleChunkCreateTask(taskName); err != nil {
-				return err
-			}
-
-			if err = r.oracle.StartOracleCreateChunkByRowID(taskName, common.StringUPPER(r.cfg.OracleConfig.SchemaName), common.StringUPPER(t), strconv.Itoa(r.cfg.CSVConfig.Rows)); err != nil {
+			avgRowBytes, chunkRows, err := r.calibrateTableChunkRows(t)
+			if err != nil {
 				return err
 			}
 
-			chunkRes, err := r.oracle.GetOracleTableChunksByRowID(taskName)
-			if err != nil {
-				return err
+			// DBMS_PARALLEL_EXECUTE 不可用（部分云数据库服务等级受限）整表单 chunk 导出
+			var chunkRes []map[string]string
+			if parallelExecute {
+				taskName := common.StringsBuilder(common.StringUPPER(r.cfg.OracleConfig.SchemaName), `_`, common.StringUPPER(t), `_`, `TASK`, strconv.Itoa(workerID))
+				if err = r.oracle.StartOracleChunkCreateTask(taskName); err != nil {
+					return err
+				}
+				if err = r.oracle.StartOracleCreateChunkByRowID(taskName, common.StringUPPER(r.cfg.OracleConfig.SchemaName), common.StringUPPER(t), strconv.Itoa(chunkRows)); err != nil {
+					return err
+				}
+				chunkRes, err = r.oracle.GetOracleTableChunksByRowID(taskName)
+				if err != nil {
+					return err
+				}
+				if err = r.oracle.CloseOracleChunkTask(taskName); err != nil {
+					return err
+				}
 			}
 
 			// 判断数据是否存在
@@ -732,23 +765,24 @@ func (r *O2M) initWaitSyncTableRowID(csvWaitTables []string, tableNameRule map[s
 					TableNameS:    common.StringUPPER(t),
 					SchemaNameT:   common.StringUPPER(r.cfg.MySQLConfig.SchemaName),
 					TableNameT:    common.StringUPPER(targetTableName),
-					GlobalScnS:    globalSCN,
+					GlobalScnS:    tableSCN,
+					SnapshotGroup: snapshotGroup.Group,
 					ColumnDetailS: sourceColumnInfo,
-					ChunkDetailS:  "1 = 1",
+					ChunkDetailS:  migrate.GenSampleChunkWhere("1 = 1", sampleWhere),
 					TaskMode:      r.cfg.TaskMode,
 					TaskStatus:    common.TaskStatusWaiting,
 					IsPartition:   isPartition,
-					CSVFile: filepath.Join(r.cfg.CSVConfig.OutputDir,
+					CSVFile: csv.JoinPath(r.cfg.CSVConfig.OutputDir,
 						common.StringUPPER(r.cfg.OracleConfig.SchemaName), common.StringUPPER(t),
 						common.StringsBuilder(common.StringUPPER(r.cfg.MySQLConfig.SchemaName),
-							`.`, common.StringUPPER(targetTableName), `.0.csv`)),
+							`.`, common.StringUPPER(targetTableName), `.0`, csv.CompressFileExt(r.cfg.CSVConfig.Compress))),
 				}, &meta.WaitSyncMeta{
 					DBTypeS:          r.cfg.DBTypeS,
 					DBTypeT:          r.cfg.DBTypeT,
 					SchemaNameS:      common.StringUPPER(r.cfg.OracleConfig.SchemaName),
 					TableNameS:       common.StringUPPER(t),
 					TaskMode:         r.cfg.TaskMode,
-					GlobalScnS:       globalSCN,
+					GlobalScnS:       tableSCN,
 					ChunkTotalNums:   1,
 					ChunkSuccessNums: 0,
 					ChunkFailedNums:  0,
@@ -764,10 +798,10 @@ func (r *O2M) initWaitSyncTableRowID(csvWaitTables []string, tableNameRule map[s
 			var fullMetas []meta.FullSyncMeta
 			for i, res := range chunkRes {
 				var csvFile string
-				csvFile = filepath.Join(r.cfg.CSVConfig.OutputDir,
+				csvFile = csv.JoinPath(r.cfg.CSVConfig.OutputDir,
 					common.StringUPPER(r.cfg.OracleConfig.SchemaName), common.StringUPPER(t),
 					common.StringsBuilder(common.StringUPPER(r.cfg.MySQLConfig.SchemaName), `.`,
-						common.StringUPPER(targetTableName), `.`, strconv.Itoa(i), `.csv`))
+						common.StringUPPER(targetTableName), `.`, strconv.Itoa(i), csv.CompressFileExt(r.cfg.CSVConfig.Compress)))
 
 				fullMetas = append(fullMetas, meta.FullSyncMeta{
 					DBTypeS:       r.cfg.DBTypeS,
@@ -776,9 +810,10 @@ func (r *O2M) initWaitSyncTableRowID(csvWaitTables []string, tableNameRule map[s
 					TableNameS:    common.StringUPPER(t),
 					SchemaNameT:   common.StringUPPER(r.cfg.MySQLConfig.SchemaName),
 					TableNameT:    common.StringUPPER(targetTableName),
-					GlobalScnS:    globalSCN,
+					GlobalScnS:    tableSCN,
+					SnapshotGroup: snapshotGroup.Group,
 					ColumnDetailS: sourceColumnInfo,
-					ChunkDetailS:  res["CMD"],
+					ChunkDetailS:  migrate.GenSampleChunkWhere(res["CMD"], sampleWhere),
 					TaskMode:      r.cfg.TaskMode,
 					TaskStatus:    common.TaskStatusWaiting,
 					IsPartition:   isPartition,
@@ -794,20 +829,18 @@ func (r *O2M) initWaitSyncTableRowID(csvWaitTables []string, tableNameRule map[s
 					SchemaNameS:      common.StringUPPER(r.cfg.OracleConfig.SchemaName),
 					TableNameS:       common.StringUPPER(t),
 					TaskMode:         r.cfg.TaskMode,
-					GlobalScnS:       globalSCN,
+					GlobalScnS:       tableSCN,
 					ChunkTotalNums:   int64(len(chunkRes)),
 					ChunkSuccessNums: 0,
 					ChunkFailedNums:  0,
 					IsPartition:      isPartition,
+					AvgRowBytes:      int64(avgRowBytes),
+					ChunkRows:        int64(chunkRows),
 				})
 			if err != nil {
 				return err
 			}
 
-			if err = r.oracle.CloseOracleChunkTask(taskName); err != nil {
-				return err
-			}
-
 			endTime := time.Now()
 			zap.L().Info("source table init wait_sync_meta and full_sync_meta finished",
 				zap.String("schema", r.cfg.OracleConfig.SchemaName),
@@ -883,3 +916,140 @@ func (r *O2M) adjustTableSelectColumn(sourceTable string, oracleCollation bool)
 
 	return strings.Join(columnNames, ","), nil
 }
+
+// calibrateTableChunkRows 按 chunk-bytes 估算每个 csv 文件行数，未开启沿用 rows
+func (r *O2M) calibrateTableChunkRows(sourceTable string) (int, int, error) {
+	if r.cfg.CSVConfig.ChunkBytes <= 0 {
+		return 0, r.cfg.CSVConfig.Rows, nil
+	}
+	avgRowBytes, err := r.oracle.GetOracleTableAvgRowBytes(common.StringUPPER(r.cfg.OracleConfig.SchemaName), common.StringUPPER(sourceTable), common.MigrateChunkCalibrateSampleRows)
+	if err != nil {
+		return 0, 0, err
+	}
+	if avgRowBytes <= 0 {
+		return avgRowBytes, r.cfg.CSVConfig.Rows, nil
+	}
+	chunkRows := r.cfg.CSVConfig.ChunkBytes / avgRowBytes
+	if chunkRows <= 0 {
+		chunkRows = 1
+	}
+	zap.L().Info("calibrate oracle table csv rows",
+		zap.String("schema", common.StringUPPER(r.cfg.OracleConfig.SchemaName)),
+		zap.String("table", common.StringUPPER(sourceTable)),
+		zap.Int("avg row bytes", avgRowBytes),
+		zap.Int("chunk bytes", r.cfg.CSVConfig.ChunkBytes),
+		zap.Int("chunk rows", chunkRows))
+	return avgRowBytes, chunkRows, nil
+}
+
+// recoverTableChunkFiles 清理中断遗留的临时文件，已记录成功但正式文件缺失的 chunk 重置为 WAITING 重新写入
+func (r *O2M) recoverTableChunkFiles(sourceTable string) error {
+	// 对象存储未完成的分片上传对象不可见，无临时文件
+	var (
+		removed int
+		err     error
+	)
+	if r.storage == nil {
+		tableDir := filepath.Join(r.cfg.CSVConfig.OutputDir, common.StringUPPER(r.cfg.OracleConfig.SchemaName), common.StringUPPER(sourceTable))
+		removed, err = cleanChunkTempFiles(tableDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	successMetas, err := meta.NewFullSyncMetaModel(r.metaDB).DetailFullSyncMeta(r.ctx, &meta.FullSyncMeta{
+		DBTypeS:     r.cfg.DBTypeS,
+		DBTypeT:     r.cfg.DBTypeT,
+		SchemaNameS: common.StringUPPER(r.cfg.OracleConfig.SchemaName),
+		TableNameS:  common.StringUPPER(sourceTable),
+		TaskMode:    r.cfg.TaskMode,
+		TaskStatus:  common.TaskStatusSuccess,
+	})
+	if err != nil {
+		return err
+	}
+
+	var rewrites int
+	for _, m := range successMetas {
+		if r.storage != nil {
+			_, exist, err := r.storage.StatObject(m.CSVFile)
+			if err != nil {
+				return err
+			}
+			if exist {
+				continue
+			}
+		} else if _, err = os.Stat(m.CSVFile); err == nil {
+			continue
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("stat csv file [%s] failed: %v", m.CSVFile, err)
+		}
+		if err = meta.NewFullSyncMetaModel(r.metaDB).UpdateFullSyncMeta(r.ctx, &meta.FullSyncMeta{
+			DBTypeS:         m.DBTypeS,
+			DBTypeT:         m.DBTypeT,
+			SchemaNameS:     m.SchemaNameS,
+			TableNameS:      m.TableNameS,
+			TaskMode:        m.TaskMode,
+			ChunkDetailS:    m.ChunkDetailS,
+			ChunkPartitionS: m.ChunkPartitionS,
+		}, map[string]interface{}{
+			"TaskStatus": common.TaskStatusWaiting,
+		}); err != nil {
+			return err
+		}
+		rewrites++
+	}
+
+	if removed > 0 || rewrites > 0 {
+		zap.L().Warn("source schema table csv chunk files recover",
+			zap.String("schema", r.cfg.OracleConfig.SchemaName),
+			zap.String("table", sourceTable),
+			zap.Int("temp files removed", removed),
+			zap.Int("chunks rewrite", rewrites))
+	}
+	return nil
+}
+
+// writeTableManifest 表导出完成生成 manifest.json，记录 load 模式导入所需目标表、字段以及 csv 格式
+func (r *O2M) writeTableManifest(m meta.FullSyncMeta, sourceCharset string) error {
+	columnsINFO, err := r.oracle.GetOracleSchemaTableColumn(m.SchemaNameS, m.TableNameS, false)
+	if err != nil {
+		return err
+	}
+	var columns []string
+	for _, rowCol := range columnsINFO {
+		columns = append(columns, rowCol["COLUMN_NAME"])
+	}
+
+	// 与 csv 文件写入默认值保持一致
+	f := NewWriter(m.SchemaNameS, m.TableNameS, sourceCharset, "", m.CSVFile, columns, r.cfg.CSVConfig, nil, r.oracle.CharsetConverter(), r.storage)
+	if err = f.adjustCSVConfig(); err != nil {
+		return err
+	}
+
+	manifest := &csv.Manifest{
+		SchemaNameS:     m.SchemaNameS,
+		TableNameS:      m.TableNameS,
+		SchemaNameT:     m.SchemaNameT,
+		TableNameT:      m.TableNameT,
+		Columns:         columns,
+		Header:          f.Header,
+		Separator:       f.Separator,
+		Terminator:      f.Terminator,
+		Delimiter:       f.Delimiter,
+		EscapeBackslash: f.EscapeBackslash,
+		Charset:         f.Charset,
+		Compress:        common.StringUPPER(f.Compress),
+		Dialect:         f.Dialect,
+		QuoteStyle:      f.QuoteStyle,
+		EscapeChar:      f.EscapeChar,
+		NullValue:       f.NullValue,
+		BOM:             f.BOM,
+	}
+	tableDir := csv.JoinPath(r.cfg.CSVConfig.OutputDir,
+		common.StringUPPER(r.cfg.OracleConfig.SchemaName), common.StringUPPER(m.TableNameS))
+	if r.storage != nil {
+		return manifest.WriteObjectManifest(r.storage, tableDir)
+	}
+	return manifest.WriteManifest(tableDir)
+}
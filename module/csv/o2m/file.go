@@ -0,0 +1,191 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unsafe"
+)
+
+// chunkWriter chunk 文件写入器，本地文件或对象存储分片上传
+type chunkWriter interface {
+	io.WriteCloser
+	Abort()
+}
+
+// O_DIRECT 写入内存地址以及长度对齐大小
+const directIOAlignSize = 4096
+
+// chunkFileWriter 单 chunk csv 文件写入器
+// 1、数据先写入 ${file}.tmp，Close 时按 fsync 策略落盘后原子 rename，避免半成品文件被识别为完成文件
+// 2、direct-io 开启时缓冲按 directIOAlignSize 对齐，仅整块写出，尾部不足一块数据关闭 O_DIRECT 后追加
+type chunkFileWriter struct {
+	fileName    string
+	tmpName     string
+	file        *os.File
+	buf         []byte
+	n           int
+	directIO    bool
+	fsyncPolicy string
+}
+
+func newChunkFileWriter(fileName string, bufferSize int, directIO bool, fsyncPolicy string) (*chunkFileWriter, error) {
+	if bufferSize <= 0 {
+		bufferSize = common.MigrateCSVBufferSize
+	}
+	w := &chunkFileWriter{
+		fileName:    fileName,
+		tmpName:     common.StringsBuilder(fileName, common.MigrateCSVTempFileSuffix),
+		directIO:    directIO,
+		fsyncPolicy: fsyncPolicy,
+	}
+
+	var err error
+	if directIO {
+		bufferSize = (bufferSize + directIOAlignSize - 1) / directIOAlignSize * directIOAlignSize
+		w.buf = alignedBlock(bufferSize)
+		w.file, err = openDirectFile(w.tmpName)
+	} else {
+		w.buf = make([]byte, bufferSize)
+		w.file, err = os.OpenFile(w.tmpName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open csv file [%s] failed: %v", w.tmpName, err)
+	}
+	return w, nil
+}
+
+func (w *chunkFileWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		c := copy(w.buf[w.n:], p)
+		w.n += c
+		written += c
+		p = p[c:]
+		if w.n == len(w.buf) {
+			if err := w.flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+func (w *chunkFileWriter) flush() error {
+	if w.n == 0 {
+		return nil
+	}
+	if _, err := w.file.Write(w.buf[:w.n]); err != nil {
+		return fmt.Errorf("write csv file [%s] failed: %v", w.tmpName, err)
+	}
+	w.n = 0
+	if w.fsyncPolicy == common.MigrateCSVFsyncPolicyFlush {
+		if err := w.file.Sync(); err != nil {
+			return fmt.Errorf("fsync csv file [%s] failed: %v", w.tmpName, err)
+		}
+	}
+	return nil
+}
+
+// Close 写出剩余缓冲、按策略 fsync 并 rename 为正式文件
+func (w *chunkFileWriter) Close() error {
+	if w.directIO && w.n > 0 {
+		// 尾部数据不满足对齐要求，重新以普通方式打开追加
+		if err := w.file.Close(); err != nil {
+			return fmt.Errorf("close csv file [%s] failed: %v", w.tmpName, err)
+		}
+		f, err := os.OpenFile(w.tmpName, os.O_WRONLY|os.O_APPEND, 0666)
+		if err != nil {
+			return fmt.Errorf("reopen csv file [%s] failed: %v", w.tmpName, err)
+		}
+		w.file = f
+	}
+	if err := w.flush(); err != nil {
+		w.file.Close()
+		return err
+	}
+	if w.fsyncPolicy != common.MigrateCSVFsyncPolicyNone {
+		if err := w.file.Sync(); err != nil {
+			w.file.Close()
+			return fmt.Errorf("fsync csv file [%s] failed: %v", w.tmpName, err)
+		}
+	}
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("close csv file [%s] failed: %v", w.tmpName, err)
+	}
+	if err := os.Rename(w.tmpName, w.fileName); err != nil {
+		return fmt.Errorf("rename csv file [%s] to [%s] failed: %v", w.tmpName, w.fileName, err)
+	}
+	if w.fsyncPolicy != common.MigrateCSVFsyncPolicyNone {
+		return syncDir(filepath.Dir(w.fileName))
+	}
+	return nil
+}
+
+// Abort 写入失败关闭并清理临时文件
+func (w *chunkFileWriter) Abort() {
+	w.file.Close()
+	os.Remove(w.tmpName)
+}
+
+// cleanChunkTempFiles 删除目录下中断遗留的 chunk 临时文件，目录不存在视为无遗留
+func cleanChunkTempFiles(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("read csv dir [%s] failed: %v", dir, err)
+	}
+	removed := 0
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), common.MigrateCSVTempFileSuffix) {
+			continue
+		}
+		if err = os.Remove(filepath.Join(dir, e.Name())); err != nil {
+			return removed, fmt.Errorf("remove csv temp file [%s] failed: %v", e.Name(), err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("open csv dir [%s] failed: %v", dir, err)
+	}
+	defer d.Close()
+	if err = d.Sync(); err != nil {
+		return fmt.Errorf("fsync csv dir [%s] failed: %v", dir, err)
+	}
+	return nil
+}
+
+// alignedBlock 分配按 directIOAlignSize 对齐的内存块
+func alignedBlock(size int) []byte {
+	block := make([]byte, size+directIOAlignSize)
+	offset := 0
+	if rem := int(uintptr(unsafe.Pointer(&block[0])) & (directIOAlignSize - 1)); rem != 0 {
+		offset = directIOAlignSize - rem
+	}
+	return block[offset : offset+size : offset+size]
+}
@@ -16,17 +16,16 @@ limitations under the License.
 package o2m
 
 import (
-	"bufio"
+	"bytes"
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"github.com/shopspring/decimal"
 	"github.com/thinkeridea/go-extend/exstrings"
 	"github.com/wentaojin/transferdb/common"
 	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/module/csv"
 	"go.uber.org/zap"
 	"io"
-	"os"
 	"path/filepath"
 	"strings"
 )
@@ -40,9 +39,13 @@ type File struct {
 	FileName         string   `json:"file_name"`
 	config.CSVConfig `json:"-"`
 	Rows             *sql.Rows `json:"-"`
+	// 字符字段字符集转换，配置后以转换目标端字符集输出
+	Converter *common.CharsetConverter `json:"-"`
+	// 对象存储输出，非空时 FileName 为 s3:// 或 oss:// 路径
+	Storage *csv.ObjectStorage `json:"-"`
 }
 
-func NewWriter(sourceSchema, sourceTable, sourceCharSet, querySQL, fileName string, sourceColumns []string, csvConfig config.CSVConfig, rows *sql.Rows) *File {
+func NewWriter(sourceSchema, sourceTable, sourceCharSet, querySQL, fileName string, sourceColumns []string, csvConfig config.CSVConfig, rows *sql.Rows, converter *common.CharsetConverter, storage *csv.ObjectStorage) *File {
 	return &File{
 		SourceSchema:  sourceSchema,
 		SourceTable:   sourceTable,
@@ -52,6 +55,8 @@ func NewWriter(sourceSchema, sourceTable, sourceCharSet, querySQL, fileName stri
 		FileName:      fileName,
 		CSVConfig:     csvConfig,
 		Rows:          rows,
+		Converter:     converter,
+		Storage:       storage,
 	}
 }
 func (f *File) WriteFile() error {
@@ -59,34 +64,67 @@ func (f *File) WriteFile() error {
 		return err
 	}
 
-	// 文件目录判断
-	if err := common.PathExist(
-		filepath.Join(
-			f.CSVConfig.OutputDir,
-			strings.ToUpper(f.SourceSchema),
-			strings.ToUpper(f.SourceTable))); err != nil {
-		return err
+	var fileW chunkWriter
+	if f.Storage != nil {
+		// 对象存储分片上传，完成上传前对象不可见
+		uploader, err := f.Storage.NewUploader(f.FileName)
+		if err != nil {
+			return err
+		}
+		fileW = uploader
+	} else {
+		// 文件目录判断
+		if err := common.PathExist(
+			filepath.Join(
+				f.CSVConfig.OutputDir,
+				strings.ToUpper(f.SourceSchema),
+				strings.ToUpper(f.SourceTable))); err != nil {
+			return err
+		}
+
+		// 每 chunk 独立文件写入器，写入完成后原子 rename
+		w, err := newChunkFileWriter(f.FileName, f.BufferSize, f.DirectIO, f.FsyncPolicy)
+		if err != nil {
+			return err
+		}
+		fileW = w
 	}
 
-	fileW, err := os.OpenFile(f.FileName, os.O_WRONLY|os.O_CREATE|os.O_APPEND|os.O_TRUNC, 0666)
+	// 边写边压缩，压缩流先于文件关闭以写出尾部数据
+	compressW, err := csv.NewCompressWriter(f.Compress, fileW)
 	if err != nil {
+		fileW.Abort()
 		return err
 	}
-	defer fileW.Close()
-
-	if err = f.write(fileW); err != nil {
+	if err = f.write(compressW); err != nil {
+		fileW.Abort()
+		return err
+	}
+	if err = compressW.Close(); err != nil {
+		fileW.Abort()
+		return fmt.Errorf("close csv file [%s] compress writer failed: %v", f.FileName, err)
+	}
+	if err = fileW.Close(); err != nil {
+		fileW.Abort()
 		return err
 	}
 	return nil
 }
 
 func (f *File) adjustCSVConfig() error {
+	if err := f.adjustCSVDialect(); err != nil {
+		return err
+	}
 	if f.Separator == "" {
 		f.Separator = ","
 	}
 	if f.Terminator == "" {
 		f.Terminator = "\r\n"
 	}
+	// 字符集转换以转换目标端字符集为准
+	if f.Converter != nil {
+		f.Charset = f.Converter.CSVCharset()
+	}
 	if f.Charset == "" {
 		if val, ok := common.OracleDBCSVCharacterSetMap[strings.ToUpper(f.SourceCharset)]; ok {
 			f.Charset = val
@@ -101,6 +139,9 @@ func (f *File) adjustCSVConfig() error {
 			isSupport = true
 		case common.GBKCharacterSetCSV:
 			isSupport = true
+		case common.GB18030CharacterSetCSV:
+			// 仅字符集转换支持 GB18030 输出
+			isSupport = f.Converter != nil
 		default:
 			isSupport = false
 		}
@@ -108,13 +149,99 @@ func (f *File) adjustCSVConfig() error {
 	if !isSupport {
 		return fmt.Errorf("target db character is not support: [%s]", f.Charset)
 	}
+	if f.BOM && !strings.EqualFold(f.Charset, common.UTF8CharacterSetCSV) {
+		return fmt.Errorf("csv config [bom] only support charset utf8, current charset [%s]", f.Charset)
+	}
+	return nil
+}
+
+// adjustCSVDialect 按方言补齐未配置项，escape-char 配置反斜杠等同 escape-backslash
+func (f *File) adjustCSVDialect() error {
+	f.Dialect = common.StringUPPER(f.Dialect)
+	switch f.Dialect {
+	case "", common.MigrateCSVDialectLightning:
+		f.Dialect = common.MigrateCSVDialectLightning
+		if f.QuoteStyle == "" {
+			f.QuoteStyle = common.MigrateCSVQuoteStyleAlways
+		}
+		if f.NullValue == "" {
+			f.NullValue = "NULL"
+		}
+	case common.MigrateCSVDialectMySQL:
+		if f.Delimiter == "" {
+			f.Delimiter = `"`
+		}
+		if f.Terminator == "" {
+			f.Terminator = "\n"
+		}
+		if f.QuoteStyle == "" {
+			f.QuoteStyle = common.MigrateCSVQuoteStyleAlways
+		}
+		if f.EscapeChar == "" {
+			f.EscapeChar = `\`
+		}
+		if f.NullValue == "" {
+			f.NullValue = `\N`
+		}
+	case common.MigrateCSVDialectSnowflake, common.MigrateCSVDialectDuckDB, common.MigrateCSVDialectGreenplum:
+		if f.Delimiter == "" {
+			f.Delimiter = `"`
+		}
+		if f.Terminator == "" {
+			f.Terminator = "\n"
+		}
+		if f.QuoteStyle == "" {
+			f.QuoteStyle = common.MigrateCSVQuoteStyleMinimal
+		}
+		if f.EscapeChar == "" {
+			f.EscapeChar = f.Delimiter
+		}
+	case common.MigrateCSVDialectClickHouse:
+		if f.Delimiter == "" {
+			f.Delimiter = `"`
+		}
+		if f.Terminator == "" {
+			f.Terminator = "\n"
+		}
+		if f.QuoteStyle == "" {
+			f.QuoteStyle = common.MigrateCSVQuoteStyleMinimal
+		}
+		if f.EscapeChar == "" {
+			f.EscapeChar = f.Delimiter
+		}
+		if f.NullValue == "" {
+			f.NullValue = `\N`
+		}
+	default:
+		return fmt.Errorf("csv config [dialect] value [%s] isn't support, only support lightning/mysql/snowflake/duckdb/clickhouse/greenplum", f.Dialect)
+	}
+
+	f.QuoteStyle = common.StringUPPER(f.QuoteStyle)
+	switch f.QuoteStyle {
+	case common.MigrateCSVQuoteStyleAlways, common.MigrateCSVQuoteStyleMinimal:
+	case common.MigrateCSVQuoteStyleNone:
+		f.Delimiter = ""
+	default:
+		return fmt.Errorf("csv config [quote-style] value [%s] isn't support, only support always/minimal/none", f.QuoteStyle)
+	}
+
+	if f.EscapeChar != "" {
+		if len([]rune(f.EscapeChar)) > 1 {
+			return fmt.Errorf("csv config [escape-char] value [%s] length over 1", f.EscapeChar)
+		}
+		f.EscapeBackslash = f.EscapeChar == `\`
+	}
 	return nil
 }
 
-func (f *File) write(w io.Writer) error {
-	writer := bufio.NewWriter(w)
+func (f *File) write(writer io.Writer) error {
+	if f.BOM {
+		if _, err := io.WriteString(writer, common.MigrateCSVUTF8BOM); err != nil {
+			return fmt.Errorf("failed to write bom: %v", err)
+		}
+	}
 	if f.Header {
-		if _, err := writer.WriteString(common.StringsBuilder(exstrings.Join(f.SourceColumns, f.Separator), f.Terminator)); err != nil {
+		if _, err := io.WriteString(writer, common.StringsBuilder(exstrings.Join(f.SourceColumns, f.Separator), f.Terminator)); err != nil {
 			return fmt.Errorf("failed to write headers: %v", err)
 		}
 	}
@@ -128,9 +255,12 @@ func (f *File) write(w io.Writer) error {
 		return fmt.Errorf("failed to csv get rows columnTypes: %v", err)
 	}
 
-	for _, ct := range colTypes {
+	// 字符字段字符集转换
+	convertColumns := make([]bool, len(colTypes))
+	for i, ct := range colTypes {
 		// 数据库字段类型 DatabaseTypeName() 映射 go 类型 ScanType()
 		columnTypes = append(columnTypes, ct.ScanType().String())
+		convertColumns[i] = f.Converter != nil && common.IsCharsetConvertColumn(ct.DatabaseTypeName())
 	}
 
 	// 数据 SCAN
@@ -141,11 +271,16 @@ func (f *File) write(w io.Writer) error {
 		dest[i] = &rawResult[i]
 	}
 
+	// 行缓冲、字节 arena 逐行复用，数据行写入后即可重置
+	lineBuf := common.GetBuffer()
+	defer common.PutBuffer(lineBuf)
+	arena := common.NewByteArena(0)
+
 	// 表行数读取
 	for f.Rows.Next() {
 		rowCount = rowCount + 1
-
-		var results []string
+		lineBuf.Reset()
+		arena.Reset()
 
 		err = f.Rows.Scan(dest...)
 		if err != nil {
@@ -158,93 +293,26 @@ func (f *File) write(w io.Writer) error {
 			// Mysql 空字符串与 NULL 非一类，NULL 是 NULL，空字符串是空字符串（is null 只查询 NULL 值，空字符串查询只查询到空字符串值）
 			// 按照 Oracle 特性来，转换同步统一转换成 NULL 即可，但需要注意业务逻辑中空字符串得写入，需要变更
 			// Oracle/Mysql 对于 'NULL' 统一字符 NULL 处理，查询出来转成 NULL,所以需要判断处理
-			if raw == nil {
-				results = append(results, "NULL")
-			} else if string(raw) == "" {
-				results = append(results, "NULL")
-			} else {
-				switch columnTypes[i] {
-				case "int64":
-					r, err := common.StrconvIntBitSize(string(raw), 64)
-					if err != nil {
-						return err
-					}
-					results = append(results, fmt.Sprintf("%v", r))
-				case "uint64":
-					r, err := common.StrconvUintBitSize(string(raw), 64)
-					if err != nil {
-						return err
-					}
-					results = append(results, fmt.Sprintf("%v", r))
-				case "float32":
-					r, err := common.StrconvFloatBitSize(string(raw), 32)
-					if err != nil {
-						return err
-					}
-					results = append(results, fmt.Sprintf("%v", r))
-				case "float64":
-					r, err := common.StrconvFloatBitSize(string(raw), 64)
-					if err != nil {
-						return err
-					}
-					results = append(results, fmt.Sprintf("%v", r))
-				case "rune":
-					r, err := common.StrconvRune(string(raw))
-					if err != nil {
-						return err
-					}
-					results = append(results, fmt.Sprintf("%v", r))
-				case "godror.Number":
-					r, err := decimal.NewFromString(string(raw))
-					if err != nil {
-						return err
-					}
-					if r.IsInteger() {
-						si, err := common.StrconvIntBitSize(string(raw), 64)
-						if err != nil {
-							return err
-						}
-						results = append(results, fmt.Sprintf("%v", si))
-					} else {
-						rf, err := common.StrconvFloatBitSize(string(raw), 64)
-						if err != nil {
-							return err
-						}
-						results = append(results, fmt.Sprintf("%v", rf))
-					}
-				default:
-					var (
-						by []byte
-						bs string
-					)
-					// 处理字符集、特殊字符转义、字符串引用定界符
-					if strings.ToUpper(f.Charset) == common.GBKCharacterSetCSV {
-						gbkBytes, err := common.Utf8ToGbk(raw)
-						if err != nil {
-							return err
-						}
-						by = gbkBytes
-					} else {
-						by = raw
-					}
-
-					if f.EscapeBackslash {
-						bs = common.SpecialLettersUsingMySQL(by)
-					} else {
-						bs = string(by)
-					}
-
-					if f.Delimiter == "" {
-						results = append(results, bs)
-					} else {
-						results = append(results, common.StringsBuilder(f.Delimiter, bs, f.Delimiter))
-					}
+			if convertColumns[i] {
+				if raw, err = f.Converter.Convert(raw); err != nil {
+					return err
 				}
 			}
+			val, err := common.ParseRowValue(raw, columnTypes[i], arena)
+			if err != nil {
+				return err
+			}
+			if i > 0 {
+				lineBuf.WriteString(f.Separator)
+			}
+			if err = f.appendValue(lineBuf, val); err != nil {
+				return err
+			}
 		}
+		lineBuf.WriteString(f.Terminator)
 
 		// 写入文件
-		if _, err = writer.WriteString(common.StringsBuilder(exstrings.Join(results, f.Separator), f.Terminator)); err != nil {
+		if _, err = writer.Write(lineBuf.Bytes()); err != nil {
 			return fmt.Errorf("failed to write data row to csv %w", err)
 		}
 	}
@@ -253,10 +321,6 @@ func (f *File) write(w io.Writer) error {
 		return err
 	}
 
-	if err := writer.Flush(); err != nil {
-		return fmt.Errorf("failed to flush data row to csv %w", err)
-	}
-
 	// Close Rows
 	if err := f.Rows.Close(); err != nil {
 		return err
@@ -272,6 +336,77 @@ func (f *File) write(w io.Writer) error {
 	return nil
 }
 
+// appendValue 追加 CSV 字段值，处理字符集、特殊字符转义、字符串引用定界符
+func (f *File) appendValue(buf *bytes.Buffer, val interface{}) error {
+	switch v := val.(type) {
+	case nil:
+		buf.WriteString(f.NullValue)
+	case []byte:
+		by := v
+		// 配置字符集转换时字符字段已在读取时转换
+		if f.Converter == nil && strings.ToUpper(f.Charset) == common.GBKCharacterSetCSV {
+			gbkBytes, err := common.Utf8ToGbk(v)
+			if err != nil {
+				return err
+			}
+			by = gbkBytes
+		}
+
+		quote := f.Delimiter
+		if f.QuoteStyle == common.MigrateCSVQuoteStyleMinimal && !f.needQuote(by) {
+			quote = ""
+		}
+		buf.WriteString(quote)
+		switch {
+		case f.EscapeBackslash:
+			common.AppendSpecialLettersUsingMySQL(buf, by)
+		case f.EscapeChar != "" && quote != "":
+			f.appendEscapeQuote(buf, by)
+		default:
+			buf.Write(by)
+		}
+		buf.WriteString(quote)
+	default:
+		common.AppendMySQLValue(buf, v)
+	}
+	return nil
+}
+
+// needQuote MINIMAL 引用方式下，空字符串需引用以区分 NULL
+func (f *File) needQuote(by []byte) bool {
+	if len(by) == 0 {
+		return true
+	}
+	if bytes.ContainsAny(by, "\r\n") || bytes.Contains(by, []byte(f.Separator)) {
+		return true
+	}
+	if f.Delimiter != "" && bytes.Contains(by, []byte(f.Delimiter)) {
+		return true
+	}
+	return f.Terminator != "" && bytes.Contains(by, []byte(f.Terminator))
+}
+
+// appendEscapeQuote 引用定界符以及转义字符前追加转义字符，转义字符与定界符相同即双写定界符
+func (f *File) appendEscapeQuote(buf *bytes.Buffer, by []byte) {
+	delimiter := []byte(f.Delimiter)
+	escape := []byte(f.EscapeChar)
+	for i := 0; i < len(by); {
+		switch {
+		case bytes.HasPrefix(by[i:], delimiter):
+			buf.Write(escape)
+			buf.Write(delimiter)
+			i += len(delimiter)
+		case bytes.HasPrefix(by[i:], escape):
+			buf.Write(escape)
+			buf.Write(escape)
+			i += len(escape)
+		default:
+			buf.WriteByte(by[i])
+			i++
+		}
+	}
+}
+
 func (f *File) String() string {
 	jsonStr, _ := json.Marshal(f)
 	return string(jsonStr)
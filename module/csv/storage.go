@@ -0,0 +1,528 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package csv
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"go.uber.org/zap"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IsObjectStorage output-dir 是否对象存储路径 s3://bucket/prefix、oss://bucket/prefix
+func IsObjectStorage(dir string) bool {
+	lower := strings.ToLower(dir)
+	return strings.HasPrefix(lower, common.MigrateCSVStorageSchemeS3) || strings.HasPrefix(lower, common.MigrateCSVStorageSchemeOSS)
+}
+
+// JoinPath 拼接 csv 输出路径，对象存储路径保留 scheme 双斜杠
+func JoinPath(dir string, elem ...string) string {
+	if !IsObjectStorage(dir) {
+		return filepath.Join(append([]string{dir}, elem...)...)
+	}
+	idx := strings.Index(dir, "://") + len("://")
+	return common.StringsBuilder(dir[:idx], path.Join(append([]string{dir[idx:]}, elem...)...))
+}
+
+// ObjectStorage S3 协议对象存储客户端，AWS S3、MinIO 以及阿里云 OSS（S3 兼容接口）按 AWS Signature V4 签名
+type ObjectStorage struct {
+	ctx          context.Context
+	endpoint     *url.URL
+	region       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	pathStyle    bool
+	partSize     int
+	retryTimes   int
+	client       *http.Client
+}
+
+func NewObjectStorage(ctx context.Context, cfg config.CSVConfig) (*ObjectStorage, error) {
+	region := cfg.StorageRegion
+	if region == "" {
+		region = "us-east-1"
+	}
+	endpoint := cfg.StorageEndpoint
+	if endpoint == "" {
+		if strings.HasPrefix(strings.ToLower(cfg.OutputDir), common.MigrateCSVStorageSchemeOSS) {
+			return nil, fmt.Errorf("csv config [storage-endpoint] can't be null when output-dir is oss, please configure")
+		}
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = common.StringsBuilder("https://", endpoint)
+	}
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("parse csv config [storage-endpoint] value [%s] failed: %v", endpoint, err)
+	}
+
+	// 未配置密钥读取环境变量
+	accessKey, secretKey := cfg.StorageAccessKey, cfg.StorageSecretKey
+	if accessKey == "" {
+		accessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+	if secretKey == "" {
+		secretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+	if accessKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("csv config [storage-access-key] and [storage-secret-key] or env AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY can't be null, please configure")
+	}
+	sessionToken := cfg.StorageSessionToken
+	if sessionToken == "" {
+		sessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	timeout := cfg.StorageTimeout
+	if timeout <= 0 {
+		timeout = common.MigrateCSVStorageTimeout
+	}
+
+	partSize := cfg.StoragePartSize
+	if partSize <= 0 {
+		partSize = common.MigrateCSVStoragePartSize
+	}
+	if partSize < common.MigrateCSVStorageMinPartSize {
+		return nil, fmt.Errorf("csv config [storage-part-size] value [%d] less than min part size [%d]", partSize, common.MigrateCSVStorageMinPartSize)
+	}
+	return &ObjectStorage{
+		ctx:          ctx,
+		endpoint:     endpointURL,
+		region:       region,
+		accessKey:    accessKey,
+		secretKey:    secretKey,
+		sessionToken: sessionToken,
+		pathStyle:    cfg.StoragePathStyle,
+		partSize:     partSize,
+		retryTimes:   cfg.StorageRetryTimes,
+		client: &http.Client{
+			// 整体请求超时，避免上传卡住导致导出永久挂起
+			Timeout: time.Duration(timeout) * time.Second,
+			Transport: &http.Transport{
+				Proxy:                 http.ProxyFromEnvironment,
+				DialContext:           (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext,
+				TLSHandshakeTimeout:   10 * time.Second,
+				ResponseHeaderTimeout: time.Duration(timeout) * time.Second,
+				IdleConnTimeout:       90 * time.Second,
+				MaxIdleConnsPerHost:   16,
+			},
+		},
+	}, nil
+}
+
+// ParseObjectPath 对象存储路径拆分 bucket、key
+func ParseObjectPath(objectPath string) (string, string, error) {
+	if !IsObjectStorage(objectPath) {
+		return "", "", fmt.Errorf("object path [%s] isn't s3:// or oss://", objectPath)
+	}
+	p := objectPath[strings.Index(objectPath, "://")+len("://"):]
+	items := strings.SplitN(p, "/", 2)
+	if items[0] == "" {
+		return "", "", fmt.Errorf("object path [%s] bucket can't be null", objectPath)
+	}
+	if len(items) == 1 {
+		return items[0], "", nil
+	}
+	return items[0], items[1], nil
+}
+
+// PutObject 单次上传小对象
+func (s *ObjectStorage) PutObject(objectPath string, data []byte) error {
+	bucket, key, err := ParseObjectPath(objectPath)
+	if err != nil {
+		return err
+	}
+	_, err = s.do(s.ctx, http.MethodPut, bucket, key, nil, data)
+	if err != nil {
+		return fmt.Errorf("put object [%s] failed: %v", objectPath, err)
+	}
+	return nil
+}
+
+// StatObject 对象大小，对象不存在返回 false
+func (s *ObjectStorage) StatObject(objectPath string) (int64, bool, error) {
+	bucket, key, err := ParseObjectPath(objectPath)
+	if err != nil {
+		return 0, false, err
+	}
+	resp, err := s.request(s.ctx, http.MethodHead, bucket, key, nil, nil)
+	if err != nil {
+		return 0, false, fmt.Errorf("head object [%s] failed: %v", objectPath, err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return 0, false, nil
+	case resp.StatusCode/100 != 2:
+		return 0, false, fmt.Errorf("head object [%s] failed: status [%s]", objectPath, resp.Status)
+	}
+	return resp.ContentLength, true, nil
+}
+
+type ObjectInfo struct {
+	Key  string `xml:"Key"`
+	Size int64  `xml:"Size"`
+}
+
+type listBucketResult struct {
+	Contents              []ObjectInfo `xml:"Contents"`
+	IsTruncated           bool         `xml:"IsTruncated"`
+	NextContinuationToken string       `xml:"NextContinuationToken"`
+}
+
+// ListObjects 列出前缀下全部对象，ListObjectsV2 分页
+func (s *ObjectStorage) ListObjects(prefixPath string) ([]ObjectInfo, error) {
+	bucket, prefix, err := ParseObjectPath(prefixPath)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		objects []ObjectInfo
+		token   string
+	)
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		body, err := s.do(s.ctx, http.MethodGet, bucket, "", query, nil)
+		if err != nil {
+			return nil, fmt.Errorf("list objects [%s] failed: %v", prefixPath, err)
+		}
+		res := &listBucketResult{}
+		if err = xml.Unmarshal(body, res); err != nil {
+			return nil, fmt.Errorf("xml unmarshal list objects [%s] failed: %v", prefixPath, err)
+		}
+		objects = append(objects, res.Contents...)
+		if !res.IsTruncated || res.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = res.NextContinuationToken
+	}
+}
+
+// do 发送请求并读取响应，非 2xx 视为失败
+func (s *ObjectStorage) do(ctx context.Context, method, bucket, key string, query url.Values, payload []byte) ([]byte, error) {
+	resp, err := s.request(ctx, method, bucket, key, query, payload)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("status [%s] response [%s]", resp.Status, string(body))
+	}
+	return body, nil
+}
+
+// request 发送签名请求，网络错误以及 5xx/429（含 503 SlowDown）按指数退避重试，每次重试重新签名
+// 返回非可重试状态码或重试耗尽后的最后一次响应，由调用方判断状态码
+func (s *ObjectStorage) request(ctx context.Context, method, bucket, key string, query url.Values, payload []byte) (*http.Response, error) {
+	backoff := time.Second
+	for i := 0; ; i++ {
+		resp, err := s.send(ctx, method, bucket, key, query, payload)
+		retryable := err != nil || resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		if !retryable || i >= s.retryTimes || ctx.Err() != nil {
+			return resp, err
+		}
+		if err == nil {
+			err = fmt.Errorf("status [%s]", resp.Status)
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		zap.L().Warn("object storage request failed, retry",
+			zap.String("method", method),
+			zap.String("bucket", bucket),
+			zap.String("key", key),
+			zap.Int("retry", i+1),
+			zap.Int("retry times", s.retryTimes),
+			zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+func (s *ObjectStorage) send(ctx context.Context, method, bucket, key string, query url.Values, payload []byte) (*http.Response, error) {
+	u := *s.endpoint
+	if s.pathStyle {
+		u.Path = common.StringsBuilder("/", bucket, "/", key)
+	} else {
+		u.Host = common.StringsBuilder(bucket, ".", s.endpoint.Host)
+		u.Path = common.StringsBuilder("/", key)
+	}
+	u.RawPath = uriEncode(u.Path, false)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(payload))
+	s.sign(req, payload)
+	return s.client.Do(req)
+}
+
+// sign AWS Signature V4 请求签名
+func (s *ObjectStorage) sign(req *http.Request, payload []byte) {
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	shortDate := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := common.StringsBuilder(
+		"host:", req.URL.Host, "\n",
+		"x-amz-content-sha256:", payloadHash, "\n",
+		"x-amz-date:", amzDate, "\n")
+	// STS 临时凭证 session token 参与签名
+	if s.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+		signedHeaders = common.StringsBuilder(signedHeaders, ";x-amz-security-token")
+		canonicalHeaders = common.StringsBuilder(canonicalHeaders, "x-amz-security-token:", s.sessionToken, "\n")
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{shortDate, s.region, "s3", "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.secretKey), shortDate)
+	signingKey = hmacSHA256(signingKey, s.region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// canonicalQuery 查询参数按 key 排序并按 RFC 3986 编码
+func canonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var items []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			items = append(items, common.StringsBuilder(uriEncode(k, true), "=", uriEncode(v, true)))
+		}
+	}
+	return strings.Join(items, "&")
+}
+
+// uriEncode RFC 3986 编码，仅保留非保留字符，encodeSlash 为 false 时保留路径分隔符
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9'),
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			b.WriteString(fmt.Sprintf("%%%02X", c))
+		}
+	}
+	return b.String()
+}
+
+// ObjectUploader 对象分片上传写入器，缓冲满一个分片即上传，数据不足一个分片时 Close 单次上传
+type ObjectUploader struct {
+	ctx        context.Context
+	storage    *ObjectStorage
+	objectPath string
+	bucket     string
+	key        string
+	uploadID   string
+	buf        []byte
+	parts      []completedPart
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []completedPart `xml:"Part"`
+}
+
+type initiateMultipartUploadResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+func (s *ObjectStorage) NewUploader(objectPath string) (*ObjectUploader, error) {
+	bucket, key, err := ParseObjectPath(objectPath)
+	if err != nil {
+		return nil, err
+	}
+	if key == "" {
+		return nil, fmt.Errorf("object path [%s] key can't be null", objectPath)
+	}
+	return &ObjectUploader{
+		ctx:        s.ctx,
+		storage:    s,
+		objectPath: objectPath,
+		bucket:     bucket,
+		key:        key,
+		buf:        make([]byte, 0, s.partSize),
+	}, nil
+}
+
+func (u *ObjectUploader) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		c := copy(u.buf[len(u.buf):cap(u.buf)], p)
+		u.buf = u.buf[:len(u.buf)+c]
+		written += c
+		p = p[c:]
+		if len(u.buf) == cap(u.buf) {
+			if err := u.uploadPart(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+func (u *ObjectUploader) uploadPart() error {
+	if u.uploadID == "" {
+		query := url.Values{}
+		query.Set("uploads", "")
+		body, err := u.storage.do(u.ctx, http.MethodPost, u.bucket, u.key, query, nil)
+		if err != nil {
+			return fmt.Errorf("create multipart upload [%s] failed: %v", u.objectPath, err)
+		}
+		res := &initiateMultipartUploadResult{}
+		if err = xml.Unmarshal(body, res); err != nil {
+			return fmt.Errorf("xml unmarshal multipart upload [%s] failed: %v", u.objectPath, err)
+		}
+		u.uploadID = res.UploadID
+	}
+	partNumber := len(u.parts) + 1
+	if partNumber > common.MigrateCSVStorageMaxParts {
+		return fmt.Errorf("multipart upload [%s] parts over max parts [%d], please increase storage-part-size", u.objectPath, common.MigrateCSVStorageMaxParts)
+	}
+
+	query := url.Values{}
+	query.Set("partNumber", strconv.Itoa(partNumber))
+	query.Set("uploadId", u.uploadID)
+	resp, err := u.storage.request(u.ctx, http.MethodPut, u.bucket, u.key, query, u.buf)
+	if err != nil {
+		return fmt.Errorf("upload object [%s] part [%d] failed: %v", u.objectPath, partNumber, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("upload object [%s] part [%d] failed: status [%s] response [%s]", u.objectPath, partNumber, resp.Status, string(body))
+	}
+	u.parts = append(u.parts, completedPart{PartNumber: partNumber, ETag: resp.Header.Get("ETag")})
+	u.buf = u.buf[:0]
+	return nil
+}
+
+// Close 上传剩余数据并完成分片上传
+func (u *ObjectUploader) Close() error {
+	if u.uploadID == "" {
+		return u.storage.PutObject(u.objectPath, u.buf)
+	}
+	if len(u.buf) > 0 {
+		if err := u.uploadPart(); err != nil {
+			return err
+		}
+	}
+	payload, err := xml.Marshal(&completeMultipartUpload{Parts: u.parts})
+	if err != nil {
+		return fmt.Errorf("xml marshal complete multipart upload [%s] failed: %v", u.objectPath, err)
+	}
+	query := url.Values{}
+	query.Set("uploadId", u.uploadID)
+	body, err := u.storage.do(u.ctx, http.MethodPost, u.bucket, u.key, query, payload)
+	if err != nil {
+		return fmt.Errorf("complete multipart upload [%s] failed: %v", u.objectPath, err)
+	}
+	// 完成请求可能返回 200 但响应体为错误
+	if bytes.Contains(body, []byte("<Error>")) {
+		return fmt.Errorf("complete multipart upload [%s] failed: response [%s]", u.objectPath, string(body))
+	}
+	return nil
+}
+
+// Abort 写入失败放弃分片上传，清理已上传分片
+func (u *ObjectUploader) Abort() {
+	if u.uploadID == "" {
+		return
+	}
+	query := url.Values{}
+	query.Set("uploadId", u.uploadID)
+	_, _ = u.storage.do(context.Background(), http.MethodDelete, u.bucket, u.key, query, nil)
+}
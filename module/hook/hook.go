@@ -0,0 +1,268 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package hook
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/database/mysql"
+	"go.uber.org/zap"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+type Hook struct {
+	ctx    context.Context
+	cfg    *config.Config
+	mysql  *mysql.MySQL
+	metaDB *meta.Meta
+}
+
+func NewHook(ctx context.Context, cfg *config.Config, mysqlDB *mysql.MySQL, metaDB *meta.Meta) *Hook {
+	return &Hook{
+		ctx:    ctx,
+		cfg:    cfg,
+		mysql:  mysqlDB,
+		metaDB: metaDB,
+	}
+}
+
+// IsExistHook 判断是否配置对应范围钩子
+func IsExistHook(cfg *config.Config, scope string) bool {
+	for _, rule := range cfg.HookConfig.HookRules {
+		if strings.EqualFold(rule.Scope, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// RunTaskHook 任务开始前/结束后钩子
+func (h *Hook) RunTaskHook(stage string) error {
+	for _, rule := range h.cfg.HookConfig.HookRules {
+		if !strings.EqualFold(rule.Scope, common.TaskHookScopeTask) || !strings.EqualFold(rule.Stage, stage) {
+			continue
+		}
+		if err := h.run(rule, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunTableHook 单表数据写入前/写入完成后钩子
+func (h *Hook) RunTableHook(stage, sourceTable string) error {
+	for _, rule := range h.cfg.HookConfig.HookRules {
+		if !strings.EqualFold(rule.Scope, common.TaskHookScopeTable) || !strings.EqualFold(rule.Stage, stage) {
+			continue
+		}
+		if rule.SourceTable != "" && rule.SourceTable != "*" && !strings.EqualFold(rule.SourceTable, sourceTable) {
+			continue
+		}
+		if err := h.run(rule, common.StringUPPER(sourceTable)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run 执行钩子并记录 hook_history，abort-on-error 返回错误中止任务
+func (h *Hook) run(rule config.HookRule, sourceTable string) error {
+	ctx := h.ctx
+	if rule.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(h.ctx, time.Duration(rule.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	if rule.Command != "" {
+		startTime := time.Now()
+		cmd := exec.CommandContext(ctx, "sh", "-c", rule.Command)
+		cmd.Env = append(os.Environ(),
+			common.StringsBuilder("TRANSFERDB_TASK_MODE=", h.cfg.TaskMode),
+			common.StringsBuilder("TRANSFERDB_SCOPE=", rule.Scope),
+			common.StringsBuilder("TRANSFERDB_STAGE=", rule.Stage),
+			common.StringsBuilder("TRANSFERDB_SCHEMA=", h.cfg.OracleConfig.SchemaName),
+			common.StringsBuilder("TRANSFERDB_TABLE=", sourceTable))
+		output, err := cmd.CombinedOutput()
+		if err = h.record(rule, sourceTable, common.TaskHookTypeShell, rule.Command, string(output), startTime, err); err != nil {
+			return err
+		}
+	}
+
+	if rule.SQLFile != "" {
+		startTime := time.Now()
+		output, err := h.execSQLFile(ctx, rule.SQLFile)
+		if err = h.record(rule, sourceTable, common.TaskHookTypeSQL, rule.SQLFile, output, startTime, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execSQLFile 下游执行 SQL 脚本，按语句结束符拆分依次执行
+func (h *Hook) execSQLFile(ctx context.Context, sqlFile string) (string, error) {
+	sqlBytes, err := os.ReadFile(sqlFile)
+	if err != nil {
+		return "", fmt.Errorf("read hook sql file [%s] failed: %v", sqlFile, err)
+	}
+	var execSQL int
+	for _, s := range splitSQLStatements(string(sqlBytes)) {
+		// 钩子脚本多为索引、约束变更，使用 DDL 用户执行
+		if _, err = h.mysql.DDLDB.ExecContext(ctx, s); err != nil {
+			return fmt.Sprintf("executed sql counts [%d]", execSQL), fmt.Errorf("hook sql [%s] exec failed: %v", s, err)
+		}
+		execSQL++
+	}
+	return fmt.Sprintf("executed sql counts [%d]", execSQL), nil
+}
+
+// splitSQLStatements 按语句结束符拆分 SQL 脚本，忽略引号、反引号以及注释内的结束符
+// 兼容 mysql 客户端 DELIMITER 指令，存储过程、触发器等复合语句需以 DELIMITER 切换结束符，例如 DELIMITER // ... END // DELIMITER ;
+func splitSQLStatements(script string) []string {
+	var (
+		stmts     []string
+		sb        strings.Builder
+		delimiter = ";"
+		quote     byte
+	)
+	flush := func() {
+		if stmt := strings.TrimSpace(sb.String()); stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+		sb.Reset()
+	}
+
+	for i := 0; i < len(script); {
+		c := script[i]
+		// 引号内原样保留，反斜杠转义以及连续引号转义
+		if quote != 0 {
+			sb.WriteByte(c)
+			switch {
+			case c == '\\' && quote != '`' && i+1 < len(script):
+				sb.WriteByte(script[i+1])
+				i += 2
+				continue
+			case c == quote && i+1 < len(script) && script[i+1] == quote:
+				sb.WriteByte(script[i+1])
+				i += 2
+				continue
+			case c == quote:
+				quote = 0
+			}
+			i++
+			continue
+		}
+
+		// 行首 DELIMITER 指令
+		if strings.TrimSpace(sb.String()) == "" && (i == 0 || script[i-1] == '\n') {
+			lineEnd := strings.IndexByte(script[i:], '\n')
+			if lineEnd < 0 {
+				lineEnd = len(script) - i
+			}
+			fields := strings.Fields(script[i : i+lineEnd])
+			if len(fields) == 2 && strings.EqualFold(fields[0], "DELIMITER") {
+				delimiter = fields[1]
+				sb.Reset()
+				i += lineEnd
+				continue
+			}
+		}
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			sb.WriteByte(c)
+			i++
+		case c == '#' || (c == '-' && strings.HasPrefix(script[i:], "--") && (i+2 == len(script) || strings.ContainsRune(" \t\r\n", rune(script[i+2])))):
+			// 单行注释跳过至行尾
+			lineEnd := strings.IndexByte(script[i:], '\n')
+			if lineEnd < 0 {
+				i = len(script)
+			} else {
+				i += lineEnd
+			}
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			// 块注释跳过，/*! ... */ 为 MySQL 可执行注释原样保留
+			commentEnd := len(script)
+			if idx := strings.Index(script[i+2:], "*/"); idx >= 0 {
+				commentEnd = i + 2 + idx + 2
+			}
+			if strings.HasPrefix(script[i:], "/*!") {
+				sb.WriteString(script[i:commentEnd])
+			} else {
+				sb.WriteByte(' ')
+			}
+			i = commentEnd
+		case strings.HasPrefix(script[i:], delimiter):
+			flush()
+			i += len(delimiter)
+		default:
+			sb.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+	return stmts
+}
+
+func (h *Hook) record(rule config.HookRule, sourceTable, hookType, hookDetail, output string, startTime time.Time, hookErr error) error {
+	history := &meta.HookHistory{
+		TaskMode:    h.cfg.TaskMode,
+		SchemaNameS: common.StringUPPER(h.cfg.OracleConfig.SchemaName),
+		TableNameS:  sourceTable,
+		HookScope:   rule.Scope,
+		HookStage:   rule.Stage,
+		HookType:    hookType,
+		HookDetail:  hookDetail,
+		TaskStatus:  common.TaskStatusSuccess,
+		Output:      output,
+		Cost:        time.Now().Sub(startTime).String(),
+	}
+	if hookErr != nil {
+		history.TaskStatus = common.TaskStatusFailed
+		history.ErrorDetail = hookErr.Error()
+	}
+	if err := meta.NewHookHistoryModel(h.metaDB).CreateHookHistory(h.ctx, history); err != nil {
+		return err
+	}
+
+	if hookErr == nil {
+		zap.L().Info("run hook finished",
+			zap.String("scope", rule.Scope),
+			zap.String("stage", rule.Stage),
+			zap.String("table", sourceTable),
+			zap.String("hook", hookDetail),
+			zap.String("cost", history.Cost))
+		return nil
+	}
+	if rule.AbortOnError {
+		return fmt.Errorf("run hook scope [%s] stage [%s] table [%s] hook [%s] failed, abort task: %v", rule.Scope, rule.Stage, sourceTable, hookDetail, hookErr)
+	}
+	zap.L().Warn("run hook failed, skip",
+		zap.String("scope", rule.Scope),
+		zap.String("stage", rule.Stage),
+		zap.String("table", sourceTable),
+		zap.String("hook", hookDetail),
+		zap.String("output", output),
+		zap.Error(hookErr))
+	return nil
+}
@@ -0,0 +1,209 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package analytic
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/oracle"
+	"github.com/wentaojin/transferdb/module/csv"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 建表语句文件名，生成于 csv 表导出目录下
+const analyticDDLFile = "analytic_ddl.sql"
+
+// target 分析型目标端建表以及单表导入
+type target interface {
+	GenCreateTableDDL(targetSchema, targetTable string, columns []map[string]string, primaryColumns []string) ([]string, error)
+	ExecDDL(ddl []string) error
+	LoadTable(tableDir, targetSchema, targetTable string, columns []map[string]string, m *csv.Manifest) error
+}
+
+type Analytic struct {
+	ctx    context.Context
+	cfg    *config.Config
+	oracle *oracle.Oracle
+	target target
+}
+
+func NewAnalytic(ctx context.Context, cfg *config.Config) (*Analytic, error) {
+	if cfg.AnalyticConfig.BinaryPath == "" {
+		return nil, fmt.Errorf("analytic config [binary-path] can not be null")
+	}
+	if cfg.AnalyticConfig.Host == "" {
+		return nil, fmt.Errorf("analytic config [host] can not be null")
+	}
+
+	var (
+		t   target
+		err error
+	)
+	switch cfg.DBTypeT {
+	case common.DatabaseTypeClickHouse:
+		t = newClickHouse(ctx, cfg.AnalyticConfig)
+	case common.DatabaseTypeGreenplum:
+		t, err = newGreenplum(ctx, cfg.AnalyticConfig)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("analytic mode target db type [%s] isn't support, only support clickhouse/greenplum", cfg.DBTypeT)
+	}
+
+	oracleDB, err := oracle.NewOracleDBEngine(ctx, cfg.OracleConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &Analytic{
+		ctx:    ctx,
+		cfg:    cfg,
+		oracle: oracleDB,
+		target: t,
+	}, nil
+}
+
+// Load 以 csv 导出目录各表清单为准，按源端字段以及主键生成目标端建表语句，再调用原生批量导入工具逐表导入
+func (a *Analytic) Load() error {
+	startTime := time.Now()
+	dataDir := a.cfg.CSVConfig.OutputDir
+	if csv.IsObjectStorage(dataDir) {
+		return fmt.Errorf("csv config output-dir [%s] is object storage, analytic import isn't support, please download to local dir", dataDir)
+	}
+
+	// 目录格式：${output-dir}/${schema}/${table}/manifest.json
+	manifestFiles, err := filepath.Glob(filepath.Join(dataDir, "*", "*", common.MigrateCSVManifestFile))
+	if err != nil {
+		return fmt.Errorf("glob data dir [%s] manifest failed: %v", dataDir, err)
+	}
+	if len(manifestFiles) == 0 {
+		return fmt.Errorf("data dir [%s] isn't exist table manifest [%s], please check csv output dir", dataDir, common.MigrateCSVManifestFile)
+	}
+
+	zap.L().Info("analytic import start",
+		zap.String("target", a.cfg.DBTypeT),
+		zap.String("binary", a.cfg.AnalyticConfig.BinaryPath),
+		zap.String("data dir", dataDir),
+		zap.Int("table totals", len(manifestFiles)))
+
+	g := &errgroup.Group{}
+	g.SetLimit(a.cfg.AnalyticConfig.TableThreads)
+	for _, f := range manifestFiles {
+		tableDir := filepath.Dir(f)
+		g.Go(func() error {
+			return a.loadTable(tableDir)
+		})
+	}
+	if err = g.Wait(); err != nil {
+		return err
+	}
+
+	zap.L().Info("analytic import finished",
+		zap.String("target", a.cfg.DBTypeT),
+		zap.String("data dir", dataDir),
+		zap.Int("table totals", len(manifestFiles)),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
+
+func (a *Analytic) loadTable(tableDir string) error {
+	startTime := time.Now()
+	m, err := csv.ReadManifest(tableDir)
+	if err != nil {
+		return err
+	}
+	if m.BOM {
+		return fmt.Errorf("table [%s.%s] csv with bom analytic import isn't support", m.SchemaNameS, m.TableNameS)
+	}
+
+	// 导入端 schema 优先以配置文件为准
+	targetSchema := common.StringUPPER(m.SchemaNameT)
+	if a.cfg.AnalyticConfig.SchemaName != "" {
+		targetSchema = a.cfg.AnalyticConfig.SchemaName
+	}
+	targetTable := common.StringUPPER(m.TableNameT)
+
+	columns, err := a.getTableColumns(m)
+	if err != nil {
+		return err
+	}
+
+	if !a.cfg.AnalyticConfig.SkipDDL {
+		pkRes, err := a.oracle.GetOracleSchemaTablePrimaryKey(m.SchemaNameS, m.TableNameS)
+		if err != nil {
+			return err
+		}
+		var primaryColumns []string
+		if len(pkRes) > 0 {
+			primaryColumns = strings.Split(pkRes[0]["COLUMN_LIST"], ",")
+		}
+		ddl, err := a.target.GenCreateTableDDL(targetSchema, targetTable, columns, primaryColumns)
+		if err != nil {
+			return fmt.Errorf("table [%s.%s] gen analytic ddl failed: %v", m.SchemaNameS, m.TableNameS, err)
+		}
+		ddlFile := filepath.Join(tableDir, analyticDDLFile)
+		if err = os.WriteFile(ddlFile, []byte(common.StringsBuilder(strings.Join(ddl, ";\n"), ";\n")), 0666); err != nil {
+			return fmt.Errorf("write table [%s.%s] analytic ddl [%s] failed: %v", m.SchemaNameS, m.TableNameS, ddlFile, err)
+		}
+		if err = a.target.ExecDDL(ddl); err != nil {
+			return err
+		}
+	}
+
+	if err = a.target.LoadTable(tableDir, targetSchema, targetTable, columns, m); err != nil {
+		return err
+	}
+
+	zap.L().Info("analytic table import finished",
+		zap.String("target", a.cfg.DBTypeT),
+		zap.String("schema", targetSchema),
+		zap.String("table", targetTable),
+		zap.Int("files", len(m.Files)),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
+
+// getTableColumns 源端字段定义，字段顺序以清单为准，源端表结构与导出时不一致报错
+func (a *Analytic) getTableColumns(m *csv.Manifest) ([]map[string]string, error) {
+	columnINFO, err := a.oracle.GetOracleSchemaTableColumn(m.SchemaNameS, m.TableNameS, false)
+	if err != nil {
+		return nil, err
+	}
+	columnMap := make(map[string]map[string]string, len(columnINFO))
+	for _, c := range columnINFO {
+		columnMap[c["COLUMN_NAME"]] = c
+	}
+	if len(columnMap) != len(m.Columns) {
+		return nil, fmt.Errorf("oracle table [%s.%s] columns [%d] isn't equal csv manifest columns [%d], please rerun csv export", m.SchemaNameS, m.TableNameS, len(columnMap), len(m.Columns))
+	}
+
+	var columns []map[string]string
+	for _, c := range m.Columns {
+		column, ok := columnMap[c]
+		if !ok {
+			return nil, fmt.Errorf("csv manifest column [%s] isn't exist in oracle table [%s.%s], please rerun csv export", c, m.SchemaNameS, m.TableNameS)
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
@@ -0,0 +1,271 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package analytic
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/module/csv"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ClickHouse 原生协议默认端口
+const clickHouseDefaultPort = 9000
+
+type clickHouse struct {
+	ctx context.Context
+	cfg config.AnalyticConfig
+}
+
+func newClickHouse(ctx context.Context, cfg config.AnalyticConfig) *clickHouse {
+	if cfg.Port <= 0 {
+		cfg.Port = clickHouseDefaultPort
+	}
+	if cfg.Engine == "" {
+		cfg.Engine = common.AnalyticClickHouseDefaultEngine
+	}
+	return &clickHouse{
+		ctx: ctx,
+		cfg: cfg,
+	}
+}
+
+// GenCreateTableDDL ClickHouse 无 schema 概念，目标 schema 对应 database
+// 排序键取源端主键字段，排序键字段不允许 Nullable，其余可空字段映射 Nullable(T)
+func (c *clickHouse) GenCreateTableDDL(targetSchema, targetTable string, columns []map[string]string, primaryColumns []string) ([]string, error) {
+	primaryMap := make(map[string]struct{}, len(primaryColumns))
+	for _, p := range primaryColumns {
+		primaryMap[p] = struct{}{}
+	}
+
+	var columnMetas []string
+	for _, column := range columns {
+		columnName := column["COLUMN_NAME"]
+		columnType, err := OracleTableColumnMapClickHouseRule(columnName, column)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := primaryMap[columnName]; !ok && column["NULLABLE"] == "Y" {
+			columnType = common.StringsBuilder("Nullable(", columnType, ")")
+		}
+		columnMeta := common.StringsBuilder("`", columnName, "` ", columnType)
+		if column["COMMENTS"] != "NULLABLE" && column["COMMENTS"] != "" {
+			columnMeta = common.StringsBuilder(columnMeta, " COMMENT '", clickHouseEscapeString(column["COMMENTS"]), "'")
+		}
+		columnMetas = append(columnMetas, columnMeta)
+	}
+
+	orderBy := "tuple()"
+	if len(primaryColumns) > 0 {
+		var keys []string
+		for _, p := range primaryColumns {
+			keys = append(keys, common.StringsBuilder("`", p, "`"))
+		}
+		orderBy = common.StringsBuilder("(", strings.Join(keys, ","), ")")
+	}
+
+	return []string{
+		common.StringsBuilder("CREATE DATABASE IF NOT EXISTS `", targetSchema, "`"),
+		common.StringsBuilder("CREATE TABLE IF NOT EXISTS `", targetSchema, "`.`", targetTable, "` (\n    ",
+			strings.Join(columnMetas, ",\n    "), "\n) ENGINE = ", c.cfg.Engine, " ORDER BY ", orderBy),
+	}, nil
+}
+
+func (c *clickHouse) ExecDDL(ddl []string) error {
+	for _, d := range ddl {
+		if err := c.execQuery(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadTable 清空目标表后逐文件解压并以标准输入流式传给 clickhouse-client INSERT ... FORMAT CSV
+func (c *clickHouse) LoadTable(tableDir, targetSchema, targetTable string, columns []map[string]string, m *csv.Manifest) error {
+	settings, err := c.csvSettings(m)
+	if err != nil {
+		return err
+	}
+	if err = c.execQuery(common.StringsBuilder("TRUNCATE TABLE IF EXISTS `", targetSchema, "`.`", targetTable, "`")); err != nil {
+		return err
+	}
+
+	var columnNames []string
+	for _, column := range columns {
+		columnNames = append(columnNames, common.StringsBuilder("`", column["COLUMN_NAME"], "`"))
+	}
+	format := "CSV"
+	if m.Header {
+		format = "CSVWithNames"
+	}
+	insertSQL := common.StringsBuilder("INSERT INTO `", targetSchema, "`.`", targetTable, "` (", strings.Join(columnNames, ","), ") FORMAT ", format)
+
+	for _, f := range m.Files {
+		if err = c.loadFile(filepath.Join(tableDir, f.FileName), insertSQL, settings, m.Compress); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *clickHouse) loadFile(fileName, insertSQL string, settings []string, compress string) error {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return fmt.Errorf("open csv file [%s] failed: %v", fileName, err)
+	}
+	defer file.Close()
+
+	reader, err := csv.NewDecompressReader(compress, file)
+	if err != nil {
+		return fmt.Errorf("csv file [%s] decompress failed: %v", fileName, err)
+	}
+
+	cmd := c.command(append(append(c.connArgs(), settings...), "--query", insertSQL)...)
+	cmd.Stdin = reader
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("clickhouse-client import csv file [%s] failed: %v, output: %s", fileName, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func (c *clickHouse) execQuery(query string) error {
+	cmd := c.command(append(c.connArgs(), "--query", query)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("clickhouse-client query [%s] failed: %v, output: %s", query, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func (c *clickHouse) connArgs() []string {
+	args := []string{"--host", c.cfg.Host, "--port", strconv.Itoa(c.cfg.Port)}
+	if c.cfg.Username != "" {
+		args = append(args, "--user", c.cfg.Username)
+	}
+	return args
+}
+
+// command 密码经 CLICKHOUSE_PASSWORD 环境变量传递，避免出现在进程命令行参数
+func (c *clickHouse) command(args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(c.ctx, c.cfg.BinaryPath, args...)
+	if c.cfg.Password != "" {
+		cmd.Env = append(os.Environ(), common.StringsBuilder("CLICKHOUSE_PASSWORD=", c.cfg.Password))
+	}
+	return cmd
+}
+
+// csvSettings ClickHouse CSV 仅支持双写定界符转义，分隔符单个字符，数据需为 UTF-8 编码
+func (c *clickHouse) csvSettings(m *csv.Manifest) ([]string, error) {
+	if !strings.EqualFold(m.Charset, common.UTF8CharacterSetCSV) {
+		return nil, fmt.Errorf("table [%s.%s] csv charset [%s] clickhouse isn't support, only support utf8", m.SchemaNameS, m.TableNameS, m.Charset)
+	}
+	if len([]rune(m.Separator)) != 1 {
+		return nil, fmt.Errorf("table [%s.%s] csv separator [%s] clickhouse isn't support, only support single character", m.SchemaNameS, m.TableNameS, m.Separator)
+	}
+	if m.EscapeBackslash || (m.EscapeChar != "" && m.EscapeChar != m.Delimiter) {
+		return nil, fmt.Errorf("table [%s.%s] csv escape char [%s] clickhouse isn't support, only support double delimiter", m.SchemaNameS, m.TableNameS, m.EscapeChar)
+	}
+	if m.Terminator != "\n" && m.Terminator != "\r\n" {
+		return nil, fmt.Errorf("table [%s.%s] csv terminator [%q] clickhouse isn't support, only support \\n or \\r\\n", m.SchemaNameS, m.TableNameS, m.Terminator)
+	}
+
+	settings := []string{
+		common.StringsBuilder("--format_csv_delimiter=", m.Separator),
+		common.StringsBuilder("--format_csv_null_representation=", m.NullLiteral()),
+	}
+	switch m.Delimiter {
+	case "", `"`:
+	case `'`:
+		settings = append(settings, "--format_csv_allow_single_quotes=1", "--format_csv_allow_double_quotes=0")
+	default:
+		return nil, fmt.Errorf("table [%s.%s] csv delimiter [%s] clickhouse isn't support, only support \" or '", m.SchemaNameS, m.TableNameS, m.Delimiter)
+	}
+	return settings, nil
+}
+
+// OracleTableColumnMapClickHouseRule 字段类型映射
+// 1、NUMBER 整数按精度映射 Int8/Int16/Int32/Int64/Int128，未指定精度映射 Float64，其余 Decimal
+// 2、字符、大字段、ROWID、INTERVAL 以及二进制统一映射 String
+// 3、DATE 映射 DateTime64(0)，TIMESTAMP 映射 DateTime64(6)（DateTime 仅支持 1970 年以后），csv 导出时带时区时间已去除时区
+func OracleTableColumnMapClickHouseRule(columnName string, column map[string]string) (string, error) {
+	dataType := common.StringUPPER(column["DATA_TYPE"])
+	dataPrecision, err := strconv.Atoi(column["DATA_PRECISION"])
+	if err != nil {
+		return "", fmt.Errorf("column [%s] data_precision [%s] parse failed: %v", columnName, column["DATA_PRECISION"], err)
+	}
+	dataScale, err := strconv.Atoi(column["DATA_SCALE"])
+	if err != nil {
+		return "", fmt.Errorf("column [%s] data_scale [%s] parse failed: %v", columnName, column["DATA_SCALE"], err)
+	}
+
+	switch {
+	case dataType == "NUMBER":
+		switch {
+		case dataPrecision == 38 && dataScale == 127:
+			return "Float64", nil
+		case dataScale == 0 && dataPrecision <= 2:
+			return "Int8", nil
+		case dataScale == 0 && dataPrecision <= 4:
+			return "Int16", nil
+		case dataScale == 0 && dataPrecision <= 9:
+			return "Int32", nil
+		case dataScale == 0 && dataPrecision <= 18:
+			return "Int64", nil
+		case dataScale == 0:
+			return "Int128", nil
+		case dataScale < 0:
+			// 负数 scale 小数点左侧舍入，保留整数位
+			precision := dataPrecision - dataScale
+			if precision > 38 {
+				precision = 38
+			}
+			return fmt.Sprintf("Decimal(%d,0)", precision), nil
+		case dataScale > dataPrecision:
+			// NUMBER(3,5) 等小数位超出精度
+			return fmt.Sprintf("Decimal(%d,%d)", dataScale, dataScale), nil
+		default:
+			return fmt.Sprintf("Decimal(%d,%d)", dataPrecision, dataScale), nil
+		}
+	case dataType == "FLOAT" || dataType == "BINARY_DOUBLE":
+		return "Float64", nil
+	case dataType == "BINARY_FLOAT":
+		return "Float32", nil
+	case dataType == "DATE":
+		return "DateTime64(0)", nil
+	case strings.HasPrefix(dataType, "TIMESTAMP"):
+		if dataScale == 0 {
+			return "DateTime64(0)", nil
+		}
+		return "DateTime64(6)", nil
+	case dataType == "CHAR" || dataType == "NCHAR" || dataType == "VARCHAR2" || dataType == "NVARCHAR2" ||
+		dataType == "CLOB" || dataType == "NCLOB" || dataType == "LONG" || dataType == "XMLTYPE" ||
+		dataType == "ROWID" || dataType == "UROWID" || strings.HasPrefix(dataType, "INTERVAL"):
+		return "String", nil
+	case dataType == "BLOB" || dataType == "LONG RAW" || dataType == "RAW":
+		return "String", nil
+	default:
+		return "", fmt.Errorf("column [%s] datatype [%s] isn't support clickhouse", columnName, dataType)
+	}
+}
+
+func clickHouseEscapeString(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+}
@@ -0,0 +1,238 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package analytic
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	_ "gitee.com/opengauss/openGauss-connector-go-pq"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/module/csv"
+	"github.com/wentaojin/transferdb/module/reverse/o2og"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Greenplum master 默认端口
+	greenplumDefaultPort = 5432
+	// gpload 控制文件名，生成于 csv 表导出目录下
+	gploadControlFile = "gpload.yaml"
+)
+
+type greenplum struct {
+	ctx context.Context
+	cfg config.AnalyticConfig
+	db  *sql.DB
+}
+
+// newGreenplum 建表语句经 PostgreSQL 协议执行，数据经 gpload 调用 gpfdist 由各 segment 并行拉取
+func newGreenplum(ctx context.Context, cfg config.AnalyticConfig) (*greenplum, error) {
+	if cfg.Port <= 0 {
+		cfg.Port = greenplumDefaultPort
+	}
+	if cfg.DBName == "" {
+		return nil, fmt.Errorf("analytic config [db-name] can not be null")
+	}
+	if cfg.TableOptions == "" {
+		cfg.TableOptions = common.AnalyticGreenplumDefaultTableOptions
+	}
+
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
+		cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.DBName)
+	db, err := sql.Open("opengauss", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error on open greenplum database connection [%v] user [%v]: %v", cfg.DBName, cfg.Username, err)
+	}
+	db.SetMaxOpenConns(cfg.TableThreads)
+	if err = db.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("error on ping greenplum database connection [%v] user [%v]: %v", cfg.DBName, cfg.Username, err)
+	}
+	return &greenplum{
+		ctx: ctx,
+		cfg: cfg,
+		db:  db,
+	}, nil
+}
+
+// GenCreateTableDDL 分布键取源端主键字段，不创建主键约束（追加优化表不支持唯一索引）
+func (g *greenplum) GenCreateTableDDL(targetSchema, targetTable string, columns []map[string]string, primaryColumns []string) ([]string, error) {
+	var columnMetas []string
+	for _, column := range columns {
+		columnName := column["COLUMN_NAME"]
+		columnType, err := OracleTableColumnMapGreenplumRule(columnName, column)
+		if err != nil {
+			return nil, err
+		}
+		columnMeta := common.StringsBuilder(`"`, columnName, `" `, columnType)
+		if column["NULLABLE"] == "N" {
+			columnMeta = common.StringsBuilder(columnMeta, " NOT NULL")
+		}
+		columnMetas = append(columnMetas, columnMeta)
+	}
+
+	ddl := common.StringsBuilder(`CREATE TABLE IF NOT EXISTS "`, targetSchema, `"."`, targetTable, "\" (\n    ",
+		strings.Join(columnMetas, ",\n    "), "\n)")
+	if g.cfg.TableOptions != "" {
+		ddl = common.StringsBuilder(ddl, " WITH (", g.cfg.TableOptions, ")")
+	}
+	if len(primaryColumns) > 0 {
+		var keys []string
+		for _, p := range primaryColumns {
+			keys = append(keys, common.StringsBuilder(`"`, p, `"`))
+		}
+		ddl = common.StringsBuilder(ddl, " DISTRIBUTED BY (", strings.Join(keys, ","), ")")
+	} else {
+		ddl = common.StringsBuilder(ddl, " DISTRIBUTED RANDOMLY")
+	}
+
+	return []string{
+		common.StringsBuilder(`CREATE SCHEMA IF NOT EXISTS "`, targetSchema, `"`),
+		ddl,
+	}, nil
+}
+
+func (g *greenplum) ExecDDL(ddl []string) error {
+	for _, d := range ddl {
+		if _, err := g.db.ExecContext(g.ctx, d); err != nil {
+			return fmt.Errorf("greenplum ddl sql [%v] exec failed: %v", d, err)
+		}
+	}
+	return nil
+}
+
+// LoadTable 生成 gpload 控制文件，导入前清空目标表，单表全部文件一次导入
+func (g *greenplum) LoadTable(tableDir, targetSchema, targetTable string, columns []map[string]string, m *csv.Manifest) error {
+	controlFile := filepath.Join(tableDir, gploadControlFile)
+	if err := g.writeControlFile(controlFile, tableDir, targetSchema, targetTable, columns, m); err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(g.ctx, g.cfg.BinaryPath, "-f", controlFile)
+	cmd.Env = append(os.Environ(), common.StringsBuilder("PGPASSWORD=", g.cfg.Password))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("gpload import table [%s.%s] control file [%s] failed: %v, output: %s",
+			targetSchema, targetTable, controlFile, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// writeControlFile gpfdist 按行切分文件分发至各 segment，csv 文件头仅首个文件可跳过，需关闭 header 导出
+func (g *greenplum) writeControlFile(controlFile, tableDir, targetSchema, targetTable string, columns []map[string]string, m *csv.Manifest) error {
+	if m.Header {
+		return fmt.Errorf("table [%s.%s] csv with header gpload isn't support, please config [csv] header = false and rerun csv export", m.SchemaNameS, m.TableNameS)
+	}
+	if m.Delimiter == "" {
+		return fmt.Errorf("table [%s.%s] csv quote-style none gpload isn't support", m.SchemaNameS, m.TableNameS)
+	}
+	if m.Terminator != "\n" && m.Terminator != "\r\n" {
+		return fmt.Errorf("table [%s.%s] csv terminator [%q] gpload isn't support, only support \\n or \\r\\n", m.SchemaNameS, m.TableNameS, m.Terminator)
+	}
+	switch common.StringUPPER(m.Compress) {
+	case "", common.MigrateCSVCompressGzip, common.MigrateCSVCompressZstd:
+	default:
+		return fmt.Errorf("table [%s.%s] csv compress [%s] gpfdist isn't support, only support gzip/zstd", m.SchemaNameS, m.TableNameS, m.Compress)
+	}
+
+	escape := m.EscapeChar
+	if m.EscapeBackslash {
+		escape = `\`
+	}
+	if escape == "" {
+		escape = m.Delimiter
+	}
+
+	var b strings.Builder
+	b.WriteString("VERSION: 1.0.0.1\n")
+	b.WriteString(common.StringsBuilder("DATABASE: ", yamlQuote(g.cfg.DBName), "\n"))
+	b.WriteString(common.StringsBuilder("USER: ", yamlQuote(g.cfg.Username), "\n"))
+	b.WriteString(common.StringsBuilder("HOST: ", yamlQuote(g.cfg.Host), "\n"))
+	b.WriteString(common.StringsBuilder("PORT: ", strconv.Itoa(g.cfg.Port), "\n"))
+	b.WriteString("GPLOAD:\n")
+	b.WriteString("  INPUT:\n")
+	b.WriteString("    - SOURCE:\n")
+	if g.cfg.LocalHostname != "" {
+		b.WriteString("        LOCAL_HOSTNAME:\n")
+		b.WriteString(common.StringsBuilder("          - ", yamlQuote(g.cfg.LocalHostname), "\n"))
+	}
+	b.WriteString("        FILE:\n")
+	for _, f := range m.Files {
+		fileName, err := filepath.Abs(filepath.Join(tableDir, f.FileName))
+		if err != nil {
+			return fmt.Errorf("csv file [%s] abs path failed: %v", f.FileName, err)
+		}
+		b.WriteString(common.StringsBuilder("          - ", yamlQuote(fileName), "\n"))
+	}
+	b.WriteString("    - COLUMNS:\n")
+	for _, column := range columns {
+		columnType, err := OracleTableColumnMapGreenplumRule(column["COLUMN_NAME"], column)
+		if err != nil {
+			return err
+		}
+		b.WriteString(common.StringsBuilder("        - ", yamlQuote(common.StringsBuilder(`"`, column["COLUMN_NAME"], `"`)), ": ", yamlQuote(columnType), "\n"))
+	}
+	b.WriteString("    - FORMAT: csv\n")
+	b.WriteString(common.StringsBuilder("    - DELIMITER: ", yamlQuote(m.Separator), "\n"))
+	b.WriteString(common.StringsBuilder("    - QUOTE: ", yamlQuote(m.Delimiter), "\n"))
+	b.WriteString(common.StringsBuilder("    - ESCAPE: ", yamlQuote(escape), "\n"))
+	b.WriteString(common.StringsBuilder("    - NULL_AS: ", yamlQuote(m.NullLiteral()), "\n"))
+	b.WriteString(common.StringsBuilder("    - ENCODING: ", yamlQuote(greenplumEncoding(m.Charset)), "\n"))
+	b.WriteString("  OUTPUT:\n")
+	b.WriteString(common.StringsBuilder("    - TABLE: ", yamlQuote(common.StringsBuilder(`"`, targetSchema, `"."`, targetTable, `"`)), "\n"))
+	b.WriteString("    - MODE: insert\n")
+	b.WriteString("  PRELOAD:\n")
+	b.WriteString("    - TRUNCATE: true\n")
+
+	if err := os.WriteFile(controlFile, []byte(b.String()), 0600); err != nil {
+		return fmt.Errorf("write gpload control file [%s] failed: %v", controlFile, err)
+	}
+	return nil
+}
+
+// OracleTableColumnMapGreenplumRule 字段类型映射，复用 openGauss 内置规则
+// 1、NVARCHAR2 为 openGauss 扩展类型，映射 VARCHAR 字符长度
+// 2、INTERVAL csv 导出为 Oracle TO_CHAR 格式，Greenplum 无法解析，映射 TEXT
+func OracleTableColumnMapGreenplumRule(columnName string, column map[string]string) (string, error) {
+	dataType := common.StringUPPER(column["DATA_TYPE"])
+	switch {
+	case dataType == "NVARCHAR2":
+		return fmt.Sprintf("VARCHAR(%s)", column["CHAR_LENGTH"]), nil
+	case strings.HasPrefix(dataType, "INTERVAL"):
+		return "TEXT", nil
+	default:
+		return o2og.OracleTableColumnMapOpenGaussRule(columnName, column)
+	}
+}
+
+func greenplumEncoding(charset string) string {
+	switch common.StringUPPER(charset) {
+	case common.GBKCharacterSetCSV:
+		return "GBK"
+	case common.GB18030CharacterSetCSV:
+		return "GB18030"
+	default:
+		return "UTF8"
+	}
+}
+
+func yamlQuote(s string) string {
+	return common.StringsBuilder("'", strings.ReplaceAll(s, "'", "''"), "'")
+}
@@ -0,0 +1,314 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package f2m
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/database/mysql"
+	"github.com/wentaojin/transferdb/module/csv"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Load struct {
+	ctx    context.Context
+	cfg    *config.Config
+	mysql  *mysql.MySQL
+	metaDB *meta.Meta
+}
+
+func NewLoader(ctx context.Context, cfg *config.Config) (*Load, error) {
+	mysqlDB, err := mysql.NewMySQLDBEngine(ctx, cfg.MySQLConfig)
+	if err != nil {
+		return nil, err
+	}
+	metaDB, err := meta.NewMetaDBEngine(ctx, cfg.MySQLConfig, cfg.AppConfig.SlowlogThreshold)
+	if err != nil {
+		return nil, err
+	}
+	return &Load{
+		ctx:    ctx,
+		cfg:    cfg,
+		mysql:  mysqlDB,
+		metaDB: metaDB,
+	}, nil
+}
+
+func (r *Load) Load() error {
+	startTime := time.Now()
+	zap.L().Info("load csv file data start",
+		zap.String("input dir", r.cfg.LoadConfig.InputDir))
+
+	// 目录格式：${input-dir}/${schema}/${table}/manifest.json
+	manifestFiles, err := filepath.Glob(filepath.Join(r.cfg.LoadConfig.InputDir, "*", "*", common.MigrateCSVManifestFile))
+	if err != nil {
+		return fmt.Errorf("glob input dir [%s] manifest failed: %v", r.cfg.LoadConfig.InputDir, err)
+	}
+	if len(manifestFiles) == 0 {
+		return fmt.Errorf("input dir [%s] isn't exist table manifest [%s], please check csv output dir", r.cfg.LoadConfig.InputDir, common.MigrateCSVManifestFile)
+	}
+
+	var (
+		failedTables []string
+		mu           sync.Mutex
+	)
+	g := &errgroup.Group{}
+	g.SetLimit(r.cfg.LoadConfig.TableThreads)
+
+	for _, f := range manifestFiles {
+		tableDir := filepath.Dir(f)
+		g.Go(func() error {
+			failedFiles, err := r.loadTable(tableDir)
+			if err != nil {
+				return err
+			}
+			if failedFiles > 0 {
+				mu.Lock()
+				failedTables = append(failedTables, tableDir)
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	if err = g.Wait(); err != nil {
+		return err
+	}
+
+	zap.L().Info("load csv file data finished",
+		zap.String("input dir", r.cfg.LoadConfig.InputDir),
+		zap.Int("table totals", len(manifestFiles)),
+		zap.Int("table failed", len(failedTables)),
+		zap.Strings("failed tables", failedTables),
+		zap.String("log detail", "if exist table failed, please see meta table [load_sync_meta]"),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
+
+// loadTable 单表按文件并发导入，返回失败文件数
+func (r *Load) loadTable(tableDir string) (int, error) {
+	startTime := time.Now()
+	manifest, err := csv.ReadManifest(tableDir)
+	if err != nil {
+		return 0, err
+	}
+
+	// 导入端 schema 优先以配置文件为准
+	schemaNameT := common.StringUPPER(manifest.SchemaNameT)
+	if r.cfg.MySQLConfig.SchemaName != "" {
+		schemaNameT = common.StringUPPER(r.cfg.MySQLConfig.SchemaName)
+	}
+	tableNameT := common.StringUPPER(manifest.TableNameT)
+
+	if !r.cfg.LoadConfig.EnableCheckpoint {
+		if err = meta.NewLoadSyncMetaModel(r.metaDB).DeleteLoadSyncMeta(r.ctx, &meta.LoadSyncMeta{
+			DBTypeT:     r.cfg.DBTypeT,
+			SchemaNameT: schemaNameT,
+			TableNameT:  tableNameT,
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	var loadMetas []meta.LoadSyncMeta
+	for _, f := range manifest.Files {
+		loadMetas = append(loadMetas, meta.LoadSyncMeta{
+			DBTypeT:     r.cfg.DBTypeT,
+			SchemaNameT: schemaNameT,
+			TableNameT:  tableNameT,
+			FileName:    f.FileName,
+			FileSize:    f.FileSize,
+			TaskStatus:  common.TaskStatusWaiting,
+		})
+	}
+	if len(loadMetas) == 0 {
+		zap.L().Warn("load table manifest files is null, skip",
+			zap.String("schema", schemaNameT),
+			zap.String("table", tableNameT),
+			zap.String("dir", tableDir))
+		return 0, nil
+	}
+	if err = meta.NewLoadSyncMetaModel(r.metaDB).BatchCreateLoadSyncMeta(r.ctx, loadMetas, r.cfg.AppConfig.InsertBatchSize); err != nil {
+		return 0, err
+	}
+
+	fileMetas, err := meta.NewLoadSyncMetaModel(r.metaDB).DetailLoadSyncMeta(r.ctx, &meta.LoadSyncMeta{
+		DBTypeT:     r.cfg.DBTypeT,
+		SchemaNameT: schemaNameT,
+		TableNameT:  tableNameT,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		skipFiles   int
+		failedFiles int
+	)
+	g := &errgroup.Group{}
+	g.SetLimit(r.cfg.LoadConfig.FileThreads)
+
+	for _, fileMeta := range fileMetas {
+		m := fileMeta
+		// 断点续传，已导入成功文件跳过
+		if strings.EqualFold(m.TaskStatus, common.TaskStatusSuccess) {
+			skipFiles++
+			continue
+		}
+		g.Go(func() error {
+			fileName := filepath.Join(tableDir, m.FileName)
+			if err := meta.NewLoadSyncMetaModel(r.metaDB).UpdateLoadSyncMeta(r.ctx, &m, map[string]interface{}{
+				"TaskStatus": common.TaskStatusRunning,
+			}); err != nil {
+				return err
+			}
+
+			affectRows, errL := r.loadFile(manifest, schemaNameT, tableNameT, fileName)
+			// record error, skip error
+			if errL != nil {
+				return meta.NewLoadSyncMetaModel(r.metaDB).UpdateLoadSyncMeta(r.ctx, &m, map[string]interface{}{
+					"TaskStatus":  common.TaskStatusFailed,
+					"ErrorDetail": errL.Error(),
+				})
+			}
+			return meta.NewLoadSyncMetaModel(r.metaDB).UpdateLoadSyncMeta(r.ctx, &m, map[string]interface{}{
+				"TaskStatus":   common.TaskStatusSuccess,
+				"RowsAffected": affectRows,
+				"ErrorDetail":  "",
+			})
+		})
+	}
+	if err = g.Wait(); err != nil {
+		return 0, err
+	}
+
+	fileMetas, err = meta.NewLoadSyncMetaModel(r.metaDB).DetailLoadSyncMeta(r.ctx, &meta.LoadSyncMeta{
+		DBTypeT:     r.cfg.DBTypeT,
+		SchemaNameT: schemaNameT,
+		TableNameT:  tableNameT,
+		TaskStatus:  common.TaskStatusFailed,
+	})
+	if err != nil {
+		return 0, err
+	}
+	failedFiles = len(fileMetas)
+
+	zap.L().Info("load single table csv file finished",
+		zap.String("schema", schemaNameT),
+		zap.String("table", tableNameT),
+		zap.Int("file totals", len(manifest.Files)),
+		zap.Int("file skip", skipFiles),
+		zap.Int("file failed", failedFiles),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return failedFiles, nil
+}
+
+// loadFile 单文件导入，压缩文件经 Reader 边解压边导入
+func (r *Load) loadFile(m *csv.Manifest, schemaNameT, tableNameT, fileName string) (int64, error) {
+	if m.Compress == "" {
+		loadSQL, err := genLoadSQL(m, schemaNameT, tableNameT, fileName, r.cfg.LoadConfig.Replace)
+		if err != nil {
+			return 0, err
+		}
+		return r.mysql.LoadMySQLTableByLocalFile(fileName, loadSQL)
+	}
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		return 0, fmt.Errorf("open load file [%s] failed: %v", fileName, err)
+	}
+	defer file.Close()
+	reader, err := csv.NewDecompressReader(m.Compress, file)
+	if err != nil {
+		return 0, fmt.Errorf("decompress load file [%s] failed: %v", fileName, err)
+	}
+	readerName := common.StringsBuilder("Reader::", fileName)
+	loadSQL, err := genLoadSQL(m, schemaNameT, tableNameT, readerName, r.cfg.LoadConfig.Replace)
+	if err != nil {
+		return 0, err
+	}
+	return r.mysql.LoadMySQLTableByReader(fileName, reader, loadSQL)
+}
+
+// genLoadSQL 依据 manifest 记录的 csv 格式生成 LOAD DATA 语句
+// csv 导出 NULL 以及空字符串统一写入 NULL 字面量（默认 NULL，依方言而定），导入时转换成 NULL
+func genLoadSQL(m *csv.Manifest, schemaName, tableName, fileName string, replace bool) (string, error) {
+	if len(m.Columns) == 0 {
+		return "", fmt.Errorf("table [%s.%s] manifest columns is null", m.SchemaNameS, m.TableNameS)
+	}
+	if len([]rune(m.Delimiter)) > 1 {
+		return "", fmt.Errorf("table [%s.%s] manifest delimiter [%s] length over 1, load data isn't support", m.SchemaNameS, m.TableNameS, m.Delimiter)
+	}
+	// BOM 仅可随表头行跳过
+	if m.BOM && !m.Header {
+		return "", fmt.Errorf("table [%s.%s] manifest bom without header, load data isn't support", m.SchemaNameS, m.TableNameS)
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("LOAD DATA LOCAL INFILE '%s'", loadEscape(fileName)))
+	if replace {
+		b.WriteString(" REPLACE")
+	}
+	b.WriteString(fmt.Sprintf(" INTO TABLE `%s`.`%s`", schemaName, tableName))
+	if strings.EqualFold(m.Charset, common.GBKCharacterSetCSV) {
+		b.WriteString(" CHARACTER SET gbk")
+	} else if strings.EqualFold(m.Charset, common.GB18030CharacterSetCSV) {
+		b.WriteString(" CHARACTER SET gb18030")
+	} else {
+		b.WriteString(" CHARACTER SET utf8mb4")
+	}
+	b.WriteString(fmt.Sprintf(" FIELDS TERMINATED BY '%s'", loadEscape(m.Separator)))
+	if m.Delimiter != "" {
+		b.WriteString(fmt.Sprintf(" ENCLOSED BY '%s'", loadEscape(m.Delimiter)))
+	}
+	// 转义字符与定界符相同（双写定界符）LOAD DATA 原生支持，无需 ESCAPED BY
+	switch {
+	case m.EscapeBackslash:
+		b.WriteString(` ESCAPED BY '\\'`)
+	case m.EscapeChar != "" && m.EscapeChar != m.Delimiter:
+		b.WriteString(fmt.Sprintf(" ESCAPED BY '%s'", loadEscape(m.EscapeChar)))
+	default:
+		b.WriteString(` ESCAPED BY ''`)
+	}
+	b.WriteString(fmt.Sprintf(" LINES TERMINATED BY '%s'", loadEscape(m.Terminator)))
+	if m.Header {
+		b.WriteString(" IGNORE 1 LINES")
+	}
+
+	var (
+		vars []string
+		sets []string
+	)
+	nullLiteral := loadEscape(m.NullLiteral())
+	for _, c := range m.Columns {
+		vars = append(vars, common.StringsBuilder("@`", c, "`"))
+		sets = append(sets, common.StringsBuilder("`", c, "` = NULLIF(@`", c, "`,'", nullLiteral, "')"))
+	}
+	b.WriteString(fmt.Sprintf(" (%s) SET %s", strings.Join(vars, ","), strings.Join(sets, ",")))
+	return b.String(), nil
+}
+
+func loadEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\r", `\r`, "\n", `\n`, "\t", `\t`).Replace(s)
+}
@@ -0,0 +1,193 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package lightning
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/module/csv"
+	"go.uber.org/zap"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// lightning 配置文件名，生成于 csv 导出目录下
+const lightningConfigFile = "tidb-lightning.toml"
+
+type Lightning struct {
+	ctx context.Context
+	cfg *config.Config
+}
+
+func NewLightning(ctx context.Context, cfg *config.Config) (*Lightning, error) {
+	if cfg.LightningConfig.BinaryPath == "" {
+		return nil, fmt.Errorf("lightning config [binary-path] can not be null")
+	}
+	if cfg.LightningConfig.PDAddr == "" {
+		return nil, fmt.Errorf("lightning config [pd-addr] can not be null")
+	}
+	return &Lightning{
+		ctx: ctx,
+		cfg: cfg,
+	}, nil
+}
+
+// Load 以 csv 导出目录为数据源生成 tidb-lightning 配置并调用 local backend 导入
+func (l *Lightning) Load() error {
+	startTime := time.Now()
+	dataDir := l.cfg.CSVConfig.OutputDir
+	if csv.IsObjectStorage(dataDir) {
+		return fmt.Errorf("csv config output-dir [%s] is object storage, tidb-lightning import isn't support, please download to local dir", dataDir)
+	}
+
+	manifest, err := l.readManifest(dataDir)
+	if err != nil {
+		return err
+	}
+
+	configFile := filepath.Join(dataDir, lightningConfigFile)
+	if err = l.writeConfig(configFile, dataDir, manifest); err != nil {
+		return err
+	}
+
+	zap.L().Info("tidb-lightning import start",
+		zap.String("binary", l.cfg.LightningConfig.BinaryPath),
+		zap.String("config", configFile),
+		zap.String("data dir", dataDir))
+
+	cmd := exec.CommandContext(l.ctx, l.cfg.LightningConfig.BinaryPath, "-config", configFile)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("tidb-lightning import failed, please see log file [%s]: %v, output: %s",
+			l.cfg.LightningConfig.LogFile, err, strings.TrimSpace(string(output)))
+	}
+
+	zap.L().Info("tidb-lightning import finished",
+		zap.String("config", configFile),
+		zap.String("output", strings.TrimSpace(string(output))),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
+
+// readManifest 读取各表清单，csv 格式全局唯一对应 lightning [mydumper.csv] 配置，格式不一致无法一次导入
+func (l *Lightning) readManifest(dataDir string) (*csv.Manifest, error) {
+	manifestFiles, err := filepath.Glob(filepath.Join(dataDir, "*", "*", common.MigrateCSVManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("glob data dir [%s] manifest failed: %v", dataDir, err)
+	}
+	if len(manifestFiles) == 0 {
+		return nil, fmt.Errorf("data dir [%s] isn't exist table manifest [%s], please check csv output dir", dataDir, common.MigrateCSVManifestFile)
+	}
+
+	var first *csv.Manifest
+	for _, f := range manifestFiles {
+		m, err := csv.ReadManifest(filepath.Dir(f))
+		if err != nil {
+			return nil, err
+		}
+		if first == nil {
+			first = m
+			continue
+		}
+		if m.Header != first.Header || m.Separator != first.Separator || m.Terminator != first.Terminator ||
+			m.Delimiter != first.Delimiter || m.EscapeBackslash != first.EscapeBackslash || m.Charset != first.Charset ||
+			m.EscapeChar != first.EscapeChar || m.NullLiteral() != first.NullLiteral() || m.BOM != first.BOM {
+			return nil, fmt.Errorf("table [%s.%s] csv format isn't consistent with table [%s.%s], lightning isn't support",
+				m.SchemaNameS, m.TableNameS, first.SchemaNameS, first.TableNameS)
+		}
+	}
+	return first, nil
+}
+
+// writeConfig 生成 tidb-lightning 配置，表结构已由 reverse 创建，no-schema 只导入数据
+func (l *Lightning) writeConfig(configFile, dataDir string, m *csv.Manifest) error {
+	// lightning 仅支持反斜杠转义或双写定界符
+	if m.EscapeChar != "" && m.EscapeChar != `\` && m.EscapeChar != m.Delimiter {
+		return fmt.Errorf("table [%s.%s] csv escape char [%s] lightning isn't support", m.SchemaNameS, m.TableNameS, m.EscapeChar)
+	}
+	if m.BOM {
+		return fmt.Errorf("table [%s.%s] csv with bom lightning isn't support", m.SchemaNameS, m.TableNameS)
+	}
+	charset := "utf8mb4"
+	if strings.EqualFold(m.Charset, common.GBKCharacterSetCSV) {
+		charset = "gbk"
+	} else if strings.EqualFold(m.Charset, common.GB18030CharacterSetCSV) {
+		charset = "gb18030"
+	}
+	statusPort := l.cfg.LightningConfig.StatusPort
+	if statusPort <= 0 {
+		statusPort = 10080
+	}
+
+	lightningCfg := map[string]interface{}{
+		"lightning": map[string]interface{}{
+			"level": "info",
+			"file":  l.cfg.LightningConfig.LogFile,
+		},
+		"checkpoint": map[string]interface{}{
+			"enable": l.cfg.LightningConfig.EnableCheckpoint,
+			"driver": "mysql",
+			"schema": "tidb_lightning_checkpoint",
+		},
+		"tikv-importer": map[string]interface{}{
+			"backend":       "local",
+			"sorted-kv-dir": l.cfg.LightningConfig.SortedKVDir,
+		},
+		"mydumper": map[string]interface{}{
+			"data-source-dir": dataDir,
+			"no-schema":       true,
+			"character-set":   charset,
+			"csv": map[string]interface{}{
+				"separator":           m.Separator,
+				"delimiter":           m.Delimiter,
+				"terminator":          m.Terminator,
+				"header":              m.Header,
+				"not-null":            false,
+				"null":                m.NullLiteral(),
+				"backslash-escape":    m.EscapeBackslash,
+				"trim-last-separator": false,
+			},
+		},
+		"tidb": map[string]interface{}{
+			"host":        l.cfg.MySQLConfig.Host,
+			"port":        l.cfg.MySQLConfig.Port,
+			"user":        l.cfg.MySQLConfig.Username,
+			"password":    l.cfg.MySQLConfig.Password,
+			"status-port": statusPort,
+			"pd-addr":     l.cfg.LightningConfig.PDAddr,
+		},
+		"post-restore": map[string]interface{}{
+			"checksum": "required",
+			"analyze":  "optional",
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(lightningCfg); err != nil {
+		return fmt.Errorf("encode tidb-lightning config failed: %v", err)
+	}
+	if err := os.WriteFile(configFile, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("write tidb-lightning config [%s] failed: %v", configFile, err)
+	}
+	return nil
+}
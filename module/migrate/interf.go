@@ -15,16 +15,52 @@ limitations under the License.
 */
 package migrate
 
+import (
+	"context"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+)
+
+// Batch 流水线数据批次，chunk 数据按 batch 在抽取、转换、应用之间流转
+type Batch struct {
+	Columns []string
+	Rows    []common.RowValue
+}
+
 type Extractor interface {
-	GetTableRows() ([]string, []string, error)
+	StreamTableRows(ctx context.Context, batchC chan<- Batch) error
 }
 
 type Translator interface {
-	TranslateTableRows() error
+	TranslateTableRows(ctx context.Context, batchC <-chan Batch, applyC chan<- Batch) error
 }
 
 type Applier interface {
-	ApplyTableRows() error
+	ApplyTableRows(ctx context.Context, applyC <-chan Batch) error
+}
+
+// TargetWriter 目标端写入，chunk 编排（batch 派发、并发控制、进度回调）由 ApplyBatches 统一处理
+// 新增目标端（文件、Kafka、S3 等）仅需实现该接口
+type TargetWriter interface {
+	// Prepare chunk 首个 batch 写入前调用
+	Prepare(ctx context.Context) error
+	// ApplyBatch 单 batch 写入，可能被并发调用
+	ApplyBatch(ctx context.Context, columns []string, rows []common.RowValue) error
+	// Finish chunk 全部 batch 写入成功后调用
+	Finish(ctx context.Context) error
+}
+
+// Source 源端读取，表筛选、一致性读取点、chunk 切分以及 chunk 数据抽取由源端实现
+// 新增源端（PostgreSQL、SQL Server、DB2 等）仅需实现该接口，全量编排不感知源端类型
+type Source interface {
+	// ListTables 按任务配置筛选待迁移表
+	ListTables(ctx context.Context) ([]string, error)
+	// Snapshot 全局一致性读取点，开启 consistent-read 时全部 chunk 基于该点读取
+	Snapshot(ctx context.Context) (uint64, error)
+	// SplitChunks 单表 chunk 切分，返回 chunk 过滤条件
+	SplitChunks(ctx context.Context, table string) ([]string, error)
+	// ReadChunk 单 chunk 抽取器，chunk 数据按 batch 流式输出
+	ReadChunk(ctx context.Context, syncMeta meta.FullSyncMeta) Extractor
 }
 
 type Fuller interface {
@@ -34,3 +70,11 @@ type Fuller interface {
 type Increr interface {
 	Incr() error
 }
+
+type Reloader interface {
+	Reload() error
+}
+
+type Uninstaller interface {
+	Uninstall() error
+}
@@ -0,0 +1,47 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package m2o
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/mysql"
+	"go.uber.org/zap"
+	"time"
+)
+
+func filterCFGTable(cfg *config.Config, mysql *mysql.MySQL) ([]string, error) {
+	startTime := time.Now()
+	ok, err := mysql.IsExistMySQLSchema(cfg.MySQLConfig.SchemaName)
+	if err != nil {
+		return []string{}, err
+	}
+	if !ok {
+		return []string{}, fmt.Errorf("filter cfg mysql schema [%v] tables isn't exists", cfg.MySQLConfig.SchemaName)
+	}
+
+	// 视图无数据，仅迁移普通表
+	normalTables, err := mysql.GetMySQLNormalTable(cfg.MySQLConfig.SchemaName)
+	if err != nil {
+		return normalTables, err
+	}
+
+	zap.L().Info("get mysql to oracle full tables",
+		zap.String("schema", cfg.MySQLConfig.SchemaName),
+		zap.Int("table counts", len(normalTables)),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return normalTables, nil
+}
@@ -0,0 +1,421 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package m2o
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/database/mysql"
+	"github.com/wentaojin/transferdb/database/oracle"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"math"
+	"math/big"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+type Migrate struct {
+	Ctx    context.Context
+	Cfg    *config.Config
+	Oracle *oracle.Oracle
+	Mysql  *mysql.MySQL
+	MetaDB *meta.Meta
+	// 目标端字段改写规则
+	ColumnRewriter *common.NameRewriter
+}
+
+func NewFuller(ctx context.Context, cfg *config.Config) (*Migrate, error) {
+	oracleDB, err := oracle.NewOracleDBEngine(ctx, cfg.OracleConfig)
+	if err != nil {
+		return nil, err
+	}
+	mysqlDB, err := mysql.NewMySQLDBEngine(ctx, cfg.MySQLConfig)
+	if err != nil {
+		return nil, err
+	}
+	metaDB, err := meta.NewMetaDBEngine(ctx, cfg.MySQLConfig, cfg.AppConfig.SlowlogThreshold)
+	if err != nil {
+		return nil, err
+	}
+	columnRewriter, err := cfg.RewriteConfig.ColumnRewriter()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrate{
+		Ctx:            ctx,
+		Cfg:            cfg,
+		Oracle:         oracleDB,
+		Mysql:          mysqlDB,
+		MetaDB:         metaDB,
+		ColumnRewriter: columnRewriter,
+	}, nil
+}
+
+func (r *Migrate) Full() error {
+	startTime := time.Now()
+	zap.L().Info("source schema full table data sync start",
+		zap.String("schema", r.Cfg.MySQLConfig.SchemaName))
+
+	tables, err := filterCFGTable(r.Cfg, r.Mysql)
+	if err != nil {
+		return err
+	}
+
+	tableNameRule, err := r.getTableNameRule()
+	if err != nil {
+		return err
+	}
+
+	var failedTables int64
+	g := &errgroup.Group{}
+	g.SetLimit(r.Cfg.FullConfig.TableThreads)
+
+	for _, table := range tables {
+		sourceTable := table
+		g.Go(func() error {
+			targetTable := common.StringUPPER(sourceTable)
+			if val, ok := tableNameRule[common.StringUPPER(sourceTable)]; ok {
+				targetTable = val
+			}
+			if err := r.syncTable(sourceTable, targetTable); err != nil {
+				atomic.AddInt64(&failedTables, 1)
+				if errL := meta.NewErrorLogDetailModel(r.MetaDB).CreateErrorLog(r.Ctx, &meta.ErrorLogDetail{
+					DBTypeS:     r.Cfg.DBTypeS,
+					DBTypeT:     r.Cfg.DBTypeT,
+					SchemaNameS: r.Cfg.MySQLConfig.SchemaName,
+					TableNameS:  sourceTable,
+					SchemaNameT: common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
+					TableNameT:  targetTable,
+					TaskMode:    r.Cfg.TaskMode,
+					TaskStatus:  common.TaskStatusFailed,
+					InfoDetail:  fmt.Sprintf("mysql table [%s.%s] full sync oracle table [%s.%s]", r.Cfg.MySQLConfig.SchemaName, sourceTable, r.Cfg.OracleConfig.SchemaName, targetTable),
+					ErrorDetail: err.Error(),
+				}); errL != nil {
+					return fmt.Errorf("mysql table [%s.%s] full sync failed: %v, record error log failed: %v", r.Cfg.MySQLConfig.SchemaName, sourceTable, err, errL)
+				}
+				zap.L().Warn("mysql table full sync failed, detail see [error_log_detail]",
+					zap.String("schema", r.Cfg.MySQLConfig.SchemaName),
+					zap.String("table", sourceTable),
+					zap.Error(err))
+			}
+			return nil
+		})
+	}
+	if err = g.Wait(); err != nil {
+		return err
+	}
+
+	if failedTables > 0 {
+		zap.L().Warn("source schema full table data finished",
+			zap.String("schema", r.Cfg.MySQLConfig.SchemaName),
+			zap.Int("table totals", len(tables)),
+			zap.Int64("table failed", failedTables),
+			zap.String("detail", "see [error_log_detail] and rerunning"),
+			zap.String("cost", time.Now().Sub(startTime).String()))
+		return nil
+	}
+	zap.L().Info("source schema full table data finished",
+		zap.String("schema", r.Cfg.MySQLConfig.SchemaName),
+		zap.Int("table totals", len(tables)),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
+
+// syncTable 表级全量同步，chunk 进度记录于 wait_sync_meta/full_sync_meta
+// 首次运行切分 chunk 并清理目标表，重跑时跳过已成功 chunk，未成功 chunk 按范围清理部分写入数据后重新同步
+func (r *Migrate) syncTable(sourceTable, targetTable string) error {
+	startTime := time.Now()
+	targetSchema := common.StringUPPER(r.Cfg.OracleConfig.SchemaName)
+
+	columnINFO, err := r.Mysql.GetMySQLTableColumnType(r.Cfg.MySQLConfig.SchemaName, sourceTable)
+	if err != nil {
+		return err
+	}
+	columns := NewColumns(columnINFO)
+	pkColumn, err := r.Mysql.GetMySQLTableIntegerPKColumn(r.Cfg.MySQLConfig.SchemaName, sourceTable)
+	if err != nil {
+		return err
+	}
+
+	waitMetas, err := meta.NewWaitSyncMetaModel(r.MetaDB).DetailWaitSyncMeta(r.Ctx, &meta.WaitSyncMeta{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: r.Cfg.MySQLConfig.SchemaName,
+		TableNameS:  sourceTable,
+		TaskMode:    r.Cfg.TaskMode,
+	})
+	if err != nil {
+		return err
+	}
+	if len(waitMetas) > 0 && waitMetas[0].TaskStatus == common.TaskStatusSuccess {
+		zap.L().Warn("mysql table full sync already success, skip",
+			zap.String("schema", r.Cfg.MySQLConfig.SchemaName),
+			zap.String("table", sourceTable))
+		return nil
+	}
+	if len(waitMetas) == 0 {
+		if err = r.initTableChunks(sourceTable, targetSchema, targetTable, pkColumn); err != nil {
+			return err
+		}
+	}
+
+	chunkMetas, err := meta.NewFullSyncMetaModel(r.MetaDB).DetailFullSyncMeta(r.Ctx, &meta.FullSyncMeta{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: r.Cfg.MySQLConfig.SchemaName,
+		TableNameS:  sourceTable,
+		TaskMode:    r.Cfg.TaskMode,
+	})
+	if err != nil {
+		return err
+	}
+
+	var columnNames []string
+	for _, c := range columns {
+		columnNames = append(columnNames, common.StringsBuilder("`", c.ColumnName, "`"))
+	}
+	insertSQL := GenOracleInsertSQL(targetSchema, targetTable, columns, r.ColumnRewriter)
+
+	var successChunks, failedChunks int64
+	g := &errgroup.Group{}
+	g.SetLimit(r.Cfg.FullConfig.SQLThreads)
+	for _, c := range chunkMetas {
+		chunk := c
+		if chunk.TaskStatus == common.TaskStatusSuccess {
+			successChunks++
+			continue
+		}
+		g.Go(func() error {
+			if err := r.syncTableChunk(chunk, columns, columnNames, insertSQL, pkColumn); err != nil {
+				atomic.AddInt64(&failedChunks, 1)
+				return meta.NewFullSyncMetaModel(r.MetaDB).UpdateFullSyncMeta(r.Ctx, &chunk, map[string]interface{}{
+					"TaskStatus":  common.TaskStatusFailed,
+					"ErrorDetail": err.Error(),
+				})
+			}
+			atomic.AddInt64(&successChunks, 1)
+			return nil
+		})
+	}
+	if err = g.Wait(); err != nil {
+		return err
+	}
+
+	taskStatus := common.TaskStatusSuccess
+	if failedChunks > 0 {
+		taskStatus = common.TaskStatusFailed
+	}
+	if err = meta.NewWaitSyncMetaModel(r.MetaDB).UpdateWaitSyncMeta(r.Ctx, &meta.WaitSyncMeta{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: r.Cfg.MySQLConfig.SchemaName,
+		TableNameS:  sourceTable,
+		TaskMode:    r.Cfg.TaskMode,
+	}, map[string]interface{}{
+		"TaskStatus":       taskStatus,
+		"ChunkSuccessNums": successChunks,
+		"ChunkFailedNums":  failedChunks,
+	}); err != nil {
+		return err
+	}
+	if failedChunks > 0 {
+		return fmt.Errorf("mysql table [%s.%s] full sync chunk failed [%d], detail see [full_sync_meta], rerunning resume failed chunks",
+			r.Cfg.MySQLConfig.SchemaName, sourceTable, failedChunks)
+	}
+	if err = meta.NewFullSyncMetaModel(r.MetaDB).DeleteFullSyncMetaBySchemaTable(r.Ctx, &meta.FullSyncMeta{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: r.Cfg.MySQLConfig.SchemaName,
+		TableNameS:  sourceTable,
+		TaskMode:    r.Cfg.TaskMode,
+	}); err != nil {
+		return err
+	}
+
+	zap.L().Info("mysql table full sync finished",
+		zap.String("schema", r.Cfg.MySQLConfig.SchemaName),
+		zap.String("table", sourceTable),
+		zap.Int("chunks", len(chunkMetas)),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
+
+// initTableChunks 首次同步切分 chunk、清理目标表并写入 chunk 元数据，wait_sync_meta 最后写入作为初始化完成标志
+func (r *Migrate) initTableChunks(sourceTable, targetSchema, targetTable, pkColumn string) error {
+	chunks, err := r.splitTableChunksByPK(sourceTable, pkColumn)
+	if err != nil {
+		return err
+	}
+	// 清理上次初始化中断残留 chunk 元数据
+	if err = meta.NewFullSyncMetaModel(r.MetaDB).DeleteFullSyncMetaBySchemaTable(r.Ctx, &meta.FullSyncMeta{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: r.Cfg.MySQLConfig.SchemaName,
+		TableNameS:  sourceTable,
+		TaskMode:    r.Cfg.TaskMode,
+	}); err != nil {
+		return err
+	}
+	if err = r.Oracle.TruncateOracleTable(QuoteOracleIdentifier(targetSchema), QuoteOracleIdentifier(targetTable)); err != nil {
+		return err
+	}
+
+	var chunkMetas []meta.FullSyncMeta
+	for _, chunk := range chunks {
+		chunkMetas = append(chunkMetas, meta.FullSyncMeta{
+			DBTypeS:      r.Cfg.DBTypeS,
+			DBTypeT:      r.Cfg.DBTypeT,
+			SchemaNameS:  r.Cfg.MySQLConfig.SchemaName,
+			TableNameS:   sourceTable,
+			SchemaNameT:  targetSchema,
+			TableNameT:   targetTable,
+			ChunkDetailS: chunk,
+			TaskMode:     r.Cfg.TaskMode,
+			TaskStatus:   common.TaskStatusWaiting,
+			InfoDetail:   fmt.Sprintf("mysql table [%s.%s] chunk [%s] full sync oracle table [%s.%s]", r.Cfg.MySQLConfig.SchemaName, sourceTable, chunk, targetSchema, targetTable),
+		})
+	}
+	if err = meta.NewFullSyncMetaModel(r.MetaDB).BatchCreateFullSyncMeta(r.Ctx, chunkMetas, r.Cfg.AppConfig.InsertBatchSize); err != nil {
+		return err
+	}
+	return meta.NewWaitSyncMetaModel(r.MetaDB).CreateWaitSyncMeta(r.Ctx, &meta.WaitSyncMeta{
+		DBTypeS:        r.Cfg.DBTypeS,
+		DBTypeT:        r.Cfg.DBTypeT,
+		SchemaNameS:    r.Cfg.MySQLConfig.SchemaName,
+		TableNameS:     sourceTable,
+		TaskMode:       r.Cfg.TaskMode,
+		TaskStatus:     common.TaskStatusRunning,
+		ChunkTotalNums: int64(len(chunks)),
+	})
+}
+
+// syncTableChunk 单 chunk 同步，非 WAITING 状态 chunk 可能已部分写入，重跑前按 chunk 范围清理目标表
+func (r *Migrate) syncTableChunk(chunk meta.FullSyncMeta, columns []Column, columnNames []string, insertSQL, pkColumn string) error {
+	if chunk.TaskStatus != common.TaskStatusWaiting {
+		if err := r.cleanTableChunk(chunk, pkColumn); err != nil {
+			return err
+		}
+	}
+	if err := meta.NewFullSyncMetaModel(r.MetaDB).UpdateFullSyncMeta(r.Ctx, &chunk, map[string]interface{}{
+		"TaskStatus": common.TaskStatusRunning,
+	}); err != nil {
+		return err
+	}
+
+	querySQL := fmt.Sprintf("SELECT %s FROM `%s`.`%s` WHERE %s",
+		strings.Join(columnNames, ","), r.Cfg.MySQLConfig.SchemaName, chunk.TableNameS, chunk.ChunkDetailS)
+	if err := r.Mysql.QueryMySQLTableRowsByBatch(querySQL, r.Cfg.AppConfig.InsertBatchSize, func(rows [][][]byte) error {
+		return r.Oracle.WriteOracleTableByArrayBind(insertSQL, GenOracleArrayBindArgs(columns, rows))
+	}); err != nil {
+		return err
+	}
+
+	return meta.NewFullSyncMetaModel(r.MetaDB).UpdateFullSyncMeta(r.Ctx, &chunk, map[string]interface{}{
+		"TaskStatus":  common.TaskStatusSuccess,
+		"ErrorDetail": "",
+	})
+}
+
+// cleanTableChunk 清理 chunk 范围目标表数据，整表单 chunk 直接 truncate
+func (r *Migrate) cleanTableChunk(chunk meta.FullSyncMeta, pkColumn string) error {
+	targetSchema, targetTable := QuoteOracleIdentifier(chunk.SchemaNameT), QuoteOracleIdentifier(chunk.TableNameT)
+	if chunk.ChunkDetailS == "1 = 1" {
+		return r.Oracle.TruncateOracleTable(targetSchema, targetTable)
+	}
+	sourcePK := common.StringsBuilder("`", pkColumn, "`")
+	if pkColumn == "" || !strings.Contains(chunk.ChunkDetailS, sourcePK) {
+		return fmt.Errorf("mysql table [%s.%s] chunk [%s] primary key [%s] mismatch, please clear [wait_sync_meta] and rerunning",
+			chunk.SchemaNameS, chunk.TableNameS, chunk.ChunkDetailS, pkColumn)
+	}
+	whereS := strings.ReplaceAll(chunk.ChunkDetailS, sourcePK, QuoteOracleIdentifier(GenOracleColumnName(pkColumn, r.ColumnRewriter)))
+	deleteRows, err := r.Oracle.DeleteOracleTableByWhere(targetSchema, targetTable, whereS)
+	if err != nil {
+		return err
+	}
+	zap.L().Info("oracle table chunk clean before rerunning",
+		zap.String("schema", chunk.SchemaNameT),
+		zap.String("table", chunk.TableNameT),
+		zap.String("chunk", whereS),
+		zap.Int64("delete rows", deleteRows))
+	return nil
+}
+
+// splitTableChunksByPK 单列整型主键按范围切分 chunk，其他情况整表单 chunk
+func (r *Migrate) splitTableChunksByPK(sourceTable, pkColumn string) ([]string, error) {
+	if pkColumn == "" {
+		return []string{"1 = 1"}, nil
+	}
+
+	tableRows, minS, maxS, err := r.Mysql.GetMySQLTableRowsAndPKRange(r.Cfg.MySQLConfig.SchemaName, sourceTable, pkColumn)
+	if err != nil {
+		return nil, err
+	}
+	if minS == "" || tableRows <= int64(r.Cfg.FullConfig.ChunkSize) {
+		return []string{"1 = 1"}, nil
+	}
+	// 主键边界按十进制大整数计算，兼容 BIGINT UNSIGNED 超出 int64 范围以及有符号全范围跨度溢出
+	minV, ok := new(big.Int).SetString(minS, 10)
+	if !ok {
+		return nil, fmt.Errorf("mysql table [%s] primary key [%s] min value [%s] parse failed", sourceTable, pkColumn, minS)
+	}
+	maxV, ok := new(big.Int).SetString(maxS, 10)
+	if !ok {
+		return nil, fmt.Errorf("mysql table [%s] primary key [%s] max value [%s] parse failed", sourceTable, pkColumn, maxS)
+	}
+
+	chunkNums := big.NewInt(int64(math.Ceil(float64(tableRows) / float64(r.Cfg.FullConfig.ChunkSize))))
+	// step = ceil((maxV - minV + 1) / chunkNums)
+	step := new(big.Int).Sub(maxV, minV)
+	step.Add(step, chunkNums)
+	step.Quo(step, chunkNums)
+	if step.Sign() <= 0 {
+		return []string{"1 = 1"}, nil
+	}
+
+	var chunks []string
+	for start := new(big.Int).Set(minV); start.Cmp(maxV) <= 0; start.Add(start, step) {
+		end := new(big.Int).Add(start, step)
+		if end.Cmp(maxV) > 0 {
+			chunks = append(chunks, fmt.Sprintf("`%s` >= %s AND `%s` <= %s", pkColumn, start.String(), pkColumn, maxV.String()))
+			break
+		}
+		chunks = append(chunks, fmt.Sprintf("`%s` >= %s AND `%s` < %s", pkColumn, start.String(), pkColumn, end.String()))
+	}
+	return chunks, nil
+}
+
+func (r *Migrate) getTableNameRule() (map[string]string, error) {
+	// 获取表名自定义规则以及正则改写规则
+	rewriter, err := r.Cfg.RewriteConfig.TableRewriter()
+	if err != nil {
+		return nil, err
+	}
+	sourceTables, err := r.Mysql.GetMySQLTable(r.Cfg.MySQLConfig.SchemaName)
+	if err != nil {
+		return nil, err
+	}
+	return meta.NewTableNameRuleModel(r.MetaDB).DetailTableNameRuleMap(r.Ctx, &meta.TableNameRule{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: r.Cfg.MySQLConfig.SchemaName,
+		SchemaNameT: r.Cfg.OracleConfig.SchemaName,
+	}, sourceTables, rewriter)
+}
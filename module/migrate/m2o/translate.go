@@ -0,0 +1,141 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package m2o
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"math/big"
+	"strings"
+)
+
+const (
+	columnKindString = iota
+	columnKindDate
+	columnKindTimestamp
+	columnKindBit
+	columnKindBinary
+)
+
+// Column 源端字段以及数据转换类型
+type Column struct {
+	ColumnName string
+	DataType   string
+	Kind       int
+}
+
+func NewColumns(columnINFO []map[string]string) []Column {
+	var columns []Column
+	for _, c := range columnINFO {
+		columns = append(columns, Column{
+			ColumnName: c["COLUMN_NAME"],
+			DataType:   common.StringUPPER(c["DATA_TYPE"]),
+			Kind:       columnKind(c["DATA_TYPE"]),
+		})
+	}
+	return columns
+}
+
+func columnKind(dataType string) int {
+	switch common.StringUPPER(dataType) {
+	case "DATE":
+		return columnKindDate
+	case "DATETIME", "TIMESTAMP":
+		return columnKindTimestamp
+	case "BIT":
+		return columnKindBit
+	case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB":
+		return columnKindBinary
+	default:
+		// ENUM/SET/TIME/YEAR 以及数值、字符类型统一字符串写入，由 Oracle 隐式转换
+		return columnKindString
+	}
+}
+
+// GenOracleInsertSQL 生成数组绑定写入语句，日期时间字段按格式转换
+// 目标端字段名按字段改写规则改写并双引号包裹，避免 LEVEL/COMMENT/SIZE/DATE 等保留字报错
+func GenOracleInsertSQL(schemaName, tableName string, columns []Column, columnRewriter *common.NameRewriter) string {
+	var (
+		columnNames []string
+		bindNames   []string
+	)
+	for i, c := range columns {
+		columnNames = append(columnNames, QuoteOracleIdentifier(GenOracleColumnName(c.ColumnName, columnRewriter)))
+		switch c.Kind {
+		case columnKindDate:
+			bindNames = append(bindNames, fmt.Sprintf("TO_DATE(:%d,'YYYY-MM-DD')", i+1))
+		case columnKindTimestamp:
+			bindNames = append(bindNames, fmt.Sprintf("TO_TIMESTAMP(:%d,'YYYY-MM-DD HH24:MI:SS.FF')", i+1))
+		default:
+			bindNames = append(bindNames, fmt.Sprintf(":%d", i+1))
+		}
+	}
+	return fmt.Sprintf("INSERT INTO %s.%s (%s) VALUES (%s)",
+		QuoteOracleIdentifier(schemaName), QuoteOracleIdentifier(tableName), strings.Join(columnNames, ","), strings.Join(bindNames, ","))
+}
+
+// GenOracleColumnName 源端字段名转换成目标端字段名，统一大写后按字段改写规则改写
+func GenOracleColumnName(columnName string, columnRewriter *common.NameRewriter) string {
+	newName, _, _ := columnRewriter.Rewrite(common.StringUPPER(columnName))
+	return newName
+}
+
+// QuoteOracleIdentifier Oracle 标识符双引号包裹
+func QuoteOracleIdentifier(name string) string {
+	return common.StringsBuilder(`"`, name, `"`)
+}
+
+// GenOracleArrayBindArgs 行数据按字段转置成数组绑定参数
+// Oracle 空字符串等同 NULL，字符类字段 NULL 以空字符串写入，二进制字段 NULL 以 nil 写入
+func GenOracleArrayBindArgs(columns []Column, rows [][][]byte) []interface{} {
+	var args []interface{}
+	for i, c := range columns {
+		switch c.Kind {
+		case columnKindBinary:
+			values := make([][]byte, 0, len(rows))
+			for _, r := range rows {
+				values = append(values, r[i])
+			}
+			args = append(args, values)
+		default:
+			values := make([]string, 0, len(rows))
+			for _, r := range rows {
+				values = append(values, convertColumnValue(c, r[i]))
+			}
+			args = append(args, values)
+		}
+	}
+	return args
+}
+
+func convertColumnValue(c Column, value []byte) string {
+	if value == nil {
+		return ""
+	}
+	switch c.Kind {
+	case columnKindDate, columnKindTimestamp:
+		// MySQL 零值日期 Oracle 不支持，转换成 NULL
+		if strings.HasPrefix(string(value), "0000-00-00") {
+			return ""
+		}
+		return string(value)
+	case columnKindBit:
+		// BIT 以大端字节返回，转换成十进制数值
+		return new(big.Int).SetBytes(value).String()
+	default:
+		return string(value)
+	}
+}
@@ -0,0 +1,230 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2d
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/dm"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/database/oracle"
+	"github.com/wentaojin/transferdb/module/migrate"
+	"github.com/wentaojin/transferdb/module/migrate/o2m"
+	"github.com/wentaojin/transferdb/module/reverse/o2d"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"sync/atomic"
+	"time"
+)
+
+type Migrate struct {
+	Ctx    context.Context
+	Cfg    *config.Config
+	Oracle *oracle.Oracle
+	DM     *dm.DM
+	MetaDB *meta.Meta
+	Source migrate.Source
+}
+
+func NewFuller(ctx context.Context, cfg *config.Config) (*Migrate, error) {
+	oracleDB, err := oracle.NewOracleDBEngine(ctx, cfg.OracleConfig)
+	if err != nil {
+		return nil, err
+	}
+	dmDB, err := dm.NewDMDBEngine(ctx, cfg.DMConfig)
+	if err != nil {
+		return nil, err
+	}
+	metaDB, err := meta.NewMetaDBEngine(ctx, cfg.MySQLConfig, cfg.AppConfig.SlowlogThreshold)
+	if err != nil {
+		return nil, err
+	}
+	source, err := o2m.NewOracleSource(cfg, oracleDB, metaDB, func(ctx context.Context) ([]string, error) {
+		return o2d.FilterCFGTable(ctx, cfg, oracleDB, metaDB)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Migrate{
+		Ctx:    ctx,
+		Cfg:    cfg,
+		Oracle: oracleDB,
+		DM:     dmDB,
+		MetaDB: metaDB,
+		Source: source,
+	}, nil
+}
+
+func (r *Migrate) Full() error {
+	startTime := time.Now()
+	zap.L().Info("source schema full table data sync start",
+		zap.String("schema", r.Cfg.OracleConfig.SchemaName))
+
+	tables, err := r.Source.ListTables(r.Ctx)
+	if err != nil {
+		return err
+	}
+	tableNameRule, err := o2d.GetTableNameRule(r.Ctx, r.Cfg, r.Oracle, r.MetaDB)
+	if err != nil {
+		return err
+	}
+	columnRewriter, err := r.Cfg.RewriteConfig.ColumnRewriter()
+	if err != nil {
+		return err
+	}
+
+	oracleDBVersion, err := r.Oracle.GetOracleDBVersion()
+	if err != nil {
+		return err
+	}
+	oracleCollation := false
+	if common.VersionOrdinal(oracleDBVersion) >= common.VersionOrdinal(common.OracleTableColumnCollationDBVersion) {
+		oracleCollation = true
+	}
+
+	// 全局 SCN，开启 consistent-read 时全部 chunk 基于该 SCN 闪回读取
+	globalSCN, err := r.Source.Snapshot(r.Ctx)
+	if err != nil {
+		return err
+	}
+
+	var failedTables int64
+	g := &errgroup.Group{}
+	g.SetLimit(r.Cfg.FullConfig.TableThreads)
+
+	for _, table := range tables {
+		sourceTable := table
+		g.Go(func() error {
+			targetTable := sourceTable
+			if val, ok := tableNameRule[sourceTable]; ok {
+				targetTable = val
+			}
+			if err := r.syncTable(sourceTable, targetTable, oracleCollation, globalSCN, columnRewriter); err != nil {
+				atomic.AddInt64(&failedTables, 1)
+				if errL := meta.NewErrorLogDetailModel(r.MetaDB).CreateErrorLog(r.Ctx, &meta.ErrorLogDetail{
+					DBTypeS:     r.Cfg.DBTypeS,
+					DBTypeT:     r.Cfg.DBTypeT,
+					SchemaNameS: common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
+					TableNameS:  sourceTable,
+					SchemaNameT: common.StringUPPER(r.Cfg.DMConfig.SchemaName),
+					TableNameT:  targetTable,
+					TaskMode:    r.Cfg.TaskMode,
+					TaskStatus:  common.TaskStatusFailed,
+					InfoDetail:  fmt.Sprintf("oracle table [%s.%s] full sync dm table [%s.%s]", r.Cfg.OracleConfig.SchemaName, sourceTable, r.Cfg.DMConfig.SchemaName, targetTable),
+					ErrorDetail: err.Error(),
+				}); errL != nil {
+					return fmt.Errorf("oracle table [%s.%s] full sync failed: %v, record error log failed: %v", r.Cfg.OracleConfig.SchemaName, sourceTable, err, errL)
+				}
+				zap.L().Warn("oracle table full sync dm failed, detail see [error_log_detail]",
+					zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+					zap.String("table", sourceTable),
+					zap.Error(err))
+			}
+			return nil
+		})
+	}
+	if err = g.Wait(); err != nil {
+		return err
+	}
+
+	if failedTables > 0 {
+		zap.L().Warn("source schema full table data finished",
+			zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+			zap.Int("table totals", len(tables)),
+			zap.Int64("table failed", failedTables),
+			zap.String("detail", "see [error_log_detail] and rerunning"),
+			zap.String("cost", time.Now().Sub(startTime).String()))
+		return nil
+	}
+	zap.L().Info("source schema full table data finished",
+		zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+		zap.Int("table totals", len(tables)),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
+
+func (r *Migrate) syncTable(sourceTable, targetTable string, oracleCollation bool, globalSCN uint64, columnRewriter *common.NameRewriter) error {
+	startTime := time.Now()
+	sourceSchema := common.StringUPPER(r.Cfg.OracleConfig.SchemaName)
+	targetSchema := common.StringUPPER(r.Cfg.DMConfig.SchemaName)
+
+	columnINFO, err := r.Oracle.GetOracleSchemaTableColumn(sourceSchema, sourceTable, oracleCollation)
+	if err != nil {
+		return err
+	}
+	columns := NewColumns(columnINFO, columnRewriter)
+	columnDetailS := GenDMColumnDetailS(columns)
+	insertSQL := GenDMInsertSQL(targetSchema, targetTable, columns)
+
+	chunks, err := r.Source.SplitChunks(r.Ctx, sourceTable)
+	if err != nil {
+		return err
+	}
+
+	if err = r.DM.TruncateDMTable(targetSchema, targetTable); err != nil {
+		return err
+	}
+
+	snapshotGroup := ""
+	if r.Cfg.FullConfig.ConsistentRead {
+		snapshotGroup = common.MigrateSnapshotGroupGlobal
+	}
+
+	g := &errgroup.Group{}
+	g.SetLimit(r.Cfg.FullConfig.SQLThreads)
+	for _, chunk := range chunks {
+		m := meta.FullSyncMeta{
+			DBTypeS:       r.Cfg.DBTypeS,
+			DBTypeT:       r.Cfg.DBTypeT,
+			SchemaNameS:   sourceSchema,
+			TableNameS:    sourceTable,
+			SchemaNameT:   targetSchema,
+			TableNameT:    targetTable,
+			GlobalScnS:    globalSCN,
+			ColumnDetailS: columnDetailS,
+			ChunkDetailS:  chunk,
+			TaskMode:      r.Cfg.TaskMode,
+			SnapshotGroup: snapshotGroup,
+		}
+		// 运行窗口外暂停派发 chunk
+		if err = common.WaitRunWindow(r.Ctx); err != nil {
+			return err
+		}
+		g.Go(func() error {
+			// 达梦按 INSERT 写入，会话被 kill 时仅重试尚未写入任何行的 chunk，避免重复数据
+			c := NewChunk(r.Ctx, m, r.DM, columns, insertSQL)
+			return r.Oracle.RetryOnSessionKilled(func(attempt int) error {
+				if attempt > 0 && c.ApplyRows() > 0 {
+					return fmt.Errorf("oracle table [%s.%s] chunk [%s] applied rows [%d] before session killed, skip retry", sourceSchema, sourceTable, m.ChunkDetailS, c.ApplyRows())
+				}
+				return o2m.IPipeline(r.Ctx, r.Source.ReadChunk(r.Ctx, m),
+					c, c, r.Cfg.FullConfig.ApplyThreads)
+			})
+		})
+	}
+	if err = g.Wait(); err != nil {
+		return err
+	}
+
+	zap.L().Info("oracle table full sync dm finished",
+		zap.String("schema", sourceSchema),
+		zap.String("table", sourceTable),
+		zap.Int("chunks", len(chunks)),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
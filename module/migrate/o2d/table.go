@@ -0,0 +1,165 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2d
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/dm"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/module/migrate"
+	"github.com/wentaojin/transferdb/module/reverse/o2d"
+	"go.uber.org/zap"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Column 字段抽取表达式以及写入绑定变量表达式
+// 时间类型抽取端 TO_CHAR 固定格式，写入端对应 TO_DATE/TO_TIMESTAMP 还原，规避会话 NLS 格式差异
+type Column struct {
+	ColumnNameS string
+	ColumnNameT string
+	SelectExpr  string
+	BindExpr    string
+	IsBinary    bool
+}
+
+func NewColumns(columnINFO []map[string]string, columnRewriter *common.NameRewriter) []Column {
+	var columns []Column
+	for _, c := range columnINFO {
+		col := Column{
+			ColumnNameS: c["COLUMN_NAME"],
+			ColumnNameT: c["COLUMN_NAME"],
+			SelectExpr:  common.StringsBuilder(`"`, c["COLUMN_NAME"], `"`),
+			BindExpr:    "?",
+			IsBinary:    o2d.IsOracleBinaryDatatype(c["DATA_TYPE"]),
+		}
+		if targetColumn, _, ok := columnRewriter.Rewrite(c["COLUMN_NAME"]); ok {
+			col.ColumnNameT = targetColumn
+		}
+
+		dataType := common.StringUPPER(c["DATA_TYPE"])
+		switch {
+		case dataType == "DATE":
+			col.SelectExpr = fmt.Sprintf(`TO_CHAR("%s",'YYYY-MM-DD HH24:MI:SS') AS "%s"`, c["COLUMN_NAME"], c["COLUMN_NAME"])
+			col.BindExpr = `TO_DATE(?,'YYYY-MM-DD HH24:MI:SS')`
+		case strings.HasPrefix(dataType, "TIMESTAMP") && strings.Contains(dataType, "TIME ZONE"):
+			col.SelectExpr = fmt.Sprintf(`TO_CHAR("%s",'YYYY-MM-DD HH24:MI:SS.FF6 TZH:TZM') AS "%s"`, c["COLUMN_NAME"], c["COLUMN_NAME"])
+			col.BindExpr = `TO_TIMESTAMP_TZ(?,'YYYY-MM-DD HH24:MI:SS.FF6 TZH:TZM')`
+		case strings.HasPrefix(dataType, "TIMESTAMP"):
+			col.SelectExpr = fmt.Sprintf(`TO_CHAR("%s",'YYYY-MM-DD HH24:MI:SS.FF6') AS "%s"`, c["COLUMN_NAME"], c["COLUMN_NAME"])
+			col.BindExpr = `TO_TIMESTAMP(?,'YYYY-MM-DD HH24:MI:SS.FF6')`
+		case strings.HasPrefix(dataType, "INTERVAL") || dataType == "ROWID" || dataType == "UROWID":
+			col.SelectExpr = fmt.Sprintf(`TO_CHAR("%s") AS "%s"`, c["COLUMN_NAME"], c["COLUMN_NAME"])
+		case dataType == "XMLTYPE":
+			col.SelectExpr = fmt.Sprintf(`XMLSERIALIZE(CONTENT "%s" AS CLOB) AS "%s"`, c["COLUMN_NAME"], c["COLUMN_NAME"])
+		}
+		columns = append(columns, col)
+	}
+	return columns
+}
+
+func GenDMColumnDetailS(columns []Column) string {
+	var exprs []string
+	for _, c := range columns {
+		exprs = append(exprs, c.SelectExpr)
+	}
+	return strings.Join(exprs, ",")
+}
+
+func GenDMInsertSQL(targetSchema, targetTable string, columns []Column) string {
+	var (
+		cols  []string
+		binds []string
+	)
+	for _, c := range columns {
+		cols = append(cols, common.StringsBuilder(`"`, c.ColumnNameT, `"`))
+		binds = append(binds, c.BindExpr)
+	}
+	return fmt.Sprintf(`INSERT INTO "%s"."%s" (%s) VALUES (%s)`, targetSchema, targetTable, strings.Join(cols, ","), strings.Join(binds, ","))
+}
+
+type Chunk struct {
+	Ctx       context.Context
+	SyncMeta  meta.FullSyncMeta
+	DM        *dm.DM
+	Columns   []Column
+	InsertSQL string
+	applyRows int64
+}
+
+func NewChunk(ctx context.Context, syncMeta meta.FullSyncMeta, dm *dm.DM, columns []Column, insertSQL string) *Chunk {
+	return &Chunk{
+		Ctx:       ctx,
+		SyncMeta:  syncMeta,
+		DM:        dm,
+		Columns:   columns,
+		InsertSQL: insertSQL,
+	}
+}
+
+// ApplyRows chunk 已写入行数
+func (t *Chunk) ApplyRows() int64 {
+	return atomic.LoadInt64(&t.applyRows)
+}
+
+// TranslateTableRows 非二进制字段 []byte 转换为 string 绑定，避免达梦按二进制写入字符字段
+func (t *Chunk) TranslateTableRows(ctx context.Context, batchC <-chan migrate.Batch, applyC chan<- migrate.Batch) error {
+	for b := range batchC {
+		if len(b.Columns) != len(t.Columns) {
+			return fmt.Errorf("oracle table [%s.%s] extract columns [%d] isn't equal dm columns [%d]", t.SyncMeta.SchemaNameS, t.SyncMeta.TableNameS, len(b.Columns), len(t.Columns))
+		}
+		for _, row := range b.Rows {
+			for i, v := range row {
+				if val, ok := v.([]byte); ok && !t.Columns[i].IsBinary {
+					row[i] = string(val)
+				}
+			}
+		}
+		select {
+		case applyC <- b:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (t *Chunk) ApplyTableRows(ctx context.Context, applyC <-chan migrate.Batch) error {
+	startTime := time.Now()
+	var applyRows int
+	for b := range applyC {
+		rows := make([][]interface{}, 0, len(b.Rows))
+		for _, r := range b.Rows {
+			rows = append(rows, r)
+		}
+		if err := t.DM.WriteDMTable(t.InsertSQL, rows); err != nil {
+			return err
+		}
+		applyRows += len(rows)
+		atomic.AddInt64(&t.applyRows, int64(len(rows)))
+	}
+
+	zap.L().Info("dm table chunk data applier finished",
+		zap.String("schema", t.SyncMeta.SchemaNameT),
+		zap.String("table", t.SyncMeta.TableNameT),
+		zap.String("chunk", t.SyncMeta.ChunkDetailS),
+		zap.Int("rows", applyRows),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
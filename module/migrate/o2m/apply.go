@@ -24,9 +24,11 @@ import (
 	"github.com/wentaojin/transferdb/config"
 	"github.com/wentaojin/transferdb/database/meta"
 	"github.com/wentaojin/transferdb/database/mysql"
+	"github.com/wentaojin/transferdb/metrics"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 	"sync"
+	"time"
 )
 
 type IncrTask struct {
@@ -119,6 +121,7 @@ func applyOracleIncrRecord(metaDB *meta.Meta, mysqlDB *mysql.MySQL, cfg *config.
 func (p *IncrTask) IncrApply() error {
 	// 数据写入并更新元数据表
 	//zap.L().Info("increment applier sql", zap.String("sql", sql))
+	startTime := time.Now()
 	if p.OperationType == common.MigrateOperationUpdate {
 		// update 语句拆分 delete/replace 放一个事务内
 		txn, err := p.MySQL.MySQLDB.BeginTx(p.Ctx, &sql.TxOptions{})
@@ -134,13 +137,22 @@ func (p *IncrTask) IncrApply() error {
 			return fmt.Errorf("single increment table [%s] data oracle redo [%v] insert mysql [%v] transaction commit falied: %v", p.SourceTable, p.OracleRedo, p.MySQLRedo, err)
 		}
 	} else {
+		// TRUNCATE/DROP/ADD COLUMN/CREATE INDEX 使用 DDL 用户执行
+		db := p.MySQL.MySQLDB
+		if p.OperationType == common.MigrateOperationTruncateTable || p.OperationType == common.MigrateOperationDropTable || p.OperationType == common.MigrateOperationDDL {
+			db = p.MySQL.DDLDB
+		}
 		for _, s := range p.MySQLRedo {
-			_, err := p.MySQL.MySQLDB.ExecContext(p.Ctx, s)
+			_, err := db.ExecContext(p.Ctx, s)
 			if err != nil {
 				return fmt.Errorf("single increment table [%s] data oracle redo [%v] insert mysql [%v] exec falied: %v", p.SourceTable, p.OracleRedo, p.MySQLRedo, err)
 			}
 		}
 	}
+	taskMode := common.StringUPPER(p.TaskMode)
+	metrics.RowsCounter.WithLabelValues(taskMode, p.SourceSchema, p.SourceTable).Add(float64(len(p.MySQLRedo)))
+	metrics.ApplyHistogram.WithLabelValues(taskMode, p.SourceTable).Observe(time.Since(startTime).Seconds())
+
 	// 数据写入完毕，更新元数据 checkpoint 表
 	// 如果同步中断，数据同步使用会以 global_scn_s 为准，也就是会进行重复消费
 	if p.Operation == common.MigrateOperationDropTable {
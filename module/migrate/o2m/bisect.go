@@ -0,0 +1,107 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+type bisectRow struct {
+	Row   string
+	Error string
+}
+
+// applyBatchBisect 失败 batch 二分重试
+// 同一事务内每次写入前设置 savepoint，失败回滚至 savepoint 后对半拆分，直至单行，问题行记录 error_log_detail
+func (t *Chunk) applyBatchBisect(prefixSQL, suffixSQL string, rows []common.RowValue) error {
+	txn, err := t.MySQL.MySQLDB.BeginTx(t.Ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("bisect table [%s.%s] transaction start failed: %v", t.SyncMeta.SchemaNameT, t.SyncMeta.TableNameT, err)
+	}
+
+	var (
+		spSeq      int
+		failedRows []bisectRow
+	)
+	if err = t.bisect(txn, prefixSQL, suffixSQL, rows, &spSeq, &failedRows); err != nil {
+		if errR := txn.Rollback(); errR != nil {
+			zap.L().Warn("bisect transaction rollback failed", zap.Error(errR))
+		}
+		return err
+	}
+	if err = txn.Commit(); err != nil {
+		return fmt.Errorf("bisect table [%s.%s] transaction commit failed: %v", t.SyncMeta.SchemaNameT, t.SyncMeta.TableNameT, err)
+	}
+
+	if len(failedRows) == 0 {
+		return nil
+	}
+	for _, r := range failedRows {
+		if err = meta.NewErrorLogDetailModel(t.MetaDB).CreateErrorLog(t.Ctx, &meta.ErrorLogDetail{
+			DBTypeS:     t.SyncMeta.DBTypeS,
+			DBTypeT:     t.SyncMeta.DBTypeT,
+			SchemaNameS: t.SyncMeta.SchemaNameS,
+			TableNameS:  t.SyncMeta.TableNameS,
+			SchemaNameT: t.SyncMeta.SchemaNameT,
+			TableNameT:  t.SyncMeta.TableNameT,
+			TaskMode:    t.SyncMeta.TaskMode,
+			TaskStatus:  common.TaskStatusFailed,
+			InfoDetail:  common.StringsBuilder(prefixSQL, r.Row, suffixSQL),
+			ErrorDetail: r.Error,
+		}); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("bisect table [%s.%s] chunk [%s] isolated [%d] failed rows, other rows written, please see meta table [error_log_detail]",
+		t.SyncMeta.SchemaNameT, t.SyncMeta.TableNameT, t.SyncMeta.ChunkDetailS, len(failedRows))
+}
+
+func (t *Chunk) bisect(txn *sql.Tx, prefixSQL, suffixSQL string, rows []common.RowValue, spSeq *int, failedRows *[]bisectRow) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	*spSeq++
+	savepoint := common.StringsBuilder("SP_BISECT_", strconv.Itoa(*spSeq))
+	if _, err := txn.ExecContext(t.Ctx, common.StringsBuilder("SAVEPOINT ", savepoint)); err != nil {
+		return fmt.Errorf("bisect savepoint [%s] failed: %v", savepoint, err)
+	}
+
+	_, errW := txn.ExecContext(t.Ctx, common.StringsBuilder(prefixSQL, common.RenderMySQLRows(rows), suffixSQL))
+	if errW == nil {
+		if _, err := txn.ExecContext(t.Ctx, common.StringsBuilder("RELEASE SAVEPOINT ", savepoint)); err != nil {
+			return fmt.Errorf("bisect release savepoint [%s] failed: %v", savepoint, err)
+		}
+		return nil
+	}
+	if _, err := txn.ExecContext(t.Ctx, common.StringsBuilder("ROLLBACK TO SAVEPOINT ", savepoint)); err != nil {
+		return fmt.Errorf("bisect rollback to savepoint [%s] failed: %v, write error: %v", savepoint, err, errW)
+	}
+
+	if len(rows) == 1 {
+		*failedRows = append(*failedRows, bisectRow{Row: common.RenderMySQLRow(rows[0]), Error: errW.Error()})
+		return nil
+	}
+	mid := len(rows) / 2
+	if err := t.bisect(txn, prefixSQL, suffixSQL, rows[:mid], spSeq, failedRows); err != nil {
+		return err
+	}
+	return t.bisect(txn, prefixSQL, suffixSQL, rows[mid:], spSeq, failedRows)
+}
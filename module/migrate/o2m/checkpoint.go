@@ -0,0 +1,92 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	"sync"
+)
+
+// chunkProgress chunk 内 batch 写入进度
+// batch 并发写入完成顺序不定，仅记录自 chunk 起始连续写入完成的 batch 行数，保证断点之前数据均已写入
+type chunkProgress struct {
+	mu        sync.Mutex
+	batchRows []int64
+	done      []bool
+	next      int
+	rows      int64
+}
+
+func newChunkProgress() *chunkProgress {
+	return &chunkProgress{}
+}
+
+// add 按到达顺序登记 batch，返回 batch 序号
+func (p *chunkProgress) add(rows int) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.batchRows = append(p.batchRows, int64(rows))
+	p.done = append(p.done, false)
+	return len(p.done) - 1
+}
+
+// finish 标记 batch 完成，返回连续完成行数以及是否推进
+func (p *chunkProgress) finish(batchIdx int) (int64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done[batchIdx] = true
+	advanced := false
+	for p.next < len(p.done) && p.done[p.next] {
+		p.rows += p.batchRows[p.next]
+		p.next++
+		advanced = true
+	}
+	return p.rows, advanced
+}
+
+// recordProgress 更新 full_sync_meta chunk 已写入行数
+func (t *Chunk) recordProgress(p *chunkProgress, batchIdx int) error {
+	rows, advanced := p.finish(batchIdx)
+	if !advanced {
+		return nil
+	}
+	// 多个 batch 同时推进时，写入顺序可能乱序，仅在更大时更新，避免回退
+	return meta.NewFullSyncMetaModel(t.MetaDB).UpdateFullSyncMetaRowOffset(t.Ctx, &meta.FullSyncMeta{
+		DBTypeS:         t.SyncMeta.DBTypeS,
+		DBTypeT:         t.SyncMeta.DBTypeT,
+		SchemaNameS:     t.SyncMeta.SchemaNameS,
+		TableNameS:      t.SyncMeta.TableNameS,
+		TaskMode:        t.SyncMeta.TaskMode,
+		ChunkDetailS:    t.SyncMeta.ChunkDetailS,
+		ChunkPartitionS: t.SyncMeta.ChunkPartitionS,
+	}, t.SyncMeta.RowOffset+rows)
+}
+
+// skipRows 流式跳过 chunk 起始 offset 行，offset 随跳过行数递减
+func skipRows(rows []common.RowValue, offset *int64) []common.RowValue {
+	if *offset <= 0 {
+		return rows
+	}
+	n := int64(len(rows))
+	if *offset >= n {
+		*offset -= n
+		return nil
+	}
+	rows = rows[*offset:]
+	*offset = 0
+	return rows
+}
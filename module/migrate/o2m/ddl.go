@@ -0,0 +1,241 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	checkO2M "github.com/wentaojin/transferdb/module/check/o2m"
+	"go.uber.org/zap"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ALTER TABLE MARVIN.T1 ADD (C1 NUMBER(10), C2 VARCHAR2(10) DEFAULT 'X')
+	oracleAddColumnRegex = regexp.MustCompile(`^ALTER\s+TABLE\s+(?:(\w+)\.)?(\w+)\s+ADD\s*(.+)$`)
+	// CREATE UNIQUE INDEX MARVIN.IDX_T1 ON MARVIN.T1 (C1, C2 DESC) TABLESPACE USERS
+	oracleCreateIndexRegex = regexp.MustCompile(`^CREATE\s+(UNIQUE\s+)?INDEX\s+(?:(\w+)\.)?(\w+)\s+ON\s+(?:(\w+)\.)?(\w+)\s*\((.+?)\)`)
+	oracleIndexColumnRegex = regexp.MustCompile(`^(\w+)(?:\s+(ASC|DESC))?$`)
+)
+
+// translateOracleIncrDDL 增量 DDL 预处理
+// 1、命中 ddl-skip 正则的 DDL 语句直接跳过，包括 TRUNCATE TABLE/DROP TABLE
+// 2、开启 ddl-replicate 时，ADD COLUMN/CREATE INDEX 按 reverse 字段类型映射规则转换为 MySQL DDL，未支持的 DDL 以及非同步表 DDL 告警忽略
+// 3、tableNameRule 仅包含改名表，未配置规则的同步表目标表名沿用源端表名
+func (r *Migrate) translateOracleIncrDDL(lcs []logminer, syncSourceTables []string, tableNameRule map[string]string) ([]logminer, error) {
+	syncTables := make(map[string]string, len(syncSourceTables))
+	for _, t := range syncSourceTables {
+		targetTable := common.StringUPPER(t)
+		if val, ok := tableNameRule[common.StringUPPER(t)]; ok {
+			targetTable = val
+		}
+		syncTables[common.StringUPPER(t)] = targetTable
+	}
+
+	var skipRegexs []*regexp.Regexp
+	for _, s := range r.Cfg.AllConfig.DDLSkip {
+		re, err := regexp.Compile(`(?i)` + s)
+		if err != nil {
+			return lcs, fmt.Errorf("increment ddl skip pattern [%s] compile failed: %v", s, err)
+		}
+		skipRegexs = append(skipRegexs, re)
+	}
+
+	var newLcs []logminer
+	for _, lc := range lcs {
+		if lc.Operation != common.MigrateOperationDDL {
+			newLcs = append(newLcs, lc)
+			continue
+		}
+
+		ddl := strings.TrimSpace(common.StringUPPER(common.ReplaceQuotesString(lc.SQLRedo)))
+		ddl = strings.TrimSpace(strings.TrimSuffix(ddl, ";"))
+
+		skip := false
+		for _, re := range skipRegexs {
+			if re.MatchString(ddl) {
+				skip = true
+				break
+			}
+		}
+		if skip {
+			zap.L().Warn("increment ddl match ddl-skip, skip",
+				zap.String("schema", lc.SourceSchema),
+				zap.Uint64("scn", lc.SCN),
+				zap.String("ddl", lc.SQLRedo))
+			continue
+		}
+
+		if !r.Cfg.AllConfig.DDLReplicate {
+			newLcs = append(newLcs, lc)
+			continue
+		}
+
+		var (
+			sourceTable string
+			mysqlRedo   []string
+			err         error
+		)
+		switch {
+		case oracleAddColumnRegex.MatchString(ddl):
+			sourceTable, mysqlRedo, err = r.translateOracleAddColumn(ddl, syncTables)
+		case oracleCreateIndexRegex.MatchString(ddl):
+			sourceTable, mysqlRedo, err = r.translateOracleCreateIndex(ddl, syncTables)
+		default:
+			// TRUNCATE TABLE/DROP TABLE 沿用原有处理
+			newLcs = append(newLcs, lc)
+			continue
+		}
+		if err != nil {
+			return lcs, fmt.Errorf("increment ddl scn [%d] sql [%s] translate failed: %v, please checkin or config [all] ddl-skip to skip", lc.SCN, lc.SQLRedo, err)
+		}
+		if len(mysqlRedo) == 0 {
+			zap.L().Warn("increment ddl isn't support replicate, ignore",
+				zap.String("schema", lc.SourceSchema),
+				zap.Uint64("scn", lc.SCN),
+				zap.String("ddl", lc.SQLRedo))
+			continue
+		}
+
+		// CREATE INDEX logminer TABLE_NAME 为索引名，统一改写为所属表
+		lc.SourceTable = sourceTable
+		lc.TargetTable = syncTables[sourceTable]
+		lc.MySQLRedo = mysqlRedo
+		newLcs = append(newLcs, lc)
+	}
+	return newLcs, nil
+}
+
+// translateOracleAddColumn 新增字段以源端数据字典为准，字段类型、默认值按 reverse 映射规则转换
+// syncTables 为同步表源端表名 -> 目标表名映射
+func (r *Migrate) translateOracleAddColumn(ddl string, syncTables map[string]string) (string, []string, error) {
+	matches := oracleAddColumnRegex.FindStringSubmatch(ddl)
+	sourceTable := matches[2]
+	targetTable, ok := syncTables[sourceTable]
+	if !ok {
+		return sourceTable, nil, nil
+	}
+
+	body := strings.TrimSpace(matches[3])
+	if strings.HasPrefix(body, "(") && strings.HasSuffix(body, ")") {
+		body = body[1 : len(body)-1]
+	}
+
+	var columnNames []string
+	for _, def := range splitOracleDDLItems(body) {
+		fields := strings.Fields(def)
+		if len(fields) == 0 {
+			continue
+		}
+		// ADD CONSTRAINT/PRIMARY KEY 等约束不属于新增字段
+		switch fields[0] {
+		case "CONSTRAINT", "PRIMARY", "UNIQUE", "FOREIGN", "CHECK", "SUPPLEMENTAL", "PARTITION":
+			return sourceTable, nil, nil
+		}
+		columnNames = append(columnNames, fields[0])
+	}
+
+	columns, _, err := checkO2M.GetOracleTableColumn(common.StringUPPER(r.Cfg.OracleConfig.SchemaName), sourceTable, r.Oracle, "", "", "", "", false)
+	if err != nil {
+		return sourceTable, nil, err
+	}
+
+	var mysqlRedo []string
+	for _, c := range columnNames {
+		columnINFO, ok := columns[c]
+		if !ok {
+			return sourceTable, nil, fmt.Errorf("oracle table [%s] column [%s] isn't exist in dictionary", sourceTable, c)
+		}
+		targetColumn, _, _ := r.ColumnRewriter.Rewrite(c)
+		columnMeta, err := checkO2M.GenOracleTableColumnMeta(r.Ctx, r.MetaDB, r.Cfg.DBTypeS, r.Cfg.DBTypeT,
+			common.StringUPPER(r.Cfg.OracleConfig.SchemaName), sourceTable, c, columnINFO)
+		if err != nil {
+			return sourceTable, nil, err
+		}
+		// 字段级映射规则按源端字段名匹配，生成后再改写字段名
+		if targetColumn != c {
+			columnMeta = strings.Replace(columnMeta, common.StringsBuilder("`", c, "`"), common.StringsBuilder("`", targetColumn, "`"), 1)
+		}
+		mysqlRedo = append(mysqlRedo, common.StringsBuilder(`ALTER TABLE `, common.StringUPPER(r.Cfg.MySQLConfig.SchemaName), ".", targetTable, ` ADD COLUMN `, columnMeta))
+	}
+	return sourceTable, mysqlRedo, nil
+}
+
+// translateOracleCreateIndex 仅支持普通字段索引，函数索引等表达式索引忽略
+func (r *Migrate) translateOracleCreateIndex(ddl string, syncTables map[string]string) (string, []string, error) {
+	matches := oracleCreateIndexRegex.FindStringSubmatch(ddl)
+	sourceTable := matches[5]
+	targetTable, ok := syncTables[sourceTable]
+	if !ok {
+		return sourceTable, nil, nil
+	}
+
+	var indexColumns []string
+	for _, col := range splitOracleDDLItems(matches[6]) {
+		colMatches := oracleIndexColumnRegex.FindStringSubmatch(strings.TrimSpace(col))
+		if colMatches == nil {
+			return sourceTable, nil, nil
+		}
+		targetColumn, _, _ := r.ColumnRewriter.Rewrite(colMatches[1])
+		if colMatches[2] != "" {
+			indexColumns = append(indexColumns, common.StringsBuilder("`", targetColumn, "` ", colMatches[2]))
+		} else {
+			indexColumns = append(indexColumns, common.StringsBuilder("`", targetColumn, "`"))
+		}
+	}
+
+	indexType := `INDEX`
+	if matches[1] != "" {
+		indexType = `UNIQUE INDEX`
+	}
+	return sourceTable, []string{common.StringsBuilder(`CREATE `, indexType, " `", matches[3], "` ON ",
+		common.StringUPPER(r.Cfg.MySQLConfig.SchemaName), ".", targetTable, " (", strings.Join(indexColumns, ","), ")")}, nil
+}
+
+// splitOracleDDLItems 按最外层逗号切分字段定义，忽略括号以及单引号内逗号，如 NUMBER(10,2)、DEFAULT 'A,B'
+func splitOracleDDLItems(s string) []string {
+	var (
+		items   []string
+		depth   int
+		start   int
+		inQuote bool
+	)
+	for i, c := range s {
+		if c == '\'' {
+			inQuote = !inQuote
+			continue
+		}
+		if inQuote {
+			continue
+		}
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				items = append(items, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		items = append(items, last)
+	}
+	return items
+}
@@ -0,0 +1,99 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// precheckDiskSpace 按上游段大小 × 膨胀系数估算下游所需空间，与下游可用空间对比
+func (r *Migrate) precheckDiskSpace(exporters []string) error {
+	if r.Cfg.FullConfig.DiskPrecheck == common.MigrateDiskPrecheckOff {
+		return nil
+	}
+	startTime := time.Now()
+
+	tables := make(map[string]struct{}, len(exporters))
+	for _, t := range exporters {
+		tables[common.StringUPPER(t)] = struct{}{}
+	}
+
+	segments, err := r.Oracle.GetOracleSchemaTableSegmentBytes(common.StringUPPER(r.Cfg.OracleConfig.SchemaName))
+	if err != nil {
+		return err
+	}
+	var dataBytes, indexBytes float64
+	for _, s := range segments {
+		if _, ok := tables[common.StringUPPER(s["TABLE_NAME"])]; !ok {
+			continue
+		}
+		bytes, err := strconv.ParseFloat(s["BYTES"], 64)
+		if err != nil {
+			return fmt.Errorf("parse oracle table [%s] segment bytes [%s] failed: %v", s["TABLE_NAME"], s["BYTES"], err)
+		}
+		if s["SEGMENT_CATEGORY"] == "INDEX" {
+			indexBytes += bytes
+		} else {
+			dataBytes += bytes
+		}
+	}
+	requireBytes := dataBytes*r.Cfg.FullConfig.DataExpansionFactor + indexBytes*r.Cfg.FullConfig.IndexExpansionFactor
+
+	var availableBytes float64
+	if strings.EqualFold(r.Cfg.MySQLConfig.DBType, common.DatabaseTypeTiDB) {
+		available, replicas, err := r.Mysql.GetTiDBStoreAvailableBytes()
+		if err != nil {
+			return err
+		}
+		availableBytes = available
+		requireBytes = requireBytes * float64(replicas)
+	} else {
+		availableBytes = r.Cfg.FullConfig.DiskAvailableGB * (1 << 30)
+	}
+
+	if availableBytes <= 0 {
+		zap.L().Warn("target disk available space unknown, disk precheck skip",
+			zap.String("db type", r.Cfg.MySQLConfig.DBType),
+			zap.Float64("require gb", requireBytes/(1<<30)))
+		return nil
+	}
+
+	if requireBytes > availableBytes {
+		if r.Cfg.FullConfig.DiskPrecheck == common.MigrateDiskPrecheckError {
+			return fmt.Errorf("target disk space isn't enough, require [%.2f GB] over available [%.2f GB], please expand target storage or adjust [full] disk-precheck",
+				requireBytes/(1<<30), availableBytes/(1<<30))
+		}
+		zap.L().Warn("target disk space isn't enough, continue",
+			zap.Float64("oracle data gb", dataBytes/(1<<30)),
+			zap.Float64("oracle index gb", indexBytes/(1<<30)),
+			zap.Float64("require gb", requireBytes/(1<<30)),
+			zap.Float64("available gb", availableBytes/(1<<30)))
+		return nil
+	}
+
+	zap.L().Info("target disk space precheck finished",
+		zap.Float64("oracle data gb", dataBytes/(1<<30)),
+		zap.Float64("oracle index gb", indexBytes/(1<<30)),
+		zap.Float64("require gb", requireBytes/(1<<30)),
+		zap.Float64("available gb", availableBytes/(1<<30)),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
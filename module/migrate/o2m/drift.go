@@ -0,0 +1,133 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	"go.uber.org/zap"
+	"time"
+)
+
+// currentExtractScn chunk 抽取 SCN，闪回查询固定为快照 SCN，获取失败仅告警不影响抽取
+func (t *Table) currentExtractScn() uint64 {
+	if t.SyncMeta.SnapshotGroup != "" {
+		return t.SyncMeta.GlobalScnS
+	}
+	if t.ChunkCheckpoint && t.SyncMeta.CheckpointScnS > 0 {
+		return t.SyncMeta.CheckpointScnS
+	}
+	scn, err := t.Oracle.GetOracleCurrentSnapshotSCN()
+	if err != nil {
+		zap.L().Warn("get oracle chunk extract scn failed, skip record",
+			zap.String("schema", t.SyncMeta.SchemaNameS),
+			zap.String("table", t.SyncMeta.TableNameS),
+			zap.String("rowid", t.SyncMeta.ChunkDetailS),
+			zap.Error(err))
+		return 0
+	}
+	return scn
+}
+
+// extractUpdates chunk 抽取 SCN 以及时间，随 chunk 成功状态一并写入 full_sync_meta
+func (t *Table) extractUpdates(updates map[string]interface{}) map[string]interface{} {
+	if t == nil || t.ExtractStartScnS == 0 || t.ExtractEndScnS == 0 {
+		return updates
+	}
+	updates["ExtractStartScnS"] = t.ExtractStartScnS
+	updates["ExtractEndScnS"] = t.ExtractEndScnS
+	updates["ExtractStartTime"] = t.ExtractStartTime
+	updates["ExtractEndTime"] = t.ExtractEndTime
+	return updates
+}
+
+// recordExtractScnRange 表同步完成清理 full_sync_meta 前，汇总 chunk 抽取 SCN 范围写入 wait_sync_meta
+func (r *Migrate) recordExtractScnRange(tableName string) error {
+	extractRange, err := meta.NewFullSyncMetaModel(r.MetaDB).DetailFullSyncMetaExtractRange(r.Ctx, &meta.FullSyncMeta{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
+		TableNameS:  common.StringUPPER(tableName),
+		TaskMode:    r.Cfg.TaskMode,
+	})
+	if err != nil {
+		return err
+	}
+	if extractRange.MinScnS == 0 {
+		return nil
+	}
+
+	var cost time.Duration
+	if extractRange.StartTime != nil && extractRange.EndTime != nil {
+		cost = extractRange.EndTime.Sub(*extractRange.StartTime)
+	}
+	zap.L().Info("full table chunk extract scn range",
+		zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+		zap.String("table", common.StringUPPER(tableName)),
+		zap.Uint64("min scn", extractRange.MinScnS),
+		zap.Uint64("max scn", extractRange.MaxScnS),
+		zap.Uint64("scn spread", extractRange.MaxScnS-extractRange.MinScnS),
+		zap.String("extract duration", cost.String()))
+
+	return meta.NewWaitSyncMetaModel(r.MetaDB).UpdateWaitSyncMeta(r.Ctx, &meta.WaitSyncMeta{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
+		TableNameS:  common.StringUPPER(tableName),
+		TaskMode:    r.Cfg.TaskMode,
+	}, map[string]interface{}{
+		"ExtractMinScnS": extractRange.MinScnS,
+		"ExtractMaxScnS": extractRange.MaxScnS,
+	})
+}
+
+// reportExtractScnDrift 非一致性读时，输出 chunk 抽取 SCN 跨度超出 scn-drift-threshold 的表
+// 此类表各 chunk 数据非同一时间点，需增量追平或重新校验
+func (r *Migrate) reportExtractScnDrift(waitSyncMetas []meta.WaitSyncMeta) {
+	if r.Cfg.FullConfig.ConsistentRead {
+		return
+	}
+	threshold := r.Cfg.FullConfig.SCNDriftThreshold
+	if threshold == 0 {
+		threshold = common.MigrateSCNDriftThreshold
+	}
+
+	var driftTables []string
+	for _, w := range waitSyncMetas {
+		if w.ExtractMinScnS == 0 || w.ExtractMaxScnS < w.ExtractMinScnS {
+			continue
+		}
+		spread := w.ExtractMaxScnS - w.ExtractMinScnS
+		if spread <= threshold {
+			continue
+		}
+		driftTables = append(driftTables, w.TableNameS)
+		zap.L().Warn("full table chunk extract scn drift over threshold, table data isn't at the same point in time, need increment catch-up or re-verification",
+			zap.String("schema", w.SchemaNameS),
+			zap.String("table", w.TableNameS),
+			zap.Uint64("min scn", w.ExtractMinScnS),
+			zap.Uint64("max scn", w.ExtractMaxScnS),
+			zap.Uint64("scn spread", spread),
+			zap.Uint64("scn drift threshold", threshold))
+	}
+	if len(driftTables) > 0 {
+		zap.L().Warn("full table chunk extract scn drift report",
+			zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+			zap.Int("drift tables", len(driftTables)),
+			zap.Strings("tables", driftTables),
+			zap.String("suggest", "enable [full] consistent-read or start increment sync from the min scn"))
+	}
+}
@@ -16,16 +16,18 @@ limitations under the License.
 package o2m
 
 import (
+	"context"
 	"fmt"
 	"github.com/wentaojin/transferdb/common"
 	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/meta"
 	"github.com/wentaojin/transferdb/database/oracle"
 	"github.com/wentaojin/transferdb/filter"
 	"go.uber.org/zap"
 	"time"
 )
 
-func filterCFGTable(cfg *config.Config, oracle *oracle.Oracle) ([]string, error) {
+func filterCFGTable(ctx context.Context, cfg *config.Config, oracle *oracle.Oracle, metaDB *meta.Meta) ([]string, error) {
 	startTime := time.Now()
 	var (
 		exporterTableSlice []string
@@ -49,6 +51,29 @@ func filterCFGTable(cfg *config.Config, oracle *oracle.Oracle) ([]string, error)
 		return exporterTableSlice, err
 	}
 
+	// 过滤内置黑名单表（回收站、物化视图日志、IOT 溢出段等），黑名单规则见元数据表 [buildin_table_blacklist]
+	allTables, blacklistTables, err := meta.NewBuildinTableBlacklistModel(metaDB).FilterBuildinTableBlacklist(ctx, cfg.DBTypeS, allTables)
+	if err != nil {
+		return exporterTableSlice, err
+	}
+	if len(blacklistTables) > 0 {
+		zap.L().Warn("filter oracle blacklist tables",
+			zap.String("schema", cfg.OracleConfig.SchemaName),
+			zap.Strings("blacklist tables", blacklistTables))
+	}
+
+	// 临时表数据会话级别，跳过数据迁移
+	temporaryTables, err := oracle.GetOracleSchemaTemporaryTable(common.StringUPPER(cfg.OracleConfig.SchemaName))
+	if err != nil {
+		return exporterTableSlice, err
+	}
+	if len(temporaryTables) > 0 {
+		allTables = common.FilterDifferenceStringItems(allTables, temporaryTables)
+		zap.L().Warn("filter oracle temporary tables, skip data",
+			zap.String("schema", cfg.OracleConfig.SchemaName),
+			zap.Strings("temporary tables", temporaryTables))
+	}
+
 	switch {
 	case len(cfg.OracleConfig.IncludeTable) != 0 && len(cfg.OracleConfig.ExcludeTable) == 0:
 		// 过滤规则加载
@@ -62,6 +87,22 @@ func filterCFGTable(cfg *config.Config, oracle *oracle.Oracle) ([]string, error)
 				exporterTableSlice = append(exporterTableSlice, t)
 			}
 		}
+
+		// EBR 编辑视图命中 include-table 时，解析至基表迁移
+		baseTables, err := oracle.FilterOracleEditioningViewBaseTable(common.StringUPPER(cfg.OracleConfig.SchemaName), f.MatchTable)
+		if err != nil {
+			return exporterTableSlice, err
+		}
+		for _, t := range baseTables {
+			if common.IsContainString(allTables, t) && !common.IsContainString(exporterTableSlice, t) {
+				exporterTableSlice = append(exporterTableSlice, t)
+			}
+		}
+		if len(baseTables) > 0 {
+			zap.L().Warn("resolve oracle editioning views to base tables",
+				zap.String("schema", cfg.OracleConfig.SchemaName),
+				zap.Strings("base tables", baseTables))
+		}
 	case len(cfg.OracleConfig.IncludeTable) == 0 && len(cfg.OracleConfig.ExcludeTable) != 0:
 		// 过滤规则加载
 		f, err := filter.Parse(cfg.OracleConfig.ExcludeTable)
@@ -74,6 +115,17 @@ func filterCFGTable(cfg *config.Config, oracle *oracle.Oracle) ([]string, error)
 				excludeTables = append(excludeTables, t)
 			}
 		}
+
+		// EBR 编辑视图命中 exclude-table 时，一并排除对应基表
+		baseTables, err := oracle.FilterOracleEditioningViewBaseTable(common.StringUPPER(cfg.OracleConfig.SchemaName), f.MatchTable)
+		if err != nil {
+			return exporterTableSlice, err
+		}
+		for _, t := range baseTables {
+			if !common.IsContainString(excludeTables, t) {
+				excludeTables = append(excludeTables, t)
+			}
+		}
 		exporterTableSlice = common.FilterDifferenceStringItems(allTables, excludeTables)
 
 	case len(cfg.OracleConfig.IncludeTable) == 0 && len(cfg.OracleConfig.ExcludeTable) == 0:
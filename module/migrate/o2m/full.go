@@ -23,6 +23,9 @@ import (
 	"github.com/wentaojin/transferdb/database/meta"
 	"github.com/wentaojin/transferdb/database/mysql"
 	"github.com/wentaojin/transferdb/database/oracle"
+	"github.com/wentaojin/transferdb/module/hook"
+	"github.com/wentaojin/transferdb/module/migrate"
+	"github.com/wentaojin/transferdb/module/rollback"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 	"strconv"
@@ -36,6 +39,20 @@ type Migrate struct {
 	Oracle *oracle.Oracle
 	Mysql  *mysql.MySQL
 	MetaDB *meta.Meta
+	// 源端表筛选、chunk 切分以及抽取
+	Source *OracleSource
+	// 字段名正则改写规则，作用于目标端写入字段列表
+	ColumnRewriter *common.NameRewriter
+	// error-policy = continue 时跳过的表
+	skipTables tableErrors
+	// 上次空间检查时间，增量同步单协程运行
+	lastSpaceCheckTime time.Time
+	// 上次物化视图日志消费时间
+	lastMVLogDrainTime time.Time
+	// 上次行数漂移检查时间、抽样轮询位置以及各表最近差异趋势
+	lastDriftCheckTime time.Time
+	driftCheckCursor   int
+	tableRowDrifts     map[string][]int64
 }
 
 func NewFuller(ctx context.Context, cfg *config.Config) (*Migrate, error) {
@@ -51,12 +68,24 @@ func NewFuller(ctx context.Context, cfg *config.Config) (*Migrate, error) {
 	if err != nil {
 		return nil, err
 	}
+	source, err := NewOracleSource(cfg, oracleDB, metaDB, func(ctx context.Context) ([]string, error) {
+		return filterCFGTable(ctx, cfg, oracleDB, metaDB)
+	})
+	if err != nil {
+		return nil, err
+	}
+	columnRewriter, err := cfg.RewriteConfig.ColumnRewriter()
+	if err != nil {
+		return nil, err
+	}
 	return &Migrate{
-		Ctx:    ctx,
-		Cfg:    cfg,
-		Oracle: oracleDB,
-		Mysql:  mysqlDB,
-		MetaDB: metaDB,
+		Ctx:            ctx,
+		Cfg:            cfg,
+		Oracle:         oracleDB,
+		Mysql:          mysqlDB,
+		MetaDB:         metaDB,
+		Source:         source,
+		ColumnRewriter: columnRewriter,
 	}, nil
 }
 
@@ -79,12 +108,32 @@ func (r *Migrate) Full() error {
 		oracleCollation = true
 	}
 
+	// 主从切换重放 batch 依赖写入幂等，连接丢失前 batch 可能已提交，INSERT 重放主键冲突
+	if r.Cfg.FullConfig.ApplyMode == common.MigrateApplyModeInsert && r.Cfg.MySQLConfig.FailoverRetryTimes > 0 {
+		return fmt.Errorf("config [full] apply-mode [%s] isn't support with [mysql] failover-retry-times [%d], replayed batch may be already committed, please use %s/%s/%s or set failover-retry-times = 0",
+			r.Cfg.FullConfig.ApplyMode, r.Cfg.MySQLConfig.FailoverRetryTimes,
+			common.MigrateApplyModeReplace, common.MigrateApplyModeInsertIgnore, common.MigrateApplyModeUpsert)
+	}
+
+	// 云数据库受限环境 chunk 切分方式降级
+	if err = r.adjustChunkSplitMode(); err != nil {
+		return err
+	}
+
 	// 获取配置文件待同步表列表
-	exporters, err := filterCFGTable(r.Cfg, r.Oracle)
+	exporters, err := r.Source.ListTables(r.Ctx)
 	if err != nil {
 		return err
 	}
 
+	// 下游磁盘空间预检查
+	if err = r.precheckDiskSpace(exporters); err != nil {
+		return err
+	}
+
+	// 下游托管实例连接配额预检查
+	r.precheckTargetFlavor()
+
 	// 清理非当前任务 SUCCESS 表元数据记录 wait_sync_meta (用于统计 SUCCESS 准备)
 	// 例如：当前任务表 A/B，之前任务表 A/C (SUCCESS)，清理元数据 C，对于表 A 任务 Skip 忽略处理，除非手工清理表 A
 	tablesByMeta, err := meta.NewWaitSyncMetaModel(r.MetaDB).DetailWaitSyncMetaSuccessTables(r.Ctx, &meta.WaitSyncMeta{
@@ -127,7 +176,7 @@ func (r *Migrate) Full() error {
 		TaskStatus:  common.TaskStatusFailed,
 	})
 	if errTotals > 0 || err != nil {
-		return fmt.Errorf(`csv schema [%s] mode [%s] table task failed: %v, meta table [wait_sync_meta] exist failed error, please firstly check log and deal, secondly clear or update meta table [wait_sync_meta] column [task_status] table status WAITING (Need UPPER), thirdly clear meta table [full_sync_meta] error table record, fively clear target schema error table record, finally rerunning`, strings.ToUpper(r.Cfg.OracleConfig.SchemaName), r.Cfg.TaskMode, err)
+		return fmt.Errorf(`csv schema [%s] mode [%s] table task failed: %v, meta table [wait_sync_meta] exist failed error, please firstly check log and deal, secondly clear or update meta table [wait_sync_meta] column [task_status] table status WAITING (Need UPPER), thirdly clear meta table [full_sync_meta] error table record, fively clear target schema error table record, finally rerunning, or directly rerunning [--mode resume --resume-mode %s] requeue failed chunks`, strings.ToUpper(r.Cfg.OracleConfig.SchemaName), r.Cfg.TaskMode, err, strings.ToLower(r.Cfg.TaskMode))
 	}
 
 	// 判断并记录待同步表列表
@@ -152,6 +201,21 @@ func (r *Migrate) Full() error {
 				TaskStatus:     common.TaskStatusWaiting,
 				GlobalScnS:     common.TaskTableDefaultSourceGlobalSCN,
 				ChunkTotalNums: common.TaskTableDefaultSplitChunkNums,
+				Priority:       r.tablePriority(tableName),
+			})
+			if err != nil {
+				return err
+			}
+		} else if waitSyncMetas[0].Priority != r.tablePriority(tableName) {
+			// 断点续传沿用已有记录，优先级以当前配置为准
+			err = meta.NewWaitSyncMetaModel(r.MetaDB).UpdateWaitSyncMeta(r.Ctx, &meta.WaitSyncMeta{
+				DBTypeS:     r.Cfg.DBTypeS,
+				DBTypeT:     r.Cfg.DBTypeT,
+				SchemaNameS: common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
+				TableNameS:  common.StringUPPER(tableName),
+				TaskMode:    r.Cfg.TaskMode,
+			}, map[string]interface{}{
+				"Priority": r.tablePriority(tableName),
 			})
 			if err != nil {
 				return err
@@ -163,6 +227,38 @@ func (r *Migrate) Full() error {
 	//  - 若想断点恢复，设置 enable-checkpoint true,首次一旦运行则 batch 数不能调整，
 	//  - 若不想断点恢复或者重新调整 batch 数，设置 enable-checkpoint false,清理元数据表 [wait_sync_meta],重新运行全量任务
 	if !r.Cfg.FullConfig.EnableCheckpoint {
+		tableNameRule, err := r.getTableNameRule()
+		if err != nil {
+			return err
+		}
+		// 多表合并为同一目标表时仅清理一次
+		truncatedTables := make(map[string]struct{})
+		// 清理已有表数据，INSERT-IGNORE / UPSERT-ON-DUPLICATE-KEY 冲突可容忍，保留下游已有数据
+		truncateTarget := r.Cfg.FullConfig.ApplyMode != common.MigrateApplyModeInsertIgnore && r.Cfg.FullConfig.ApplyMode != common.MigrateApplyModeUpsert
+
+		// 清理下游表前导出回退快照，不清理下游表的写入模式无需快照
+		// 快照目标表名与清理目标表名一致，按表名规则映射，多表合并仅快照一次
+		if truncateTarget {
+			snapshot := rollback.NewSnapshot(r.Ctx, r.Cfg, r.Mysql, r.MetaDB)
+			snapshotTables := make(map[string]struct{})
+			for _, tableName := range exporters {
+				targetTableName := common.StringUPPER(tableName)
+				if val, ok := tableNameRule[targetTableName]; ok {
+					targetTableName = val
+				}
+				if _, ok := snapshotTables[targetTableName]; ok {
+					continue
+				}
+				if err = snapshot.SnapshotTable(targetTableName); err != nil {
+					return err
+				}
+				snapshotTables[targetTableName] = struct{}{}
+			}
+			if err = snapshot.Prune(); err != nil {
+				return err
+			}
+		}
+
 		err = meta.NewFullSyncMetaModel(r.MetaDB).DeleteFullSyncMetaBySchemaSyncMode(
 			r.Ctx, &meta.FullSyncMeta{
 				DBTypeS:     r.Cfg.DBTypeS,
@@ -184,9 +280,17 @@ func (r *Migrate) Full() error {
 			if err != nil {
 				return err
 			}
-			// 清理已有表数据
-			if err := r.Mysql.TruncateMySQLTable(r.Cfg.MySQLConfig.SchemaName, tableName); err != nil {
-				return err
+			if truncateTarget {
+				targetTableName := common.StringUPPER(tableName)
+				if val, ok := tableNameRule[targetTableName]; ok {
+					targetTableName = val
+				}
+				if _, ok := truncatedTables[targetTableName]; !ok {
+					if err := r.Mysql.TruncateMySQLTable(r.Cfg.MySQLConfig.SchemaName, targetTableName); err != nil {
+						return err
+					}
+					truncatedTables[targetTableName] = struct{}{}
+				}
 			}
 			// 判断并记录待同步表列表
 			waitSyncMetas, err := meta.NewWaitSyncMetaModel(r.MetaDB).DetailWaitSyncMeta(r.Ctx, &meta.WaitSyncMeta{
@@ -208,6 +312,7 @@ func (r *Migrate) Full() error {
 					TaskMode:       r.Cfg.TaskMode,
 					GlobalScnS:     common.TaskTableDefaultSourceGlobalSCN,
 					ChunkTotalNums: common.TaskTableDefaultSplitChunkNums,
+					Priority:       r.tablePriority(tableName),
 				})
 				if err != nil {
 					return err
@@ -235,10 +340,10 @@ func (r *Migrate) Full() error {
 		return err
 	}
 	waitSyncTableMetas = waitSyncDetails
-	if len(waitSyncTableMetas) > 0 {
-		for _, table := range waitSyncTableMetas {
-			waitSyncTables = append(waitSyncTables, common.StringUPPER(table.TableNameS))
-		}
+	// 按表优先级调度，避免大表或核心业务表最后启动拉长整体耗时
+	waitSyncTables, err = r.sortTablesByPriority(waitSyncTableMetas)
+	if err != nil {
+		return err
 	}
 
 	// 判断未同步完成的表列表能否断点续传
@@ -256,10 +361,9 @@ func (r *Migrate) Full() error {
 	if err != nil {
 		return err
 	}
-	if len(partSyncDetails) > 0 {
-		for _, t := range partSyncDetails {
-			partSyncTables = append(partSyncTables, common.StringUPPER(t.TableNameS))
-		}
+	partSyncTables, err = r.sortTablesByPriority(partSyncDetails)
+	if err != nil {
+		return err
 	}
 
 	waitFullChunkTables, err := meta.NewFullSyncMetaModel(r.MetaDB).DistinctFullSyncMetaTableNameSByTaskStatus(r.Ctx, &meta.FullSyncMeta{
@@ -342,14 +446,17 @@ func (r *Migrate) Full() error {
 		return err
 	}
 
+	r.reportExtractScnDrift(succTotals)
+
 	zap.L().Info("all full table data sync finished",
 		zap.String("schema", r.Cfg.OracleConfig.SchemaName),
 		zap.Int("table totals", len(exporters)),
 		zap.Int("table success", len(succTotals)),
 		zap.Int("table failed", len(failedTotals)),
+		zap.Int("table skipped", len(r.skipTables.Tables())),
 		zap.String("log detail", "if exist table failed, please see meta table [wait/full_sync_meta]"),
 		zap.String("cost", time.Now().Sub(startTime).String()))
-	return nil
+	return r.skipTables.Summary(r.Cfg.OracleConfig.SchemaName, r.Cfg.TaskMode)
 }
 
 func (r *Migrate) fullPartSyncTable(fullPartTables []string) error {
@@ -358,10 +465,16 @@ func (r *Migrate) fullPartSyncTable(fullPartTables []string) error {
 	g := &errgroup.Group{}
 	g.SetLimit(r.Cfg.FullConfig.TableThreads)
 
+	// 表级别钩子
+	h := hook.NewHook(r.Ctx, r.Cfg, r.Mysql, r.MetaDB)
+
 	for _, table := range fullPartTables {
 		t := table
-		g.Go(func() error {
+		g.Go(r.tableErrorPolicy(t, func() error {
 			startTime := time.Now()
+			if err := h.RunTableHook(common.TaskHookStageBefore, t); err != nil {
+				return err
+			}
 			err := meta.NewWaitSyncMetaModel(r.MetaDB).UpdateWaitSyncMeta(r.Ctx, &meta.WaitSyncMeta{
 				DBTypeS:     r.Cfg.DBTypeS,
 				DBTypeT:     r.Cfg.DBTypeT,
@@ -375,6 +488,12 @@ func (r *Migrate) fullPartSyncTable(fullPartTables []string) error {
 				return err
 			}
 
+			// LOB 大字段表，抽取以及写入按 lob 批次行数
+			lobBatchSize, err := r.adjustTableLOBBatchSize(t)
+			if err != nil {
+				return err
+			}
+
 			fullMetas, err := meta.NewFullSyncMetaModel(r.MetaDB).DetailFullSyncMeta(r.Ctx, &meta.FullSyncMeta{
 				DBTypeS:     r.Cfg.DBTypeS,
 				DBTypeT:     r.Cfg.DBTypeT,
@@ -391,80 +510,87 @@ func (r *Migrate) fullPartSyncTable(fullPartTables []string) error {
 			g1.SetLimit(r.Cfg.FullConfig.SQLThreads)
 			for _, fullMeta := range fullMetas {
 				m := fullMeta
+				// 运行窗口外暂停派发 chunk，在途 chunk 不受影响
+				if err := common.WaitRunWindow(r.Ctx); err != nil {
+					return err
+				}
 				g1.Go(func() error {
-					// 数据写入
-					columnFields, batchResults, err := IExtractor(
-						NewTable(r.Ctx, m, r.Oracle, r.Cfg.AppConfig.InsertBatchSize))
-					if err != nil {
-						// record error, skip error
-						if errf := meta.NewFullSyncMetaModel(r.MetaDB).UpdateFullSyncMeta(r.Ctx, &meta.FullSyncMeta{
-							DBTypeS:      m.DBTypeS,
-							DBTypeT:      m.DBTypeT,
-							SchemaNameS:  m.SchemaNameS,
-							TableNameS:   m.TableNameS,
-							TaskMode:     m.TaskMode,
-							ChunkDetailS: m.ChunkDetailS,
-						}, map[string]interface{}{
-							"TaskStatus":  common.TaskStatusFailed,
-							"InfoDetail":  m.String(),
-							"ErrorDetail": err.Error(),
-						}); errf != nil {
-							return fmt.Errorf("get oracle schema table [%v] IExtractor failed: %v", m.String(), errf)
+					// 数据写入，抽取、转换、应用流水线，会话被 kill 时重建会话重试 chunk
+					var extractor *Table
+					err := r.Oracle.RetryOnSessionKilled(func(attempt int) error {
+						// 写入端按 row_offset 累加记录断点，需与抽取端使用同一 chunk 断点信息，创建前固定读取 SCN
+						if r.Cfg.FullConfig.ChunkCheckpoint {
+							if attempt > 0 {
+								if err := r.Source.ReloadChunkCheckpoint(r.Ctx, &m); err != nil {
+									return err
+								}
+							}
+							if err := r.Source.PinChunkReadScn(r.Ctx, &m); err != nil {
+								return err
+							}
 						}
-
-						return nil
-					}
-					err = ITranslator(NewChunk(r.Ctx, m, r.Oracle, r.Mysql, r.MetaDB, columnFields, batchResults, r.Cfg.FullConfig.ApplyThreads, r.Cfg.AppConfig.InsertBatchSize, true))
+						chunk := NewChunk(r.Ctx, m, r.Oracle, r.Mysql, r.MetaDB, r.Cfg.FullConfig.ApplyThreads, r.Cfg.AppConfig.InsertBatchSize, r.Cfg.FullConfig.ApplyMode, r.Cfg.FullConfig.ApplyProtocol,
+							r.Cfg.MySQLConfig.FailoverRetryTimes, time.Duration(r.Cfg.MySQLConfig.FailoverRetryInterval)*time.Second, r.Cfg.FullConfig.ApplyBisect, r.Cfg.FullConfig.ChunkCheckpoint, lobBatchSize, r.Cfg.FullConfig.MaxStatementBytes, r.ColumnRewriter)
+						extractor = r.Source.ReadTableChunk(r.Ctx, m, lobBatchSize)
+						return IPipeline(r.Ctx, extractor, chunk, chunk, r.Cfg.FullConfig.ApplyThreads)
+					})
 					if err != nil {
 						// record error, skip error
 						if errf := meta.NewFullSyncMetaModel(r.MetaDB).UpdateFullSyncMeta(r.Ctx, &meta.FullSyncMeta{
-							DBTypeS:      m.DBTypeS,
-							DBTypeT:      m.DBTypeT,
-							SchemaNameS:  m.SchemaNameS,
-							TableNameS:   m.TableNameS,
-							TaskMode:     m.TaskMode,
-							ChunkDetailS: m.ChunkDetailS,
+							DBTypeS:         m.DBTypeS,
+							DBTypeT:         m.DBTypeT,
+							SchemaNameS:     m.SchemaNameS,
+							TableNameS:      m.TableNameS,
+							TaskMode:        m.TaskMode,
+							ChunkDetailS:    m.ChunkDetailS,
+							ChunkPartitionS: m.ChunkPartitionS,
 						}, map[string]interface{}{
 							"TaskStatus":  common.TaskStatusFailed,
 							"InfoDetail":  m.String(),
 							"ErrorDetail": err.Error(),
 						}); errf != nil {
-							return fmt.Errorf("get oracle schema table [%v] ITranslator failed: %v", m.String(), errf)
+							return fmt.Errorf("get oracle schema table [%v] IPipeline failed: %v", m.String(), errf)
 						}
 
 						return nil
 					}
-					err = IApplier(NewChunk(r.Ctx, m, r.Oracle, r.Mysql, r.MetaDB, columnFields, batchResults, r.Cfg.FullConfig.ApplyThreads, r.Cfg.AppConfig.InsertBatchSize, true))
-					if err != nil {
-						// record error, skip error
-						if errf := meta.NewFullSyncMetaModel(r.MetaDB).UpdateFullSyncMeta(r.Ctx, &meta.FullSyncMeta{
-							DBTypeS:      m.DBTypeS,
-							DBTypeT:      m.DBTypeT,
-							SchemaNameS:  m.SchemaNameS,
-							TableNameS:   m.TableNameS,
-							TaskMode:     m.TaskMode,
-							ChunkDetailS: m.ChunkDetailS,
-						}, map[string]interface{}{
-							"TaskStatus":  common.TaskStatusFailed,
-							"InfoDetail":  m.String(),
-							"ErrorDetail": err.Error(),
-						}); errf != nil {
-							return fmt.Errorf("get oracle schema table [%v] IApplier failed: %v", m.String(), errf)
-						}
 
-						return nil
+					// 抽样校验
+					verify := NewVerify(r.Ctx, m, r.Oracle, r.Mysql, r.Cfg.FullConfig.VerifyChunkPercent, r.Cfg.FullConfig.VerifySampleRows, extractor.ExtractStartScnS, r.ColumnRewriter)
+					if verify.IsSampled() {
+						if err = verify.VerifyTableRows(); err != nil {
+							// record error, skip error
+							if errf := meta.NewFullSyncMetaModel(r.MetaDB).UpdateFullSyncMeta(r.Ctx, &meta.FullSyncMeta{
+								DBTypeS:         m.DBTypeS,
+								DBTypeT:         m.DBTypeT,
+								SchemaNameS:     m.SchemaNameS,
+								TableNameS:      m.TableNameS,
+								TaskMode:        m.TaskMode,
+								ChunkDetailS:    m.ChunkDetailS,
+								ChunkPartitionS: m.ChunkPartitionS,
+							}, map[string]interface{}{
+								"TaskStatus":  common.TaskStatusFailed,
+								"InfoDetail":  m.String(),
+								"ErrorDetail": err.Error(),
+							}); errf != nil {
+								return fmt.Errorf("get oracle schema table [%v] Verify failed: %v", m.String(), errf)
+							}
+
+							return nil
+						}
 					}
 
 					if errf := meta.NewFullSyncMetaModel(r.MetaDB).UpdateFullSyncMeta(r.Ctx, &meta.FullSyncMeta{
-						DBTypeS:      m.DBTypeS,
-						DBTypeT:      m.DBTypeT,
-						SchemaNameS:  m.SchemaNameS,
-						TableNameS:   m.TableNameS,
-						TaskMode:     m.TaskMode,
-						ChunkDetailS: m.ChunkDetailS,
-					}, map[string]interface{}{
+						DBTypeS:         m.DBTypeS,
+						DBTypeT:         m.DBTypeT,
+						SchemaNameS:     m.SchemaNameS,
+						TableNameS:      m.TableNameS,
+						TaskMode:        m.TaskMode,
+						ChunkDetailS:    m.ChunkDetailS,
+						ChunkPartitionS: m.ChunkPartitionS,
+					}, extractor.extractUpdates(map[string]interface{}{
 						"TaskStatus": common.TaskStatusSuccess,
-					}); errf != nil {
+					})); errf != nil {
 						return fmt.Errorf("get oracle schema table [%v] Success failed: %v", m.String(), errf)
 					}
 					return nil
@@ -491,6 +617,9 @@ func (r *Migrate) fullPartSyncTable(fullPartTables []string) error {
 
 			// 不存在错误，清理 full_sync_meta 记录, 更新 wait_sync_meta 记录
 			if totalErrs == 0 {
+				if err = r.recordExtractScnRange(t); err != nil {
+					return err
+				}
 				err = meta.NewCommonModel(r.MetaDB).DeleteTableFullSyncMetaAndUpdateWaitSyncMeta(r.Ctx,
 					&meta.FullSyncMeta{
 						DBTypeS:     r.Cfg.DBTypeS,
@@ -511,6 +640,9 @@ func (r *Migrate) fullPartSyncTable(fullPartTables []string) error {
 				if err != nil {
 					return err
 				}
+				if err = h.RunTableHook(common.TaskHookStageAfter, t); err != nil {
+					return err
+				}
 				zap.L().Info("full single table oracle to mysql finished",
 					zap.String("schema", r.Cfg.OracleConfig.SchemaName),
 					zap.String("table", common.StringUPPER(t)),
@@ -539,7 +671,7 @@ func (r *Migrate) fullPartSyncTable(fullPartTables []string) error {
 					zap.String("cost", time.Now().Sub(startTime).String()))
 			}
 			return nil
-		})
+		}))
 	}
 
 	if err := g.Wait(); err != nil {
@@ -558,7 +690,8 @@ func (r *Migrate) fullWaitSyncTable(fullWaitTables []string, oracleCollation boo
 	if err != nil {
 		return err
 	}
-	err = r.fullPartSyncTable(fullWaitTables)
+	// 初始化失败跳过的表不存在 chunk 记录，不再同步
+	err = r.fullPartSyncTable(r.skipTables.Filter(fullWaitTables))
 	if err != nil {
 		return err
 	}
@@ -579,19 +712,38 @@ func (r *Migrate) initWaitSyncTableRowID(csvWaitTables []string, oracleCollation
 	if err != nil {
 		return err
 	}
+	if err = r.Oracle.WaitStandbyApplySCN(globalSCN); err != nil {
+		return err
+	}
+	// 一致性快照组，同组表统一 SCN
+	snapshotGroups, err := migrate.GenSnapshotGroupSCN(r.Cfg, r.Oracle, csvWaitTables)
+	if err != nil {
+		return err
+	}
+	if r.Cfg.FullConfig.ConsistentRead {
+		snapshotGroups = migrate.GenGlobalSnapshotGroup(snapshotGroups, csvWaitTables, globalSCN)
+	}
 	partitionTables, err := r.Oracle.GetOracleSchemaPartitionTable(r.Cfg.OracleConfig.SchemaName)
 	if err != nil {
 		return err
 	}
 
+	sampler := migrate.NewSampler(r.Cfg.SampleConfig, r.Oracle, r.Cfg.OracleConfig.SchemaName)
+
 	g := &errgroup.Group{}
 	g.SetLimit(r.Cfg.FullConfig.TaskThreads)
 
 	for idx, table := range csvWaitTables {
 		t := table
 		workerID := idx
-		g.Go(func() error {
+		g.Go(r.tableErrorPolicy(t, func() error {
 			startTime := time.Now()
+			tableSCN := globalSCN
+			snapshotGroup, isSnapshot := snapshotGroups[common.StringUPPER(t)]
+			if isSnapshot {
+				tableSCN = snapshotGroup.SCN
+			}
+
 			// 库名、表名规则
 			var targetTableName string
 			if val, ok := tableNameRule[common.StringUPPER(t)]; ok {
@@ -600,6 +752,12 @@ func (r *Migrate) initWaitSyncTableRowID(csvWaitTables []string, oracleCollation
 				targetTableName = common.StringUPPER(t)
 			}
 
+			// 开发环境抽样导出
+			sampleWhere, err := sampler.SampleWhere(t)
+			if err != nil {
+				return err
+			}
+
 			sourceColumnInfo, err := r.adjustTableSelectColumn(t, oracleCollation)
 			if err != nil {
 				return err
@@ -634,9 +792,10 @@ func (r *Migrate) initWaitSyncTableRowID(csvWaitTables []string, oracleCollation
 					TableNameS:    common.StringUPPER(t),
 					SchemaNameT:   common.StringUPPER(r.Cfg.MySQLConfig.SchemaName),
 					TableNameT:    common.StringUPPER(targetTableName),
-					GlobalScnS:    globalSCN,
+					GlobalScnS:    tableSCN,
+					SnapshotGroup: snapshotGroup.Group,
 					ColumnDetailS: sourceColumnInfo,
-					ChunkDetailS:  "1 = 1",
+					ChunkDetailS:  migrate.GenSampleChunkWhere("1 = 1", sampleWhere),
 					TaskMode:      r.Cfg.TaskMode,
 					TaskStatus:    common.TaskStatusWaiting,
 					IsPartition:   isPartition,
@@ -646,7 +805,7 @@ func (r *Migrate) initWaitSyncTableRowID(csvWaitTables []string, oracleCollation
 					SchemaNameS:      common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
 					TableNameS:       common.StringUPPER(t),
 					TaskMode:         r.Cfg.TaskMode,
-					GlobalScnS:       globalSCN,
+					GlobalScnS:       tableSCN,
 					ChunkTotalNums:   1,
 					ChunkSuccessNums: 0,
 					ChunkFailedNums:  0,
@@ -663,17 +822,21 @@ func (r *Migrate) initWaitSyncTableRowID(csvWaitTables []string, oracleCollation
 				zap.String("table", common.StringUPPER(t)),
 				zap.Int("rows", tableRowsByStatistics))
 
-			taskName := common.StringsBuilder(common.StringUPPER(r.Cfg.OracleConfig.SchemaName), `_`, common.StringUPPER(t), `_`, `TASK`, strconv.Itoa(workerID))
-
-			if err = r.Oracle.StartOracleChunkCreateTask(taskName); err != nil {
+			avgRowBytes, chunkRows, err := r.calibrateTableChunkRows(t)
+			if err != nil {
 				return err
 			}
 
-			if err = r.Oracle.StartOracleCreateChunkByRowID(taskName, common.StringUPPER(r.Cfg.OracleConfig.SchemaName), common.StringUPPER(t), strconv.Itoa(r.Cfg.CSVConfig.Rows)); err != nil {
-				return err
+			var chunkRes []map[string]string
+			switch {
+			case strings.EqualFold(r.Cfg.FullConfig.ChunkSplitMode, common.MigrateChunkSplitModePK):
+				chunkRes, err = r.splitTableChunksByPK(t, tableRowsByStatistics, chunkRows, snapshotGroup.Group, tableSCN)
+			case strings.EqualFold(r.Cfg.FullConfig.ChunkSplitMode, common.MigrateChunkSplitModePartition) && isPartition == "YES":
+				chunkRes, err = r.splitTableChunksByPartition(t)
+			default:
+				taskName := common.StringsBuilder(common.StringUPPER(r.Cfg.OracleConfig.SchemaName), `_`, common.StringUPPER(t), `_`, `TASK`, strconv.Itoa(workerID))
+				chunkRes, err = r.splitTableChunksByRowID(taskName, t, chunkRows)
 			}
-
-			chunkRes, err := r.Oracle.GetOracleTableChunksByRowID(taskName)
 			if err != nil {
 				return err
 			}
@@ -694,9 +857,10 @@ func (r *Migrate) initWaitSyncTableRowID(csvWaitTables []string, oracleCollation
 					TableNameS:    common.StringUPPER(t),
 					SchemaNameT:   common.StringUPPER(r.Cfg.MySQLConfig.SchemaName),
 					TableNameT:    common.StringUPPER(targetTableName),
-					GlobalScnS:    globalSCN,
+					GlobalScnS:    tableSCN,
+					SnapshotGroup: snapshotGroup.Group,
 					ColumnDetailS: sourceColumnInfo,
-					ChunkDetailS:  "1 = 1",
+					ChunkDetailS:  migrate.GenSampleChunkWhere("1 = 1", sampleWhere),
 					TaskMode:      r.Cfg.TaskMode,
 					TaskStatus:    common.TaskStatusWaiting,
 					IsPartition:   isPartition,
@@ -706,7 +870,7 @@ func (r *Migrate) initWaitSyncTableRowID(csvWaitTables []string, oracleCollation
 					SchemaNameS:      common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
 					TableNameS:       common.StringUPPER(t),
 					TaskMode:         r.Cfg.TaskMode,
-					GlobalScnS:       globalSCN,
+					GlobalScnS:       tableSCN,
 					ChunkTotalNums:   1,
 					ChunkSuccessNums: 0,
 					ChunkFailedNums:  0,
@@ -722,18 +886,20 @@ func (r *Migrate) initWaitSyncTableRowID(csvWaitTables []string, oracleCollation
 			var fullMetas []meta.FullSyncMeta
 			for _, res := range chunkRes {
 				fullMetas = append(fullMetas, meta.FullSyncMeta{
-					DBTypeS:       r.Cfg.DBTypeS,
-					DBTypeT:       r.Cfg.DBTypeT,
-					SchemaNameS:   common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
-					TableNameS:    common.StringUPPER(t),
-					SchemaNameT:   common.StringUPPER(r.Cfg.MySQLConfig.SchemaName),
-					TableNameT:    common.StringUPPER(targetTableName),
-					GlobalScnS:    globalSCN,
-					ColumnDetailS: sourceColumnInfo,
-					ChunkDetailS:  res["CMD"],
-					TaskMode:      r.Cfg.TaskMode,
-					TaskStatus:    common.TaskStatusWaiting,
-					IsPartition:   isPartition,
+					DBTypeS:         r.Cfg.DBTypeS,
+					DBTypeT:         r.Cfg.DBTypeT,
+					SchemaNameS:     common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
+					TableNameS:      common.StringUPPER(t),
+					SchemaNameT:     common.StringUPPER(r.Cfg.MySQLConfig.SchemaName),
+					TableNameT:      common.StringUPPER(targetTableName),
+					GlobalScnS:      tableSCN,
+					SnapshotGroup:   snapshotGroup.Group,
+					ColumnDetailS:   sourceColumnInfo,
+					ChunkDetailS:    migrate.GenSampleChunkWhere(res["CMD"], sampleWhere),
+					ChunkPartitionS: res["PARTITION"],
+					TaskMode:        r.Cfg.TaskMode,
+					TaskStatus:      common.TaskStatusWaiting,
+					IsPartition:     isPartition,
 				})
 			}
 
@@ -751,18 +917,21 @@ func (r *Migrate) initWaitSyncTableRowID(csvWaitTables []string, oracleCollation
 				TableNameS:  common.StringUPPER(t),
 				TaskMode:    r.Cfg.TaskMode,
 			}, map[string]interface{}{
-				"GlobalScnS":       globalSCN,
+				"GlobalScnS":       tableSCN,
 				"ChunkTotalNums":   len(chunkRes),
 				"ChunkSuccessNums": 0,
 				"ChunkFailedNums":  0,
 				"IsPartition":      isPartition,
+				"AvgRowBytes":      avgRowBytes,
+				"ChunkRows":        chunkRows,
 			})
 			if err != nil {
 				return err
 			}
 
-			if err = r.Oracle.CloseOracleChunkTask(taskName); err != nil {
-				return err
+			// 下游 TiDB 写入前预切分 region
+			if r.Cfg.FullConfig.TiDBPreSplit && strings.EqualFold(r.Cfg.MySQLConfig.DBType, common.DatabaseTypeTiDB) {
+				r.preSplitTiDBTable(t, targetTableName, tableRowsByStatistics)
 			}
 
 			endTime := time.Now()
@@ -771,7 +940,7 @@ func (r *Migrate) initWaitSyncTableRowID(csvWaitTables []string, oracleCollation
 				zap.String("table", t),
 				zap.String("cost", endTime.Sub(startTime).String()))
 			return nil
-		})
+		}))
 	}
 
 	if err = g.Wait(); err != nil {
@@ -784,25 +953,168 @@ func (r *Migrate) initWaitSyncTableRowID(csvWaitTables []string, oracleCollation
 	return nil
 }
 
-func (r *Migrate) getTableNameRule() (map[string]string, error) {
-	// 获取表名自定义规则
-	tableNameRules, err := meta.NewTableNameRuleModel(r.MetaDB).DetailTableNameRule(r.Ctx, &meta.TableNameRule{
-		DBTypeS:     r.Cfg.DBTypeS,
-		DBTypeT:     r.Cfg.DBTypeT,
-		SchemaNameS: r.Cfg.OracleConfig.SchemaName,
-		SchemaNameT: r.Cfg.MySQLConfig.SchemaName,
-	})
+// adjustChunkSplitMode ROWID 切分依赖 DBMS_PARALLEL_EXECUTE，部分 Autonomous Database 服务等级不可用时降级为 PK 切分
+func (r *Migrate) adjustChunkSplitMode() error {
+	if !strings.EqualFold(r.Cfg.FullConfig.ChunkSplitMode, common.MigrateChunkSplitModeRowID) {
+		return nil
+	}
+	available, err := r.Oracle.IsOracleParallelExecuteAvailable()
+	if err != nil {
+		return err
+	}
+	if available {
+		return nil
+	}
+	zap.L().Warn("oracle dbms_parallel_execute isn't available, chunk split mode fallback to pk",
+		zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+		zap.String("cloud service", r.Oracle.GetOracleCloudService()),
+		zap.String("chunk split mode", common.MigrateChunkSplitModePK))
+	r.Cfg.FullConfig.ChunkSplitMode = common.MigrateChunkSplitModePK
+	return nil
+}
+
+// precheckTargetFlavor RDS/Aurora 托管实例连接配额受限，表并发 × 写入并发超出连接池上限时写入线程排队等待连接
+func (r *Migrate) precheckTargetFlavor() {
+	if r.Mysql.Flavor == "" || r.Mysql.Flavor == common.MySQLFlavorCommunity {
+		return
+	}
+	maxConns := r.Mysql.MySQLDB.Stats().MaxOpenConnections
+	requireConns := r.Cfg.FullConfig.TableThreads * r.Cfg.FullConfig.ApplyThreads
+	if maxConns > 0 && requireConns > maxConns {
+		zap.L().Warn("target managed instance connection quota isn't enough, apply threads will wait for connection, please decrease [full] table-threads or apply-threads",
+			zap.String("flavor", r.Mysql.Flavor),
+			zap.Int("max open conns", maxConns),
+			zap.Int("table-threads", r.Cfg.FullConfig.TableThreads),
+			zap.Int("apply-threads", r.Cfg.FullConfig.ApplyThreads))
+	}
+}
+
+// splitTableChunksByRowID DBMS_PARALLEL_EXECUTE 按 ROWID 切分，需要 CREATE JOB 权限，出错时同样删除切分任务
+func (r *Migrate) splitTableChunksByRowID(taskName, sourceTable string, chunkRows int) ([]map[string]string, error) {
+	return splitOracleTableChunksByRowID(r.Oracle, taskName, r.Cfg.OracleConfig.SchemaName, sourceTable, chunkRows)
+}
+
+// splitTableChunksByPK 单列 NUMBER 主键 NTILE 区间切分，表无满足条件主键则整表单 chunk
+func (r *Migrate) splitTableChunksByPK(sourceTable string, statisticsRows, chunkRows int, snapshotGroup string, tableSCN uint64) ([]map[string]string, error) {
+	schemaName := common.StringUPPER(r.Cfg.OracleConfig.SchemaName)
+	pkColumn, err := r.Oracle.GetOracleTableNumberPKColumn(schemaName, common.StringUPPER(sourceTable))
 	if err != nil {
 		return nil, err
 	}
-	tableNameRuleMap := make(map[string]string)
+	if pkColumn == "" {
+		zap.L().Warn("oracle table number primary key isn't exist, chunk split skip",
+			zap.String("schema", schemaName),
+			zap.String("table", sourceTable),
+			zap.String("where", "1 = 1"))
+		return []map[string]string{{"CMD": "1 = 1"}}, nil
+	}
+
+	chunkNums := 1
+	if chunkRows > 0 && statisticsRows > chunkRows {
+		chunkNums = (statisticsRows + chunkRows - 1) / chunkRows
+	}
+	return r.Oracle.GetOracleTableChunksByNTILE(
+		migrate.GenSnapshotTableFrom(schemaName, common.StringUPPER(sourceTable), "", snapshotGroup, tableSCN), pkColumn, chunkNums)
+}
 
-	if len(tableNameRules) > 0 {
-		for _, tr := range tableNameRules {
-			tableNameRuleMap[common.StringUPPER(tr.TableNameS)] = common.StringUPPER(tr.TableNameT)
+// preSplitTiDBTable 按上游单列 NUMBER 主键 MIN/MAX 以及统计信息行数预切分下游 region，失败仅告警
+func (r *Migrate) preSplitTiDBTable(sourceTable, targetTable string, statisticsRows int) {
+	schemaNameS := common.StringUPPER(r.Cfg.OracleConfig.SchemaName)
+	schemaNameT := common.StringUPPER(r.Cfg.MySQLConfig.SchemaName)
+	regionRows := r.Cfg.FullConfig.PreSplitRegionRows
+	if regionRows <= 0 {
+		regionRows = common.MigrateTiDBPreSplitRegionRows
+	}
+	regions := (statisticsRows + regionRows - 1) / regionRows
+	if regions > common.MigrateTiDBPreSplitMaxRegions {
+		regions = common.MigrateTiDBPreSplitMaxRegions
+	}
+	if regions < 2 {
+		return
+	}
+
+	err := func() error {
+		pkColumn, err := r.Oracle.GetOracleTableNumberPKColumn(schemaNameS, common.StringUPPER(sourceTable))
+		if err != nil {
+			return err
+		}
+		if pkColumn == "" {
+			zap.L().Warn("oracle table number primary key isn't exist, tidb pre split skip",
+				zap.String("schema", schemaNameS),
+				zap.String("table", sourceTable))
+			return nil
+		}
+		minValue, maxValue, err := r.Oracle.GetOracleTableColumnMinMax(schemaNameS, common.StringUPPER(sourceTable), pkColumn)
+		if err != nil {
+			return err
+		}
+		if minValue == "" || minValue == "NULLABLE" || maxValue == "" || maxValue == "NULLABLE" || minValue == maxValue {
+			return nil
+		}
+
+		// 聚簇主键即表数据 region 键，非聚簇主键切分主键索引
+		pkType, err := r.Mysql.GetTiDBTablePKType(schemaNameT, targetTable)
+		if err != nil {
+			return err
+		}
+		var indexName string
+		if pkType != "CLUSTERED" {
+			indexName = "PRIMARY"
+		}
+		splitRegions, err := r.Mysql.SplitTiDBTableRegion(schemaNameT, targetTable, indexName, minValue, maxValue, regions)
+		if err != nil {
+			return err
 		}
+		zap.L().Info("tidb table pre split region",
+			zap.String("schema", schemaNameT),
+			zap.String("table", targetTable),
+			zap.String("pk type", pkType),
+			zap.String("lower", minValue),
+			zap.String("upper", maxValue),
+			zap.Int("regions", regions),
+			zap.String("split regions", splitRegions))
+		return nil
+	}()
+	if err != nil {
+		zap.L().Warn("tidb table pre split region failed, skip",
+			zap.String("schema", schemaNameT),
+			zap.String("table", targetTable),
+			zap.Error(err))
 	}
-	return tableNameRuleMap, nil
+}
+
+// splitTableChunksByPartition 分区表按分区（存在子分区则按子分区）切分，每个分区单 chunk，利用分区裁剪以及分区级别重试
+func (r *Migrate) splitTableChunksByPartition(sourceTable string) ([]map[string]string, error) {
+	partitions, err := r.Oracle.GetOracleTablePartitionClause(common.StringUPPER(r.Cfg.OracleConfig.SchemaName), common.StringUPPER(sourceTable))
+	if err != nil {
+		return nil, err
+	}
+	var chunkRes []map[string]string
+	for _, p := range partitions {
+		chunkRes = append(chunkRes, map[string]string{
+			"CMD":       "1 = 1",
+			"PARTITION": p,
+		})
+	}
+	return chunkRes, nil
+}
+
+func (r *Migrate) getTableNameRule() (map[string]string, error) {
+	// 获取表名自定义规则以及正则改写规则
+	rewriter, err := r.Cfg.RewriteConfig.TableRewriter()
+	if err != nil {
+		return nil, err
+	}
+	sourceTables, err := r.Oracle.GetOracleSchemaTable(common.StringUPPER(r.Cfg.OracleConfig.SchemaName))
+	if err != nil {
+		return nil, err
+	}
+	return meta.NewTableNameRuleModel(r.MetaDB).DetailTableNameRuleMap(r.Ctx, &meta.TableNameRule{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: r.Cfg.OracleConfig.SchemaName,
+		SchemaNameT: r.Cfg.MySQLConfig.SchemaName,
+	}, sourceTables, rewriter)
 }
 
 func (r *Migrate) adjustTableSelectColumn(sourceTable string, oracleCollation bool) (string, error) {
@@ -861,3 +1173,28 @@ func (r *Migrate) adjustTableSelectColumn(sourceTable string, oracleCollation bo
 
 	return strings.Join(columnNames, ","), nil
 }
+
+// calibrateTableChunkRows 按目标 chunk 字节数校准每 chunk 行数，未开启则返回固定 rows
+func (r *Migrate) calibrateTableChunkRows(sourceTable string) (int, int, error) {
+	if r.Cfg.FullConfig.ChunkBytes <= 0 {
+		return 0, r.Cfg.CSVConfig.Rows, nil
+	}
+	avgRowBytes, err := r.Oracle.GetOracleTableAvgRowBytes(common.StringUPPER(r.Cfg.OracleConfig.SchemaName), common.StringUPPER(sourceTable), common.MigrateChunkCalibrateSampleRows)
+	if err != nil {
+		return 0, 0, err
+	}
+	if avgRowBytes <= 0 {
+		return avgRowBytes, r.Cfg.CSVConfig.Rows, nil
+	}
+	chunkRows := r.Cfg.FullConfig.ChunkBytes / avgRowBytes
+	if chunkRows <= 0 {
+		chunkRows = 1
+	}
+	zap.L().Info("calibrate oracle table chunk rows",
+		zap.String("schema", common.StringUPPER(r.Cfg.OracleConfig.SchemaName)),
+		zap.String("table", common.StringUPPER(sourceTable)),
+		zap.Int("avg row bytes", avgRowBytes),
+		zap.Int("chunk bytes", r.Cfg.FullConfig.ChunkBytes),
+		zap.Int("chunk rows", chunkRows))
+	return avgRowBytes, chunkRows, nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	"go.uber.org/zap"
+)
+
+// recordIncrGTIDPoint 日志文件增量应用完毕后记录一致性位点，小于等于捕获记录最大 SCN 的变更均已应用至下游
+func (r *Migrate) recordIncrGTIDPoint(rowsResult []logminer, logFile string) error {
+	if !r.Cfg.AllConfig.GTIDCheckpoint || len(rowsResult) == 0 {
+		return nil
+	}
+	var appliedSCN uint64
+	for _, lc := range rowsResult {
+		if lc.SCN > appliedSCN {
+			appliedSCN = lc.SCN
+		}
+	}
+
+	binlogFile, binlogPos, gtidSet, err := r.Mysql.GetMySQLBinlogPoint()
+	if err != nil {
+		return err
+	}
+	if binlogFile == "" && gtidSet == "" {
+		zap.L().Warn("target binlog isn't enabled, increment gtid checkpoint skip",
+			zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+			zap.Uint64("applied scn", appliedSCN))
+		return nil
+	}
+
+	if err = meta.NewIncrSyncGTIDModel(r.MetaDB).CreateIncrSyncGTID(r.Ctx, &meta.IncrSyncGTID{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
+		GlobalScnS:  appliedSCN,
+		LogFileS:    logFile,
+		SchemaNameT: common.StringUPPER(r.Cfg.MySQLConfig.SchemaName),
+		BinlogFileT: binlogFile,
+		BinlogPosT:  binlogPos,
+		GTIDSetT:    gtidSet,
+	}); err != nil {
+		return err
+	}
+	zap.L().Info("increment gtid checkpoint record",
+		zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+		zap.Uint64("applied scn", appliedSCN),
+		zap.String("binlog file", binlogFile),
+		zap.Uint64("binlog pos", binlogPos),
+		zap.String("gtid set", gtidSet))
+	return nil
+}
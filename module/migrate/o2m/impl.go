@@ -16,29 +16,41 @@ limitations under the License.
 package o2m
 
 import (
+	"context"
+	"fmt"
 	"github.com/wentaojin/transferdb/module/migrate"
+	"golang.org/x/sync/errgroup"
 )
 
-func IExtractor(e migrate.Extractor) ([]string, []string, error) {
-	columnFields, batchResults, err := e.GetTableRows()
-	if err != nil {
-		return columnFields, batchResults, err
+// IPipeline 抽取、转换、应用三阶段以 channel 串联，chunk 数据按 batch 流转不整体驻留内存
+// 任一阶段失败取消其余阶段，queueSize 为阶段间最多缓冲 batch 数
+func IPipeline(ctx context.Context, e migrate.Extractor, t migrate.Translator, r migrate.Applier, queueSize int) error {
+	if queueSize <= 0 {
+		queueSize = 1
 	}
-	return columnFields, batchResults, nil
-}
+	batchC := make(chan migrate.Batch, queueSize)
+	applyC := make(chan migrate.Batch, queueSize)
 
-func ITranslator(t migrate.Translator) error {
-	err := t.TranslateTableRows()
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func IApplier(r migrate.Applier) error {
-	err := r.ApplyTableRows()
-	if err != nil {
-		return err
-	}
-	return nil
+	g, gCtx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		defer close(batchC)
+		if err := e.StreamTableRows(gCtx, batchC); err != nil {
+			return fmt.Errorf("IExtractor failed: %v", err)
+		}
+		return nil
+	})
+	g.Go(func() error {
+		defer close(applyC)
+		if err := t.TranslateTableRows(gCtx, batchC, applyC); err != nil {
+			return fmt.Errorf("ITranslator failed: %v", err)
+		}
+		return nil
+	})
+	g.Go(func() error {
+		if err := r.ApplyTableRows(gCtx, applyC); err != nil {
+			return fmt.Errorf("IApplier failed: %v", err)
+		}
+		return nil
+	})
+	return g.Wait()
 }
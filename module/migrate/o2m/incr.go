@@ -21,35 +21,16 @@ import (
 	"github.com/wentaojin/transferdb/common"
 	"github.com/wentaojin/transferdb/config"
 	"github.com/wentaojin/transferdb/database/meta"
-	"github.com/wentaojin/transferdb/database/mysql"
-	"github.com/wentaojin/transferdb/database/oracle"
+	"github.com/wentaojin/transferdb/metrics"
 	"go.uber.org/zap"
 	"strconv"
 	"strings"
 	"time"
 )
 
+// NewIncr 增量任务首次运行需执行全量同步，沿用全量任务源端读取、限速以及字段改写配置
 func NewIncr(ctx context.Context, cfg *config.Config) (*Migrate, error) {
-	oracleDB, err := oracle.NewOracleDBEngine(ctx, cfg.OracleConfig)
-	if err != nil {
-		return nil, err
-	}
-	mysqlDB, err := mysql.NewMySQLDBEngine(ctx, cfg.MySQLConfig)
-	if err != nil {
-		return nil, err
-	}
-	metaDB, err := meta.NewMetaDBEngine(ctx, cfg.MySQLConfig, cfg.AppConfig.SlowlogThreshold)
-	if err != nil {
-		return nil, err
-	}
-
-	return &Migrate{
-		Ctx:    ctx,
-		Cfg:    cfg,
-		Oracle: oracleDB,
-		Mysql:  mysqlDB,
-		MetaDB: metaDB,
-	}, nil
+	return NewFuller(ctx, cfg)
 }
 
 func (r *Migrate) Incr() error {
@@ -66,11 +47,30 @@ func (r *Migrate) Incr() error {
 	}
 
 	// 获取配置文件待同步表列表
-	exporters, err := filterCFGTable(r.Cfg, r.Oracle)
+	exporters, err := filterCFGTable(r.Ctx, r.Cfg, r.Oracle, r.MetaDB)
 	if err != nil {
 		return err
 	}
 
+	// 物化视图日志、触发器捕获表，其余表使用 logminer
+	mvlogTables := common.FilterIntersectionStringItems(exporters, r.Cfg.AllConfig.MVLogTables)
+	triggerTables := common.FilterDifferenceStringItems(common.FilterIntersectionStringItems(exporters, r.Cfg.AllConfig.TriggerTables), mvlogTables)
+	captureTables := append(append([]string{}, mvlogTables...), triggerTables...)
+	logminerTables := common.FilterDifferenceStringItems(exporters, captureTables)
+
+	// 指定起始 SCN，跳过全量以及元数据位点判断，直接从该 SCN 开始 logminer 增量
+	if r.Cfg.AllConfig.StartSCN > 0 {
+		if err = r.resetIncrSyncMetaSCN(logminerTables, r.Cfg.AllConfig.StartSCN); err != nil {
+			return err
+		}
+		for range time.Tick(300 * time.Millisecond) {
+			if err = r.syncTableIncr(logminerTables, captureTables); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	// 判断 [wait_sync_meta] 是否存在错误记录，是否可进行 ALL
 	errTotals, err := meta.NewWaitSyncMetaModel(r.MetaDB).CountsErrWaitSyncMetaBySchema(r.Ctx, &meta.WaitSyncMeta{
 		DBTypeS:     r.Cfg.DBTypeS,
@@ -139,9 +139,28 @@ func (r *Migrate) Incr() error {
 			if len(panicTables) != 0 {
 				return fmt.Errorf("table list %s can't incremently sync, because table increment sync meta record is exist and full meta sync isn't finished", panicTables)
 			}
+
+			// 物化视图日志、触发器需在全量前创建，全量完成后再开启的表无法保证数据完整
+			for _, t := range captureTables {
+				counts, err := meta.NewMVLogSyncMetaModel(r.MetaDB).CountsMVLogSyncMetaBySchemaTable(r.Ctx, &meta.MVLogSyncMeta{
+					DBTypeS:     r.Cfg.DBTypeS,
+					DBTypeT:     r.Cfg.DBTypeT,
+					SchemaNameS: common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
+					TableNameS:  t,
+				})
+				if err != nil {
+					return err
+				}
+				if counts != 1 {
+					panicTables = append(panicTables, t)
+				}
+			}
+			if len(panicTables) != 0 {
+				return fmt.Errorf("table list %s can't incremently sync by materialized view log or trigger, because meta table [mv_log_sync_meta] record isn't exist, materialized view log or trigger must be created before full sync, please rerun full + increment", panicTables)
+			}
 			// 增量数据同步
 			for range time.Tick(300 * time.Millisecond) {
-				if err := r.syncTableIncrRecord(); err != nil {
+				if err := r.syncTableIncr(logminerTables, captureTables); err != nil {
 					return err
 				}
 			}
@@ -154,6 +173,18 @@ func (r *Migrate) Incr() error {
 
 	// 如果下游数据库增量元数据表 incr_sync_meta 不存在任何记录，说明未进行过数据同步，则进行全量 + 增量数据同步
 	if len(incrExistTableList) == 0 && len(incrIsNotExistTableList) == len(exporters) {
+		// 物化视图日志、触发器需在全量快照前创建
+		if len(mvlogTables) > 0 {
+			if err = r.initMVLogSyncMeta(mvlogTables); err != nil {
+				return err
+			}
+		}
+		if len(triggerTables) > 0 {
+			if err = r.initTriggerSyncMeta(triggerTables); err != nil {
+				return err
+			}
+		}
+
 		// 全量同步
 		err = r.Full()
 		if err != nil {
@@ -211,7 +242,7 @@ func (r *Migrate) Incr() error {
 
 		// 增量数据同步
 		for range time.Tick(300 * time.Millisecond) {
-			if err = r.syncTableIncrRecord(); err != nil {
+			if err = r.syncTableIncr(logminerTables, captureTables); err != nil {
 				return err
 			}
 		}
@@ -220,7 +251,29 @@ func (r *Migrate) Incr() error {
 	return fmt.Errorf("increment sync taskflow condition isn't match, can't sync")
 }
 
+// syncTableIncr 物化视图日志、触发器捕获表与 logminer 表分别捕获
+func (r *Migrate) syncTableIncr(logminerTables, captureTables []string) error {
+	if len(captureTables) > 0 {
+		if err := r.syncTableMVLogRecord(); err != nil {
+			return err
+		}
+	}
+	if len(logminerTables) > 0 {
+		return r.syncTableIncrRecord()
+	}
+	return nil
+}
+
 func (r *Migrate) syncTableIncrRecord() error {
+	// 上游空间监控
+	if err := r.monitorOracleSpace(); err != nil {
+		return err
+	}
+	// 上下游行数漂移监控
+	if err := r.monitorTableRowDrift(); err != nil {
+		return err
+	}
+
 	// 获取自定义库表名规则
 	tableNameRule, err := r.getTableNameRule()
 	if err != nil {
@@ -274,6 +327,11 @@ func (r *Migrate) syncTableIncrRecord() error {
 		)
 		transferTableMetaMap = make(map[string]uint64)
 		for _, tbl := range incrSyncMetas {
+			// 物化视图日志、触发器捕获表不参与 logminer
+			if common.IsContainString(r.Cfg.AllConfig.MVLogTables, strings.ToUpper(tbl.TableNameS)) ||
+				common.IsContainString(r.Cfg.AllConfig.TriggerTables, strings.ToUpper(tbl.TableNameS)) {
+				continue
+			}
 			transferTableMetaMap[strings.ToUpper(tbl.TableNameS)] = tbl.TableScnS
 			syncSourceTables = append(syncSourceTables, strings.ToUpper(tbl.TableNameS))
 		}
@@ -303,7 +361,13 @@ func (r *Migrate) syncTableIncrRecord() error {
 			common.StringArrayToCapitalChar(syncSourceTables),
 			tableNameRule,
 			strconv.FormatUint(minSourceTableSCN, 10),
-			r.Cfg.AllConfig.LogminerQueryTimeout)
+			r.Cfg.AllConfig.LogminerQueryTimeout,
+			r.Cfg.AllConfig.DDLReplicate)
+		if err != nil {
+			return err
+		}
+		// DDL 跳过以及 ADD COLUMN/CREATE INDEX 转换
+		rowsResult, err = r.translateOracleIncrDDL(rowsResult, syncSourceTables, tableNameRule)
 		if err != nil {
 			return err
 		}
@@ -328,6 +392,9 @@ func (r *Migrate) syncTableIncrRecord() error {
 		if err != nil {
 			return err
 		}
+		if currentRedoLogMaxSCN > minSourceTableSCN {
+			metrics.LogminerLagGauge.WithLabelValues(common.StringUPPER(r.Cfg.OracleConfig.SchemaName)).Set(float64(currentRedoLogMaxSCN - minSourceTableSCN))
+		}
 
 		// 按表级别筛选数据
 		var (
@@ -395,6 +462,9 @@ func (r *Migrate) syncTableIncrRecord() error {
 							return err
 						}
 					}
+					if err = r.recordIncrGTIDPoint(rowsResult, log["LOG_FILE"]); err != nil {
+						return err
+					}
 
 					continue
 				}
@@ -426,6 +496,9 @@ func (r *Migrate) syncTableIncrRecord() error {
 				if err != nil {
 					return err
 				}
+				if err = r.recordIncrGTIDPoint(rowsResult, log["LOG_FILE"]); err != nil {
+					return err
+				}
 				continue
 			}
 			zap.L().Warn("increment table log file logminer data that needn't to be consumed by logfile, transferdb will continue to capture")
@@ -520,3 +593,92 @@ func (r *Migrate) getTableIncrRecordLogfile() ([]map[string]string, error) {
 	}
 	return logFiles, nil
 }
+
+// resetIncrSyncMetaSCN 校验起始 SCN 可被日志覆盖后，重置 logminer 表增量位点，元数据记录缺失则重建
+func (r *Migrate) resetIncrSyncMetaSCN(logminerTables []string, startSCN uint64) error {
+	currentSCN, err := r.Oracle.GetOracleCurrentSnapshotSCN()
+	if err != nil {
+		return err
+	}
+	if startSCN > currentSCN {
+		return fmt.Errorf("increment sync start scn [%d] is greater than oracle current scn [%d]", startSCN, currentSCN)
+	}
+	isCover, err := r.Oracle.IsOracleLogCoverSCN(strconv.FormatUint(startSCN, 10))
+	if err != nil {
+		return err
+	}
+	if !isCover {
+		return fmt.Errorf("increment sync start scn [%d] isn't covered by available archived log or redo log, please restore archived log or choose a later scn", startSCN)
+	}
+
+	tableNameRule, err := r.getTableNameRule()
+	if err != nil {
+		return err
+	}
+
+	schemaName := common.StringUPPER(r.Cfg.OracleConfig.SchemaName)
+	var incrSyncMetas []meta.IncrSyncMeta
+	for _, t := range logminerTables {
+		counts, err := meta.NewIncrSyncMetaModel(r.MetaDB).CountsIncrSyncMetaBySchemaTable(r.Ctx, &meta.IncrSyncMeta{
+			DBTypeS:     r.Cfg.DBTypeS,
+			DBTypeT:     r.Cfg.DBTypeT,
+			SchemaNameS: schemaName,
+			TableNameS:  t,
+		})
+		if err != nil {
+			return err
+		}
+		switch {
+		case counts == 0:
+			targetTableName := common.StringUPPER(t)
+			if val, ok := tableNameRule[common.StringUPPER(t)]; ok {
+				targetTableName = val
+			}
+			isPartTable, err := r.Oracle.IsOraclePartitionTable(schemaName, t)
+			if err != nil {
+				return err
+			}
+			isPartition := "NO"
+			if isPartTable {
+				isPartition = "YES"
+			}
+			incrSyncMetas = append(incrSyncMetas, meta.IncrSyncMeta{
+				DBTypeS:     r.Cfg.DBTypeS,
+				DBTypeT:     r.Cfg.DBTypeT,
+				GlobalScnS:  startSCN,
+				SchemaNameS: schemaName,
+				TableNameS:  common.StringUPPER(t),
+				SchemaNameT: common.StringUPPER(r.Cfg.MySQLConfig.SchemaName),
+				TableNameT:  targetTableName,
+				TableScnS:   startSCN,
+				IsPartition: isPartition,
+			})
+		case counts == 1:
+			if err = meta.NewIncrSyncMetaModel(r.MetaDB).UpdateIncrSyncMeta(r.Ctx, &meta.IncrSyncMeta{
+				DBTypeS:     r.Cfg.DBTypeS,
+				DBTypeT:     r.Cfg.DBTypeT,
+				SchemaNameS: schemaName,
+				TableNameS:  t,
+				GlobalScnS:  startSCN,
+				TableScnS:   startSCN,
+			}); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("meta table [incr_sync_meta] schema [%s] table [%s] record counts [%d] isn't unique, please manually clear", schemaName, t, counts)
+		}
+	}
+	if len(incrSyncMetas) > 0 {
+		if err = meta.NewIncrSyncMetaModel(r.MetaDB).BatchCreateIncrSyncMeta(r.Ctx, incrSyncMetas, r.Cfg.AppConfig.InsertBatchSize); err != nil {
+			return err
+		}
+	}
+	common.MigrateCurrentResetFlag = 0
+
+	zap.L().Warn("increment sync meta scn reset by start scn",
+		zap.String("schema", schemaName),
+		zap.Uint64("start scn", startSCN),
+		zap.Int("create tables", len(incrSyncMetas)),
+		zap.Int("reset tables", len(logminerTables)-len(incrSyncMetas)))
+	return nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"go.uber.org/zap"
+)
+
+// adjustTableLOBBatchSize 判断表是否走 LOB 写入路径
+// LOB 字段抽样最大长度超过 lob-threshold 返回预编译每批行数，否则返回 0 走普通 SQL 字面量拼接写入
+func (r *Migrate) adjustTableLOBBatchSize(sourceTable string) (int, error) {
+	if r.Cfg.FullConfig.LOBThreshold <= 0 {
+		return 0, nil
+	}
+	lobBytes, err := r.Oracle.GetOracleTableLOBMaxBytes(common.StringUPPER(r.Cfg.OracleConfig.SchemaName), common.StringUPPER(sourceTable), common.MigrateChunkCalibrateSampleRows)
+	if err != nil {
+		return 0, err
+	}
+	if lobBytes < r.Cfg.FullConfig.LOBThreshold {
+		return 0, nil
+	}
+
+	lobBatchSize := r.Cfg.FullConfig.LOBBatchSize
+	if lobBatchSize <= 0 {
+		lobBatchSize = common.MigrateLOBBatchSize
+	}
+	zap.L().Warn("oracle table lob column over threshold, apply with lob prepare path",
+		zap.String("schema", common.StringUPPER(r.Cfg.OracleConfig.SchemaName)),
+		zap.String("table", common.StringUPPER(sourceTable)),
+		zap.Int("lob max bytes", lobBytes),
+		zap.Int("lob threshold", r.Cfg.FullConfig.LOBThreshold),
+		zap.Int("lob batch size", lobBatchSize))
+	return lobBatchSize, nil
+}
+
+// applyLOBRows 按 LOBBatchSize 行预编译绑定参数写入，LOB 值以参数传输，避免拼接转义超大 INSERT 字符串
+func (t *Chunk) applyLOBRows(columns []string, rows []common.RowValue) error {
+	for start := 0; start < len(rows); start += t.LOBBatchSize {
+		end := start + t.LOBBatchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		args := make([]interface{}, 0, (end-start)*len(columns))
+		for _, row := range rows[start:end] {
+			args = append(args, row...)
+		}
+		prefixSQL, suffixSQL := GenMySQLApplySQLStmt(t.SyncMeta.SchemaNameT, t.SyncMeta.TableNameT, columns, t.ApplyMode)
+		prepareSQL := common.StringsBuilder(prefixSQL, GenMySQLPrepareBindVarStmt(len(columns), end-start), suffixSQL)
+		if err := t.MySQL.WriteMySQLTableArgsWithFailover(prepareSQL, args, t.RetryTimes, t.RetryInterval); err != nil {
+			return fmt.Errorf("error on write db lob rows [%d-%d], rowid [%s], error: %v", start, end, t.SyncMeta.ChunkDetailS, err)
+		}
+	}
+	return nil
+}
@@ -28,7 +28,7 @@ import (
 )
 
 // 获取 Oracle logminer 日志内容并过滤筛选已提交的 INSERT/DELETE/UPDATE 事务语句
-// 考虑异构数据库，只同步 INSERT/DELETE/UPDATE 事务语句以及 TRUNCATE TABLE/DROP TABLE DDL 语句，其他类型 SQL 不同步
+// 考虑异构数据库，只同步 INSERT/DELETE/UPDATE 事务语句以及 TRUNCATE TABLE/DROP TABLE DDL 语句，开启 ddl-replicate 额外同步 ADD COLUMN/CREATE INDEX，其他类型 SQL 不同步
 // V$LOGMNR_CONTENTS 字段解释参考链接
 // https://docs.oracle.com/en/database/oracle/oracle-database/21/refrn/V-LOGMNR_CONTENTS.html#GUID-B9196942-07BF-4935-B603-FA875064F5C3
 type logminer struct {
@@ -40,12 +40,20 @@ type logminer struct {
 	SQLRedo      string
 	SQLUndo      string
 	Operation    string
+	// DDL 预转换后的 MySQL 语句，非空则不再解析 SQLRedo
+	MySQLRedo []string
 }
 
 // 捕获增量数据
-func getOracleIncrRecord(ctx context.Context, oracle *oracle.Oracle, sourceSchema, targetSchema string, sourceTable string, tableNameRule map[string]string, lastCheckpoint string, queryTimeout int) ([]logminer, error) {
+func getOracleIncrRecord(ctx context.Context, oracle *oracle.Oracle, sourceSchema, targetSchema string, sourceTable string, tableNameRule map[string]string, lastCheckpoint string, queryTimeout int, captureDDL bool) ([]logminer, error) {
 	var lcs []logminer
 
+	// CREATE INDEX 等 DDL TABLE_NAME 非所属表名，开启 DDL 同步时捕获 schema 下全部 DDL，后续按所属表筛选
+	tableFilter := common.StringsBuilder(`UPPER(TABLE_NAME) IN (`, sourceTable, `)`)
+	if captureDDL {
+		tableFilter = common.StringsBuilder(`(`, tableFilter, ` OR OPERATION = 'DDL')`)
+	}
+
 	c, cancel := context.WithTimeout(ctx, time.Duration(queryTimeout)*time.Second)
 	defer cancel()
 
@@ -58,7 +66,7 @@ func getOracleIncrRecord(ctx context.Context, oracle *oracle.Oracle, sourceSchem
   FROM V$LOGMNR_CONTENTS
  WHERE 1 = 1
    AND UPPER(SEG_OWNER) = '`, common.StringUPPER(sourceSchema), `'
-   AND UPPER(TABLE_NAME) IN (`, sourceTable, `)
+   AND `, tableFilter, `
    AND OPERATION IN ('INSERT', 'DELETE', 'UPDATE', 'DDL')
    AND SCN >= `, lastCheckpoint, ` ORDER BY SCN`)
 
@@ -133,7 +141,10 @@ func filterOracleIncrRecord(
 			// 2、根据元数据表 incr_synce_meta 对应表已经同步写入得 SCN SQL 记录,过滤 Oracle 提交记录 SCN 号，过滤,防止重复写入
 			if currentResetFlag == 0 {
 				if rows.SCN >= sourceTableSCNMAP[strings.ToUpper(rows.SourceTable)] {
-					if rows.Operation == common.MigrateOperationDDL {
+					if rows.Operation == common.MigrateOperationDDL && len(rows.MySQLRedo) > 0 {
+						// ADD COLUMN/CREATE INDEX 已预转换
+						s.AddData(rows)
+					} else if rows.Operation == common.MigrateOperationDDL {
 						splitDDL := strings.Split(rows.SQLRedo, ` `)
 						ddl := common.StringsBuilder(splitDDL[0], ` `, splitDDL[1])
 						if strings.ToUpper(ddl) == common.MigrateOperationDropTable {
@@ -153,7 +164,10 @@ func filterOracleIncrRecord(
 
 			} else if currentResetFlag == 1 {
 				if rows.SCN > sourceTableSCNMAP[strings.ToUpper(rows.SQLRedo)] {
-					if rows.Operation == common.MigrateOperationDDL {
+					if rows.Operation == common.MigrateOperationDDL && len(rows.MySQLRedo) > 0 {
+						// ADD COLUMN/CREATE INDEX 已预转换
+						s.AddData(rows)
+					} else if rows.Operation == common.MigrateOperationDDL {
 						splitDDL := strings.Split(rows.SQLRedo, ` `)
 						ddl := common.StringsBuilder(splitDDL[0], ` `, splitDDL[1])
 						if strings.ToUpper(ddl) == common.MigrateOperationDropTable {
@@ -0,0 +1,205 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	"go.uber.org/zap"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// monitorOracleSpace 增量同步期间监控上游快速恢复区以及 ASM 磁盘组空间
+// 超过 space-warn-threshold 告警，超过 space-pause-threshold 暂停日志挖掘直至回落
+func (r *Migrate) monitorOracleSpace() error {
+	if r.Cfg.AllConfig.SpaceCheckInterval <= 0 {
+		return nil
+	}
+	checkInterval := time.Duration(r.Cfg.AllConfig.SpaceCheckInterval) * time.Second
+	if time.Now().Sub(r.lastSpaceCheckTime) < checkInterval {
+		return nil
+	}
+
+	for {
+		r.lastSpaceCheckTime = time.Now()
+
+		usedPercent, err := r.getOracleSpaceMaxUsage()
+		if err != nil {
+			return err
+		}
+		logMB, err := r.Oracle.GetOracleArchivedLogGenerateRate()
+		if err != nil {
+			return err
+		}
+
+		if r.Cfg.AllConfig.SpacePauseThreshold > 0 && usedPercent >= float64(r.Cfg.AllConfig.SpacePauseThreshold) {
+			zap.L().Error("oracle space pressure over pause threshold, increment logminer paused",
+				zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+				zap.Float64("used percent", usedPercent),
+				zap.Int("pause threshold", r.Cfg.AllConfig.SpacePauseThreshold),
+				zap.Float64("archived log mb last hour", logMB),
+				zap.String("tips", "please clean archived log or expand recovery area/asm diskgroup"))
+			select {
+			case <-r.Ctx.Done():
+				return r.Ctx.Err()
+			case <-time.After(checkInterval):
+			}
+			continue
+		}
+
+		if r.Cfg.AllConfig.SpaceWarnThreshold > 0 && usedPercent >= float64(r.Cfg.AllConfig.SpaceWarnThreshold) {
+			zap.L().Warn("oracle space pressure over warn threshold",
+				zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+				zap.Float64("used percent", usedPercent),
+				zap.Int("warn threshold", r.Cfg.AllConfig.SpaceWarnThreshold),
+				zap.Float64("archived log mb last hour", logMB))
+		}
+		return nil
+	}
+}
+
+// getOracleSpaceMaxUsage 获取快速恢复区以及 ASM 磁盘组最大空间使用率
+func (r *Migrate) getOracleSpaceMaxUsage() (float64, error) {
+	usedPercent, err := r.Oracle.GetOracleRecoveryAreaUsage()
+	if err != nil {
+		return usedPercent, err
+	}
+
+	diskgroups, err := r.Oracle.GetOracleASMDiskgroupUsage()
+	if err != nil {
+		return usedPercent, err
+	}
+	for _, dg := range diskgroups {
+		dgUsed, err := strconv.ParseFloat(dg["USED_PERCENT"], 64)
+		if err != nil {
+			return usedPercent, fmt.Errorf("get oracle asm diskgroup [%s] used percent [%s] strconv.ParseFloat failed: %v", dg["NAME"], dg["USED_PERCENT"], err)
+		}
+		if dgUsed > usedPercent {
+			usedPercent = dgUsed
+		}
+	}
+	return usedPercent, nil
+}
+
+// monitorTableRowDrift 增量同步期间抽样对比上下游表行数，早于全量校验发现数据偏离
+// 上游按表已应用 SCN 闪回计数，排除同步延迟带来的差异；差异百分比超过 drift-threshold 告警
+func (r *Migrate) monitorTableRowDrift() error {
+	if r.Cfg.AllConfig.DriftCheckInterval <= 0 {
+		return nil
+	}
+	if time.Now().Sub(r.lastDriftCheckTime) < time.Duration(r.Cfg.AllConfig.DriftCheckInterval)*time.Second {
+		return nil
+	}
+	r.lastDriftCheckTime = time.Now()
+
+	incrSyncMetas, err := meta.NewIncrSyncMetaModel(r.MetaDB).DetailIncrSyncMetaBySchema(r.Ctx, &meta.IncrSyncMeta{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: r.Cfg.OracleConfig.SchemaName,
+	})
+	if err != nil {
+		return err
+	}
+	if len(incrSyncMetas) == 0 {
+		return nil
+	}
+	sort.Slice(incrSyncMetas, func(i, j int) bool {
+		return incrSyncMetas[i].TableNameS < incrSyncMetas[j].TableNameS
+	})
+
+	// 按表名轮询抽样
+	sampleTables := r.Cfg.AllConfig.DriftSampleTables
+	if sampleTables <= 0 || sampleTables > len(incrSyncMetas) {
+		sampleTables = len(incrSyncMetas)
+	}
+	for i := 0; i < sampleTables; i++ {
+		m := incrSyncMetas[(r.driftCheckCursor+i)%len(incrSyncMetas)]
+		r.checkTableRowDrift(m)
+	}
+	r.driftCheckCursor = (r.driftCheckCursor + sampleTables) % len(incrSyncMetas)
+	return nil
+}
+
+// checkTableRowDrift 单表行数对比，计数失败只告警不中断增量同步
+func (r *Migrate) checkTableRowDrift(m meta.IncrSyncMeta) {
+	oraRows, err := r.Oracle.GetOracleTableActualRows(common.StringsBuilder(`SELECT COUNT(1) FROM `,
+		m.SchemaNameS, `.`, m.TableNameS, ` AS OF SCN `, strconv.FormatUint(m.TableScnS, 10)))
+	if err != nil {
+		zap.L().Warn("oracle table row drift flashback count failed, fallback current count",
+			zap.String("schema", m.SchemaNameS),
+			zap.String("table", m.TableNameS),
+			zap.Uint64("table scn", m.TableScnS),
+			zap.Error(err))
+		oraRows, err = r.Oracle.GetOracleTableActualRows(common.StringsBuilder(`SELECT COUNT(1) FROM `, m.SchemaNameS, `.`, m.TableNameS))
+		if err != nil {
+			zap.L().Warn("oracle table row drift count failed, skip", zap.String("table", m.TableNameS), zap.Error(err))
+			return
+		}
+	}
+	mysqlRows, err := r.Mysql.GetMySQLTableActualRows(common.StringsBuilder("SELECT COUNT(1) FROM ", m.SchemaNameT, ".", m.TableNameT))
+	if err != nil {
+		zap.L().Warn("mysql table row drift count failed, skip", zap.String("table", m.TableNameT), zap.Error(err))
+		return
+	}
+
+	drift := mysqlRows - oraRows
+	if r.tableRowDrifts == nil {
+		r.tableRowDrifts = make(map[string][]int64)
+	}
+	trend := append(r.tableRowDrifts[m.TableNameS], drift)
+	if len(trend) > common.MigrateDriftTrendSize {
+		trend = trend[len(trend)-common.MigrateDriftTrendSize:]
+	}
+	r.tableRowDrifts[m.TableNameS] = trend
+
+	driftPercent := math.Abs(float64(drift)) / math.Max(float64(oraRows), 1) * 100
+	if drift != 0 && driftPercent >= r.Cfg.AllConfig.DriftThreshold {
+		zap.L().Warn("table row count drift over threshold",
+			zap.String("schema", m.SchemaNameS),
+			zap.String("table", m.TableNameS),
+			zap.Uint64("table scn", m.TableScnS),
+			zap.Int64("oracle rows", oraRows),
+			zap.Int64("mysql rows", mysqlRows),
+			zap.Float64("drift percent", driftPercent),
+			zap.Int64s("drift trend", trend),
+			zap.Bool("drift growing", isRowDriftGrowing(trend)),
+			zap.String("suggest", "please run compare mode to locate differ rows"))
+		return
+	}
+	zap.L().Info("table row count drift check",
+		zap.String("schema", m.SchemaNameS),
+		zap.String("table", m.TableNameS),
+		zap.Int64("oracle rows", oraRows),
+		zap.Int64("mysql rows", mysqlRows),
+		zap.Int64s("drift trend", trend))
+}
+
+// isRowDriftGrowing 最近差异绝对值持续扩大
+func isRowDriftGrowing(trend []int64) bool {
+	if len(trend) < 2 {
+		return false
+	}
+	for i := 1; i < len(trend); i++ {
+		if math.Abs(float64(trend[i])) <= math.Abs(float64(trend[i-1])) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,307 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// initMVLogSyncMeta 全量开始前创建物化视图日志并初始化位点，保证全量快照之后的变更均已记录
+// 快照之前已记录的变更重复消费按主键回查覆盖，结果幂等
+func (r *Migrate) initMVLogSyncMeta(mvlogTables []string) error {
+	tableNameRule, err := r.getTableNameRule()
+	if err != nil {
+		return err
+	}
+	schemaName := common.StringUPPER(r.Cfg.OracleConfig.SchemaName)
+
+	for _, t := range mvlogTables {
+		pkINFO, err := r.Oracle.GetOracleSchemaTablePrimaryKey(schemaName, t)
+		if err != nil {
+			return err
+		}
+		if len(pkINFO) == 0 {
+			return fmt.Errorf("oracle schema [%s] table [%s] materialized view log capture need primary key, please remove it from config [mvlog-tables]", schemaName, t)
+		}
+
+		logTable, err := r.Oracle.GetOracleTableMViewLog(schemaName, t)
+		if err != nil {
+			return err
+		}
+		reuseLog := logTable != ""
+		// 已存在物化视图日志可能被其他物化视图刷新使用，删除记录会破坏其快速刷新
+		if reuseLog && r.Cfg.AllConfig.MVLogPurge {
+			return fmt.Errorf("oracle schema [%s] table [%s] materialized view log [%s] exist, refuse to reuse it with [all] mvlog-purge = true, please disable mvlog-purge or drop the log", schemaName, t, logTable)
+		}
+		if !reuseLog {
+			if err = r.Oracle.CreateOracleTableMViewLog(schemaName, t); err != nil {
+				return err
+			}
+			logTable, err = r.Oracle.GetOracleTableMViewLog(schemaName, t)
+			if err != nil {
+				return err
+			}
+		} else {
+			zap.L().Warn("oracle table materialized view log exist, reuse it",
+				zap.String("schema", schemaName),
+				zap.String("table", t),
+				zap.String("log table", logTable))
+		}
+
+		targetTableName := common.StringUPPER(t)
+		if val, ok := tableNameRule[common.StringUPPER(t)]; ok {
+			targetTableName = val
+		}
+
+		if err = meta.NewMVLogSyncMetaModel(r.MetaDB).CreateMVLogSyncMeta(r.Ctx, &meta.MVLogSyncMeta{
+			DBTypeS:     r.Cfg.DBTypeS,
+			DBTypeT:     r.Cfg.DBTypeT,
+			SchemaNameS: schemaName,
+			TableNameS:  common.StringUPPER(t),
+			SchemaNameT: common.StringUPPER(r.Cfg.MySQLConfig.SchemaName),
+			TableNameT:  targetTableName,
+			CaptureMode: common.MigrateCaptureModeMVLog,
+			LogTableS:   logTable,
+			ReuseLogS:   reuseLog,
+			PrimaryKeyS: pkINFO[0]["COLUMN_LIST"],
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncTableMVLogRecord 按 mvlog-interval 周期消费物化视图日志以及触发器影子表
+func (r *Migrate) syncTableMVLogRecord() error {
+	interval := r.Cfg.AllConfig.MVLogInterval
+	if interval <= 0 {
+		interval = common.MigrateMVLogInterval
+	}
+	if time.Now().Sub(r.lastMVLogDrainTime) < time.Duration(interval)*time.Second {
+		return nil
+	}
+	r.lastMVLogDrainTime = time.Now()
+
+	mvlogMetas, err := meta.NewMVLogSyncMetaModel(r.MetaDB).DetailMVLogSyncMetaBySchema(r.Ctx, &meta.MVLogSyncMeta{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: r.Cfg.OracleConfig.SchemaName,
+	})
+	if err != nil {
+		return err
+	}
+
+	oracleDBVersion, err := r.Oracle.GetOracleDBVersion()
+	if err != nil {
+		return err
+	}
+	oracleCollation := false
+	if common.VersionOrdinal(oracleDBVersion) >= common.VersionOrdinal(common.OracleTableColumnCollationDBVersion) {
+		oracleCollation = true
+	}
+
+	g := &errgroup.Group{}
+	g.SetLimit(r.Cfg.AllConfig.ApplyThreads)
+	for _, m := range mvlogMetas {
+		mvlogMeta := m
+		g.Go(func() error {
+			return r.drainTableMVLog(mvlogMeta, oracleCollation)
+		})
+	}
+	return g.Wait()
+}
+
+// drainTableMVLog 消费单表物化视图日志：主键去重后回查源表当前行，下游同事务先删后写
+func (r *Migrate) drainTableMVLog(m meta.MVLogSyncMeta, oracleCollation bool) error {
+	startTime := time.Now()
+	batchSize := r.Cfg.AllConfig.MVLogBatchSize
+	if batchSize <= 0 {
+		batchSize = common.MigrateMVLogBatchSize
+	}
+	if batchSize > common.MigrateMVLogMaxBatchSize {
+		batchSize = common.MigrateMVLogMaxBatchSize
+	}
+
+	columnDetail, err := r.adjustTableSelectColumn(m.TableNameS, oracleCollation)
+	if err != nil {
+		return err
+	}
+	pkColumns := strings.Split(m.PrimaryKeyS, ",")
+	// 触发器影子表由 transferdb 独占，消费后总是删除
+	purge := r.Cfg.AllConfig.MVLogPurge || m.CaptureMode == common.MigrateCaptureModeTrigger
+	if purge && m.ReuseLogS {
+		return fmt.Errorf("oracle schema [%s] table [%s] materialized view log [%s] is reused, refuse to purge it, please disable [all] mvlog-purge", m.SchemaNameS, m.TableNameS, m.LogTableS)
+	}
+
+	var applyTotals int64
+	sequence := m.SequenceS
+	for {
+		records, err := r.Oracle.GetOracleMViewLogRecord(m.SchemaNameS, m.LogTableS, pkColumns, sequence, purge, batchSize)
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			break
+		}
+
+		var (
+			keys    [][]string
+			rowIDs  []string
+			keyDups = make(map[string]struct{})
+		)
+		for _, rec := range records {
+			seq, err := strconv.ParseUint(rec["SEQUENCE"], 10, 64)
+			if err != nil {
+				return fmt.Errorf("oracle materialized view log [%s] sequence [%s] strconv.ParseUint failed: %v", m.LogTableS, rec["SEQUENCE"], err)
+			}
+			if seq > sequence {
+				sequence = seq
+			}
+			rowIDs = append(rowIDs, rec["LOG_ROWID"])
+
+			var key []string
+			for _, c := range pkColumns {
+				key = append(key, rec[c])
+			}
+			dupKey := strings.Join(key, "\x00")
+			if _, ok := keyDups[dupKey]; ok {
+				continue
+			}
+			keyDups[dupKey] = struct{}{}
+			keys = append(keys, key)
+		}
+
+		if err = r.applyTableMVLogRecord(m, columnDetail, pkColumns, keys); err != nil {
+			return err
+		}
+
+		if purge {
+			if err = r.Oracle.PurgeOracleMViewLogRecord(m.SchemaNameS, m.LogTableS, rowIDs); err != nil {
+				return err
+			}
+		}
+		if err = meta.NewMVLogSyncMetaModel(r.MetaDB).UpdateMVLogSyncMetaSequence(r.Ctx, &m, sequence, int64(len(keys))); err != nil {
+			return err
+		}
+		applyTotals += int64(len(keys))
+
+		if len(records) < batchSize {
+			break
+		}
+	}
+
+	if applyTotals > 0 {
+		zap.L().Info("increment table materialized view log drain finished",
+			zap.String("schema", m.SchemaNameS),
+			zap.String("table", m.TableNameS),
+			zap.String("capture mode", m.CaptureMode),
+			zap.String("log table", m.LogTableS),
+			zap.Uint64("sequence", sequence),
+			zap.Int64("apply keys", applyTotals),
+			zap.String("cost", time.Now().Sub(startTime).String()))
+	}
+	return nil
+}
+
+// applyTableMVLogRecord 回查源表当前行，不存在的主键即已删除，下游按主键删除后写入现存行
+func (r *Migrate) applyTableMVLogRecord(m meta.MVLogSyncMeta, columnDetail string, pkColumns []string, keys [][]string) error {
+	var (
+		oraKeys   []string
+		mysqlKeys strings.Builder
+	)
+	for i, key := range keys {
+		var oraKey []string
+		for _, k := range key {
+			oraKey = append(oraKey, common.StringsBuilder(`'`, strings.ReplaceAll(k, `'`, `''`), `'`))
+		}
+		oraKeys = append(oraKeys, common.StringsBuilder(`(`, strings.Join(oraKey, ","), `)`))
+
+		if i > 0 {
+			mysqlKeys.WriteString(",")
+		}
+		buf := common.GetBuffer()
+		buf.WriteByte('(')
+		for j, k := range key {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			common.AppendMySQLValue(buf, []byte(k))
+		}
+		buf.WriteByte(')')
+		mysqlKeys.WriteString(buf.String())
+		common.PutBuffer(buf)
+	}
+	pkList := common.StringsBuilder(`(`, strings.Join(pkColumns, ","), `)`)
+	var targetPKs []string
+	for _, c := range pkColumns {
+		targetPK, _, _ := r.ColumnRewriter.Rewrite(c)
+		targetPKs = append(targetPKs, common.StringsBuilder("`", targetPK, "`"))
+	}
+
+	querySQL := common.StringsBuilder(`SELECT `, columnDetail, ` FROM `, m.SchemaNameS, `.`, m.TableNameS,
+		` WHERE `, pkList, ` IN (`, strings.Join(oraKeys, ","), `)`)
+	columns, batchResults, err := r.Oracle.GetOracleTableRowsData(querySQL, r.Cfg.AppConfig.InsertBatchSize)
+	if err != nil {
+		return err
+	}
+
+	txn, err := r.Mysql.MySQLDB.BeginTx(r.Ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("mvlog table [%s.%s] transaction start failed: %v", m.SchemaNameT, m.TableNameT, err)
+	}
+	deleteSQL := common.StringsBuilder("DELETE FROM `", m.SchemaNameT, "`.`", m.TableNameT, "` WHERE (", strings.Join(targetPKs, ","), `) IN (`, mysqlKeys.String(), `)`)
+	if _, err = txn.ExecContext(r.Ctx, deleteSQL); err != nil {
+		if errR := txn.Rollback(); errR != nil {
+			zap.L().Warn("mvlog transaction rollback failed", zap.Error(errR))
+		}
+		return fmt.Errorf("mvlog table [%s.%s] sql [%s] delete failed: %v", m.SchemaNameT, m.TableNameT, deleteSQL, err)
+	}
+
+	prefixSQL := GenMySQLInsertSQLStmtPrefix(common.StringsBuilder("`", m.SchemaNameT, "`"), common.StringsBuilder("`", m.TableNameT, "`"), r.ColumnRewriter.RewriteQuotedColumns(columns), true)
+	for _, rows := range batchResults {
+		if len(rows) == 0 {
+			continue
+		}
+		buf := common.GetBuffer()
+		buf.WriteString(prefixSQL)
+		for i, row := range rows {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			common.AppendMySQLRow(buf, row)
+		}
+		query := buf.String()
+		common.PutBuffer(buf)
+		if _, err = txn.ExecContext(r.Ctx, query); err != nil {
+			if errR := txn.Rollback(); errR != nil {
+				zap.L().Warn("mvlog transaction rollback failed", zap.Error(errR))
+			}
+			return fmt.Errorf("mvlog table [%s.%s] sql [%s] write failed: %v", m.SchemaNameT, m.TableNameT, query, err)
+		}
+	}
+	if err = txn.Commit(); err != nil {
+		return fmt.Errorf("mvlog table [%s.%s] transaction commit failed: %v", m.SchemaNameT, m.TableNameT, err)
+	}
+	return nil
+}
@@ -0,0 +1,412 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/segmentio/kafka-go"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/database/mysql"
+	"github.com/wentaojin/transferdb/filter"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OGG 消费 OGG Kafka Handler 变更记录同步下游，适用于上游禁止 logminer 场景
+type OGG struct {
+	Ctx            context.Context
+	Cfg            *config.Config
+	Mysql          *mysql.MySQL
+	MetaDB         *meta.Meta
+	tableFilter    filter.Filter
+	excludeFilter  filter.Filter
+	tableNameRule  map[string]string
+	tableRewriter  *common.NameRewriter
+	columnRewriter *common.NameRewriter
+}
+
+// OGGRecord OGG JSON Formatter 单条变更记录（op-per-message）
+type OGGRecord struct {
+	Table       string                 `json:"table"`
+	OpType      string                 `json:"op_type"`
+	OpTs        string                 `json:"op_ts"`
+	Pos         string                 `json:"pos"`
+	PrimaryKeys []string               `json:"primary_keys"`
+	Before      map[string]interface{} `json:"before"`
+	After       map[string]interface{} `json:"after"`
+}
+
+// oggFlushInterval 分区批次未满时最长等待时间
+const oggFlushInterval = time.Second
+
+func NewOGG(ctx context.Context, cfg *config.Config) (*OGG, error) {
+	mysqlDB, err := mysql.NewMySQLDBEngine(ctx, cfg.MySQLConfig)
+	if err != nil {
+		return nil, err
+	}
+	metaDB, err := meta.NewMetaDBEngine(ctx, cfg.MySQLConfig, cfg.AppConfig.SlowlogThreshold)
+	if err != nil {
+		return nil, err
+	}
+	return &OGG{
+		Ctx:    ctx,
+		Cfg:    cfg,
+		Mysql:  mysqlDB,
+		MetaDB: metaDB,
+	}, nil
+}
+
+func (r *OGG) Incr() error {
+	zap.L().Info("oracle to mysql ogg kafka increment sync start",
+		zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+		zap.Strings("brokers", r.Cfg.OGGConfig.Brokers),
+		zap.Strings("topics", r.Cfg.OGGConfig.Topics))
+
+	if len(r.Cfg.OGGConfig.Brokers) == 0 || len(r.Cfg.OGGConfig.Topics) == 0 {
+		return fmt.Errorf("ogg config brokers [%v] or topics [%v] can't be null", r.Cfg.OGGConfig.Brokers, r.Cfg.OGGConfig.Topics)
+	}
+	if r.Cfg.OGGConfig.Format != common.OGGFormatJSON {
+		// AVRO 需 schema registry 解码，暂不支持
+		return fmt.Errorf("ogg config format [%s] isn't support, only support [%s]", r.Cfg.OGGConfig.Format, common.OGGFormatJSON)
+	}
+
+	var err error
+	if len(r.Cfg.OracleConfig.IncludeTable) > 0 {
+		if r.tableFilter, err = filter.Parse(r.Cfg.OracleConfig.IncludeTable); err != nil {
+			return err
+		}
+	}
+	if len(r.Cfg.OracleConfig.ExcludeTable) > 0 {
+		if r.excludeFilter, err = filter.Parse(r.Cfg.OracleConfig.ExcludeTable); err != nil {
+			return err
+		}
+	}
+
+	// 无源端连接无法枚举源表，正则改写规则按变更记录表名即时匹配
+	r.tableNameRule, err = meta.NewTableNameRuleModel(r.MetaDB).DetailTableNameRuleMap(r.Ctx, &meta.TableNameRule{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: r.Cfg.OracleConfig.SchemaName,
+		SchemaNameT: r.Cfg.MySQLConfig.SchemaName,
+	}, nil, nil)
+	if err != nil {
+		return err
+	}
+	r.tableRewriter, err = r.Cfg.RewriteConfig.TableRewriter()
+	if err != nil {
+		return err
+	}
+	r.columnRewriter, err = r.Cfg.RewriteConfig.ColumnRewriter()
+	if err != nil {
+		return err
+	}
+
+	// 每个 topic 分区独立消费，保证分区内变更有序
+	ctx, cancel := context.WithCancel(r.Ctx)
+	defer cancel()
+	g, gCtx := errgroup.WithContext(ctx)
+	for _, topic := range r.Cfg.OGGConfig.Topics {
+		partitions, err := r.readTopicPartitions(topic)
+		if err != nil {
+			// 已启动的分区消费随 context 取消退出，等待退出后返回
+			cancel()
+			_ = g.Wait()
+			return err
+		}
+		for _, p := range partitions {
+			t, partition := topic, p
+			g.Go(func() error {
+				return r.consumePartition(gCtx, t, partition)
+			})
+		}
+	}
+	return g.Wait()
+}
+
+func (r *OGG) readTopicPartitions(topic string) ([]int, error) {
+	var lastErr error
+	for _, broker := range r.Cfg.OGGConfig.Brokers {
+		conn, err := kafka.DialContext(r.Ctx, "tcp", broker)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		partitions, err := conn.ReadPartitions(topic)
+		conn.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		var ids []int
+		for _, p := range partitions {
+			ids = append(ids, p.ID)
+		}
+		return ids, nil
+	}
+	return nil, fmt.Errorf("read kafka topic [%s] partitions failed: %v", topic, lastErr)
+}
+
+func (r *OGG) consumePartition(ctx context.Context, topic string, partition int) error {
+	offsetMeta := &meta.OGGOffsetMeta{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: r.Cfg.OracleConfig.SchemaName,
+		Topic:       topic,
+		PartitionID: partition,
+	}
+	offsets, err := meta.NewOGGOffsetMetaModel(r.MetaDB).DetailOGGOffsetMeta(r.Ctx, offsetMeta)
+	if err != nil {
+		return err
+	}
+
+	startOffset := kafka.FirstOffset
+	if r.Cfg.OGGConfig.StartOffset == common.OGGStartOffsetLatest {
+		startOffset = kafka.LastOffset
+	}
+	if len(offsets) > 0 {
+		startOffset = offsets[0].Offset
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   r.Cfg.OGGConfig.Brokers,
+		Topic:     topic,
+		Partition: partition,
+		MinBytes:  1,
+		MaxBytes:  10e6,
+	})
+	defer reader.Close()
+	if err = reader.SetOffset(startOffset); err != nil {
+		return fmt.Errorf("kafka topic [%s] partition [%d] set offset [%d] failed: %v", topic, partition, startOffset, err)
+	}
+
+	zap.L().Info("ogg kafka partition consume start",
+		zap.String("topic", topic),
+		zap.Int("partition", partition),
+		zap.Int64("offset", startOffset))
+
+	for {
+		// 首条消息阻塞等待，后续消息等待 oggFlushInterval 或达到 batch-size 后批量写入
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			return fmt.Errorf("kafka topic [%s] partition [%d] read message failed: %v", topic, partition, err)
+		}
+		msgs := []kafka.Message{msg}
+
+		flushCtx, cancel := context.WithTimeout(ctx, oggFlushInterval)
+		for len(msgs) < r.Cfg.OGGConfig.BatchSize {
+			msg, err = reader.ReadMessage(flushCtx)
+			if err != nil {
+				break
+			}
+			msgs = append(msgs, msg)
+		}
+		cancel()
+		if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("kafka topic [%s] partition [%d] read message failed: %v", topic, partition, err)
+		}
+
+		var stmts []string
+		for _, m := range msgs {
+			sqls, isDDL, err := r.translateRecord(m.Value)
+			if err != nil {
+				return fmt.Errorf("kafka topic [%s] partition [%d] offset [%d] translate failed: %v", topic, partition, m.Offset, err)
+			}
+			if !isDDL {
+				stmts = append(stmts, sqls...)
+				continue
+			}
+			// TRUNCATE 隐式提交，先提交已累积变更，再使用 DDL 用户事务外执行
+			if err = r.applyStmts(stmts); err != nil {
+				return fmt.Errorf("kafka topic [%s] partition [%d] offset [%d-%d] apply failed: %v", topic, partition, msgs[0].Offset, m.Offset, err)
+			}
+			stmts = nil
+			if err = r.applyDDL(sqls); err != nil {
+				return fmt.Errorf("kafka topic [%s] partition [%d] offset [%d] apply failed: %v", topic, partition, m.Offset, err)
+			}
+		}
+		if err = r.applyStmts(stmts); err != nil {
+			return fmt.Errorf("kafka topic [%s] partition [%d] offset [%d-%d] apply failed: %v", topic, partition, msgs[0].Offset, msgs[len(msgs)-1].Offset, err)
+		}
+
+		// 写入成功后记录下一条待消费位点，重启后至少一次重放，REPLACE/UPDATE/主键 DELETE 幂等
+		offsetMeta.Offset = msgs[len(msgs)-1].Offset + 1
+		if err = meta.NewOGGOffsetMetaModel(r.MetaDB).UpsertOGGOffsetMeta(r.Ctx, offsetMeta); err != nil {
+			return err
+		}
+	}
+}
+
+func (r *OGG) applyStmts(stmts []string) error {
+	if len(stmts) == 0 {
+		return nil
+	}
+	txn, err := r.Mysql.MySQLDB.BeginTx(r.Ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("ogg transaction start failed: %v", err)
+	}
+	for _, s := range stmts {
+		if _, err = txn.ExecContext(r.Ctx, s); err != nil {
+			if errR := txn.Rollback(); errR != nil {
+				zap.L().Warn("ogg transaction rollback failed", zap.Error(errR))
+			}
+			return fmt.Errorf("ogg sql [%s] apply failed: %v", s, err)
+		}
+	}
+	if err = txn.Commit(); err != nil {
+		return fmt.Errorf("ogg transaction commit failed: %v", err)
+	}
+	return nil
+}
+
+// applyDDL TRUNCATE 等 DDL 使用 DDL 用户执行，不参与 DML 事务
+func (r *OGG) applyDDL(stmts []string) error {
+	for _, s := range stmts {
+		if _, err := r.Mysql.DDLDB.ExecContext(r.Ctx, s); err != nil {
+			return fmt.Errorf("ogg sql [%s] apply failed: %v", s, err)
+		}
+	}
+	return nil
+}
+
+// translateRecord OGG 变更记录转换下游 DML/DDL，非同步 schema 或过滤表返回空
+func (r *OGG) translateRecord(value []byte) ([]string, bool, error) {
+	var record OGGRecord
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+	if err := decoder.Decode(&record); err != nil {
+		return nil, false, fmt.Errorf("ogg json record [%s] decode failed: %v", string(value), err)
+	}
+
+	// table 格式 SCHEMA.TABLE，CDB 下为 PDB.SCHEMA.TABLE
+	names := strings.Split(common.StringUPPER(record.Table), ".")
+	if len(names) < 2 {
+		return nil, false, fmt.Errorf("ogg json record table [%s] format error", record.Table)
+	}
+	schemaName, tableName := names[len(names)-2], names[len(names)-1]
+	if schemaName != r.Cfg.OracleConfig.SchemaName {
+		return nil, false, nil
+	}
+	if r.tableFilter != nil && !r.tableFilter.MatchTable(tableName) {
+		return nil, false, nil
+	}
+	if r.excludeFilter != nil && r.excludeFilter.MatchTable(tableName) {
+		return nil, false, nil
+	}
+
+	targetTable := tableName
+	if val, ok := r.tableNameRule[tableName]; ok {
+		targetTable = val
+	} else {
+		targetTable, _, _ = r.tableRewriter.Rewrite(tableName)
+	}
+	targetTableFrom := common.StringsBuilder("`", r.Cfg.MySQLConfig.SchemaName, "`.`", targetTable, "`")
+
+	switch common.StringUPPER(record.OpType) {
+	case common.OGGOpTypeInsert:
+		var (
+			columns []string
+			values  []string
+		)
+		for _, c := range sortedRecordColumns(record.After) {
+			columns = append(columns, common.StringsBuilder("`", r.targetColumn(c), "`"))
+			values = append(values, renderOGGValue(record.After[c]))
+		}
+		return []string{common.StringsBuilder("REPLACE INTO ", targetTableFrom, " (", strings.Join(columns, ","), ") VALUES (", strings.Join(values, ","), ")")}, false, nil
+	case common.OGGOpTypeUpdate:
+		// 压缩更新 after 仅包含变更字段，使用 UPDATE 而非 REPLACE
+		before := record.Before
+		if len(before) == 0 {
+			before = record.After
+		}
+		var sets []string
+		for _, c := range sortedRecordColumns(record.After) {
+			sets = append(sets, common.StringsBuilder("`", r.targetColumn(c), "` = ", renderOGGValue(record.After[c])))
+		}
+		return []string{common.StringsBuilder("UPDATE ", targetTableFrom, " SET ", strings.Join(sets, ","), " WHERE ", r.genOGGWhere(record.PrimaryKeys, before))}, false, nil
+	case common.OGGOpTypeDelete:
+		// 无主键/唯一键表 DELETE ... LIMIT 1 重放时会误删重复行，非幂等，要求源端 trandata 提供主键或唯一键
+		if len(record.PrimaryKeys) == 0 {
+			return nil, false, fmt.Errorf("ogg json record table [%s] op_type [%s] primary_keys is null, delete replay requires primary key or unique key", record.Table, record.OpType)
+		}
+		return []string{common.StringsBuilder("DELETE FROM ", targetTableFrom, " WHERE ", r.genOGGWhere(record.PrimaryKeys, record.Before))}, false, nil
+	case common.OGGOpTypeTruncate:
+		return []string{common.StringsBuilder("TRUNCATE TABLE ", targetTableFrom)}, true, nil
+	default:
+		return nil, false, fmt.Errorf("ogg json record table [%s] op_type [%s] isn't support", record.Table, record.OpType)
+	}
+}
+
+// targetColumn 按 [rewrite] 字段改写规则转换下游字段名
+func (r *OGG) targetColumn(column string) string {
+	targetColumn, _, _ := r.columnRewriter.Rewrite(column)
+	return targetColumn
+}
+
+// genOGGWhere 存在主键按主键定位，否则按全部 before 字段定位
+func (r *OGG) genOGGWhere(primaryKeys []string, before map[string]interface{}) string {
+	columns := primaryKeys
+	if len(columns) == 0 {
+		columns = sortedRecordColumns(before)
+	}
+	var conds []string
+	for _, c := range columns {
+		val := before[c]
+		if val == nil {
+			conds = append(conds, common.StringsBuilder("`", r.targetColumn(c), "` IS NULL"))
+			continue
+		}
+		conds = append(conds, common.StringsBuilder("`", r.targetColumn(c), "` = ", renderOGGValue(val)))
+	}
+	return strings.Join(conds, " AND ")
+}
+
+func sortedRecordColumns(values map[string]interface{}) []string {
+	var columns []string
+	for c := range values {
+		columns = append(columns, c)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+func renderOGGValue(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return "NULL"
+	case json.Number:
+		return v.String()
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case string:
+		return common.RenderMySQLValue([]byte(v))
+	default:
+		b, _ := json.Marshal(v)
+		return common.RenderMySQLValue(b)
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	"go.uber.org/zap"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// tableErrors error-policy = continue 时记录跳过的表以及错误，零值可用
+type tableErrors struct {
+	mu     sync.Mutex
+	errors map[string]string
+}
+
+func (e *tableErrors) Add(tableName string, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.errors == nil {
+		e.errors = make(map[string]string)
+	}
+	e.errors[common.StringUPPER(tableName)] = err.Error()
+}
+
+// Tables 跳过的表，按表名排序
+func (e *tableErrors) Tables() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var tables []string
+	for t := range e.errors {
+		tables = append(tables, t)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// Filter 过滤已跳过的表，保持原有顺序
+func (e *tableErrors) Filter(tables []string) []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var filters []string
+	for _, t := range tables {
+		if _, ok := e.errors[common.StringUPPER(t)]; !ok {
+			filters = append(filters, t)
+		}
+	}
+	return filters
+}
+
+// Summary 输出跳过表汇总，存在跳过的表返回错误，进程以非 0 退出
+func (e *tableErrors) Summary(schemaName, taskMode string) error {
+	tables := e.Tables()
+	if len(tables) == 0 {
+		return nil
+	}
+	e.mu.Lock()
+	for _, t := range tables {
+		zap.L().Error("table skipped by error policy continue",
+			zap.String("schema", schemaName),
+			zap.String("table", t),
+			zap.String("mode", taskMode),
+			zap.String("error", e.errors[t]))
+	}
+	e.mu.Unlock()
+	return fmt.Errorf("schema [%s] mode [%s] skipped tables [%d] by error-policy continue: [%s], please see meta table [wait_sync_meta] and [error_log_detail], fix and rerunning",
+		schemaName, taskMode, len(tables), strings.Join(tables, ","))
+}
+
+// tableErrorPolicy 表级任务按 error-policy 处理错误
+// continue 时记录表失败状态以及错误详情后返回 nil，不中断其他表
+func (r *Migrate) tableErrorPolicy(tableName string, fn func() error) func() error {
+	return func() error {
+		err := fn()
+		if err == nil || r.Cfg.AppConfig.ErrorPolicy != common.TaskErrorPolicyContinue {
+			return err
+		}
+		zap.L().Error("table task failed, skip by error policy continue",
+			zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+			zap.String("table", common.StringUPPER(tableName)),
+			zap.String("mode", r.Cfg.TaskMode),
+			zap.Error(err))
+		r.skipTables.Add(tableName, err)
+
+		if errf := meta.NewWaitSyncMetaModel(r.MetaDB).UpdateWaitSyncMeta(r.Ctx, &meta.WaitSyncMeta{
+			DBTypeS:     r.Cfg.DBTypeS,
+			DBTypeT:     r.Cfg.DBTypeT,
+			SchemaNameS: common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
+			TableNameS:  common.StringUPPER(tableName),
+			TaskMode:    r.Cfg.TaskMode,
+		}, map[string]interface{}{
+			"TaskStatus": common.TaskStatusFailed,
+		}); errf != nil {
+			return fmt.Errorf("table [%s] error [%v] update meta table [wait_sync_meta] failed: %v", tableName, err, errf)
+		}
+		if errf := meta.NewErrorLogDetailModel(r.MetaDB).CreateErrorLog(r.Ctx, &meta.ErrorLogDetail{
+			DBTypeS:     r.Cfg.DBTypeS,
+			DBTypeT:     r.Cfg.DBTypeT,
+			SchemaNameS: common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
+			TableNameS:  common.StringUPPER(tableName),
+			SchemaNameT: common.StringUPPER(r.Cfg.MySQLConfig.SchemaName),
+			TableNameT:  common.StringUPPER(tableName),
+			TaskMode:    r.Cfg.TaskMode,
+			TaskStatus:  common.TaskStatusFailed,
+			InfoDetail:  "table skipped by error-policy continue",
+			ErrorDetail: err.Error(),
+		}); errf != nil {
+			return fmt.Errorf("table [%s] error [%v] create meta table [error_log_detail] failed: %v", tableName, err, errf)
+		}
+		return nil
+	}
+}
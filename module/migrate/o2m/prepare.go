@@ -0,0 +1,111 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"go.uber.org/zap"
+)
+
+// applyPrepareRows apply-protocol = PREPARE 占位符绑定参数写入
+// 按整 batch 行数（insert-batch-size，受 MySQL 占位符上限约束）拆分，整 batch 复用预编译语句，尾部剩余行直接绑定参数执行
+// 自适应 batch 行数不固定，仅缓存单条预编译语句，避免按行数缓存触及 max_prepared_stmt_count
+func (t *Chunk) applyPrepareRows(columns []string, rows []common.RowValue) error {
+	stmtRows := t.BatchSize
+	if maxRows := common.MigrateMySQLMaxPlaceholders / len(columns); stmtRows <= 0 || stmtRows > maxRows {
+		stmtRows = maxRows
+	}
+
+	for start := 0; start < len(rows); start += stmtRows {
+		end := start + stmtRows
+		if end > len(rows) {
+			end = len(rows)
+		}
+		if err := t.applyPrepareStmt(columns, rows[start:end], end-start == stmtRows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Chunk) applyPrepareStmt(columns []string, rows []common.RowValue, fullBatch bool) error {
+	prefixSQL, suffixSQL := GenMySQLApplySQLStmt(t.SyncMeta.SchemaNameT, t.SyncMeta.TableNameT, columns, t.ApplyMode)
+	prepareSQL := common.StringsBuilder(prefixSQL, GenMySQLPrepareBindVarStmt(len(columns), len(rows)), suffixSQL)
+
+	args := make([]interface{}, 0, len(rows)*len(columns))
+	for _, row := range rows {
+		args = append(args, row...)
+	}
+
+	var err error
+	if fullBatch {
+		var stmt *sql.Stmt
+		stmt, err = t.getPrepareStmt(prepareSQL)
+		if err != nil {
+			return err
+		}
+		err = t.MySQL.WriteMySQLTableStmtWithFailover(stmt, prepareSQL, args, t.RetryTimes, t.RetryInterval)
+	} else {
+		err = t.MySQL.WriteMySQLTableArgsWithFailover(prepareSQL, args, t.RetryTimes, t.RetryInterval)
+	}
+	if err != nil {
+		// batch 二分重试，定位问题数据行
+		if t.BisectRetry {
+			zap.L().Warn("target schema table prepare batch write failed, bisect retry",
+				zap.String("schema", t.SyncMeta.SchemaNameT),
+				zap.String("table", t.SyncMeta.TableNameT),
+				zap.String("rowid", t.SyncMeta.ChunkDetailS),
+				zap.Error(err))
+			return t.applyBatchBisect(prefixSQL, suffixSQL, rows)
+		}
+		return fmt.Errorf("error on write db prepare rows, rowid [%s], error: %v", t.SyncMeta.ChunkDetailS, err)
+	}
+	return nil
+}
+
+// getPrepareStmt 同一 chunk 内整 batch 预编译语句，首次使用时预编译
+func (t *Chunk) getPrepareStmt(prepareSQL string) (*sql.Stmt, error) {
+	t.stmtMu.Lock()
+	defer t.stmtMu.Unlock()
+
+	if t.stmt != nil {
+		return t.stmt, nil
+	}
+	stmt, err := t.MySQL.PrepareMySQLTableStmt(prepareSQL)
+	if err != nil {
+		return nil, err
+	}
+	t.stmt = stmt
+	return stmt, nil
+}
+
+func (t *Chunk) closePrepareStmts() {
+	t.stmtMu.Lock()
+	defer t.stmtMu.Unlock()
+
+	if t.stmt == nil {
+		return
+	}
+	if err := t.stmt.Close(); err != nil {
+		zap.L().Warn("target schema table prepare stmt close failed",
+			zap.String("schema", t.SyncMeta.SchemaNameT),
+			zap.String("table", t.SyncMeta.TableNameT),
+			zap.Error(err))
+	}
+	t.stmt = nil
+}
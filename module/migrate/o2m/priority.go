@@ -0,0 +1,75 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	"go.uber.org/zap"
+	"sort"
+	"strconv"
+)
+
+// tablePriority 表级调度优先级，未配置默认 0
+func (r *Migrate) tablePriority(sourceTable string) int {
+	for _, tp := range r.Cfg.FullConfig.TablePriority {
+		if common.StringUPPER(tp.SourceTable) == common.StringUPPER(sourceTable) {
+			return tp.Priority
+		}
+	}
+	return 0
+}
+
+// sortTablesByPriority 按 wait_sync_meta 优先级降序排列待同步表，同优先级开启 priority-by-size 时按表数据段（含 LOB）大小降序，否则保持原有顺序
+func (r *Migrate) sortTablesByPriority(waitSyncMetas []meta.WaitSyncMeta) ([]string, error) {
+	tableBytes := make(map[string]float64)
+	if r.Cfg.FullConfig.PriorityBySize && len(waitSyncMetas) > 1 {
+		segments, err := r.Oracle.GetOracleSchemaTableSegmentBytes(common.StringUPPER(r.Cfg.OracleConfig.SchemaName))
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range segments {
+			if s["SEGMENT_CATEGORY"] != "TABLE" {
+				continue
+			}
+			bytes, err := strconv.ParseFloat(s["BYTES"], 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse oracle table [%s] segment bytes [%s] failed: %v", s["TABLE_NAME"], s["BYTES"], err)
+			}
+			tableBytes[common.StringUPPER(s["TABLE_NAME"])] += bytes
+		}
+	}
+
+	sort.SliceStable(waitSyncMetas, func(i, j int) bool {
+		if waitSyncMetas[i].Priority != waitSyncMetas[j].Priority {
+			return waitSyncMetas[i].Priority > waitSyncMetas[j].Priority
+		}
+		return tableBytes[common.StringUPPER(waitSyncMetas[i].TableNameS)] > tableBytes[common.StringUPPER(waitSyncMetas[j].TableNameS)]
+	})
+
+	var tables []string
+	for _, m := range waitSyncMetas {
+		tables = append(tables, common.StringUPPER(m.TableNameS))
+	}
+	if len(r.Cfg.FullConfig.TablePriority) > 0 || r.Cfg.FullConfig.PriorityBySize {
+		zap.L().Info("full table sync order by priority",
+			zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+			zap.Bool("priority by size", r.Cfg.FullConfig.PriorityBySize),
+			zap.Strings("tables", tables))
+	}
+	return tables, nil
+}
@@ -0,0 +1,205 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+// Reload 指定表范围数据重新加载
+// 1、分批删除下游满足条件数据
+// 2、上游按照条件重新抽取写入，任务以 RELOAD 模式记录 wait_sync_meta / full_sync_meta
+func (r *Migrate) Reload() error {
+	startTime := time.Now()
+	zap.L().Info("source schema table range data reload start",
+		zap.String("schema", r.Cfg.OracleConfig.SchemaName))
+
+	if len(r.Cfg.ReloadConfig.TableConfig) == 0 {
+		return fmt.Errorf("reload config [table-config] can't be null, please configure")
+	}
+	if r.Cfg.ReloadConfig.DeleteBatchSize <= 0 {
+		return fmt.Errorf("reload config [delete-batch-size] value [%d] should be greater than 0", r.Cfg.ReloadConfig.DeleteBatchSize)
+	}
+
+	oracleDBVersion, err := r.Oracle.GetOracleDBVersion()
+	if err != nil {
+		return err
+	}
+	if common.VersionOrdinal(oracleDBVersion) < common.VersionOrdinal(common.RequireOracleDBVersion) {
+		return fmt.Errorf("oracle db version [%v] is less than 11g, can't be using transferdb tools", oracleDBVersion)
+	}
+	oracleCollation := false
+	if common.VersionOrdinal(oracleDBVersion) >= common.VersionOrdinal(common.OracleTableColumnCollationDBVersion) {
+		oracleCollation = true
+	}
+
+	tableNameRule, err := r.getTableNameRule()
+	if err != nil {
+		return err
+	}
+
+	globalSCN, err := r.Oracle.GetOracleCurrentSnapshotSCN()
+	if err != nil {
+		return err
+	}
+	if err = r.Oracle.WaitStandbyApplySCN(globalSCN); err != nil {
+		return err
+	}
+
+	// 先整体校验全部 table-config，避免部分表下游数据已删除后因后续配置非法而中断
+	var reloadTables []string
+	for _, tc := range r.Cfg.ReloadConfig.TableConfig {
+		sourceTable := common.StringUPPER(tc.SourceTable)
+		if sourceTable == "" || strings.TrimSpace(tc.SourceWhere) == "" {
+			return fmt.Errorf("reload config [table-config] source-table [%s] or source-where [%s] can't be null", tc.SourceTable, tc.SourceWhere)
+		}
+		// chunk_detail_s 字段长度 varchar(300)
+		if len(tc.SourceWhere) > 300 {
+			return fmt.Errorf("reload table [%s] source-where [%s] length over 300, please adjust", sourceTable, tc.SourceWhere)
+		}
+		if common.IsContainString(reloadTables, sourceTable) {
+			return fmt.Errorf("reload table [%s] is repeated in config [table-config], only configure once", sourceTable)
+		}
+		reloadTables = append(reloadTables, sourceTable)
+	}
+
+	for _, tc := range r.Cfg.ReloadConfig.TableConfig {
+		sourceTable := common.StringUPPER(tc.SourceTable)
+		targetTable := sourceTable
+		if val, ok := tableNameRule[sourceTable]; ok {
+			targetTable = val
+		}
+		// 下游条件未配置，默认与上游条件一致
+		targetWhere := tc.SourceWhere
+		if strings.TrimSpace(tc.TargetWhere) != "" {
+			targetWhere = tc.TargetWhere
+		}
+
+		sourceColumnInfo, err := r.adjustTableSelectColumn(sourceTable, oracleCollation)
+		if err != nil {
+			return err
+		}
+
+		// 清理历史 reload 任务记录，重新初始化
+		if err = meta.NewFullSyncMetaModel(r.MetaDB).DeleteFullSyncMetaBySchemaTable(r.Ctx, &meta.FullSyncMeta{
+			DBTypeS:     r.Cfg.DBTypeS,
+			DBTypeT:     r.Cfg.DBTypeT,
+			SchemaNameS: r.Cfg.OracleConfig.SchemaName,
+			TableNameS:  sourceTable,
+			TaskMode:    r.Cfg.TaskMode,
+		}); err != nil {
+			return err
+		}
+		if err = meta.NewWaitSyncMetaModel(r.MetaDB).DeleteWaitSyncMeta(r.Ctx, &meta.WaitSyncMeta{
+			DBTypeS:     r.Cfg.DBTypeS,
+			DBTypeT:     r.Cfg.DBTypeT,
+			SchemaNameS: r.Cfg.OracleConfig.SchemaName,
+			TableNameS:  sourceTable,
+			TaskMode:    r.Cfg.TaskMode,
+		}); err != nil {
+			return err
+		}
+		if err = meta.NewWaitSyncMetaModel(r.MetaDB).CreateWaitSyncMeta(r.Ctx, &meta.WaitSyncMeta{
+			DBTypeS:        r.Cfg.DBTypeS,
+			DBTypeT:        r.Cfg.DBTypeT,
+			SchemaNameS:    r.Cfg.OracleConfig.SchemaName,
+			TableNameS:     sourceTable,
+			TaskMode:       r.Cfg.TaskMode,
+			TaskStatus:     common.TaskStatusWaiting,
+			GlobalScnS:     common.TaskTableDefaultSourceGlobalSCN,
+			ChunkTotalNums: common.TaskTableDefaultSplitChunkNums,
+		}); err != nil {
+			return err
+		}
+		if err = meta.NewCommonModel(r.MetaDB).CreateFullSyncMetaAndUpdateWaitSyncMeta(r.Ctx, &meta.FullSyncMeta{
+			DBTypeS:       r.Cfg.DBTypeS,
+			DBTypeT:       r.Cfg.DBTypeT,
+			SchemaNameS:   r.Cfg.OracleConfig.SchemaName,
+			TableNameS:    sourceTable,
+			SchemaNameT:   r.Cfg.MySQLConfig.SchemaName,
+			TableNameT:    targetTable,
+			GlobalScnS:    globalSCN,
+			ColumnDetailS: sourceColumnInfo,
+			ChunkDetailS:  tc.SourceWhere,
+			TaskMode:      r.Cfg.TaskMode,
+			TaskStatus:    common.TaskStatusWaiting,
+			InfoDetail:    common.StringsBuilder("target where: ", targetWhere),
+		}, &meta.WaitSyncMeta{
+			DBTypeS:          r.Cfg.DBTypeS,
+			DBTypeT:          r.Cfg.DBTypeT,
+			SchemaNameS:      r.Cfg.OracleConfig.SchemaName,
+			TableNameS:       sourceTable,
+			TaskMode:         r.Cfg.TaskMode,
+			GlobalScnS:       globalSCN,
+			ChunkTotalNums:   1,
+			ChunkSuccessNums: 0,
+			ChunkFailedNums:  0,
+		}); err != nil {
+			return err
+		}
+
+		// 分批删除下游数据
+		deleteRows, err := r.Mysql.DeleteMySQLTableByBatch(r.Cfg.MySQLConfig.SchemaName, targetTable, targetWhere, r.Cfg.ReloadConfig.DeleteBatchSize)
+		if err != nil {
+			if errf := meta.NewWaitSyncMetaModel(r.MetaDB).UpdateWaitSyncMeta(r.Ctx, &meta.WaitSyncMeta{
+				DBTypeS:     r.Cfg.DBTypeS,
+				DBTypeT:     r.Cfg.DBTypeT,
+				SchemaNameS: r.Cfg.OracleConfig.SchemaName,
+				TableNameS:  sourceTable,
+				TaskMode:    r.Cfg.TaskMode,
+			}, map[string]interface{}{
+				"TaskStatus": common.TaskStatusFailed,
+			}); errf != nil {
+				return errf
+			}
+			return err
+		}
+		zap.L().Info("target schema table range data delete finished",
+			zap.String("schema", r.Cfg.MySQLConfig.SchemaName),
+			zap.String("table", targetTable),
+			zap.String("where", targetWhere),
+			zap.Int64("delete rows", deleteRows))
+	}
+
+	// 重新抽取写入
+	if err = r.fullPartSyncTable(reloadTables); err != nil {
+		return err
+	}
+
+	failedTotals, err := meta.NewWaitSyncMetaModel(r.MetaDB).DetailWaitSyncMeta(r.Ctx, &meta.WaitSyncMeta{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: r.Cfg.OracleConfig.SchemaName,
+		TaskMode:    r.Cfg.TaskMode,
+		TaskStatus:  common.TaskStatusFailed,
+	})
+	if err != nil {
+		return err
+	}
+
+	zap.L().Info("source schema table range data reload finished",
+		zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+		zap.Int("table totals", len(reloadTables)),
+		zap.Int("table failed", len(failedTotals)),
+		zap.String("log detail", "if exist table failed, please see meta table [wait/full_sync_meta] task_mode [RELOAD]"),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
@@ -0,0 +1,187 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"context"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/database/oracle"
+	"github.com/wentaojin/transferdb/module/migrate"
+	"strconv"
+)
+
+// OracleSource Oracle 源端读取
+type OracleSource struct {
+	Cfg    *config.Config
+	Oracle *oracle.Oracle
+	MetaDB *meta.Meta
+	// 全局抽取限速，表级限速 [[full.table-limit]] 以其为父级，同表各 chunk 共享
+	Throttle       *Throttle
+	tableThrottles map[string]*Throttle
+	// 待迁移表筛选，各目标端表兼容性规则不同，由目标端 reverse 模块提供
+	filter func(ctx context.Context) ([]string, error)
+}
+
+func NewOracleSource(cfg *config.Config, oracle *oracle.Oracle, metaDB *meta.Meta, filter func(ctx context.Context) ([]string, error)) (*OracleSource, error) {
+	throttle, err := NewThrottle(cfg.FullConfig.QPSLimit, cfg.FullConfig.BandwidthLimit, nil)
+	if err != nil {
+		return nil, err
+	}
+	tableThrottles := make(map[string]*Throttle)
+	for _, tl := range cfg.FullConfig.TableLimit {
+		tableThrottle, err := NewThrottle(tl.QPSLimit, tl.BandwidthLimit, throttle)
+		if err != nil {
+			return nil, err
+		}
+		tableThrottles[common.StringUPPER(tl.SourceTable)] = tableThrottle
+	}
+	return &OracleSource{
+		Cfg:            cfg,
+		Oracle:         oracle,
+		MetaDB:         metaDB,
+		Throttle:       throttle,
+		tableThrottles: tableThrottles,
+		filter:         filter,
+	}, nil
+}
+
+func (s *OracleSource) ListTables(ctx context.Context) ([]string, error) {
+	return s.filter(ctx)
+}
+
+// Snapshot 当前 SCN，存在备库读取时等待备库应用至该 SCN
+func (s *OracleSource) Snapshot(ctx context.Context) (uint64, error) {
+	globalSCN, err := s.Oracle.GetOracleCurrentSnapshotSCN()
+	if err != nil {
+		return globalSCN, err
+	}
+	if err = s.Oracle.WaitStandbyApplySCN(globalSCN); err != nil {
+		return globalSCN, err
+	}
+	return globalSCN, nil
+}
+
+// SplitChunks 基于 DBMS_PARALLEL_EXECUTE ROWID 切分，表无数据则整表单 chunk
+func (s *OracleSource) SplitChunks(ctx context.Context, table string) ([]string, error) {
+	sourceSchema := common.StringUPPER(s.Cfg.OracleConfig.SchemaName)
+	taskName := common.StringsBuilder(sourceSchema, `_`, table, `_`, `TASK`)
+
+	chunkRes, err := splitOracleTableChunksByRowID(s.Oracle, taskName, sourceSchema, table, s.Cfg.FullConfig.ChunkSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunkRes) == 0 {
+		return []string{"1 = 1"}, nil
+	}
+	var chunks []string
+	for _, c := range chunkRes {
+		chunks = append(chunks, c["CMD"])
+	}
+	return chunks, nil
+}
+
+func (s *OracleSource) ReadChunk(ctx context.Context, syncMeta meta.FullSyncMeta) migrate.Extractor {
+	return s.ReadTableChunk(ctx, syncMeta, 0)
+}
+
+// ReadTableChunk 单 chunk 抽取器，沿用 insert-batch-size、batch-bytes、表级限速以及 chunk 断点配置
+// lobBatchSize > 0 为 LOB 大字段表，按 LOB 批次行数抽取，不按字节自适应
+func (s *OracleSource) ReadTableChunk(ctx context.Context, syncMeta meta.FullSyncMeta, lobBatchSize int) *Table {
+	batchSize, batchBytes := s.Cfg.AppConfig.InsertBatchSize, s.Cfg.FullConfig.BatchBytes
+	if lobBatchSize > 0 {
+		batchSize, batchBytes = lobBatchSize, 0
+	}
+	t := NewTable(ctx, syncMeta, s.Oracle, batchSize, batchBytes, s.Cfg.FullConfig.ChunkCheckpoint, s.TableThrottle(syncMeta.TableNameS))
+	if s.Cfg.FullConfig.ChunkCheckpoint {
+		t.PinReadScn = s.PinChunkReadScn
+	}
+	return t
+}
+
+// TableThrottle 表级限速配置 [[full.table-limit]]，未配置表沿用全局限速
+func (s *OracleSource) TableThrottle(sourceTable string) *Throttle {
+	if t, ok := s.tableThrottles[common.StringUPPER(sourceTable)]; ok {
+		return t
+	}
+	return s.Throttle
+}
+
+// ReloadChunkCheckpoint 重试前读取 chunk 内最新已写入行数以及固定读取 SCN，跳过会话中断前已写入数据
+func (s *OracleSource) ReloadChunkCheckpoint(ctx context.Context, m *meta.FullSyncMeta) error {
+	metas, err := meta.NewFullSyncMetaModel(s.MetaDB).DetailFullSyncMeta(ctx, &meta.FullSyncMeta{
+		DBTypeS:         m.DBTypeS,
+		DBTypeT:         m.DBTypeT,
+		SchemaNameS:     m.SchemaNameS,
+		TableNameS:      m.TableNameS,
+		TaskMode:        m.TaskMode,
+		ChunkDetailS:    m.ChunkDetailS,
+		ChunkPartitionS: m.ChunkPartitionS,
+	})
+	if err != nil {
+		return err
+	}
+	if len(metas) > 0 {
+		m.RowOffset = metas[0].RowOffset
+		m.CheckpointScnS = metas[0].CheckpointScnS
+	}
+	return nil
+}
+
+// PinChunkReadScn chunk 首次抽取前记录固定读取 SCN，重试以及断点续传基于同一 SCN 闪回读取，保证 row_offset 跳过行一致
+// 快照组 chunk 已基于 global_scn_s 读取；已有 row_offset 但未记录 SCN 的历史断点不补记，由抽取端拒绝续传
+func (s *OracleSource) PinChunkReadScn(ctx context.Context, m *meta.FullSyncMeta) error {
+	if m.SnapshotGroup != "" || m.CheckpointScnS > 0 || m.RowOffset > 0 {
+		return nil
+	}
+	scn, err := s.Oracle.GetOracleCurrentSnapshotSCN()
+	if err != nil {
+		return err
+	}
+	if err = meta.NewFullSyncMetaModel(s.MetaDB).UpdateFullSyncMeta(ctx, &meta.FullSyncMeta{
+		DBTypeS:         m.DBTypeS,
+		DBTypeT:         m.DBTypeT,
+		SchemaNameS:     m.SchemaNameS,
+		TableNameS:      m.TableNameS,
+		TaskMode:        m.TaskMode,
+		ChunkDetailS:    m.ChunkDetailS,
+		ChunkPartitionS: m.ChunkPartitionS,
+	}, map[string]interface{}{
+		"CheckpointScnS": scn,
+	}); err != nil {
+		return err
+	}
+	m.CheckpointScnS = scn
+	return nil
+}
+
+// splitOracleTableChunksByRowID DBMS_PARALLEL_EXECUTE 按 ROWID 切分，需要 CREATE JOB 权限
+// 任务创建后无论成功失败均删除，避免残留任务阻塞重跑
+func splitOracleTableChunksByRowID(o *oracle.Oracle, taskName, schemaName, tableName string, chunkRows int) (chunkRes []map[string]string, err error) {
+	if err = o.StartOracleChunkCreateTask(taskName); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if errC := o.CloseOracleChunkTask(taskName); errC != nil && err == nil {
+			chunkRes, err = nil, errC
+		}
+	}()
+	if err = o.StartOracleCreateChunkByRowID(taskName, common.StringUPPER(schemaName), common.StringUPPER(tableName), strconv.Itoa(chunkRows)); err != nil {
+		return nil, err
+	}
+	return o.GetOracleTableChunksByRowID(taskName)
+}
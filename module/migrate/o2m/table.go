@@ -17,125 +17,242 @@ package o2m
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"github.com/wentaojin/transferdb/common"
 	"github.com/wentaojin/transferdb/database/meta"
 	"github.com/wentaojin/transferdb/database/mysql"
 	"github.com/wentaojin/transferdb/database/oracle"
+	"github.com/wentaojin/transferdb/metrics"
+	"github.com/wentaojin/transferdb/module/migrate"
 	"go.uber.org/zap"
-	"golang.org/x/sync/errgroup"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
 type Table struct {
-	Ctx       context.Context
-	SyncMeta  meta.FullSyncMeta
-	Oracle    *oracle.Oracle
-	BatchSize int
+	Ctx             context.Context
+	SyncMeta        meta.FullSyncMeta
+	Oracle          *oracle.Oracle
+	BatchSize       int
+	BatchBytes      int
+	ChunkCheckpoint bool
+	Throttle        *Throttle
+	// chunk 断点续读固定 SCN，未固定时抽取前记录
+	PinReadScn func(ctx context.Context, m *meta.FullSyncMeta) error
+	// chunk 实际抽取 SCN 以及时间，抽取完成后记录至 full_sync_meta
+	ExtractStartScnS uint64
+	ExtractEndScnS   uint64
+	ExtractStartTime time.Time
+	ExtractEndTime   time.Time
 }
 
 func NewTable(ctx context.Context, syncMeta meta.FullSyncMeta,
-	oracle *oracle.Oracle, batchSize int) *Table {
+	oracle *oracle.Oracle, batchSize, batchBytes int, chunkCheckpoint bool, throttle *Throttle) *Table {
 	return &Table{
-		Ctx:       ctx,
-		SyncMeta:  syncMeta,
-		Oracle:    oracle,
-		BatchSize: batchSize,
+		Ctx:             ctx,
+		SyncMeta:        syncMeta,
+		Oracle:          oracle,
+		BatchSize:       batchSize,
+		BatchBytes:      batchBytes,
+		ChunkCheckpoint: chunkCheckpoint,
+		Throttle:        throttle,
 	}
 }
 
-func (t *Table) GetTableRows() ([]string, []string, error) {
+// StreamTableRows 按 batch 推送 chunk 数据，游标读取与下游写入并行，不整体缓存 chunk
+func (t *Table) StreamTableRows(ctx context.Context, batchC chan<- migrate.Batch) error {
 	startTime := time.Now()
-	querySQL := common.StringsBuilder(`SELECT `, t.SyncMeta.ColumnDetailS, ` FROM `, t.SyncMeta.SchemaNameS, `.`, t.SyncMeta.TableNameS, ` WHERE `, t.SyncMeta.ChunkDetailS)
+	if t.ChunkCheckpoint && t.PinReadScn != nil {
+		if err := t.PinReadScn(ctx, &t.SyncMeta); err != nil {
+			return err
+		}
+	}
+	tableFrom := migrate.GenSnapshotTableFrom(t.SyncMeta.SchemaNameS, t.SyncMeta.TableNameS, t.SyncMeta.ChunkPartitionS, t.SyncMeta.SnapshotGroup, t.SyncMeta.GlobalScnS)
+	readScn := t.SyncMeta.GlobalScnS
+
+	// chunk 内断点，固定 SCN 闪回读取并按 ROWID 排序，保证重复抽取行集合以及顺序一致，row_offset 跳过才有效
+	if t.ChunkCheckpoint && t.SyncMeta.SnapshotGroup == "" {
+		if t.SyncMeta.CheckpointScnS == 0 {
+			if t.SyncMeta.RowOffset > 0 {
+				return fmt.Errorf("source schema table [%s.%s] chunk [%s] row offset [%d] isn't recorded with fixed read scn, refuse offset resume, please reset the chunk and truncate target rows before rerunning",
+					t.SyncMeta.SchemaNameS, t.SyncMeta.TableNameS, t.SyncMeta.ChunkDetailS, t.SyncMeta.RowOffset)
+			}
+			return fmt.Errorf("source schema table [%s.%s] chunk [%s] checkpoint read scn isn't pinned", t.SyncMeta.SchemaNameS, t.SyncMeta.TableNameS, t.SyncMeta.ChunkDetailS)
+		}
+		readScn = t.SyncMeta.CheckpointScnS
+		tableFrom = common.StringsBuilder(migrate.GenSnapshotTableFrom(t.SyncMeta.SchemaNameS, t.SyncMeta.TableNameS, t.SyncMeta.ChunkPartitionS, "", 0),
+			` AS OF SCN `, strconv.FormatUint(readScn, 10))
+	}
+	querySQL := common.StringsBuilder(`SELECT `, t.SyncMeta.ColumnDetailS, ` FROM `, tableFrom, ` WHERE `, t.SyncMeta.ChunkDetailS)
+	if t.ChunkCheckpoint {
+		querySQL = common.StringsBuilder(querySQL, ` ORDER BY ROWID`)
+	}
 
-	columnFields, rowResults, err := t.Oracle.GetOracleTableRowsData(querySQL, t.BatchSize)
+	// 跳过 chunk 已写入行数
+	skipOffset := int64(0)
+	if t.ChunkCheckpoint && t.SyncMeta.RowOffset > 0 {
+		skipOffset = t.SyncMeta.RowOffset
+		zap.L().Info("source schema table rowid data skip applied rows",
+			zap.String("schema", t.SyncMeta.SchemaNameS),
+			zap.String("table", t.SyncMeta.TableNameS),
+			zap.String("rowid", t.SyncMeta.ChunkDetailS),
+			zap.Int64("row offset", t.SyncMeta.RowOffset))
+	}
+
+	// 按目标字节数自适应 batch 行数，行数上限为 insert-batch-size 的 MigrateAdaptiveBatchMaxFactor 倍
+	var batcher *common.AdaptiveBatcher
+	if t.BatchBytes > 0 {
+		batcher = common.NewAdaptiveBatcher(t.BatchBytes, t.BatchSize*common.MigrateAdaptiveBatchMaxFactor)
+	}
+
+	var extractRows int64
+	t.ExtractStartScnS, t.ExtractStartTime = t.currentExtractScn(), time.Now()
+	batchStart := time.Now()
+	err := t.Oracle.StreamOracleTableRowsDataByBytes(ctx, querySQL, t.BatchSize, batcher, func(columns []string, rows []common.RowValue) error {
+		metrics.ExtractHistogram.WithLabelValues(common.StringUPPER(t.SyncMeta.TaskMode), t.SyncMeta.TableNameS).Observe(time.Since(batchStart).Seconds())
+		rows = skipRows(rows, &skipOffset)
+		if len(rows) == 0 {
+			batchStart = time.Now()
+			return nil
+		}
+		// 抽取限速，令牌不足阻塞游标读取
+		if err := t.Throttle.Wait(ctx, rows); err != nil {
+			return err
+		}
+		extractRows += int64(len(rows))
+		select {
+		case batchC <- migrate.Batch{Columns: columns, Rows: rows}:
+			batchStart = time.Now()
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
 	if err != nil {
-		return columnFields, rowResults, err
+		// 闪回查询 undo 被覆盖
+		if t.SyncMeta.SnapshotGroup != "" && strings.Contains(err.Error(), "ORA-01555") {
+			return fmt.Errorf("source schema table [%s.%s] snapshot group [%s] read AS OF SCN [%d] snapshot too old, please raise UNDO_RETENTION and undo tablespace to cover the whole full load duration, or disable [full] consistent-read: %v",
+				t.SyncMeta.SchemaNameS, t.SyncMeta.TableNameS, t.SyncMeta.SnapshotGroup, t.SyncMeta.GlobalScnS, err)
+		}
+		if t.ChunkCheckpoint && strings.Contains(err.Error(), "ORA-01555") {
+			return fmt.Errorf("source schema table [%s.%s] chunk [%s] checkpoint read AS OF SCN [%d] snapshot too old, please raise UNDO_RETENTION to cover the chunk resume interval: %v",
+				t.SyncMeta.SchemaNameS, t.SyncMeta.TableNameS, t.SyncMeta.ChunkDetailS, readScn, err)
+		}
+		return err
 	}
+	t.ExtractEndScnS, t.ExtractEndTime = t.currentExtractScn(), time.Now()
 
+	var avgRowBytes int64
+	if batcher != nil {
+		avgRowBytes = batcher.AvgRowBytes()
+	}
 	endTime := time.Now()
 	zap.L().Info("source schema table rowid data extractor finished",
 		zap.String("schema", t.SyncMeta.SchemaNameS),
 		zap.String("table", t.SyncMeta.TableNameS),
 		zap.String("rowid", t.SyncMeta.ChunkDetailS),
 		zap.String("sql", querySQL),
+		zap.Int64("rows", extractRows),
+		zap.Int64("avg encoded row bytes", avgRowBytes),
 		zap.String("cost", endTime.Sub(startTime).String()))
-	return columnFields, rowResults, nil
+	return nil
 }
 
 type Chunk struct {
-	Ctx           context.Context
-	SyncMeta      meta.FullSyncMeta
-	ApplyThreads  int
-	BatchSize     int
-	SafeMode      bool
-	MySQL         *mysql.MySQL
-	Oracle        *oracle.Oracle
-	MetaDB        *meta.Meta
-	SourceColumns []string
-	BatchResults  []string
+	Ctx               context.Context
+	SyncMeta          meta.FullSyncMeta
+	ApplyThreads      int
+	BatchSize         int
+	ApplyMode         string
+	ApplyProtocol     string
+	MySQL             *mysql.MySQL
+	Oracle            *oracle.Oracle
+	MetaDB            *meta.Meta
+	RetryTimes        int
+	RetryInterval     time.Duration
+	BisectRetry       bool
+	ChunkCheckpoint   bool
+	LOBBatchSize      int
+	MaxStatementBytes int
+	ColumnRewriter    *common.NameRewriter
+	// apply-protocol = PREPARE 整 batch 预编译语句，其余行数直接绑定参数执行
+	stmtMu sync.Mutex
+	stmt   *sql.Stmt
 }
 
 func NewChunk(ctx context.Context, syncMeta meta.FullSyncMeta,
 	oracle *oracle.Oracle, mysql *mysql.MySQL, metaDB *meta.Meta,
-	sourceColumns, batchResults []string, applyThreads, batchSize int, safeMode bool) *Chunk {
+	applyThreads, batchSize int, applyMode, applyProtocol string, retryTimes int, retryInterval time.Duration, bisectRetry, chunkCheckpoint bool, lobBatchSize, maxStatementBytes int, columnRewriter *common.NameRewriter) *Chunk {
 	return &Chunk{
-		Ctx:           ctx,
-		SyncMeta:      syncMeta,
-		ApplyThreads:  applyThreads,
-		BatchSize:     batchSize,
-		SafeMode:      safeMode,
-		MySQL:         mysql,
-		Oracle:        oracle,
-		MetaDB:        metaDB,
-		SourceColumns: sourceColumns,
-		BatchResults:  batchResults,
+		Ctx:               ctx,
+		SyncMeta:          syncMeta,
+		ApplyThreads:      applyThreads,
+		BatchSize:         batchSize,
+		ApplyMode:         applyMode,
+		ApplyProtocol:     applyProtocol,
+		MySQL:             mysql,
+		Oracle:            oracle,
+		MetaDB:            metaDB,
+		RetryTimes:        retryTimes,
+		RetryInterval:     retryInterval,
+		BisectRetry:       bisectRetry,
+		ChunkCheckpoint:   chunkCheckpoint,
+		LOBBatchSize:      lobBatchSize,
+		MaxStatementBytes: maxStatementBytes,
+		ColumnRewriter:    columnRewriter,
 	}
 }
 
-func (t *Chunk) TranslateTableRows() error {
-	if len(t.BatchResults) == 0 {
-		return nil
+// TranslateTableRows 数据行已在抽取阶段类型化，此处原样转发
+func (t *Chunk) TranslateTableRows(ctx context.Context, batchC <-chan migrate.Batch, applyC chan<- migrate.Batch) error {
+	for b := range batchC {
+		select {
+		case applyC <- b:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	}
 	return nil
 }
 
-func (t *Chunk) ApplyTableRows() error {
+func (t *Chunk) ApplyTableRows(ctx context.Context, applyC <-chan migrate.Batch) error {
 	startTime := time.Now()
 	zap.L().Info("target schema table rowid data applier start",
 		zap.String("schema", t.SyncMeta.SchemaNameT),
 		zap.String("table", t.SyncMeta.TableNameT),
 		zap.String("rowid", t.SyncMeta.ChunkDetailS))
 
-	if len(t.BatchResults) == 0 {
-		zap.L().Warn("oracle schema table rowid data return null rows, skip",
-			zap.String("schema", t.SyncMeta.SchemaNameS),
-			zap.String("table", t.SyncMeta.TableNameS),
-			zap.String("info", common.StringsBuilder(`SELECT `, t.SyncMeta.ColumnDetailS, ` FROM `, t.SyncMeta.SchemaNameS, `.`, t.SyncMeta.TableNameS, ` WHERE `, t.SyncMeta.ChunkDetailS)))
-		return nil
-	}
+	// 写入失败不调用 Finish，预编译语句统一在此释放
+	defer t.closePrepareStmts()
 
-	g := &errgroup.Group{}
-	g.SetLimit(t.ApplyThreads)
-	for _, result := range t.BatchResults {
-		valArgs := result
-		g.Go(func() error {
-			query := common.StringsBuilder(GenMySQLInsertSQLStmtPrefix(
-				t.SyncMeta.SchemaNameT,
-				t.SyncMeta.TableNameT,
-				t.SourceColumns,
-				t.SafeMode), valArgs)
-			err := t.MySQL.WriteMySQLTable(query)
-			if err != nil {
-				return fmt.Errorf("error on write db, sql: [%v], error: %v", query, err)
+	progress := newChunkProgress()
+	batches, err := migrate.ApplyBatches(ctx, t, applyC, t.ApplyThreads, migrate.ApplyHook{
+		Dispatch: progress.add,
+		Applied: func(batchIdx, rows int, cost time.Duration) error {
+			taskMode := common.StringUPPER(t.SyncMeta.TaskMode)
+			metrics.RowsCounter.WithLabelValues(taskMode, t.SyncMeta.SchemaNameT, t.SyncMeta.TableNameT).Add(float64(rows))
+			metrics.ApplyHistogram.WithLabelValues(taskMode, t.SyncMeta.TableNameT).Observe(cost.Seconds())
+			if t.ChunkCheckpoint {
+				return t.recordProgress(progress, batchIdx)
 			}
 			return nil
-		})
-	}
-	if err := g.Wait(); err != nil {
+		},
+	})
+	if err != nil {
 		return err
 	}
 
+	if batches == 0 {
+		zap.L().Warn("oracle schema table rowid data return null rows, skip",
+			zap.String("schema", t.SyncMeta.SchemaNameS),
+			zap.String("table", t.SyncMeta.TableNameS),
+			zap.String("info", common.StringsBuilder(`SELECT `, t.SyncMeta.ColumnDetailS, ` FROM `, migrate.GenSnapshotTableFrom(t.SyncMeta.SchemaNameS, t.SyncMeta.TableNameS, t.SyncMeta.ChunkPartitionS, "", 0), ` WHERE `, t.SyncMeta.ChunkDetailS)))
+		return nil
+	}
+
 	endTime := time.Now()
 	zap.L().Info("target schema table rowid data applier finished",
 		zap.String("schema", t.SyncMeta.SchemaNameT),
@@ -145,3 +262,96 @@ func (t *Chunk) ApplyTableRows() error {
 
 	return nil
 }
+
+// Prepare MySQL 目标端无需预处理
+func (t *Chunk) Prepare(ctx context.Context) error {
+	return nil
+}
+
+func (t *Chunk) ApplyBatch(ctx context.Context, columns []string, rows []common.RowValue) error {
+	return t.applyBatch(columns, rows)
+}
+
+// Finish 各 batch 独立提交，无需收尾
+func (t *Chunk) Finish(ctx context.Context) error {
+	return nil
+}
+
+// applyBatch 单 batch 写入目标端
+func (t *Chunk) applyBatch(sourceColumns []string, valArgs []common.RowValue) error {
+	// 源端查询字段已反引号包裹，去除后改写再包裹，保证锚定规则命中
+	targetColumns := t.ColumnRewriter.RewriteQuotedColumns(sourceColumns)
+	// LOB 大字段表预编译绑定参数写入
+	if t.LOBBatchSize > 0 {
+		return t.applyLOBRows(targetColumns, valArgs)
+	}
+	if t.ApplyProtocol == common.MigrateApplyProtocolPrepare {
+		return t.applyPrepareRows(targetColumns, valArgs)
+	}
+
+	prefixSQL, suffixSQL := GenMySQLApplySQLStmt(
+		t.SyncMeta.SchemaNameT,
+		t.SyncMeta.TableNameT,
+		targetColumns,
+		t.ApplyMode)
+	for _, stmt := range genBatchStmts(prefixSQL, suffixSQL, valArgs, t.MaxStatementBytes) {
+		if err := t.applyBatchStmt(prefixSQL, suffixSQL, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type batchStmt struct {
+	Query string
+	Rows  []common.RowValue
+}
+
+// genBatchStmts 按字节预算拆分 batch 拼接多条写入语句，单行超出预算时单独成句
+func genBatchStmts(prefixSQL, suffixSQL string, valArgs []common.RowValue, maxBytes int) []batchStmt {
+	var stmts []batchStmt
+
+	buf := common.GetBuffer()
+	rowBuf := common.GetBuffer()
+	defer common.PutBuffer(buf)
+	defer common.PutBuffer(rowBuf)
+
+	start := 0
+	buf.WriteString(prefixSQL)
+	for i, row := range valArgs {
+		rowBuf.Reset()
+		common.AppendMySQLRow(rowBuf, row)
+		if maxBytes > 0 && i > start && buf.Len()+1+rowBuf.Len()+len(suffixSQL) > maxBytes {
+			buf.WriteString(suffixSQL)
+			stmts = append(stmts, batchStmt{Query: buf.String(), Rows: valArgs[start:i]})
+			buf.Reset()
+			buf.WriteString(prefixSQL)
+			start = i
+		}
+		if i > start {
+			buf.WriteByte(',')
+		}
+		buf.Write(rowBuf.Bytes())
+	}
+	buf.WriteString(suffixSQL)
+	stmts = append(stmts, batchStmt{Query: buf.String(), Rows: valArgs[start:]})
+	return stmts
+}
+
+func (t *Chunk) applyBatchStmt(prefixSQL, suffixSQL string, stmt batchStmt) error {
+	query, valArgs := stmt.Query, stmt.Rows
+	err := t.MySQL.WriteMySQLTableWithFailover(query, t.RetryTimes, t.RetryInterval)
+	if err != nil {
+		// batch 二分重试，定位问题数据行
+		if t.BisectRetry {
+			zap.L().Warn("target schema table batch write failed, bisect retry",
+				zap.String("schema", t.SyncMeta.SchemaNameT),
+				zap.String("table", t.SyncMeta.TableNameT),
+				zap.String("rowid", t.SyncMeta.ChunkDetailS),
+				zap.Error(err))
+			return t.applyBatchBisect(prefixSQL, suffixSQL, valArgs)
+		}
+		return fmt.Errorf("error on write db, sql: [%v], error: %v", query, err)
+	}
+	return nil
+}
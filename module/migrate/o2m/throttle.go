@@ -0,0 +1,69 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+)
+
+// Throttle 抽取限速，行速率以及带宽令牌桶，表级限速与父级（全局）限速同时生效
+// 抽取受限后下游写入随流水线背压同步受限
+type Throttle struct {
+	Rows   *common.TokenBucket
+	Bytes  *common.TokenBucket
+	Parent *Throttle
+}
+
+// NewThrottle qpsLimit 每秒行数，bandwidthLimit 每秒字节数如 50MiB，均未配置返回 parent
+func NewThrottle(qpsLimit int, bandwidthLimit string, parent *Throttle) (*Throttle, error) {
+	var bandwidth float64
+	if bandwidthLimit != "" {
+		b, err := common.ParseStoreSize(bandwidthLimit)
+		if err != nil {
+			return nil, fmt.Errorf("parse bandwidth-limit [%s] failed: %v", bandwidthLimit, err)
+		}
+		bandwidth = b
+	}
+	if qpsLimit <= 0 && bandwidth <= 0 {
+		return parent, nil
+	}
+	return &Throttle{
+		Rows:   common.NewTokenBucket(float64(qpsLimit)),
+		Bytes:  common.NewTokenBucket(bandwidth),
+		Parent: parent,
+	}, nil
+}
+
+func (t *Throttle) Wait(ctx context.Context, rows []common.RowValue) error {
+	if t == nil || len(rows) == 0 {
+		return nil
+	}
+	if err := t.Rows.WaitN(ctx, float64(len(rows))); err != nil {
+		return err
+	}
+	if t.Bytes != nil {
+		var size int
+		for _, row := range rows {
+			size += common.RowValueBytes(row)
+		}
+		if err := t.Bytes.WaitN(ctx, float64(size)); err != nil {
+			return err
+		}
+	}
+	return t.Parent.Wait(ctx, rows)
+}
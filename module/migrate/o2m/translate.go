@@ -126,6 +126,26 @@ func GenMySQLInsertSQLStmtPrefix(targetSchemaName, targetTableName string, colum
 	return prefixSQL
 }
 
+// GenMySQLApplySQLStmt 按写入冲突处理方式生成 SQL 前缀以及后缀，后缀仅 ON DUPLICATE KEY UPDATE 非空
+func GenMySQLApplySQLStmt(targetSchemaName, targetTableName string, columns []string, applyMode string) (string, string) {
+	column := common.StringsBuilder(" (", strings.Join(columns, ","), ")")
+	switch applyMode {
+	case common.MigrateApplyModeInsert:
+		return common.StringsBuilder(`INSERT INTO `, targetSchemaName, ".", targetTableName, column, ` VALUES `), ""
+	case common.MigrateApplyModeInsertIgnore:
+		return common.StringsBuilder(`INSERT IGNORE INTO `, targetSchemaName, ".", targetTableName, column, ` VALUES `), ""
+	case common.MigrateApplyModeUpsert:
+		var updates []string
+		for _, c := range columns {
+			updates = append(updates, common.StringsBuilder(c, "=VALUES(", c, ")"))
+		}
+		return common.StringsBuilder(`INSERT INTO `, targetSchemaName, ".", targetTableName, column, ` VALUES `),
+			common.StringsBuilder(` ON DUPLICATE KEY UPDATE `, strings.Join(updates, ","))
+	default:
+		return common.StringsBuilder(`REPLACE INTO `, targetSchemaName, ".", targetTableName, column, ` VALUES `), ""
+	}
+}
+
 // SQL Prepare 语句
 func GenMySQLPrepareBindVarStmt(columns, bindVarBatch int) string {
 	var (
@@ -181,9 +201,20 @@ func translateAndAddOracleIncrRecord(dbTypeS, dbTypeT, taskMode, sourceSchema, s
 		// 比如：UPDATE MARVIN.MARVIN1 SET ID = 2 , NAME = 'marvin' WHERE ID = 2 AND NAME = 'pty'
 		// 比如: drop table marvin.marvin7
 		// 比如: truncate table marvin.marvin7
-		mysqlRedo, operationType, err := translateOracleToMySQLSQL(rows.SQLRedo, rows.SQLUndo, common.StringUPPER(rows.TargetSchema), common.StringUPPER(rows.TargetSchema))
-		if err != nil {
-			return err
+		var (
+			mysqlRedo     []string
+			operationType string
+			err           error
+		)
+		if len(rows.MySQLRedo) > 0 {
+			// ADD COLUMN/CREATE INDEX 已按 reverse 映射规则转换
+			mysqlRedo = rows.MySQLRedo
+			operationType = common.MigrateOperationDDL
+		} else {
+			mysqlRedo, operationType, err = translateOracleToMySQLSQL(rows.SQLRedo, rows.SQLUndo, common.StringUPPER(rows.TargetSchema), common.StringUPPER(rows.TargetSchema))
+			if err != nil {
+				return err
+			}
 		}
 
 		// 注册任务到 Job 队列
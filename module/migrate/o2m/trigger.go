@@ -0,0 +1,111 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+// initTriggerSyncMeta 全量开始前安装触发器捕获对象并初始化位点，与物化视图日志共用元数据以及消费流程
+func (r *Migrate) initTriggerSyncMeta(triggerTables []string) error {
+	tableNameRule, err := r.getTableNameRule()
+	if err != nil {
+		return err
+	}
+	schemaName := common.StringUPPER(r.Cfg.OracleConfig.SchemaName)
+
+	for _, t := range triggerTables {
+		pkINFO, err := r.Oracle.GetOracleSchemaTablePrimaryKey(schemaName, t)
+		if err != nil {
+			return err
+		}
+		if len(pkINFO) == 0 {
+			return fmt.Errorf("oracle schema [%s] table [%s] trigger capture need primary key, please remove it from config [trigger-tables]", schemaName, t)
+		}
+		objectID, err := r.Oracle.GetOracleTableObjectID(schemaName, t)
+		if err != nil {
+			return err
+		}
+		shadowTable, err := r.Oracle.CreateOracleTableChangeCapture(schemaName, common.StringUPPER(t), objectID, strings.Split(pkINFO[0]["COLUMN_LIST"], ","))
+		if err != nil {
+			return err
+		}
+		zap.L().Info("oracle table change capture trigger installed",
+			zap.String("schema", schemaName),
+			zap.String("table", t),
+			zap.String("shadow table", shadowTable))
+
+		targetTableName := common.StringUPPER(t)
+		if val, ok := tableNameRule[common.StringUPPER(t)]; ok {
+			targetTableName = val
+		}
+
+		if err = meta.NewMVLogSyncMetaModel(r.MetaDB).CreateMVLogSyncMeta(r.Ctx, &meta.MVLogSyncMeta{
+			DBTypeS:     r.Cfg.DBTypeS,
+			DBTypeT:     r.Cfg.DBTypeT,
+			SchemaNameS: schemaName,
+			TableNameS:  common.StringUPPER(t),
+			SchemaNameT: common.StringUPPER(r.Cfg.MySQLConfig.SchemaName),
+			TableNameT:  targetTableName,
+			CaptureMode: common.MigrateCaptureModeTrigger,
+			LogTableS:   shadowTable,
+			PrimaryKeyS: pkINFO[0]["COLUMN_LIST"],
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Uninstall 卸载 schema 下 transferdb 安装的触发器捕获对象，并清理对应元数据
+func (r *Migrate) Uninstall() error {
+	startTime := time.Now()
+	schemaName := common.StringUPPER(r.Cfg.OracleConfig.SchemaName)
+
+	syncMetas, err := meta.NewMVLogSyncMetaModel(r.MetaDB).DetailMVLogSyncMetaBySchema(r.Ctx, &meta.MVLogSyncMeta{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: schemaName,
+	})
+	if err != nil {
+		return err
+	}
+
+	var uninstallTables []string
+	for _, m := range syncMetas {
+		if m.CaptureMode != common.MigrateCaptureModeTrigger {
+			continue
+		}
+		if err = r.Oracle.DropOracleTableChangeCapture(m.SchemaNameS, m.LogTableS); err != nil {
+			return err
+		}
+		if err = meta.NewMVLogSyncMetaModel(r.MetaDB).DeleteMVLogSyncMeta(r.Ctx, &m); err != nil {
+			return err
+		}
+		uninstallTables = append(uninstallTables, m.TableNameS)
+	}
+
+	zap.L().Info("oracle table change capture trigger uninstalled",
+		zap.String("schema", schemaName),
+		zap.Strings("tables", uninstallTables),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
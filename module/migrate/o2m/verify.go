@@ -0,0 +1,126 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/database/mysql"
+	"github.com/wentaojin/transferdb/database/oracle"
+	"github.com/wentaojin/transferdb/module/migrate"
+	"go.uber.org/zap"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Verify 全量 chunk 写入完成后抽样校验
+type Verify struct {
+	Ctx           context.Context
+	SyncMeta      meta.FullSyncMeta
+	Oracle        *oracle.Oracle
+	MySQL         *mysql.MySQL
+	SamplePercent int
+	SampleRows    int
+	// chunk 抽取 SCN，抽样数据基于该 SCN 闪回查询，避免源端后续变更误判
+	LoadScnS       uint64
+	ColumnRewriter *common.NameRewriter
+}
+
+func NewVerify(ctx context.Context, syncMeta meta.FullSyncMeta,
+	oracle *oracle.Oracle, mysql *mysql.MySQL, samplePercent, sampleRows int, loadScnS uint64, columnRewriter *common.NameRewriter) *Verify {
+	return &Verify{
+		Ctx:            ctx,
+		SyncMeta:       syncMeta,
+		Oracle:         oracle,
+		MySQL:          mysql,
+		SamplePercent:  samplePercent,
+		SampleRows:     sampleRows,
+		LoadScnS:       loadScnS,
+		ColumnRewriter: columnRewriter,
+	}
+}
+
+// IsSampled 按照 verify-chunk-percent 百分比判断当前 chunk 是否需要抽样校验
+func (v *Verify) IsSampled() bool {
+	if v.SamplePercent <= 0 || v.SampleRows <= 0 {
+		return false
+	}
+	if v.SamplePercent >= 100 {
+		return true
+	}
+	return rand.Intn(100) < v.SamplePercent
+}
+
+// VerifyTableRows 重新读取上游 chunk 抽样数据，逐行判断下游是否存在相同数据
+func (v *Verify) VerifyTableRows() error {
+	startTime := time.Now()
+
+	// 抽取 SCN 获取失败时退化为当前数据抽样
+	tableFrom := migrate.GenSnapshotTableFrom(v.SyncMeta.SchemaNameS, v.SyncMeta.TableNameS, v.SyncMeta.ChunkPartitionS, "", 0)
+	if v.LoadScnS > 0 {
+		tableFrom = common.StringsBuilder(tableFrom, ` AS OF SCN `, strconv.FormatUint(v.LoadScnS, 10))
+	}
+	cols, sampleRows, err := v.Oracle.GetOracleTableChunkSampleRows(tableFrom, v.SyncMeta.ColumnDetailS, v.SyncMeta.ChunkDetailS, v.SampleRows)
+	if err != nil {
+		return err
+	}
+
+	var diffRows []string
+	for _, row := range sampleRows {
+		var conds []string
+		for _, col := range cols {
+			targetCol, _, _ := v.ColumnRewriter.Rewrite(col)
+			// Oracle 空字符串与 NULL 统一 NULL 处理，与数据写入保持一致
+			if row[col] == "NULLABLE" || row[col] == "" {
+				conds = append(conds, common.StringsBuilder("`", targetCol, "` IS NULL"))
+			} else {
+				conds = append(conds, common.StringsBuilder("`", targetCol, "` = '", common.SpecialLettersUsingMySQL([]byte(row[col])), "'"))
+			}
+		}
+		querySQL := common.StringsBuilder("SELECT COUNT(1) FROM `", v.SyncMeta.SchemaNameT, "`.`", v.SyncMeta.TableNameT, "` WHERE ", strings.Join(conds, " AND "))
+
+		rows, err := v.MySQL.GetMySQLTableActualRows(querySQL)
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			diffRows = append(diffRows, querySQL)
+		}
+	}
+
+	if len(diffRows) > 0 {
+		zap.L().Error("target schema table chunk spot verify failed",
+			zap.String("schema", v.SyncMeta.SchemaNameT),
+			zap.String("table", v.SyncMeta.TableNameT),
+			zap.String("rowid", v.SyncMeta.ChunkDetailS),
+			zap.Int("sample rows", len(sampleRows)),
+			zap.Int("diff rows", len(diffRows)),
+			zap.Strings("diff sql", diffRows))
+		return fmt.Errorf("chunk spot verify failed, sample rows [%d] diff rows [%d], first diff sql: [%s]", len(sampleRows), len(diffRows), diffRows[0])
+	}
+
+	zap.L().Info("target schema table chunk spot verify finished",
+		zap.String("schema", v.SyncMeta.SchemaNameT),
+		zap.String("table", v.SyncMeta.TableNameT),
+		zap.String("rowid", v.SyncMeta.ChunkDetailS),
+		zap.Int("sample rows", len(sampleRows)),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
@@ -0,0 +1,172 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package migrate
+
+import (
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/oracle"
+	"go.uber.org/zap"
+	"sort"
+	"strconv"
+)
+
+// SnapshotGroupSCN 表所属一致性快照组以及快照组 SCN
+type SnapshotGroupSCN struct {
+	Group string
+	SCN   uint64
+}
+
+// GenSnapshotGroupSCN 划分一致性快照组，每组获取一次 SCN，返回表名 -> 快照组
+// 手工配置快照组与外键关联表（group-by-fk）取并集合并，仅涉及本次待同步表
+func GenSnapshotGroupSCN(cfg *config.Config, o *oracle.Oracle, tables []string) (map[string]SnapshotGroupSCN, error) {
+	groupSCN := make(map[string]SnapshotGroupSCN)
+	if len(cfg.SnapshotConfig.SnapshotGroups) == 0 && !cfg.SnapshotConfig.GroupByFK {
+		return groupSCN, nil
+	}
+
+	parent := make(map[string]string)
+	for _, t := range tables {
+		parent[common.StringUPPER(t)] = common.StringUPPER(t)
+	}
+	var find func(t string) string
+	find = func(t string) string {
+		if parent[t] != t {
+			parent[t] = find(parent[t])
+		}
+		return parent[t]
+	}
+	union := func(a, b string) {
+		_, okA := parent[a]
+		_, okB := parent[b]
+		if !okA || !okB {
+			return
+		}
+		ra, rb := find(a), find(b)
+		if ra == rb {
+			return
+		}
+		// 字典序较小表作为根，保证组名稳定
+		if ra < rb {
+			parent[rb] = ra
+		} else {
+			parent[ra] = rb
+		}
+	}
+
+	// 表 -> 手工配置组名
+	configGroup := make(map[string]string)
+	for _, g := range cfg.SnapshotConfig.SnapshotGroups {
+		var first string
+		for _, t := range g.Tables {
+			if _, ok := parent[t]; !ok {
+				continue
+			}
+			configGroup[t] = g.Name
+			if first == "" {
+				first = t
+				continue
+			}
+			union(first, t)
+		}
+	}
+
+	if cfg.SnapshotConfig.GroupByFK {
+		pairs, err := o.GetOracleSchemaForeignKeyTablePairs(cfg.OracleConfig.SchemaName)
+		if err != nil {
+			return groupSCN, err
+		}
+		for _, p := range pairs {
+			union(common.StringUPPER(p["TABLE_NAME"]), common.StringUPPER(p["RTABLE_NAME"]))
+		}
+	}
+
+	members := make(map[string][]string)
+	for t := range parent {
+		root := find(t)
+		members[root] = append(members[root], t)
+	}
+
+	var roots []string
+	for root := range members {
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+
+	for _, root := range roots {
+		tbls := members[root]
+		sort.Strings(tbls)
+
+		// 组名优先使用手工配置组名，外键划分组以 FK_ + 根表命名
+		var groupName string
+		for _, t := range tbls {
+			if name, ok := configGroup[t]; ok {
+				groupName = name
+				break
+			}
+		}
+		if groupName == "" {
+			if len(tbls) < 2 {
+				continue
+			}
+			groupName = common.StringsBuilder("FK_", root)
+		}
+
+		scn, err := o.GetOracleCurrentSnapshotSCN()
+		if err != nil {
+			return groupSCN, err
+		}
+		if err = o.WaitStandbyApplySCN(scn); err != nil {
+			return groupSCN, err
+		}
+		for _, t := range tbls {
+			groupSCN[t] = SnapshotGroupSCN{Group: groupName, SCN: scn}
+		}
+		zap.L().Info("source schema table snapshot group",
+			zap.String("schema", cfg.OracleConfig.SchemaName),
+			zap.String("group", groupName),
+			zap.Strings("tables", tbls),
+			zap.Uint64("scn", scn))
+	}
+	return groupSCN, nil
+}
+
+// GenGlobalSnapshotGroup 未归属快照组的表统一归入全局快照组，全部 chunk 基于 globalSCN 闪回查询，保证全量数据事务一致
+func GenGlobalSnapshotGroup(groupSCN map[string]SnapshotGroupSCN, tables []string, globalSCN uint64) map[string]SnapshotGroupSCN {
+	if groupSCN == nil {
+		groupSCN = make(map[string]SnapshotGroupSCN)
+	}
+	for _, t := range tables {
+		if _, ok := groupSCN[common.StringUPPER(t)]; ok {
+			continue
+		}
+		groupSCN[common.StringUPPER(t)] = SnapshotGroupSCN{Group: common.MigrateSnapshotGroupGlobal, SCN: globalSCN}
+	}
+	return groupSCN
+}
+
+// GenSnapshotTableFrom 查询 FROM 表，分区 chunk 追加分区扩展子句，快照组表基于快照组 SCN 闪回查询
+// Oracle 语法要求分区扩展子句位于 AS OF 之前
+func GenSnapshotTableFrom(schemaName, tableName, chunkPartition, snapshotGroup string, scn uint64) string {
+	tableFrom := common.StringsBuilder(schemaName, `.`, tableName)
+	if chunkPartition != "" {
+		tableFrom = common.StringsBuilder(tableFrom, ` `, chunkPartition)
+	}
+	if snapshotGroup == "" {
+		return tableFrom
+	}
+	return common.StringsBuilder(tableFrom, ` AS OF SCN `, strconv.FormatUint(scn, 10))
+}
@@ -0,0 +1,76 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"golang.org/x/sync/errgroup"
+	"time"
+)
+
+// ApplyHook chunk 应用过程回调，字段均可为空
+type ApplyHook struct {
+	// Dispatch batch 按到达顺序派发前调用，返回 batch 序号
+	Dispatch func(rows int) int
+	// Applied batch 写入成功后调用
+	Applied func(batchIdx, rows int, cost time.Duration) error
+}
+
+// ApplyBatches 通用 chunk 应用编排：Prepare -> 按 batch 并发 ApplyBatch -> Finish
+// 任一 batch 写入失败停止派发，返回已派发 batch 数
+func ApplyBatches(ctx context.Context, w TargetWriter, applyC <-chan Batch, threads int, hook ApplyHook) (int, error) {
+	if err := w.Prepare(ctx); err != nil {
+		return 0, fmt.Errorf("target writer prepare failed: %v", err)
+	}
+
+	batches := 0
+	g, gCtx := errgroup.WithContext(ctx)
+	if threads > 0 {
+		g.SetLimit(threads)
+	}
+	for b := range applyC {
+		// 已有 batch 写入失败，停止派发
+		if gCtx.Err() != nil {
+			break
+		}
+		batchIdx := batches
+		if hook.Dispatch != nil {
+			batchIdx = hook.Dispatch(len(b.Rows))
+		}
+		batches++
+		columns := b.Columns
+		rows := b.Rows
+		g.Go(func() error {
+			batchStart := time.Now()
+			if err := w.ApplyBatch(gCtx, columns, rows); err != nil {
+				return err
+			}
+			if hook.Applied != nil {
+				return hook.Applied(batchIdx, len(rows), time.Since(batchStart))
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return batches, err
+	}
+
+	if err := w.Finish(ctx); err != nil {
+		return batches, fmt.Errorf("target writer finish failed: %v", err)
+	}
+	return batches, nil
+}
@@ -0,0 +1,166 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package db2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"strconv"
+	"strings"
+)
+
+// DB2 字符类型 FOR BIT DATA 字段 CODEPAGE 为 0
+const db2BitDataCodepage = "0"
+
+// DB2TableColumnMapMySQLRule 字段类型映射
+// 1、CHAR/VARCHAR FOR BIT DATA 映射 BINARY/VARBINARY，GRAPHIC 系列长度为字符数
+// 2、VARCHAR 超过 16383（utf8mb4 单行上限）映射 TEXT，CLOB/BLOB/DBCLOB 按长度映射 TEXT/MEDIUMTEXT/LONGTEXT 以及 BLOB 系列
+// 3、TIMESTAMP 精度最大 12，MySQL DATETIME 最大 6
+// 4、DECFLOAT 映射 DECIMAL(65,30)，XML 映射 LONGTEXT，用户自定义类型不支持
+func DB2TableColumnMapMySQLRule(columnName string, column map[string]string) (string, error) {
+	if column["TYPE_SCHEMA"] != "" && !strings.EqualFold(column["TYPE_SCHEMA"], "SYSIBM") {
+		return "", fmt.Errorf("column [%s] user-defined datatype [%s.%s] isn't support mysql", columnName, column["TYPE_SCHEMA"], column["DATA_TYPE"])
+	}
+	dataType := common.StringUPPER(column["DATA_TYPE"])
+	dataLength, err := strconv.ParseInt(column["DATA_LENGTH"], 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("column [%s] data_length [%s] parse failed: %v", columnName, column["DATA_LENGTH"], err)
+	}
+	dataScale, err := strconv.Atoi(column["DATA_SCALE"])
+	if err != nil {
+		return "", fmt.Errorf("column [%s] data_scale [%s] parse failed: %v", columnName, column["DATA_SCALE"], err)
+	}
+	bitData := column["CODEPAGE"] == db2BitDataCodepage
+
+	switch dataType {
+	case "SMALLINT":
+		return "SMALLINT", nil
+	case "INTEGER":
+		return "INT", nil
+	case "BIGINT":
+		return "BIGINT", nil
+	case "DECIMAL":
+		return fmt.Sprintf("DECIMAL(%d,%d)", dataLength, dataScale), nil
+	case "DECFLOAT":
+		return "DECIMAL(65,30)", nil
+	case "REAL":
+		return "FLOAT", nil
+	case "DOUBLE":
+		return "DOUBLE", nil
+	case "BOOLEAN":
+		return "TINYINT(1)", nil
+	case "CHARACTER":
+		if bitData {
+			return fmt.Sprintf("BINARY(%d)", dataLength), nil
+		}
+		return fmt.Sprintf("CHAR(%d)", dataLength), nil
+	case "VARCHAR":
+		if bitData {
+			return fmt.Sprintf("VARBINARY(%d)", dataLength), nil
+		}
+		if dataLength > 16383 {
+			return "TEXT", nil
+		}
+		return fmt.Sprintf("VARCHAR(%d)", dataLength), nil
+	case "LONG VARCHAR":
+		if bitData {
+			return "BLOB", nil
+		}
+		return "TEXT", nil
+	case "GRAPHIC":
+		return fmt.Sprintf("CHAR(%d)", dataLength), nil
+	case "VARGRAPHIC":
+		if dataLength > 16383 {
+			return "TEXT", nil
+		}
+		return fmt.Sprintf("VARCHAR(%d)", dataLength), nil
+	case "LONG VARGRAPHIC":
+		return "TEXT", nil
+	case "BINARY":
+		return fmt.Sprintf("BINARY(%d)", dataLength), nil
+	case "VARBINARY":
+		return fmt.Sprintf("VARBINARY(%d)", dataLength), nil
+	case "CLOB", "DBCLOB":
+		// DBCLOB 长度为双字节字符数
+		if dataType == "DBCLOB" {
+			dataLength = dataLength * 2
+		}
+		switch {
+		case dataLength <= 65535:
+			return "TEXT", nil
+		case dataLength <= 16777215:
+			return "MEDIUMTEXT", nil
+		default:
+			return "LONGTEXT", nil
+		}
+	case "BLOB":
+		switch {
+		case dataLength <= 65535:
+			return "BLOB", nil
+		case dataLength <= 16777215:
+			return "MEDIUMBLOB", nil
+		default:
+			return "LONGBLOB", nil
+		}
+	case "DATE":
+		return "DATE", nil
+	case "TIME":
+		return "TIME", nil
+	case "TIMESTAMP":
+		if dataScale > 6 {
+			dataScale = 6
+		}
+		if dataScale == 0 {
+			return "DATETIME", nil
+		}
+		return fmt.Sprintf("DATETIME(%d)", dataScale), nil
+	case "XML":
+		return "LONGTEXT", nil
+	default:
+		return "", fmt.Errorf("column [%s] datatype [%s] isn't support mysql", columnName, dataType)
+	}
+}
+
+// DB2ColumnDefaultMapMySQLRule 字段默认值映射，特殊寄存器转换为 MySQL 函数，无法转换返回 false
+func DB2ColumnDefaultMapMySQLRule(dataDefault, columnType string) (string, bool) {
+	dataDefault = strings.TrimSpace(dataDefault)
+	switch common.StringUPPER(dataDefault) {
+	case "CURRENT TIMESTAMP", "CURRENT_TIMESTAMP":
+		// DATETIME(n) 默认值精度需与字段精度一致
+		if strings.HasPrefix(columnType, "DATETIME(") {
+			return common.StringsBuilder("CURRENT_TIMESTAMP", strings.TrimPrefix(columnType, "DATETIME")), true
+		}
+		if columnType == "DATETIME" {
+			return "CURRENT_TIMESTAMP", true
+		}
+		return "", false
+	case "CURRENT DATE", "CURRENT_DATE":
+		return "(CURRENT_DATE)", true
+	case "CURRENT TIME", "CURRENT_TIME":
+		return "(CURRENT_TIME)", true
+	case "NULL":
+		return "NULL", true
+	}
+	if strings.HasPrefix(common.StringUPPER(dataDefault), "CURRENT ") || strings.HasPrefix(common.StringUPPER(dataDefault), "SESSION_USER") ||
+		strings.HasPrefix(common.StringUPPER(dataDefault), "USER") {
+		return "", false
+	}
+	// 大字段 MySQL 8.0.13 以下不支持字面量默认值
+	if strings.HasSuffix(columnType, "TEXT") || strings.HasSuffix(columnType, "BLOB") {
+		return "", false
+	}
+	return dataDefault, true
+}
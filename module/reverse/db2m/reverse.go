@@ -0,0 +1,172 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package db2m
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/db2"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/database/mysql"
+	"github.com/wentaojin/transferdb/module/reverse"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+type Reverse struct {
+	Ctx    context.Context
+	Cfg    *config.Config
+	DB2    *db2.DB2
+	MySQL  *mysql.MySQL
+	MetaDB *meta.Meta
+}
+
+func NewReverse(ctx context.Context, cfg *config.Config) (*Reverse, error) {
+	db2DB, err := db2.NewDB2DBEngine(ctx, cfg.DB2Config)
+	if err != nil {
+		return nil, err
+	}
+	mysqlDB, err := mysql.NewMySQLDBEngine(ctx, cfg.MySQLConfig)
+	if err != nil {
+		return nil, err
+	}
+	metaDB, err := meta.NewMetaDBEngine(ctx, cfg.MySQLConfig, cfg.AppConfig.SlowlogThreshold)
+	if err != nil {
+		return nil, err
+	}
+	return &Reverse{
+		Ctx:    ctx,
+		Cfg:    cfg,
+		DB2:    db2DB,
+		MySQL:  mysqlDB,
+		MetaDB: metaDB,
+	}, nil
+}
+
+func (r *Reverse) Reverse() error {
+	startTime := time.Now()
+	zap.L().Info("reverse table db2 to mysql start",
+		zap.String("schema", r.Cfg.DB2Config.SchemaName))
+
+	exporters, err := FilterCFGTable(r.Ctx, r.Cfg, r.DB2, r.MetaDB)
+	if err != nil {
+		return err
+	}
+
+	db2DBVersion, err := r.DB2.GetDB2DBVersion()
+	if err != nil {
+		return err
+	}
+	zap.L().Info("db2 db version", zap.String("version", db2DBVersion))
+
+	tableNameRule, err := GetTableNameRule(r.Ctx, r.Cfg, r.DB2, r.MetaDB)
+	if err != nil {
+		return err
+	}
+	columnRewriter, err := r.Cfg.RewriteConfig.ColumnRewriter()
+	if err != nil {
+		return err
+	}
+
+	f, err := reverse.NewWriter(r.Cfg, r.MySQL, nil)
+	if err != nil {
+		return err
+	}
+
+	sourceSchema := common.StringUPPER(r.Cfg.DB2Config.SchemaName)
+	targetSchema := common.StringUPPER(r.Cfg.MySQLConfig.SchemaName)
+
+	if err = r.genCreateSchema(f, targetSchema); err != nil {
+		return err
+	}
+
+	var failedTables int64
+	g := &errgroup.Group{}
+	g.SetLimit(r.Cfg.ReverseConfig.ReverseThreads)
+
+	for _, table := range exporters {
+		t := &Table{
+			SourceSchemaName: sourceSchema,
+			SourceTableName:  table,
+			TargetSchemaName: targetSchema,
+			TargetTableName:  table,
+			Overwrite:        r.Cfg.MySQLConfig.Overwrite,
+			ColumnRewriter:   columnRewriter,
+			DB2:              r.DB2,
+		}
+		if val, ok := tableNameRule[table]; ok {
+			t.TargetTableName = val
+		}
+		g.Go(func() error {
+			ddl, err := t.GenCreateTableDDL()
+			if err == nil {
+				err = ddl.Write(f, t.Overwrite)
+			}
+			if err != nil {
+				atomic.AddInt64(&failedTables, 1)
+				if errL := meta.NewErrorLogDetailModel(r.MetaDB).CreateErrorLog(r.Ctx, &meta.ErrorLogDetail{
+					DBTypeS:     r.Cfg.DBTypeS,
+					DBTypeT:     r.Cfg.DBTypeT,
+					SchemaNameS: t.SourceSchemaName,
+					TableNameS:  t.SourceTableName,
+					SchemaNameT: t.TargetSchemaName,
+					TableNameT:  t.TargetTableName,
+					TaskMode:    r.Cfg.TaskMode,
+					TaskStatus:  common.TaskStatusFailed,
+					InfoDetail:  t.String(),
+					ErrorDetail: err.Error(),
+				}); errL != nil {
+					return fmt.Errorf("reverse table db2 to mysql [%s.%s] failed: %v, record error log failed: %v", t.SourceSchemaName, t.SourceTableName, err, errL)
+				}
+				zap.L().Warn("reverse table db2 to mysql failed, detail see [error_log_detail]",
+					zap.String("schema", t.SourceSchemaName),
+					zap.String("table", t.SourceTableName),
+					zap.Error(err))
+			}
+			return nil
+		})
+	}
+	if err = g.Wait(); err != nil {
+		return err
+	}
+
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	zap.L().Info("reverse table db2 to mysql finished",
+		zap.String("schema", r.Cfg.DB2Config.SchemaName),
+		zap.Int("table totals", len(exporters)),
+		zap.Int64("table failed", failedTables),
+		zap.String("reverse dir", r.Cfg.ReverseConfig.DDLReverseDir),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
+
+// genCreateSchema 目标库不存在时创建，字符集统一 utf8mb4
+func (r *Reverse) genCreateSchema(f *reverse.Write, targetSchema string) error {
+	createSQL := fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s` DEFAULT CHARACTER SET %s COLLATE utf8mb4_bin", targetSchema, strings.ToLower(common.MySQLCharacterSet))
+	if !r.Cfg.ReverseConfig.DirectWrite {
+		_, err := f.RWriteFile(common.StringsBuilder(createSQL, ";\n\n"))
+		return err
+	}
+	return f.RWriteDB(createSQL)
+}
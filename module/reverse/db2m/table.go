@@ -0,0 +1,321 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package db2m
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/db2"
+	"github.com/wentaojin/transferdb/module/reverse"
+	"strings"
+)
+
+type Table struct {
+	SourceSchemaName string `json:"source_schema_name"`
+	SourceTableName  string `json:"source_table_name"`
+	TargetSchemaName string `json:"target_schema_name"`
+	TargetTableName  string `json:"target_table_name"`
+	Overwrite        bool   `json:"overwrite"`
+
+	ColumnRewriter *common.NameRewriter `json:"-"`
+	DB2            *db2.DB2             `json:"-"`
+}
+
+type DDL struct {
+	SourceSchemaName string
+	SourceTableName  string
+	TargetSchemaName string
+	TargetTableName  string
+	// 源端数据/索引/大字段表空间，MySQL 无对应概念，仅输出注释
+	Tablespace     string
+	TableCreateSQL string
+	IndexCreateSQL []string
+	ConstraintSQL  []string
+	// MySQL 不兼容对象，输出至 compatibility 文件人工处理
+	Incompatibility []string
+}
+
+func (t *Table) columnName(sourceColumn string) string {
+	if targetColumn, _, ok := t.ColumnRewriter.Rewrite(sourceColumn); ok {
+		return targetColumn
+	}
+	return sourceColumn
+}
+
+func (t *Table) quoteColumnList(columnList string) string {
+	var cols []string
+	for _, c := range strings.Split(columnList, ",") {
+		cols = append(cols, common.StringsBuilder("`", t.columnName(c), "`"))
+	}
+	return strings.Join(cols, ",")
+}
+
+// quoteIndexColumns COLNAMES 形如 +COL1-COL2，+ 升序 - 降序
+func (t *Table) quoteIndexColumns(colNames string) string {
+	var (
+		cols []string
+		sb   strings.Builder
+		desc bool
+	)
+	flush := func() {
+		if sb.Len() == 0 {
+			return
+		}
+		col := common.StringsBuilder("`", t.columnName(sb.String()), "`")
+		if desc {
+			col = common.StringsBuilder(col, " DESC")
+		}
+		cols = append(cols, col)
+		sb.Reset()
+	}
+	for _, r := range strings.TrimSpace(colNames) {
+		switch r {
+		case '+', '-':
+			flush()
+			desc = r == '-'
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	flush()
+	return strings.Join(cols, ",")
+}
+
+func (t *Table) fullTableName() string {
+	return common.StringsBuilder("`", t.TargetSchemaName, "`.`", t.TargetTableName, "`")
+}
+
+func (t *Table) GenCreateTableDDL() (*DDL, error) {
+	ddl := &DDL{
+		SourceSchemaName: t.SourceSchemaName,
+		SourceTableName:  t.SourceTableName,
+		TargetSchemaName: t.TargetSchemaName,
+		TargetTableName:  t.TargetTableName,
+	}
+
+	detail, err := t.DB2.GetDB2SchemaTableDetail(t.SourceSchemaName, t.SourceTableName)
+	if err != nil {
+		return nil, err
+	}
+	ddl.Tablespace = fmt.Sprintf("data [%s] index [%s] long [%s]", detail["TBSPACE"], detail["INDEX_TBSPACE"], detail["LONG_TBSPACE"])
+
+	columns, err := t.DB2.GetDB2SchemaTableColumn(t.SourceSchemaName, t.SourceTableName)
+	if err != nil {
+		return nil, err
+	}
+	identities, err := t.DB2.GetDB2SchemaTableIdentityColumn(t.SourceSchemaName, t.SourceTableName)
+	if err != nil {
+		return nil, err
+	}
+	identityCols := make(map[string]map[string]string)
+	for _, i := range identities {
+		identityCols[i["COLUMN_NAME"]] = i
+	}
+
+	var (
+		tableColumns   []string
+		targetCols     []string
+		identityColumn string
+		autoIncrement  string
+	)
+	for _, c := range columns {
+		datatype, err := DB2TableColumnMapMySQLRule(c["COLUMN_NAME"], c)
+		if err != nil {
+			return nil, fmt.Errorf("db2 table [%s.%s] %v", t.SourceSchemaName, t.SourceTableName, err)
+		}
+		targetColumn := t.columnName(c["COLUMN_NAME"])
+		if common.IsContainString(targetCols, targetColumn) {
+			return nil, fmt.Errorf("db2 table [%s.%s] column [%s] rewrite target column [%s] conflict", t.SourceSchemaName, t.SourceTableName, c["COLUMN_NAME"], targetColumn)
+		}
+
+		// 表达式生成列语法差异较大，跳过建表，原表达式输出至不兼容性文件
+		if c["GENERATED"] == "A" && c["IDENTITY"] != "Y" && c["ROWCHANGETIMESTAMP"] != "Y" {
+			ddl.Incompatibility = append(ddl.Incompatibility, fmt.Sprintf("-- db2 table [%s.%s] generated column [%s] expression [%s] isn't support, please manual add column",
+				t.SourceSchemaName, t.SourceTableName, c["COLUMN_NAME"], strings.TrimSpace(c["GENERATION_EXPRESSION"])))
+			continue
+		}
+		targetCols = append(targetCols, targetColumn)
+
+		var sb strings.Builder
+		sb.WriteString(common.StringsBuilder("`", targetColumn, "` ", datatype))
+		if strings.EqualFold(c["NULLABLE"], "N") {
+			sb.WriteString(" NOT NULL")
+		}
+
+		switch {
+		case c["IDENTITY"] == "Y":
+			// 标识列转换为自增列，AUTO_INCREMENT 起始值取下一个待分配值
+			sb.WriteString(" AUTO_INCREMENT")
+			identityColumn = targetColumn
+			if i, ok := identityCols[c["COLUMN_NAME"]]; ok {
+				autoIncrement = strings.TrimSpace(i["NEXTCACHEFIRSTVALUE"])
+				if increment := strings.TrimSpace(i["INCREMENT"]); increment != "1" {
+					ddl.Incompatibility = append(ddl.Incompatibility, fmt.Sprintf("-- db2 table [%s.%s] identity column [%s] increment [%s] isn't support, mysql auto_increment_increment is instance level",
+						t.SourceSchemaName, t.SourceTableName, c["COLUMN_NAME"], increment))
+				}
+			}
+		case c["ROWCHANGETIMESTAMP"] == "Y":
+			// 行更新时间戳列
+			precision := strings.TrimPrefix(datatype, "DATETIME")
+			sb.WriteString(common.StringsBuilder(" DEFAULT CURRENT_TIMESTAMP", precision, " ON UPDATE CURRENT_TIMESTAMP", precision))
+		case c["DATA_DEFAULT"] != "NULLABLE" && strings.TrimSpace(c["DATA_DEFAULT"]) != "":
+			if dataDefault, ok := DB2ColumnDefaultMapMySQLRule(c["DATA_DEFAULT"], datatype); ok {
+				sb.WriteString(common.StringsBuilder(" DEFAULT ", dataDefault))
+			} else {
+				ddl.Incompatibility = append(ddl.Incompatibility, fmt.Sprintf("-- db2 table [%s.%s] column [%s] default value [%s] isn't support, skip default",
+					t.SourceSchemaName, t.SourceTableName, c["COLUMN_NAME"], strings.TrimSpace(c["DATA_DEFAULT"])))
+			}
+		}
+
+		if c["COMMENTS"] != "NULLABLE" && c["COMMENTS"] != "" {
+			sb.WriteString(common.StringsBuilder(" COMMENT '", common.SpecialLettersUsingMySQL([]byte(c["COMMENTS"])), "'"))
+		}
+		tableColumns = append(tableColumns, sb.String())
+	}
+
+	keys, err := t.DB2.GetDB2SchemaTableKey(t.SourceSchemaName, t.SourceTableName)
+	if err != nil {
+		return nil, err
+	}
+	identityIndexed := false
+	for _, k := range keys {
+		columnList := t.quoteColumnList(k["COLUMN_LIST"])
+		if identityColumn != "" && strings.HasPrefix(columnList, common.StringsBuilder("`", identityColumn, "`")) {
+			identityIndexed = true
+		}
+		if k["CONSTRAINT_TYPE"] == "P" {
+			tableColumns = append(tableColumns, fmt.Sprintf("PRIMARY KEY (%s)", columnList))
+		} else {
+			tableColumns = append(tableColumns, fmt.Sprintf("UNIQUE KEY `%s` (%s)", k["CONSTRAINT_NAME"], columnList))
+		}
+	}
+	// MySQL 自增列必须为索引首列
+	if identityColumn != "" && !identityIndexed {
+		tableColumns = append(tableColumns, fmt.Sprintf("KEY `%s` (`%s`)", identityColumn, identityColumn))
+	}
+
+	tableSuffix := fmt.Sprintf("ENGINE=InnoDB DEFAULT CHARSET=%s COLLATE=%s", strings.ToLower(common.MySQLCharacterSet), "utf8mb4_bin")
+	if autoIncrement != "" && autoIncrement != "NULLABLE" {
+		tableSuffix = common.StringsBuilder(tableSuffix, " AUTO_INCREMENT=", autoIncrement)
+	}
+	if detail["COMMENTS"] != "NULLABLE" && detail["COMMENTS"] != "" {
+		tableSuffix = common.StringsBuilder(tableSuffix, " COMMENT='", common.SpecialLettersUsingMySQL([]byte(detail["COMMENTS"])), "'")
+	}
+	ddl.TableCreateSQL = fmt.Sprintf("CREATE TABLE %s (\n    %s\n) %s;", t.fullTableName(), strings.Join(tableColumns, ",\n    "), tableSuffix)
+
+	indexes, err := t.DB2.GetDB2SchemaTableIndex(t.SourceSchemaName, t.SourceTableName)
+	if err != nil {
+		return nil, err
+	}
+	for _, idx := range indexes {
+		switch common.StringUPPER(idx["INDEX_TYPE"]) {
+		case "REG", "CLUS":
+			unique := ""
+			if idx["UNIQUERULE"] == "U" {
+				unique = "UNIQUE "
+			}
+			ddl.IndexCreateSQL = append(ddl.IndexCreateSQL, fmt.Sprintf("CREATE %sINDEX `%s` ON %s (%s);",
+				unique, idx["INDEX_NAME"], t.fullTableName(), t.quoteIndexColumns(idx["COLNAMES"])))
+		default:
+			// XML 索引、维度块索引等
+			ddl.Incompatibility = append(ddl.Incompatibility, fmt.Sprintf("-- db2 table [%s.%s] index [%s] type [%s] columns [%s] isn't support mysql",
+				t.SourceSchemaName, t.SourceTableName, idx["INDEX_NAME"], idx["INDEX_TYPE"], idx["COLNAMES"]))
+		}
+	}
+
+	checks, err := t.DB2.GetDB2SchemaTableCheckKey(t.SourceSchemaName, t.SourceTableName)
+	if err != nil {
+		return nil, err
+	}
+	for _, ck := range checks {
+		ddl.ConstraintSQL = append(ddl.ConstraintSQL, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT `%s` CHECK (%s);",
+			t.fullTableName(), ck["CONSTRAINT_NAME"], strings.TrimSpace(ck["SEARCH_CONDITION"])))
+	}
+
+	foreignKeys, err := t.DB2.GetDB2SchemaTableForeignKey(t.SourceSchemaName, t.SourceTableName)
+	if err != nil {
+		return nil, err
+	}
+	for _, fk := range foreignKeys {
+		fkSQL := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT `%s` FOREIGN KEY (%s) REFERENCES `%s`.`%s` (%s)",
+			t.fullTableName(), fk["CONSTRAINT_NAME"], t.quoteColumnList(fk["COLUMN_LIST"]), t.TargetSchemaName, fk["RTABLE_NAME"], t.quoteColumnList(fk["RCOLUMN_LIST"]))
+		switch fk["DELETE_RULE"] {
+		case "C":
+			fkSQL = common.StringsBuilder(fkSQL, " ON DELETE CASCADE")
+		case "N":
+			fkSQL = common.StringsBuilder(fkSQL, " ON DELETE SET NULL")
+		case "R":
+			fkSQL = common.StringsBuilder(fkSQL, " ON DELETE RESTRICT")
+		}
+		if fk["R_OWNER"] != t.SourceSchemaName {
+			ddl.Incompatibility = append(ddl.Incompatibility, fmt.Sprintf("-- db2 table [%s.%s] foreign key [%s] reference other schema table [%s.%s], please manual check\n%s;",
+				t.SourceSchemaName, t.SourceTableName, fk["CONSTRAINT_NAME"], fk["R_OWNER"], fk["RTABLE_NAME"], fkSQL))
+			continue
+		}
+		ddl.ConstraintSQL = append(ddl.ConstraintSQL, common.StringsBuilder(fkSQL, ";"))
+	}
+
+	// 范围分区边界默认双闭区间，无法等价转换为 VALUES LESS THAN，按普通表创建
+	partitions, err := t.DB2.GetDB2SchemaTableDataPartition(t.SourceSchemaName, t.SourceTableName)
+	if err != nil {
+		return nil, err
+	}
+	if len(partitions) > 0 {
+		ddl.Incompatibility = append(ddl.Incompatibility, fmt.Sprintf("-- db2 table [%s.%s] range partitions [%d] isn't support auto convert, reverse as normal table, please manual add partition",
+			t.SourceSchemaName, t.SourceTableName, len(partitions)))
+	}
+	return ddl, nil
+}
+
+func (t *Table) String() string {
+	jsonStr, _ := json.Marshal(t)
+	return string(jsonStr)
+}
+
+func (d *DDL) Write(w *reverse.Write, overwrite bool) error {
+	var sqls []string
+	if overwrite {
+		sqls = append(sqls, common.StringsBuilder("DROP TABLE IF EXISTS `", d.TargetSchemaName, "`.`", d.TargetTableName, "`;"))
+	}
+	sqls = append(sqls, d.TableCreateSQL)
+	sqls = append(sqls, d.IndexCreateSQL...)
+	sqls = append(sqls, d.ConstraintSQL...)
+
+	if w.Cfg.ReverseConfig.DirectWrite {
+		for _, s := range sqls {
+			if err := w.RWriteDB(s); err != nil {
+				return err
+			}
+		}
+	} else {
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("-- db2 table [%s.%s] reverse mysql table [%s.%s]\n", d.SourceSchemaName, d.SourceTableName, d.TargetSchemaName, d.TargetTableName))
+		sb.WriteString(fmt.Sprintf("-- db2 tablespace %s\n", d.Tablespace))
+		sb.WriteString(strings.Join(sqls, "\n"))
+		sb.WriteString("\n\n")
+		if _, err := w.RWriteFile(sb.String()); err != nil {
+			return err
+		}
+	}
+
+	if len(d.Incompatibility) > 0 {
+		if _, err := w.CWriteFile(strings.Join(d.Incompatibility, "\n") + "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -29,38 +29,32 @@ import (
 )
 
 type Change struct {
-	Ctx              context.Context `json:"-"`
-	DBTypeS          string          `json:"db_type_s"`
-	DBTypeT          string          `json:"db_type_t"`
-	SourceSchemaName string          `json:"source_schema_name"`
-	TargetSchemaName string          `json:"target_schema_name"`
-	SourceTables     []string        `json:"source_tables"`
-	Threads          int             `json:"threads"`
-	MySQL            *mysql.MySQL    `json:"-"`
-	MetaDB           *meta.Meta      `json:"-"`
+	Ctx              context.Context      `json:"-"`
+	DBTypeS          string               `json:"db_type_s"`
+	DBTypeT          string               `json:"db_type_t"`
+	SourceSchemaName string               `json:"source_schema_name"`
+	TargetSchemaName string               `json:"target_schema_name"`
+	SourceTables     []string             `json:"source_tables"`
+	TableRewriter    *common.NameRewriter `json:"-"`
+	Threads          int                  `json:"threads"`
+	MySQL            *mysql.MySQL         `json:"-"`
+	MetaDB           *meta.Meta           `json:"-"`
 }
 
 func (r *Change) ChangeTableName() (map[string]string, error) {
 	startTime := time.Now()
 	tableNameRule := make(map[string]string)
-	customTableNameRule := make(map[string]string)
-	// 获取表名自定义规则
-	tableNameRules, err := meta.NewTableNameRuleModel(r.MetaDB).DetailTableNameRule(r.Ctx, &meta.TableNameRule{
+	// 获取表名自定义规则以及正则改写规则
+	customTableNameRule, err := meta.NewTableNameRuleModel(r.MetaDB).DetailTableNameRuleMap(r.Ctx, &meta.TableNameRule{
 		DBTypeS:     r.DBTypeS,
 		DBTypeT:     r.DBTypeT,
 		SchemaNameS: r.SourceSchemaName,
 		SchemaNameT: r.TargetSchemaName,
-	})
+	}, r.SourceTables, r.TableRewriter)
 	if err != nil {
 		return tableNameRule, err
 	}
 
-	if len(tableNameRules) > 0 {
-		for _, tr := range tableNameRules {
-			tableNameRule[common.StringUPPER(tr.TableNameS)] = common.StringUPPER(tr.TableNameT)
-		}
-	}
-
 	wg := &sync.WaitGroup{}
 	tableChS := make(chan string, common.ChannelBufferSize)
 	tableChT := make(chan string, common.ChannelBufferSize)
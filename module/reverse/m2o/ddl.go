@@ -40,6 +40,7 @@ type DDL struct {
 	TableCheckKeys       []string `json:"table_check_keys""`
 	TableForeignKeys     []string `json:"table_foreign_keys"`
 	TableCompatibleDDL   []string `json:"table_compatible_ddl"`
+	TableAutoIncrement   []string `json:"table_auto_increment"`
 	TablePartitionDetail string   `json:"table_partition_detail"`
 }
 
@@ -138,6 +139,10 @@ func (d *DDL) Write(w *reverse.Write) error {
 		sqlRev.WriteString(strings.Join(d.TableIndexes, "\n") + "\n")
 	}
 
+	if len(d.TableAutoIncrement) > 0 {
+		sqlRev.WriteString(strings.Join(d.TableAutoIncrement, "\n") + "\n")
+	}
+
 	if len(d.TableCompatibleDDL) > 0 {
 		sqlComp.WriteString(strings.Join(d.TableCompatibleDDL, "\n") + "\n")
 	}
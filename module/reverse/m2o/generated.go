@@ -0,0 +1,380 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package m2o
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"strings"
+	"unicode"
+)
+
+// Oracle 虚拟列不支持 LOB 以及 LONG 类型
+var oracleVirtualColumnNotSupportDataType = []string{"CLOB", "NCLOB", "BLOB", "LONG", "LONG RAW", "BFILE", "XMLTYPE"}
+
+// MySQL 同名同语义函数
+var mysqlGeneratedSameFuncs = map[string]struct{}{
+	"ABS": {}, "CEIL": {}, "FLOOR": {}, "ROUND": {}, "MOD": {}, "POWER": {}, "SQRT": {}, "SIGN": {}, "EXP": {}, "LN": {},
+	"LOWER": {}, "UPPER": {}, "TRIM": {}, "LTRIM": {}, "RTRIM": {}, "REPLACE": {}, "LPAD": {}, "RPAD": {},
+	"INSTR": {}, "COALESCE": {}, "NULLIF": {}, "GREATEST": {}, "LEAST": {}, "SUBSTR": {}, "ASCII": {},
+}
+
+// MySQL 函数名不同语义相同，直接改名
+var mysqlGeneratedRenameFuncs = map[string]string{
+	"CEILING":          "CEIL",
+	"POW":              "POWER",
+	"SUBSTRING":        "SUBSTR",
+	"MID":              "SUBSTR",
+	"CHAR_LENGTH":      "LENGTH",
+	"CHARACTER_LENGTH": "LENGTH",
+	"LENGTH":           "LENGTHB",
+	"OCTET_LENGTH":     "LENGTHB",
+	"TRUNCATE":         "TRUNC",
+	"LCASE":            "LOWER",
+	"UCASE":            "UPPER",
+	"IFNULL":           "NVL",
+}
+
+// MySQL 表达式中可直接保留的关键字
+var mysqlGeneratedKeywords = map[string]struct{}{
+	"AND": {}, "OR": {}, "NOT": {}, "IS": {}, "NULL": {}, "CASE": {}, "WHEN": {}, "THEN": {}, "ELSE": {}, "END": {},
+	"IN": {}, "BETWEEN": {}, "LIKE": {},
+}
+
+// MySQL 表达式中可直接保留的运算符
+var mysqlGeneratedOperators = map[string]struct{}{
+	"+": {}, "-": {}, "*": {}, "/": {}, "=": {}, "<>": {}, "!=": {}, "<": {}, ">": {}, "<=": {}, ">=": {},
+}
+
+// genGeneratedColumn 生成列转换为 Oracle 虚拟列，STORED/VIRTUAL 统一转换为 VIRTUAL（Oracle 不支持存储生成列）
+// 表达式无法转换或字段类型不支持虚拟列时返回原因，生成列输出至不兼容性文件
+func (r *Rule) genGeneratedColumn(columnName, columnType, nullable string) (string, string) {
+	for _, g := range r.GeneratedColumnINFO {
+		if g["COLUMN_NAME"] != columnName {
+			continue
+		}
+		if common.IsContainString(oracleVirtualColumnNotSupportDataType, common.StringUPPER(columnType)) {
+			return "", fmt.Sprintf("oracle virtual column datatype [%s] isn't support", columnType)
+		}
+		expr, err := TranslateMySQLGeneratedExpr(g["GENERATION_EXPRESSION"])
+		if err != nil {
+			return "", err.Error()
+		}
+		if strings.EqualFold(nullable, "NOT NULL") {
+			return fmt.Sprintf("%s %s GENERATED ALWAYS AS (%s) VIRTUAL NOT NULL", columnName, columnType, expr), ""
+		}
+		return fmt.Sprintf("%s %s GENERATED ALWAYS AS (%s) VIRTUAL", columnName, columnType, expr), ""
+	}
+	return "", ""
+}
+
+// GenTableGeneratedColumnCompatibility 无法转换的生成列，建表时跳过并输出原表达式至不兼容性文件，需人工改写后手工添加
+func (r *Rule) GenTableGeneratedColumnCompatibility() (compatibilityColumnSQL []string, err error) {
+	for _, g := range r.GeneratedColumnINFO {
+		columnName := g["COLUMN_NAME"]
+		columnType := r.TableColumnDatatypeRule[columnName]
+		if _, reason := r.genGeneratedColumn(columnName, columnType, ""); reason != "" {
+			compatibilityColumnSQL = append(compatibilityColumnSQL,
+				fmt.Sprintf("/* mysql generated column [%s] %s expression [%s] reverse failed: %s */\nALTER TABLE %s.%s ADD %s %s GENERATED ALWAYS AS (%s) VIRTUAL;",
+					columnName, g["EXTRA"], g["GENERATION_EXPRESSION"], reason,
+					r.TargetSchemaName, r.TargetTableName, columnName, columnType, g["GENERATION_EXPRESSION"]))
+		}
+	}
+	return compatibilityColumnSQL, nil
+}
+
+// unsupportedGeneratedColumns 无法转换的生成列
+func (r *Rule) unsupportedGeneratedColumns() []string {
+	var columns []string
+	for _, g := range r.GeneratedColumnINFO {
+		columnName := g["COLUMN_NAME"]
+		if _, reason := r.genGeneratedColumn(columnName, r.TableColumnDatatypeRule[columnName], ""); reason != "" {
+			columns = append(columns, columnName)
+		}
+	}
+	return columns
+}
+
+// isContainUnsupportedGeneratedColumn 索引/约束字段包含无法转换的生成列
+func (r *Rule) isContainUnsupportedGeneratedColumn(columnList string) bool {
+	unsupported := r.unsupportedGeneratedColumns()
+	if len(unsupported) == 0 {
+		return false
+	}
+	for _, c := range strings.Split(columnList, ",") {
+		for _, u := range unsupported {
+			if strings.EqualFold(strings.TrimSpace(c), u) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type generatedToken struct {
+	kind  string // ident/string/number/op/lparen/rparen/comma
+	value string
+}
+
+// TranslateMySQLGeneratedExpr MySQL 生成列表达式改写为 Oracle 表达式
+// 1、去除反引号以及字符集前缀 _utf8mb4'x'，字符串反斜杠转义改写为双写单引号
+// 2、CONCAT 改写为 ||，IF 改写为 CASE WHEN，IFNULL/SUBSTRING/CHAR_LENGTH 等改写为 Oracle 同语义函数
+// 3、JSON_UNQUOTE(JSON_EXTRACT(col, path)) 改写为 JSON_VALUE(col, path)，JSON_EXTRACT 改写为 JSON_QUERY
+// 4、其余函数以及 %、DIV、||、&& 等 MySQL 特有运算符视为无法转换
+func TranslateMySQLGeneratedExpr(expr string) (string, error) {
+	// MySQL 8.0 information_schema 表达式内字符串单引号以反斜杠转义输出，如 _utf8mb4\'a\'
+	tokens, err := tokenizeMySQLGeneratedExpr(strings.ReplaceAll(expr, `\'`, `'`))
+	if err != nil {
+		return "", err
+	}
+	p := &generatedParser{tokens: tokens}
+	out, err := p.parseSeq(false)
+	if err != nil {
+		return "", err
+	}
+	if p.pos < len(p.tokens) {
+		return "", fmt.Errorf("mysql generated expression [%s] unexpected token [%s]", expr, p.tokens[p.pos].value)
+	}
+	return out, nil
+}
+
+func tokenizeMySQLGeneratedExpr(expr string) ([]generatedToken, error) {
+	var (
+		tokens []generatedToken
+		rs     = []rune(expr)
+	)
+	for i := 0; i < len(rs); {
+		c := rs[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '`':
+			j := i + 1
+			for j < len(rs) && rs[j] != '`' {
+				j++
+			}
+			if j >= len(rs) {
+				return nil, fmt.Errorf("mysql generated expression [%s] backtick isn't closed", expr)
+			}
+			tokens = append(tokens, generatedToken{kind: "ident", value: string(rs[i+1 : j])})
+			i = j + 1
+		case c == '\'' || c == '"':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(rs); j++ {
+				if rs[j] == '\\' && j+1 < len(rs) {
+					j++
+					sb.WriteRune(rs[j])
+					continue
+				}
+				if rs[j] == c {
+					if j+1 < len(rs) && rs[j+1] == c {
+						sb.WriteRune(c)
+						j++
+						continue
+					}
+					break
+				}
+				sb.WriteRune(rs[j])
+			}
+			if j >= len(rs) {
+				return nil, fmt.Errorf("mysql generated expression [%s] string literal isn't closed", expr)
+			}
+			tokens = append(tokens, generatedToken{kind: "string", value: sb.String()})
+			i = j + 1
+		case unicode.IsDigit(c) || (c == '.' && i+1 < len(rs) && unicode.IsDigit(rs[i+1])):
+			j := i
+			for j < len(rs) && (unicode.IsDigit(rs[j]) || rs[j] == '.' || rs[j] == 'e' || rs[j] == 'E') {
+				j++
+			}
+			tokens = append(tokens, generatedToken{kind: "number", value: string(rs[i:j])})
+			i = j
+		case unicode.IsLetter(c) || c == '_' || c == '$':
+			j := i
+			for j < len(rs) && (unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j]) || rs[j] == '_' || rs[j] == '$') {
+				j++
+			}
+			// 字符集前缀 _utf8mb4'x' 直接丢弃
+			if c == '_' && j < len(rs) && rs[j] == '\'' {
+				i = j
+				continue
+			}
+			tokens = append(tokens, generatedToken{kind: "ident", value: string(rs[i:j])})
+			i = j
+		case c == '(':
+			tokens = append(tokens, generatedToken{kind: "lparen", value: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, generatedToken{kind: "rparen", value: ")"})
+			i++
+		case c == ',':
+			tokens = append(tokens, generatedToken{kind: "comma", value: ","})
+			i++
+		default:
+			j := i + 1
+			if j < len(rs) && strings.ContainsRune("=<>|&", rs[j]) && strings.ContainsRune("<>!|&", c) {
+				j++
+				if j < len(rs) && rs[j] == '>' && c == '<' {
+					j++
+				}
+			}
+			tokens = append(tokens, generatedToken{kind: "op", value: string(rs[i:j])})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+type generatedParser struct {
+	tokens []generatedToken
+	pos    int
+}
+
+// parseSeq 解析至右括号或逗号（stopAtComma）为止
+func (p *generatedParser) parseSeq(stopAtComma bool) (string, error) {
+	var parts []string
+	for p.pos < len(p.tokens) {
+		t := p.tokens[p.pos]
+		switch t.kind {
+		case "rparen":
+			return strings.Join(parts, " "), nil
+		case "comma":
+			if stopAtComma {
+				return strings.Join(parts, " "), nil
+			}
+			return "", fmt.Errorf("mysql generated expression row constructor isn't support")
+		case "lparen":
+			p.pos++
+			inner, err := p.parseSeq(false)
+			if err != nil {
+				return "", err
+			}
+			if err = p.expect("rparen"); err != nil {
+				return "", err
+			}
+			parts = append(parts, common.StringsBuilder("(", inner, ")"))
+		case "string":
+			p.pos++
+			parts = append(parts, common.StringsBuilder("'", strings.ReplaceAll(t.value, "'", "''"), "'"))
+		case "number":
+			p.pos++
+			parts = append(parts, t.value)
+		case "op":
+			if _, ok := mysqlGeneratedOperators[t.value]; !ok {
+				return "", fmt.Errorf("mysql generated expression operator [%s] isn't support", t.value)
+			}
+			p.pos++
+			parts = append(parts, t.value)
+		case "ident":
+			p.pos++
+			if p.pos < len(p.tokens) && p.tokens[p.pos].kind == "lparen" {
+				fn, err := p.parseFunc(t.value)
+				if err != nil {
+					return "", err
+				}
+				parts = append(parts, fn)
+				continue
+			}
+			upper := common.StringUPPER(t.value)
+			switch {
+			case upper == "TRUE":
+				parts = append(parts, "1")
+			case upper == "FALSE":
+				parts = append(parts, "0")
+			case upper == "DIV" || upper == "XOR" || upper == "REGEXP" || upper == "RLIKE":
+				return "", fmt.Errorf("mysql generated expression operator [%s] isn't support", t.value)
+			default:
+				if _, ok := mysqlGeneratedKeywords[upper]; ok {
+					parts = append(parts, upper)
+				} else {
+					// 字段名，目标端字段名不加引号
+					parts = append(parts, t.value)
+				}
+			}
+		}
+	}
+	return strings.Join(parts, " "), nil
+}
+
+func (p *generatedParser) parseFunc(name string) (string, error) {
+	// 左括号
+	p.pos++
+	var args []string
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == "rparen" {
+		p.pos++
+	} else {
+		for {
+			arg, err := p.parseSeq(true)
+			if err != nil {
+				return "", err
+			}
+			args = append(args, arg)
+			if p.pos >= len(p.tokens) {
+				return "", fmt.Errorf("mysql generated expression function [%s] isn't closed", name)
+			}
+			if p.tokens[p.pos].kind == "comma" {
+				p.pos++
+				continue
+			}
+			p.pos++
+			break
+		}
+	}
+
+	upper := common.StringUPPER(name)
+	switch upper {
+	case "CONCAT":
+		if len(args) == 0 {
+			return "", fmt.Errorf("mysql generated expression function [%s] arguments isn't support", name)
+		}
+		return common.StringsBuilder("(", strings.Join(args, " || "), ")"), nil
+	case "IF":
+		if len(args) != 3 {
+			return "", fmt.Errorf("mysql generated expression function [%s] arguments isn't support", name)
+		}
+		return fmt.Sprintf("CASE WHEN %s THEN %s ELSE %s END", args[0], args[1], args[2]), nil
+	case "LOG10":
+		if len(args) != 1 {
+			return "", fmt.Errorf("mysql generated expression function [%s] arguments isn't support", name)
+		}
+		return fmt.Sprintf("LOG(10, %s)", args[0]), nil
+	case "JSON_EXTRACT":
+		if len(args) != 2 {
+			return "", fmt.Errorf("mysql generated expression function [%s] multiple path isn't support", name)
+		}
+		return fmt.Sprintf("JSON_QUERY(%s, %s)", args[0], args[1]), nil
+	case "JSON_UNQUOTE":
+		if len(args) != 1 || !strings.HasPrefix(args[0], "JSON_QUERY(") {
+			return "", fmt.Errorf("mysql generated expression function [%s] only support json_unquote(json_extract())", name)
+		}
+		return common.StringsBuilder("JSON_VALUE(", strings.TrimPrefix(args[0], "JSON_QUERY(")), nil
+	}
+	if val, ok := mysqlGeneratedRenameFuncs[upper]; ok {
+		return common.StringsBuilder(val, "(", strings.Join(args, ", "), ")"), nil
+	}
+	if _, ok := mysqlGeneratedSameFuncs[upper]; ok {
+		return common.StringsBuilder(upper, "(", strings.Join(args, ", "), ")"), nil
+	}
+	return "", fmt.Errorf("mysql generated expression function [%s] isn't support", name)
+}
+
+func (p *generatedParser) expect(kind string) error {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != kind {
+		return fmt.Errorf("mysql generated expression parse failed, expect [%s]", kind)
+	}
+	p.pos++
+	return nil
+}
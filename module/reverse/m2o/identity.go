@@ -0,0 +1,66 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package m2o
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+)
+
+// Oracle 12.2 以下版本对象名最大 30 字节，序列、触发器名预留 _SEQ/_TRG 后缀长度
+const oracleAutoIncrementObjectPrefixMaxLength = 26
+
+// genIdentityColumn 自增列 Oracle 12c 及以上转换为标识列，起始值取源端当前 AUTO_INCREMENT
+// BY DEFAULT ON NULL 允许数据迁移显式写入自增列值
+func (r *Rule) genIdentityColumn(columnName, columnType string) (string, bool) {
+	if common.VersionOrdinal(r.OracleDBVersion) < common.VersionOrdinal(common.OracleIdentityColumnDBVersion) {
+		return "", false
+	}
+	for _, a := range r.AutoIncrementINFO {
+		if a["COLUMN_NAME"] == columnName {
+			return fmt.Sprintf("%s %s GENERATED BY DEFAULT ON NULL AS IDENTITY (START WITH %s INCREMENT BY 1) NOT NULL", columnName, columnType, a["AUTO_INCREMENT"]), true
+		}
+	}
+	return "", false
+}
+
+// GenTableAutoIncrement 自增列 Oracle 12c 以下版本通过序列 + BEFORE INSERT 触发器模拟，序列起始值取源端当前 AUTO_INCREMENT
+// 触发器仅在写入值为 NULL 时取序列值，数据迁移显式写入的自增列值保持不变
+func (r *Rule) GenTableAutoIncrement() (autoIncrementDDL []string, err error) {
+	if common.VersionOrdinal(r.OracleDBVersion) >= common.VersionOrdinal(common.OracleIdentityColumnDBVersion) {
+		return autoIncrementDDL, nil
+	}
+	for _, a := range r.AutoIncrementINFO {
+		columnName := a["COLUMN_NAME"]
+		// 超长时截断并追加表名、列名 CRC32 后缀，避免不同表前缀相同导致序列、触发器重名
+		// 对象名与约束名一致按大写不加引号生成
+		prefix := common.TruncateIdentifier(common.StringUPPER(common.StringsBuilder(r.TargetTableName, "_", columnName)), oracleAutoIncrementObjectPrefixMaxLength)
+		sequenceName := common.StringsBuilder(prefix, "_SEQ")
+		triggerName := common.StringsBuilder(prefix, "_TRG")
+
+		autoIncrementDDL = append(autoIncrementDDL,
+			fmt.Sprintf("CREATE SEQUENCE %s.%s START WITH %s INCREMENT BY 1;", r.TargetSchemaName, sequenceName, a["AUTO_INCREMENT"]),
+			fmt.Sprintf(`CREATE OR REPLACE TRIGGER %[1]s.%[2]s
+BEFORE INSERT ON %[1]s.%[3]s
+FOR EACH ROW
+WHEN (NEW.%[4]s IS NULL)
+BEGIN
+  :NEW.%[4]s := %[1]s.%[5]s.NEXTVAL;
+END;
+/`, r.TargetSchemaName, triggerName, r.TargetTableName, columnName, sequenceName))
+	}
+	return autoIncrementDDL, nil
+}
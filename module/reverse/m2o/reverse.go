@@ -111,6 +111,10 @@ func (r *Reverse) Reverse() error {
 
 	// 获取规则
 	ruleTime := time.Now()
+	tableRewriter, err := r.cfg.RewriteConfig.TableRewriter()
+	if err != nil {
+		return err
+	}
 	tableNameRuleMap, tableColumnRuleMap, tableDefaultRuleMap, err := IChanger(&Change{
 		Ctx:              r.ctx,
 		DBTypeS:          r.cfg.DBTypeS,
@@ -118,6 +122,7 @@ func (r *Reverse) Reverse() error {
 		SourceSchemaName: common.StringUPPER(r.cfg.MySQLConfig.SchemaName),
 		TargetSchemaName: common.StringUPPER(r.cfg.OracleConfig.SchemaName),
 		SourceTables:     reverseTaskTables,
+		TableRewriter:    tableRewriter,
 		Threads:          r.cfg.ReverseConfig.ReverseThreads,
 		MySQL:            r.mysql,
 		MetaDB:           r.metaDB,
@@ -269,11 +274,21 @@ func (r *Reverse) Reverse() error {
 			zap.Int64("table failed", errTotals),
 			zap.String("cost", endTime.Sub(startTime).String()))
 	} else {
+		suggestions, err := meta.NewErrorLogDetailModel(r.metaDB).DistinctErrorSuggestion(r.ctx, &meta.ErrorLogDetail{
+			DBTypeS:     r.cfg.DBTypeS,
+			DBTypeT:     r.cfg.DBTypeT,
+			SchemaNameS: common.StringUPPER(r.cfg.MySQLConfig.SchemaName),
+			TaskMode:    r.cfg.TaskMode,
+		})
+		if err != nil {
+			return err
+		}
 		zap.L().Warn("reverse table mysql to oracle finished",
 			zap.Int("table totals", len(tables)),
 			zap.Int("table success", len(tables)-int(errTotals)),
 			zap.Int64("table failed", errTotals),
 			zap.String("failed tips", "failed detail, please see table [error_log_detail]"),
+			zap.Strings("suggest", suggestions),
 			zap.String("cost", endTime.Sub(startTime).String()))
 	}
 	return nil
@@ -38,6 +38,8 @@ type Info struct {
 	TableCommentINFO     []map[string]string `json:"table_comment_info"`
 	TableColumnINFO      []map[string]string `json:"table_column_info"`
 	ColumnCommentINFO    []map[string]string `json:"column_comment_info"`
+	GeneratedColumnINFO  []map[string]string `json:"generated_column_info"`
+	AutoIncrementINFO    []map[string]string `json:"auto_increment_info"`
 	TablePartitionDetail string              `json:"table_partition_detail"`
 }
 
@@ -54,6 +56,12 @@ func (r *Rule) GenCreateTableDDL() (interface{}, error) {
 		return nil, err
 	}
 
+	compGeneratedColumn, err := r.GenTableGeneratedColumnCompatibility()
+	if err != nil {
+		return nil, err
+	}
+	compatibleDDL = append(compatibleDDL, compGeneratedColumn...)
+
 	tablePrefix := fmt.Sprintf("CREATE TABLE %s.%s", r.TargetSchemaName, r.TargetTableName)
 
 	tableSuffix, err := r.GenTableSuffix()
@@ -86,6 +94,11 @@ func (r *Rule) GenCreateTableDDL() (interface{}, error) {
 	}
 	compatibleDDL = append(compatibleDDL, compNormalIndex...)
 
+	autoIncrementDDL, err := r.GenTableAutoIncrement()
+	if err != nil {
+		return nil, err
+	}
+
 	return &DDL{
 		SourceSchemaName:     r.SourceSchemaName,
 		SourceTableName:      r.SourceTableName,
@@ -102,6 +115,7 @@ func (r *Rule) GenCreateTableDDL() (interface{}, error) {
 		TableCheckKeys:       checkKeyMetas,
 		TableForeignKeys:     foreignKeys,
 		TableCompatibleDDL:   compatibleDDL,
+		TableAutoIncrement:   autoIncrementDDL,
 		TablePartitionDetail: r.TablePartitionDetail,
 	}, nil
 }
@@ -168,6 +182,10 @@ func (r *Rule) GenTableUniqueKey() (uniqueKeys []string, err error) {
 				uk = fmt.Sprintf("CONSTRAINT %s UNIQUE (%s)",
 					strings.ToUpper(rowUKCol["CONSTRAINT_NAME"]), strings.ToUpper(rowUKCol["COLUMN_LIST"]))
 			}
+			// 无法转换的生成列已跳过建表，唯一约束改为建表后手工添加
+			if r.isContainUnsupportedGeneratedColumn(rowUKCol["COLUMN_LIST"]) {
+				continue
+			}
 			uniqueKeys = append(uniqueKeys, uk)
 		}
 	}
@@ -274,6 +292,11 @@ func (r *Rule) GenTableNormalIndex() (normalIndexes []string, compatibilityIndex
 					strings.ToUpper(kv["COLUMN_LIST"]),
 				)
 			}
+			// 无法转换的生成列已跳过建表，索引输出至不兼容性文件
+			if r.isContainUnsupportedGeneratedColumn(kv["COLUMN_LIST"]) {
+				compatibilityIndexSQL = append(compatibilityIndexSQL, idx)
+				continue
+			}
 			normalIndexes = append(normalIndexes, idx)
 		}
 	}
@@ -347,18 +370,32 @@ func (r *Rule) GenTableColumn() (columnMetas []string, err error) {
 
 		columnName = rowCol["COLUMN_NAME"]
 
-		if val, ok := r.TableColumnDefaultValRule[columnName]; ok {
-			dataDefault = val
-		} else {
-			return columnMetas, fmt.Errorf("mysql table [%s.%s] column [%s] default value isn't exist", r.SourceSchemaName, r.SourceTableName, columnName)
-		}
-
 		if val, ok := r.TableColumnDatatypeRule[columnName]; ok {
 			columnType = val
 		} else {
 			return columnMetas, fmt.Errorf("mysql table [%s.%s] column [%s] data type isn't exist", r.SourceSchemaName, r.SourceTableName, columnName)
 		}
 
+		// 生成列转换为虚拟列，无法转换的生成列跳过，输出至不兼容性文件
+		if generatedColumn, reason := r.genGeneratedColumn(columnName, columnType, nullable); generatedColumn != "" || reason != "" {
+			if generatedColumn != "" {
+				columnMetas = append(columnMetas, generatedColumn)
+			}
+			continue
+		}
+
+		// 自增列 Oracle 12c 及以上转换为标识列
+		if identityColumn, ok := r.genIdentityColumn(columnName, columnType); ok {
+			columnMetas = append(columnMetas, identityColumn)
+			continue
+		}
+
+		if val, ok := r.TableColumnDefaultValRule[columnName]; ok {
+			dataDefault = val
+		} else {
+			return columnMetas, fmt.Errorf("mysql table [%s.%s] column [%s] default value isn't exist", r.SourceSchemaName, r.SourceTableName, columnName)
+		}
+
 		if strings.EqualFold(nullable, "NULL") {
 			// M2O
 			switch {
@@ -178,6 +178,9 @@ func GenReverseTableTask(r *Reverse, tableNameRule map[string]string, tableColum
 		return tables, err
 	}
 
+	// 正则改写合并的表只生成一次目标表结构
+	exporters = common.DistinctRewriteTargets(exporters, tableNameRule)
+
 	g1 := &errgroup.Group{}
 	tableChan := make(chan *Table, common.ChannelBufferSize)
 
@@ -299,6 +302,14 @@ func (t *Table) GetTableColumnComment() ([]map[string]string, error) {
 	return t.MySQL.GetMySQLTableColumnComment(t.SourceSchemaName, t.SourceTableName)
 }
 
+func (t *Table) GetTableGeneratedColumn() ([]map[string]string, error) {
+	return t.MySQL.GetMySQLTableGeneratedColumn(t.SourceSchemaName, t.SourceTableName)
+}
+
+func (t *Table) GetTableAutoIncrement() ([]map[string]string, error) {
+	return t.MySQL.GetMySQLTableAutoIncrement(t.SourceSchemaName, t.SourceTableName)
+}
+
 func (t *Table) GetTableInfo() (interface{}, error) {
 	primaryKey, err := t.GetTablePrimaryKey()
 	if err != nil {
@@ -338,6 +349,14 @@ func (t *Table) GetTableInfo() (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
+	generatedColumn, err := t.GetTableGeneratedColumn()
+	if err != nil {
+		return nil, err
+	}
+	autoIncrement, err := t.GetTableAutoIncrement()
+	if err != nil {
+		return nil, err
+	}
 	tablePartitionDetail, err := t.GetTablePartitionDetail()
 	if err != nil {
 		return nil, err
@@ -353,6 +372,8 @@ func (t *Table) GetTableInfo() (interface{}, error) {
 		TableCommentINFO:     tableComment,
 		TableColumnINFO:      columnMeta,
 		ColumnCommentINFO:    columnComment,
+		GeneratedColumnINFO:  generatedColumn,
+		AutoIncrementINFO:    autoIncrement,
 		TablePartitionDetail: tablePartitionDetail,
 	}, nil
 }
@@ -0,0 +1,111 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2d
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/database/oracle"
+	"github.com/wentaojin/transferdb/filter"
+	"go.uber.org/zap"
+	"time"
+)
+
+// FilterCFGTable 按 include-table/exclude-table 过滤 oracle 待迁移表，并剔除内置黑名单表
+func FilterCFGTable(ctx context.Context, cfg *config.Config, oracle *oracle.Oracle, metaDB *meta.Meta) ([]string, error) {
+	startTime := time.Now()
+	var exporterTableSlice []string
+
+	allOraSchemas, err := oracle.GetOracleSchemas()
+	if err != nil {
+		return nil, err
+	}
+	if !common.IsContainString(allOraSchemas, common.StringUPPER(cfg.OracleConfig.SchemaName)) {
+		return nil, fmt.Errorf("oracle schema [%s] isn't exist in the database", cfg.OracleConfig.SchemaName)
+	}
+
+	allTables, err := oracle.GetOracleSchemaTable(common.StringUPPER(cfg.OracleConfig.SchemaName))
+	if err != nil {
+		return nil, err
+	}
+	allTables, blacklistTables, err := meta.NewBuildinTableBlacklistModel(metaDB).FilterBuildinTableBlacklist(ctx, cfg.DBTypeS, allTables)
+	if err != nil {
+		return nil, err
+	}
+	if len(blacklistTables) > 0 {
+		zap.L().Warn("filter oracle blacklist tables",
+			zap.String("schema", cfg.OracleConfig.SchemaName),
+			zap.Strings("blacklist tables", blacklistTables))
+	}
+
+	switch {
+	case len(cfg.OracleConfig.IncludeTable) != 0 && len(cfg.OracleConfig.ExcludeTable) == 0:
+		f, err := filter.Parse(cfg.OracleConfig.IncludeTable)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range allTables {
+			if f.MatchTable(t) {
+				exporterTableSlice = append(exporterTableSlice, t)
+			}
+		}
+	case len(cfg.OracleConfig.IncludeTable) == 0 && len(cfg.OracleConfig.ExcludeTable) != 0:
+		f, err := filter.Parse(cfg.OracleConfig.ExcludeTable)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range allTables {
+			if !f.MatchTable(t) {
+				exporterTableSlice = append(exporterTableSlice, t)
+			}
+		}
+	case len(cfg.OracleConfig.IncludeTable) == 0 && len(cfg.OracleConfig.ExcludeTable) == 0:
+		exporterTableSlice = allTables
+	default:
+		return nil, fmt.Errorf("source config params include-table/exclude-table cannot exist at the same time")
+	}
+
+	if len(exporterTableSlice) == 0 {
+		return nil, fmt.Errorf("exporter tables aren't exist, please check config params include-table/exclude-table")
+	}
+
+	zap.L().Info("get oracle to dm table list finished",
+		zap.String("schema", cfg.OracleConfig.SchemaName),
+		zap.Int("table totals", len(exporterTableSlice)),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return exporterTableSlice, nil
+}
+
+// GetTableNameRule 表名自定义规则以及正则改写规则
+func GetTableNameRule(ctx context.Context, cfg *config.Config, oracle *oracle.Oracle, metaDB *meta.Meta) (map[string]string, error) {
+	rewriter, err := cfg.RewriteConfig.TableRewriter()
+	if err != nil {
+		return nil, err
+	}
+	sourceTables, err := oracle.GetOracleSchemaTable(common.StringUPPER(cfg.OracleConfig.SchemaName))
+	if err != nil {
+		return nil, err
+	}
+	return meta.NewTableNameRuleModel(metaDB).DetailTableNameRuleMap(ctx, &meta.TableNameRule{
+		DBTypeS:     cfg.DBTypeS,
+		DBTypeT:     cfg.DBTypeT,
+		SchemaNameS: common.StringUPPER(cfg.OracleConfig.SchemaName),
+		SchemaNameT: common.StringUPPER(cfg.DMConfig.SchemaName),
+	}, sourceTables, rewriter)
+}
@@ -0,0 +1,111 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2d
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	oracleTimestampRegex = regexp.MustCompile(`^TIMESTAMP\((\d+)\)(.*)$`)
+)
+
+// OracleTableColumnMapDMRule 字段类型映射，达梦兼容 Oracle 大部分类型，仅处理差异项
+// DATE 达梦不含时分秒，映射为 TIMESTAMP(0)
+func OracleTableColumnMapDMRule(columnName string, column map[string]string) (string, error) {
+	dataType := common.StringUPPER(column["DATA_TYPE"])
+	dataLength := column["DATA_LENGTH"]
+	charLength := column["CHAR_LENGTH"]
+	dataPrecision, err := strconv.Atoi(column["DATA_PRECISION"])
+	if err != nil {
+		return "", fmt.Errorf("column [%s] data_precision [%s] parse failed: %v", columnName, column["DATA_PRECISION"], err)
+	}
+	dataScale, err := strconv.Atoi(column["DATA_SCALE"])
+	if err != nil {
+		return "", fmt.Errorf("column [%s] data_scale [%s] parse failed: %v", columnName, column["DATA_SCALE"], err)
+	}
+
+	switch {
+	case dataType == "NUMBER":
+		// number / number(*) 查询结果为 number(38,127)
+		if dataPrecision == 38 && dataScale == 127 {
+			return "NUMBER", nil
+		}
+		if dataScale == 0 {
+			return fmt.Sprintf("NUMBER(%d)", dataPrecision), nil
+		}
+		return fmt.Sprintf("NUMBER(%d,%d)", dataPrecision, dataScale), nil
+	case dataType == "FLOAT":
+		return fmt.Sprintf("FLOAT(%d)", dataPrecision), nil
+	case dataType == "BINARY_FLOAT":
+		return "FLOAT", nil
+	case dataType == "BINARY_DOUBLE":
+		return "DOUBLE", nil
+	case dataType == "CHAR" || dataType == "VARCHAR2":
+		// CHAR_USED = C 字符语义，长度取 CHAR_LENGTH
+		if strings.EqualFold(column["CHAR_USED"], "C") {
+			return fmt.Sprintf("%s(%s CHAR)", dataType, charLength), nil
+		}
+		return fmt.Sprintf("%s(%s)", dataType, dataLength), nil
+	case dataType == "NCHAR" || dataType == "NVARCHAR2":
+		return fmt.Sprintf("%s(%s)", dataType, charLength), nil
+	case dataType == "DATE":
+		return "TIMESTAMP(0)", nil
+	case strings.HasPrefix(dataType, "TIMESTAMP"):
+		// 达梦时间精度最大 6
+		if m := oracleTimestampRegex.FindStringSubmatch(dataType); m != nil {
+			scale, err := strconv.Atoi(m[1])
+			if err != nil {
+				return "", fmt.Errorf("column [%s] datatype [%s] scale parse failed: %v", columnName, dataType, err)
+			}
+			if scale > 6 {
+				scale = 6
+			}
+			return fmt.Sprintf("TIMESTAMP(%d)%s", scale, m[2]), nil
+		}
+		return dataType, nil
+	case strings.HasPrefix(dataType, "INTERVAL"):
+		return dataType, nil
+	case dataType == "CLOB" || dataType == "NCLOB" || dataType == "LONG" || dataType == "XMLTYPE":
+		return "CLOB", nil
+	case dataType == "BLOB" || dataType == "LONG RAW":
+		return "BLOB", nil
+	case dataType == "RAW":
+		return fmt.Sprintf("VARBINARY(%s)", dataLength), nil
+	case dataType == "BFILE":
+		return "BFILE", nil
+	case dataType == "ROWID":
+		return "VARCHAR(18)", nil
+	case dataType == "UROWID":
+		return fmt.Sprintf("VARCHAR(%s)", dataLength), nil
+	default:
+		return "", fmt.Errorf("column [%s] datatype [%s] isn't support dm", columnName, dataType)
+	}
+}
+
+// IsOracleBinaryDatatype 二进制字段，数据写入时按 []byte 绑定，其余字符类按 string 绑定
+func IsOracleBinaryDatatype(dataType string) bool {
+	switch common.StringUPPER(dataType) {
+	case "BLOB", "RAW", "LONG RAW":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2d
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/dm"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/database/oracle"
+	"github.com/wentaojin/transferdb/module/reverse"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"sync/atomic"
+	"time"
+)
+
+type Reverse struct {
+	Ctx    context.Context
+	Cfg    *config.Config
+	DM     *dm.DM
+	Oracle *oracle.Oracle
+	MetaDB *meta.Meta
+}
+
+func NewReverse(ctx context.Context, cfg *config.Config) (*Reverse, error) {
+	oracleDB, err := oracle.NewOracleDBEngine(ctx, cfg.OracleConfig)
+	if err != nil {
+		return nil, err
+	}
+	dmDB, err := dm.NewDMDBEngine(ctx, cfg.DMConfig)
+	if err != nil {
+		return nil, err
+	}
+	metaDB, err := meta.NewMetaDBEngine(ctx, cfg.MySQLConfig, cfg.AppConfig.SlowlogThreshold)
+	if err != nil {
+		return nil, err
+	}
+	return &Reverse{
+		Ctx:    ctx,
+		Cfg:    cfg,
+		DM:     dmDB,
+		Oracle: oracleDB,
+		MetaDB: metaDB,
+	}, nil
+}
+
+func (r *Reverse) Reverse() error {
+	startTime := time.Now()
+	zap.L().Info("reverse table oracle to dm start",
+		zap.String("schema", r.Cfg.OracleConfig.SchemaName))
+
+	exporters, err := FilterCFGTable(r.Ctx, r.Cfg, r.Oracle, r.MetaDB)
+	if err != nil {
+		return err
+	}
+
+	oracleDBVersion, err := r.Oracle.GetOracleDBVersion()
+	if err != nil {
+		return err
+	}
+	oracleCollation := false
+	if common.VersionOrdinal(oracleDBVersion) >= common.VersionOrdinal(common.OracleTableColumnCollationDBVersion) {
+		oracleCollation = true
+	}
+
+	tableNameRule, err := GetTableNameRule(r.Ctx, r.Cfg, r.Oracle, r.MetaDB)
+	if err != nil {
+		return err
+	}
+	columnRewriter, err := r.Cfg.RewriteConfig.ColumnRewriter()
+	if err != nil {
+		return err
+	}
+
+	f, err := reverse.NewWriter(r.Cfg, nil, r.Oracle)
+	if err != nil {
+		return err
+	}
+	f.DM = r.DM
+
+	sourceSchema := common.StringUPPER(r.Cfg.OracleConfig.SchemaName)
+	targetSchema := common.StringUPPER(r.Cfg.DMConfig.SchemaName)
+
+	if err = r.genCreateSchema(f, targetSchema); err != nil {
+		return err
+	}
+
+	var failedTables int64
+	g := &errgroup.Group{}
+	g.SetLimit(r.Cfg.ReverseConfig.ReverseThreads)
+
+	for _, table := range exporters {
+		t := &Table{
+			SourceSchemaName: sourceSchema,
+			SourceTableName:  table,
+			TargetSchemaName: targetSchema,
+			TargetTableName:  table,
+			Overwrite:        r.Cfg.DMConfig.Overwrite,
+			OracleCollation:  oracleCollation,
+			ColumnRewriter:   columnRewriter,
+			Oracle:           r.Oracle,
+		}
+		if val, ok := tableNameRule[table]; ok {
+			t.TargetTableName = val
+		}
+		g.Go(func() error {
+			ddl, err := t.GenCreateTableDDL()
+			if err == nil {
+				err = ddl.Write(f, t.Overwrite)
+			}
+			if err != nil {
+				atomic.AddInt64(&failedTables, 1)
+				if errL := meta.NewErrorLogDetailModel(r.MetaDB).CreateErrorLog(r.Ctx, &meta.ErrorLogDetail{
+					DBTypeS:     r.Cfg.DBTypeS,
+					DBTypeT:     r.Cfg.DBTypeT,
+					SchemaNameS: t.SourceSchemaName,
+					TableNameS:  t.SourceTableName,
+					SchemaNameT: t.TargetSchemaName,
+					TableNameT:  t.TargetTableName,
+					TaskMode:    r.Cfg.TaskMode,
+					TaskStatus:  common.TaskStatusFailed,
+					InfoDetail:  t.String(),
+					ErrorDetail: err.Error(),
+				}); errL != nil {
+					return fmt.Errorf("reverse table oracle to dm [%s.%s] failed: %v, record error log failed: %v", t.SourceSchemaName, t.SourceTableName, err, errL)
+				}
+				zap.L().Warn("reverse table oracle to dm failed, detail see [error_log_detail]",
+					zap.String("schema", t.SourceSchemaName),
+					zap.String("table", t.SourceTableName),
+					zap.Error(err))
+			}
+			return nil
+		})
+	}
+	if err = g.Wait(); err != nil {
+		return err
+	}
+
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	zap.L().Info("reverse table oracle to dm finished",
+		zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+		zap.Int("table totals", len(exporters)),
+		zap.Int64("table failed", failedTables),
+		zap.String("reverse dir", r.Cfg.ReverseConfig.DDLReverseDir),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
+
+// genCreateSchema 达梦模式不存在时创建，归属当前连接用户
+func (r *Reverse) genCreateSchema(f *reverse.Write, targetSchema string) error {
+	createSQL := common.StringsBuilder(`CREATE SCHEMA "`, targetSchema, `"`)
+	if !r.Cfg.ReverseConfig.DirectWrite {
+		_, err := f.RWriteFile(common.StringsBuilder(createSQL, ";\n\n"))
+		return err
+	}
+	isExist, err := r.DM.IsExistDMSchema(targetSchema)
+	if err != nil {
+		return err
+	}
+	if isExist {
+		return nil
+	}
+	return f.RWriteDB(createSQL)
+}
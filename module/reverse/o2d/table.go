@@ -0,0 +1,210 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2d
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/oracle"
+	"github.com/wentaojin/transferdb/module/reverse"
+	"strings"
+)
+
+type Table struct {
+	SourceSchemaName string `json:"source_schema_name"`
+	SourceTableName  string `json:"source_table_name"`
+	TargetSchemaName string `json:"target_schema_name"`
+	TargetTableName  string `json:"target_table_name"`
+	Overwrite        bool   `json:"overwrite"`
+	OracleCollation  bool   `json:"oracle_collation"`
+
+	ColumnRewriter *common.NameRewriter `json:"-"`
+	Oracle         *oracle.Oracle       `json:"-"`
+}
+
+type DDL struct {
+	SourceSchemaName string
+	SourceTableName  string
+	TargetSchemaName string
+	TargetTableName  string
+	TableCreateSQL   string
+	IndexCreateSQL   []string
+	CommentSQL       []string
+	// 达梦不兼容对象，输出至 compatibility 文件人工处理
+	Incompatibility []string
+}
+
+func (t *Table) columnName(sourceColumn string) string {
+	if targetColumn, _, ok := t.ColumnRewriter.Rewrite(sourceColumn); ok {
+		return targetColumn
+	}
+	return sourceColumn
+}
+
+func (t *Table) quoteColumnList(columnList string) string {
+	var cols []string
+	for _, c := range strings.Split(columnList, ",") {
+		cols = append(cols, common.StringsBuilder(`"`, t.columnName(c), `"`))
+	}
+	return strings.Join(cols, ",")
+}
+
+func (t *Table) fullTableName() string {
+	return common.StringsBuilder(`"`, t.TargetSchemaName, `"."`, t.TargetTableName, `"`)
+}
+
+func (t *Table) GenCreateTableDDL() (*DDL, error) {
+	ddl := &DDL{
+		SourceSchemaName: t.SourceSchemaName,
+		SourceTableName:  t.SourceTableName,
+		TargetSchemaName: t.TargetSchemaName,
+		TargetTableName:  t.TargetTableName,
+	}
+
+	columns, err := t.Oracle.GetOracleSchemaTableColumn(t.SourceSchemaName, t.SourceTableName, t.OracleCollation)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		tableColumns []string
+		targetCols   []string
+	)
+	for _, c := range columns {
+		datatype, err := OracleTableColumnMapDMRule(c["COLUMN_NAME"], c)
+		if err != nil {
+			return nil, fmt.Errorf("oracle table [%s.%s] %v", t.SourceSchemaName, t.SourceTableName, err)
+		}
+		targetColumn := t.columnName(c["COLUMN_NAME"])
+		if common.IsContainString(targetCols, targetColumn) {
+			return nil, fmt.Errorf("oracle table [%s.%s] column [%s] rewrite target column [%s] conflict", t.SourceSchemaName, t.SourceTableName, c["COLUMN_NAME"], targetColumn)
+		}
+		targetCols = append(targetCols, targetColumn)
+
+		var sb strings.Builder
+		sb.WriteString(common.StringsBuilder(`"`, targetColumn, `" `, datatype))
+		if c["DATA_DEFAULT"] != "NULLABLE" && strings.TrimSpace(c["DATA_DEFAULT"]) != "" {
+			sb.WriteString(common.StringsBuilder(" DEFAULT ", strings.TrimSpace(c["DATA_DEFAULT"])))
+		}
+		if strings.EqualFold(c["NULLABLE"], "N") {
+			sb.WriteString(" NOT NULL")
+		}
+		tableColumns = append(tableColumns, sb.String())
+
+		if c["COMMENTS"] != "NULLABLE" && c["COMMENTS"] != "" {
+			ddl.CommentSQL = append(ddl.CommentSQL, fmt.Sprintf(`COMMENT ON COLUMN %s."%s" IS '%s';`,
+				t.fullTableName(), targetColumn, common.SpecialLettersUsingOracle([]byte(c["COMMENTS"]))))
+		}
+	}
+
+	primaryKeys, err := t.Oracle.GetOracleSchemaTablePrimaryKey(t.SourceSchemaName, t.SourceTableName)
+	if err != nil {
+		return nil, err
+	}
+	for _, pk := range primaryKeys {
+		tableColumns = append(tableColumns, fmt.Sprintf(`CONSTRAINT "%s" PRIMARY KEY (%s)`, pk["CONSTRAINT_NAME"], t.quoteColumnList(pk["COLUMN_LIST"])))
+	}
+
+	uniqueKeys, err := t.Oracle.GetOracleSchemaTableUniqueKey(t.SourceSchemaName, t.SourceTableName)
+	if err != nil {
+		return nil, err
+	}
+	for _, uk := range uniqueKeys {
+		tableColumns = append(tableColumns, fmt.Sprintf(`CONSTRAINT "%s" UNIQUE (%s)`, uk["CONSTRAINT_NAME"], t.quoteColumnList(uk["COLUMN_LIST"])))
+	}
+
+	ddl.TableCreateSQL = fmt.Sprintf("CREATE TABLE %s (\n    %s\n);", t.fullTableName(), strings.Join(tableColumns, ",\n    "))
+
+	uniqueIndexes, err := t.Oracle.GetOracleSchemaTableUniqueIndex(t.SourceSchemaName, t.SourceTableName)
+	if err != nil {
+		return nil, err
+	}
+	normalIndexes, err := t.Oracle.GetOracleSchemaTableNormalIndex(t.SourceSchemaName, t.SourceTableName)
+	if err != nil {
+		return nil, err
+	}
+	for _, idx := range append(uniqueIndexes, normalIndexes...) {
+		unique := ""
+		if strings.EqualFold(idx["UNIQUENESS"], "UNIQUE") {
+			unique = "UNIQUE "
+		}
+		switch common.StringUPPER(idx["INDEX_TYPE"]) {
+		case "NORMAL":
+			ddl.IndexCreateSQL = append(ddl.IndexCreateSQL, fmt.Sprintf(`CREATE %sINDEX "%s"."%s" ON %s (%s);`,
+				unique, t.TargetSchemaName, idx["INDEX_NAME"], t.fullTableName(), t.quoteColumnList(idx["COLUMN_LIST"])))
+		case "FUNCTION-BASED NORMAL":
+			// 函数索引 COLUMN_LIST 为表达式原文，达梦兼容 Oracle 表达式语法，原样输出
+			ddl.IndexCreateSQL = append(ddl.IndexCreateSQL, fmt.Sprintf(`CREATE %sINDEX "%s"."%s" ON %s (%s);`,
+				unique, t.TargetSchemaName, idx["INDEX_NAME"], t.fullTableName(), idx["COLUMN_LIST"]))
+		case "BITMAP":
+			ddl.IndexCreateSQL = append(ddl.IndexCreateSQL, fmt.Sprintf(`CREATE BITMAP INDEX "%s"."%s" ON %s (%s);`,
+				t.TargetSchemaName, idx["INDEX_NAME"], t.fullTableName(), t.quoteColumnList(idx["COLUMN_LIST"])))
+		default:
+			ddl.Incompatibility = append(ddl.Incompatibility, fmt.Sprintf(`-- oracle table [%s.%s] index [%s] type [%s] columns [%s] isn't support dm`,
+				t.SourceSchemaName, t.SourceTableName, idx["INDEX_NAME"], idx["INDEX_TYPE"], idx["COLUMN_LIST"]))
+		}
+	}
+
+	tableComments, err := t.Oracle.GetOracleSchemaTableComment(t.SourceSchemaName, t.SourceTableName)
+	if err != nil {
+		return nil, err
+	}
+	if len(tableComments) > 0 && tableComments[0]["COMMENTS"] != "NULLABLE" && tableComments[0]["COMMENTS"] != "" {
+		ddl.CommentSQL = append([]string{fmt.Sprintf(`COMMENT ON TABLE %s IS '%s';`,
+			t.fullTableName(), common.SpecialLettersUsingOracle([]byte(tableComments[0]["COMMENTS"])))}, ddl.CommentSQL...)
+	}
+	return ddl, nil
+}
+
+func (t *Table) String() string {
+	jsonStr, _ := json.Marshal(t)
+	return string(jsonStr)
+}
+
+func (d *DDL) Write(w *reverse.Write, overwrite bool) error {
+	var sqls []string
+	if overwrite {
+		sqls = append(sqls, common.StringsBuilder(`DROP TABLE IF EXISTS "`, d.TargetSchemaName, `"."`, d.TargetTableName, `";`))
+	}
+	sqls = append(sqls, d.TableCreateSQL)
+	sqls = append(sqls, d.IndexCreateSQL...)
+	sqls = append(sqls, d.CommentSQL...)
+
+	if w.Cfg.ReverseConfig.DirectWrite {
+		for _, s := range sqls {
+			// 达梦执行语句不带结尾分号
+			if err := w.RWriteDB(strings.TrimSuffix(s, ";")); err != nil {
+				return err
+			}
+		}
+	} else {
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("-- oracle table [%s.%s] reverse dm table [%s.%s]\n", d.SourceSchemaName, d.SourceTableName, d.TargetSchemaName, d.TargetTableName))
+		sb.WriteString(strings.Join(sqls, "\n"))
+		sb.WriteString("\n\n")
+		if _, err := w.RWriteFile(sb.String()); err != nil {
+			return err
+		}
+	}
+
+	if len(d.Incompatibility) > 0 {
+		if _, err := w.CWriteFile(strings.Join(d.Incompatibility, "\n") + "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -34,6 +34,7 @@ type DDL struct {
 	TargetDBType       string   `json:"target_db_type"`
 	TargetDBVersion    string   `json:"target_db_version"`
 	TablePrefix        string   `json:"table_prefix"`
+	TemporaryTable     bool     `json:"temporary_table"`
 	TableColumns       []string `json:"table_columns"`
 	TableKeys          []string `json:"table_keys"`
 	TableSuffix        string   `json:"table_suffix"`
@@ -51,7 +52,14 @@ func (d *DDL) Write(w *reverse.Write) error {
 
 		sqlRev  strings.Builder
 		sqlComp strings.Builder
+
+		// 按对象类型拆分输出时索引、约束、注释单独输出
+		sqlIdx     strings.Builder
+		sqlCons    strings.Builder
+		sqlComment strings.Builder
 	)
+	// 临时表脚本统一输出至兼容性文件，不拆分
+	split := w.IsSplitByObject() && !d.TemporaryTable
 
 	zap.L().Info("reverse oracle table struct", zap.String("table", d.String()))
 
@@ -68,23 +76,40 @@ func (d *DDL) Write(w *reverse.Write) error {
 	sqlRev.WriteString(fmt.Sprintf("%v\n", sw.Render()))
 	sqlRev.WriteString("*/\n")
 
+	// 拆分输出时建表仅保留主键（TiDB 聚簇索引需建表时指定），其余索引数据导入后再创建
+	tableKeys := d.TableKeys
+	if split {
+		tableKeys = nil
+		for _, key := range d.TableKeys {
+			if strings.HasPrefix(key, "PRIMARY KEY") {
+				tableKeys = append(tableKeys, key)
+				continue
+			}
+			sqlIdx.WriteString(fmt.Sprintf("ALTER TABLE `%s`.`%s` ADD %s;\n", d.TargetSchemaName, d.TargetTableName, key))
+		}
+		if !strings.EqualFold(d.TableComment, "") {
+			sqlComment.WriteString(fmt.Sprintf("ALTER TABLE `%s`.`%s` %s;\n", d.TargetSchemaName, d.TargetTableName, d.TableComment))
+		}
+	}
+
 	var reverseDDL string
-	if len(d.TableKeys) > 0 {
+	if len(tableKeys) > 0 {
 		reverseDDL = fmt.Sprintf("%s (\n%s,\n%s\n)",
 			d.TablePrefix,
 			strings.Join(d.TableColumns, ",\n"),
-			strings.Join(d.TableKeys, ",\n"))
+			strings.Join(tableKeys, ",\n"))
 	} else {
 		reverseDDL = fmt.Sprintf("%s (\n%s\n)",
 			d.TablePrefix,
 			strings.Join(d.TableColumns, ",\n"))
 	}
 
-	if strings.EqualFold(d.TableComment, "") {
+	if strings.EqualFold(d.TableComment, "") || split {
 		tableDDL = fmt.Sprintf("%s %s;", reverseDDL, d.TableSuffix)
 	} else {
 		tableDDL = fmt.Sprintf("%s %s %s;", reverseDDL, d.TableSuffix, d.TableComment)
 	}
+
 	sqlRev.WriteString(tableDDL + "\n\n")
 
 	// foreign and check key sql ddl
@@ -111,6 +136,33 @@ func (d *DDL) Write(w *reverse.Write) error {
 		}
 	}
 
+	// MySQL 临时表会话级别，无法预先创建，脚本输出至兼容性文件
+	if d.TemporaryTable {
+		sqlComp.WriteString("/*\n")
+		sqlComp.WriteString(" oracle global temporary table convert to mysql temporary table script, session level, please create in application session\n")
+		tw := table.NewWriter()
+		tw.SetStyle(table.StyleLight)
+		tw.AppendHeader(table.Row{"#", "ORACLE TABLE TYPE", "ORACLE", "MYSQL", "SUGGEST"})
+		tw.AppendRows([]table.Row{
+			{"TABLE", d.SourceTableType, fmt.Sprintf("%s.%s", d.SourceSchemaName, d.SourceTableName), fmt.Sprintf("%s.%s", d.TargetSchemaName, d.TargetTableName), "Create Temporary Table"},
+		})
+		sqlComp.WriteString(fmt.Sprintf("%v\n", tw.Render()))
+		sqlComp.WriteString("*/\n")
+		sqlComp.WriteString(tableDDL + "\n")
+		for _, sql := range d.TableCompatibleDDL {
+			sqlComp.WriteString(sql + "\n")
+		}
+		// 临时表不支持外键，检查约束按兼容项输出
+		for _, sql := range checkKeyDDL {
+			sqlComp.WriteString(sql + "\n")
+		}
+		sqlComp.WriteString("\n")
+		if _, err := w.CWriteFile(sqlComp.String()); err != nil {
+			return err
+		}
+		return nil
+	}
+
 	// 兼容项处理
 	if len(d.TableForeignKeys) > 0 || len(d.TableCheckKeys) > 0 || len(d.TableCompatibleDDL) > 0 {
 		sqlComp.WriteString("/*\n")
@@ -127,16 +179,24 @@ func (d *DDL) Write(w *reverse.Write) error {
 
 	// 外键约束、检查约束
 	if d.TargetDBType != common.DatabaseTypeTiDB {
+		consRev := &sqlRev
+		if split {
+			consRev = &sqlCons
+		}
 		if len(foreignKeyDDL) > 0 {
 			for _, sql := range foreignKeyDDL {
-				sqlRev.WriteString(sql + "\n")
+				consRev.WriteString(sql + "\n")
 			}
 		}
 
-		if common.VersionOrdinal(d.TargetDBVersion) > common.VersionOrdinal(common.MySQLCheckConsVersion) {
+		checkConsVersion := common.MySQLCheckConsVersion
+		if d.TargetDBType == common.DatabaseTypeMariaDB {
+			checkConsVersion = common.MariaDBCheckConsVersion
+		}
+		if common.VersionOrdinal(d.TargetDBVersion) > common.VersionOrdinal(checkConsVersion) {
 			if len(checkKeyDDL) > 0 {
 				for _, sql := range checkKeyDDL {
-					sqlRev.WriteString(sql + "\n")
+					consRev.WriteString(sql + "\n")
 				}
 			}
 		} else {
@@ -155,7 +215,11 @@ func (d *DDL) Write(w *reverse.Write) error {
 		}
 
 		// 文件写入
-		if sqlRev.String() != "" {
+		if split {
+			if err := writeSplitDDL(w, d.TargetTableName, sqlRev.String(), sqlIdx.String(), sqlCons.String(), sqlComment.String()); err != nil {
+				return err
+			}
+		} else if sqlRev.String() != "" {
 			if w.Cfg.ReverseConfig.DirectWrite {
 				if err := w.RWriteDB(sqlRev.String()); err != nil {
 					return err
@@ -192,7 +256,11 @@ func (d *DDL) Write(w *reverse.Write) error {
 		}
 	}
 	// 文件写入
-	if sqlRev.String() != "" {
+	if split {
+		if err := writeSplitDDL(w, d.TargetTableName, sqlRev.String(), sqlIdx.String(), sqlCons.String(), sqlComment.String()); err != nil {
+			return err
+		}
+	} else if sqlRev.String() != "" {
 		if w.Cfg.ReverseConfig.DirectWrite {
 			if err := w.RWriteDB(sqlRev.String()); err != nil {
 				return err
@@ -211,6 +279,25 @@ func (d *DDL) Write(w *reverse.Write) error {
 	return nil
 }
 
+// writeSplitDDL 表、索引、约束、注释分别写入对应拆分文件或按表拆分文件
+func writeSplitDDL(w *reverse.Write, tableName, tableSQL, indexSQL, constraintSQL, commentSQL string) error {
+	splitSQL := map[string]string{
+		common.ReverseSplitObjectTable:      tableSQL,
+		common.ReverseSplitObjectIndex:      indexSQL,
+		common.ReverseSplitObjectConstraint: constraintSQL,
+		common.ReverseSplitObjectComment:    commentSQL,
+	}
+	for _, objectType := range common.ReverseSplitObjects {
+		if splitSQL[objectType] == "" {
+			continue
+		}
+		if err := w.STableWriteFile(objectType, tableName, splitSQL[objectType]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (d *DDL) String() string {
 	jsonBytes, _ := json.Marshal(d)
 	return string(jsonBytes)
@@ -0,0 +1,157 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DecisionRecorder 记录本次 reverse 决策（类型映射、重命名、排序规则），用于与上一次 reverse 对比
+type DecisionRecorder struct {
+	mu        sync.Mutex
+	decisions []meta.ReverseDecision
+}
+
+func NewDecisionRecorder() *DecisionRecorder {
+	return &DecisionRecorder{}
+}
+
+// Record 依据表级规则及数据字典生成表、字段决策
+func (d *DecisionRecorder) Record(r *Rule) error {
+	var decisions []meta.ReverseDecision
+	decision := func(decisionType, objectName, value string) {
+		decisions = append(decisions, meta.ReverseDecision{
+			SchemaNameS:   r.SourceSchemaName,
+			TableNameS:    r.SourceTableName,
+			DecisionType:  decisionType,
+			ObjectNameS:   objectName,
+			DecisionValue: value,
+		})
+	}
+
+	decision(common.ReverseDecisionTableName, r.SourceTableName, common.StringsBuilder(r.GenSchemaName(), ".", r.GenTableName()))
+	tableCollation, err := r.genTableCollation()
+	if err != nil {
+		return err
+	}
+	decision(common.ReverseDecisionTableCollation, r.SourceTableName, tableCollation)
+
+	for _, rowCol := range r.TableColumnINFO {
+		columnName := rowCol["COLUMN_NAME"]
+		if targetName := r.columnName(columnName); targetName != columnName {
+			decision(common.ReverseDecisionColumnName, columnName, targetName)
+		}
+		decision(common.ReverseDecisionColumnType, columnName, r.TableColumnDatatypeRule[columnName])
+		decision(common.ReverseDecisionColumnDefault, columnName, r.TableColumnDefaultValRule[columnName])
+		if r.OracleCollation {
+			if val, ok := common.OracleCollationToMySQL(r.TargetDBType, r.TargetDBVersion, rowCol["COLLATION"]); ok {
+				decision(common.ReverseDecisionColumnCollation, columnName, val)
+			}
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.decisions = append(d.decisions, decisions...)
+	return nil
+}
+
+// RecordIdentifier 索引、约束截断或冲突重命名决策
+func (d *DecisionRecorder) RecordIdentifier(identRules []meta.IdentifierNameRule) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, ident := range identRules {
+		d.decisions = append(d.decisions, meta.ReverseDecision{
+			SchemaNameS:   ident.SchemaNameS,
+			TableNameS:    ident.TableNameS,
+			DecisionType:  common.ReverseDecisionIdentifierName,
+			ObjectNameS:   common.StringsBuilder(ident.ObjectType, ".", ident.ObjectNameS),
+			DecisionValue: ident.ObjectNameT,
+		})
+	}
+}
+
+func (d *DecisionRecorder) Decisions(dbTypeS, dbTypeT string) []meta.ReverseDecision {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i := range d.decisions {
+		d.decisions[i].DBTypeS = dbTypeS
+		d.decisions[i].DBTypeT = dbTypeT
+	}
+	return d.decisions
+}
+
+// GenReverseDecisionDiff 对比上一次 reverse 决策，输出新增、变更、移除项，返回差异数
+// 上一次无决策记录（首次 reverse）不输出
+func GenReverseDecisionDiff(diffFile string, previous, current []meta.ReverseDecision) (int, error) {
+	if len(previous) == 0 {
+		return 0, nil
+	}
+	decisionKey := func(rd meta.ReverseDecision) string {
+		return common.StringsBuilder(rd.TableNameS, "|", rd.DecisionType, "|", rd.ObjectNameS)
+	}
+	prevMap := make(map[string]meta.ReverseDecision, len(previous))
+	for _, rd := range previous {
+		prevMap[decisionKey(rd)] = rd
+	}
+	currMap := make(map[string]meta.ReverseDecision, len(current))
+	for _, rd := range current {
+		currMap[decisionKey(rd)] = rd
+	}
+
+	var diffs []string
+	for k, rd := range currMap {
+		prev, ok := prevMap[k]
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("+ %s %s [%s] %s", rd.TableNameS, rd.DecisionType, rd.ObjectNameS, rd.DecisionValue))
+		case prev.DecisionValue != rd.DecisionValue:
+			diffs = append(diffs, fmt.Sprintf("~ %s %s [%s] %s -> %s", rd.TableNameS, rd.DecisionType, rd.ObjectNameS, prev.DecisionValue, rd.DecisionValue))
+		}
+	}
+	for k, rd := range prevMap {
+		if _, ok := currMap[k]; !ok {
+			diffs = append(diffs, fmt.Sprintf("- %s %s [%s] %s", rd.TableNameS, rd.DecisionType, rd.ObjectNameS, rd.DecisionValue))
+		}
+	}
+	// 按表名、决策类型排序，便于逐表审阅
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i][2:] < diffs[j][2:]
+	})
+
+	var sb strings.Builder
+	sb.WriteString("/*\n")
+	sb.WriteString(" reverse decision diff versus previous run\n")
+	sb.WriteString(" + added, ~ changed (previous -> current), - removed\n")
+	sb.WriteString("*/\n")
+	for _, diff := range diffs {
+		sb.WriteString(diff + "\n")
+	}
+	if err := common.PathExist(filepath.Dir(diffFile)); err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(diffFile, []byte(sb.String()), 0666); err != nil {
+		return 0, fmt.Errorf("write reverse decision diff file [%s] failed: %v", diffFile, err)
+	}
+	return len(diffs), nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"github.com/wentaojin/transferdb/database/oracle"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"time"
+)
+
+// Dictionary schema 级数据字典，每类对象一次查询后按表名分组，供 IReader 读取
+type Dictionary struct {
+	PrimaryKey    map[string][]map[string]string
+	UniqueKey     map[string][]map[string]string
+	ForeignKey    map[string][]map[string]string
+	CheckKey      map[string][]map[string]string
+	UniqueIndex   map[string][]map[string]string
+	NormalIndex   map[string][]map[string]string
+	TableComment  map[string][]map[string]string
+	TableColumn   map[string][]map[string]string
+	ColumnComment map[string][]map[string]string
+}
+
+func NewDictionary(oracleDB *oracle.Oracle, schemaName string, oraCollation bool) (*Dictionary, error) {
+	startTime := time.Now()
+	d := &Dictionary{}
+
+	// 各类对象并发查询，互不依赖
+	g := &errgroup.Group{}
+	fetches := []struct {
+		dest  *map[string][]map[string]string
+		fetch func() ([]map[string]string, error)
+	}{
+		{&d.PrimaryKey, func() ([]map[string]string, error) { return oracleDB.GetOracleSchemaPrimaryKey(schemaName) }},
+		{&d.UniqueKey, func() ([]map[string]string, error) { return oracleDB.GetOracleSchemaUniqueKey(schemaName) }},
+		{&d.ForeignKey, func() ([]map[string]string, error) { return oracleDB.GetOracleSchemaForeignKey(schemaName) }},
+		{&d.CheckKey, func() ([]map[string]string, error) { return oracleDB.GetOracleSchemaCheckKey(schemaName) }},
+		{&d.UniqueIndex, func() ([]map[string]string, error) { return oracleDB.GetOracleSchemaIndex(schemaName, "UNIQUE") }},
+		{&d.NormalIndex, func() ([]map[string]string, error) { return oracleDB.GetOracleSchemaIndex(schemaName, "NONUNIQUE") }},
+		{&d.TableComment, func() ([]map[string]string, error) { return oracleDB.GetOracleSchemaComment(schemaName) }},
+		{&d.TableColumn, func() ([]map[string]string, error) { return oracleDB.GetOracleSchemaColumn(schemaName, oraCollation) }},
+		{&d.ColumnComment, func() ([]map[string]string, error) { return oracleDB.GetOracleSchemaColumnComment(schemaName) }},
+	}
+	for _, f := range fetches {
+		fc := f
+		g.Go(func() error {
+			res, err := fc.fetch()
+			if err != nil {
+				return err
+			}
+			*fc.dest = oracle.GroupByTableName(res)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	zap.L().Info("get oracle schema dictionary finished",
+		zap.String("schema", schemaName),
+		zap.Int("table column totals", len(d.TableColumn)),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return d, nil
+}
@@ -16,16 +16,18 @@ limitations under the License.
 package o2m
 
 import (
+	"context"
 	"fmt"
 	"github.com/wentaojin/transferdb/common"
 	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/meta"
 	"github.com/wentaojin/transferdb/database/oracle"
 	"github.com/wentaojin/transferdb/filter"
 	"go.uber.org/zap"
 	"time"
 )
 
-func filterCFGTable(cfg *config.Config, oracle *oracle.Oracle) ([]string, error) {
+func filterCFGTable(ctx context.Context, cfg *config.Config, oracle *oracle.Oracle, metaDB *meta.Meta) ([]string, error) {
 	startTime := time.Now()
 	var (
 		exporterTableSlice []string
@@ -49,6 +51,17 @@ func filterCFGTable(cfg *config.Config, oracle *oracle.Oracle) ([]string, error)
 		return exporterTableSlice, err
 	}
 
+	// 过滤内置黑名单表（回收站、物化视图日志、IOT 溢出段等），黑名单规则见元数据表 [buildin_table_blacklist]
+	allTables, blacklistTables, err := meta.NewBuildinTableBlacklistModel(metaDB).FilterBuildinTableBlacklist(ctx, cfg.DBTypeS, allTables)
+	if err != nil {
+		return exporterTableSlice, err
+	}
+	if len(blacklistTables) > 0 {
+		zap.L().Warn("filter oracle blacklist tables",
+			zap.String("schema", cfg.OracleConfig.SchemaName),
+			zap.Strings("blacklist tables", blacklistTables))
+	}
+
 	switch {
 	case len(cfg.OracleConfig.IncludeTable) != 0 && len(cfg.OracleConfig.ExcludeTable) == 0:
 		// 过滤规则加载
@@ -62,6 +75,22 @@ func filterCFGTable(cfg *config.Config, oracle *oracle.Oracle) ([]string, error)
 				exporterTableSlice = append(exporterTableSlice, t)
 			}
 		}
+
+		// EBR 编辑视图命中 include-table 时，解析至基表迁移
+		baseTables, err := oracle.FilterOracleEditioningViewBaseTable(common.StringUPPER(cfg.OracleConfig.SchemaName), f.MatchTable)
+		if err != nil {
+			return exporterTableSlice, err
+		}
+		for _, t := range baseTables {
+			if common.IsContainString(allTables, t) && !common.IsContainString(exporterTableSlice, t) {
+				exporterTableSlice = append(exporterTableSlice, t)
+			}
+		}
+		if len(baseTables) > 0 {
+			zap.L().Warn("resolve oracle editioning views to base tables",
+				zap.String("schema", cfg.OracleConfig.SchemaName),
+				zap.Strings("base tables", baseTables))
+		}
 	case len(cfg.OracleConfig.IncludeTable) == 0 && len(cfg.OracleConfig.ExcludeTable) != 0:
 		// 过滤规则加载
 		f, err := filter.Parse(cfg.OracleConfig.ExcludeTable)
@@ -74,6 +103,17 @@ func filterCFGTable(cfg *config.Config, oracle *oracle.Oracle) ([]string, error)
 				excludeTables = append(excludeTables, t)
 			}
 		}
+
+		// EBR 编辑视图命中 exclude-table 时，一并排除对应基表
+		baseTables, err := oracle.FilterOracleEditioningViewBaseTable(common.StringUPPER(cfg.OracleConfig.SchemaName), f.MatchTable)
+		if err != nil {
+			return exporterTableSlice, err
+		}
+		for _, t := range baseTables {
+			if !common.IsContainString(excludeTables, t) {
+				excludeTables = append(excludeTables, t)
+			}
+		}
 		exporterTableSlice = common.FilterDifferenceStringItems(allTables, excludeTables)
 
 	case len(cfg.OracleConfig.IncludeTable) == 0 && len(cfg.OracleConfig.ExcludeTable) == 0:
@@ -154,6 +194,88 @@ func FilterOracleCompatibleTable(cfg *config.Config, oracle *oracle.Oracle, expo
 	return partitionTables, temporaryTables, clusteredTables, materializedView, exporterTables, nil
 }
 
+// FilterOracleTemporalTable 筛选 Flashback Data Archive 以及 Temporal Validity 表，转换为普通表
+func FilterOracleTemporalTable(cfg *config.Config, oracle *oracle.Oracle, exporters []string, oracleDBVersion string) ([]string, []string, error) {
+	flashbackTables, err := filterOracleFlashbackArchiveTable(cfg, oracle, exporters)
+	if err != nil {
+		return []string{}, []string{}, fmt.Errorf("error on filter r.Oracle flashback archive table: %v", err)
+	}
+
+	var temporalTables []string
+	if common.VersionOrdinal(oracleDBVersion) >= common.VersionOrdinal(common.OracleTemporalValidityDBVersion) {
+		temporalTables, err = filterOracleTemporalValidityTable(cfg, oracle, exporters)
+		if err != nil {
+			return []string{}, []string{}, fmt.Errorf("error on filter r.Oracle temporal validity table: %v", err)
+		}
+	}
+
+	if len(flashbackTables) != 0 {
+		zap.L().Warn("flashback archive tables",
+			zap.String("schema", cfg.OracleConfig.SchemaName),
+			zap.String("flashback archive table list", fmt.Sprintf("%v", flashbackTables)),
+			zap.String("suggest", "convert to normal table, flashback archive history data isn't migrated, if necessary, please manually process"))
+	}
+	if len(temporalTables) != 0 {
+		zap.L().Warn("temporal validity tables",
+			zap.String("schema", cfg.OracleConfig.SchemaName),
+			zap.String("temporal validity table list", fmt.Sprintf("%v", temporalTables)),
+			zap.String("suggest", "convert to normal table, hidden period columns and valid time semantics aren't migrated, if necessary, please manually process"))
+	}
+	return flashbackTables, temporalTables, nil
+}
+
+// FilterOracleEditionObject 筛选 EBR 编辑视图、跨版本触发器以及非当前版本编辑对象，仅输出兼容提示不做转换
+func FilterOracleEditionObject(cfg *config.Config, oracle *oracle.Oracle, exporters []string) (map[string]string, []map[string]string, map[string][]string, error) {
+	schemaName := common.StringUPPER(cfg.OracleConfig.SchemaName)
+
+	editioningViews, err := oracle.GetOracleSchemaEditioningView(schemaName)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error on filter r.Oracle editioning view: %v", err)
+	}
+	views := make(map[string]string)
+	for view, table := range editioningViews {
+		if common.IsContainString(exporters, table) {
+			views[view] = table
+		}
+	}
+
+	triggers, err := oracle.GetOracleSchemaCrosseditionTrigger(schemaName)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error on filter r.Oracle crossedition trigger: %v", err)
+	}
+	var crosseditionTriggers []map[string]string
+	for _, t := range triggers {
+		if common.IsContainString(exporters, t["TABLE_NAME"]) {
+			crosseditionTriggers = append(crosseditionTriggers, t)
+		}
+	}
+
+	editionedObjects, err := oracle.GetOracleSchemaEditionedObject(schemaName)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error on filter r.Oracle editioned object: %v", err)
+	}
+
+	if len(views) != 0 {
+		zap.L().Warn("editioning views",
+			zap.String("schema", cfg.OracleConfig.SchemaName),
+			zap.String("editioning view list", fmt.Sprintf("%v", views)),
+			zap.String("suggest", "editioning view is resolved to base table, view isn't reversed"))
+	}
+	if len(crosseditionTriggers) != 0 {
+		zap.L().Warn("crossedition triggers",
+			zap.String("schema", cfg.OracleConfig.SchemaName),
+			zap.String("crossedition trigger list", fmt.Sprintf("%v", crosseditionTriggers)),
+			zap.String("suggest", "crossedition trigger only take effect during edition upgrade, excluded from reverse"))
+	}
+	if len(editionedObjects) != 0 {
+		zap.L().Warn("editioned objects not in current edition",
+			zap.String("schema", cfg.OracleConfig.SchemaName),
+			zap.String("editioned object list", fmt.Sprintf("%v", editionedObjects)),
+			zap.String("suggest", "objects of other editions are excluded, if necessary, please manually process"))
+	}
+	return views, crosseditionTriggers, editionedObjects, nil
+}
+
 func filterOraclePartitionTable(cfg *config.Config, oracle *oracle.Oracle, exporters []string) ([]string, error) {
 	tables, err := oracle.GetOracleSchemaPartitionTable(common.StringUPPER(cfg.OracleConfig.SchemaName))
 	if err != nil {
@@ -170,6 +292,22 @@ func filterOracleTemporaryTable(cfg *config.Config, oracle *oracle.Oracle, expor
 	return common.FilterIntersectionStringItems(exporters, tables), nil
 }
 
+func filterOracleFlashbackArchiveTable(cfg *config.Config, oracle *oracle.Oracle, exporters []string) ([]string, error) {
+	tables, err := oracle.GetOracleSchemaFlashbackArchiveTable(common.StringUPPER(cfg.OracleConfig.SchemaName))
+	if err != nil {
+		return nil, err
+	}
+	return common.FilterIntersectionStringItems(exporters, tables), nil
+}
+
+func filterOracleTemporalValidityTable(cfg *config.Config, oracle *oracle.Oracle, exporters []string) ([]string, error) {
+	tables, err := oracle.GetOracleSchemaTemporalValidityTable(common.StringUPPER(cfg.OracleConfig.SchemaName))
+	if err != nil {
+		return nil, err
+	}
+	return common.FilterIntersectionStringItems(exporters, tables), nil
+}
+
 func filterOracleClusteredTable(cfg *config.Config, oracle *oracle.Oracle, exporters []string) ([]string, error) {
 	tables, err := oracle.GetOracleSchemaClusteredTable(common.StringUPPER(cfg.OracleConfig.SchemaName))
 	if err != nil {
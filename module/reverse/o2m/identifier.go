@@ -0,0 +1,102 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/meta"
+	"go.uber.org/zap"
+	"strings"
+	"sync"
+)
+
+// IdentifierMapper 索引、约束名长度截断与冲突检测
+// MySQL 索引名、唯一约束名表级唯一，外键、检查约束名库级唯一
+type IdentifierMapper struct {
+	mu    sync.Mutex
+	used  map[string]map[string]string // scope -> target name -> source key
+	rules []meta.IdentifierNameRule
+}
+
+func NewIdentifierMapper() *IdentifierMapper {
+	return &IdentifierMapper{
+		used: make(map[string]map[string]string),
+	}
+}
+
+// Rename 返回目标端对象名，同一对象重复调用结果不变
+func (m *IdentifierMapper) Rename(t *Table, objectType, sourceName string) (string, error) {
+	var scope string
+	switch objectType {
+	case common.JSONFKConstraint, common.JSONCKConstraint:
+		scope = t.TargetSchemaName
+	default:
+		scope = common.StringsBuilder(t.TargetSchemaName, ".", t.TargetTableName)
+	}
+	sourceKey := common.StringsBuilder(t.SourceTableName, ".", sourceName)
+	upperName := strings.ToUpper(sourceName)
+	targetName := common.TruncateIdentifier(upperName, common.MySQLIdentifierMaxLength)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.used[scope]; !ok {
+		m.used[scope] = make(map[string]string)
+	}
+	names := m.used[scope]
+
+	if owner, ok := names[targetName]; ok {
+		if owner == sourceKey {
+			return targetName, nil
+		}
+		// 截断或大小写折叠后重名，以源端表名+对象名哈希重新生成
+		renamed := common.HashSuffixIdentifier(upperName, sourceKey, common.MySQLIdentifierMaxLength)
+		if other, exist := names[renamed]; exist && other != sourceKey {
+			return "", fmt.Errorf("oracle schema [%s] table [%s] %s [%s] target name [%s] collision with [%s]",
+				t.SourceSchemaName, t.SourceTableName, objectType, sourceName, renamed, other)
+		}
+		zap.L().Warn("reverse identifier collision",
+			zap.String("schema", t.SourceSchemaName),
+			zap.String("table", t.SourceTableName),
+			zap.String("object type", objectType),
+			zap.String("object name", sourceName),
+			zap.String("collision with", owner),
+			zap.String("rename", renamed))
+		targetName = renamed
+	}
+	names[targetName] = sourceKey
+
+	if targetName != upperName {
+		m.rules = append(m.rules, meta.IdentifierNameRule{
+			SchemaNameS: t.SourceSchemaName,
+			TableNameS:  t.SourceTableName,
+			ObjectType:  objectType,
+			ObjectNameS: sourceName,
+			SchemaNameT: t.TargetSchemaName,
+			TableNameT:  t.TargetTableName,
+			ObjectNameT: targetName,
+		})
+	}
+	return targetName, nil
+}
+
+// Rules 本次 reverse 重命名的对象映射
+func (m *IdentifierMapper) Rules() []meta.IdentifierNameRule {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rules
+}
@@ -0,0 +1,243 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"go.uber.org/zap"
+	"regexp"
+	"strings"
+)
+
+var (
+	// 降序索引字段表达式 "COL"
+	oracleIndexDescColumnRegex = regexp.MustCompile(`^"([^"]+)"$`)
+	// 单字段函数表达式 UPPER("COL")、SUBSTR("COL",1,4)、NVL("COL",'X')
+	oracleIndexFuncRegex = regexp.MustCompile(`^(?i:(UPPER|LOWER|TRIM|LTRIM|RTRIM|SUBSTR|NVL|TRUNC))\(\s*"([^"]+)"\s*((?:,\s*(?:'[^']*'|[^,()'"]+)\s*)*)\)$`)
+)
+
+const (
+	// MySQL 标识符长度上限
+	mysqlIdentifierMaxLength = 64
+	// 降级索引生成列后缀
+	indexVirtualColumnSuffix = "_VC"
+)
+
+// downgradeIndex 不兼容索引降级为 MySQL 近似索引，返回索引定义以及调整说明
+// 1、位图索引、反向键索引降级为普通 B-Tree 索引
+// 2、函数索引表达式为单字段时按降序索引转换为 DESC 字段
+// 3、函数索引表达式可转换时新增虚拟生成列，并于生成列上创建索引，生成列由 r.indexColumns 输出至建表语句
+// 表达式无法转换返回 false 以及原因
+func (r *Rule) downgradeIndex(indexName string, idxMeta map[string]string, unique bool) (string, string, bool, error) {
+	columnTypes, err := r.genColumnTypeMap()
+	if err != nil {
+		return "", "", false, err
+	}
+
+	var (
+		indexColumns   []string
+		virtualColumns []string
+		adjusts        []string
+	)
+	for i, expr := range splitIndexColumnList(idxMeta["COLUMN_LIST"]) {
+		expr = strings.TrimSpace(expr)
+
+		if !strings.Contains(idxMeta["INDEX_TYPE"], "FUNCTION-BASED") {
+			indexColumns = append(indexColumns, fmt.Sprintf("`%s`", r.columnName(expr)))
+			continue
+		}
+
+		if m := oracleIndexDescColumnRegex.FindStringSubmatch(expr); m != nil {
+			indexColumns = append(indexColumns, fmt.Sprintf("`%s` DESC", r.columnName(m[1])))
+			adjusts = append(adjusts, fmt.Sprintf("%s -> DESC", expr))
+			continue
+		}
+
+		m := oracleIndexFuncRegex.FindStringSubmatch(expr)
+		if m == nil {
+			return "", fmt.Sprintf("function expression [%s] can't convert", expr), false, nil
+		}
+		columnName := r.columnName(m[2])
+		columnType, ok := columnTypes[columnName]
+		if !ok {
+			return "", fmt.Sprintf("function expression [%s] column [%s] isn't exist", expr, m[2]), false, nil
+		}
+		mysqlExpr, exprType, ok := convertIndexFuncExpr(common.StringUPPER(m[1]), columnName, columnType, splitIndexFuncArgs(m[3]))
+		if !ok {
+			return "", fmt.Sprintf("function expression [%s] column type [%s] can't convert", expr, columnType), false, nil
+		}
+		// 大字段生成列索引需指定前缀长度，无法等价
+		if strings.Contains(exprType, "TEXT") || strings.Contains(exprType, "BLOB") || strings.Contains(exprType, "JSON") {
+			return "", fmt.Sprintf("function expression [%s] column type [%s] index isn't support", expr, exprType), false, nil
+		}
+
+		suffix := fmt.Sprintf("%s%d", indexVirtualColumnSuffix, i+1)
+		virtualName := indexName
+		if len(virtualName)+len(suffix) > mysqlIdentifierMaxLength {
+			virtualName = virtualName[:mysqlIdentifierMaxLength-len(suffix)]
+		}
+		virtualName = common.StringsBuilder(virtualName, suffix)
+
+		virtualColumns = append(virtualColumns, fmt.Sprintf("`%s` %s GENERATED ALWAYS AS (%s) VIRTUAL", virtualName, exprType, mysqlExpr))
+		indexColumns = append(indexColumns, fmt.Sprintf("`%s`", virtualName))
+		adjusts = append(adjusts, fmt.Sprintf("%s -> virtual column `%s` AS (%s)", expr, virtualName, mysqlExpr))
+	}
+
+	var keyIndex string
+	if unique {
+		keyIndex = fmt.Sprintf("UNIQUE INDEX `%s` (%s)", indexName, strings.Join(indexColumns, ","))
+	} else {
+		keyIndex = fmt.Sprintf("KEY `%s` (%s)", indexName, strings.Join(indexColumns, ","))
+	}
+	r.indexColumns = append(r.indexColumns, virtualColumns...)
+
+	adjust := "btree index"
+	if len(adjusts) > 0 {
+		adjust = strings.Join(adjusts, ", ")
+	}
+	return keyIndex, adjust, true, nil
+}
+
+// genColumnTypeMap 目标端字段名与转换后数据类型映射
+func (r *Rule) genColumnTypeMap() (map[string]string, error) {
+	columnMetas, err := r.GenTableColumn()
+	if err != nil {
+		return nil, err
+	}
+	columnTypes := make(map[string]string, len(columnMetas))
+	for _, columnMeta := range columnMetas {
+		fields := strings.Fields(columnMeta)
+		columnTypes[strings.Trim(fields[0], "`")] = common.StringUPPER(fields[1])
+	}
+	return columnTypes, nil
+}
+
+// convertIndexFuncExpr oracle 函数表达式转换为 MySQL 表达式，返回表达式以及生成列数据类型
+func convertIndexFuncExpr(funcName, columnName, columnType string, args []string) (string, string, bool) {
+	column := fmt.Sprintf("`%s`", columnName)
+	switch funcName {
+	case "UPPER", "LOWER", "TRIM", "LTRIM", "RTRIM":
+		if len(args) != 0 {
+			return "", "", false
+		}
+		return fmt.Sprintf("%s(%s)", funcName, column), columnType, true
+	case "SUBSTR":
+		if len(args) == 0 || len(args) > 2 {
+			return "", "", false
+		}
+		// oracle 起始位置 0 视作 1，MySQL 返回空串
+		if args[0] == "0" {
+			args[0] = "1"
+		}
+		return fmt.Sprintf("SUBSTRING(%s,%s)", column, strings.Join(args, ",")), columnType, true
+	case "NVL":
+		if len(args) != 1 {
+			return "", "", false
+		}
+		return fmt.Sprintf("IFNULL(%s,%s)", column, args[0]), columnType, true
+	case "TRUNC":
+		if len(args) != 0 {
+			return "", "", false
+		}
+		switch {
+		case strings.HasPrefix(columnType, "DATE"), strings.HasPrefix(columnType, "TIMESTAMP"):
+			return fmt.Sprintf("DATE(%s)", column), "DATE", true
+		case strings.HasPrefix(columnType, "DECIMAL"), strings.HasPrefix(columnType, "DOUBLE"), strings.HasPrefix(columnType, "FLOAT"),
+			strings.Contains(columnType, "INT"):
+			return fmt.Sprintf("TRUNCATE(%s,0)", column), columnType, true
+		}
+	}
+	return "", "", false
+}
+
+// genIndexReport 索引跳过或调整记录，输出至 compatibility 文件
+func (r *Rule) genIndexReport(idxMeta map[string]string, action, detail string) string {
+	return fmt.Sprintf("-- oracle table [%s.%s] index [%s] type [%s] columns [%s] %s: %s",
+		r.SourceSchemaName, r.SourceTableName, idxMeta["INDEX_NAME"], idxMeta["INDEX_TYPE"], idxMeta["COLUMN_LIST"], action, detail)
+}
+
+// splitIndexColumnList COLUMN_LIST 逗号拼接，函数表达式内逗号以及引号内逗号不拆分
+func splitIndexColumnList(columnList string) []string {
+	var (
+		cols     []string
+		sb       strings.Builder
+		depth    int
+		quote    rune
+		inQuoted bool
+	)
+	for _, c := range columnList {
+		switch {
+		case inQuoted:
+			if c == quote {
+				inQuoted = false
+			}
+		case c == '\'' || c == '"':
+			inQuoted, quote = true, c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			cols = append(cols, sb.String())
+			sb.Reset()
+			continue
+		}
+		sb.WriteRune(c)
+	}
+	cols = append(cols, sb.String())
+	return cols
+}
+
+// splitIndexFuncArgs 函数字段之后参数 ,1,4 拆分
+func splitIndexFuncArgs(args string) []string {
+	args = strings.TrimSpace(args)
+	if args == "" {
+		return nil
+	}
+	var res []string
+	for _, a := range splitIndexColumnList(strings.TrimPrefix(args, ",")) {
+		res = append(res, strings.TrimSpace(a))
+	}
+	return res
+}
+
+// genIncompatibleIndex MySQL 不支持索引处理，开启 index-downgrade 时尝试降级，返回降级后索引定义
+// 未开启或降级失败返回空索引定义，跳过原因以及源端索引语句输出至 compatibility 文件
+func (r *Rule) genIncompatibleIndex(indexName string, idxMeta map[string]string, unique bool, sourceSQL string) (string, []string, error) {
+	reason := "mysql not support, index-downgrade is disabled"
+	if r.IndexDowngrade {
+		keyIndex, detail, ok, err := r.downgradeIndex(indexName, idxMeta, unique)
+		if err != nil {
+			return "", nil, err
+		}
+		if ok {
+			return keyIndex, []string{r.genIndexReport(idxMeta, "downgrade", common.StringsBuilder(keyIndex, ", ", detail))}, nil
+		}
+		reason = detail
+	}
+	return "", []string{r.genIndexReport(idxMeta, "skip", reason), sourceSQL}, nil
+}
+
+func (r *Rule) logIndexDowngrade(idxMeta map[string]string, keyIndex string) {
+	zap.L().Warn("reverse index downgrade",
+		zap.String("schema", r.SourceSchemaName),
+		zap.String("table", idxMeta["TABLE_NAME"]),
+		zap.String("index name", idxMeta["INDEX_NAME"]),
+		zap.String("index type", idxMeta["INDEX_TYPE"]),
+		zap.String("index column list", idxMeta["COLUMN_LIST"]),
+		zap.String("index info", keyIndex))
+}
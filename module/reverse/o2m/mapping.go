@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"github.com/wentaojin/transferdb/common"
 	"github.com/wentaojin/transferdb/database/meta"
+	"go.uber.org/zap"
 	"strconv"
 	"strings"
 )
@@ -330,7 +331,8 @@ func OracleTableColumnMapRule(sourceSchema, sourceTable string, column Column, b
 				originColumnType = fmt.Sprintf("%s(%d)", common.BuildInOracleDatatypeNcharVarying, dataLength)
 				buildInColumnType = fmt.Sprintf("%s(%d)", common.StringUPPER(val), dataLength)
 			}
-			return originColumnType, buildInColumnType, nil
+			buildInColumnType, err = adjustMySQLVarcharOverflow(sourceSchema, sourceTable, originColumnType, buildInColumnType, val, column)
+			return originColumnType, buildInColumnType, err
 		} else {
 			return originColumnType, buildInColumnType, fmt.Errorf("oracle table column type [%s] map mysql column type rule isn't exist, please checkin", common.BuildInOracleDatatypeNcharVarying)
 		}
@@ -359,7 +361,8 @@ func OracleTableColumnMapRule(sourceSchema, sourceTable string, column Column, b
 				originColumnType = fmt.Sprintf("%s(%d)", common.BuildInOracleDatatypeNvarchar2, dataLength)
 				buildInColumnType = fmt.Sprintf("%s(%d)", common.StringUPPER(val), dataLength)
 			}
-			return originColumnType, buildInColumnType, nil
+			buildInColumnType, err = adjustMySQLVarcharOverflow(sourceSchema, sourceTable, originColumnType, buildInColumnType, val, column)
+			return originColumnType, buildInColumnType, err
 		} else {
 			return originColumnType, buildInColumnType, fmt.Errorf("oracle table column type [%s] map mysql column type rule isn't exist, please checkin", common.BuildInOracleDatatypeNvarchar2)
 		}
@@ -418,7 +421,8 @@ func OracleTableColumnMapRule(sourceSchema, sourceTable string, column Column, b
 				originColumnType = fmt.Sprintf("%s(%d)", common.BuildInOracleDatatypeVarchar2, dataLength)
 				buildInColumnType = fmt.Sprintf("%s(%d)", common.StringUPPER(val), dataLength)
 			}
-			return originColumnType, buildInColumnType, nil
+			buildInColumnType, err = adjustMySQLVarcharOverflow(sourceSchema, sourceTable, originColumnType, buildInColumnType, val, column)
+			return originColumnType, buildInColumnType, err
 		} else {
 			return originColumnType, buildInColumnType, fmt.Errorf("oracle table column type [%s] map mysql column type rule isn't exist, please checkin", common.BuildInOracleDatatypeVarchar2)
 		}
@@ -431,7 +435,8 @@ func OracleTableColumnMapRule(sourceSchema, sourceTable string, column Column, b
 				originColumnType = fmt.Sprintf("%s(%d)", common.BuildInOracleDatatypeVarchar, dataLength)
 				buildInColumnType = fmt.Sprintf("%s(%d)", common.StringUPPER(val), dataLength)
 			}
-			return originColumnType, buildInColumnType, nil
+			buildInColumnType, err = adjustMySQLVarcharOverflow(sourceSchema, sourceTable, originColumnType, buildInColumnType, val, column)
+			return originColumnType, buildInColumnType, err
 		} else {
 			return originColumnType, buildInColumnType, fmt.Errorf("oracle table column type [%s] map mysql column type rule isn't exist, please checkin", common.BuildInOracleDatatypeVarchar)
 		}
@@ -484,3 +489,33 @@ func OracleTableColumnMapRule(sourceSchema, sourceTable string, column Column, b
 		return originColumnType, buildInColumnType, nil
 	}
 }
+
+// adjustMySQLVarcharOverflow 按长度语义换算字符数，VARCHAR 字符数 × 目标字符集单字符最大字节数超出 65535 字节时转换为 MEDIUMTEXT，避免下游建表失败
+func adjustMySQLVarcharOverflow(sourceSchema, sourceTable, originColumnType, buildInColumnType, targetType string, column Column) (string, error) {
+	var charset string
+	switch common.StringUPPER(targetType) {
+	case "VARCHAR":
+		charset = common.MySQLCharacterSet
+	case "NVARCHAR", "NATIONAL VARCHAR":
+		// MySQL 国家字符集固定 utf8
+		charset = "UTF8"
+	default:
+		return buildInColumnType, nil
+	}
+	charLength, err := common.OracleColumnCharLength(column.CharUsed, column.CharLength, column.DataLength)
+	if err != nil {
+		return buildInColumnType, fmt.Errorf("oracle schema [%s] table [%s] column type [%s] adjust varchar length failed: %v", sourceSchema, sourceTable, originColumnType, err)
+	}
+	if !common.IsMySQLVarcharOverflow(charLength, charset) {
+		return buildInColumnType, nil
+	}
+	zap.L().Warn("oracle column varchar length over mysql max bytes, map to text type",
+		zap.String("schema", sourceSchema),
+		zap.String("table", sourceTable),
+		zap.String("origin column type", originColumnType),
+		zap.String("buildin column type", buildInColumnType),
+		zap.String("charset", charset),
+		zap.Int("char length", charLength),
+		zap.String("convert column type", common.MySQLVarcharOverflowType))
+	return common.MySQLVarcharOverflowType, nil
+}
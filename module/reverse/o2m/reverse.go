@@ -32,11 +32,13 @@ import (
 )
 
 type Reverse struct {
-	Ctx    context.Context
-	Cfg    *config.Config
-	Mysql  *mysql.MySQL
-	Oracle *oracle.Oracle
-	MetaDB *meta.Meta
+	Ctx        context.Context
+	Cfg        *config.Config
+	Mysql      *mysql.MySQL
+	Oracle     *oracle.Oracle
+	MetaDB     *meta.Meta
+	Identifier *IdentifierMapper
+	Decision   *DecisionRecorder
 }
 
 func NewReverse(ctx context.Context, cfg *config.Config) (*Reverse, error) {
@@ -44,6 +46,9 @@ func NewReverse(ctx context.Context, cfg *config.Config) (*Reverse, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err = oracleDB.EnableDictionaryCache(cfg.OracleConfig.DictionaryCache, cfg.OracleConfig.DictionaryCacheTTL); err != nil {
+		return nil, err
+	}
 	mysqlDB, err := mysql.NewMySQLDBEngine(ctx, cfg.MySQLConfig)
 	if err != nil {
 		return nil, err
@@ -53,21 +58,28 @@ func NewReverse(ctx context.Context, cfg *config.Config) (*Reverse, error) {
 		return nil, err
 	}
 	return &Reverse{
-		Ctx:    ctx,
-		Cfg:    cfg,
-		Mysql:  mysqlDB,
-		Oracle: oracleDB,
-		MetaDB: metaDB,
+		Ctx:        ctx,
+		Cfg:        cfg,
+		Mysql:      mysqlDB,
+		Oracle:     oracleDB,
+		MetaDB:     metaDB,
+		Identifier: NewIdentifierMapper(),
+		Decision:   NewDecisionRecorder(),
 	}, nil
 }
 
 func (r *Reverse) Reverse() error {
 	startTime := time.Now()
+	defer func() {
+		if err := r.Oracle.FlushDictionaryCache(); err != nil {
+			zap.L().Warn("flush oracle dictionary cache failed", zap.Error(err))
+		}
+	}()
 	zap.L().Info("reverse table r.Oracle to mysql start",
 		zap.String("schema", r.Cfg.OracleConfig.SchemaName))
 
 	// 获取配置文件待同步表列表
-	exporters, err := filterCFGTable(r.Cfg, r.Oracle)
+	exporters, err := filterCFGTable(r.Ctx, r.Cfg, r.Oracle, r.MetaDB)
 	if err != nil {
 		return err
 	}
@@ -126,9 +138,37 @@ func (r *Reverse) Reverse() error {
 	if err != nil {
 		return err
 	}
+	flashbackTables, temporalTables, err := FilterOracleTemporalTable(r.Cfg, r.Oracle, exporterTables, oracleDBVersion)
+	if err != nil {
+		return err
+	}
+	editioningViews, crosseditionTriggers, editionedObjects, err := FilterOracleEditionObject(r.Cfg, r.Oracle, exporterTables)
+	if err != nil {
+		return err
+	}
+	var schedulerJobs []SchedulerJob
+	if r.Cfg.ReverseConfig.SchedulerJob {
+		schedulerJobs, err = FilterOracleSchedulerJob(r.Cfg, r.Oracle)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 临时表处理策略
+	switch r.Cfg.ReverseConfig.TemporaryTablePolicy {
+	case common.ReverseTemporaryTablePolicyNormal, common.ReverseTemporaryTablePolicyTemporary:
+	case common.ReverseTemporaryTablePolicySkip:
+		exporterTables = common.FilterDifferenceStringItems(exporterTables, temporaryTables)
+	default:
+		return fmt.Errorf("reverse config [temporary-table-policy] value [%s] isn't support, only support normal/temporary/skip", r.Cfg.ReverseConfig.TemporaryTablePolicy)
+	}
 
 	// 获取规则
 	ruleTime := time.Now()
+	tableRewriter, err := r.Cfg.RewriteConfig.TableRewriter()
+	if err != nil {
+		return err
+	}
 	tableNameRuleMap, tableColumnRuleMap, tableDefaultRuleMap, err := IChanger(&Change{
 		Ctx:              r.Ctx,
 		DBTypeS:          r.Cfg.DBTypeS,
@@ -136,6 +176,7 @@ func (r *Reverse) Reverse() error {
 		SourceSchemaName: common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
 		TargetSchemaName: common.StringUPPER(r.Cfg.MySQLConfig.SchemaName),
 		SourceTables:     exporterTables,
+		TableRewriter:    tableRewriter,
 		OracleCollation:  oracleCollation,
 		Threads:          r.Cfg.ReverseConfig.ReverseThreads,
 		Oracle:           r.Oracle,
@@ -167,8 +208,26 @@ func (r *Reverse) Reverse() error {
 		return err
 	}
 
+	// MariaDB 序列
+	err = r.genCreateSequence(f, common.StringUPPER(r.Cfg.OracleConfig.SchemaName), common.StringUPPER(r.Cfg.MySQLConfig.SchemaName))
+	if err != nil {
+		return err
+	}
+
 	// 表类型不兼容项输出
-	err = GenCompatibilityTable(f, common.StringUPPER(r.Cfg.OracleConfig.SchemaName), partitionTables, temporaryTables, clusteredTables, materializedView)
+	err = GenCompatibilityTable(f, common.StringUPPER(r.Cfg.OracleConfig.SchemaName), partitionTables, temporaryTables, clusteredTables, materializedView, flashbackTables, temporalTables, r.Cfg.ReverseConfig.TemporaryTablePolicy)
+	if err != nil {
+		return err
+	}
+
+	// EBR 编辑对象输出
+	err = GenCompatibilityEdition(f, common.StringUPPER(r.Cfg.OracleConfig.SchemaName), editioningViews, crosseditionTriggers, editionedObjects)
+	if err != nil {
+		return err
+	}
+
+	// 调度作业输出
+	err = GenCompatibilitySchedulerJob(f, common.StringUPPER(r.Cfg.OracleConfig.SchemaName), schedulerJobs)
 	if err != nil {
 		return err
 	}
@@ -254,6 +313,9 @@ func (r *Reverse) Reverse() error {
 				return nil
 			}
 
+			if err = r.Decision.Record(rule); err != nil {
+				return fmt.Errorf("record table [%s.%s] reverse decision failed: %v", t.SourceSchemaName, t.SourceTableName, err)
+			}
 			return nil
 		})
 	}
@@ -262,6 +324,59 @@ func (r *Reverse) Reverse() error {
 		return err
 	}
 
+	// 索引、约束重命名映射记录
+	identRules := r.Identifier.Rules()
+	for i := range identRules {
+		identRules[i].DBTypeS = r.Cfg.DBTypeS
+		identRules[i].DBTypeT = r.Cfg.DBTypeT
+	}
+	err = meta.NewIdentifierNameRuleModel(r.MetaDB).ReplaceIdentifierNameRule(r.Ctx, &meta.IdentifierNameRule{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
+	}, identRules)
+	if err != nil {
+		return err
+	}
+	if len(identRules) > 0 {
+		zap.L().Warn("reverse identifier renamed",
+			zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+			zap.Int("renamed totals", len(identRules)),
+			zap.String("rename detail", "please see table [identifier_name_rule]"))
+	}
+
+	// reverse 决策与上一次对比输出差异，并替换为本次决策
+	r.Decision.RecordIdentifier(identRules)
+	decisionS := &meta.ReverseDecision{
+		DBTypeS:     r.Cfg.DBTypeS,
+		DBTypeT:     r.Cfg.DBTypeT,
+		SchemaNameS: common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
+	}
+	prevDecisions, err := meta.NewReverseDecisionModel(r.MetaDB).DetailReverseDecision(r.Ctx, decisionS)
+	if err != nil {
+		return err
+	}
+	currDecisions := r.Decision.Decisions(r.Cfg.DBTypeS, r.Cfg.DBTypeT)
+	decisionDir := r.Cfg.ReverseConfig.DDLReverseDir
+	if r.Cfg.ReverseConfig.DirectWrite {
+		decisionDir = r.Cfg.ReverseConfig.DDLCompatibleDir
+	}
+	decisionFile := filepath.Join(decisionDir, fmt.Sprintf("reverse_decision_%s.diff", r.Cfg.OracleConfig.SchemaName))
+	diffTotals, err := GenReverseDecisionDiff(decisionFile, prevDecisions, currDecisions)
+	if err != nil {
+		return err
+	}
+	if len(prevDecisions) > 0 {
+		zap.L().Warn("reverse decision changed versus previous run",
+			zap.String("schema", r.Cfg.OracleConfig.SchemaName),
+			zap.Int("diff totals", diffTotals),
+			zap.String("diff detail", decisionFile))
+	}
+	err = meta.NewReverseDecisionModel(r.MetaDB).ReplaceReverseDecision(r.Ctx, decisionS, currDecisions)
+	if err != nil {
+		return err
+	}
+
 	err = f.Close()
 	if err != nil {
 		return err
@@ -281,6 +396,10 @@ func (r *Reverse) Reverse() error {
 	if !r.Cfg.ReverseConfig.DirectWrite {
 		zap.L().Info("reverse", zap.String("create table and index output", filepath.Join(r.Cfg.ReverseConfig.DDLReverseDir,
 			fmt.Sprintf("reverse_%s.sql", r.Cfg.OracleConfig.SchemaName))))
+		if r.Cfg.ReverseConfig.SplitByObject {
+			zap.L().Info("reverse", zap.String("split by object execution order", filepath.Join(r.Cfg.ReverseConfig.DDLReverseDir,
+				fmt.Sprintf("README_%s.md", r.Cfg.OracleConfig.SchemaName))))
+		}
 	}
 	zap.L().Info("compatibility", zap.String("maybe exist compatibility output", filepath.Join(r.Cfg.ReverseConfig.DDLCompatibleDir,
 		fmt.Sprintf("compatibility_%s.sql", r.Cfg.OracleConfig.SchemaName))))
@@ -292,12 +411,22 @@ func (r *Reverse) Reverse() error {
 			zap.Int64("reverse failed", errTotals),
 			zap.String("cost", endTime.Sub(startTime).String()))
 	} else {
+		suggestions, err := meta.NewErrorLogDetailModel(r.MetaDB).DistinctErrorSuggestion(r.Ctx, &meta.ErrorLogDetail{
+			DBTypeS:     r.Cfg.DBTypeS,
+			DBTypeT:     r.Cfg.DBTypeT,
+			SchemaNameS: common.StringUPPER(r.Cfg.OracleConfig.SchemaName),
+			TaskMode:    r.Cfg.TaskMode,
+		})
+		if err != nil {
+			return err
+		}
 		zap.L().Warn("reverse table oracle to mysql finished",
 			zap.Int("table totals", len(exporters)),
 			zap.Int("reverse totals", len(tables)),
 			zap.Int("reverse success", len(tables)-int(errTotals)),
 			zap.Int64("reverse failed", errTotals),
 			zap.String("failed tips", "failed detail, please see table [error_log_detail]"),
+			zap.Strings("suggest", suggestions),
 			zap.String("cost", endTime.Sub(startTime).String()))
 	}
 	return nil
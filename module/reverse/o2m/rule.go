@@ -26,9 +26,15 @@ import (
 	"strings"
 )
 
+// "DOC" IS JSON、"DOC" IS JSON (STRICT) WITH UNIQUE KEYS
+var oracleCheckJSONRegex = regexp.MustCompile(`^\s*"?([\w$#]+)"?\s+(?i:IS\s+JSON)(?:\s*\(?\s*(?i:STRICT|LAX)\s*\)?)?(?:\s+(?i:WITH\s+UNIQUE\s+KEYS))?\s*$`)
+
 type Rule struct {
 	*Table
 	*Info
+
+	// 索引降级新增虚拟生成列，GenTableKeys 生成
+	indexColumns []string
 }
 
 type Info struct {
@@ -64,8 +70,16 @@ func (r *Rule) GenCreateTableDDL() (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
+	tableColumns = append(tableColumns, r.indexColumns...)
 
-	tablePrefix = fmt.Sprintf("CREATE TABLE `%s`.`%s`", targetSchema, targetTable)
+	// 临时表策略 TEMPORARY，转换为 MySQL 临时表脚本
+	temporaryTable := false
+	if strings.Contains(r.SourceTableType, common.BuildInOracleTableTypeTemporary) && strings.EqualFold(r.TemporaryTablePolicy, common.ReverseTemporaryTablePolicyTemporary) {
+		temporaryTable = true
+		tablePrefix = fmt.Sprintf("CREATE TEMPORARY TABLE `%s`.`%s`", targetSchema, targetTable)
+	} else {
+		tablePrefix = fmt.Sprintf("CREATE TABLE `%s`.`%s`", targetSchema, targetTable)
+	}
 
 	checkKeys, err = r.GenTableCheckKey()
 	if err != nil {
@@ -91,6 +105,7 @@ func (r *Rule) GenCreateTableDDL() (interface{}, error) {
 		TargetDBType:       r.TargetDBType,
 		TargetDBVersion:    r.TargetDBVersion,
 		TablePrefix:        tablePrefix,
+		TemporaryTable:     temporaryTable,
 		TableColumns:       tableColumns,
 		TableKeys:          tableKeys,
 		TableSuffix:        tableSuffix,
@@ -102,6 +117,8 @@ func (r *Rule) GenCreateTableDDL() (interface{}, error) {
 }
 
 func (r *Rule) GenTableKeys() (tableKeys []string, compatibilityIndexSQL []string, err error) {
+	r.indexColumns = nil
+
 	// 唯一约束/普通索引/唯一索引
 	uniqueKeyMetas, err := r.GenTableUniqueKey()
 	if err != nil {
@@ -169,7 +186,7 @@ func (r *Rule) GenTableSuffix() (string, error) {
 
 	if len(r.PrimaryKeyINFO) > 0 {
 		for _, col := range strings.Split(r.PrimaryKeyINFO[0]["COLUMN_LIST"], ",") {
-			primaryColumns = append(primaryColumns, fmt.Sprintf("`%s`", col))
+			primaryColumns = append(primaryColumns, fmt.Sprintf("`%s`", r.columnName(col)))
 		}
 	}
 
@@ -198,36 +215,12 @@ func (r *Rule) GenTableSuffix() (string, error) {
 	}
 
 	// schema、db、table collation
-	if r.OracleCollation {
-		// table collation
-		if r.SourceTableCollation != "" {
-			if val, ok := common.OracleCollationMap[r.SourceTableCollation]; ok {
-				tableCollation = val
-			} else {
-				return tableSuffix, fmt.Errorf("oracle table collation [%v] isn't support", r.SourceTableCollation)
-			}
-		}
-		// schema collation
-		if r.SourceTableCollation == "" && r.SourceSchemaCollation != "" {
-			if val, ok := common.OracleCollationMap[r.SourceSchemaCollation]; ok {
-				tableCollation = val
-			} else {
-				return tableSuffix, fmt.Errorf("oracle schema collation [%v] table collation [%v] isn't support", r.SourceSchemaCollation, r.SourceTableCollation)
-			}
-		}
-		if r.SourceTableName == "" && r.SourceSchemaCollation == "" {
-			return tableSuffix, fmt.Errorf("oracle schema collation [%v] table collation [%v] isn't support", r.SourceSchemaCollation, r.SourceTableCollation)
-		}
-	} else {
-		// db collation
-		if val, ok := common.OracleCollationMap[r.SourceDBNLSComp]; ok {
-			tableCollation = val
-		} else {
-			return tableSuffix, fmt.Errorf("oracle db nls_comp [%v] nls_sort [%v] isn't support", r.SourceDBNLSComp, r.SourceDBNLSSort)
-		}
+	tableCollation, err = r.genTableCollation()
+	if err != nil {
+		return tableSuffix, err
 	}
 	// table-option 表后缀可选项
-	if strings.EqualFold(r.TargetDBType, common.DatabaseTypeMySQL) || r.TargetTableOption == "" {
+	if !strings.EqualFold(r.TargetDBType, common.DatabaseTypeTiDB) || r.TargetTableOption == "" {
 		zap.L().Warn("reverse oracle table suffix",
 			zap.String("table", r.String()),
 			zap.String("table-option", "table-option is null, would be disabled"))
@@ -334,6 +327,22 @@ func (r *Rule) GenTableSuffix() (string, error) {
 			}
 		}
 	}
+	// TiDB TTL 表属性
+	if r.TargetTableTTL != "" {
+		tidbVersion := r.TargetDBVersion
+		if strings.Contains(tidbVersion, common.TiDBVersionDelimiter) {
+			tidbVersion = strings.Split(tidbVersion, common.TiDBVersionDelimiter)[1]
+		}
+		if strings.EqualFold(r.TargetDBType, common.DatabaseTypeTiDB) && common.VersionOrdinal(tidbVersion) >= common.VersionOrdinal(common.TiDBTableTTLVersion) {
+			tableSuffix = fmt.Sprintf("%s %s TTL_ENABLE = 'ON'", tableSuffix, r.TargetTableTTL)
+		} else {
+			zap.L().Warn("reverse oracle table suffix",
+				zap.String("table", r.String()),
+				zap.String("ttl", r.TargetTableTTL),
+				zap.String("ttl-config", "target db isn't tidb or tidb version less than v6.5.0, would be disabled"))
+		}
+	}
+
 	zap.L().Info("reverse oracle table suffix",
 		zap.String("table", r.String()),
 		zap.String("create table suffix", tableSuffix))
@@ -341,6 +350,37 @@ func (r *Rule) GenTableSuffix() (string, error) {
 	return tableSuffix, nil
 }
 
+// genTableCollation 表排序规则，优先表级、其次 schema 级，低版本取 DB nls_comp
+func (r *Rule) genTableCollation() (string, error) {
+	if !r.OracleCollation {
+		if val, ok := common.OracleCollationToMySQL(r.TargetDBType, r.TargetDBVersion, r.SourceDBNLSComp); ok {
+			return val, nil
+		}
+		return "", fmt.Errorf("oracle db nls_comp [%v] nls_sort [%v] isn't support", r.SourceDBNLSComp, r.SourceDBNLSSort)
+	}
+	var tableCollation string
+	// table collation
+	if r.SourceTableCollation != "" {
+		if val, ok := common.OracleCollationToMySQL(r.TargetDBType, r.TargetDBVersion, r.SourceTableCollation); ok {
+			tableCollation = val
+		} else {
+			return "", fmt.Errorf("oracle table collation [%v] isn't support", r.SourceTableCollation)
+		}
+	}
+	// schema collation
+	if r.SourceTableCollation == "" && r.SourceSchemaCollation != "" {
+		if val, ok := common.OracleCollationToMySQL(r.TargetDBType, r.TargetDBVersion, r.SourceSchemaCollation); ok {
+			tableCollation = val
+		} else {
+			return "", fmt.Errorf("oracle schema collation [%v] table collation [%v] isn't support", r.SourceSchemaCollation, r.SourceTableCollation)
+		}
+	}
+	if r.SourceTableName == "" && r.SourceSchemaCollation == "" {
+		return "", fmt.Errorf("oracle schema collation [%v] table collation [%v] isn't support", r.SourceSchemaCollation, r.SourceTableCollation)
+	}
+	return tableCollation, nil
+}
+
 func (r *Rule) GenTablePrimaryKey() (primaryKeys []string, err error) {
 	if len(r.PrimaryKeyINFO) > 1 {
 		return primaryKeys, fmt.Errorf("oracle schema [%s] table [%s] primary key exist multiple values: [%v]", r.SourceSchemaName, r.SourceTableName, r.PrimaryKeyINFO)
@@ -348,7 +388,7 @@ func (r *Rule) GenTablePrimaryKey() (primaryKeys []string, err error) {
 	if len(r.PrimaryKeyINFO) > 0 {
 		var primaryColumns []string
 		for _, col := range strings.Split(r.PrimaryKeyINFO[0]["COLUMN_LIST"], ",") {
-			primaryColumns = append(primaryColumns, fmt.Sprintf("`%s`", col))
+			primaryColumns = append(primaryColumns, fmt.Sprintf("`%s`", r.columnName(col)))
 		}
 		pk := fmt.Sprintf("PRIMARY KEY (%s)", strings.ToUpper(strings.Join(primaryColumns, ",")))
 		primaryKeys = append(primaryKeys, pk)
@@ -362,10 +402,14 @@ func (r *Rule) GenTableUniqueKey() (uniqueKeys []string, err error) {
 		for _, rowUKCol := range r.UniqueKeyINFO {
 			var ukArr []string
 			for _, col := range strings.Split(rowUKCol["COLUMN_LIST"], ",") {
-				ukArr = append(ukArr, fmt.Sprintf("`%s`", col))
+				ukArr = append(ukArr, fmt.Sprintf("`%s`", r.columnName(col)))
+			}
+			ukName, err := r.GenIdentifierName(common.JSONPUConstraint, rowUKCol["CONSTRAINT_NAME"])
+			if err != nil {
+				return uniqueKeys, err
 			}
 			uk := fmt.Sprintf("UNIQUE KEY `%s` (%s)",
-				strings.ToUpper(rowUKCol["CONSTRAINT_NAME"]), strings.ToUpper(strings.Join(ukArr, ",")))
+				ukName, strings.ToUpper(strings.Join(ukArr, ",")))
 
 			uniqueKeys = append(uniqueKeys, uk)
 		}
@@ -376,9 +420,13 @@ func (r *Rule) GenTableUniqueKey() (uniqueKeys []string, err error) {
 func (r *Rule) GenTableForeignKey() (foreignKeys []string, err error) {
 	if len(r.ForeignKeyINFO) > 0 {
 		for _, rowFKCol := range r.ForeignKeyINFO {
+			fkName, err := r.GenIdentifierName(common.JSONFKConstraint, rowFKCol["CONSTRAINT_NAME"])
+			if err != nil {
+				return foreignKeys, err
+			}
 			if rowFKCol["DELETE_RULE"] == "" || rowFKCol["DELETE_RULE"] == "NO ACTION" {
 				fk := fmt.Sprintf("CONSTRAINT `%s` FOREIGN KEY (%s) REFERENCES `%s`.`%s` (%s)",
-					strings.ToUpper(rowFKCol["CONSTRAINT_NAME"]),
+					fkName,
 					strings.ToUpper(rowFKCol["COLUMN_LIST"]),
 					strings.ToUpper(rowFKCol["R_OWNER"]),
 					strings.ToUpper(rowFKCol["RTABLE_NAME"]),
@@ -387,7 +435,7 @@ func (r *Rule) GenTableForeignKey() (foreignKeys []string, err error) {
 			}
 			if rowFKCol["DELETE_RULE"] == "CASCADE" {
 				fk := fmt.Sprintf("CONSTRAINT `%s` FOREIGN KEY (%s) REFERENCES `%s`.`%s`(%s) ON DELETE CASCADE",
-					strings.ToUpper(rowFKCol["CONSTRAINT_NAME"]),
+					fkName,
 					strings.ToUpper(rowFKCol["COLUMN_LIST"]),
 					strings.ToUpper(rowFKCol["R_OWNER"]),
 					strings.ToUpper(rowFKCol["RTABLE_NAME"]),
@@ -396,7 +444,7 @@ func (r *Rule) GenTableForeignKey() (foreignKeys []string, err error) {
 			}
 			if rowFKCol["DELETE_RULE"] == "SET NULL" {
 				fk := fmt.Sprintf("CONSTRAINT `%s` FOREIGN KEY(%s) REFERENCES `%s`.`%s`(%s) ON DELETE SET NULL",
-					strings.ToUpper(rowFKCol["CONSTRAINT_NAME"]),
+					fkName,
 					strings.ToUpper(rowFKCol["COLUMN_LIST"]),
 					strings.ToUpper(rowFKCol["R_OWNER"]),
 					strings.ToUpper(rowFKCol["RTABLE_NAME"]),
@@ -434,9 +482,13 @@ func (r *Rule) GenTableCheckKey() (checkKeys []string, err error) {
 
 			if !reg.MatchString(s) {
 				if !matchRex.MatchString(s) {
+					ckName, err := r.GenIdentifierName(common.JSONCKConstraint, rowCKCol["CONSTRAINT_NAME"])
+					if err != nil {
+						return checkKeys, err
+					}
 					checkKeys = append(checkKeys, fmt.Sprintf("CONSTRAINT `%s` CHECK (%s)",
-						strings.ToUpper(rowCKCol["CONSTRAINT_NAME"]),
-						rowCKCol["SEARCH_CONDITION"]))
+						ckName,
+						r.genCheckJSONCondition(rowCKCol["SEARCH_CONDITION"])))
 				}
 			} else {
 
@@ -480,8 +532,12 @@ func (r *Rule) GenTableCheckKey() (checkKeys []string, err error) {
 					d = d[:len(d)-1]
 				}
 
+				ckName, err := r.GenIdentifierName(common.JSONCKConstraint, rowCKCol["CONSTRAINT_NAME"])
+				if err != nil {
+					return checkKeys, err
+				}
 				checkKeys = append(checkKeys, fmt.Sprintf("CONSTRAINT `%s` CHECK (%s)",
-					strings.ToUpper(rowCKCol["CONSTRAINT_NAME"]),
+					ckName,
 					strings.Join(d, " ")))
 			}
 		}
@@ -490,18 +546,37 @@ func (r *Rule) GenTableCheckKey() (checkKeys []string, err error) {
 	return checkKeys, nil
 }
 
+// genCheckJSONCondition oracle JSON 校验约束 col IS JSON [STRICT|LAX] [WITH UNIQUE KEYS] 转换为 JSON_VALID(col)
+// MySQL 8.0.16+ 以及 MariaDB 10.2+ 均支持，MariaDB JSON 类型为 LONGTEXT 别名，同样依赖 JSON_VALID 校验
+func (r *Rule) genCheckJSONCondition(cond string) string {
+	matches := oracleCheckJSONRegex.FindStringSubmatch(cond)
+	if matches == nil {
+		return cond
+	}
+	// 未加双引号字段名 oracle 存储为大写
+	columnName := matches[1]
+	if !strings.HasPrefix(strings.TrimSpace(cond), `"`) {
+		columnName = common.StringUPPER(columnName)
+	}
+	return fmt.Sprintf("JSON_VALID(`%s`)", r.columnName(columnName))
+}
+
 func (r *Rule) GenTableUniqueIndex() (uniqueIndexes []string, compatibilityIndexSQL []string, err error) {
 	if len(r.UniqueIndexINFO) > 0 {
 		for _, idxMeta := range r.UniqueIndexINFO {
 			if idxMeta["TABLE_NAME"] != "" && strings.ToUpper(idxMeta["UNIQUENESS"]) == "UNIQUE" {
+				indexName, err := r.GenIdentifierName(common.JSONIndex, idxMeta["INDEX_NAME"])
+				if err != nil {
+					return uniqueIndexes, compatibilityIndexSQL, err
+				}
 				switch idxMeta["INDEX_TYPE"] {
 				case "NORMAL":
 					var uniqueIndex []string
 					for _, col := range strings.Split(idxMeta["COLUMN_LIST"], ",") {
-						uniqueIndex = append(uniqueIndex, fmt.Sprintf("`%s`", col))
+						uniqueIndex = append(uniqueIndex, fmt.Sprintf("`%s`", r.columnName(col)))
 					}
 
-					uniqueIDX := fmt.Sprintf("UNIQUE INDEX `%s` (%s)", strings.ToUpper(idxMeta["INDEX_NAME"]), strings.Join(uniqueIndex, ","))
+					uniqueIDX := fmt.Sprintf("UNIQUE INDEX `%s` (%s)", indexName, strings.Join(uniqueIndex, ","))
 
 					uniqueIndexes = append(uniqueIndexes, uniqueIDX)
 
@@ -515,12 +590,26 @@ func (r *Rule) GenTableUniqueIndex() (uniqueIndexes []string, compatibilityIndex
 
 					continue
 
-				case "FUNCTION-BASED NORMAL":
+				case "FUNCTION-BASED NORMAL", "NORMAL/REV":
 					sql := fmt.Sprintf("CREATE UNIQUE INDEX `%s` ON `%s`.`%s` (%s);",
-						strings.ToUpper(idxMeta["INDEX_NAME"]), r.TargetSchemaName, r.TargetTableName,
+						indexName, r.TargetSchemaName, r.TargetTableName,
 						idxMeta["COLUMN_LIST"])
+					if idxMeta["INDEX_TYPE"] == "NORMAL/REV" {
+						sql = fmt.Sprintf("CREATE UNIQUE INDEX `%s` ON `%s`.`%s` (%s) REVERSE;",
+							indexName, r.TargetSchemaName, r.TargetTableName,
+							idxMeta["COLUMN_LIST"])
+					}
 
-					compatibilityIndexSQL = append(compatibilityIndexSQL, sql)
+					uniqueIDX, compSQL, err := r.genIncompatibleIndex(indexName, idxMeta, true, sql)
+					if err != nil {
+						return uniqueIndexes, compatibilityIndexSQL, err
+					}
+					compatibilityIndexSQL = append(compatibilityIndexSQL, compSQL...)
+					if uniqueIDX != "" {
+						uniqueIndexes = append(uniqueIndexes, uniqueIDX)
+						r.logIndexDowngrade(idxMeta, uniqueIDX)
+						continue
+					}
 
 					zap.L().Warn("reverse unique key",
 						zap.String("schema", r.SourceSchemaName),
@@ -564,14 +653,18 @@ func (r *Rule) GenTableNormalIndex() (normalIndexes []string, compatibilityIndex
 	if len(r.NormalIndexINFO) > 0 {
 		for _, idxMeta := range r.NormalIndexINFO {
 			if idxMeta["TABLE_NAME"] != "" && strings.ToUpper(idxMeta["UNIQUENESS"]) == "NONUNIQUE" {
+				indexName, err := r.GenIdentifierName(common.JSONIndex, idxMeta["INDEX_NAME"])
+				if err != nil {
+					return normalIndexes, compatibilityIndexSQL, err
+				}
 				switch idxMeta["INDEX_TYPE"] {
 				case "NORMAL":
 					var normalIndex []string
 					for _, col := range strings.Split(idxMeta["COLUMN_LIST"], ",") {
-						normalIndex = append(normalIndex, fmt.Sprintf("`%s`", col))
+						normalIndex = append(normalIndex, fmt.Sprintf("`%s`", r.columnName(col)))
 					}
 
-					keyIndex := fmt.Sprintf("KEY `%s` (%s)", strings.ToUpper(idxMeta["INDEX_NAME"]), strings.Join(normalIndex, ","))
+					keyIndex := fmt.Sprintf("KEY `%s` (%s)", indexName, strings.Join(normalIndex, ","))
 
 					normalIndexes = append(normalIndexes, keyIndex)
 
@@ -585,12 +678,26 @@ func (r *Rule) GenTableNormalIndex() (normalIndexes []string, compatibilityIndex
 
 					continue
 
-				case "FUNCTION-BASED NORMAL":
+				case "FUNCTION-BASED NORMAL", "NORMAL/REV":
 					sql := fmt.Sprintf("CREATE INDEX %s ON %s.%s (%s);",
-						strings.ToUpper(idxMeta["INDEX_NAME"]), r.TargetSchemaName, r.TargetTableName,
+						indexName, r.TargetSchemaName, r.TargetTableName,
 						idxMeta["COLUMN_LIST"])
+					if idxMeta["INDEX_TYPE"] == "NORMAL/REV" {
+						sql = fmt.Sprintf("CREATE INDEX %s ON %s.%s (%s) REVERSE;",
+							indexName, r.TargetSchemaName, r.TargetTableName,
+							idxMeta["COLUMN_LIST"])
+					}
 
-					compatibilityIndexSQL = append(compatibilityIndexSQL, sql)
+					keyIndex, compSQL, err := r.genIncompatibleIndex(indexName, idxMeta, false, sql)
+					if err != nil {
+						return normalIndexes, compatibilityIndexSQL, err
+					}
+					compatibilityIndexSQL = append(compatibilityIndexSQL, compSQL...)
+					if keyIndex != "" {
+						normalIndexes = append(normalIndexes, keyIndex)
+						r.logIndexDowngrade(idxMeta, keyIndex)
+						continue
+					}
 
 					zap.L().Warn("reverse normal index",
 						zap.String("schema", r.SourceSchemaName),
@@ -604,10 +711,19 @@ func (r *Rule) GenTableNormalIndex() (normalIndexes []string, compatibilityIndex
 
 				case "BITMAP":
 					sql := fmt.Sprintf("CREATE BITMAP INDEX %s ON %s.%s (%s);",
-						strings.ToUpper(idxMeta["INDEX_NAME"]), r.TargetSchemaName, r.TargetTableName,
+						indexName, r.TargetSchemaName, r.TargetTableName,
 						idxMeta["COLUMN_LIST"])
 
-					compatibilityIndexSQL = append(compatibilityIndexSQL, sql)
+					keyIndex, compSQL, err := r.genIncompatibleIndex(indexName, idxMeta, false, sql)
+					if err != nil {
+						return normalIndexes, compatibilityIndexSQL, err
+					}
+					compatibilityIndexSQL = append(compatibilityIndexSQL, compSQL...)
+					if keyIndex != "" {
+						normalIndexes = append(normalIndexes, keyIndex)
+						r.logIndexDowngrade(idxMeta, keyIndex)
+						continue
+					}
 
 					zap.L().Warn("reverse normal index",
 						zap.String("schema", r.SourceSchemaName),
@@ -621,10 +737,19 @@ func (r *Rule) GenTableNormalIndex() (normalIndexes []string, compatibilityIndex
 
 				case "FUNCTION-BASED BITMAP":
 					sql := fmt.Sprintf("CREATE BITMAP INDEX %s ON %s.%s (%s);",
-						strings.ToUpper(idxMeta["INDEX_NAME"]), r.TargetSchemaName, r.TargetTableName,
+						indexName, r.TargetSchemaName, r.TargetTableName,
 						idxMeta["COLUMN_LIST"])
 
-					compatibilityIndexSQL = append(compatibilityIndexSQL, sql)
+					keyIndex, compSQL, err := r.genIncompatibleIndex(indexName, idxMeta, false, sql)
+					if err != nil {
+						return normalIndexes, compatibilityIndexSQL, err
+					}
+					compatibilityIndexSQL = append(compatibilityIndexSQL, compSQL...)
+					if keyIndex != "" {
+						normalIndexes = append(normalIndexes, keyIndex)
+						r.logIndexDowngrade(idxMeta, keyIndex)
+						continue
+					}
 
 					zap.L().Warn("reverse normal index",
 						zap.String("schema", r.SourceSchemaName),
@@ -638,13 +763,14 @@ func (r *Rule) GenTableNormalIndex() (normalIndexes []string, compatibilityIndex
 
 				case "DOMAIN":
 					sql := fmt.Sprintf("CREATE INDEX %s ON %s.%s (%s) INDEXTYPE IS %s.%s PARAMETERS ('%s');",
-						strings.ToUpper(idxMeta["INDEX_NAME"]), r.TargetSchemaName, r.TargetTableName,
+						indexName, r.TargetSchemaName, r.TargetTableName,
 						idxMeta["COLUMN_LIST"],
 						strings.ToUpper(idxMeta["ITYP_OWNER"]),
 						strings.ToUpper(idxMeta["ITYP_NAME"]),
 						idxMeta["PARAMETERS"])
 
-					compatibilityIndexSQL = append(compatibilityIndexSQL, sql)
+					// DOMAIN 索引（全文、空间等）依赖 oracle 扩展索引类型，不降级
+					compatibilityIndexSQL = append(compatibilityIndexSQL, r.genIndexReport(idxMeta, "skip", "domain index mysql not support"), sql)
 
 					zap.L().Warn("reverse normal index",
 						zap.String("schema", r.SourceSchemaName),
@@ -691,6 +817,14 @@ func (r *Rule) GenTableNormalIndex() (normalIndexes []string, compatibilityIndex
 	return normalIndexes, compatibilityIndexSQL, err
 }
 
+// GenIdentifierName 索引、约束名超长截断及冲突重命名
+func (r *Rule) GenIdentifierName(objectType, sourceName string) (string, error) {
+	if r.Identifier == nil {
+		return strings.ToUpper(sourceName), nil
+	}
+	return r.Identifier.Rename(r.Table, objectType, sourceName)
+}
+
 func (r *Rule) GenTableComment() (tableComment string, err error) {
 	if len(r.TableColumnINFO) > 0 && r.TableCommentINFO[0]["COMMENTS"] != "" {
 		tableComment = fmt.Sprintf("COMMENT='%s'", r.TableCommentINFO[0]["COMMENTS"])
@@ -699,6 +833,15 @@ func (r *Rule) GenTableComment() (tableComment string, err error) {
 }
 
 func (r *Rule) GenTableColumn() (tableColumns []string, err error) {
+	// 字段名改写后不允许重名
+	var columnNames []string
+	for _, rowCol := range r.TableColumnINFO {
+		columnNames = append(columnNames, rowCol["COLUMN_NAME"])
+	}
+	if _, err = common.GenNameRewriteMap(nil, columnNames, r.ColumnRewriter); err != nil {
+		return tableColumns, fmt.Errorf("oracle table [%s.%s] column rewrite failed: %v", r.SourceSchemaName, r.SourceTableName, err)
+	}
+
 	for _, rowCol := range r.TableColumnINFO {
 		var (
 			columnCollation string
@@ -709,7 +852,7 @@ func (r *Rule) GenTableColumn() (tableColumns []string, err error) {
 		)
 		if r.OracleCollation {
 			// 字段排序规则检查
-			if collationMapVal, ok := common.OracleCollationMap[strings.ToUpper(rowCol["COLLATION"])]; ok {
+			if collationMapVal, ok := common.OracleCollationToMySQL(r.TargetDBType, r.TargetDBVersion, rowCol["COLLATION"]); ok {
 				columnCollation = collationMapVal
 			} else {
 				// 字段数值数据类型不存在排序规则，排除忽略
@@ -750,21 +893,21 @@ func (r *Rule) GenTableColumn() (tableColumns []string, err error) {
 		if nullable == "NULL" {
 			switch {
 			case columnCollation != "" && comment != "" && dataDefault != "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s DEFAULT %s COMMENT %s", rowCol["COLUMN_NAME"], columnType, columnCollation, dataDefault, comment))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s DEFAULT %s COMMENT %s", r.columnName(rowCol["COLUMN_NAME"]), columnType, columnCollation, dataDefault, comment))
 			case columnCollation != "" && comment == "" && dataDefault != "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s DEFAULT %s", rowCol["COLUMN_NAME"], columnType, columnCollation, dataDefault))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s DEFAULT %s", r.columnName(rowCol["COLUMN_NAME"]), columnType, columnCollation, dataDefault))
 			case columnCollation != "" && comment == "" && dataDefault == "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s", rowCol["COLUMN_NAME"], columnType, columnCollation))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s", r.columnName(rowCol["COLUMN_NAME"]), columnType, columnCollation))
 			case columnCollation != "" && comment != "" && dataDefault == "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s COMMENT %s", rowCol["COLUMN_NAME"], columnType, columnCollation, comment))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s COMMENT %s", r.columnName(rowCol["COLUMN_NAME"]), columnType, columnCollation, comment))
 			case columnCollation == "" && comment != "" && dataDefault != "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s DEFAULT %s COMMENT %s", rowCol["COLUMN_NAME"], columnType, dataDefault, comment))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s DEFAULT %s COMMENT %s", r.columnName(rowCol["COLUMN_NAME"]), columnType, dataDefault, comment))
 			case columnCollation == "" && comment == "" && dataDefault != "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s DEFAULT %s", rowCol["COLUMN_NAME"], columnType, dataDefault))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s DEFAULT %s", r.columnName(rowCol["COLUMN_NAME"]), columnType, dataDefault))
 			case columnCollation == "" && comment == "" && dataDefault == "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s", rowCol["COLUMN_NAME"], columnType))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s", r.columnName(rowCol["COLUMN_NAME"]), columnType))
 			case columnCollation == "" && comment != "" && dataDefault == "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COMMENT %s", rowCol["COLUMN_NAME"], columnType, comment))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COMMENT %s", r.columnName(rowCol["COLUMN_NAME"]), columnType, comment))
 			default:
 				return tableColumns, fmt.Errorf("error on gen oracle schema table column meta with nullable, rule: %v", r.String())
 			}
@@ -772,21 +915,21 @@ func (r *Rule) GenTableColumn() (tableColumns []string, err error) {
 			switch {
 			case columnCollation != "" && comment != "" && dataDefault != "":
 				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s %s DEFAULT %s COMMENT %s",
-					rowCol["COLUMN_NAME"], columnType, columnCollation, nullable, dataDefault, comment))
+					r.columnName(rowCol["COLUMN_NAME"]), columnType, columnCollation, nullable, dataDefault, comment))
 			case columnCollation != "" && comment != "" && dataDefault == "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s %s COMMENT %s", rowCol["COLUMN_NAME"], columnType, columnCollation, nullable, comment))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s %s COMMENT %s", r.columnName(rowCol["COLUMN_NAME"]), columnType, columnCollation, nullable, comment))
 			case columnCollation != "" && comment == "" && dataDefault != "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s %s DEFAULT %s", rowCol["COLUMN_NAME"], columnType, columnCollation, nullable, dataDefault))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s %s DEFAULT %s", r.columnName(rowCol["COLUMN_NAME"]), columnType, columnCollation, nullable, dataDefault))
 			case columnCollation != "" && comment == "" && dataDefault == "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s %s", rowCol["COLUMN_NAME"], columnType, columnCollation, nullable))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s COLLATE %s %s", r.columnName(rowCol["COLUMN_NAME"]), columnType, columnCollation, nullable))
 			case columnCollation == "" && comment != "" && dataDefault != "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s %s DEFAULT %s COMMENT %s", rowCol["COLUMN_NAME"], columnType, nullable, dataDefault, comment))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s %s DEFAULT %s COMMENT %s", r.columnName(rowCol["COLUMN_NAME"]), columnType, nullable, dataDefault, comment))
 			case columnCollation == "" && comment != "" && dataDefault == "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s %s COMMENT %s", rowCol["COLUMN_NAME"], columnType, nullable, comment))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s %s COMMENT %s", r.columnName(rowCol["COLUMN_NAME"]), columnType, nullable, comment))
 			case columnCollation == "" && comment == "" && dataDefault != "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s %s DEFAULT %s", rowCol["COLUMN_NAME"], columnType, nullable, dataDefault))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s %s DEFAULT %s", r.columnName(rowCol["COLUMN_NAME"]), columnType, nullable, dataDefault))
 			case columnCollation == "" && comment == "" && dataDefault == "":
-				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s %s", rowCol["COLUMN_NAME"], columnType, nullable))
+				tableColumns = append(tableColumns, fmt.Sprintf("`%s` %s %s", r.columnName(rowCol["COLUMN_NAME"]), columnType, nullable))
 			default:
 				return tableColumns, fmt.Errorf("error on gen oracle schema table column meta without nullable, rule: %v", r.String())
 			}
@@ -796,6 +939,12 @@ func (r *Rule) GenTableColumn() (tableColumns []string, err error) {
 	return tableColumns, nil
 }
 
+// columnName 字段名正则改写，外键、检查约束以及函数索引表达式不做改写
+func (r *Rule) columnName(sourceName string) string {
+	name, _, _ := r.ColumnRewriter.Rewrite(sourceName)
+	return name
+}
+
 func (r *Rule) GenTableColumnComment() (columnComments []string, err error) {
 	// O2M Skip
 	return
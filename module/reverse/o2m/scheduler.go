@@ -0,0 +1,262 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/oracle"
+	"github.com/wentaojin/transferdb/module/reverse"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchedulerJob oracle 调度作业以及转换后 cron / event 模板
+type SchedulerJob struct {
+	JobName        string
+	JobType        string
+	JobAction      string
+	RepeatInterval string
+	Enabled        string
+	Cron           string
+	Event          string
+	Manual         []string
+}
+
+// FilterOracleSchedulerJob 获取 DBMS_SCHEDULER 以及 DBMS_JOB 作业
+func FilterOracleSchedulerJob(cfg *config.Config, oracle *oracle.Oracle) ([]SchedulerJob, error) {
+	schemaName := common.StringUPPER(cfg.OracleConfig.SchemaName)
+
+	schedulerJobs, err := oracle.GetOracleSchemaSchedulerJob(schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("error on filter r.Oracle scheduler job: %v", err)
+	}
+	legacyJobs, err := oracle.GetOracleSchemaLegacyJob(schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("error on filter r.Oracle legacy job: %v", err)
+	}
+
+	var jobs []SchedulerJob
+	for _, j := range append(schedulerJobs, legacyJobs...) {
+		jobs = append(jobs, genSchedulerJob(common.StringUPPER(cfg.MySQLConfig.SchemaName), j))
+	}
+
+	if len(jobs) != 0 {
+		zap.L().Warn("scheduler jobs",
+			zap.String("schema", cfg.OracleConfig.SchemaName),
+			zap.Int("scheduler jobs", len(schedulerJobs)),
+			zap.Int("legacy jobs", len(legacyJobs)),
+			zap.String("suggest", "scheduler job isn't migrated, see compatibility output cron/event template"))
+	}
+	return jobs, nil
+}
+
+// GenCompatibilitySchedulerJob 调度作业清单以及 cron / event 模板输出
+func GenCompatibilitySchedulerJob(f *reverse.Write, sourceSchema string, jobs []SchedulerJob) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+	startTime := time.Now()
+
+	var sqlComp strings.Builder
+	sqlComp.WriteString("/*\n")
+	sqlComp.WriteString(" oracle scheduler jobs, will skip convert to reverse, please port with cron or mysql event scheduler before cutover\n")
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"SCHEMA", "JOB NAME", "JOB TYPE", "REPEAT INTERVAL", "ENABLED", "CRON", "SUGGEST"})
+	for _, j := range jobs {
+		suggest := "Template Generated"
+		if len(j.Manual) > 0 {
+			suggest = common.StringsBuilder("Manual Port: ", strings.Join(j.Manual, "; "))
+		}
+		t.AppendRows([]table.Row{
+			{sourceSchema, j.JobName, j.JobType, j.RepeatInterval, j.Enabled, j.Cron, suggest},
+		})
+	}
+	sqlComp.WriteString(t.Render() + "\n")
+	sqlComp.WriteString("*/\n")
+
+	// 模板以注释输出，避免误执行
+	for _, j := range jobs {
+		sqlComp.WriteString(fmt.Sprintf("-- scheduler job [%s.%s] action:\n", sourceSchema, j.JobName))
+		for _, line := range strings.Split(strings.TrimSpace(j.JobAction), "\n") {
+			sqlComp.WriteString(fmt.Sprintf("--   %s\n", line))
+		}
+		if j.Cron != "" {
+			sqlComp.WriteString(fmt.Sprintf("-- cron template:\n--   %s <command, eg: mysql -e \"CALL ...\">\n", j.Cron))
+		}
+		if j.Event != "" {
+			sqlComp.WriteString(fmt.Sprintf("-- event scheduler template:\n--   %s\n", j.Event))
+		}
+		sqlComp.WriteString("\n")
+	}
+
+	if _, err := f.CWriteFile(sqlComp.String()); err != nil {
+		return err
+	}
+
+	zap.L().Info("output oracle to mysql scheduler job tips",
+		zap.String("schema", sourceSchema),
+		zap.Int("jobs", len(jobs)),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
+
+func genSchedulerJob(targetSchema string, j map[string]string) SchedulerJob {
+	job := SchedulerJob{
+		JobName:        j["JOB_NAME"],
+		JobType:        common.StringUPPER(j["JOB_TYPE"]),
+		JobAction:      schedulerJobValue(j["JOB_ACTION"]),
+		RepeatInterval: schedulerJobValue(j["REPEAT_INTERVAL"]),
+		Enabled:        j["ENABLED"],
+	}
+
+	// 调度转换，仅支持日历表达式 FREQ=...，日期表达式以及事件/窗口调度需人工处理
+	var every string
+	switch {
+	case job.RepeatInterval == "":
+		job.Manual = append(job.Manual, fmt.Sprintf("schedule type [%s] without repeat interval", j["SCHEDULE_TYPE"]))
+	case !strings.HasPrefix(common.StringUPPER(strings.TrimSpace(job.RepeatInterval)), "FREQ"):
+		job.Manual = append(job.Manual, "repeat interval is pl/sql date expression")
+	default:
+		cron, interval, err := genSchedulerCron(job.RepeatInterval)
+		if err != nil {
+			job.Manual = append(job.Manual, err.Error())
+		} else {
+			job.Cron = cron
+			every = interval
+		}
+	}
+
+	// 执行内容转换，仅存储过程可直接 CALL，PL/SQL 块需改写，外部程序仅支持 cron
+	var body string
+	switch job.JobType {
+	case "STORED_PROCEDURE":
+		body = fmt.Sprintf("CALL `%s`.%s()", targetSchema, strings.ToLower(job.JobAction))
+	case "PLSQL_BLOCK":
+		job.Manual = append(job.Manual, "pl/sql block action need rewrite")
+	case "EXECUTABLE", "EXTERNAL_SCRIPT":
+		job.Manual = append(job.Manual, "os executable action only port to cron")
+	default:
+		job.Manual = append(job.Manual, fmt.Sprintf("job type [%s] action need rewrite", job.JobType))
+	}
+
+	if every != "" {
+		if body == "" {
+			body = "BEGIN /* TODO: rewrite job action */ END"
+		}
+		job.Event = fmt.Sprintf("CREATE EVENT IF NOT EXISTS `%s`.`%s` ON SCHEDULE EVERY %s %s DO %s;",
+			targetSchema, strings.ToLower(job.JobName), every, genSchedulerEventStart(job.RepeatInterval), body)
+	}
+	return job
+}
+
+// genSchedulerCron 日历表达式转换 cron 表达式以及 event EVERY 间隔
+// 例如 FREQ=DAILY;BYHOUR=2;BYMINUTE=30 -> 30 2 * * *，1 DAY
+func genSchedulerCron(repeatInterval string) (string, string, error) {
+	calendar := parseSchedulerCalendar(repeatInterval)
+
+	interval := 1
+	if val, ok := calendar["INTERVAL"]; ok {
+		i, err := strconv.Atoi(val)
+		if err != nil || i <= 0 {
+			return "", "", fmt.Errorf("repeat interval [%s] interval value isn't support", repeatInterval)
+		}
+		interval = i
+	}
+	for key := range calendar {
+		if !common.IsContainString([]string{"FREQ", "INTERVAL", "BYMINUTE", "BYHOUR", "BYDAY", "BYMONTHDAY", "BYMONTH", "BYSECOND"}, key) {
+			return "", "", fmt.Errorf("repeat interval [%s] clause [%s] isn't support", repeatInterval, key)
+		}
+	}
+
+	minute := schedulerCronField(calendar["BYMINUTE"], "0")
+	hour := schedulerCronField(calendar["BYHOUR"], "0")
+	step := "*"
+	if interval > 1 {
+		step = fmt.Sprintf("*/%d", interval)
+	}
+
+	switch calendar["FREQ"] {
+	case "MINUTELY":
+		return fmt.Sprintf("%s * * * *", step), fmt.Sprintf("%d MINUTE", interval), nil
+	case "HOURLY":
+		return fmt.Sprintf("%s %s * * *", minute, step), fmt.Sprintf("%d HOUR", interval), nil
+	case "DAILY":
+		return fmt.Sprintf("%s %s %s * *", minute, hour, step), fmt.Sprintf("%d DAY", interval), nil
+	case "WEEKLY":
+		if interval > 1 {
+			return "", "", fmt.Errorf("repeat interval [%s] weekly interval isn't support by cron", repeatInterval)
+		}
+		return fmt.Sprintf("%s %s * * %s", minute, hour, schedulerCronField(calendar["BYDAY"], "*")), "1 WEEK", nil
+	case "MONTHLY":
+		return fmt.Sprintf("%s %s %s %s *", minute, hour, schedulerCronField(calendar["BYMONTHDAY"], "1"), step), fmt.Sprintf("%d MONTH", interval), nil
+	case "YEARLY":
+		return fmt.Sprintf("%s %s %s %s *", minute, hour, schedulerCronField(calendar["BYMONTHDAY"], "1"), schedulerCronField(calendar["BYMONTH"], "1")), fmt.Sprintf("%d YEAR", interval), nil
+	default:
+		return "", "", fmt.Errorf("repeat interval [%s] freq isn't support", repeatInterval)
+	}
+}
+
+// genSchedulerEventStart event 首次执行时间，按 BYHOUR/BYMINUTE 对齐当天
+func genSchedulerEventStart(repeatInterval string) string {
+	calendar := parseSchedulerCalendar(repeatInterval)
+	hour, minute := calendar["BYHOUR"], calendar["BYMINUTE"]
+	if hour == "" && minute == "" {
+		return "STARTS CURRENT_TIMESTAMP"
+	}
+	if strings.Contains(hour, ",") || strings.Contains(minute, ",") {
+		return "STARTS CURRENT_TIMESTAMP"
+	}
+	if hour == "" {
+		hour = "0"
+	}
+	if minute == "" {
+		minute = "0"
+	}
+	return fmt.Sprintf("STARTS CURRENT_DATE + INTERVAL %s HOUR + INTERVAL %s MINUTE", hour, minute)
+}
+
+func parseSchedulerCalendar(repeatInterval string) map[string]string {
+	calendar := make(map[string]string)
+	for _, clause := range strings.Split(common.StringUPPER(repeatInterval), ";") {
+		kv := strings.SplitN(strings.TrimSpace(clause), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		calendar[strings.TrimSpace(kv[0])] = strings.ReplaceAll(strings.TrimSpace(kv[1]), " ", "")
+	}
+	return calendar
+}
+
+func schedulerCronField(val, defaultVal string) string {
+	if val == "" {
+		return defaultVal
+	}
+	return val
+}
+
+// schedulerJobValue oracle 空字符串即 NULL，查询结果统一为 NULLABLE
+func schedulerJobValue(val string) string {
+	if val == "NULLABLE" {
+		return ""
+	}
+	return val
+}
@@ -0,0 +1,101 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/module/reverse"
+	"go.uber.org/zap"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// GenCreateSequence oracle 序列转换 MariaDB 序列（10.3+），起始值取 LAST_NUMBER 保证目标端不分配已使用值
+// MariaDB 序列取值范围为 BIGINT，超出范围（oracle 默认 MAXVALUE 28 位）按不限制处理；标识列系统序列（ISEQ$$_）跳过
+func GenCreateSequence(targetSchema string, sequences []map[string]string) []string {
+	var sqls []string
+	for _, s := range sequences {
+		if strings.HasPrefix(s["SEQUENCE_NAME"], "ISEQ$$_") {
+			continue
+		}
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("CREATE SEQUENCE IF NOT EXISTS `%s`.`%s` INCREMENT BY %s", targetSchema, s["SEQUENCE_NAME"], s["INCREMENT_BY"]))
+		if v, err := strconv.ParseInt(s["MIN_VALUE"], 10, 64); err == nil && v > math.MinInt64 {
+			sb.WriteString(common.StringsBuilder(" MINVALUE ", s["MIN_VALUE"]))
+		} else {
+			sb.WriteString(" NO MINVALUE")
+		}
+		if v, err := strconv.ParseInt(s["MAX_VALUE"], 10, 64); err == nil && v < math.MaxInt64 {
+			sb.WriteString(common.StringsBuilder(" MAXVALUE ", s["MAX_VALUE"]))
+		} else {
+			sb.WriteString(" NO MAXVALUE")
+		}
+		sb.WriteString(common.StringsBuilder(" START WITH ", s["LAST_NUMBER"]))
+		if s["CACHE_SIZE"] == "0" || s["CACHE_SIZE"] == "" {
+			sb.WriteString(" NOCACHE")
+		} else {
+			sb.WriteString(common.StringsBuilder(" CACHE ", s["CACHE_SIZE"]))
+		}
+		if strings.EqualFold(s["CYCLE_FLAG"], "Y") {
+			sb.WriteString(" CYCLE")
+		}
+		sb.WriteString(";")
+		sqls = append(sqls, sb.String())
+	}
+	return sqls
+}
+
+// genCreateSequence MariaDB 目标端 schema 级序列，先于建表生成；MySQL/TiDB 以及低版本 MariaDB 不支持序列，仅告警
+func (r *Reverse) genCreateSequence(f *reverse.Write, sourceSchema, targetSchema string) error {
+	if !strings.EqualFold(r.Cfg.MySQLConfig.DBType, common.DatabaseTypeMariaDB) {
+		return nil
+	}
+	mysqlVersion, err := r.Mysql.GetMySQLDBVersion()
+	if err != nil {
+		return err
+	}
+	sequences, err := r.Oracle.GetOracleSchemaSequence(sourceSchema)
+	if err != nil {
+		return err
+	}
+	sqls := GenCreateSequence(targetSchema, sequences)
+	if len(sqls) == 0 {
+		return nil
+	}
+	if common.VersionOrdinal(common.MariaDBVersion(mysqlVersion)) < common.VersionOrdinal(common.MariaDBSequenceVersion) {
+		zap.L().Warn("mariadb version less than 10.3.0 isn't support sequence, oracle sequence skip",
+			zap.String("schema", sourceSchema),
+			zap.String("mariadb version", mysqlVersion),
+			zap.Int("sequence totals", len(sqls)))
+		return nil
+	}
+	if !r.Cfg.ReverseConfig.DirectWrite {
+		if f.IsSplitByObject() {
+			_, err = f.SWriteFile(common.ReverseSplitObjectSequence, common.StringsBuilder(strings.Join(sqls, "\n"), "\n"))
+			return err
+		}
+		_, err = f.RWriteFile(common.StringsBuilder(strings.Join(sqls, "\n"), "\n\n"))
+		return err
+	}
+	for _, s := range sqls {
+		if err = f.RWriteDB(strings.TrimSuffix(s, ";")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
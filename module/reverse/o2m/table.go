@@ -40,19 +40,25 @@ type Table struct {
 	TargetDBVersion       string          `json:"target_db_version"`
 	TargetTableName       string          `json:"target_table_name"`
 	TargetTableOption     string          `json:"target_table_option"`
+	TargetTableTTL        string          `json:"target_table_ttl"`
 	OracleCollation       bool            `json:"oracle_collation"`
 	SourceSchemaCollation string          `json:"source_schema_collation"` // 可为空
 	SourceTableCollation  string          `json:"source_table_collation"`  // 可为空
 	SourceDBNLSSort       string          `json:"sourcedb_nlssort"`
 	SourceDBNLSComp       string          `json:"sourcedb_nlscomp"`
 	SourceTableType       string          `json:"source_table_type"`
-
-	TableColumnDatatypeRule   map[string]string `json:"table_column_datatype_rule"`
-	TableColumnDefaultValRule map[string]string `json:"table_column_default_val_rule"`
-	Overwrite                 bool              `json:"overwrite"`
-	Oracle                    *oracle.Oracle    `json:"-"`
-	MySQL                     *mysql.MySQL      `json:"-"`
-	MetaDB                    *meta.Meta        `json:"-"`
+	TemporaryTablePolicy  string          `json:"temporary_table_policy"`
+	IndexDowngrade        bool            `json:"index_downgrade"`
+
+	TableColumnDatatypeRule   map[string]string    `json:"table_column_datatype_rule"`
+	TableColumnDefaultValRule map[string]string    `json:"table_column_default_val_rule"`
+	Overwrite                 bool                 `json:"overwrite"`
+	Oracle                    *oracle.Oracle       `json:"-"`
+	MySQL                     *mysql.MySQL         `json:"-"`
+	MetaDB                    *meta.Meta           `json:"-"`
+	Identifier                *IdentifierMapper    `json:"-"`
+	Dictionary                *Dictionary          `json:"-"`
+	ColumnRewriter            *common.NameRewriter `json:"-"`
 }
 
 func GenReverseTableTask(r *Reverse, tableNameRule map[string]string, tableColumnRule, tableDefaultRule map[string]map[string]string, oracleDBVersion string, oracleCollation bool, exporters []string, nlsSort, nlsComp string) ([]*Table, error) {
@@ -134,9 +140,18 @@ func GenReverseTableTask(r *Reverse, tableNameRule map[string]string, tableColum
 
 	var dbVersion string
 
-	if strings.EqualFold(r.Cfg.MySQLConfig.DBType, common.DatabaseTypeTiDB) {
+	switch {
+	case strings.EqualFold(r.Cfg.MySQLConfig.DBType, common.DatabaseTypeTiDB):
 		dbVersion = mysqlVersion
-	} else {
+	case strings.EqualFold(r.Cfg.MySQLConfig.DBType, common.DatabaseTypeMariaDB):
+		if !common.IsMariaDBVersion(mysqlVersion) {
+			return nil, fmt.Errorf("target db type is mariadb, but currently target db version [%v] isn't mariadb, please adjust target db type", mysqlVersion)
+		}
+		dbVersion = common.MariaDBVersion(mysqlVersion)
+	default:
+		if common.IsMariaDBVersion(mysqlVersion) {
+			return nil, fmt.Errorf("target db version [%v] is mariadb, please config [mysql] db-type = \"mariadb\" or --target mariadb", mysqlVersion)
+		}
 		if strings.Contains(mysqlVersion, common.MySQLVersionDelimiter) {
 			dbVersion = strings.Split(mysqlVersion, common.MySQLVersionDelimiter)[0]
 		} else {
@@ -144,6 +159,31 @@ func GenReverseTableTask(r *Reverse, tableNameRule map[string]string, tableColum
 		}
 	}
 
+	// 表 TTL 规则
+	tableTTLRule := make(map[string]string)
+	for _, tc := range r.Cfg.ReverseConfig.TTLConfig {
+		if tc.SourceTable == "" || tc.TTLColumn == "" || tc.TTLInterval == "" {
+			return nil, fmt.Errorf("reverse config [ttl-config] source-table [%s] ttl-column [%s] ttl-interval [%s] can't be null", tc.SourceTable, tc.TTLColumn, tc.TTLInterval)
+		}
+		tableTTLRule[common.StringUPPER(tc.SourceTable)] = fmt.Sprintf("TTL = `%s` + INTERVAL %s", common.StringUPPER(tc.TTLColumn), common.StringUPPER(tc.TTLInterval))
+	}
+
+	// 表数量较多或开启数据字典缓存时 schema 级批量查询数据字典，避免逐表查询
+	var dict *Dictionary
+	if len(exporters) >= common.ReverseDictionaryBatchThreshold || r.Cfg.OracleConfig.DictionaryCache != "" {
+		dict, err = NewDictionary(r.Oracle, common.StringUPPER(r.Cfg.OracleConfig.SchemaName), oracleCollation)
+		if err != nil {
+			return tables, err
+		}
+	}
+
+	// 正则改写合并的表只生成一次目标表结构
+	exporters = common.DistinctRewriteTargets(exporters, tableNameRule)
+	columnRewriter, err := r.Cfg.RewriteConfig.ColumnRewriter()
+	if err != nil {
+		return tables, err
+	}
+
 	startTime = time.Now()
 	g1 := &errgroup.Group{}
 	tableChan := make(chan *Table, common.ChannelBufferSize)
@@ -171,7 +211,10 @@ func GenReverseTableTask(r *Reverse, tableNameRule map[string]string, tableColum
 					TargetDBVersion:           dbVersion,
 					TargetTableName:           targetTableName,
 					TargetTableOption:         common.StringUPPER(r.Cfg.MySQLConfig.TableOption),
+					TargetTableTTL:            tableTTLRule[common.StringUPPER(t)],
 					SourceTableType:           tablesMap[t],
+					TemporaryTablePolicy:      r.Cfg.ReverseConfig.TemporaryTablePolicy,
+					IndexDowngrade:            r.Cfg.ReverseConfig.IndexDowngrade,
 					SourceDBNLSSort:           nlsSort,
 					SourceDBNLSComp:           nlsComp,
 					TableColumnDatatypeRule:   tableColumnRule[common.StringUPPER(t)],
@@ -180,6 +223,9 @@ func GenReverseTableTask(r *Reverse, tableNameRule map[string]string, tableColum
 					Oracle:                    r.Oracle,
 					MySQL:                     r.Mysql,
 					MetaDB:                    r.MetaDB,
+					Identifier:                r.Identifier,
+					Dictionary:                dict,
+					ColumnRewriter:            columnRewriter,
 				}
 				tbl.OracleCollation = oracleCollation
 				if oracleCollation {
@@ -220,41 +266,75 @@ func GenReverseTableTask(r *Reverse, tableNameRule map[string]string, tableColum
 }
 
 func (t *Table) GetTablePrimaryKey() ([]map[string]string, error) {
+	if t.Dictionary != nil {
+		return t.Dictionary.PrimaryKey[t.SourceTableName], nil
+	}
 	return t.Oracle.GetOracleSchemaTablePrimaryKey(t.SourceSchemaName, t.SourceTableName)
 }
 
 func (t *Table) GetTableUniqueKey() ([]map[string]string, error) {
+	if t.Dictionary != nil {
+		return t.Dictionary.UniqueKey[t.SourceTableName], nil
+	}
 	return t.Oracle.GetOracleSchemaTableUniqueKey(t.SourceSchemaName, t.SourceTableName)
 }
 
 func (t *Table) GetTableForeignKey() ([]map[string]string, error) {
+	if t.Dictionary != nil {
+		return t.Dictionary.ForeignKey[t.SourceTableName], nil
+	}
 	return t.Oracle.GetOracleSchemaTableForeignKey(t.SourceSchemaName, t.SourceTableName)
 }
 
 func (t *Table) GetTableCheckKey() ([]map[string]string, error) {
+	if t.Dictionary != nil {
+		return t.Dictionary.CheckKey[t.SourceTableName], nil
+	}
 	return t.Oracle.GetOracleSchemaTableCheckKey(t.SourceSchemaName, t.SourceTableName)
 }
 
 func (t *Table) GetTableUniqueIndex() ([]map[string]string, error) {
+	if t.Dictionary != nil {
+		return t.Dictionary.UniqueIndex[t.SourceTableName], nil
+	}
 	// 唯一索引
 	return t.Oracle.GetOracleSchemaTableUniqueIndex(t.SourceSchemaName, t.SourceTableName)
 }
 
 func (t *Table) GetTableNormalIndex() ([]map[string]string, error) {
+	if t.Dictionary != nil {
+		return t.Dictionary.NormalIndex[t.SourceTableName], nil
+	}
 	// 普通索引【普通索引、函数索引、位图索引、DOMAIN 索引】
 	return t.Oracle.GetOracleSchemaTableNormalIndex(t.SourceSchemaName, t.SourceTableName)
 }
 
 func (t *Table) GetTableComment() ([]map[string]string, error) {
+	if t.Dictionary != nil {
+		// 字典加载后新建的表回退逐表查询
+		if res, ok := t.Dictionary.TableComment[t.SourceTableName]; ok {
+			return res, nil
+		}
+	}
 	return t.Oracle.GetOracleSchemaTableComment(t.SourceSchemaName, t.SourceTableName)
 }
 
 func (t *Table) GetTableColumnMeta() ([]map[string]string, error) {
+	if t.Dictionary != nil {
+		if res, ok := t.Dictionary.TableColumn[t.SourceTableName]; ok {
+			return res, nil
+		}
+	}
 	// 获取表数据字段列信息
 	return t.Oracle.GetOracleSchemaTableColumn(t.SourceSchemaName, t.SourceTableName, t.OracleCollation)
 }
 
 func (t *Table) GetTableColumnComment() ([]map[string]string, error) {
+	if t.Dictionary != nil {
+		if res, ok := t.Dictionary.ColumnComment[t.SourceTableName]; ok {
+			return res, nil
+		}
+	}
 	// 获取表数据字段列备注
 	return t.Oracle.GetOracleSchemaTableColumnComment(t.SourceSchemaName, t.SourceTableName)
 }
@@ -351,16 +431,28 @@ func GenCreateSchema(w *reverse.Write, sourceSchema, targetSchema, nlsComp strin
 	sqlRev.WriteString(t.Render() + "\n")
 	sqlRev.WriteString("*/\n")
 
+	// MariaDB 按版本改写排序规则
+	var targetDBVersion string
+	if strings.EqualFold(w.Cfg.MySQLConfig.DBType, common.DatabaseTypeMariaDB) {
+		mysqlVersion, err := w.MySQL.GetMySQLDBVersion()
+		if err != nil {
+			return err
+		}
+		targetDBVersion = common.MariaDBVersion(mysqlVersion)
+	}
+
 	if oraCollation {
-		if _, ok := common.OracleCollationMap[common.StringUPPER(schemaCollation)]; !ok {
+		collation, ok := common.OracleCollationToMySQL(w.Cfg.MySQLConfig.DBType, targetDBVersion, schemaCollation)
+		if !ok {
 			return fmt.Errorf("oracle schema collation [%s] isn't support", schemaCollation)
 		}
-		sqlRev.WriteString(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s DEFAULT CHARACTER SET %s COLLATE %s;\n\n", common.StringUPPER(targetSchema), strings.ToLower(common.MySQLCharacterSet), common.OracleCollationMap[common.StringUPPER(schemaCollation)]))
+		sqlRev.WriteString(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s DEFAULT CHARACTER SET %s COLLATE %s;\n\n", common.StringUPPER(targetSchema), strings.ToLower(common.MySQLCharacterSet), collation))
 	} else {
-		if _, ok := common.OracleCollationMap[common.StringUPPER(nlsComp)]; !ok {
+		collation, ok := common.OracleCollationToMySQL(w.Cfg.MySQLConfig.DBType, targetDBVersion, nlsComp)
+		if !ok {
 			return fmt.Errorf("oracle db nls_comp collation [%s] isn't support", nlsComp)
 		}
-		sqlRev.WriteString(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s DEFAULT CHARACTER SET %s COLLATE %s;\n\n", common.StringUPPER(targetSchema), strings.ToLower(common.MySQLCharacterSet), common.OracleCollationMap[common.StringUPPER(nlsComp)]))
+		sqlRev.WriteString(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s DEFAULT CHARACTER SET %s COLLATE %s;\n\n", common.StringUPPER(targetSchema), strings.ToLower(common.MySQLCharacterSet), collation))
 	}
 
 	if directWrite {
@@ -381,10 +473,10 @@ func GenCreateSchema(w *reverse.Write, sourceSchema, targetSchema, nlsComp strin
 	return nil
 }
 
-func GenCompatibilityTable(f *reverse.Write, sourceSchema string, partitionTables, temporaryTables, clusteredTables []string, materializedViews []string) error {
+func GenCompatibilityTable(f *reverse.Write, sourceSchema string, partitionTables, temporaryTables, clusteredTables []string, materializedViews []string, flashbackTables, temporalTables []string, temporaryPolicy string) error {
 	startTime := time.Now()
 	// 兼容提示
-	if len(partitionTables) > 0 || len(temporaryTables) > 0 || len(clusteredTables) > 0 || len(materializedViews) > 0 {
+	if len(partitionTables) > 0 || len(temporaryTables) > 0 || len(clusteredTables) > 0 || len(materializedViews) > 0 || len(flashbackTables) > 0 || len(temporalTables) > 0 {
 		var sqlComp strings.Builder
 
 		sqlComp.WriteString("/*\n")
@@ -401,9 +493,18 @@ func GenCompatibilityTable(f *reverse.Write, sourceSchema string, partitionTable
 			}
 		}
 		if len(temporaryTables) > 0 {
+			var suggest string
+			switch temporaryPolicy {
+			case common.ReverseTemporaryTablePolicySkip:
+				suggest = "Policy Skip, Skip Table"
+			case common.ReverseTemporaryTablePolicyTemporary:
+				suggest = "Policy Temporary, Temporary Table Script"
+			default:
+				suggest = "Policy Normal, Normal Table"
+			}
 			for _, temp := range temporaryTables {
 				t.AppendRows([]table.Row{
-					{sourceSchema, temp, "Temporary", "Manual Process Table"},
+					{sourceSchema, temp, "Temporary", suggest},
 				})
 			}
 		}
@@ -414,6 +515,20 @@ func GenCompatibilityTable(f *reverse.Write, sourceSchema string, partitionTable
 				})
 			}
 		}
+		if len(flashbackTables) > 0 {
+			for _, fba := range flashbackTables {
+				t.AppendRows([]table.Row{
+					{sourceSchema, fba, "Flashback Archive", "Normal Table, History Data Not Migrated"},
+				})
+			}
+		}
+		if len(temporalTables) > 0 {
+			for _, tv := range temporalTables {
+				t.AppendRows([]table.Row{
+					{sourceSchema, tv, "Temporal Validity", "Normal Table, Period Semantics Not Migrated"},
+				})
+			}
+		}
 		sqlComp.WriteString(t.Render() + "\n")
 		sqlComp.WriteString("*/\n")
 
@@ -450,3 +565,48 @@ func GenCompatibilityTable(f *reverse.Write, sourceSchema string, partitionTable
 
 	return nil
 }
+
+// GenCompatibilityEdition 输出 EBR 编辑视图、跨版本触发器以及其他版本编辑对象
+func GenCompatibilityEdition(f *reverse.Write, sourceSchema string, editioningViews map[string]string, crosseditionTriggers []map[string]string, editionedObjects map[string][]string) error {
+	if len(editioningViews) == 0 && len(crosseditionTriggers) == 0 && len(editionedObjects) == 0 {
+		return nil
+	}
+	startTime := time.Now()
+
+	var sqlComp strings.Builder
+	sqlComp.WriteString("/*\n")
+	sqlComp.WriteString(" oracle edition-based redefinition objects, will skip convert to reverse, please manual process\n")
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"SCHEMA", "OBJECT NAME", "ORACLE OBJECT TYPE", "SUGGEST"})
+
+	for view, baseTable := range editioningViews {
+		t.AppendRows([]table.Row{
+			{sourceSchema, view, "Editioning View", fmt.Sprintf("Resolved To Base Table [%s]", baseTable)},
+		})
+	}
+	for _, trigger := range crosseditionTriggers {
+		t.AppendRows([]table.Row{
+			{sourceSchema, trigger["TRIGGER_NAME"], fmt.Sprintf("Crossedition Trigger [%s]", trigger["CROSSEDITION"]),
+				fmt.Sprintf("Skip Trigger On Table [%s]", trigger["TABLE_NAME"])},
+		})
+	}
+	for objectType, objects := range editionedObjects {
+		for _, obj := range objects {
+			t.AppendRows([]table.Row{
+				{sourceSchema, obj, fmt.Sprintf("Editioned %s", objectType), "Not Current Edition, Skip Object"},
+			})
+		}
+	}
+	sqlComp.WriteString(t.Render() + "\n")
+	sqlComp.WriteString("*/\n")
+
+	if _, err := f.CWriteFile(sqlComp.String()); err != nil {
+		return err
+	}
+
+	zap.L().Info("output oracle to mysql edition objects tips",
+		zap.String("schema", sourceSchema),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
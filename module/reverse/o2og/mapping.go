@@ -0,0 +1,123 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2og
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	oracleTimestampRegex = regexp.MustCompile(`^TIMESTAMP\((\d+)\)(.*)$`)
+)
+
+// OracleTableColumnMapOpenGaussRule 字段类型映射
+// 1、NUMBER 整数按精度映射 SMALLINT/INTEGER/BIGINT，其余 NUMERIC
+// 2、VARCHAR2/CHAR 按字节长度 DATA_LENGTH 映射（openGauss VARCHAR(n) 为字节长度），NVARCHAR2/NCHAR 按字符长度
+// 3、DATE 含时分秒映射 TIMESTAMP(0)，时间精度上限 6
+func OracleTableColumnMapOpenGaussRule(columnName string, column map[string]string) (string, error) {
+	dataType := common.StringUPPER(column["DATA_TYPE"])
+	dataLength := column["DATA_LENGTH"]
+	charLength := column["CHAR_LENGTH"]
+	dataPrecision, err := strconv.Atoi(column["DATA_PRECISION"])
+	if err != nil {
+		return "", fmt.Errorf("column [%s] data_precision [%s] parse failed: %v", columnName, column["DATA_PRECISION"], err)
+	}
+	dataScale, err := strconv.Atoi(column["DATA_SCALE"])
+	if err != nil {
+		return "", fmt.Errorf("column [%s] data_scale [%s] parse failed: %v", columnName, column["DATA_SCALE"], err)
+	}
+
+	switch {
+	case dataType == "NUMBER":
+		switch {
+		case dataPrecision == 38 && dataScale == 127:
+			return "NUMERIC", nil
+		case dataScale == 0 && dataPrecision <= 4:
+			return "SMALLINT", nil
+		case dataScale == 0 && dataPrecision <= 9:
+			return "INTEGER", nil
+		case dataScale == 0 && dataPrecision <= 18:
+			return "BIGINT", nil
+		case dataScale == 0:
+			return fmt.Sprintf("NUMERIC(%d)", dataPrecision), nil
+		case dataScale < 0:
+			// 负数 scale 小数点左侧舍入，保留整数位
+			return fmt.Sprintf("NUMERIC(%d)", dataPrecision-dataScale), nil
+		default:
+			return fmt.Sprintf("NUMERIC(%d,%d)", dataPrecision, dataScale), nil
+		}
+	case dataType == "FLOAT" || dataType == "BINARY_DOUBLE":
+		return "DOUBLE PRECISION", nil
+	case dataType == "BINARY_FLOAT":
+		return "REAL", nil
+	case dataType == "CHAR":
+		return fmt.Sprintf("CHAR(%s)", dataLength), nil
+	case dataType == "VARCHAR2":
+		return fmt.Sprintf("VARCHAR(%s)", dataLength), nil
+	case dataType == "NCHAR":
+		return fmt.Sprintf("NCHAR(%s)", charLength), nil
+	case dataType == "NVARCHAR2":
+		return fmt.Sprintf("NVARCHAR2(%s)", charLength), nil
+	case dataType == "DATE":
+		return "TIMESTAMP(0)", nil
+	case strings.HasPrefix(dataType, "TIMESTAMP"):
+		scale := 6
+		suffix := ""
+		if m := oracleTimestampRegex.FindStringSubmatch(dataType); m != nil {
+			scale, err = strconv.Atoi(m[1])
+			if err != nil {
+				return "", fmt.Errorf("column [%s] datatype [%s] scale parse failed: %v", columnName, dataType, err)
+			}
+			if scale > 6 {
+				scale = 6
+			}
+			suffix = m[2]
+		}
+		// WITH LOCAL TIME ZONE 存储为会话时区归一化值，统一映射 WITH TIME ZONE
+		if strings.Contains(suffix, "TIME ZONE") {
+			return fmt.Sprintf("TIMESTAMP(%d) WITH TIME ZONE", scale), nil
+		}
+		return fmt.Sprintf("TIMESTAMP(%d)", scale), nil
+	case strings.HasPrefix(dataType, "INTERVAL YEAR"):
+		return "INTERVAL YEAR TO MONTH", nil
+	case strings.HasPrefix(dataType, "INTERVAL DAY"):
+		return "INTERVAL DAY TO SECOND", nil
+	case dataType == "CLOB" || dataType == "NCLOB" || dataType == "LONG" || dataType == "XMLTYPE":
+		return "TEXT", nil
+	case dataType == "BLOB" || dataType == "LONG RAW" || dataType == "RAW":
+		return "BYTEA", nil
+	case dataType == "ROWID":
+		return "VARCHAR(18)", nil
+	case dataType == "UROWID":
+		return fmt.Sprintf("VARCHAR(%s)", dataLength), nil
+	default:
+		return "", fmt.Errorf("column [%s] datatype [%s] isn't support opengauss", columnName, dataType)
+	}
+}
+
+// IsOracleBinaryDatatype 二进制字段，COPY 写入按 bytea 编码，其余字符类按 string 写入
+func IsOracleBinaryDatatype(dataType string) bool {
+	switch common.StringUPPER(dataType) {
+	case "BLOB", "RAW", "LONG RAW":
+		return true
+	default:
+		return false
+	}
+}
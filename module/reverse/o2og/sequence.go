@@ -0,0 +1,60 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2og
+
+import (
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"strconv"
+	"strings"
+)
+
+// GenCreateSequence oracle 序列转换，起始值取 LAST_NUMBER 保证目标端不分配已使用值
+// 标识列系统序列（ISEQ$$_）随表标识列生成，此处跳过
+func GenCreateSequence(targetSchema string, sequences []map[string]string) []string {
+	var sqls []string
+	for _, s := range sequences {
+		if strings.HasPrefix(s["SEQUENCE_NAME"], "ISEQ$$_") {
+			continue
+		}
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf(`CREATE SEQUENCE "%s"."%s" INCREMENT BY %s`, targetSchema, s["SEQUENCE_NAME"], s["INCREMENT_BY"]))
+		// 超出 BIGINT 范围（oracle 默认 MAXVALUE 28 位）按不限制处理
+		if _, err := strconv.ParseInt(s["MIN_VALUE"], 10, 64); err == nil {
+			sb.WriteString(common.StringsBuilder(" MINVALUE ", s["MIN_VALUE"]))
+		} else {
+			sb.WriteString(" NO MINVALUE")
+		}
+		if _, err := strconv.ParseInt(s["MAX_VALUE"], 10, 64); err == nil {
+			sb.WriteString(common.StringsBuilder(" MAXVALUE ", s["MAX_VALUE"]))
+		} else {
+			sb.WriteString(" NO MAXVALUE")
+		}
+		sb.WriteString(common.StringsBuilder(" START WITH ", s["LAST_NUMBER"]))
+		// NOCACHE 对应 CACHE 1
+		cache := s["CACHE_SIZE"]
+		if cache == "0" || cache == "" {
+			cache = "1"
+		}
+		sb.WriteString(common.StringsBuilder(" CACHE ", cache))
+		if strings.EqualFold(s["CYCLE_FLAG"], "Y") {
+			sb.WriteString(" CYCLE")
+		}
+		sb.WriteString(";")
+		sqls = append(sqls, sb.String())
+	}
+	return sqls
+}
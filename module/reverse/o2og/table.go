@@ -0,0 +1,247 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2og
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/database/oracle"
+	"github.com/wentaojin/transferdb/module/reverse"
+	"regexp"
+	"strings"
+)
+
+// oracle 常用默认值函数转换
+var oracleDefaultValueMap = map[string]string{
+	"SYSDATE":      "CURRENT_TIMESTAMP(0)",
+	"SYSTIMESTAMP": "CURRENT_TIMESTAMP",
+	"USER":         "CURRENT_USER",
+}
+
+var identitySequenceNameRegex = regexp.MustCompile(`[^A-Z0-9_$#]`)
+
+type Table struct {
+	SourceSchemaName string `json:"source_schema_name"`
+	SourceTableName  string `json:"source_table_name"`
+	TargetSchemaName string `json:"target_schema_name"`
+	TargetTableName  string `json:"target_table_name"`
+	Overwrite        bool   `json:"overwrite"`
+	OracleCollation  bool   `json:"oracle_collation"`
+	// oracle 12c 及以上存在标识列
+	OracleIdentity bool `json:"oracle_identity"`
+
+	ColumnRewriter *common.NameRewriter `json:"-"`
+	Oracle         *oracle.Oracle       `json:"-"`
+}
+
+type DDL struct {
+	SourceSchemaName string
+	SourceTableName  string
+	TargetSchemaName string
+	TargetTableName  string
+	// 标识列序列，需先于建表创建
+	SequenceSQL    []string
+	TableCreateSQL string
+	IndexCreateSQL []string
+	CommentSQL     []string
+	// 建表后序列归属设置
+	PostSQL []string
+	// openGauss 不兼容对象，输出至 compatibility 文件人工处理
+	Incompatibility []string
+}
+
+func (t *Table) columnName(sourceColumn string) string {
+	if targetColumn, _, ok := t.ColumnRewriter.Rewrite(sourceColumn); ok {
+		return targetColumn
+	}
+	return sourceColumn
+}
+
+func (t *Table) quoteColumnList(columnList string) string {
+	var cols []string
+	for _, c := range strings.Split(columnList, ",") {
+		cols = append(cols, common.StringsBuilder(`"`, t.columnName(c), `"`))
+	}
+	return strings.Join(cols, ",")
+}
+
+func (t *Table) fullTableName() string {
+	return common.StringsBuilder(`"`, t.TargetSchemaName, `"."`, t.TargetTableName, `"`)
+}
+
+func (t *Table) GenCreateTableDDL() (*DDL, error) {
+	ddl := &DDL{
+		SourceSchemaName: t.SourceSchemaName,
+		SourceTableName:  t.SourceTableName,
+		TargetSchemaName: t.TargetSchemaName,
+		TargetTableName:  t.TargetTableName,
+	}
+
+	columns, err := t.Oracle.GetOracleSchemaTableColumn(t.SourceSchemaName, t.SourceTableName, t.OracleCollation)
+	if err != nil {
+		return nil, err
+	}
+
+	// 标识列转换为序列 + DEFAULT nextval，GENERATED ALWAYS 语义（禁止显式写入）不保留
+	identityCols := make(map[string]map[string]string)
+	if t.OracleIdentity {
+		identities, err := t.Oracle.GetOracleSchemaTableIdentityColumn(t.SourceSchemaName, t.SourceTableName)
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range identities {
+			identityCols[i["COLUMN_NAME"]] = i
+		}
+	}
+
+	var (
+		tableColumns []string
+		targetCols   []string
+	)
+	for _, c := range columns {
+		datatype, err := OracleTableColumnMapOpenGaussRule(c["COLUMN_NAME"], c)
+		if err != nil {
+			return nil, fmt.Errorf("oracle table [%s.%s] %v", t.SourceSchemaName, t.SourceTableName, err)
+		}
+		targetColumn := t.columnName(c["COLUMN_NAME"])
+		if common.IsContainString(targetCols, targetColumn) {
+			return nil, fmt.Errorf("oracle table [%s.%s] column [%s] rewrite target column [%s] conflict", t.SourceSchemaName, t.SourceTableName, c["COLUMN_NAME"], targetColumn)
+		}
+		targetCols = append(targetCols, targetColumn)
+
+		var sb strings.Builder
+		sb.WriteString(common.StringsBuilder(`"`, targetColumn, `" `, datatype))
+		if identity, ok := identityCols[c["COLUMN_NAME"]]; ok {
+			seqName := identitySequenceNameRegex.ReplaceAllString(common.StringsBuilder(t.TargetTableName, "_", targetColumn, "_SEQ"), "_")
+			ddl.SequenceSQL = append(ddl.SequenceSQL, fmt.Sprintf(`CREATE SEQUENCE "%s"."%s" INCREMENT BY %s START WITH %s;`,
+				t.TargetSchemaName, seqName, identity["INCREMENT_BY"], identity["LAST_NUMBER"]))
+			ddl.PostSQL = append(ddl.PostSQL, fmt.Sprintf(`ALTER SEQUENCE "%s"."%s" OWNED BY %s."%s";`,
+				t.TargetSchemaName, seqName, t.fullTableName(), targetColumn))
+			sb.WriteString(fmt.Sprintf(` DEFAULT nextval('"%s"."%s"')`, t.TargetSchemaName, seqName))
+		} else if defaultVal := strings.TrimSpace(c["DATA_DEFAULT"]); defaultVal != "NULLABLE" && defaultVal != "" {
+			if val, ok := oracleDefaultValueMap[common.StringUPPER(defaultVal)]; ok {
+				defaultVal = val
+			}
+			sb.WriteString(common.StringsBuilder(" DEFAULT ", defaultVal))
+		}
+		if strings.EqualFold(c["NULLABLE"], "N") {
+			sb.WriteString(" NOT NULL")
+		}
+		tableColumns = append(tableColumns, sb.String())
+
+		if c["COMMENTS"] != "NULLABLE" && c["COMMENTS"] != "" {
+			ddl.CommentSQL = append(ddl.CommentSQL, fmt.Sprintf(`COMMENT ON COLUMN %s."%s" IS '%s';`,
+				t.fullTableName(), targetColumn, common.SpecialLettersUsingOracle([]byte(c["COMMENTS"]))))
+		}
+	}
+
+	primaryKeys, err := t.Oracle.GetOracleSchemaTablePrimaryKey(t.SourceSchemaName, t.SourceTableName)
+	if err != nil {
+		return nil, err
+	}
+	for _, pk := range primaryKeys {
+		tableColumns = append(tableColumns, fmt.Sprintf(`CONSTRAINT "%s" PRIMARY KEY (%s)`, pk["CONSTRAINT_NAME"], t.quoteColumnList(pk["COLUMN_LIST"])))
+	}
+
+	uniqueKeys, err := t.Oracle.GetOracleSchemaTableUniqueKey(t.SourceSchemaName, t.SourceTableName)
+	if err != nil {
+		return nil, err
+	}
+	for _, uk := range uniqueKeys {
+		tableColumns = append(tableColumns, fmt.Sprintf(`CONSTRAINT "%s" UNIQUE (%s)`, uk["CONSTRAINT_NAME"], t.quoteColumnList(uk["COLUMN_LIST"])))
+	}
+
+	ddl.TableCreateSQL = fmt.Sprintf("CREATE TABLE %s (\n    %s\n);", t.fullTableName(), strings.Join(tableColumns, ",\n    "))
+
+	uniqueIndexes, err := t.Oracle.GetOracleSchemaTableUniqueIndex(t.SourceSchemaName, t.SourceTableName)
+	if err != nil {
+		return nil, err
+	}
+	normalIndexes, err := t.Oracle.GetOracleSchemaTableNormalIndex(t.SourceSchemaName, t.SourceTableName)
+	if err != nil {
+		return nil, err
+	}
+	// openGauss 索引与表同 schema，索引名不可带 schema 前缀
+	for _, idx := range append(uniqueIndexes, normalIndexes...) {
+		unique := ""
+		if strings.EqualFold(idx["UNIQUENESS"], "UNIQUE") {
+			unique = "UNIQUE "
+		}
+		switch common.StringUPPER(idx["INDEX_TYPE"]) {
+		case "NORMAL", "BITMAP":
+			// BITMAP 索引无对应类型，转换为 BTREE 普通索引
+			ddl.IndexCreateSQL = append(ddl.IndexCreateSQL, fmt.Sprintf(`CREATE %sINDEX "%s" ON %s (%s);`,
+				unique, idx["INDEX_NAME"], t.fullTableName(), t.quoteColumnList(idx["COLUMN_LIST"])))
+		case "FUNCTION-BASED NORMAL":
+			ddl.IndexCreateSQL = append(ddl.IndexCreateSQL, fmt.Sprintf(`CREATE %sINDEX "%s" ON %s (%s);`,
+				unique, idx["INDEX_NAME"], t.fullTableName(), idx["COLUMN_LIST"]))
+		default:
+			ddl.Incompatibility = append(ddl.Incompatibility, fmt.Sprintf(`-- oracle table [%s.%s] index [%s] type [%s] columns [%s] isn't support opengauss`,
+				t.SourceSchemaName, t.SourceTableName, idx["INDEX_NAME"], idx["INDEX_TYPE"], idx["COLUMN_LIST"]))
+		}
+	}
+
+	tableComments, err := t.Oracle.GetOracleSchemaTableComment(t.SourceSchemaName, t.SourceTableName)
+	if err != nil {
+		return nil, err
+	}
+	if len(tableComments) > 0 && tableComments[0]["COMMENTS"] != "NULLABLE" && tableComments[0]["COMMENTS"] != "" {
+		ddl.CommentSQL = append([]string{fmt.Sprintf(`COMMENT ON TABLE %s IS '%s';`,
+			t.fullTableName(), common.SpecialLettersUsingOracle([]byte(tableComments[0]["COMMENTS"])))}, ddl.CommentSQL...)
+	}
+	return ddl, nil
+}
+
+func (t *Table) String() string {
+	jsonStr, _ := json.Marshal(t)
+	return string(jsonStr)
+}
+
+func (d *DDL) Write(w *reverse.Write, overwrite bool) error {
+	var sqls []string
+	if overwrite {
+		sqls = append(sqls, common.StringsBuilder(`DROP TABLE IF EXISTS "`, d.TargetSchemaName, `"."`, d.TargetTableName, `" CASCADE;`))
+	}
+	sqls = append(sqls, d.SequenceSQL...)
+	sqls = append(sqls, d.TableCreateSQL)
+	sqls = append(sqls, d.PostSQL...)
+	sqls = append(sqls, d.IndexCreateSQL...)
+	sqls = append(sqls, d.CommentSQL...)
+
+	if w.Cfg.ReverseConfig.DirectWrite {
+		for _, s := range sqls {
+			if err := w.RWriteDB(s); err != nil {
+				return err
+			}
+		}
+	} else {
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("-- oracle table [%s.%s] reverse opengauss table [%s.%s]\n", d.SourceSchemaName, d.SourceTableName, d.TargetSchemaName, d.TargetTableName))
+		sb.WriteString(strings.Join(sqls, "\n"))
+		sb.WriteString("\n\n")
+		if _, err := w.RWriteFile(sb.String()); err != nil {
+			return err
+		}
+	}
+
+	if len(d.Incompatibility) > 0 {
+		if _, err := w.CWriteFile(strings.Join(d.Incompatibility, "\n") + "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
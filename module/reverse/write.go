@@ -20,7 +20,9 @@ import (
 	"fmt"
 	"github.com/wentaojin/transferdb/common"
 	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/dm"
 	"github.com/wentaojin/transferdb/database/mysql"
+	"github.com/wentaojin/transferdb/database/opengauss"
 	"github.com/wentaojin/transferdb/database/oracle"
 	"os"
 	"path/filepath"
@@ -36,30 +38,52 @@ type Write struct {
 	CWriter *bufio.Writer
 	Mutex   *sync.Mutex
 
+	// 按对象类型拆分输出，split-by-object 开启且非 direct-write 时设置
+	SFiles   map[string]*os.File
+	SWriters map[string]*bufio.Writer
+	// 按表拆分输出目录，split-per-table 开启时表级对象（表、索引、约束、注释）每表单独文件
+	SDirs map[string]string
+
 	MySQL  *mysql.MySQL
 	Oracle *oracle.Oracle
+	// DM 达梦目标端，仅 oracle -> dm 时设置
+	DM *dm.DM
+	// OpenGauss 目标端，仅 oracle -> opengauss 时设置
+	OpenGauss *opengauss.OpenGauss
 }
 
 func NewWriter(cfg *config.Config, mysql *mysql.MySQL, oracle *oracle.Oracle) (*Write, error) {
 	w := &Write{}
 
+	// 输出文件以源端 schema 命名，DB2 源端取 db2 配置
+	schemaName := cfg.OracleConfig.SchemaName
+	if strings.EqualFold(cfg.DBTypeS, common.DatabaseTypeDB2) {
+		schemaName = cfg.DB2Config.SchemaName
+	}
+
 	if !cfg.ReverseConfig.DirectWrite {
 		err := common.PathExist(cfg.ReverseConfig.DDLReverseDir)
 		if err != nil {
 			return nil, err
 		}
-		reverseFile := filepath.Join(cfg.ReverseConfig.DDLReverseDir, fmt.Sprintf("reverse_%s.sql", cfg.OracleConfig.SchemaName))
+		reverseFile := filepath.Join(cfg.ReverseConfig.DDLReverseDir, fmt.Sprintf("reverse_%s.sql", schemaName))
 		err = w.initOutReverseFile(reverseFile)
 		if err != nil {
 			return nil, err
 		}
+		if cfg.ReverseConfig.SplitByObject {
+			err = w.initOutSplitFile(cfg.ReverseConfig.DDLReverseDir, schemaName, cfg.ReverseConfig.SplitPerTable)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	err := common.PathExist(cfg.ReverseConfig.DDLCompatibleDir)
 	if err != nil {
 		return nil, err
 	}
-	compFile := filepath.Join(cfg.ReverseConfig.DDLCompatibleDir, fmt.Sprintf("compatibility_%s.sql", cfg.OracleConfig.SchemaName))
+	compFile := filepath.Join(cfg.ReverseConfig.DDLCompatibleDir, fmt.Sprintf("compatibility_%s.sql", schemaName))
 
 	err = w.initOutCompatibleFile(compFile)
 	if err != nil {
@@ -81,7 +105,12 @@ func (w *Write) RWriteFile(s string) (nn int, err error) {
 func (w *Write) RWriteDB(s string) error {
 	switch {
 	case strings.EqualFold(w.Cfg.DBTypeS, common.DatabaseTypeOracle) && strings.EqualFold(w.Cfg.DBTypeT, common.DatabaseTypeMySQL):
-		err := w.MySQL.WriteMySQLTable(s)
+		err := w.MySQL.WriteMySQLDDL(s)
+		if err != nil {
+			return err
+		}
+	case strings.EqualFold(w.Cfg.DBTypeS, common.DatabaseTypeDB2) && strings.EqualFold(w.Cfg.DBTypeT, common.DatabaseTypeMySQL):
+		err := w.MySQL.WriteMySQLDDL(s)
 		if err != nil {
 			return err
 		}
@@ -90,10 +119,46 @@ func (w *Write) RWriteDB(s string) error {
 		if err != nil {
 			return err
 		}
+	case strings.EqualFold(w.Cfg.DBTypeS, common.DatabaseTypeOracle) && strings.EqualFold(w.Cfg.DBTypeT, common.DatabaseTypeDM):
+		err := w.DM.WriteDMDDL(s)
+		if err != nil {
+			return err
+		}
+	case strings.EqualFold(w.Cfg.DBTypeS, common.DatabaseTypeOracle) && strings.EqualFold(w.Cfg.DBTypeT, common.DatabaseTypeOpenGauss):
+		err := w.OpenGauss.WriteOpenGaussDDL(s)
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// IsSplitByObject 是否按对象类型拆分输出
+func (w *Write) IsSplitByObject() bool {
+	return len(w.SWriters) > 0 || len(w.SDirs) > 0
+}
+
+func (w *Write) SWriteFile(objectType, s string) (nn int, err error) {
+	w.Mutex.Lock()
+	defer w.Mutex.Unlock()
+	sw, ok := w.SWriters[objectType]
+	if !ok {
+		return 0, fmt.Errorf("reverse split object type [%s] file isn't exist", objectType)
+	}
+	return sw.WriteString(s)
+}
+
+// STableWriteFile 表级对象写入，split-per-table 开启时写入对象类型目录下 ${table}.sql，否则写入对象类型拆分文件
+// 同一表同一对象类型仅由单个 reverse 任务写入，按表文件可并行执行
+func (w *Write) STableWriteFile(objectType, tableName, s string) error {
+	dir, ok := w.SDirs[objectType]
+	if !ok {
+		_, err := w.SWriteFile(objectType, s)
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, fmt.Sprintf("%s.sql", tableName)), []byte(s), 0666)
+}
+
 func (w *Write) CWriteFile(s string) (nn int, err error) {
 	w.Mutex.Lock()
 	defer w.Mutex.Unlock()
@@ -109,6 +174,51 @@ func (w *Write) initOutReverseFile(reverseFile string) error {
 	return nil
 }
 
+// initOutSplitFile 按对象类型创建拆分文件（按表拆分时表级对象创建目录），并输出执行顺序说明
+func (w *Write) initOutSplitFile(reverseDir, schemaName string, perTable bool) error {
+	w.SFiles = make(map[string]*os.File)
+	w.SWriters = make(map[string]*bufio.Writer)
+	w.SDirs = make(map[string]string)
+
+	var readme strings.Builder
+	readme.WriteString(fmt.Sprintf("# reverse schema %s execution order\n\n", schemaName))
+	readme.WriteString(fmt.Sprintf("1. reverse_%s.sql, create schema\n", schemaName))
+	step := 2
+	for _, objectType := range common.ReverseSplitObjects {
+		// 序列为 schema 级对象，不按表拆分
+		if perTable && objectType != common.ReverseSplitObjectSequence {
+			splitDir := filepath.Join(reverseDir, fmt.Sprintf("reverse_%s_%s", schemaName, objectType))
+			if err := os.RemoveAll(splitDir); err != nil {
+				return err
+			}
+			if err := os.MkdirAll(splitDir, 0755); err != nil {
+				return err
+			}
+			w.SDirs[objectType] = splitDir
+
+			readme.WriteString(fmt.Sprintf("%d. %s/*.sql, create %s, per table files can be executed in parallel\n", step, filepath.Base(splitDir), objectType))
+		} else {
+			splitFile := fmt.Sprintf("reverse_%s_%s.sql", schemaName, objectType)
+			outSplitFile, err := os.OpenFile(filepath.Join(reverseDir, splitFile), os.O_WRONLY|os.O_CREATE|os.O_APPEND|os.O_TRUNC, 0666)
+			if err != nil {
+				return err
+			}
+			w.SWriters[objectType], w.SFiles[objectType] = bufio.NewWriter(outSplitFile), outSplitFile
+
+			readme.WriteString(fmt.Sprintf("%d. %s, create %s\n", step, splitFile, objectType))
+		}
+		step++
+		// 表结构创建后先导入数据，再创建索引、约束
+		if objectType == common.ReverseSplitObjectTable {
+			readme.WriteString(fmt.Sprintf("%d. load data, full / csv mode\n", step))
+			step++
+		}
+	}
+	readme.WriteString(fmt.Sprintf("\nincompatible objects see compatibility_%s.sql, review and apply manually\n", schemaName))
+
+	return os.WriteFile(filepath.Join(reverseDir, fmt.Sprintf("README_%s.md", schemaName)), []byte(readme.String()), 0666)
+}
+
 func (w *Write) initOutCompatibleFile(compFile string) error {
 	outCompFile, err := os.OpenFile(compFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND|os.O_TRUNC, 0666)
 	if err != nil {
@@ -129,6 +239,16 @@ func (w *Write) Close() error {
 			return err
 		}
 	}
+	for objectType, sf := range w.SFiles {
+		err := w.SWriters[objectType].Flush()
+		if err != nil {
+			return err
+		}
+		err = sf.Close()
+		if err != nil {
+			return err
+		}
+	}
 	if w.CFile != nil {
 		err := w.CWriter.Flush()
 		if err != nil {
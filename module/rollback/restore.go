@@ -0,0 +1,155 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package rollback
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/database/mysql"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+const (
+	restoreStatusRestored = "RESTORED"
+	restoreStatusFailed   = "FAILED"
+)
+
+// Restore 按快照回退下游表
+type Restore struct {
+	ctx    context.Context
+	cfg    *config.Config
+	mysql  *mysql.MySQL
+	metaDB *meta.Meta
+}
+
+func NewRestore(ctx context.Context, cfg *config.Config) (*Restore, error) {
+	mysqlDB, err := mysql.NewMySQLDBEngine(ctx, cfg.MySQLConfig)
+	if err != nil {
+		return nil, err
+	}
+	metaDB, err := meta.NewMetaDBEngine(ctx, cfg.MySQLConfig, cfg.AppConfig.SlowlogThreshold)
+	if err != nil {
+		return nil, err
+	}
+	return &Restore{
+		ctx:    ctx,
+		cfg:    cfg,
+		mysql:  mysqlDB,
+		metaDB: metaDB,
+	}, nil
+}
+
+func (r *Restore) Rollback() error {
+	startTime := time.Now()
+	snapshotID := r.cfg.RollbackConfig.SnapshotID
+	if snapshotID == "" {
+		snapshotIDs, err := meta.NewRollbackSnapshotModel(r.metaDB).DistinctRollbackSnapshotID(r.ctx, &meta.RollbackSnapshot{
+			DBTypeT:     r.cfg.DBTypeT,
+			SchemaNameT: r.cfg.MySQLConfig.SchemaName,
+		})
+		if err != nil {
+			return err
+		}
+		if len(snapshotIDs) == 0 {
+			return fmt.Errorf("rollback target schema [%s] snapshot isn't exist, please see meta table [rollback_snapshot]", r.cfg.MySQLConfig.SchemaName)
+		}
+		snapshotID = snapshotIDs[0]
+	}
+
+	snapshots, err := meta.NewRollbackSnapshotModel(r.metaDB).DetailRollbackSnapshot(r.ctx, &meta.RollbackSnapshot{
+		DBTypeT:     r.cfg.DBTypeT,
+		SchemaNameT: common.StringUPPER(r.cfg.MySQLConfig.SchemaName),
+		SnapshotID:  snapshotID,
+	})
+	if err != nil {
+		return err
+	}
+	for _, s := range snapshots {
+		if s.TaskStatus != common.TaskStatusSuccess {
+			return fmt.Errorf("rollback snapshot [%s] table [%s] status [%s] isn't success, can't restore: %s", snapshotID, s.TableNameT, s.TaskStatus, s.ErrorDetail)
+		}
+	}
+
+	zap.L().Info("rollback target schema start",
+		zap.String("schema", r.cfg.MySQLConfig.SchemaName),
+		zap.String("snapshot", snapshotID),
+		zap.Int("tables", len(snapshots)))
+
+	var failedTables []string
+	for _, s := range snapshots {
+		tableTime := time.Now()
+		updates := map[string]interface{}{
+			"RestoreStatus": restoreStatusRestored,
+			"ErrorDetail":   "",
+		}
+		rows, err := r.restoreTable(s)
+		if err != nil {
+			// record error, skip error
+			failedTables = append(failedTables, s.TableNameT)
+			updates["RestoreStatus"] = restoreStatusFailed
+			updates["ErrorDetail"] = err.Error()
+		} else if rows != s.TableRows {
+			failedTables = append(failedTables, s.TableNameT)
+			updates["RestoreStatus"] = restoreStatusFailed
+			updates["ErrorDetail"] = fmt.Sprintf("restore rows [%d] isn't equal snapshot rows [%d]", rows, s.TableRows)
+		}
+		if err = meta.NewRollbackSnapshotModel(r.metaDB).UpdateRollbackSnapshot(r.ctx, &s, updates); err != nil {
+			return err
+		}
+		zap.L().Info("rollback target table finished",
+			zap.String("schema", s.SchemaNameT),
+			zap.String("table", s.TableNameT),
+			zap.Int64("rows", rows),
+			zap.Any("status", updates["RestoreStatus"]),
+			zap.String("cost", time.Now().Sub(tableTime).String()))
+	}
+
+	if len(failedTables) > 0 {
+		return fmt.Errorf("rollback target schema [%s] snapshot [%s] failed tables %v, please see meta table [rollback_snapshot]",
+			r.cfg.MySQLConfig.SchemaName, snapshotID, failedTables)
+	}
+	zap.L().Info("rollback target schema finished",
+		zap.String("schema", r.cfg.MySQLConfig.SchemaName),
+		zap.String("snapshot", snapshotID),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
+
+func (r *Restore) restoreTable(s meta.RollbackSnapshot) (int64, error) {
+	if err := r.mysql.TruncateMySQLTable(s.SchemaNameT, s.TableNameT); err != nil {
+		return 0, err
+	}
+	// 快照时无数据表仅清理
+	if s.TableRows == 0 {
+		return 0, nil
+	}
+	loadSQL := fmt.Sprintf("LOAD DATA LOCAL INFILE '%s' INTO TABLE `%s`.`%s`", loadEscape(s.FileName), s.SchemaNameT, s.TableNameT)
+	// 按快照导出字段列表导入，跳过生成列
+	if s.ColumnDetailT != "" {
+		loadSQL = common.StringsBuilder(loadSQL, " (", s.ColumnDetailT, ")")
+	}
+	return r.mysql.LoadMySQLTableByLocalFile(s.FileName, loadSQL)
+}
+
+// loadEscape LOAD DATA 文件路径字符串转义
+func loadEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\r", `\r`, "\n", `\n`, "\t", `\t`).Replace(s)
+}
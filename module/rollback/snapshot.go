@@ -0,0 +1,153 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package rollback
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/database/mysql"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Snapshot 下游表清理前数据快照，快照编号按任务启动时间生成
+type Snapshot struct {
+	ctx        context.Context
+	cfg        *config.Config
+	mysql      *mysql.MySQL
+	metaDB     *meta.Meta
+	snapshotID string
+}
+
+func NewSnapshot(ctx context.Context, cfg *config.Config, mysqlDB *mysql.MySQL, metaDB *meta.Meta) *Snapshot {
+	return &Snapshot{
+		ctx:        ctx,
+		cfg:        cfg,
+		mysql:      mysqlDB,
+		metaDB:     metaDB,
+		snapshotID: time.Now().Format("20060102150405"),
+	}
+}
+
+// SnapshotTable 导出下游表快照，表不存在跳过；无数据表同样记录快照，回退时清理写入数据
+func (s *Snapshot) SnapshotTable(tableName string) error {
+	if !s.cfg.RollbackConfig.EnableSnapshot {
+		return nil
+	}
+	startTime := time.Now()
+	schemaName := common.StringUPPER(s.cfg.MySQLConfig.SchemaName)
+	tableName = common.StringUPPER(tableName)
+
+	tables, err := s.mysql.GetMySQLTableName(schemaName, common.StringsBuilder(`'`, tableName, `'`))
+	if err != nil {
+		return err
+	}
+	if len(tables) == 0 {
+		return nil
+	}
+
+	snapshotDir := filepath.Join(s.cfg.RollbackConfig.OutputDir, s.snapshotID, schemaName)
+	if err = common.PathExist(snapshotDir); err != nil {
+		return err
+	}
+	fileName := filepath.Join(snapshotDir, common.StringsBuilder(tableName, ".tsv"))
+
+	// 生成列无法写入，快照导出以及回退导入均按非生成列字段列表
+	columns, err := s.mysql.GetMySQLTableColumnType(schemaName, tables[0])
+	if err != nil {
+		return err
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("rollback snapshot target table [%s.%s] columns isn't exist", schemaName, tableName)
+	}
+	var columnNames []string
+	for _, c := range columns {
+		columnNames = append(columnNames, common.StringsBuilder("`", c["COLUMN_NAME"], "`"))
+	}
+	columnDetail := strings.Join(columnNames, ",")
+
+	record := &meta.RollbackSnapshot{
+		DBTypeT:       s.cfg.DBTypeT,
+		SchemaNameT:   schemaName,
+		SnapshotID:    s.snapshotID,
+		TableNameT:    tableName,
+		TaskMode:      s.cfg.TaskMode,
+		FileName:      fileName,
+		ColumnDetailT: columnDetail,
+		TaskStatus:    common.TaskStatusSuccess,
+	}
+	rows, err := s.mysql.ExportMySQLTableToFile(schemaName, tables[0], columnDetail, fileName)
+	if err != nil {
+		record.TaskStatus = common.TaskStatusFailed
+		record.ErrorDetail = err.Error()
+		if errc := meta.NewRollbackSnapshotModel(s.metaDB).CreateRollbackSnapshot(s.ctx, record); errc != nil {
+			return errc
+		}
+		return fmt.Errorf("rollback snapshot target table [%s.%s] failed, target table isn't cleaned: %v", schemaName, tableName, err)
+	}
+	record.TableRows = rows
+	if err = meta.NewRollbackSnapshotModel(s.metaDB).CreateRollbackSnapshot(s.ctx, record); err != nil {
+		return err
+	}
+
+	zap.L().Info("rollback snapshot target table finished",
+		zap.String("snapshot", s.snapshotID),
+		zap.String("schema", schemaName),
+		zap.String("table", tableName),
+		zap.String("file", fileName),
+		zap.Int64("rows", rows),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
+
+// Prune 保留最近 keep-snapshots 份快照，清理更早快照文件以及元数据记录
+func (s *Snapshot) Prune() error {
+	if !s.cfg.RollbackConfig.EnableSnapshot || s.cfg.RollbackConfig.KeepSnapshots <= 0 {
+		return nil
+	}
+	snapshotIDs, err := meta.NewRollbackSnapshotModel(s.metaDB).DistinctRollbackSnapshotID(s.ctx, &meta.RollbackSnapshot{
+		DBTypeT:     s.cfg.DBTypeT,
+		SchemaNameT: s.cfg.MySQLConfig.SchemaName,
+	})
+	if err != nil {
+		return err
+	}
+	if len(snapshotIDs) <= s.cfg.RollbackConfig.KeepSnapshots {
+		return nil
+	}
+	for _, snapshotID := range snapshotIDs[s.cfg.RollbackConfig.KeepSnapshots:] {
+		if err = os.RemoveAll(filepath.Join(s.cfg.RollbackConfig.OutputDir, snapshotID, common.StringUPPER(s.cfg.MySQLConfig.SchemaName))); err != nil {
+			return fmt.Errorf("rollback snapshot [%s] remove files failed: %v", snapshotID, err)
+		}
+		if err = meta.NewRollbackSnapshotModel(s.metaDB).DeleteRollbackSnapshot(s.ctx, &meta.RollbackSnapshot{
+			DBTypeT:     s.cfg.DBTypeT,
+			SchemaNameT: s.cfg.MySQLConfig.SchemaName,
+			SnapshotID:  snapshotID,
+		}); err != nil {
+			return err
+		}
+		zap.L().Info("rollback snapshot pruned",
+			zap.String("schema", s.cfg.MySQLConfig.SchemaName),
+			zap.String("snapshot", snapshotID))
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package agent
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// 传输消息类型
+const (
+	shipMsgFile = "FILE"
+	shipMsgDone = "DONE"
+)
+
+// 传输应答状态
+const (
+	shipReplyOK    = "OK"
+	shipReplySkip  = "SKIP"
+	shipReplyError = "ERROR"
+)
+
+// shipMaxMessageSize 单条消息长度上限，避免异常长度前缀导致超大内存分配
+const shipMaxMessageSize = 1 << 20
+
+// shipHeader sender 每个文件连接首个消息，DONE 代表全部文件传输完成
+type shipHeader struct {
+	Type       string `json:"type"`
+	SchemaName string `json:"schema_name"`
+	TableName  string `json:"table_name"`
+	FileName   string `json:"file_name"`
+	FileSize   int64  `json:"file_size"`
+	Checksum   string `json:"checksum"`
+}
+
+// shipReply receiver 应答，Offset 代表已接收字节数，sender 从该位置续传
+type shipReply struct {
+	Status  string `json:"status"`
+	Offset  int64  `json:"offset"`
+	Message string `json:"message"`
+}
+
+// writeMessage 消息格式：4 字节长度 + json，消息之后紧跟文件数据流
+func writeMessage(w io.Writer, v interface{}) error {
+	jsonBytes, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("json marshal ship message failed: %v", err)
+	}
+	lenBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(lenBytes, uint32(len(jsonBytes)))
+	if _, err = w.Write(append(lenBytes, jsonBytes...)); err != nil {
+		return fmt.Errorf("write ship message failed: %v", err)
+	}
+	return nil
+}
+
+func readMessage(r io.Reader, v interface{}) error {
+	lenBytes := make([]byte, 4)
+	if _, err := io.ReadFull(r, lenBytes); err != nil {
+		return fmt.Errorf("read ship message length failed: %v", err)
+	}
+	msgSize := binary.BigEndian.Uint32(lenBytes)
+	if msgSize > shipMaxMessageSize {
+		return fmt.Errorf("read ship message length [%d] exceeds limit [%d]", msgSize, shipMaxMessageSize)
+	}
+	jsonBytes := make([]byte, msgSize)
+	if _, err := io.ReadFull(r, jsonBytes); err != nil {
+		return fmt.Errorf("read ship message body failed: %v", err)
+	}
+	if err := json.Unmarshal(jsonBytes, v); err != nil {
+		return fmt.Errorf("json unmarshal ship message failed: %v", err)
+	}
+	return nil
+}
+
+// newTLSConfig sender/receiver 双向证书认证
+func newTLSConfig(caPath, certPath, keyPath, serverName string, isServer bool) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("load tls cert [%s] key [%s] failed: %v", certPath, keyPath, err)
+	}
+	caBytes, err := os.ReadFile(caPath)
+	if err != nil {
+		return nil, fmt.Errorf("read tls ca [%s] failed: %v", caPath, err)
+	}
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(caBytes) {
+		return nil, fmt.Errorf("append tls ca [%s] failed", caPath)
+	}
+
+	if isServer {
+		return &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			ClientCAs:    certPool,
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+			MinVersion:   tls.VersionTLS12,
+		}, nil
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      certPool,
+		ServerName:   serverName,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+func fileChecksum(fileName string) (string, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("file [%s] checksum failed: %v", fileName, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
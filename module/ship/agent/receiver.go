@@ -0,0 +1,220 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package agent
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/module/load/f2m"
+	"go.uber.org/zap"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type Receiver struct {
+	ctx    context.Context
+	cfg    *config.Config
+	tlsCfg *tls.Config
+}
+
+func NewReceiver(ctx context.Context, cfg *config.Config) (*Receiver, error) {
+	tlsCfg, err := newTLSConfig(cfg.ShipConfig.CAPath, cfg.ShipConfig.CertPath, cfg.ShipConfig.KeyPath, "", true)
+	if err != nil {
+		return nil, err
+	}
+	return &Receiver{
+		ctx:    ctx,
+		cfg:    cfg,
+		tlsCfg: tlsCfg,
+	}, nil
+}
+
+// Ship receiver 接收文件写入 [load] input-dir，收到 sender DONE 后退出
+func (r *Receiver) Ship() error {
+	startTime := time.Now()
+	listener, err := tls.Listen("tcp", r.cfg.ShipConfig.ListenAddr, r.tlsCfg)
+	if err != nil {
+		return fmt.Errorf("ship receiver listen [%s] failed: %v", r.cfg.ShipConfig.ListenAddr, err)
+	}
+	defer listener.Close()
+
+	zap.L().Info("ship csv file receiver start",
+		zap.String("listen addr", r.cfg.ShipConfig.ListenAddr),
+		zap.String("input dir", r.cfg.LoadConfig.InputDir))
+
+	doneC := make(chan error, 1)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				doneC <- fmt.Errorf("ship receiver accept failed: %v", err)
+				return
+			}
+			go func() {
+				defer conn.Close()
+				isDone, err := r.handleConn(conn)
+				if err != nil {
+					zap.L().Warn("ship receiver handle conn failed",
+						zap.String("remote addr", conn.RemoteAddr().String()),
+						zap.Error(err))
+				}
+				if isDone {
+					doneC <- err
+				}
+			}()
+		}
+	}()
+
+	select {
+	case err = <-doneC:
+		if err != nil {
+			return err
+		}
+	case <-r.ctx.Done():
+		return r.ctx.Err()
+	}
+
+	zap.L().Info("ship csv file receiver finished",
+		zap.String("input dir", r.cfg.LoadConfig.InputDir),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
+
+func (r *Receiver) handleConn(conn net.Conn) (bool, error) {
+	var header shipHeader
+	if err := readMessage(conn, &header); err != nil {
+		return false, err
+	}
+
+	if header.Type == shipMsgDone {
+		if r.cfg.ShipConfig.AutoLoad {
+			errL := r.autoLoad()
+			if errL != nil {
+				return true, writeMessage(conn, &shipReply{Status: shipReplyError, Message: errL.Error()})
+			}
+		}
+		return true, writeMessage(conn, &shipReply{Status: shipReplyOK})
+	}
+
+	if header.FileSize < 0 {
+		return false, writeMessage(conn, &shipReply{Status: shipReplyError, Message: fmt.Sprintf("file [%s] size [%d] is invalid", header.FileName, header.FileSize)})
+	}
+	tableDir, err := shipInputPath(r.cfg.LoadConfig.InputDir, header.SchemaName, header.TableName)
+	if err != nil {
+		return false, writeMessage(conn, &shipReply{Status: shipReplyError, Message: err.Error()})
+	}
+	if err = common.PathExist(tableDir); err != nil {
+		return false, writeMessage(conn, &shipReply{Status: shipReplyError, Message: err.Error()})
+	}
+	fileName, err := shipInputPath(r.cfg.LoadConfig.InputDir, header.SchemaName, header.TableName, header.FileName)
+	if err != nil {
+		return false, writeMessage(conn, &shipReply{Status: shipReplyError, Message: err.Error()})
+	}
+	partName := common.StringsBuilder(fileName, ".part")
+
+	// 文件已存在且校验一致，跳过
+	if checksum, err := fileChecksum(fileName); err == nil && checksum == header.Checksum {
+		return false, writeMessage(conn, &shipReply{Status: shipReplySkip, Offset: header.FileSize})
+	}
+
+	var offset int64
+	if fi, err := os.Stat(partName); err == nil {
+		offset = fi.Size()
+	}
+	if offset > header.FileSize {
+		offset = 0
+	}
+	f, err := os.OpenFile(partName, os.O_WRONLY|os.O_CREATE, 0666)
+	if err != nil {
+		return false, writeMessage(conn, &shipReply{Status: shipReplyError, Message: err.Error()})
+	}
+	defer f.Close()
+	if err = f.Truncate(offset); err != nil {
+		return false, writeMessage(conn, &shipReply{Status: shipReplyError, Message: err.Error()})
+	}
+	if _, err = f.Seek(offset, io.SeekStart); err != nil {
+		return false, writeMessage(conn, &shipReply{Status: shipReplyError, Message: err.Error()})
+	}
+
+	if err = writeMessage(conn, &shipReply{Status: shipReplyOK, Offset: offset}); err != nil {
+		return false, err
+	}
+	// 传输中断已接收数据保留在 .part，下次续传
+	if _, err = io.CopyN(f, conn, header.FileSize-offset); err != nil {
+		return false, fmt.Errorf("receive file [%s] failed: %v", fileName, err)
+	}
+	if err = f.Sync(); err != nil {
+		return false, err
+	}
+
+	checksum, err := fileChecksum(partName)
+	if err != nil {
+		return false, writeMessage(conn, &shipReply{Status: shipReplyError, Message: err.Error()})
+	}
+	if checksum != header.Checksum {
+		if errR := os.Remove(partName); errR != nil {
+			zap.L().Warn("ship receiver remove part file failed", zap.String("file", partName), zap.Error(errR))
+		}
+		return false, writeMessage(conn, &shipReply{Status: shipReplyError, Offset: offset,
+			Message: fmt.Sprintf("file [%s] checksum [%s] isn't equal sender checksum [%s]", fileName, checksum, header.Checksum)})
+	}
+	if err = os.Rename(partName, fileName); err != nil {
+		return false, writeMessage(conn, &shipReply{Status: shipReplyError, Message: err.Error()})
+	}
+
+	zap.L().Info("receive file finished",
+		zap.String("file", fileName),
+		zap.Int64("resume offset", offset),
+		zap.Int64("size", header.FileSize))
+	return false, writeMessage(conn, &shipReply{Status: shipReplyOK, Offset: offset})
+}
+
+// autoLoad 全部文件接收完成，按 [load] 配置导入下游
+func (r *Receiver) autoLoad() error {
+	loader, err := f2m.NewLoader(r.ctx, r.cfg)
+	if err != nil {
+		return err
+	}
+	return loader.Load()
+}
+
+// shipInputPath sender 传入 schema/table/文件名只允许单级名称，拼接后路径必须位于 input-dir 之内，避免路径穿越
+func shipInputPath(inputDir string, names ...string) (string, error) {
+	baseDir, err := filepath.Abs(inputDir)
+	if err != nil {
+		return "", fmt.Errorf("get input dir [%s] abs path failed: %v", inputDir, err)
+	}
+	elems := []string{baseDir}
+	for _, n := range names {
+		if n == "" || n == "." || n == ".." || strings.ContainsAny(n, `/\`) || strings.ContainsRune(n, os.PathSeparator) {
+			return "", fmt.Errorf("ship name [%s] is invalid, only support single level name", n)
+		}
+		elems = append(elems, n)
+	}
+	fullPath := filepath.Join(elems...)
+	rel, err := filepath.Rel(baseDir, fullPath)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, common.StringsBuilder("..", string(os.PathSeparator))) {
+		return "", fmt.Errorf("ship path [%s] isn't under input dir [%s]", fullPath, baseDir)
+	}
+	return fullPath, nil
+}
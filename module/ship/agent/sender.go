@@ -0,0 +1,291 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package agent
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/module/csv"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type Sender struct {
+	ctx    context.Context
+	cfg    *config.Config
+	tlsCfg *tls.Config
+	metaDB *meta.Meta
+}
+
+func NewSender(ctx context.Context, cfg *config.Config) (*Sender, error) {
+	host, _, err := net.SplitHostPort(cfg.ShipConfig.RemoteAddr)
+	if err != nil {
+		return nil, fmt.Errorf("ship remote addr [%s] parse failed: %v", cfg.ShipConfig.RemoteAddr, err)
+	}
+	tlsCfg, err := newTLSConfig(cfg.ShipConfig.CAPath, cfg.ShipConfig.CertPath, cfg.ShipConfig.KeyPath, host, false)
+	if err != nil {
+		return nil, err
+	}
+	metaDB, err := meta.NewMetaDBEngine(ctx, cfg.MySQLConfig, cfg.AppConfig.SlowlogThreshold)
+	if err != nil {
+		return nil, err
+	}
+	return &Sender{
+		ctx:    ctx,
+		cfg:    cfg,
+		tlsCfg: tlsCfg,
+		metaDB: metaDB,
+	}, nil
+}
+
+func (s *Sender) Ship() error {
+	startTime := time.Now()
+	zap.L().Info("ship csv file sender start",
+		zap.String("output dir", s.cfg.CSVConfig.OutputDir),
+		zap.String("remote addr", s.cfg.ShipConfig.RemoteAddr))
+
+	if csv.IsObjectStorage(s.cfg.CSVConfig.OutputDir) {
+		return fmt.Errorf("csv config output-dir [%s] is object storage, ship isn't required", s.cfg.CSVConfig.OutputDir)
+	}
+
+	// 只传输导出完成（已生成 manifest.json）的表
+	manifestFiles, err := filepath.Glob(filepath.Join(s.cfg.CSVConfig.OutputDir, "*", "*", common.MigrateCSVManifestFile))
+	if err != nil {
+		return fmt.Errorf("glob output dir [%s] manifest failed: %v", s.cfg.CSVConfig.OutputDir, err)
+	}
+	if len(manifestFiles) == 0 {
+		return fmt.Errorf("output dir [%s] isn't exist table manifest [%s], please firstly running csv mode", s.cfg.CSVConfig.OutputDir, common.MigrateCSVManifestFile)
+	}
+
+	var failedTables []string
+	for _, f := range manifestFiles {
+		failedFiles, err := s.shipTable(filepath.Dir(f))
+		if err != nil {
+			return err
+		}
+		if failedFiles > 0 {
+			failedTables = append(failedTables, filepath.Dir(f))
+		}
+	}
+
+	// 存在失败文件不通知 receiver 完成，重新运行续传
+	if len(failedTables) > 0 {
+		zap.L().Warn("ship csv file sender exist failed table, skip done",
+			zap.Strings("failed tables", failedTables),
+			zap.String("log detail", "please see meta table [ship_sync_meta] and rerunning"),
+			zap.String("cost", time.Now().Sub(startTime).String()))
+		return nil
+	}
+	if err = s.shipDone(); err != nil {
+		return err
+	}
+
+	zap.L().Info("ship csv file sender finished",
+		zap.String("output dir", s.cfg.CSVConfig.OutputDir),
+		zap.Int("table totals", len(manifestFiles)),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
+
+// shipTable 单表数据文件并发传输，全部成功后最后传输 manifest.json，返回失败文件数
+func (s *Sender) shipTable(tableDir string) (int, error) {
+	startTime := time.Now()
+	manifest, err := csv.ReadManifest(tableDir)
+	if err != nil {
+		return 0, err
+	}
+
+	// 记录文件 sha256，重新导出文件内容变化时重置传输状态
+	var shipMetas []meta.ShipSyncMeta
+	for _, f := range manifest.Files {
+		checksum, err := fileChecksum(filepath.Join(tableDir, f.FileName))
+		if err != nil {
+			return 0, err
+		}
+		shipMetas = append(shipMetas, meta.ShipSyncMeta{
+			SchemaNameS: common.StringUPPER(manifest.SchemaNameS),
+			TableNameS:  common.StringUPPER(manifest.TableNameS),
+			FileName:    f.FileName,
+			FileSize:    f.FileSize,
+			Checksum:    checksum,
+			TaskStatus:  common.TaskStatusWaiting,
+		})
+	}
+	if len(shipMetas) > 0 {
+		if err = meta.NewShipSyncMetaModel(s.metaDB).BatchCreateShipSyncMeta(s.ctx, shipMetas, s.cfg.AppConfig.InsertBatchSize); err != nil {
+			return 0, err
+		}
+	}
+
+	fileMetas, err := meta.NewShipSyncMetaModel(s.metaDB).DetailShipSyncMeta(s.ctx, &meta.ShipSyncMeta{
+		SchemaNameS: common.StringUPPER(manifest.SchemaNameS),
+		TableNameS:  common.StringUPPER(manifest.TableNameS),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	g := &errgroup.Group{}
+	g.SetLimit(s.cfg.ShipConfig.FileThreads)
+	for _, fileMeta := range fileMetas {
+		m := fileMeta
+		if strings.EqualFold(m.TaskStatus, common.TaskStatusSuccess) {
+			continue
+		}
+		g.Go(func() error {
+			checksum, errS := s.shipFile(tableDir, m.FileName)
+			// record error, skip error
+			if errS != nil {
+				return meta.NewShipSyncMetaModel(s.metaDB).UpdateShipSyncMeta(s.ctx, &m, map[string]interface{}{
+					"TaskStatus":  common.TaskStatusFailed,
+					"ErrorDetail": errS.Error(),
+				})
+			}
+			return meta.NewShipSyncMetaModel(s.metaDB).UpdateShipSyncMeta(s.ctx, &m, map[string]interface{}{
+				"TaskStatus":  common.TaskStatusSuccess,
+				"Checksum":    checksum,
+				"ErrorDetail": "",
+			})
+		})
+	}
+	if err = g.Wait(); err != nil {
+		return 0, err
+	}
+
+	failedMetas, err := meta.NewShipSyncMetaModel(s.metaDB).DetailShipSyncMeta(s.ctx, &meta.ShipSyncMeta{
+		SchemaNameS: common.StringUPPER(manifest.SchemaNameS),
+		TableNameS:  common.StringUPPER(manifest.TableNameS),
+		TaskStatus:  common.TaskStatusFailed,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(failedMetas) > 0 {
+		zap.L().Warn("ship single table csv file failed",
+			zap.String("schema", manifest.SchemaNameS),
+			zap.String("table", manifest.TableNameS),
+			zap.Int("file failed", len(failedMetas)))
+		return len(failedMetas), nil
+	}
+
+	// receiver 以 manifest.json 识别表文件是否传输完整
+	if _, err = s.shipFile(tableDir, common.MigrateCSVManifestFile); err != nil {
+		return 0, err
+	}
+
+	zap.L().Info("ship single table csv file finished",
+		zap.String("schema", manifest.SchemaNameS),
+		zap.String("table", manifest.TableNameS),
+		zap.Int("file totals", len(fileMetas)),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return 0, nil
+}
+
+// shipFile 单文件单连接传输，receiver 返回已接收字节数续传，传输完成 receiver sha256 校验
+func (s *Sender) shipFile(tableDir, fileName string) (string, error) {
+	filePath := filepath.Join(tableDir, fileName)
+	fi, err := os.Stat(filePath)
+	if err != nil {
+		return "", fmt.Errorf("stat ship file [%s] failed: %v", filePath, err)
+	}
+	checksum, err := fileChecksum(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	conn, err := tls.Dial("tcp", s.cfg.ShipConfig.RemoteAddr, s.tlsCfg)
+	if err != nil {
+		return checksum, fmt.Errorf("dial ship receiver [%s] failed: %v", s.cfg.ShipConfig.RemoteAddr, err)
+	}
+	defer conn.Close()
+
+	if err = writeMessage(conn, &shipHeader{
+		Type:       shipMsgFile,
+		SchemaName: filepath.Base(filepath.Dir(tableDir)),
+		TableName:  filepath.Base(tableDir),
+		FileName:   fileName,
+		FileSize:   fi.Size(),
+		Checksum:   checksum,
+	}); err != nil {
+		return checksum, err
+	}
+
+	var reply shipReply
+	if err = readMessage(conn, &reply); err != nil {
+		return checksum, err
+	}
+	switch reply.Status {
+	case shipReplySkip:
+		return checksum, nil
+	case shipReplyError:
+		return checksum, fmt.Errorf("ship file [%s] receiver error: %s", filePath, reply.Message)
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return checksum, err
+	}
+	defer f.Close()
+	if _, err = f.Seek(reply.Offset, io.SeekStart); err != nil {
+		return checksum, fmt.Errorf("seek ship file [%s] offset [%d] failed: %v", filePath, reply.Offset, err)
+	}
+	if _, err = io.CopyN(conn, f, fi.Size()-reply.Offset); err != nil {
+		return checksum, fmt.Errorf("send ship file [%s] offset [%d] failed: %v", filePath, reply.Offset, err)
+	}
+
+	if err = readMessage(conn, &reply); err != nil {
+		return checksum, err
+	}
+	if reply.Status != shipReplyOK {
+		return checksum, fmt.Errorf("ship file [%s] receiver verify failed: %s", filePath, reply.Message)
+	}
+	zap.L().Info("ship file finished",
+		zap.String("file", filePath),
+		zap.Int64("resume offset", reply.Offset),
+		zap.Int64("size", fi.Size()))
+	return checksum, nil
+}
+
+// shipDone 通知 receiver 全部文件传输完成，receiver 开启 auto-load 则导入完成后应答
+func (s *Sender) shipDone() error {
+	conn, err := tls.Dial("tcp", s.cfg.ShipConfig.RemoteAddr, s.tlsCfg)
+	if err != nil {
+		return fmt.Errorf("dial ship receiver [%s] failed: %v", s.cfg.ShipConfig.RemoteAddr, err)
+	}
+	defer conn.Close()
+
+	if err = writeMessage(conn, &shipHeader{Type: shipMsgDone}); err != nil {
+		return err
+	}
+	var reply shipReply
+	if err = readMessage(conn, &reply); err != nil {
+		return err
+	}
+	if reply.Status != shipReplyOK {
+		return fmt.Errorf("ship receiver done failed: %s", reply.Message)
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package o2m
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/database/mysql"
+	"github.com/wentaojin/transferdb/database/oracle"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	relationSourceDefined = "DEFINED"
+	relationSourceConfig  = "CONFIG"
+)
+
+type Verify struct {
+	ctx    context.Context
+	cfg    *config.Config
+	oracle *oracle.Oracle
+	mysql  *mysql.MySQL
+	metaDB *meta.Meta
+}
+
+// relation 下游子表 -> 父表引用关系
+type relation struct {
+	tableName  string
+	columns    []string
+	rtableName string
+	rcolumns   []string
+	source     string
+}
+
+func NewVerify(ctx context.Context, cfg *config.Config) (*Verify, error) {
+	oracleDB, err := oracle.NewOracleDBEngine(ctx, cfg.OracleConfig)
+	if err != nil {
+		return nil, err
+	}
+	mysqlDB, err := mysql.NewMySQLDBEngine(ctx, cfg.MySQLConfig)
+	if err != nil {
+		return nil, err
+	}
+	metaDB, err := meta.NewMetaDBEngine(ctx, cfg.MySQLConfig, cfg.AppConfig.SlowlogThreshold)
+	if err != nil {
+		return nil, err
+	}
+	return &Verify{
+		ctx:    ctx,
+		cfg:    cfg,
+		oracle: oracleDB,
+		mysql:  mysqlDB,
+		metaDB: metaDB,
+	}, nil
+}
+
+func (r *Verify) Verify() error {
+	startTime := time.Now()
+	zap.L().Info("verify target referential integrity start",
+		zap.String("oracleSchema", r.cfg.OracleConfig.SchemaName),
+		zap.String("mysqlSchema", r.cfg.MySQLConfig.SchemaName))
+
+	relations, err := r.genRelations()
+	if err != nil {
+		return err
+	}
+
+	// 清理历史校验记录
+	if err = meta.NewForeignKeyVerifyModel(r.metaDB).DeleteForeignKeyVerify(r.ctx, &meta.ForeignKeyVerify{
+		DBTypeS:     r.cfg.DBTypeS,
+		DBTypeT:     r.cfg.DBTypeT,
+		SchemaNameT: r.cfg.MySQLConfig.SchemaName,
+	}); err != nil {
+		return err
+	}
+
+	var orphanRelations, failedRelations int64
+
+	g := &errgroup.Group{}
+	g.SetLimit(r.cfg.VerifyConfig.Threads)
+	for _, rel := range relations {
+		re := rel
+		g.Go(func() error {
+			record := &meta.ForeignKeyVerify{
+				DBTypeS:        r.cfg.DBTypeS,
+				DBTypeT:        r.cfg.DBTypeT,
+				SchemaNameT:    common.StringUPPER(r.cfg.MySQLConfig.SchemaName),
+				TableNameT:     re.tableName,
+				ColumnListT:    strings.Join(re.columns, ","),
+				RTableNameT:    re.rtableName,
+				RColumnListT:   strings.Join(re.rcolumns, ","),
+				RelationSource: re.source,
+				TaskStatus:     common.TaskStatusSuccess,
+			}
+			orphanRows, sampleKeys, err := r.mysql.GetMySQLTableOrphanRows(r.cfg.MySQLConfig.SchemaName,
+				re.tableName, re.columns, re.rtableName, re.rcolumns, r.cfg.VerifyConfig.SampleRows)
+			if err != nil {
+				// record error, skip error
+				atomic.AddInt64(&failedRelations, 1)
+				record.TaskStatus = common.TaskStatusFailed
+				record.ErrorDetail = err.Error()
+			} else if orphanRows > 0 {
+				atomic.AddInt64(&orphanRelations, 1)
+				record.TaskStatus = common.TaskStatusFailed
+				record.OrphanRows = orphanRows
+				record.SampleKeys = strings.Join(sampleKeys, ";")
+				zap.L().Warn("verify target table orphan rows",
+					zap.String("schema", r.cfg.MySQLConfig.SchemaName),
+					zap.String("table", re.tableName),
+					zap.Strings("columns", re.columns),
+					zap.String("parent table", re.rtableName),
+					zap.Strings("parent columns", re.rcolumns),
+					zap.Int64("orphan rows", orphanRows),
+					zap.Strings("sample keys", sampleKeys))
+			}
+			return meta.NewForeignKeyVerifyModel(r.metaDB).CreateForeignKeyVerify(r.ctx, record)
+		})
+	}
+	if err = g.Wait(); err != nil {
+		return err
+	}
+
+	zap.L().Info("verify target referential integrity finished",
+		zap.String("mysqlSchema", r.cfg.MySQLConfig.SchemaName),
+		zap.Int("relations", len(relations)),
+		zap.Int64("orphan relations", orphanRelations),
+		zap.Int64("failed relations", failedRelations),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+
+	if orphanRelations > 0 || failedRelations > 0 {
+		return fmt.Errorf("verify target schema [%s] referential integrity found [%d] orphan relations and [%d] failed relations, please see meta table [foreign_key_verify]",
+			r.cfg.MySQLConfig.SchemaName, orphanRelations, failedRelations)
+	}
+	return nil
+}
+
+// genRelations 上游已定义外键（按表名规则映射下游表）以及手工配置关系
+func (r *Verify) genRelations() ([]relation, error) {
+	var relations []relation
+
+	exporters, err := filterCFGTable(r.ctx, r.cfg, r.oracle, r.metaDB)
+	if err != nil {
+		return relations, err
+	}
+	tableNameRule, err := r.getTableNameRule()
+	if err != nil {
+		return relations, err
+	}
+	targetTable := func(sourceTable string) string {
+		if val, ok := tableNameRule[common.StringUPPER(sourceTable)]; ok {
+			return val
+		}
+		return common.StringUPPER(sourceTable)
+	}
+
+	for _, t := range exporters {
+		fks, err := r.oracle.GetOracleSchemaTableForeignKey(r.cfg.OracleConfig.SchemaName, t)
+		if err != nil {
+			return relations, err
+		}
+		for _, fk := range fks {
+			// 跨 schema 或者父表未迁移关系跳过
+			if !strings.EqualFold(fk["R_OWNER"], r.cfg.OracleConfig.SchemaName) || !common.IsContainString(exporters, common.StringUPPER(fk["RTABLE_NAME"])) {
+				zap.L().Warn("verify skip oracle foreign key, parent table isn't migrated",
+					zap.String("schema", r.cfg.OracleConfig.SchemaName),
+					zap.String("table", t),
+					zap.String("constraint", fk["CONSTRAINT_NAME"]),
+					zap.String("parent schema", fk["R_OWNER"]),
+					zap.String("parent table", fk["RTABLE_NAME"]))
+				continue
+			}
+			relations = append(relations, relation{
+				tableName:  targetTable(t),
+				columns:    strings.Split(fk["COLUMN_LIST"], ","),
+				rtableName: targetTable(fk["RTABLE_NAME"]),
+				rcolumns:   strings.Split(fk["RCOLUMN_LIST"], ","),
+				source:     relationSourceDefined,
+			})
+		}
+	}
+
+	for _, rel := range r.cfg.VerifyConfig.VerifyRelation {
+		columns := splitColumns(rel.ChildColumns)
+		rcolumns := splitColumns(rel.ParentColumns)
+		if rel.ChildTable == "" || rel.ParentTable == "" || len(columns) == 0 || len(columns) != len(rcolumns) {
+			return relations, fmt.Errorf("verify config relation [%v] isn't valid, child-table/parent-table can't be null and child-columns must correspond to parent-columns", rel)
+		}
+		relations = append(relations, relation{
+			tableName:  common.StringUPPER(rel.ChildTable),
+			columns:    columns,
+			rtableName: common.StringUPPER(rel.ParentTable),
+			rcolumns:   rcolumns,
+			source:     relationSourceConfig,
+		})
+	}
+	return relations, nil
+}
+
+func (r *Verify) getTableNameRule() (map[string]string, error) {
+	// 获取表名自定义规则以及正则改写规则
+	rewriter, err := r.cfg.RewriteConfig.TableRewriter()
+	if err != nil {
+		return nil, err
+	}
+	sourceTables, err := r.oracle.GetOracleSchemaTable(common.StringUPPER(r.cfg.OracleConfig.SchemaName))
+	if err != nil {
+		return nil, err
+	}
+	return meta.NewTableNameRuleModel(r.metaDB).DetailTableNameRuleMap(r.ctx, &meta.TableNameRule{
+		DBTypeS:     r.cfg.DBTypeS,
+		DBTypeT:     r.cfg.DBTypeT,
+		SchemaNameS: r.cfg.OracleConfig.SchemaName,
+		SchemaNameT: r.cfg.MySQLConfig.SchemaName,
+	}, sourceTables, rewriter)
+}
+
+func splitColumns(columnList string) []string {
+	var columns []string
+	for _, c := range strings.Split(columnList, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			columns = append(columns, common.StringUPPER(c))
+		}
+	}
+	return columns
+}
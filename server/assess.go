@@ -20,6 +20,7 @@ import (
 	"github.com/wentaojin/transferdb/common"
 	"github.com/wentaojin/transferdb/config"
 	"github.com/wentaojin/transferdb/module/assess"
+	"github.com/wentaojin/transferdb/module/assess/db2m"
 	"github.com/wentaojin/transferdb/module/assess/o2m"
 	"strings"
 )
@@ -35,6 +36,11 @@ func IAssess(ctx context.Context, cfg *config.Config) error {
 		if err != nil {
 			return err
 		}
+	case strings.EqualFold(cfg.DBTypeS, common.DatabaseTypeDB2) && strings.EqualFold(cfg.DBTypeT, common.DatabaseTypeMySQL):
+		a, err = db2m.NewAssess(ctx, cfg)
+		if err != nil {
+			return err
+		}
 	}
 
 	err = a.Assess()
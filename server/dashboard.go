@@ -0,0 +1,295 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/meta"
+	"go.uber.org/zap"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// TableProgress 表级任务进度，行数按成功 chunk 数 * chunk 校准行数估算，非精确已写入行数
+type TableProgress struct {
+	TableName     string  `json:"table_name"`
+	TaskStatus    string  `json:"task_status"`
+	ChunkTotal    int64   `json:"chunk_total"`
+	ChunkSuccess  int64   `json:"chunk_success"`
+	ChunkFailed   int64   `json:"chunk_failed"`
+	ChunkRunning  int64   `json:"chunk_running"`
+	ChunkWaiting  int64   `json:"chunk_waiting"`
+	RowsEstimated int64   `json:"rows_estimated"`
+	RowsPerSecond float64 `json:"rows_per_second"`
+	Elapsed       string  `json:"elapsed"`
+	ETA           string  `json:"eta"`
+}
+
+// TaskProgress 任务整体进度
+type TaskProgress struct {
+	TaskMode      string          `json:"task_mode"`
+	SchemaName    string          `json:"schema_name"`
+	TableTotal    int             `json:"table_total"`
+	TableSuccess  int             `json:"table_success"`
+	TableFailed   int             `json:"table_failed"`
+	ChunkTotal    int64           `json:"chunk_total"`
+	ChunkSuccess  int64           `json:"chunk_success"`
+	ChunkFailed   int64           `json:"chunk_failed"`
+	RowsEstimated int64           `json:"rows_estimated"`
+	RowsPerSecond float64         `json:"rows_per_second"`
+	ETA           string          `json:"eta"`
+	Tables        []TableProgress `json:"tables"`
+}
+
+// startDashboard 可选 HTTP 服务，提供任务进度页面以及 JSON 接口，独立于 pprof-port 监听
+// GET /                 任务进度页面
+// GET /api/v1/progress  任务进度 JSON，task-mode 缺省取当前任务模式
+// 未指定监听主机时仅绑定 127.0.0.1，返回的 stop 用于任务退出时关闭服务
+func startDashboard(ctx context.Context, cfg *config.Config) (func(), error) {
+	if cfg.AppConfig.HTTPAddr == "" {
+		return func() {}, nil
+	}
+	addr, err := dashboardListenAddr(cfg.AppConfig.HTTPAddr)
+	if err != nil {
+		return nil, err
+	}
+	metaDB, err := meta.NewMetaDBEngine(ctx, cfg.MySQLConfig, cfg.AppConfig.SlowlogThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/" {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(dashboardHTML))
+	})
+	mux.HandleFunc("/api/v1/progress", func(w http.ResponseWriter, req *http.Request) {
+		taskMode := common.StringUPPER(req.URL.Query().Get("task-mode"))
+		if taskMode == "" {
+			taskMode = common.StringUPPER(cfg.TaskMode)
+		}
+		progress, err := getTaskProgress(ctx, cfg, metaDB, taskMode)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err = json.NewEncoder(w).Encode(progress); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	srv := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		zap.L().Info("dashboard http server start", zap.String("http addr", addr))
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			zap.L().Error("dashboard http server failed", zap.String("http addr", addr), zap.Error(err))
+		}
+	}()
+	return func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			zap.L().Warn("dashboard http server shutdown failed", zap.String("http addr", addr), zap.Error(err))
+		}
+	}, nil
+}
+
+// dashboardListenAddr 监听地址未指定主机（如 :8080）时默认仅绑定本机，需对外暴露请显式配置 0.0.0.0:port
+func dashboardListenAddr(httpAddr string) (string, error) {
+	host, port, err := net.SplitHostPort(httpAddr)
+	if err != nil {
+		return "", fmt.Errorf("config [app] http-addr [%s] invalid: %v", httpAddr, err)
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// getTaskProgress 汇总 wait_sync_meta 表级状态以及 full_sync_meta chunk 状态
+// 表完成后 full_sync_meta 记录清理，chunk 成功数取 wait_sync_meta chunk_success_nums
+func getTaskProgress(ctx context.Context, cfg *config.Config, metaDB *meta.Meta, taskMode string) (TaskProgress, error) {
+	progress := TaskProgress{
+		TaskMode:   taskMode,
+		SchemaName: common.StringUPPER(cfg.OracleConfig.SchemaName),
+	}
+
+	waitMetas, err := meta.NewWaitSyncMetaModel(metaDB).DetailWaitSyncMeta(ctx, &meta.WaitSyncMeta{
+		DBTypeS:     cfg.DBTypeS,
+		DBTypeT:     cfg.DBTypeT,
+		SchemaNameS: progress.SchemaName,
+		TaskMode:    taskMode,
+	})
+	if err != nil {
+		return progress, err
+	}
+	chunkCounts, err := meta.NewFullSyncMetaModel(metaDB).CountsFullSyncMetaGroupByTableStatus(ctx, &meta.FullSyncMeta{
+		DBTypeS:     cfg.DBTypeS,
+		DBTypeT:     cfg.DBTypeT,
+		SchemaNameS: progress.SchemaName,
+		TaskMode:    taskMode,
+	})
+	if err != nil {
+		return progress, err
+	}
+	tableChunks := make(map[string][]meta.FullSyncMetaStatusCounts)
+	for _, c := range chunkCounts {
+		tableChunks[c.TableNameS] = append(tableChunks[c.TableNameS], c)
+	}
+
+	now := time.Now()
+	var (
+		taskStart time.Time
+		taskRows  int64
+	)
+	for _, w := range waitMetas {
+		tp := TableProgress{
+			TableName:  w.TableNameS,
+			TaskStatus: w.TaskStatus,
+			ChunkTotal: w.ChunkTotalNums,
+		}
+		startAt, lastAt := w.CreatedAt, now
+		switch w.TaskStatus {
+		case common.TaskStatusSuccess, common.TaskStatusFailed:
+			tp.ChunkSuccess, tp.ChunkFailed = w.ChunkSuccessNums, w.ChunkFailedNums
+			lastAt = w.UpdatedAt
+		default:
+			for _, c := range tableChunks[w.TableNameS] {
+				switch c.TaskStatus {
+				case common.TaskStatusSuccess:
+					tp.ChunkSuccess += c.Counts
+				case common.TaskStatusFailed:
+					tp.ChunkFailed += c.Counts
+				case common.TaskStatusRunning:
+					tp.ChunkRunning += c.Counts
+				default:
+					tp.ChunkWaiting += c.Counts
+				}
+				if !c.StartAt.IsZero() && c.StartAt.Before(startAt) {
+					startAt = c.StartAt
+				}
+			}
+		}
+		tp.RowsEstimated = tp.ChunkSuccess * w.ChunkRows
+
+		elapsed := lastAt.Sub(startAt)
+		tp.Elapsed = elapsed.Round(time.Second).String()
+		if elapsed.Seconds() > 0 {
+			tp.RowsPerSecond = float64(tp.RowsEstimated) / elapsed.Seconds()
+		}
+		tp.ETA = estimateETA(elapsed, tp.ChunkSuccess+tp.ChunkFailed, tp.ChunkTotal)
+
+		if taskStart.IsZero() || startAt.Before(taskStart) {
+			taskStart = startAt
+		}
+		taskRows += tp.RowsEstimated
+		switch w.TaskStatus {
+		case common.TaskStatusSuccess:
+			progress.TableSuccess++
+		case common.TaskStatusFailed:
+			progress.TableFailed++
+		}
+		progress.ChunkTotal += tp.ChunkTotal
+		progress.ChunkSuccess += tp.ChunkSuccess
+		progress.ChunkFailed += tp.ChunkFailed
+		progress.Tables = append(progress.Tables, tp)
+	}
+	sort.Slice(progress.Tables, func(i, j int) bool {
+		return progress.Tables[i].TableName < progress.Tables[j].TableName
+	})
+
+	progress.TableTotal = len(waitMetas)
+	progress.RowsEstimated = taskRows
+	if !taskStart.IsZero() {
+		elapsed := now.Sub(taskStart)
+		if elapsed.Seconds() > 0 {
+			progress.RowsPerSecond = float64(taskRows) / elapsed.Seconds()
+		}
+		progress.ETA = estimateETA(elapsed, progress.ChunkSuccess+progress.ChunkFailed, progress.ChunkTotal)
+	}
+	return progress, nil
+}
+
+// estimateETA 按已完成 chunk 平均耗时线性估算剩余时间
+func estimateETA(elapsed time.Duration, done, total int64) string {
+	if total <= 0 || done >= total {
+		return "0s"
+	}
+	if done <= 0 {
+		return "unknown"
+	}
+	return (time.Duration(float64(elapsed) / float64(done) * float64(total-done))).Round(time.Second).String()
+}
+
+const dashboardHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>transferdb dashboard</title>
+<style>
+body { font-family: sans-serif; margin: 20px; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+.FAILED { color: #c00; }
+.SUCCESS { color: #080; }
+</style>
+</head>
+<body>
+<h3 id="summary">loading...</h3>
+<table>
+<thead><tr><th>Table</th><th>Status</th><th>Chunks</th><th>Success</th><th>Failed</th><th>Running</th><th>Waiting</th><th>Rows (est.)</th><th>Rows/s (est.)</th><th>Elapsed</th><th>ETA</th></tr></thead>
+<tbody id="tables"></tbody>
+</table>
+<script>
+function refresh() {
+  fetch('/api/v1/progress').then(function (r) { return r.json(); }).then(function (p) {
+    document.getElementById('summary').textContent = p.task_mode + ' ' + p.schema_name +
+      ' tables ' + p.table_success + '/' + p.table_total + ' (failed ' + p.table_failed + ')' +
+      ' chunks ' + p.chunk_success + '/' + p.chunk_total + ' (failed ' + p.chunk_failed + ')' +
+      ' rows (est.) ' + p.rows_estimated + ' rows/s (est.) ' + p.rows_per_second.toFixed(0) + ' eta ' + p.eta;
+    var tbody = document.getElementById('tables');
+    while (tbody.firstChild) { tbody.removeChild(tbody.firstChild); }
+    (p.tables || []).forEach(function (t) {
+      var tr = document.createElement('tr');
+      tr.className = t.task_status;
+      [t.table_name, t.task_status, t.chunk_total, t.chunk_success, t.chunk_failed, t.chunk_running, t.chunk_waiting,
+        t.rows_estimated, t.rows_per_second.toFixed(0), t.elapsed, t.eta].forEach(function (v) {
+        var td = document.createElement('td');
+        td.textContent = String(v);
+        tr.appendChild(td);
+      });
+      tbody.appendChild(tr);
+    });
+  });
+}
+refresh();
+setInterval(refresh, 5000);
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,99 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/module/csv/o2m"
+	"github.com/wentaojin/transferdb/module/load"
+	"github.com/wentaojin/transferdb/module/load/analytic"
+	"github.com/wentaojin/transferdb/module/load/f2m"
+	"github.com/wentaojin/transferdb/module/load/lightning"
+	"strings"
+)
+
+func ILoader(ctx context.Context, cfg *config.Config) error {
+	var (
+		l   load.Loader
+		err error
+	)
+	// 上游为 csv 导出文件，只区分下游数据库类型
+	switch {
+	case strings.EqualFold(cfg.DBTypeT, common.DatabaseTypeMySQL) || strings.EqualFold(cfg.DBTypeT, common.DatabaseTypeTiDB):
+		l, err = f2m.NewLoader(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("load mode target db type [%s] isn't support", cfg.DBTypeT)
+	}
+
+	err = l.Load()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ILightning csv 导出后调用 tidb-lightning local backend 导入，仅适用于 oracle -> tidb
+func ILightning(ctx context.Context, cfg *config.Config) error {
+	if !strings.EqualFold(cfg.DBTypeS, common.DatabaseTypeOracle) || !strings.EqualFold(cfg.DBTypeT, common.DatabaseTypeTiDB) {
+		return fmt.Errorf("lightning mode db type [%s] -> [%s] isn't support, only support oracle -> tidb", cfg.DBTypeS, cfg.DBTypeT)
+	}
+	l, err := lightning.NewLightning(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	if !cfg.LightningConfig.SkipExport {
+		c, err := o2m.NewCSVer(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		if err = c.CSV(); err != nil {
+			return err
+		}
+	}
+	return l.Load()
+}
+
+// IAnalytic csv 按目标端方言导出后生成建表语句并调用原生批量导入工具，仅适用于 oracle -> clickhouse/greenplum
+func IAnalytic(ctx context.Context, cfg *config.Config) error {
+	if !strings.EqualFold(cfg.DBTypeS, common.DatabaseTypeOracle) ||
+		(!strings.EqualFold(cfg.DBTypeT, common.DatabaseTypeClickHouse) && !strings.EqualFold(cfg.DBTypeT, common.DatabaseTypeGreenplum)) {
+		return fmt.Errorf("analytic mode db type [%s] -> [%s] isn't support, only support oracle -> clickhouse/greenplum", cfg.DBTypeS, cfg.DBTypeT)
+	}
+	a, err := analytic.NewAnalytic(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	if !cfg.AnalyticConfig.SkipExport {
+		// 未配置方言时按目标端预置引用、转义以及 NULL 字面量
+		if cfg.CSVConfig.Dialect == "" {
+			cfg.CSVConfig.Dialect = cfg.DBTypeT
+		}
+		c, err := o2m.NewCSVer(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		if err = c.CSV(); err != nil {
+			return err
+		}
+	}
+	return a.Load()
+}
@@ -0,0 +1,173 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/meta"
+	"go.uber.org/zap"
+	"os"
+	"time"
+)
+
+// metaSnapshot 任务元数据快照，用于离线排查
+type metaSnapshot struct {
+	DBTypeS     string                `json:"db_type_s"`
+	DBTypeT     string                `json:"db_type_t"`
+	SchemaNameS string                `json:"schema_name_s"`
+	TaskMode    string                `json:"task_mode"`
+	ExportTime  string                `json:"export_time"`
+	WaitMetas   []meta.WaitSyncMeta   `json:"wait_sync_meta"`
+	FullMetas   []meta.FullSyncMeta   `json:"full_sync_meta"`
+	ErrorLogs   []meta.ErrorLogDetail `json:"error_log_detail"`
+}
+
+// IMetaer 导出任务元数据快照 gzip json 文件，或导入他处导出的快照至当前元数据库
+func IMetaer(ctx context.Context, cfg *config.Config) error {
+	metaDB, err := meta.NewMetaDBEngine(ctx, cfg.MySQLConfig, cfg.AppConfig.SlowlogThreshold)
+	if err != nil {
+		return err
+	}
+	switch cfg.MetaAction {
+	case common.MetaActionExport:
+		return exportMetaSnapshot(ctx, cfg, metaDB)
+	case common.MetaActionImport:
+		return importMetaSnapshot(ctx, cfg, metaDB)
+	default:
+		return fmt.Errorf("flag [meta-action] value [%s] isn't support, only support [export import]", cfg.MetaAction)
+	}
+}
+
+func exportMetaSnapshot(ctx context.Context, cfg *config.Config, metaDB *meta.Meta) error {
+	startTime := time.Now()
+	snapshot := metaSnapshot{
+		DBTypeS:     cfg.DBTypeS,
+		DBTypeT:     cfg.DBTypeT,
+		SchemaNameS: common.StringUPPER(cfg.OracleConfig.SchemaName),
+		TaskMode:    cfg.MetaTask,
+		ExportTime:  startTime.Format(time.RFC3339),
+	}
+
+	waitMetas, err := meta.NewWaitSyncMetaModel(metaDB).DetailWaitSyncMeta(ctx, &meta.WaitSyncMeta{
+		DBTypeS:     snapshot.DBTypeS,
+		DBTypeT:     snapshot.DBTypeT,
+		SchemaNameS: snapshot.SchemaNameS,
+		TaskMode:    snapshot.TaskMode,
+	})
+	if err != nil {
+		return err
+	}
+	fullMetas, err := meta.NewFullSyncMetaModel(metaDB).DetailFullSyncMeta(ctx, &meta.FullSyncMeta{
+		DBTypeS:     snapshot.DBTypeS,
+		DBTypeT:     snapshot.DBTypeT,
+		SchemaNameS: snapshot.SchemaNameS,
+		TaskMode:    snapshot.TaskMode,
+	})
+	if err != nil {
+		return err
+	}
+	errLogs, err := meta.NewErrorLogDetailModel(metaDB).DetailErrorLog(ctx, &meta.ErrorLogDetail{
+		DBTypeS:     snapshot.DBTypeS,
+		DBTypeT:     snapshot.DBTypeT,
+		SchemaNameS: snapshot.SchemaNameS,
+		TaskMode:    snapshot.TaskMode,
+	})
+	if err != nil {
+		return err
+	}
+	snapshot.WaitMetas, snapshot.FullMetas, snapshot.ErrorLogs = waitMetas, fullMetas, errLogs
+
+	file, err := os.Create(cfg.MetaFile)
+	if err != nil {
+		return fmt.Errorf("create meta snapshot file [%s] failed: %v", cfg.MetaFile, err)
+	}
+	defer file.Close()
+	gw := gzip.NewWriter(file)
+	if err = json.NewEncoder(gw).Encode(&snapshot); err != nil {
+		return fmt.Errorf("encode meta snapshot file [%s] failed: %v", cfg.MetaFile, err)
+	}
+	if err = gw.Close(); err != nil {
+		return fmt.Errorf("close meta snapshot file [%s] gzip writer failed: %v", cfg.MetaFile, err)
+	}
+
+	zap.L().Info("export meta snapshot finished",
+		zap.String("schema", snapshot.SchemaNameS),
+		zap.String("task mode", snapshot.TaskMode),
+		zap.String("file", cfg.MetaFile),
+		zap.Int("wait_sync_meta", len(waitMetas)),
+		zap.Int("full_sync_meta", len(fullMetas)),
+		zap.Int("error_log_detail", len(errLogs)),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
+
+func importMetaSnapshot(ctx context.Context, cfg *config.Config, metaDB *meta.Meta) error {
+	startTime := time.Now()
+	file, err := os.Open(cfg.MetaFile)
+	if err != nil {
+		return fmt.Errorf("open meta snapshot file [%s] failed: %v", cfg.MetaFile, err)
+	}
+	defer file.Close()
+	gr, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("open meta snapshot file [%s] gzip reader failed: %v", cfg.MetaFile, err)
+	}
+	defer gr.Close()
+
+	var snapshot metaSnapshot
+	if err = json.NewDecoder(gr).Decode(&snapshot); err != nil {
+		return fmt.Errorf("decode meta snapshot file [%s] failed: %v", cfg.MetaFile, err)
+	}
+	if snapshot.TaskMode != cfg.MetaTask {
+		return fmt.Errorf("meta snapshot file [%s] task mode [%s] isn't equal flag [task] value [%s]", cfg.MetaFile, snapshot.TaskMode, cfg.MetaTask)
+	}
+
+	// 自增编号由当前元数据库重新分配
+	for i := range snapshot.WaitMetas {
+		snapshot.WaitMetas[i].ID = 0
+	}
+	for i := range snapshot.FullMetas {
+		snapshot.FullMetas[i].ID = 0
+	}
+	for i := range snapshot.ErrorLogs {
+		snapshot.ErrorLogs[i].ID = 0
+	}
+
+	if err = meta.NewCommonModel(metaDB).ImportMetaSnapshot(ctx, &meta.WaitSyncMeta{
+		DBTypeS:     snapshot.DBTypeS,
+		DBTypeT:     snapshot.DBTypeT,
+		SchemaNameS: snapshot.SchemaNameS,
+		TaskMode:    snapshot.TaskMode,
+	}, snapshot.WaitMetas, snapshot.FullMetas, snapshot.ErrorLogs, cfg.AppConfig.InsertBatchSize); err != nil {
+		return err
+	}
+
+	zap.L().Info("import meta snapshot finished",
+		zap.String("schema", snapshot.SchemaNameS),
+		zap.String("task mode", snapshot.TaskMode),
+		zap.String("file", cfg.MetaFile),
+		zap.String("export time", snapshot.ExportTime),
+		zap.Int("wait_sync_meta", len(snapshot.WaitMetas)),
+		zap.Int("full_sync_meta", len(snapshot.FullMetas)),
+		zap.Int("error_log_detail", len(snapshot.ErrorLogs)),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+	return nil
+}
@@ -17,10 +17,14 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"github.com/wentaojin/transferdb/common"
 	"github.com/wentaojin/transferdb/config"
 	"github.com/wentaojin/transferdb/module/migrate"
+	"github.com/wentaojin/transferdb/module/migrate/m2o"
+	"github.com/wentaojin/transferdb/module/migrate/o2d"
 	"github.com/wentaojin/transferdb/module/migrate/o2m"
+	"github.com/wentaojin/transferdb/module/migrate/o2og"
 	"strings"
 )
 
@@ -35,6 +39,21 @@ func IMigrateFull(ctx context.Context, cfg *config.Config) error {
 		if err != nil {
 			return err
 		}
+	case strings.EqualFold(cfg.DBTypeS, common.DatabaseTypeMySQL) && strings.EqualFold(cfg.DBTypeT, common.DatabaseTypeOracle):
+		f, err = m2o.NewFuller(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	case strings.EqualFold(cfg.DBTypeS, common.DatabaseTypeOracle) && strings.EqualFold(cfg.DBTypeT, common.DatabaseTypeDM):
+		f, err = o2d.NewFuller(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	case strings.EqualFold(cfg.DBTypeS, common.DatabaseTypeOracle) && strings.EqualFold(cfg.DBTypeT, common.DatabaseTypeOpenGauss):
+		f, err = o2og.NewFuller(ctx, cfg)
+		if err != nil {
+			return err
+		}
 	}
 	err = f.Full()
 	if err != nil {
@@ -61,3 +80,62 @@ func IMigrateIncr(ctx context.Context, cfg *config.Config) error {
 	}
 	return nil
 }
+
+func IMigrateOGG(ctx context.Context, cfg *config.Config) error {
+	var (
+		i   migrate.Increr
+		err error
+	)
+	switch {
+	case strings.EqualFold(cfg.DBTypeS, common.DatabaseTypeOracle) && strings.EqualFold(cfg.DBTypeT, common.DatabaseTypeMySQL):
+		i, err = o2m.NewOGG(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("ogg mode isn't support db-type-s [%s] db-type-t [%s]", cfg.DBTypeS, cfg.DBTypeT)
+	}
+	err = i.Incr()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func IMigrateReload(ctx context.Context, cfg *config.Config) error {
+	var (
+		r   migrate.Reloader
+		err error
+	)
+	switch {
+	case strings.EqualFold(cfg.DBTypeS, common.DatabaseTypeOracle) && strings.EqualFold(cfg.DBTypeT, common.DatabaseTypeMySQL):
+		r, err = o2m.NewFuller(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	}
+	err = r.Reload()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func IMigrateUninstall(ctx context.Context, cfg *config.Config) error {
+	var (
+		u   migrate.Uninstaller
+		err error
+	)
+	switch {
+	case strings.EqualFold(cfg.DBTypeS, common.DatabaseTypeOracle) && strings.EqualFold(cfg.DBTypeT, common.DatabaseTypeMySQL):
+		u, err = o2m.NewFuller(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	}
+	err = u.Uninstall()
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+// pipelineStageModes 编排阶段对应任务模式，阶段内按顺序执行
+var pipelineStageModes = map[string][]string{
+	common.PipelineStageSchema: {common.TaskModeReverse, common.TaskModeCheck},
+	common.PipelineStageData:   {common.TaskModeFull},
+	common.PipelineStageVerify: {common.TaskModeCompare},
+}
+
+// pipelineStatusSkipped 前序任务失败，后续任务不再执行
+const pipelineStatusSkipped = "SKIPPED"
+
+// pipelineStep 单任务模式执行结果
+type pipelineStep struct {
+	Stage    string
+	TaskMode string
+	Status   string
+	Cost     time.Duration
+	Err      error
+}
+
+// IPipeliner 按 --pipeline 阶段顺序执行 reverse/check/full/compare，任一任务失败即终止，最终汇总各任务执行结果
+func IPipeliner(ctx context.Context, cfg *config.Config) error {
+	stages, err := parsePipelineStages(cfg.Pipeline)
+	if err != nil {
+		return err
+	}
+
+	originMode := cfg.TaskMode
+	defer func() {
+		cfg.TaskMode = originMode
+	}()
+
+	startTime := time.Now()
+	var (
+		steps   []pipelineStep
+		failErr error
+	)
+	for _, stage := range stages {
+		for _, mode := range pipelineStageModes[stage] {
+			if failErr != nil {
+				steps = append(steps, pipelineStep{Stage: stage, TaskMode: mode, Status: pipelineStatusSkipped})
+				continue
+			}
+			zap.L().Info("pipeline task start",
+				zap.String("stage", stage),
+				zap.String("mode", mode))
+
+			stepTime := time.Now()
+			// 各模块按 TaskMode 区分元数据记录，阶段间复用同一配置
+			cfg.TaskMode = mode
+			err = runTask(ctx, cfg)
+			step := pipelineStep{Stage: stage, TaskMode: mode, Status: common.TaskStatusSuccess, Cost: time.Since(stepTime), Err: err}
+			if err != nil {
+				step.Status = common.TaskStatusFailed
+				failErr = fmt.Errorf("pipeline stage [%s] mode [%s] failed: %v", stage, mode, err)
+			}
+			steps = append(steps, step)
+		}
+	}
+
+	for _, s := range steps {
+		fields := []zap.Field{
+			zap.String("stage", s.Stage),
+			zap.String("mode", s.TaskMode),
+			zap.String("status", s.Status),
+			zap.String("cost", s.Cost.String()),
+		}
+		if s.Err != nil {
+			fields = append(fields, zap.Error(s.Err))
+		}
+		zap.L().Info("pipeline task outcome", fields...)
+	}
+	zap.L().Info("pipeline finished",
+		zap.String("pipeline", cfg.Pipeline),
+		zap.Bool("success", failErr == nil),
+		zap.String("cost", time.Since(startTime).String()))
+
+	return failErr
+}
+
+// parsePipelineStages 逗号分隔阶段，保持配置顺序，不允许重复阶段
+func parsePipelineStages(pipeline string) ([]string, error) {
+	var stages []string
+	for _, s := range strings.Split(pipeline, ",") {
+		stage := common.StringUPPER(strings.TrimSpace(s))
+		if stage == "" {
+			continue
+		}
+		if _, ok := pipelineStageModes[stage]; !ok {
+			return nil, fmt.Errorf("flag [pipeline] stage [%s] isn't support, only support [schema data verify]", stage)
+		}
+		if common.IsContainString(stages, stage) {
+			return nil, fmt.Errorf("flag [pipeline] stage [%s] is repeated", stage)
+		}
+		stages = append(stages, stage)
+	}
+	if len(stages) == 0 {
+		return nil, fmt.Errorf("flag [pipeline] value [%s] can't be null", pipeline)
+	}
+	return stages, nil
+}
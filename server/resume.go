@@ -0,0 +1,70 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/meta"
+	"go.uber.org/zap"
+	"time"
+)
+
+// IResumer 失败 chunk 重新入队并以断点续传方式重跑对应任务，无需手工修改元数据表
+func IResumer(ctx context.Context, cfg *config.Config) error {
+	startTime := time.Now()
+	if cfg.ResumeMode != common.TaskModeFull && cfg.ResumeMode != common.TaskModeCSV {
+		return fmt.Errorf("flag [resume-mode] value [%s] isn't support, only support [full csv]", cfg.ResumeMode)
+	}
+
+	metaDB, err := meta.NewMetaDBEngine(ctx, cfg.MySQLConfig, cfg.AppConfig.SlowlogThreshold)
+	if err != nil {
+		return err
+	}
+	tables, err := meta.NewCommonModel(metaDB).RequeueFailedFullSyncMetaAndWaitSyncMeta(ctx, &meta.WaitSyncMeta{
+		DBTypeS:     cfg.DBTypeS,
+		DBTypeT:     cfg.DBTypeT,
+		SchemaNameS: common.StringUPPER(cfg.OracleConfig.SchemaName),
+		TaskMode:    cfg.ResumeMode,
+	})
+	if err != nil {
+		return err
+	}
+	if len(tables) == 0 {
+		zap.L().Warn("resume task failed chunks isn't exist, skip",
+			zap.String("schema", cfg.OracleConfig.SchemaName),
+			zap.String("mode", cfg.ResumeMode))
+		return nil
+	}
+	zap.L().Info("resume task failed chunks requeue finished",
+		zap.String("schema", cfg.OracleConfig.SchemaName),
+		zap.String("mode", cfg.ResumeMode),
+		zap.Strings("tables", tables),
+		zap.String("cost", time.Now().Sub(startTime).String()))
+
+	// 重跑原任务，强制断点续传，避免清理元数据以及下游表数据
+	cfg.TaskMode = cfg.ResumeMode
+	switch cfg.ResumeMode {
+	case common.TaskModeFull:
+		cfg.FullConfig.EnableCheckpoint = true
+		return IMigrateFull(ctx, cfg)
+	default:
+		cfg.CSVConfig.EnableCheckpoint = true
+		return ICSVer(ctx, cfg)
+	}
+}
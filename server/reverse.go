@@ -20,8 +20,11 @@ import (
 	"github.com/wentaojin/transferdb/common"
 	"github.com/wentaojin/transferdb/config"
 	"github.com/wentaojin/transferdb/module/reverse"
+	"github.com/wentaojin/transferdb/module/reverse/db2m"
 	"github.com/wentaojin/transferdb/module/reverse/m2o"
+	"github.com/wentaojin/transferdb/module/reverse/o2d"
 	"github.com/wentaojin/transferdb/module/reverse/o2m"
+	"github.com/wentaojin/transferdb/module/reverse/o2og"
 	"strings"
 )
 
@@ -41,6 +44,21 @@ func IReverse(ctx context.Context, cfg *config.Config) error {
 		if err != nil {
 			return err
 		}
+	case strings.EqualFold(cfg.DBTypeS, common.DatabaseTypeOracle) && strings.EqualFold(cfg.DBTypeT, common.DatabaseTypeDM):
+		r, err = o2d.NewReverse(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	case strings.EqualFold(cfg.DBTypeS, common.DatabaseTypeOracle) && strings.EqualFold(cfg.DBTypeT, common.DatabaseTypeOpenGauss):
+		r, err = o2og.NewReverse(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	case strings.EqualFold(cfg.DBTypeS, common.DatabaseTypeDB2) && strings.EqualFold(cfg.DBTypeT, common.DatabaseTypeMySQL):
+		r, err = db2m.NewReverse(ctx, cfg)
+		if err != nil {
+			return err
+		}
 	}
 
 	err = r.Reverse()
@@ -0,0 +1,81 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/meta"
+	"go.uber.org/zap"
+)
+
+// upsertDatatypeRules 配置文件 [[rule.datatype]] 字段类型映射规则写入元数据库，规则随配置文件纳入版本管理
+// 仅新增或更新，配置文件删除的规则不会从元数据库删除
+func upsertDatatypeRules(ctx context.Context, cfg *config.Config) error {
+	if len(cfg.RuleConfig.DatatypeRules) == 0 {
+		return nil
+	}
+	metaDB, err := meta.NewMetaDBEngine(ctx, cfg.MySQLConfig, cfg.AppConfig.SlowlogThreshold)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range cfg.RuleConfig.DatatypeRules {
+		if r.SchemaName == "" || r.ColumnTypeS == "" || r.ColumnTypeT == "" {
+			return fmt.Errorf("rule datatype [%+v] schema-name / column-type-s / column-type-t can not be null", r)
+		}
+		switch {
+		case r.ColumnName != "":
+			if r.TableName == "" {
+				return fmt.Errorf("rule datatype [%+v] column level rule table-name can not be null", r)
+			}
+			err = meta.NewColumnDatatypeRuleModel(metaDB).UpsertColumnRule(ctx, &meta.ColumnDatatypeRule{
+				DBTypeS:     r.DBTypeS,
+				DBTypeT:     r.DBTypeT,
+				SchemaNameS: r.SchemaName,
+				TableNameS:  r.TableName,
+				ColumnNameS: r.ColumnName,
+				ColumnTypeS: r.ColumnTypeS,
+				ColumnTypeT: r.ColumnTypeT,
+			})
+		case r.TableName != "":
+			err = meta.NewTableDatatypeRuleModel(metaDB).UpsertTableRule(ctx, &meta.TableDatatypeRule{
+				DBTypeS:     r.DBTypeS,
+				DBTypeT:     r.DBTypeT,
+				SchemaNameS: r.SchemaName,
+				TableNameS:  r.TableName,
+				ColumnTypeS: r.ColumnTypeS,
+				ColumnTypeT: r.ColumnTypeT,
+			})
+		default:
+			err = meta.NewSchemaDatatypeRuleModel(metaDB).UpsertSchemaRule(ctx, &meta.SchemaDatatypeRule{
+				DBTypeS:     r.DBTypeS,
+				DBTypeT:     r.DBTypeT,
+				SchemaNameS: r.SchemaName,
+				ColumnTypeS: r.ColumnTypeS,
+				ColumnTypeT: r.ColumnTypeT,
+			})
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	zap.L().Info("upsert config datatype rules finished",
+		zap.Int("rule totals", len(cfg.RuleConfig.DatatypeRules)))
+	return nil
+}
@@ -20,12 +20,90 @@ import (
 	"fmt"
 	"github.com/wentaojin/transferdb/common"
 	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/database/mysql"
+	"github.com/wentaojin/transferdb/module/hook"
 	"github.com/wentaojin/transferdb/module/prepare"
 	"strings"
+	"time"
 )
 
 // 程序运行
 func Run(ctx context.Context, cfg *config.Config) error {
+	// 上下游会话时区不一致，DATE/TIMESTAMP 迁移后出现整小时偏移
+	if cfg.OracleConfig.SessionTimeZone != "" && cfg.MySQLConfig.SessionTimeZone != "" &&
+		!strings.EqualFold(cfg.OracleConfig.SessionTimeZone, cfg.MySQLConfig.SessionTimeZone) {
+		return fmt.Errorf("oracle session-time-zone [%s] isn't equal mysql session-time-zone [%s]",
+			cfg.OracleConfig.SessionTimeZone, cfg.MySQLConfig.SessionTimeZone)
+	}
+
+	// 元数据库慢查询诊断
+	if cfg.AppConfig.SlowlogDiagnostics && cfg.AppConfig.SlowlogThreshold > 0 {
+		metaDB, err := meta.NewMetaDBEngine(ctx, cfg.MySQLConfig, cfg.AppConfig.SlowlogThreshold)
+		if err != nil {
+			return err
+		}
+		if err = metaDB.StartSlowQueryDiagnostics(ctx); err != nil {
+			return err
+		}
+	}
+
+	// 配置文件字段类型映射规则
+	if err := upsertDatatypeRules(ctx, cfg); err != nil {
+		return err
+	}
+
+	// chunk 调度运行窗口
+	runWindow, err := common.NewRunWindow(cfg.AppConfig.RunWindows, cfg.AppConfig.RunWindowTimeZone,
+		time.Duration(cfg.AppConfig.RunWindowCheckInterval)*time.Second)
+	if err != nil {
+		return err
+	}
+	common.SetRunWindow(runWindow)
+
+	// 任务错误状态接口
+	if err := registerStatusAPI(ctx, cfg); err != nil {
+		return err
+	}
+
+	// 任务进度页面，任务退出时关闭
+	stopDashboard, err := startDashboard(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer stopDashboard()
+
+	// 任务级别钩子
+	if !hook.IsExistHook(cfg, common.TaskHookScopeTask) {
+		return run(ctx, cfg)
+	}
+	mysqlDB, err := mysql.NewMySQLDBEngine(ctx, cfg.MySQLConfig)
+	if err != nil {
+		return err
+	}
+	metaDB, err := meta.NewMetaDBEngine(ctx, cfg.MySQLConfig, cfg.AppConfig.SlowlogThreshold)
+	if err != nil {
+		return err
+	}
+	h := hook.NewHook(ctx, cfg, mysqlDB, metaDB)
+	if err = h.RunTaskHook(common.TaskHookStageBefore); err != nil {
+		return err
+	}
+	if err = run(ctx, cfg); err != nil {
+		return err
+	}
+	return h.RunTaskHook(common.TaskHookStageAfter)
+}
+
+func run(ctx context.Context, cfg *config.Config) error {
+	// 多阶段任务编排
+	if cfg.Pipeline != "" {
+		return IPipeliner(ctx, cfg)
+	}
+	return runTask(ctx, cfg)
+}
+
+func runTask(ctx context.Context, cfg *config.Config) error {
 	switch strings.ToUpper(strings.TrimSpace(cfg.TaskMode)) {
 	case common.TaskModePrepare:
 		// 表结构转换 - only prepare 阶段
@@ -75,6 +153,78 @@ func Run(ctx context.Context, cfg *config.Config) error {
 		if err != nil {
 			return err
 		}
+	case common.TaskModeOGG:
+		// 增量数据同步 - 消费 OGG Kafka 变更记录，适用于上游禁止 logminer 场景
+		err := IMigrateOGG(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	case common.TaskModeReload:
+		// 指定表范围数据重新加载 - 分批删除下游并重新抽取上游
+		err := IMigrateReload(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	case common.TaskModeBench:
+		// 上下游读写性能基准测试
+		err := IBench(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	case common.TaskModeLoad:
+		// csv 导出文件导入 - 按文件断点续传
+		err := ILoader(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	case common.TaskModeLightning:
+		// csv 导出后 tidb-lightning local backend 导入 - 绕过 SQL 层
+		err := ILightning(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	case common.TaskModeAnalytic:
+		// csv 导出后生成 ClickHouse/Greenplum 建表语句并调用原生批量导入工具 - 适用于报表分析迁移
+		err := IAnalytic(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	case common.TaskModeShip:
+		// csv 导出文件跨网络传输 - sender/receiver
+		err := IShipper(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	case common.TaskModeVerify:
+		// 下游引用完整性校验 - 孤儿数据
+		err := IVerifier(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	case common.TaskModeRollback:
+		// 下游切换回退 - 按快照恢复下游表
+		err := IRollbacker(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	case common.TaskModeUninstall:
+		// 卸载增量触发器捕获对象 - 触发器、影子表以及序列
+		err := IMigrateUninstall(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	case common.TaskModeResume:
+		// 失败 chunk 重新入队 - 仅重跑 full/csv 任务 FAILED chunk
+		err := IResumer(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	case common.TaskModeMeta:
+		// 任务元数据快照导出导入 - 离线排查
+		err := IMetaer(ctx, cfg)
+		if err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("flag [mode] can not null or value configure error")
 	}
@@ -0,0 +1,52 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"fmt"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/module/ship"
+	"github.com/wentaojin/transferdb/module/ship/agent"
+)
+
+func IShipper(ctx context.Context, cfg *config.Config) error {
+	var (
+		s   ship.Shipper
+		err error
+	)
+	switch cfg.ShipConfig.Role {
+	case common.MigrateShipRoleSender:
+		s, err = agent.NewSender(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	case common.MigrateShipRoleReceiver:
+		s, err = agent.NewReceiver(ctx, cfg)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("ship mode role [%s] isn't support, only support sender or receiver", cfg.ShipConfig.Role)
+	}
+
+	err = s.Ship()
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+/*
+Copyright © 2020 Marvin
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/wentaojin/transferdb/common"
+	"github.com/wentaojin/transferdb/config"
+	"github.com/wentaojin/transferdb/database/meta"
+	"github.com/wentaojin/transferdb/logger"
+	"github.com/wentaojin/transferdb/metrics"
+	"go.uber.org/zap"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// registerStatusAPI 注册任务错误状态接口（含处理建议），复用 pprof-port 监听
+// GET /api/v1/errors?task-mode=xxx，task-mode 缺省取当前任务模式，错误明细可能包含行数据，仅允许本机访问
+// GET /metrics，prometheus 指标（chunk、行数、写入/抽取耗时、错误数、logminer 延迟）
+// GET /api/v1/run-window 运行窗口以及 chunk 调度暂停状态
+// GET /api/v1/log-level 查看日志级别，PUT /api/v1/log-level?module=xxx&level=debug 运行时调整，module 缺省为 global，仅允许本机访问
+func registerStatusAPI(ctx context.Context, cfg *config.Config) error {
+	schemaName := cfg.OracleConfig.SchemaName
+	if strings.EqualFold(cfg.DBTypeS, common.DatabaseTypeMySQL) {
+		schemaName = cfg.MySQLConfig.SchemaName
+	}
+
+	// 元数据库连接首次请求时建立，未访问接口的任务不额外占用连接
+	var (
+		metaMu sync.Mutex
+		metaDB *meta.Meta
+	)
+	getMetaDB := func() (*meta.Meta, error) {
+		metaMu.Lock()
+		defer metaMu.Unlock()
+		if metaDB != nil {
+			return metaDB, nil
+		}
+		db, err := meta.NewMetaDBEngine(ctx, cfg.MySQLConfig, cfg.AppConfig.SlowlogThreshold)
+		if err != nil {
+			return nil, err
+		}
+		metaDB = db
+		return metaDB, nil
+	}
+
+	http.Handle("/metrics", metrics.Handler())
+
+	http.HandleFunc("/api/v1/run-window", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(common.CurrentRunWindowState()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	// 日志级别调整无鉴权，仅允许本机访问
+	http.HandleFunc("/api/v1/log-level", loopbackOnly(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			module := req.URL.Query().Get("module")
+			level := req.URL.Query().Get("level")
+			if err := logger.SetLogLevel(module, level); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			zap.L().Warn("log level changed by admin api",
+				zap.String("module", module),
+				zap.String("level", level))
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(logger.LogLevels()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}))
+
+	http.HandleFunc("/api/v1/errors", loopbackOnly(func(w http.ResponseWriter, req *http.Request) {
+		db, err := getMetaDB()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		taskMode := common.StringUPPER(req.URL.Query().Get("task-mode"))
+		if taskMode == "" {
+			taskMode = common.StringUPPER(cfg.TaskMode)
+		}
+		errLogs, err := meta.NewErrorLogDetailModel(db).DetailErrorLog(ctx, &meta.ErrorLogDetail{
+			DBTypeS:     cfg.DBTypeS,
+			DBTypeT:     cfg.DBTypeT,
+			SchemaNameS: schemaName,
+			TaskMode:    taskMode,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err = json.NewEncoder(w).Encode(errLogs); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}))
+	return nil
+}
+
+// loopbackOnly pprof-port 默认监听全部网卡，敏感接口仅允许本机访问，远程查看需经 ssh 隧道等方式转发
+func loopbackOnly(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		host, _, err := net.SplitHostPort(req.RemoteAddr)
+		if err != nil {
+			host = req.RemoteAddr
+		}
+		if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+			http.Error(w, "forbidden, only allow loopback access", http.StatusForbidden)
+			return
+		}
+		handler(w, req)
+	}
+}